@@ -7,7 +7,7 @@ _gokanon_completions() {
     _init_completion || return
 
     # Main commands
-    local commands="run list compare export stats trend check flamegraph serve delete baseline doctor interactive completion help"
+    local commands="run list show compare export stats trend check flamegraph serve delete baseline doctor interactive attach annotate reanalyze agent daemon slo config import projects ci bisect sync profile snapshot stability prune search fleet tui push analyze telemetry plugins completion help"
 
     # If we're at the first argument, complete commands
     if [ $cword -eq 1 ]; then
@@ -21,36 +21,87 @@ _gokanon_completions() {
     # Command-specific completions
     case "$command" in
         run)
-            local run_opts="-bench -pkg -profile -storage -benchtime -count -timeout -cpu -v"
+            local run_opts="-bench -pkg -profile -storage -benchtime -count -timeout -cpu -stress -parallelism -parallel-packages -startup -startup-args -startup-ready -startup-iterations -startup-timeout -load -load-name -load-duration -load-rate -load-concurrency -load-method -load-body -load-header -gcflags -v -wait -skip-if-unstable -config -on -controller -token -sink -hooks -tags -note"
             COMPREPLY=($(compgen -W "$run_opts" -- "$cur"))
             ;;
+        list)
+            if [[ "$prev" == "-time-format" ]]; then
+                COMPREPLY=($(compgen -W "default datetime date rfc3339 rfc1123 kitchen unix relative" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "-storage -config -failed -time-format -tz" -- "$cur"))
+            fi
+            ;;
+        show)
+            if [[ "$prev" == "-o" ]]; then
+                COMPREPLY=($(compgen -W "text json" -- "$cur"))
+            elif [[ "$prev" == "-time-format" ]]; then
+                COMPREPLY=($(compgen -W "default datetime date rfc3339 rfc1123 kitchen unix relative" -- "$cur"))
+            elif [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "-storage -config -o -time-format -tz" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "latest previous latest~1 latest~2" -- "$cur"))
+            fi
+            ;;
         compare)
             if [[ "$cur" == -* ]]; then
-                COMPREPLY=($(compgen -W "--latest --baseline -storage -format" -- "$cur"))
+                COMPREPLY=($(compgen -W "--latest --baseline -storage -format -config -dry-run -normalize -allow-env-mismatch -scaling -no-cache -time-format -tz" -- "$cur"))
             else
-                # Complete with run IDs (would need to call gokanon list)
-                COMPREPLY=()
+                # Symbolic run references; run IDs would need gokanon list
+                COMPREPLY=($(compgen -W "latest previous latest~1 latest~2 baseline: commit:" -- "$cur"))
             fi
             ;;
         export)
             if [[ "$prev" == "-format" ]]; then
-                COMPREPLY=($(compgen -W "html csv markdown json" -- "$cur"))
+                COMPREPLY=($(compgen -W "html csv markdown benchstat json ipynb parquet badge" -- "$cur"))
             elif [[ "$cur" == -* ]]; then
-                COMPREPLY=($(compgen -W "--latest -format -output -storage" -- "$cur"))
+                COMPREPLY=($(compgen -W "--latest -format -output -storage -config -normalize -allow-env-mismatch -history -run -summary-only -deterministic -round -timestamps -open -no-open -time-format -tz" -- "$cur"))
             fi
             ;;
-        stats|trend)
-            COMPREPLY=($(compgen -W "-last -storage -format" -- "$cur"))
+        stats)
+            COMPREPLY=($(compgen -W "-last -storage -format -stress" -- "$cur"))
+            ;;
+        trend)
+            if [[ "$prev" == "-anomaly-method" ]]; then
+                COMPREPLY=($(compgen -W "stddev iqr median" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "-last -storage -benchmark -metric -config -normalize -sparkline -include-failed -anomalies -anomaly-method -anomaly-window -k" -- "$cur"))
+            fi
             ;;
         check)
-            COMPREPLY=($(compgen -W "--latest -threshold -storage -format" -- "$cur"))
+            if [[ "$prev" == "-format" ]]; then
+                COMPREPLY=($(compgen -W "text junit" -- "$cur"))
+            elif [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "--latest -threshold -fail-on-removed -memory -summary-only -explain -storage -format -output -config -normalize -allow-env-mismatch" -- "$cur"))
+            fi
             ;;
         serve)
-            COMPREPLY=($(compgen -W "-port -storage -open" -- "$cur"))
+            COMPREPLY=($(compgen -W "-port -storage -open -no-open -run-pkg -agents -token -basic-auth -auth-token -read-only -config -tz" -- "$cur"))
             ;;
-        flamegraph)
+        agent)
+            COMPREPLY=($(compgen -W "-join -labels -name -token -pkg -poll" -- "$cur"))
+            ;;
+        daemon)
+            COMPREPLY=($(compgen -W "-storage -config -once" -- "$cur"))
+            ;;
+        annotate)
+            if [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "-storage -kind -remove" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "latest previous latest~1 latest~2" -- "$cur"))
+            fi
+            ;;
+        reanalyze)
             if [[ "$cur" == -* ]]; then
-                COMPREPLY=($(compgen -W "-port -storage -open" -- "$cur"))
+                COMPREPLY=($(compgen -W "-storage -config -all" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "latest previous latest~1 latest~2" -- "$cur"))
+            fi
+            ;;
+        flamegraph)
+            if [[ "$prev" == "-format" ]]; then
+                COMPREPLY=($(compgen -W "web speedscope collapsed" -- "$cur"))
+            elif [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "-port -storage -open -no-open -format -o -profile -sample-type" -- "$cur"))
             fi
             ;;
         baseline)
@@ -64,10 +115,10 @@ _gokanon_completions() {
                         COMPREPLY=($(compgen -W "-name -run -desc -storage" -- "$cur"))
                         ;;
                     list)
-                        COMPREPLY=($(compgen -W "-storage" -- "$cur"))
+                        COMPREPLY=($(compgen -W "-storage -time-format -tz" -- "$cur"))
                         ;;
                     show)
-                        COMPREPLY=($(compgen -W "-name -storage" -- "$cur"))
+                        COMPREPLY=($(compgen -W "-name -storage -time-format -tz" -- "$cur"))
                         ;;
                     delete)
                         COMPREPLY=($(compgen -W "-name -storage" -- "$cur"))
@@ -75,6 +126,112 @@ _gokanon_completions() {
                 esac
             fi
             ;;
+        slo)
+            if [ $cword -eq 2 ]; then
+                COMPREPLY=($(compgen -W "status" -- "$cur"))
+            elif [[ "$prev" == "-format" ]]; then
+                COMPREPLY=($(compgen -W "table json" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "-storage -config -format" -- "$cur"))
+            fi
+            ;;
+        plugins)
+            COMPREPLY=($(compgen -W "-json" -- "$cur"))
+            ;;
+        telemetry)
+            if [ $cword -eq 2 ]; then
+                COMPREPLY=($(compgen -W "on off status" -- "$cur"))
+            fi
+            ;;
+        config)
+            if [ $cword -eq 2 ]; then
+                COMPREPLY=($(compgen -W "path" -- "$cur"))
+            elif [ $cword -eq 3 ]; then
+                COMPREPLY=($(compgen -W "storage config" -- "$cur"))
+            fi
+            ;;
+        ci)
+            COMPREPLY=($(compgen -W "-baseline -threshold -fail-on-removed -memory -bench -pkg -benchtime -count -storage -config -summary -allow-env-mismatch" -- "$cur"))
+            ;;
+        bisect)
+            COMPREPLY=($(compgen -W "-benchmark -good -bad -threshold -metric -pkg -bench -benchtime -count -config" -- "$cur"))
+            ;;
+        sync)
+            if [ $cword -eq 2 ]; then
+                COMPREPLY=($(compgen -W "push pull" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "-remote -endpoint -region -dry-run -force -storage -config" -- "$cur"))
+            fi
+            ;;
+        fleet)
+            if [ $cword -eq 2 ]; then
+                COMPREPLY=($(compgen -W "report" -- "$cur"))
+            elif [[ "$prev" == "-format" ]]; then
+                COMPREPLY=($(compgen -W "text json markdown" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "-storage -remote -endpoint -region -owner -baseline -threshold -format -output" -- "$cur"))
+            fi
+            ;;
+        profile)
+            if [ $cword -eq 2 ]; then
+                COMPREPLY=($(compgen -W "compare -duration -pkg -storage -config -cpu-sample-type -mem-sample-type -gcflags -web -port -wait" -- "$cur"))
+            elif [[ "${words[2]}" == "compare" ]]; then
+                if [[ "$prev" == "-type" ]]; then
+                    COMPREPLY=($(compgen -W "cpu mem block mutex warmup" -- "$cur"))
+                else
+                    COMPREPLY=($(compgen -W "-type -sample-type -top -format -storage -config" -- "$cur"))
+                fi
+            else
+                COMPREPLY=($(compgen -W "-duration -pkg -storage -config -cpu-sample-type -mem-sample-type -gcflags -web -port -wait" -- "$cur"))
+            fi
+            ;;
+        doctor)
+            COMPREPLY=($(compgen -W "-ci -json -storage -baseline -config" -- "$cur"))
+            ;;
+        stability)
+            COMPREPLY=($(compgen -W "-bench -pkg -count -benchtime -cv-threshold -threshold -config" -- "$cur"))
+            ;;
+        search)
+            if [[ "$prev" == "-time-format" ]]; then
+                COMPREPLY=($(compgen -W "default datetime date rfc3339 rfc1123 kitchen unix relative" -- "$cur"))
+            elif [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "-limit -since -json -storage -config -time-format -tz" -- "$cur"))
+            fi
+            ;;
+        interactive|i)
+            COMPREPLY=($(compgen -W "-prompt -history -storage -config" -- "$cur"))
+            ;;
+        tui)
+            if [[ "$prev" == "-time-format" ]]; then
+                COMPREPLY=($(compgen -W "default datetime date rfc3339 rfc1123 kitchen unix relative" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "-last -window -include-failed -storage -config -time-format -tz" -- "$cur"))
+            fi
+            ;;
+        analyze)
+            COMPREPLY=($(compgen -W "-no-cache -show-cached -diff -storage -config -time-format -tz" -- "$cur"))
+            ;;
+        push)
+            COMPREPLY=($(compgen -W "-perfdata -token -all -force -dry-run -storage -config" -- "$cur"))
+            ;;
+        prune)
+            COMPREPLY=($(compgen -W "-keep-last -older-than -profiles-older-than -keep-baselines -compress -dry-run -wait -storage -config" -- "$cur"))
+            ;;
+        snapshot)
+            COMPREPLY=($(compgen -W "-name -desc -limit -force -list -storage -config -time-format -tz" -- "$cur"))
+            ;;
+        import)
+            if [[ "$prev" == "-format" ]]; then
+                COMPREPLY=($(compgen -W "auto go perf benchstat-csv" -- "$cur"))
+            elif [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "-storage -config -format -timestamp -pkg -commit" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -f -- "$cur"))
+            fi
+            ;;
+        projects)
+            COMPREPLY=($(compgen -W "-prune" -- "$cur"))
+            ;;
         completion)
             if [ $cword -eq 2 ]; then
                 COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
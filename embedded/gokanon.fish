@@ -3,6 +3,7 @@
 # Main commands
 complete -c gokanon -f -n __fish_use_subcommand -a run -d "Run benchmarks and save results"
 complete -c gokanon -f -n __fish_use_subcommand -a list -d "List all saved benchmark results"
+complete -c gokanon -f -n __fish_use_subcommand -a show -d "Show everything recorded about one run"
 complete -c gokanon -f -n __fish_use_subcommand -a compare -d "Compare two benchmark results"
 complete -c gokanon -f -n __fish_use_subcommand -a export -d "Export comparison results"
 complete -c gokanon -f -n __fish_use_subcommand -a stats -d "Show statistical analysis"
@@ -14,47 +15,174 @@ complete -c gokanon -f -n __fish_use_subcommand -a delete -d "Delete a benchmark
 complete -c gokanon -f -n __fish_use_subcommand -a baseline -d "Manage baseline benchmarks"
 complete -c gokanon -f -n __fish_use_subcommand -a doctor -d "Run diagnostics"
 complete -c gokanon -f -n __fish_use_subcommand -a interactive -d "Start interactive mode"
+complete -c gokanon -f -n __fish_use_subcommand -a attach -d "Attach an external pprof profile to a run"
+complete -c gokanon -f -n __fish_use_subcommand -a annotate -d "Add a note to a run, shown in listings, reports and trends"
+complete -c gokanon -f -n __fish_use_subcommand -a reanalyze -d "Re-run the profile analysis of saved runs"
+complete -c gokanon -f -n __fish_use_subcommand -a agent -d "Join a dashboard controller as a benchmark agent"
+complete -c gokanon -f -n __fish_use_subcommand -a daemon -d "Run benchmarks on the schedules of the configuration"
+complete -c gokanon -f -n __fish_use_subcommand -a slo -d "Check service level objectives"
+complete -c gokanon -f -n __fish_use_subcommand -a config -d "Show resolved storage and configuration locations"
+complete -c gokanon -f -n __fish_use_subcommand -a import -d "Import go test -bench output, perf data or benchstat CSV"
+complete -c gokanon -f -n __fish_use_subcommand -a projects -d "List projects tracked in the project registry"
+complete -c gokanon -f -n __fish_use_subcommand -a ci -d "Run benchmarks and check them against a baseline in GitHub Actions"
+complete -c gokanon -f -n __fish_use_subcommand -a bisect -d "Find the commit that introduced a regression"
+complete -c gokanon -f -n __fish_use_subcommand -a sync -d "Share history through an S3/GCS bucket"
+complete -c gokanon -f -n __fish_use_subcommand -a profile -d "Profile a single benchmark for a fixed time"
+complete -c gokanon -f -n __fish_use_subcommand -a snapshot -d "Freeze dashboard stats and trends for a release"
+complete -c gokanon -f -n __fish_use_subcommand -a stability -d "Find benchmarks too noisy to gate CI on"
+complete -c gokanon -f -n __fish_use_subcommand -a prune -d "Delete old runs and profiles by a retention policy"
+complete -c gokanon -f -n __fish_use_subcommand -a search -d "Search runs by benchmark, package, tag, note or commit"
+complete -c gokanon -f -n __fish_use_subcommand -a fleet -d "Roll up regressions across many repositories"
+complete -c gokanon -f -n __fish_use_subcommand -a tui -d "Full-screen terminal UI for runs, trends and comparisons"
+complete -c gokanon -f -n __fish_use_subcommand -a push -d "Upload runs to a perf data server"
+complete -c gokanon -f -n __fish_use_subcommand -a analyze -d "AI analysis of two runs, or replay cached analyses"
+complete -c gokanon -f -n __fish_use_subcommand -a telemetry -d "Turn anonymous usage telemetry on or off, or inspect it"
+complete -c gokanon -f -n __fish_use_subcommand -a plugins -d "List plugin commands found on PATH"
 complete -c gokanon -f -n __fish_use_subcommand -a completion -d "Install shell completion scripts"
 complete -c gokanon -f -n __fish_use_subcommand -a help -d "Show help message"
 
 # run command options
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o bench -d "Benchmark pattern"
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o pkg -d "Package pattern"
-complete -c gokanon -n "__fish_seen_subcommand_from run" -o profile -d "Enable profiling" -a "cpu mem cpu,mem"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o profile -d "Enable profiling" -a "cpu mem block mutex cpu,mem"
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o storage -d "Storage directory" -r
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o benchtime -d "Benchmark duration"
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o count -d "Run count"
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o timeout -d "Test timeout"
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o cpu -d "CPU counts"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o stress -d "Measure RunParallel scaling"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o parallelism -d "Goroutine counts for -stress"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o parallel-packages -d "Packages benchmarked at once"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o startup -d "Measure the startup time of a main package"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o startup-args -d "Arguments of the started processes"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o startup-ready -d "When a started process is ready" -a "exit http:// tcp:// output:"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o startup-iterations -d "Processes started"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o startup-timeout -d "Longest a process may take to become ready"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load -d "Send HTTP load to a URL"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load-name -d "Name of the load result"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load-duration -d "How long to send requests"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load-rate -d "Requests per second"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load-concurrency -d "Requests in flight at most"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load-method -d "HTTP method" -a "GET POST PUT PATCH DELETE HEAD"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load-body -d "Request body, or @file"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o load-header -d "Request header as Name: value"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o gcflags -d "Compiler flags"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o hooks -d "Run GokanonSetup/GokanonTeardown hooks"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o sink -d "Send results to a destination" -a "storage stdout file: webhook: otlp:"
 complete -c gokanon -n "__fish_seen_subcommand_from run" -o v -d "Verbose output"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o wait -d "Wait for a run in progress"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o skip-if-unstable -d "Record runs on a busy or throttled machine as environment-degraded"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o on -d "Run on an agent with these labels"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o controller -d "Controller URL"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o token -d "Controller access token"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o tags -d "Tag the run for search"
+complete -c gokanon -n "__fish_seen_subcommand_from run" -o note -d "Note recorded with the run"
+
+# timestamp display options
+complete -c gokanon -n "__fish_seen_subcommand_from list compare export baseline" -o time-format -d "Timestamp format" -a "default datetime date rfc3339 rfc1123 kitchen unix relative"
+complete -c gokanon -n "__fish_seen_subcommand_from list compare export baseline" -o tz -d "Time zone for timestamps" -a "local UTC"
 
 # compare command options
 complete -c gokanon -n "__fish_seen_subcommand_from compare" -l latest -d "Compare latest two runs"
 complete -c gokanon -n "__fish_seen_subcommand_from compare" -l baseline -d "Compare against baseline" -r
 complete -c gokanon -n "__fish_seen_subcommand_from compare" -o storage -d "Storage directory" -r
 complete -c gokanon -n "__fish_seen_subcommand_from compare" -o format -d "Output format" -a "table json"
+complete -c gokanon -n "__fish_seen_subcommand_from compare" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from compare" -o normalize -d "Reference benchmark to normalize by"
+complete -c gokanon -n "__fish_seen_subcommand_from compare" -o allow-env-mismatch -d "Compare runs of different environments, marked as such"
+complete -c gokanon -n "__fish_seen_subcommand_from compare" -o scaling -d "Compare ns/op by GOMAXPROCS of runs with a CPU matrix"
+complete -c gokanon -n "__fish_seen_subcommand_from compare" -o no-cache -d "Ask the AI provider again instead of replaying a cached analysis"
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o sparkline -d "Print compact sparklines"
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o include-failed -d "Include runs that terminated abnormally"
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o anomalies -d "Flag runs deviating from the runs before them"
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o anomaly-method -d "How runs are compared with the window" -a "stddev iqr median"
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o anomaly-window -r -d "Preceding runs each run is compared with"
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o k -r -d "Deviation flagged as an anomaly"
+complete -c gokanon -n "__fish_seen_subcommand_from list; and not __fish_seen_subcommand_from baseline" -o failed -d "List only runs that terminated abnormally"
+complete -c gokanon -f -n "__fish_seen_subcommand_from show; and not __fish_seen_subcommand_from baseline" -o o -d "Output format" -a "text json"
+complete -c gokanon -f -n "__fish_seen_subcommand_from show; and not __fish_seen_subcommand_from baseline" -a "latest previous latest~1" -d "Run reference"
+complete -c gokanon -n "__fish_seen_subcommand_from compare" -o dry-run -d "Only report how benchmarks were matched"
+complete -c gokanon -f -n "__fish_seen_subcommand_from compare check export delete annotate reanalyze" -a "latest previous latest~1" -d "Run reference"
 
 # export command options
 complete -c gokanon -n "__fish_seen_subcommand_from export" -l latest -d "Export latest comparison"
-complete -c gokanon -n "__fish_seen_subcommand_from export" -o format -d "Export format" -a "html csv markdown json"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o format -d "Export format" -a "html csv markdown benchstat json ipynb parquet badge"
 complete -c gokanon -n "__fish_seen_subcommand_from export" -o output -d "Output file" -r
 complete -c gokanon -n "__fish_seen_subcommand_from export" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o normalize -d "Reference benchmark to normalize by"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o allow-env-mismatch -d "Compare runs of different environments, marked as such"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o history -d "Export the full result history"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o run -d "Export a single run as an HTML page"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o summary-only -d "Export only the counts and top changes"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o deterministic -d "Sorted, rounded output without run IDs or timestamps"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o round -r -d "Round values to N significant digits"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o timestamps -d "Keep run IDs and timestamps of deterministic exports"
+complete -c gokanon -n "__fish_seen_subcommand_from export" -o open -d "Open the HTML report in the browser"
 
 # stats and trend command options
 complete -c gokanon -n "__fish_seen_subcommand_from stats trend" -o last -d "Number of runs"
 complete -c gokanon -n "__fish_seen_subcommand_from stats trend" -o storage -d "Storage directory" -r
 complete -c gokanon -n "__fish_seen_subcommand_from stats trend" -o format -d "Output format" -a "table json"
+complete -c gokanon -n "__fish_seen_subcommand_from stats" -o stress -d "Scaling report of the newest stress run"
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from trend" -o normalize -d "Reference benchmark to normalize by"
 
 # check command options
 complete -c gokanon -n "__fish_seen_subcommand_from check" -l latest -d "Check latest two runs"
 complete -c gokanon -n "__fish_seen_subcommand_from check" -o threshold -d "Threshold percentage"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o fail-on-removed -d "Fail when benchmarks were removed"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o memory -d "Fail on B/op and allocs/op regressions"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o summary-only -d "Print only the counts and top changes"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o explain -d "Explain failing benchmarks"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o normalize -d "Reference benchmark to normalize by"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o allow-env-mismatch -d "Compare runs of different environments, marked as such"
 complete -c gokanon -n "__fish_seen_subcommand_from check" -o storage -d "Storage directory" -r
-complete -c gokanon -n "__fish_seen_subcommand_from check" -o format -d "Output format" -a "table json"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o format -d "Output format" -a "text junit"
+complete -c gokanon -n "__fish_seen_subcommand_from check" -o output -d "JUnit XML report file" -r
 
 # serve and flamegraph command options
 complete -c gokanon -n "__fish_seen_subcommand_from serve flamegraph" -o port -d "Server port"
 complete -c gokanon -n "__fish_seen_subcommand_from serve flamegraph" -o storage -d "Storage directory" -r
 complete -c gokanon -n "__fish_seen_subcommand_from serve flamegraph" -o open -d "Open browser automatically"
+complete -c gokanon -n "__fish_seen_subcommand_from serve flamegraph export" -o no-open -d "Do not open the browser, even when configured to"
+complete -c gokanon -n "__fish_seen_subcommand_from flamegraph" -o format -d "Output format" -xa "web speedscope collapsed"
+complete -c gokanon -n "__fish_seen_subcommand_from flamegraph" -o o -d "Output file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from flamegraph" -o profile -d "Profile to convert" -xa "cpu mem warmup block mutex"
+complete -c gokanon -n "__fish_seen_subcommand_from flamegraph" -o sample-type -d "Sample type to convert" -x
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o run-pkg -d "Package the dashboard may run"
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o agents -d "Accept remote benchmark agents"
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o token -d "Token required to trigger runs"
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o basic-auth -d "Require viewers to log in as user:password"
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o auth-token -d "Require viewers to present this token"
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o read-only -d "Reject requests that change anything"
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from serve" -o tz -d "Time zone for timestamps"
+
+# agent command options
+complete -c gokanon -n "__fish_seen_subcommand_from agent" -o join -d "Controller URL"
+complete -c gokanon -n "__fish_seen_subcommand_from agent" -o labels -d "Labels describing this machine"
+complete -c gokanon -n "__fish_seen_subcommand_from agent" -o name -d "Agent name"
+complete -c gokanon -n "__fish_seen_subcommand_from agent" -o token -d "Controller access token"
+complete -c gokanon -n "__fish_seen_subcommand_from agent" -o pkg -d "Package path" -r
+complete -c gokanon -n "__fish_seen_subcommand_from agent" -o poll -d "Poll interval"
+
+# annotate command options
+complete -c gokanon -n "__fish_seen_subcommand_from annotate" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from annotate" -o kind -d "Category of the annotation" -a "upgrade infra config"
+complete -c gokanon -n "__fish_seen_subcommand_from annotate" -o remove -d "Remove the annotation with this number"
+
+# reanalyze command options
+complete -c gokanon -n "__fish_seen_subcommand_from reanalyze" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from reanalyze" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from reanalyze" -o all -d "Re-analyze every saved run"
+
+# daemon command options
+complete -c gokanon -n "__fish_seen_subcommand_from daemon" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from daemon" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from daemon" -o once -d "Run this schedule now and exit"
 
 # baseline command - subcommands
 complete -c gokanon -f -n "__fish_seen_subcommand_from baseline; and not __fish_seen_subcommand_from save list show delete" -a save -d "Save a benchmark run as baseline"
@@ -79,5 +207,185 @@ complete -c gokanon -n "__fish_seen_subcommand_from baseline; and __fish_seen_su
 complete -c gokanon -n "__fish_seen_subcommand_from baseline; and __fish_seen_subcommand_from delete" -o name -d "Baseline name" -r
 complete -c gokanon -n "__fish_seen_subcommand_from baseline; and __fish_seen_subcommand_from delete" -o storage -d "Storage directory" -r
 
+# slo command - subcommands and options
+complete -c gokanon -f -n "__fish_seen_subcommand_from slo; and not __fish_seen_subcommand_from status" -a status -d "Show SLO compliance and breach history"
+complete -c gokanon -n "__fish_seen_subcommand_from slo; and __fish_seen_subcommand_from status" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from slo; and __fish_seen_subcommand_from status" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from slo; and __fish_seen_subcommand_from status" -o format -d "Output format" -a "table json"
+
+# config command - subcommands and locations
+complete -c gokanon -f -n "__fish_seen_subcommand_from config; and not __fish_seen_subcommand_from path" -a path -d "Print resolved storage and config locations"
+complete -c gokanon -f -n "__fish_seen_subcommand_from config; and __fish_seen_subcommand_from path" -a "storage config"
+
+# ci command options
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o baseline -d "Baseline to compare against"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o threshold -d "Maximum degradation percentage"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o fail-on-removed -d "Fail when a baseline benchmark is missing"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o memory -d "Fail on B/op and allocs/op regressions"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o bench -d "Benchmark filter"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o pkg -d "Package path"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o benchtime -d "Benchmark time"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o count -d "Samples per benchmark"
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o summary -d "Job summary file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from ci" -o allow-env-mismatch -d "Compare against a baseline of another environment, marked as such"
+
+# bisect command options
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o benchmark -d "Regressed benchmark"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o good -d "Commit without the regression"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o bad -d "Commit with the regression"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o threshold -d "Degradation percentage that counts as regressed"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o metric -d "Metric to compare"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o pkg -d "Package path"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o bench -d "Benchmark filter"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o benchtime -d "Benchmark time"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o count -d "Runs per commit"
+complete -c gokanon -n "__fish_seen_subcommand_from bisect" -o config -d "Configuration file" -r
+
+# sync command - subcommands
+complete -c gokanon -f -n "__fish_seen_subcommand_from sync; and not __fish_seen_subcommand_from push pull" -a push -d "Upload local runs and baselines"
+complete -c gokanon -f -n "__fish_seen_subcommand_from sync; and not __fish_seen_subcommand_from push pull" -a pull -d "Download runs and baselines"
+
+# sync push/pull options
+complete -c gokanon -n "__fish_seen_subcommand_from sync; and __fish_seen_subcommand_from push pull" -o remote -d "Bucket URL" -r
+complete -c gokanon -n "__fish_seen_subcommand_from sync; and __fish_seen_subcommand_from push pull" -o endpoint -d "S3-compatible endpoint" -r
+complete -c gokanon -n "__fish_seen_subcommand_from sync; and __fish_seen_subcommand_from push pull" -o region -d "Bucket region" -r
+complete -c gokanon -n "__fish_seen_subcommand_from sync; and __fish_seen_subcommand_from push pull" -o dry-run -d "Show what would be transferred"
+complete -c gokanon -n "__fish_seen_subcommand_from sync; and __fish_seen_subcommand_from push pull" -o force -d "Replace conflicting copies"
+complete -c gokanon -n "__fish_seen_subcommand_from sync; and __fish_seen_subcommand_from push pull" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from sync; and __fish_seen_subcommand_from push pull" -o config -d "Configuration file" -r
+
+# import command options
+complete -c gokanon -n "__fish_seen_subcommand_from import" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from import" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from import" -o format -d "Input format" -a "auto go perf benchstat-csv"
+complete -c gokanon -n "__fish_seen_subcommand_from import" -o timestamp -d "Time the benchmarks ran (RFC 3339), per run"
+complete -c gokanon -n "__fish_seen_subcommand_from import" -o pkg -d "Package to record"
+complete -c gokanon -n "__fish_seen_subcommand_from import" -o commit -d "Git commit the benchmarks ran at"
+
+# projects command options
+complete -c gokanon -n "__fish_seen_subcommand_from projects" -o prune -d "Forget projects whose directory no longer exists"
+
+# profile command options
+complete -c gokanon -f -n "__fish_seen_subcommand_from profile; and not __fish_seen_subcommand_from compare" -a compare -d "Compare the profiles of two saved runs"
+complete -c gokanon -n "__fish_seen_subcommand_from profile; and __fish_seen_subcommand_from compare" -o type -d "Profile to compare" -xa "cpu mem block mutex warmup"
+complete -c gokanon -n "__fish_seen_subcommand_from profile; and __fish_seen_subcommand_from compare" -o sample-type -d "Sample type to compare"
+complete -c gokanon -n "__fish_seen_subcommand_from profile; and __fish_seen_subcommand_from compare" -o top -d "Number of functions to show"
+complete -c gokanon -n "__fish_seen_subcommand_from profile; and __fish_seen_subcommand_from compare" -o format -d "Output format" -xa "table json"
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o duration -d "How long to run the benchmark"
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o pkg -d "Package defining the benchmark" -r
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o cpu-sample-type -d "CPU profile sample type"
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o mem-sample-type -d "Memory profile sample type" -a "alloc_space alloc_objects inuse_space inuse_objects"
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o gcflags -d "Compiler flags"
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o web -d "Open the flame graph viewer afterwards"
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o port -d "Port of the flame graph viewer"
+complete -c gokanon -n "__fish_seen_subcommand_from profile" -o wait -d "Wait for a run in progress"
+
+# doctor command options
+complete -c gokanon -n "__fish_seen_subcommand_from doctor" -o ci -d "Run the preflight checks of a CI pipeline"
+complete -c gokanon -n "__fish_seen_subcommand_from doctor" -o json -d "Print the results as JSON"
+complete -c gokanon -n "__fish_seen_subcommand_from doctor" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from doctor" -o baseline -d "Baseline that must exist"
+complete -c gokanon -n "__fish_seen_subcommand_from doctor" -o config -d "Configuration file" -r
+
+# stability command options
+complete -c gokanon -n "__fish_seen_subcommand_from stability" -o bench -d "Benchmark filter"
+complete -c gokanon -n "__fish_seen_subcommand_from stability" -o pkg -d "Package path"
+complete -c gokanon -n "__fish_seen_subcommand_from stability" -o count -d "Runs per benchmark"
+complete -c gokanon -n "__fish_seen_subcommand_from stability" -o benchtime -d "Benchmark time"
+complete -c gokanon -n "__fish_seen_subcommand_from stability" -o cv-threshold -d "Coefficient of variation percentage above which a benchmark is noisy"
+complete -c gokanon -n "__fish_seen_subcommand_from stability" -o threshold -d "Regression threshold percentage CI gates on"
+complete -c gokanon -n "__fish_seen_subcommand_from stability" -o config -d "Configuration file" -r
+
+# prune command options
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o keep-last -d "Never delete the newest runs"
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o older-than -d "Delete runs older than this age"
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o profiles-older-than -d "Delete the profiles of runs older than this age"
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o keep-baselines -d "Keep the runs baselines point at" -a "true false"
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o compress -d "Compress the files of older versions"
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o dry-run -d "Show what would be deleted"
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o wait -d "Wait for a run in progress to finish"
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from prune" -o config -d "Configuration file" -r
+
+# search command options
+complete -c gokanon -n "__fish_seen_subcommand_from search" -o limit -d "Show at most this many runs"
+complete -c gokanon -n "__fish_seen_subcommand_from search" -o since -d "Only search runs newer than this age"
+complete -c gokanon -n "__fish_seen_subcommand_from search" -o json -d "Print the matching runs as JSON"
+complete -c gokanon -n "__fish_seen_subcommand_from search" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from search" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from search" -o time-format -d "Timestamp format" -a "default datetime date rfc3339 rfc1123 kitchen unix relative"
+complete -c gokanon -n "__fish_seen_subcommand_from search" -o tz -d "Time zone for timestamps"
+
+# fleet command - subcommands
+complete -c gokanon -f -n "__fish_seen_subcommand_from fleet; and not __fish_seen_subcommand_from report" -a report -d "Regressions of many repositories by owner"
+
+# fleet report options
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o storage -d "Storage directory of a repository" -r
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o remote -d "Bucket of a repository" -r
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o endpoint -d "S3-compatible endpoint" -r
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o region -d "Bucket region" -r
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o owner -d "Owner of a repository, as repo=team" -r
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o baseline -d "Baseline to compare with" -r
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o threshold -d "Maximum allowed degradation (%)" -r
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o format -d "Output format" -a "text json markdown"
+complete -c gokanon -n "__fish_seen_subcommand_from fleet; and __fish_seen_subcommand_from report" -o output -d "Write the report to this file" -r
+
+# interactive command options
+complete -c gokanon -n "__fish_seen_subcommand_from interactive" -o prompt -d "Prompt"
+complete -c gokanon -n "__fish_seen_subcommand_from interactive" -o history -d "History file, or off" -r
+complete -c gokanon -n "__fish_seen_subcommand_from interactive" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from interactive" -o config -d "Configuration file" -r
+
+# analyze command options
+complete -c gokanon -n "__fish_seen_subcommand_from analyze" -o no-cache -d "Ask the AI provider again instead of replaying a cached analysis"
+complete -c gokanon -n "__fish_seen_subcommand_from analyze" -o show-cached -d "Show cached analyses without asking the provider"
+complete -c gokanon -n "__fish_seen_subcommand_from analyze" -o diff -d "Include the code changed between the runs in the prompt"
+complete -c gokanon -n "__fish_seen_subcommand_from analyze" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from analyze" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from analyze" -o time-format -d "Timestamp format" -a "default datetime date rfc3339 rfc1123 kitchen unix relative"
+complete -c gokanon -n "__fish_seen_subcommand_from analyze" -o tz -d "Time zone for timestamps"
+
+# push command options
+complete -c gokanon -n "__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from sync" -o perfdata -d "URL of the perf data server"
+complete -c gokanon -n "__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from sync" -o token -d "Bearer token sent with uploads"
+complete -c gokanon -n "__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from sync" -o all -d "Upload every run not uploaded to the server yet"
+complete -c gokanon -n "__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from sync" -o force -d "Upload runs again even if they were uploaded before"
+complete -c gokanon -n "__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from sync" -o dry-run -d "Print the data that would be uploaded"
+complete -c gokanon -n "__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from sync" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from push; and not __fish_seen_subcommand_from sync" -o config -d "Configuration file" -r
+
+# tui command options
+complete -c gokanon -n "__fish_seen_subcommand_from tui" -o last -d "Only list the last N runs"
+complete -c gokanon -n "__fish_seen_subcommand_from tui" -o window -d "Runs each trend covers"
+complete -c gokanon -n "__fish_seen_subcommand_from tui" -o include-failed -d "Include runs that terminated abnormally"
+complete -c gokanon -n "__fish_seen_subcommand_from tui" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from tui" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from tui" -o time-format -d "Timestamp format" -a "default datetime date rfc3339 rfc1123 kitchen unix relative"
+complete -c gokanon -n "__fish_seen_subcommand_from tui" -o tz -d "Time zone for timestamps"
+
+# snapshot command options
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o name -d "Snapshot name, such as the release version"
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o desc -d "Snapshot description"
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o limit -d "Number of newest runs whose trends are frozen"
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o force -d "Replace an existing snapshot"
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o list -d "List the saved snapshots"
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o storage -d "Storage directory" -r
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o config -d "Configuration file" -r
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o time-format -d "Timestamp format" -a "default datetime date rfc3339 rfc1123 kitchen unix relative"
+complete -c gokanon -n "__fish_seen_subcommand_from snapshot" -o tz -d "Time zone for timestamps"
+
+# telemetry command - subcommands
+complete -c gokanon -f -n "__fish_seen_subcommand_from telemetry; and not __fish_seen_subcommand_from on off status" -a on -d "Count commands and export formats used"
+complete -c gokanon -f -n "__fish_seen_subcommand_from telemetry; and not __fish_seen_subcommand_from on off status" -a off -d "Stop counting and delete pending counts"
+complete -c gokanon -f -n "__fish_seen_subcommand_from telemetry; and not __fish_seen_subcommand_from on off status" -a status -d "Show the report that would be sent"
+
+# plugins command options
+complete -c gokanon -n "__fish_seen_subcommand_from plugins" -o json -d "Print the plugins as JSON"
+
 # completion command options
 complete -c gokanon -f -n "__fish_seen_subcommand_from completion" -a "bash zsh fish" -d "Shell type"
+
@@ -0,0 +1,183 @@
+// Package agent implements workers that join a dashboard controller, run
+// queued benchmark jobs locally and upload the results.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/dashboard"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// ParseLabels parses a comma-separated list of key=value labels
+func ParseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid label %q (expected key=value)", pair)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// FormatLabels formats labels as a sorted key=value list
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// DefaultLabels returns the labels every agent reports unless overridden
+func DefaultLabels() map[string]string {
+	return map[string]string{
+		"os":   runtime.GOOS,
+		"arch": runtime.GOARCH,
+	}
+}
+
+// RunFunc executes a job on the agent and returns the run to upload
+type RunFunc func(req dashboard.JobRequest) (*models.BenchmarkRun, error)
+
+// Agent polls a controller for jobs and runs them
+type Agent struct {
+	client *Client
+	name   string
+	labels map[string]string
+	run    RunFunc
+
+	pollInterval time.Duration
+	logf         func(format string, args ...interface{})
+
+	id string // Assigned by the controller on registration
+}
+
+// New creates an agent joining the controller at the given base URL
+func New(controller, token, name string, labels map[string]string, run RunFunc) *Agent {
+	return &Agent{
+		client:       NewClient(controller, token),
+		name:         name,
+		labels:       labels,
+		run:          run,
+		pollInterval: 5 * time.Second,
+		logf:         func(string, ...interface{}) {},
+	}
+}
+
+// WithPollInterval sets how often the agent asks for work when idle
+func (a *Agent) WithPollInterval(d time.Duration) *Agent {
+	a.pollInterval = d
+	return a
+}
+
+// WithLogger sets the function used to report progress
+func (a *Agent) WithLogger(logf func(format string, args ...interface{})) *Agent {
+	a.logf = logf
+	return a
+}
+
+// Run registers with the controller and processes jobs until ctx is done
+func (a *Agent) Run(ctx context.Context) error {
+	if err := a.register(ctx); err != nil {
+		return err
+	}
+
+	for {
+		job, err := a.claim(ctx)
+		if errors.Is(err, errUnregistered) {
+			a.logf("Controller forgot this agent, registering again")
+			err = a.register(ctx)
+		}
+		if err != nil && ctx.Err() == nil {
+			a.logf("Failed to reach controller: %v", err)
+		}
+
+		if job != nil {
+			a.process(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(a.pollInterval):
+		}
+	}
+}
+
+// process runs a job while keeping the registration alive, then uploads the result
+func (a *Agent) process(ctx context.Context, job *dashboard.Job) {
+	a.logf("Running %s (bench=%s)", job.ID, job.Request.Bench)
+
+	heartbeatCtx, stop := context.WithCancel(ctx)
+	go a.heartbeat(heartbeatCtx)
+	run, err := a.run(job.Request)
+	stop()
+
+	result := dashboard.JobResult{Run: run}
+	if err != nil {
+		result.Error = err.Error()
+		a.logf("%s failed: %v", job.ID, err)
+	} else {
+		a.logf("%s finished as run %s", job.ID, run.ID)
+	}
+
+	if err := a.client.post(ctx, "/api/agents/"+a.id+"/jobs/"+job.ID, result, nil); err != nil {
+		a.logf("Failed to upload result of %s: %v", job.ID, err)
+	}
+}
+
+// heartbeat keeps the agent registered during long runs
+func (a *Agent) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(dashboard.AgentTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.client.post(ctx, "/api/agents/"+a.id+"/heartbeat", nil, nil); err != nil && ctx.Err() == nil {
+				a.logf("Heartbeat failed: %v", err)
+			}
+		}
+	}
+}
+
+// register announces the agent to the controller
+func (a *Agent) register(ctx context.Context) error {
+	var registered dashboard.Agent
+	reg := dashboard.AgentRegistration{Name: a.name, Labels: a.labels}
+	if err := a.client.post(ctx, "/api/agents", reg, &registered); err != nil {
+		return fmt.Errorf("failed to register with %s: %w", a.client.controller, err)
+	}
+	a.id = registered.ID
+	a.logf("Registered with %s as %s", a.client.controller, registered.ID)
+	return nil
+}
+
+// claim asks the controller for the next job. It returns nil if none is waiting.
+func (a *Agent) claim(ctx context.Context) (*dashboard.Job, error) {
+	var job dashboard.Job
+	if err := a.client.post(ctx, "/api/agents/"+a.id+"/claim", nil, &job); err != nil {
+		return nil, err
+	}
+	if job.ID == "" {
+		return nil, nil
+	}
+	return &job, nil
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/dashboard"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"", map[string]string{}, false},
+		{"os=linux,cpu=epyc", map[string]string{"os": "linux", "cpu": "epyc"}, false},
+		{" os = linux , ", map[string]string{"os": "linux"}, false},
+		{"os", nil, true},
+		{"=linux", nil, true},
+		{"os=", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLabels(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLabels(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLabels(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	got := FormatLabels(map[string]string{"os": "linux", "cpu": "epyc"})
+	if got != "cpu=epyc,os=linux" {
+		t.Errorf("FormatLabels() = %q, want %q", got, "cpu=epyc,os=linux")
+	}
+}
+
+func TestAgentRunsJobs(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	server := dashboard.NewServer(store, "localhost", 0)
+	server.EnableAgents("secret")
+	controller := httptest.NewServer(server.Handler())
+	defer controller.Close()
+
+	ran := make(chan dashboard.JobRequest, 1)
+	run := func(req dashboard.JobRequest) (*models.BenchmarkRun, error) {
+		ran <- req
+		return &models.BenchmarkRun{
+			ID:        "run-agent",
+			Timestamp: time.Now(),
+			Results:   []models.BenchmarkResult{{Name: "Sum-8", NsPerOp: 10}},
+		}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := New(controller.URL, "secret", "test", map[string]string{"os": "linux", "cpu": "epyc"}, run).
+		WithPollInterval(10 * time.Millisecond)
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+
+	// The job is queued through the public API, like 'gokanon run -on' does
+	client := NewClient(controller.URL, "secret")
+	job, err := client.Submit(ctx, dashboard.JobRequest{Bench: "Sum", Labels: map[string]string{"cpu": "epyc"}})
+	if err != nil {
+		t.Fatalf("failed to queue job: %v", err)
+	}
+
+	select {
+	case req := <-ran:
+		if req.Bench != "Sum" {
+			t.Errorf("agent ran bench %q, want Sum", req.Bench)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not run the job")
+	}
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelWait()
+	job, err = client.Wait(waitCtx, job.ID, 10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	if job.Status != dashboard.JobDone || job.RunID != "run-agent" {
+		t.Fatalf("unexpected finished job: %+v", job)
+	}
+
+	saved, err := client.Run(ctx, job.RunID)
+	if err != nil {
+		t.Fatalf("failed to download run: %v", err)
+	}
+	if saved.Agent != "test" || len(saved.Results) != 1 {
+		t.Errorf("unexpected downloaded run: %+v", saved)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() returned %v", err)
+	}
+}
+
+func TestAgentRejectedToken(t *testing.T) {
+	server := dashboard.NewServer(storage.NewStorage(t.TempDir()), "localhost", 0)
+	server.EnableAgents("secret")
+	controller := httptest.NewServer(server.Handler())
+	defer controller.Close()
+
+	a := New(controller.URL, "wrong", "test", nil, nil)
+	if err := a.Run(context.Background()); err == nil {
+		t.Error("expected registration with a wrong token to fail")
+	}
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/dashboard"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// errUnregistered is returned when the controller no longer knows the agent
+var errUnregistered = errors.New("agent is not registered with the controller")
+
+// Client talks to the job API of a controller
+type Client struct {
+	controller string
+	token      string
+	http       *http.Client
+}
+
+// NewClient creates a client for the controller at the given base URL
+func NewClient(controller, token string) *Client {
+	return &Client{
+		controller: strings.TrimRight(controller, "/"),
+		token:      token,
+		http:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Submit queues a job on the controller
+func (c *Client) Submit(ctx context.Context, req dashboard.JobRequest) (dashboard.Job, error) {
+	var job dashboard.Job
+	err := c.post(ctx, "/api/jobs", req, &job)
+	return job, err
+}
+
+// Job fetches the current state of a job
+func (c *Client) Job(ctx context.Context, id string) (dashboard.Job, error) {
+	var job dashboard.Job
+	err := c.get(ctx, "/api/jobs/"+url.PathEscape(id), &job)
+	return job, err
+}
+
+// Wait polls a job until it is done or failed. If update is not nil, it is
+// called with every state seen.
+func (c *Client) Wait(ctx context.Context, id string, interval time.Duration, update func(dashboard.Job)) (dashboard.Job, error) {
+	for {
+		job, err := c.Job(ctx, id)
+		if err != nil {
+			return job, err
+		}
+		if update != nil {
+			update(job)
+		}
+		if job.Status == dashboard.JobDone || job.Status == dashboard.JobFailed {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Run fetches a saved run from the controller
+func (c *Client) Run(ctx context.Context, id string) (*models.BenchmarkRun, error) {
+	var run models.BenchmarkRun
+	if err := c.get(ctx, "/api/runs/"+url.PathEscape(id), &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Agents lists the agents connected to the controller
+func (c *Client) Agents(ctx context.Context) ([]dashboard.Agent, error) {
+	var agents []dashboard.Agent
+	err := c.get(ctx, "/api/agents", &agents)
+	return agents, err
+}
+
+// get decodes the response of a GET request into out
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+// post sends body as JSON and decodes the response into out, if any
+func (c *Client) post(ctx context.Context, path string, body, out interface{}) error {
+	return c.do(ctx, http.MethodPost, path, body, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.controller+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusGone:
+		return errUnregistered
+	case resp.StatusCode == http.StatusNoContent:
+		return nil
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
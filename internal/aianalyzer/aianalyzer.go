@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alenon/gokanon/internal/models"
 )
@@ -15,12 +18,20 @@ type Config struct {
 	Model    string // Model name to use
 	APIKey   string // API key for cloud providers (not needed for Ollama)
 	BaseURL  string // Base URL for the provider
+
+	Timeout   time.Duration // Timeout of a request to the provider (default 60s)
+	Retries   int           // Attempts after a failed request, backing off exponentially
+	Fallbacks []Config      // Providers asked in order when the provider fails
 }
 
 // Analyzer provides AI-powered analysis of benchmark results
 type Analyzer struct {
-	config   Config
-	provider AIProvider
+	config    Config
+	provider  AIProvider
+	fallbacks []fallback
+	cache     Cache
+	refresh   bool   // Ask the provider even when the cache holds an answer
+	diff      string // Code changes between the runs compared
 }
 
 // NewAnalyzer creates a new AI analyzer
@@ -29,6 +40,27 @@ func NewAnalyzer(config Config) (*Analyzer, error) {
 		return &Analyzer{config: config}, nil
 	}
 
+	provider, err := newProvider(config)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzer := &Analyzer{
+		config:   config,
+		provider: provider,
+	}
+	for _, fallbackConfig := range config.Fallbacks {
+		fallbackProvider, err := newProvider(fallbackConfig)
+		if err != nil {
+			return nil, fmt.Errorf("fallback %s: %w", fallbackConfig.Provider, err)
+		}
+		analyzer.fallbacks = append(analyzer.fallbacks, fallback{config: fallbackConfig, provider: fallbackProvider})
+	}
+	return analyzer, nil
+}
+
+// newProvider creates the provider a configuration names
+func newProvider(config Config) (AIProvider, error) {
 	var provider AIProvider
 	var err error
 
@@ -52,23 +84,73 @@ func NewAnalyzer(config Config) (*Analyzer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize AI provider: %w", err)
 	}
-
-	return &Analyzer{
-		config:   config,
-		provider: provider,
-	}, nil
+	return provider, nil
 }
 
 // NewFromEnv creates an analyzer from environment variables
 func NewFromEnv() (*Analyzer, error) {
+	return NewAnalyzer(ConfigFromEnv(Config{}))
+}
+
+// ConfigFromEnv returns the analyzer configuration set by environment
+// variables. Variables that are not set fall back to the given defaults,
+// such as those of the project configuration, and then to the defaults of
+// the provider. GOKANON_AI_FALLBACK lists fallback providers, each with an
+// optional model, as in "openai:gpt-4o,groq"; their API keys are read from
+// GOKANON_AI_<PROVIDER>_API_KEY only, so that a key is never sent to
+// another provider.
+func ConfigFromEnv(defaults Config) Config {
+	enabled := defaults.Enabled
+	if value := os.Getenv("GOKANON_AI_ENABLED"); value != "" {
+		enabled = value == "true"
+	}
+	if defaults.Provider == "" {
+		defaults.Provider = "ollama"
+	}
 	config := Config{
-		Enabled:  os.Getenv("GOKANON_AI_ENABLED") == "true",
-		Provider: getEnvWithDefault("GOKANON_AI_PROVIDER", "ollama"),
-		Model:    getEnvWithDefault("GOKANON_AI_MODEL", ""),
-		APIKey:   os.Getenv("GOKANON_AI_API_KEY"),
-		BaseURL:  getEnvWithDefault("GOKANON_AI_BASE_URL", ""),
+		Enabled:  enabled,
+		Provider: getEnvWithDefault("GOKANON_AI_PROVIDER", defaults.Provider),
+		Model:    getEnvWithDefault("GOKANON_AI_MODEL", defaults.Model),
+		APIKey:   getEnvWithDefault("GOKANON_AI_API_KEY", defaults.APIKey),
+		BaseURL:  getEnvWithDefault("GOKANON_AI_BASE_URL", defaults.BaseURL),
+
+		Timeout:   defaults.Timeout,
+		Retries:   defaults.Retries,
+		Fallbacks: defaults.Fallbacks,
+	}
+	if d, err := time.ParseDuration(os.Getenv("GOKANON_AI_TIMEOUT")); err == nil && d > 0 {
+		config.Timeout = d
 	}
+	if n, err := strconv.Atoi(os.Getenv("GOKANON_AI_RETRIES")); err == nil && n >= 0 {
+		config.Retries = n
+	}
+	if value := os.Getenv("GOKANON_AI_FALLBACK"); value != "" {
+		config.Fallbacks = nil
+		for _, item := range strings.Split(value, ",") {
+			provider, model, _ := strings.Cut(strings.TrimSpace(item), ":")
+			if provider != "" {
+				config.Fallbacks = append(config.Fallbacks, Config{Enabled: true, Provider: provider, Model: model})
+			}
+		}
+	}
+	var fallbacks []Config
+	for _, fallback := range config.Fallbacks {
+		fallback.APIKey = os.Getenv("GOKANON_AI_" + envName(fallback.Provider) + "_API_KEY")
+		fallbacks = append(fallbacks, withProviderDefaults(fallback))
+	}
+	config.Fallbacks = fallbacks
 
+	return withProviderDefaults(config)
+}
+
+// envName returns a provider name as used in environment variable names
+func envName(provider string) string {
+	return strings.ToUpper(strings.ReplaceAll(provider, "-", "_"))
+}
+
+// withProviderDefaults sets the model and base URL of the provider when
+// they are not set
+func withProviderDefaults(config Config) Config {
 	// Set default models if not specified
 	if config.Model == "" {
 		switch config.Provider {
@@ -105,11 +187,17 @@ func NewFromEnv() (*Analyzer, error) {
 		}
 	}
 
-	return NewAnalyzer(config)
+	return config
 }
 
 // EnhanceProfileSummary enhances a profile summary with AI insights
 func (a *Analyzer) EnhanceProfileSummary(summary *models.ProfileSummary) (*models.ProfileSummary, error) {
+	return a.EnhanceRunProfile("", summary)
+}
+
+// EnhanceRunProfile enhances the profile summary of a run with AI
+// insights, caching the analysis under the run's ID
+func (a *Analyzer) EnhanceRunProfile(runID string, summary *models.ProfileSummary) (*models.ProfileSummary, error) {
 	if !a.config.Enabled || a.provider == nil {
 		return summary, nil
 	}
@@ -122,13 +210,17 @@ func (a *Analyzer) EnhanceProfileSummary(summary *models.ProfileSummary) (*model
 
 	// Get AI analysis
 	prompt := buildProfileAnalysisPrompt(context)
-	response, err := a.provider.Analyze(prompt)
+	var runIDs []string
+	if runID != "" {
+		runIDs = []string{runID}
+	}
+	analysis, _, err := a.analyze(KindProfile, runIDs, prompt)
 	if err != nil {
 		return summary, fmt.Errorf("AI analysis failed: %w", err)
 	}
 
 	// Parse AI response and enhance suggestions
-	enhancedSuggestions, err := a.parseAISuggestions(response, summary)
+	enhancedSuggestions, err := a.parseAISuggestions(analysis.Response, summary)
 	if err != nil {
 		// If parsing fails, keep original suggestions
 		return summary, fmt.Errorf("failed to parse AI suggestions: %w", err)
@@ -142,21 +234,39 @@ func (a *Analyzer) EnhanceProfileSummary(summary *models.ProfileSummary) (*model
 
 // AnalyzeComparison provides AI insights on benchmark comparison
 func (a *Analyzer) AnalyzeComparison(oldRun, newRun *models.BenchmarkRun, comparisons []models.Comparison) (string, error) {
+	analysis, _, err := a.ComparisonAnalysis(oldRun, newRun, comparisons)
+	if err != nil || analysis == nil {
+		return "", err
+	}
+	return analysis.Response, nil
+}
+
+// ComparisonAnalysis is AnalyzeComparison returning the whole analysis,
+// with the provider and model that answered it, and whether it came from
+// the cache. It returns nil when the analyzer is disabled. Regressions are
+// analyzed for their root cause when the new run was profiled or a code
+// diff was given.
+func (a *Analyzer) ComparisonAnalysis(oldRun, newRun *models.BenchmarkRun, comparisons []models.Comparison) (*models.AIAnalysis, bool, error) {
 	if !a.config.Enabled || a.provider == nil {
-		return "", nil
+		return nil, false, nil
 	}
 
-	// Prepare comparison context
-	context := a.prepareComparisonContext(oldRun, newRun, comparisons)
+	kind := KindComparison
+	var prompt string
+	if hasRegressions(comparisons) && (newRun.ProfileSummary != nil || a.diff != "") {
+		kind = KindRootCause
+		prompt = buildRootCausePrompt(a.prepareRootCauseContext(oldRun, newRun, comparisons), truncateDiff(a.diff))
+	} else {
+		prompt = buildComparisonAnalysisPrompt(a.prepareComparisonContext(oldRun, newRun, comparisons))
+	}
 
 	// Get AI analysis
-	prompt := buildComparisonAnalysisPrompt(context)
-	response, err := a.provider.Analyze(prompt)
+	analysis, cached, err := a.analyze(kind, []string{oldRun.ID, newRun.ID}, prompt)
 	if err != nil {
-		return "", fmt.Errorf("AI comparison analysis failed: %w", err)
+		return nil, false, fmt.Errorf("AI comparison analysis failed: %w", err)
 	}
 
-	return response, nil
+	return analysis, cached, nil
 }
 
 // prepareProfileContext converts profile summary to AI-friendly format
@@ -165,6 +275,7 @@ func (a *Analyzer) prepareProfileContext(summary *models.ProfileSummary) (string
 		"cpu_top_functions":    summary.CPUTopFunctions,
 		"memory_top_functions": summary.MemoryTopFunctions,
 		"memory_leaks":         summary.MemoryLeaks,
+		"memory_growth":        summary.MemoryGrowth,
 		"hot_paths":            summary.HotPaths,
 		"total_cpu_samples":    summary.TotalCPUSamples,
 		"total_memory_bytes":   summary.TotalMemoryBytes,
@@ -217,6 +328,7 @@ func (a *Analyzer) parseAISuggestions(aiResponse string, summary *models.Profile
 	// If we can't parse, add the raw AI response as a general suggestion
 	if aiResponse != "" {
 		general := models.Suggestion{
+			RuleID:     RuleAI,
 			Type:       "general",
 			Severity:   "info",
 			Function:   "Overall Analysis",
@@ -230,6 +342,10 @@ func (a *Analyzer) parseAISuggestions(aiResponse string, summary *models.Profile
 	return summary.Suggestions, nil
 }
 
+// RuleAI identifies suggestions made by the AI provider rather than by the
+// profiler's rules
+const RuleAI = "ai-analysis"
+
 // mergeSuggestions combines original and AI suggestions, removing duplicates
 func (a *Analyzer) mergeSuggestions(original, ai []models.Suggestion) []models.Suggestion {
 	// Use a map to track suggestions by function+type to avoid duplicates
@@ -253,6 +369,9 @@ func (a *Analyzer) mergeSuggestions(original, ai []models.Suggestion) []models.S
 			if s.Impact == "" {
 				s.Impact = "AI-suggested optimization"
 			}
+			if s.RuleID == "" {
+				s.RuleID = RuleAI
+			}
 			merged = append(merged, s)
 			seen[key] = true
 		}
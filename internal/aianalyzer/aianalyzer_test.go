@@ -119,6 +119,29 @@ func TestNewFromEnv(t *testing.T) {
 	}
 }
 
+func TestConfigFromEnvDefaults(t *testing.T) {
+	t.Setenv("GOKANON_AI_ENABLED", "")
+	t.Setenv("GOKANON_AI_PROVIDER", "")
+	t.Setenv("GOKANON_AI_MODEL", "")
+	t.Setenv("GOKANON_AI_BASE_URL", "")
+
+	config := ConfigFromEnv(Config{Enabled: true, Provider: "groq"})
+	if !config.Enabled || config.Provider != "groq" {
+		t.Errorf("Expected the defaults, got %+v", config)
+	}
+	if config.Model != "llama-3.3-70b-versatile" || config.BaseURL != "https://api.groq.com/openai/v1" {
+		t.Errorf("Expected the provider defaults, got %+v", config)
+	}
+
+	// Environment variables take precedence
+	t.Setenv("GOKANON_AI_ENABLED", "false")
+	t.Setenv("GOKANON_AI_PROVIDER", "ollama")
+	config = ConfigFromEnv(Config{Enabled: true, Provider: "groq", Model: "custom"})
+	if config.Enabled || config.Provider != "ollama" || config.Model != "custom" {
+		t.Errorf("Expected the environment to win, got %+v", config)
+	}
+}
+
 func TestGetEnvWithDefault(t *testing.T) {
 	tests := []struct {
 		name         string
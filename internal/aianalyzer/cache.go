@@ -0,0 +1,80 @@
+package aianalyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Kinds of cached analyses
+const (
+	KindComparison = "comparison"
+	KindProfile    = "profile"
+)
+
+// Cache keeps the analyses answered by the provider, such as the storage
+// holding the runs analyzed
+type Cache interface {
+	LoadAnalysis(key string) (*models.AIAnalysis, error)
+	SaveAnalysis(analysis *models.AIAnalysis) error
+}
+
+// WithCache makes the analyzer answer questions it asked before from the
+// cache, and cache new answers. With refresh the provider is asked again
+// and the cached answer replaced.
+func (a *Analyzer) WithCache(cache Cache, refresh bool) *Analyzer {
+	a.cache = cache
+	a.refresh = refresh
+	return a
+}
+
+// AnalysisKey identifies an analysis by the runs analyzed, the provider
+// and model answering, and the prompt, so that a changed prompt template
+// or model asks the provider again
+func AnalysisKey(runIDs []string, provider, model, promptHash string) string {
+	sum := sha256.Sum256([]byte(strings.Join(runIDs, ",") + "\n" + provider + "\n" + model + "\n" + promptHash))
+	return hex.EncodeToString(sum[:16])
+}
+
+// hashPrompt returns the hex-encoded SHA-256 of a prompt
+func hashPrompt(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// analyze asks the provider a question about runs, unless the cache holds
+// its answer. It reports whether the answer came from the cache. An answer
+// of a fallback is cached under the key of the provider, so that it is
+// replayed rather than asked again of the provider that failed.
+func (a *Analyzer) analyze(kind string, runIDs []string, prompt string) (*models.AIAnalysis, bool, error) {
+	promptHash := hashPrompt(prompt)
+	key := AnalysisKey(runIDs, a.config.Provider, a.config.Model, promptHash)
+	if a.cache != nil && !a.refresh {
+		if cached, err := a.cache.LoadAnalysis(key); err == nil {
+			return cached, true, nil
+		}
+	}
+
+	response, answered, err := a.ask(prompt)
+	if err != nil {
+		return nil, false, err
+	}
+	analysis := &models.AIAnalysis{
+		Key:        key,
+		Kind:       kind,
+		RunIDs:     runIDs,
+		Provider:   answered.Provider,
+		Model:      answered.Model,
+		PromptHash: promptHash,
+		Response:   response,
+		CreatedAt:  time.Now(),
+	}
+	if a.cache != nil && response != "" {
+		// An answer that could not be cached is asked for again next time
+		a.cache.SaveAnalysis(analysis)
+	}
+	return analysis, false, nil
+}
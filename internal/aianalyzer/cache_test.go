@@ -0,0 +1,97 @@
+package aianalyzer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// memoryCache is a Cache in memory
+type memoryCache map[string]*models.AIAnalysis
+
+func (c memoryCache) LoadAnalysis(key string) (*models.AIAnalysis, error) {
+	if analysis, ok := c[key]; ok {
+		return analysis, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (c memoryCache) SaveAnalysis(analysis *models.AIAnalysis) error {
+	c[analysis.Key] = analysis
+	return nil
+}
+
+// countingProvider answers with the number of questions asked so far
+type countingProvider struct {
+	asked int
+}
+
+func (p *countingProvider) Analyze(prompt string) (string, error) {
+	p.asked++
+	return "answer " + string(rune('0'+p.asked)), nil
+}
+
+func TestComparisonAnalysisCache(t *testing.T) {
+	provider := &countingProvider{}
+	cache := memoryCache{}
+	analyzer := (&Analyzer{config: Config{Enabled: true, Provider: "ollama", Model: "llama3.2"}, provider: provider}).WithCache(cache, false)
+
+	oldRun := &models.BenchmarkRun{ID: "run-1"}
+	newRun := &models.BenchmarkRun{ID: "run-2"}
+	comparisons := []models.Comparison{{Name: "Parse", OldNsPerOp: 100, NewNsPerOp: 120, DeltaPercent: 20, Status: "degraded"}}
+
+	analysis, cached, err := analyzer.ComparisonAnalysis(oldRun, newRun, comparisons)
+	if err != nil || cached || analysis.Response != "answer 1" {
+		t.Fatalf("First analysis = %+v, cached %v, error %v", analysis, cached, err)
+	}
+	if analysis.Kind != KindComparison || len(analysis.RunIDs) != 2 || analysis.Model != "llama3.2" || len(cache) != 1 {
+		t.Errorf("Cached analysis = %+v", analysis)
+	}
+
+	// The same question is answered from the cache
+	analysis, cached, err = analyzer.ComparisonAnalysis(oldRun, newRun, comparisons)
+	if err != nil || !cached || analysis.Response != "answer 1" || provider.asked != 1 {
+		t.Errorf("Repeated analysis = %+v, cached %v, asked %d", analysis, cached, provider.asked)
+	}
+
+	// Another model, or other comparisons, ask again
+	analyzer.config.Model = "llama3.3"
+	if analysis, cached, _ = analyzer.ComparisonAnalysis(oldRun, newRun, comparisons); cached || analysis.Response != "answer 2" {
+		t.Errorf("Analysis by another model = %+v, cached %v", analysis, cached)
+	}
+	comparisons[0].NewNsPerOp = 130
+	if _, cached, _ = analyzer.ComparisonAnalysis(oldRun, newRun, comparisons); cached {
+		t.Error("Analysis of other comparisons came from the cache")
+	}
+
+	// A refresh asks again and replaces the cached answer
+	analyzer.WithCache(cache, true)
+	if analysis, cached, _ = analyzer.ComparisonAnalysis(oldRun, newRun, comparisons); cached || analysis.Response != "answer 4" {
+		t.Errorf("Refreshed analysis = %+v, cached %v", analysis, cached)
+	}
+	if cache[analysis.Key].Response != "answer 4" {
+		t.Errorf("Cache holds %q after a refresh", cache[analysis.Key].Response)
+	}
+}
+
+func TestEnhanceRunProfileCache(t *testing.T) {
+	provider := &countingProvider{}
+	cache := memoryCache{}
+	analyzer := (&Analyzer{config: Config{Enabled: true}, provider: provider}).WithCache(cache, false)
+
+	summary := &models.ProfileSummary{TotalCPUSamples: 1000}
+	for range 2 {
+		if _, err := analyzer.EnhanceRunProfile("run-1", summary); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if provider.asked != 1 || len(cache) != 1 {
+		t.Fatalf("Asked %d times, %d analyses cached", provider.asked, len(cache))
+	}
+	for _, analysis := range cache {
+		if analysis.Kind != KindProfile || len(analysis.RunIDs) != 1 || analysis.RunIDs[0] != "run-1" {
+			t.Errorf("Cached analysis = %+v", analysis)
+		}
+	}
+}
@@ -38,7 +38,7 @@ Respond with a JSON array of suggestions in this format:
 
 Focus on the most impactful optimizations. Consider:
 - Hot functions consuming significant CPU/memory
-- Potential memory leaks (high allocation with low in-use memory)
+- Potential memory leaks (in-use memory growing after warmup, or high allocation with low in-use memory)
 - Hot paths that could be optimized
 - Common Go performance patterns (e.g., unnecessary allocations, inefficient algorithms)
 - Opportunities for sync.Pool, buffering, or pre-allocation
@@ -53,6 +53,9 @@ func buildComparisonAnalysisPrompt(context string) string {
 COMPARISON DATA:
 %s
 
+Where present, the "doc" field of a comparison is the doc comment of the
+benchmark function and describes what the benchmark measures.
+
 Please analyze the performance changes and provide insights about:
 1. Significant improvements or regressions
 2. Possible causes for the changes
@@ -62,6 +65,35 @@ Please analyze the performance changes and provide insights about:
 Provide a concise analysis (2-3 paragraphs) focusing on the most important findings.`, context)
 }
 
+// buildRootCausePrompt creates a prompt for finding the root cause of
+// regressions from the profiles of the runs and the code changed between
+// them
+func buildRootCausePrompt(context, diff string) string {
+	if diff == "" {
+		diff = "(not available)"
+	}
+	return fmt.Sprintf(`You are finding the root cause of performance regressions between two Go benchmark runs.
+
+REGRESSIONS AND PROFILES:
+%s
+
+The "profile" of each run, where present, lists the functions taking the
+most CPU time and allocating the most memory, the hottest call paths,
+memory leak candidates and lock contention. Compare the profiles of the
+two runs to find what started taking more time or memory.
+
+CODE CHANGES BETWEEN THE COMMITS OF THE RUNS:
+%s
+
+For each regression:
+1. Name the most likely root cause, pointing at the functions of the profiles and the lines of the code changes involved
+2. Explain how the change leads to the slowdown or extra allocations
+3. Suggest a specific fix, with a code sketch where it helps
+4. Say how confident you are, and what to measure to confirm it
+
+Regressions with the same cause can be explained together. Do not speculate about code that is not shown; say so when the profiles and changes do not explain a regression.`, context, diff)
+}
+
 // parseTextSuggestions attempts to parse suggestions from markdown/text format
 func parseTextSuggestions(text string) []models.Suggestion {
 	var suggestions []models.Suggestion
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 )
 
 // AIProvider is the interface for AI service providers
@@ -27,7 +26,7 @@ func NewOllamaProvider(config Config) (*OllamaProvider, error) {
 		baseURL: config.BaseURL,
 		model:   config.Model,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: config.requestTimeout(),
 		},
 	}, nil
 }
@@ -91,7 +90,7 @@ func NewGroqProvider(config Config) (*GroqProvider, error) {
 		model:   config.Model,
 		apiKey:  config.APIKey,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: config.requestTimeout(),
 		},
 	}, nil
 }
@@ -177,7 +176,7 @@ func NewOpenAIProvider(config Config) (*OpenAIProvider, error) {
 		model:   config.Model,
 		apiKey:  config.APIKey,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: config.requestTimeout(),
 		},
 	}, nil
 }
@@ -263,7 +262,7 @@ func NewAnthropicProvider(config Config) (*AnthropicProvider, error) {
 		model:   config.Model,
 		apiKey:  config.APIKey,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: config.requestTimeout(),
 		},
 	}, nil
 }
@@ -356,7 +355,7 @@ func NewOpenAICompatibleProvider(config Config) (*OpenAICompatibleProvider, erro
 		model:   config.Model,
 		apiKey:  config.APIKey,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: config.requestTimeout(),
 		},
 	}, nil
 }
@@ -449,7 +448,7 @@ func NewGeminiProvider(config Config) (*GeminiProvider, error) {
 		model:   config.Model,
 		apiKey:  config.APIKey,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: config.requestTimeout(),
 		},
 	}, nil
 }
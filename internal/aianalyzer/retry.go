@@ -0,0 +1,65 @@
+package aianalyzer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultTimeout bounds a request to a provider whose timeout is not set
+const DefaultTimeout = 60 * time.Second
+
+// retryBackoff is the delay before the first retry, doubled before each
+// further one
+var retryBackoff = time.Second
+
+// fallback is a provider asked when the ones before it failed
+type fallback struct {
+	config   Config
+	provider AIProvider
+}
+
+// requestTimeout returns the timeout of requests to the provider
+func (c Config) requestTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// ask sends a prompt to the provider, retrying failed requests, and then
+// to each fallback in turn. It returns the configuration of the provider
+// that answered.
+func (a *Analyzer) ask(prompt string) (string, Config, error) {
+	chain := append([]fallback{{config: a.config, provider: a.provider}}, a.fallbacks...)
+
+	var errs []error
+	for _, link := range chain {
+		response, err := askWithRetries(link.provider, prompt, a.config.Retries)
+		if err == nil {
+			return response, link.config, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", link.config.Provider, err))
+	}
+	if len(chain) == 1 {
+		return "", a.config, errors.Unwrap(errs[0])
+	}
+	return "", a.config, fmt.Errorf("all AI providers failed: %w", errors.Join(errs...))
+}
+
+// askWithRetries sends a prompt to a provider, retrying up to retries times
+// with exponential backoff
+func askWithRetries(provider AIProvider, prompt string, retries int) (string, error) {
+	delay := retryBackoff
+	for attempt := 0; ; attempt++ {
+		response, err := provider.Analyze(prompt)
+		if err == nil || attempt >= retries {
+			if err != nil && retries > 0 {
+				err = fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+			}
+			return response, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
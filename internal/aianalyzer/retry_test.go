@@ -0,0 +1,130 @@
+package aianalyzer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// flakyProvider fails the first failures questions, then answers
+type flakyProvider struct {
+	failures int
+	asked    int
+	answer   string
+}
+
+func (p *flakyProvider) Analyze(prompt string) (string, error) {
+	p.asked++
+	if p.asked <= p.failures {
+		return "", errors.New("connection refused")
+	}
+	return p.answer, nil
+}
+
+func withFastBackoff(t *testing.T) {
+	t.Helper()
+	old := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = old })
+}
+
+func TestRetries(t *testing.T) {
+	withFastBackoff(t)
+	provider := &flakyProvider{failures: 2, answer: "slower"}
+	analyzer := &Analyzer{config: Config{Enabled: true, Provider: "ollama", Retries: 2}, provider: provider}
+
+	response, err := analyzer.AnalyzeComparison(&models.BenchmarkRun{ID: "a"}, &models.BenchmarkRun{ID: "b"}, nil)
+	if err != nil || response != "slower" || provider.asked != 3 {
+		t.Errorf("AnalyzeComparison = %q, %v after %d attempts, want an answer on the third", response, err, provider.asked)
+	}
+
+	// Without retries the first failure is returned as is
+	provider = &flakyProvider{failures: 1, answer: "slower"}
+	analyzer = &Analyzer{config: Config{Enabled: true, Provider: "ollama"}, provider: provider}
+	_, err = analyzer.AnalyzeComparison(&models.BenchmarkRun{ID: "a"}, &models.BenchmarkRun{ID: "b"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "attempts") || provider.asked != 1 {
+		t.Errorf("Unexpected error %v after %d attempts", err, provider.asked)
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	withFastBackoff(t)
+	local := &flakyProvider{failures: 10}
+	remote := &flakyProvider{answer: "from openai"}
+	analyzer := &Analyzer{
+		config:    Config{Enabled: true, Provider: "ollama", Model: "llama3.2", Retries: 1},
+		provider:  local,
+		fallbacks: []fallback{{config: Config{Provider: "openai", Model: "gpt-4o"}, provider: remote}},
+	}
+
+	analysis, _, err := analyzer.ComparisonAnalysis(&models.BenchmarkRun{ID: "a"}, &models.BenchmarkRun{ID: "b"}, nil)
+	if err != nil || analysis.Response != "from openai" {
+		t.Fatalf("ComparisonAnalysis = %+v, %v, want the fallback's answer", analysis, err)
+	}
+	if analysis.Provider != "openai" || analysis.Model != "gpt-4o" || local.asked != 2 {
+		t.Errorf("Analysis answered by %s/%s after %d attempts of ollama", analysis.Provider, analysis.Model, local.asked)
+	}
+
+	// The errors of every provider are reported when all fail
+	remote.failures = 10
+	remote.asked = 0
+	_, _, err = analyzer.ComparisonAnalysis(&models.BenchmarkRun{ID: "a"}, &models.BenchmarkRun{ID: "b"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "all AI providers failed") || !strings.Contains(err.Error(), "ollama:") || !strings.Contains(err.Error(), "openai:") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"response":"late"}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewOllamaProvider(Config{BaseURL: server.URL, Model: "llama3.2", Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Analyze("prompt"); err == nil {
+		t.Error("Expected the request to time out")
+	}
+	if (Config{}).requestTimeout() != DefaultTimeout {
+		t.Errorf("Default timeout = %s, want %s", (Config{}).requestTimeout(), DefaultTimeout)
+	}
+}
+
+func TestConfigFromEnvFallbacks(t *testing.T) {
+	t.Setenv("GOKANON_AI_PROVIDER", "ollama")
+	t.Setenv("GOKANON_AI_API_KEY", "primary-key")
+	t.Setenv("GOKANON_AI_TIMEOUT", "90s")
+	t.Setenv("GOKANON_AI_RETRIES", "3")
+	t.Setenv("GOKANON_AI_FALLBACK", "openai:gpt-4o-mini, groq")
+	t.Setenv("GOKANON_AI_OPENAI_API_KEY", "openai-key")
+	t.Setenv("GOKANON_AI_GROQ_API_KEY", "")
+
+	config := ConfigFromEnv(Config{Fallbacks: []Config{{Provider: "gemini"}}})
+	if config.Timeout != 90*time.Second || config.Retries != 3 {
+		t.Errorf("Timeout = %s, retries = %d", config.Timeout, config.Retries)
+	}
+	if len(config.Fallbacks) != 2 {
+		t.Fatalf("Fallbacks = %+v, want the two of GOKANON_AI_FALLBACK", config.Fallbacks)
+	}
+	openai, groq := config.Fallbacks[0], config.Fallbacks[1]
+	if openai.Provider != "openai" || openai.Model != "gpt-4o-mini" || openai.APIKey != "openai-key" || openai.BaseURL != "https://api.openai.com" {
+		t.Errorf("Unexpected OpenAI fallback: %+v", openai)
+	}
+	// The key of the provider is never sent to a fallback
+	if groq.Model != "llama-3.3-70b-versatile" || groq.APIKey != "" {
+		t.Errorf("Unexpected Groq fallback: %+v", groq)
+	}
+
+	// A fallback that cannot be set up fails the analyzer
+	if _, err := NewAnalyzer(Config{Enabled: true, Provider: "ollama", Fallbacks: []Config{groq}}); err == nil || !strings.Contains(err.Error(), "fallback groq") {
+		t.Errorf("Expected an error for the Groq fallback without a key, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package aianalyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// KindRootCause is the kind of analyses explaining regressions from the
+// profiles and code changes of the runs compared
+const KindRootCause = "root-cause"
+
+// maxDiffBytes bounds the code diff put in a prompt, so that it fits the
+// context window of small local models
+const maxDiffBytes = 24 << 10
+
+// WithCodeDiff adds the diff of the code between the commits of the runs
+// compared to the prompt when they have regressions
+func (a *Analyzer) WithCodeDiff(diff string) *Analyzer {
+	a.diff = diff
+	return a
+}
+
+// hasRegressions reports whether any benchmark got slower
+func hasRegressions(comparisons []models.Comparison) bool {
+	for _, c := range comparisons {
+		if c.Status == "degraded" {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareRootCauseContext converts the regressions and the profiles of
+// the runs to AI-friendly format
+func (a *Analyzer) prepareRootCauseContext(oldRun, newRun *models.BenchmarkRun, comparisons []models.Comparison) string {
+	var regressions []models.Comparison
+	for _, c := range comparisons {
+		if c.Status == "degraded" {
+			regressions = append(regressions, c)
+		}
+	}
+	context := map[string]interface{}{
+		"old_run":     runContext(oldRun),
+		"new_run":     runContext(newRun),
+		"regressions": regressions,
+	}
+	data, _ := json.MarshalIndent(context, "", "  ")
+	return string(data)
+}
+
+// runContext returns what the root-cause prompt tells about a run: its
+// commit and where its time and memory went
+func runContext(run *models.BenchmarkRun) map[string]interface{} {
+	context := map[string]interface{}{
+		"commit":     run.Commit,
+		"go_version": run.GoVersion,
+		"package":    run.Package,
+	}
+	if s := run.ProfileSummary; s != nil {
+		context["profile"] = map[string]interface{}{
+			"cpu_top_functions":    s.CPUTopFunctions,
+			"memory_top_functions": s.MemoryTopFunctions,
+			"memory_leaks":         s.MemoryLeaks,
+			"hot_paths":            s.HotPaths,
+			"contention_hotspots":  s.ContentionHotspots,
+		}
+	}
+	return context
+}
+
+// truncateDiff cuts a diff to maxDiffBytes at a line boundary, saying so
+func truncateDiff(diff string) string {
+	if len(diff) <= maxDiffBytes {
+		return diff
+	}
+	cut := strings.LastIndexByte(diff[:maxDiffBytes], '\n')
+	if cut < 0 {
+		cut = maxDiffBytes
+	}
+	return diff[:cut] + fmt.Sprintf("\n... (diff truncated, %d more bytes)\n", len(diff)-cut)
+}
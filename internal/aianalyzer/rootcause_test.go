@@ -0,0 +1,68 @@
+package aianalyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// promptRecorder answers every prompt, keeping the last one
+type promptRecorder struct {
+	prompt string
+}
+
+func (p *promptRecorder) Analyze(prompt string) (string, error) {
+	p.prompt = prompt
+	return "answer", nil
+}
+
+func TestRootCauseAnalysis(t *testing.T) {
+	provider := &promptRecorder{}
+	analyzer := &Analyzer{config: Config{Enabled: true, Provider: "ollama"}, provider: provider}
+
+	oldRun := &models.BenchmarkRun{ID: "run-1", Commit: "aaa"}
+	newRun := &models.BenchmarkRun{ID: "run-2", Commit: "bbb", ProfileSummary: &models.ProfileSummary{
+		CPUTopFunctions: []models.FunctionProfile{{Name: "parser.(*Lexer).next"}},
+	}}
+	regressed := []models.Comparison{
+		{Name: "Parse", OldNsPerOp: 100, NewNsPerOp: 150, DeltaPercent: 50, Status: "degraded"},
+		{Name: "Encode", OldNsPerOp: 100, NewNsPerOp: 100, Status: "same"},
+	}
+
+	// Regressions of a profiled run are analyzed for their root cause
+	analysis, _, err := analyzer.WithCodeDiff("+\tbuf = append(buf, tok...)\n").ComparisonAnalysis(oldRun, newRun, regressed)
+	if err != nil || analysis.Kind != KindRootCause {
+		t.Fatalf("ComparisonAnalysis = %+v, %v, want a root-cause analysis", analysis, err)
+	}
+	for _, want := range []string{"parser.(*Lexer).next", "buf = append(buf, tok...)", `"commit": "bbb"`, "Parse"} {
+		if !strings.Contains(provider.prompt, want) {
+			t.Errorf("Root-cause prompt lacks %q:\n%s", want, provider.prompt)
+		}
+	}
+	if strings.Contains(provider.prompt, "Encode") {
+		t.Error("Root-cause prompt includes a benchmark that did not regress")
+	}
+
+	// Without regressions the comparison is summarized as before
+	unchanged := []models.Comparison{regressed[1]}
+	if analysis, _, _ := analyzer.ComparisonAnalysis(oldRun, newRun, unchanged); analysis.Kind != KindComparison {
+		t.Errorf("Kind = %s without regressions, want %s", analysis.Kind, KindComparison)
+	}
+}
+
+func TestTruncateDiff(t *testing.T) {
+	short := "+a\n-b\n"
+	if truncateDiff(short) != short {
+		t.Error("Short diff was truncated")
+	}
+
+	long := strings.Repeat("+line of code\n", maxDiffBytes/10)
+	truncated := truncateDiff(long)
+	if len(truncated) > maxDiffBytes+100 || !strings.Contains(truncated, "diff truncated") {
+		t.Errorf("Truncated diff has %d bytes and ends with %q", len(truncated), truncated[len(truncated)-60:])
+	}
+	if !strings.HasSuffix(strings.Split(truncated, "\n...")[0], "+line of code") {
+		t.Error("Diff was not cut at a line boundary")
+	}
+}
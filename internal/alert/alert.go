@@ -0,0 +1,223 @@
+// Package alert evaluates the rate-of-change rules declared in the project
+// configuration against the history of benchmark runs, and notifies the
+// channels of the rules that fire. A trend fitted over days catches slow
+// regressions that no comparison of two runs flags.
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/slo"
+	"github.com/alenon/gokanon/internal/stats"
+)
+
+// client sends notifications
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Alert is a rule that fired for a benchmark
+type Alert struct {
+	Rule      string    `json:"rule"`
+	Benchmark string    `json:"benchmark"`
+	Metric    string    `json:"metric"`
+	Rate      float64   `json:"rate"`  // Fitted change (%) per period; negative is a decrease
+	Limit     float64   `json:"limit"` // Largest change (%) per period the rule allows
+	Per       string    `json:"per"`
+	Over      string    `json:"over"`
+	Runs      int       `json:"runs"`  // Runs the trend was fitted on
+	From      float64   `json:"from"`  // Fitted value at the oldest run
+	To        float64   `json:"to"`    // Fitted value at the newest run
+	RunID     string    `json:"runId"` // Newest run
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Message describes the alert in one line
+func (a Alert) Message() string {
+	direction := "increased"
+	if a.Rate < 0 {
+		direction = "decreased"
+	}
+	return fmt.Sprintf("%s %s %s %.1f%% per %s over %s (%s → %s, %d runs), limit %g%% [%s]",
+		a.Benchmark, a.Metric, direction, math.Abs(a.Rate), a.Per, a.Over,
+		slo.FormatValue(a.From, a.Metric), slo.FormatValue(a.To, a.Metric), a.Runs, a.Limit, a.Rule)
+}
+
+// Evaluate evaluates a rule for each benchmark of the newest run it
+// matches. Runs are newest first, as returned by storage.List. The values
+// of the runs within the rule's window of the newest one are fitted by a
+// line against time, and the rate is its slope over a period relative to
+// the fitted value at the oldest run.
+func Evaluate(rule config.Alert, runs []models.BenchmarkRun) ([]Alert, error) {
+	per, err := rule.Period()
+	if err != nil {
+		return nil, fmt.Errorf("alert %q: invalid per: %w", rule.Name, err)
+	}
+	over, err := rule.Window()
+	if err != nil {
+		return nil, fmt.Errorf("alert %q: invalid over: %w", rule.Name, err)
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	newest := &runs[0]
+	metric := rule.MetricName()
+	var alerts []Alert
+	for _, benchmark := range matching(newest, rule.Benchmark) {
+		var times, values []float64
+		for i := len(runs) - 1; i >= 0; i-- {
+			if newest.Timestamp.Sub(runs[i].Timestamp) > over {
+				continue
+			}
+			value, ok := sample(&runs[i], benchmark, metric)
+			if !ok {
+				continue
+			}
+			times = append(times, runs[i].Timestamp.Sub(newest.Timestamp).Seconds())
+			values = append(values, value)
+		}
+		if len(values) < rule.Runs() {
+			continue
+		}
+
+		slope, intercept, _ := stats.LinearRegression(times, values)
+		from, to := slope*times[0]+intercept, intercept
+		if math.IsNaN(slope) || math.IsInf(slope, 0) || from <= 0 {
+			continue // All runs at the same time, or a meaningless baseline
+		}
+		rate := slope * per.Seconds() / from * 100
+
+		limit := rule.MaxIncrease
+		fired := rule.MaxIncrease > 0 && rate > rule.MaxIncrease
+		if rule.MaxDecrease > 0 {
+			limit = rule.MaxDecrease
+			fired = -rate > rule.MaxDecrease
+		}
+		if !fired {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Rule:      rule.Name,
+			Benchmark: benchmark,
+			Metric:    metric,
+			Rate:      rate,
+			Limit:     limit,
+			Per:       orDefault(rule.Per, "1d"),
+			Over:      orDefault(rule.Over, "7d"),
+			Runs:      len(values),
+			From:      from,
+			To:        to,
+			RunID:     newest.ID,
+			Timestamp: newest.Timestamp,
+		})
+	}
+	return alerts, nil
+}
+
+// matching returns the benchmarks of a run matching a name or pattern,
+// without their "Benchmark" prefix and GOMAXPROCS suffix
+func matching(run *models.BenchmarkRun, pattern string) []string {
+	pattern = strings.TrimPrefix(pattern, "Benchmark")
+	seen := make(map[string]bool)
+	var names []string
+	for _, result := range run.Results {
+		if !result.Measured() {
+			continue
+		}
+		name := strings.TrimPrefix(result.Name, "Benchmark")
+		key := stats.BenchmarkKey(name)
+		if seen[key] {
+			continue
+		}
+		if ok, _ := path.Match(pattern, key); ok || name == pattern {
+			seen[key] = true
+			names = append(names, key)
+		}
+	}
+	return names
+}
+
+// sample returns the value of a metric of a benchmark in a run
+func sample(run *models.BenchmarkRun, benchmark, metric string) (float64, bool) {
+	for _, result := range run.Results {
+		if result.Measured() && stats.BenchmarkKey(strings.TrimPrefix(result.Name, "Benchmark")) == benchmark {
+			return stats.MetricValue(result, metric)
+		}
+	}
+	return 0, false
+}
+
+// orDefault returns value, or fallback when it is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// Notify sends the alerts of a rule to each of its channels: the alerts as
+// JSON to webhook channels, and their messages to Slack incoming webhooks.
+// Every channel is tried, even after another one failed.
+func Notify(rule config.Alert, alerts []Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	lines := make([]string, len(alerts))
+	for i, a := range alerts {
+		lines[i] = a.Message()
+	}
+	return Send(rule.Notify, map[string]any{"rule": rule.Name, "alerts": alerts}, "gokanon alert: "+strings.Join(lines, "\n"))
+}
+
+// Send posts a notification to each channel: payload as JSON to webhook
+// channels, and text to Slack incoming webhooks. Every channel is tried,
+// even after another one failed.
+func Send(channels []string, payload any, text string) error {
+	var errs []error
+	for _, channel := range channels {
+		kind, url, _ := strings.Cut(channel, ":")
+		var body any
+		switch kind {
+		case "webhook":
+			body = payload
+		case "slack":
+			body = map[string]string{"text": text}
+		default:
+			errs = append(errs, fmt.Errorf("unknown notification channel %q", channel))
+			continue
+		}
+		if err := post(url, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", kind, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends a JSON body and fails unless the response status is 2xx
+func post(url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s responded with status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// daily builds runs a day apart, newest first, from ns/op values of
+// BenchmarkCheckout given oldest first
+func daily(values ...float64) []models.BenchmarkRun {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := make([]models.BenchmarkRun, len(values))
+	for i, v := range values {
+		runs[len(values)-1-i] = models.BenchmarkRun{
+			ID:        fmt.Sprintf("run-%d", i),
+			Timestamp: start.Add(time.Duration(i) * 24 * time.Hour),
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkCheckout-8", NsPerOp: v},
+				{Name: "BenchmarkOther-8", NsPerOp: 1000},
+			},
+		}
+	}
+	return runs
+}
+
+func TestEvaluate(t *testing.T) {
+	rule := config.Alert{Name: "creep", Benchmark: "Check*", MaxIncrease: 2}
+
+	// 3% a day: no single day-to-day comparison would flag it
+	alerts, err := Evaluate(rule, daily(1000, 1030, 1060, 1090, 1120, 1150, 1180, 1210, 1240))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Evaluate returned %d alerts, want 1: %+v", len(alerts), alerts)
+	}
+	a := alerts[0]
+	// The run of the first day is older than the 7 days window
+	if a.Benchmark != "Checkout" || a.Runs != 8 || a.RunID != "run-8" || a.Rate < 2.5 || a.Rate > 3 {
+		t.Errorf("Unexpected alert %+v", a)
+	}
+	if msg := a.Message(); !strings.Contains(msg, "Checkout ns/op increased 2.9% per 1d over 7d") || !strings.Contains(msg, "[creep]") {
+		t.Errorf("Message() = %q", msg)
+	}
+
+	// 1% a day is within the limit
+	if alerts, _ := Evaluate(rule, daily(1000, 1010, 1020, 1030, 1040)); len(alerts) != 0 {
+		t.Errorf("Expected no alert, got %+v", alerts)
+	}
+
+	// Too few runs to fit a trend
+	if alerts, _ := Evaluate(rule, daily(1000, 2000)); len(alerts) != 0 {
+		t.Errorf("Expected no alert with two runs, got %+v", alerts)
+	}
+}
+
+func TestEvaluateDecrease(t *testing.T) {
+	rule := config.Alert{Name: "throughput", Benchmark: "Checkout", Metric: "ns/op", MaxDecrease: 5, Per: "1w"}
+	alerts, err := Evaluate(rule, daily(1000, 990, 980, 970))
+	if err != nil || len(alerts) != 1 || alerts[0].Rate > -5 || alerts[0].Limit != 5 {
+		t.Fatalf("Evaluate = %+v, %v, want a decrease of about 7%% per week", alerts, err)
+	}
+	if msg := alerts[0].Message(); !strings.Contains(msg, "decreased") {
+		t.Errorf("Message() = %q", msg)
+	}
+
+	// An increase never fires a decrease rule
+	if alerts, _ := Evaluate(rule, daily(1000, 1100, 1200, 1300)); len(alerts) != 0 {
+		t.Errorf("Expected no alert, got %+v", alerts)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var webhook, slack map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Invalid notification: %v", err)
+		}
+		switch r.URL.Path {
+		case "/hook":
+			webhook = body
+		case "/slack":
+			slack = body
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	rule := config.Alert{Name: "creep", Notify: []string{"webhook:" + server.URL + "/hook", "slack:" + server.URL + "/slack"}}
+	alerts := []Alert{{Rule: "creep", Benchmark: "Checkout", Metric: "ns/op", Rate: 3, Limit: 2, Per: "1d", Over: "7d", Runs: 7}}
+	if err := Notify(rule, alerts); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if webhook["rule"] != "creep" || len(webhook["alerts"].([]any)) != 1 {
+		t.Errorf("Webhook got %v", webhook)
+	}
+	if text, _ := slack["text"].(string); !strings.Contains(text, "Checkout ns/op increased 3.0%") {
+		t.Errorf("Slack got %v", slack)
+	}
+
+	// Every channel is tried and failures are reported
+	rule.Notify = []string{"webhook:" + server.URL + "/missing", "slack:" + server.URL + "/slack"}
+	slack = nil
+	if err := Notify(rule, alerts); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Notify = %v, want the 404 reported", err)
+	}
+	if slack == nil {
+		t.Error("Slack was not notified after the webhook failed")
+	}
+}
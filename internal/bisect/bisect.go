@@ -0,0 +1,195 @@
+// Package bisect finds the commit that introduced a benchmark regression by
+// binary search over the git history, like git bisect driven by benchmark
+// results instead of a pass/fail test.
+package bisect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MeasureFunc benchmarks a commit and returns the value of the regressed
+// benchmark. An error marks the commit as untestable, e.g. when it does not
+// build, and the search continues around it like git bisect skip. A
+// context.Canceled error stops the search instead.
+type MeasureFunc func(commit Commit) (float64, error)
+
+// Commit is a commit in the bisected range
+type Commit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+}
+
+// Short returns the abbreviated hash of the commit
+func (c Commit) Short() string {
+	if len(c.Hash) > 12 {
+		return c.Hash[:12]
+	}
+	return c.Hash
+}
+
+// Step is the measurement of one commit during the search
+type Step struct {
+	Commit       Commit  `json:"commit"`
+	Value        float64 `json:"value"`
+	DeltaPercent float64 `json:"deltaPercent"` // Change from the good commit
+	Regressed    bool    `json:"regressed"`
+	Skipped      bool    `json:"skipped"` // The commit could not be measured
+	Error        string  `json:"error,omitempty"`
+}
+
+// Result is the outcome of a bisection
+type Result struct {
+	Good  Step   `json:"good"`
+	Bad   Step   `json:"bad"`
+	Steps []Step `json:"steps"` // Commits measured between good and bad, in order
+
+	// First is the first commit whose value regressed beyond the
+	// threshold. When skipped commits leave the search ambiguous, it is the
+	// first measured regressed commit and Candidates lists every commit
+	// that may have introduced the regression.
+	First      Commit   `json:"first"`
+	Candidates []Commit `json:"candidates,omitempty"`
+}
+
+// Ambiguous reports whether skipped commits prevented the search from
+// narrowing the regression down to a single commit
+func (r *Result) Ambiguous() bool {
+	return len(r.Candidates) > 1
+}
+
+// FirstStep returns the measurement of the first regressed commit
+func (r *Result) FirstStep() Step {
+	for _, step := range r.Steps {
+		if step.Commit == r.First {
+			return step
+		}
+	}
+	return r.Bad
+}
+
+// ErrNotRegressed is returned when the bad commit is not slower than the
+// good one beyond the threshold
+var ErrNotRegressed = errors.New("the bad commit did not regress beyond the threshold")
+
+// Bisector searches a range of commits for a regression
+type Bisector struct {
+	threshold float64 // Maximum allowed increase in percent
+	measure   MeasureFunc
+	progress  func(step Step, remaining int)
+}
+
+// NewBisector creates a bisector that considers a commit regressed when
+// its value is more than threshold percent above the good commit's
+func NewBisector(threshold float64, measure MeasureFunc) *Bisector {
+	return &Bisector{threshold: threshold, measure: measure}
+}
+
+// WithProgress reports each measured commit with the number of commits
+// that may still contain the regression
+func (b *Bisector) WithProgress(progress func(step Step, remaining int)) *Bisector {
+	b.progress = progress
+	return b
+}
+
+// Run measures the good commit and the bad commit, then binary searches the
+// commits in between, given oldest first, for the first regressed commit.
+// Commits that cannot be measured are skipped in favor of their
+// neighbors. It assumes, like git bisect, that once the regression appears
+// every later commit is regressed.
+func (b *Bisector) Run(good Commit, between []Commit, bad Commit) (*Result, error) {
+	goodValue, err := b.measure(good)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure good commit %s: %w", good.Short(), err)
+	}
+	if goodValue <= 0 {
+		return nil, fmt.Errorf("good commit %s measured %v, cannot compute a change from it", good.Short(), goodValue)
+	}
+	result := &Result{Good: Step{Commit: good, Value: goodValue}}
+	b.report(result.Good, len(between)+1)
+
+	badValue, err := b.measure(bad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure bad commit %s: %w", bad.Short(), err)
+	}
+	result.Bad = b.step(bad, goodValue, badValue)
+	b.report(result.Bad, len(between)+1)
+	if !result.Bad.Regressed {
+		return result, fmt.Errorf("%w: %+.2f%% (threshold %.2f%%)", ErrNotRegressed, result.Bad.DeltaPercent, b.threshold)
+	}
+
+	// The regression is in (lo, hi]: lo is known good, hi known regressed.
+	// Indexes are into between, with -1 for the good commit and
+	// len(between) for the bad one.
+	lo, hi := -1, len(between)
+	skipped := make(map[int]bool)
+	for {
+		mid, ok := pick(lo, hi, skipped)
+		if !ok {
+			break
+		}
+		value, err := b.measure(between[mid])
+		if errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+		var step Step
+		if err != nil {
+			step = Step{Commit: between[mid], Skipped: true, Error: err.Error()}
+			skipped[mid] = true
+		} else {
+			step = b.step(between[mid], goodValue, value)
+			if step.Regressed {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		result.Steps = append(result.Steps, step)
+		b.report(step, hi-lo)
+	}
+
+	commit := func(i int) Commit {
+		if i == len(between) {
+			return bad
+		}
+		return between[i]
+	}
+	result.First = commit(hi)
+	// Skipped commits just before the first regressed one may have
+	// introduced the regression as well
+	for i := lo + 1; i <= hi; i++ {
+		result.Candidates = append(result.Candidates, commit(i))
+	}
+	if len(result.Candidates) == 1 {
+		result.Candidates = nil
+	}
+	return result, nil
+}
+
+// step evaluates the value measured for a commit against the good value
+func (b *Bisector) step(commit Commit, goodValue, value float64) Step {
+	delta := (value - goodValue) / goodValue * 100
+	return Step{Commit: commit, Value: value, DeltaPercent: delta, Regressed: delta > b.threshold}
+}
+
+// report calls the progress callback, if any
+func (b *Bisector) report(step Step, remaining int) {
+	if b.progress != nil {
+		b.progress(step, remaining)
+	}
+}
+
+// pick returns the untested commit closest to the middle of (lo, hi), or
+// false when every commit in between was skipped
+func pick(lo, hi int, skipped map[int]bool) (int, bool) {
+	mid := lo + (hi-lo)/2
+	for d := 0; mid-d > lo || mid+d < hi; d++ {
+		for _, i := range []int{mid - d, mid + d} {
+			if i > lo && i < hi && !skipped[i] {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,150 @@
+package bisect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// commits builds a good commit, n commits in between and a bad commit
+func commits(n int) (Commit, []Commit, Commit) {
+	between := make([]Commit, n)
+	for i := range between {
+		between[i] = Commit{Hash: fmt.Sprintf("c%d", i)}
+	}
+	return Commit{Hash: "good"}, between, Commit{Hash: "bad"}
+}
+
+// regressedFrom returns values that jump by 50% from the commit at index
+// first of between, with "bad" regressed too
+func regressedFrom(between []Commit, first int, measured *[]string) MeasureFunc {
+	index := make(map[string]int)
+	for i, c := range between {
+		index[c.Hash] = i
+	}
+	return func(commit Commit) (float64, error) {
+		*measured = append(*measured, commit.Hash)
+		switch commit.Hash {
+		case "good":
+			return 100, nil
+		case "bad":
+			return 150, nil
+		}
+		if index[commit.Hash] >= first {
+			return 150, nil
+		}
+		return 101, nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	for _, first := range []int{0, 1, 7, 15, 16} {
+		t.Run(fmt.Sprintf("first=%d", first), func(t *testing.T) {
+			good, between, bad := commits(16)
+			var measured []string
+			result, err := NewBisector(10, regressedFrom(between, first, &measured)).Run(good, between, bad)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			want := bad
+			if first < len(between) {
+				want = between[first]
+			}
+			if result.First != want || result.Ambiguous() {
+				t.Errorf("Expected first regressed commit %s, got %s (candidates %v)", want.Hash, result.First.Hash, result.Candidates)
+			}
+			// Good, bad and a binary search over 16 commits
+			if len(measured) > 2+5 {
+				t.Errorf("Expected at most 7 measurements, got %d: %v", len(measured), measured)
+			}
+			if step := result.FirstStep(); step.Commit != want || step.DeltaPercent != 50 {
+				t.Errorf("Unexpected first step: %+v", step)
+			}
+		})
+	}
+}
+
+func TestRunNotRegressed(t *testing.T) {
+	good, between, bad := commits(4)
+	result, err := NewBisector(10, func(Commit) (float64, error) { return 100, nil }).Run(good, between, bad)
+	if !errors.Is(err, ErrNotRegressed) {
+		t.Fatalf("Expected ErrNotRegressed, got %v", err)
+	}
+	if result == nil || result.Bad.Regressed || result.Good.Value != 100 {
+		t.Errorf("Expected the good and bad measurements with the error, got %+v", result)
+	}
+}
+
+func TestRunSkipped(t *testing.T) {
+	good, between, bad := commits(8)
+	var measured []string
+	measure := regressedFrom(between, 5, &measured)
+
+	// The commit before the regression does not build
+	result, err := NewBisector(10, func(commit Commit) (float64, error) {
+		if commit.Hash == "c4" {
+			return 0, errors.New("build failed")
+		}
+		return measure(commit)
+	}).Run(good, between, bad)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.Ambiguous() || result.First != between[5] {
+		t.Fatalf("Expected c5 with c4 as an ambiguous candidate, got %s and %v", result.First.Hash, result.Candidates)
+	}
+	if len(result.Candidates) != 2 || result.Candidates[0] != between[4] {
+		t.Errorf("Expected candidates c4 and c5, got %v", result.Candidates)
+	}
+
+	var skipped int
+	for _, step := range result.Steps {
+		if step.Skipped {
+			skipped++
+		}
+	}
+	if skipped != 1 {
+		t.Errorf("Expected one skipped step, got %d", skipped)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	good, between, bad := commits(8)
+	var measured []string
+	measure := regressedFrom(between, 5, &measured)
+
+	_, err := NewBisector(10, func(commit Commit) (float64, error) {
+		if commit.Hash != "good" && commit.Hash != "bad" {
+			return 0, fmt.Errorf("benchmark run interrupted: %w", context.Canceled)
+		}
+		return measure(commit)
+	}).Run(good, between, bad)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the search to stop when canceled, got %v", err)
+	}
+}
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name    string
+		lo, hi  int
+		skipped map[int]bool
+		want    int
+		wantOK  bool
+	}{
+		{"middle", -1, 8, nil, 3, true},
+		{"adjacent", 2, 3, nil, 0, false},
+		{"skipped middle", -1, 8, map[int]bool{3: true}, 2, true},
+		{"all skipped", 0, 3, map[int]bool{1: true, 2: true}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pick(tt.lo, tt.hi, tt.skipped)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("pick(%d, %d) = %d, %v, want %d, %v", tt.lo, tt.hi, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
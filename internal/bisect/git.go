@@ -0,0 +1,140 @@
+package bisect
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repository is the git repository containing a directory
+type Repository struct {
+	dir    string // Directory within the repository the commands run in
+	prefix string // Path of dir relative to the repository root
+}
+
+// OpenRepository returns the git repository containing dir
+func OpenRepository(dir string) (*Repository, error) {
+	r := &Repository{dir: dir}
+	prefix, err := r.git("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	r.prefix = filepath.FromSlash(prefix)
+	return r, nil
+}
+
+// Range returns the good and bad commits and the commits between them,
+// oldest first. Only the first-parent history of bad is followed, so that
+// the commits form a line even across merges.
+func (r *Repository) Range(good, bad string) (Commit, []Commit, Commit, error) {
+	goodCommit, err := r.commit(good)
+	if err != nil {
+		return Commit{}, nil, Commit{}, err
+	}
+	badCommit, err := r.commit(bad)
+	if err != nil {
+		return Commit{}, nil, Commit{}, err
+	}
+	if _, err := r.git("merge-base", "--is-ancestor", goodCommit.Hash, badCommit.Hash); err != nil {
+		return Commit{}, nil, Commit{}, fmt.Errorf("good commit %s is not an ancestor of bad commit %s", good, bad)
+	}
+
+	output, err := r.git("log", "--reverse", "--first-parent", "--format=%H %s", goodCommit.Hash+".."+badCommit.Hash)
+	if err != nil {
+		return Commit{}, nil, Commit{}, err
+	}
+	var between []Commit
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		hash, subject, _ := strings.Cut(line, " ")
+		if hash != badCommit.Hash {
+			between = append(between, Commit{Hash: hash, Subject: subject})
+		}
+	}
+	return goodCommit, between, badCommit, nil
+}
+
+// commit resolves a revision to a commit
+func (r *Repository) commit(rev string) (Commit, error) {
+	output, err := r.git("log", "-1", "--format=%H %s", rev+"^{commit}", "--")
+	if err != nil {
+		return Commit{}, fmt.Errorf("unknown commit %q: %w", rev, err)
+	}
+	hash, subject, _ := strings.Cut(output, " ")
+	return Commit{Hash: hash, Subject: subject}, nil
+}
+
+// Diff returns the changes to Go files between two commits
+func (r *Repository) Diff(from, to string) (string, error) {
+	return r.git("diff", "--no-color", "--no-ext-diff", from, to, "--", "*.go")
+}
+
+// Worktree is a separate checkout of the repository, so that commits are
+// benchmarked without touching the working tree
+type Worktree struct {
+	repo *Repository
+	root string
+}
+
+// AddWorktree creates a worktree in a temporary directory. Remove deletes
+// it.
+func (r *Repository) AddWorktree() (*Worktree, error) {
+	root, err := os.MkdirTemp("", "gokanon-bisect-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	if _, err := r.git("worktree", "add", "--detach", root); err != nil {
+		os.RemoveAll(root)
+		return nil, err
+	}
+	return &Worktree{repo: r, root: root}, nil
+}
+
+// Checkout checks out a commit in the worktree
+func (w *Worktree) Checkout(commit Commit) error {
+	_, err := git(w.root, "checkout", "--quiet", "--force", "--detach", commit.Hash)
+	return err
+}
+
+// Dir returns the directory in the worktree that corresponds to the
+// directory the repository was opened from
+func (w *Worktree) Dir() string {
+	return filepath.Join(w.root, w.repo.prefix)
+}
+
+// Remove deletes the worktree
+func (w *Worktree) Remove() error {
+	_, err := w.repo.git("worktree", "remove", "--force", w.root)
+	if err != nil {
+		// Leave no stale checkout behind even if git cannot remove it
+		os.RemoveAll(w.root)
+		w.repo.git("worktree", "prune")
+	}
+	return err
+}
+
+// git runs a git command in the repository and returns its output
+func (r *Repository) git(args ...string) (string, error) {
+	return git(r.dir, args...)
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
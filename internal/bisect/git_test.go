@@ -0,0 +1,133 @@
+package bisect
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRepository creates a git repository with a commit per subject, each
+// writing the subject to a file in the sub directory
+func newRepository(t *testing.T, subjects ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "--quiet")
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	for _, subject := range subjects {
+		if err := os.WriteFile(filepath.Join(dir, "sub", "version"), []byte(subject), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "-A")
+		run("commit", "--quiet", "-m", subject)
+	}
+	return dir
+}
+
+func TestRange(t *testing.T) {
+	dir := newRepository(t, "one", "two", "three", "four")
+	repo, err := OpenRepository(dir)
+	if err != nil {
+		t.Fatalf("OpenRepository failed: %v", err)
+	}
+
+	good, between, bad, err := repo.Range("HEAD~3", "HEAD")
+	if err != nil {
+		t.Fatalf("Range failed: %v", err)
+	}
+	if good.Subject != "one" || bad.Subject != "four" {
+		t.Errorf("Expected good commit one and bad commit four, got %q and %q", good.Subject, bad.Subject)
+	}
+	if len(between) != 2 || between[0].Subject != "two" || between[1].Subject != "three" {
+		t.Errorf("Expected commits two and three in between, got %+v", between)
+	}
+
+	if _, _, _, err := repo.Range("HEAD", "HEAD~1"); err == nil {
+		t.Error("Expected an error when the good commit is not an ancestor of the bad one")
+	}
+	if _, _, _, err := repo.Range("no-such-commit", "HEAD"); err == nil {
+		t.Error("Expected an error for an unknown commit")
+	}
+}
+
+func TestWorktree(t *testing.T) {
+	dir := newRepository(t, "one", "two")
+	repo, err := OpenRepository(filepath.Join(dir, "sub"))
+	if err != nil {
+		t.Fatalf("OpenRepository failed: %v", err)
+	}
+	good, _, _, err := repo.Range("HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("Range failed: %v", err)
+	}
+
+	worktree, err := repo.AddWorktree()
+	if err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if err := worktree.Checkout(good); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	// The worktree directory corresponds to the sub directory
+	data, err := os.ReadFile(filepath.Join(worktree.Dir(), "version"))
+	if err != nil || string(data) != "one" {
+		t.Errorf("Expected version one in the worktree, got %q (%v)", data, err)
+	}
+	// The working tree is left alone
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub", "version")); string(data) != "two" {
+		t.Errorf("Expected the working tree to stay at two, got %q", data)
+	}
+
+	if err := worktree.Remove(); err != nil {
+		t.Errorf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(worktree.root); !os.IsNotExist(err) {
+		t.Errorf("Expected the worktree to be removed, got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := newRepository(t, "one")
+	for _, env := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(env, "test")
+	}
+	for _, env := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(env, "test@example.com")
+	}
+	os.WriteFile(filepath.Join(dir, "sub", "version"), []byte("two"), 0644)
+	if err := os.WriteFile(filepath.Join(dir, "sub", "parse.go"), []byte("package sub\n\nfunc Parse() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git(dir, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git(dir, "commit", "--quiet", "-m", "two"); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := OpenRepository(dir)
+	if err != nil {
+		t.Fatalf("OpenRepository failed: %v", err)
+	}
+	diff, err := repo.Diff("HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	// Only Go files are diffed
+	if !strings.Contains(diff, "+func Parse() {}") || strings.Contains(diff, "version") {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+}
@@ -0,0 +1,175 @@
+// Package ci reports benchmark results to CI systems
+package ci
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/threshold"
+)
+
+// Report is the outcome of a CI run: the new run, the comparison with the
+// baseline and the threshold check of that comparison. Without a baseline
+// Comparisons and Result are nil.
+type Report struct {
+	Run         *models.BenchmarkRun
+	Baseline    string
+	Comparisons []models.Comparison
+	Result      *threshold.Result
+	Threshold   float64
+
+	// How the environments of the run and the baseline differ, when they
+	// were compared anyway
+	EnvironmentDifferences []string
+}
+
+// Passed reports whether the run passed the threshold check. A run without
+// a baseline passes.
+func (r *Report) Passed() bool {
+	return r.Result == nil || r.Result.Passed
+}
+
+// JobSummary renders the report as the Markdown of a GitHub Actions job
+// summary
+func JobSummary(r *Report) string {
+	var sb strings.Builder
+
+	sb.WriteString("## Benchmark Results\n\n")
+	switch {
+	case r.Result == nil:
+		sb.WriteString(fmt.Sprintf("ℹ️ No baseline `%s` to compare with. Save one with `gokanon baseline save -name=%s`.\n\n", r.Baseline, r.Baseline))
+	case r.Result.Passed:
+		sb.WriteString(fmt.Sprintf("✅ All %d benchmarks are within %.1f%% of baseline `%s`.\n\n", r.Result.TotalChecked, r.Threshold, r.Baseline))
+	default:
+		sb.WriteString(fmt.Sprintf("❌ %d/%d benchmarks regressed beyond %.1f%% of baseline `%s`.\n\n", len(r.Result.Failures), r.Result.TotalChecked, r.Threshold, r.Baseline))
+	}
+	if len(r.EnvironmentDifferences) > 0 {
+		sb.WriteString("> ⚠️ **Environments differ**: deltas may come from the environment rather than the code.\n>\n")
+		for _, difference := range r.EnvironmentDifferences {
+			sb.WriteString(fmt.Sprintf("> - %s\n", difference))
+		}
+		sb.WriteString("\n")
+	}
+
+	if r.Result == nil {
+		sb.WriteString("| Benchmark | ns/op | B/op | allocs/op |\n")
+		sb.WriteString("|-----------|-------|------|-----------|\n")
+		for _, result := range r.Run.Results {
+			if !result.Measured() {
+				sb.WriteString(fmt.Sprintf("| %s | %s | - | - |\n", result.Name, strings.ToUpper(result.Status)))
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %.2f | %d | %d |\n", result.Name, result.NsPerOp, result.BytesPerOp, result.AllocsPerOp))
+		}
+	} else {
+		failed := make(map[string]bool)
+		for _, failure := range r.Result.Failures {
+			failed[failure.BenchmarkName] = true
+		}
+
+		matched, added, removed := compare.Split(r.Comparisons)
+		unit := "ns/op"
+		if len(r.Comparisons) > 0 {
+			unit = r.Comparisons[0].ValueUnit()
+		}
+		sb.WriteString(fmt.Sprintf("| Status | Benchmark | Baseline (%s) | Current (%s) | Delta (%%) |\n", unit, unit))
+		sb.WriteString("|--------|-----------|---------------|--------------|-----------|\n")
+		for _, comp := range matched {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %.2f | %.2f | %+.2f%% |\n",
+				statusIcon(comp, failed[comp.Name]), comp.Name, comp.OldNsPerOp, comp.NewNsPerOp, comp.DeltaPercent))
+		}
+		for _, comp := range added {
+			sb.WriteString(fmt.Sprintf("| ➕ | %s | - | %.2f | - |\n", comp.Name, comp.NewNsPerOp))
+		}
+		for _, comp := range removed {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %.2f | - | - |\n", statusIcon(comp, failed[comp.Name]), comp.Name, comp.OldNsPerOp))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\nRun `%s`", r.Run.ID))
+	if r.Run.Commit != "" {
+		sb.WriteString(fmt.Sprintf(" of commit `%s`", shortCommit(r.Run.Commit)))
+	}
+	sb.WriteString(fmt.Sprintf(", %s\n", r.Run.GoVersion))
+
+	return sb.String()
+}
+
+// statusIcon returns the status column of a comparison in the job summary
+func statusIcon(comp models.Comparison, failed bool) string {
+	switch {
+	case failed:
+		return "❌"
+	case comp.Status == models.StatusRemoved:
+		return "➖"
+	case comp.Status == "improved":
+		return "🟢"
+	case comp.Status == "degraded":
+		return "🟡"
+	}
+	return "⚪"
+}
+
+// shortCommit abbreviates a commit hash
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// Annotations returns the GitHub Actions workflow commands annotating the
+// report: an error for every benchmark that failed the threshold check and
+// a warning for every benchmark that degraded within the threshold
+func Annotations(r *Report) []string {
+	if r.Result == nil {
+		return nil
+	}
+
+	var lines []string
+	failed := make(map[string]bool)
+	for _, failure := range r.Result.Failures {
+		failed[failure.BenchmarkName] = true
+		lines = append(lines, workflowCommand("error", "Benchmark regression: "+failure.BenchmarkName, failure.Message))
+	}
+	for _, comp := range r.Comparisons {
+		if comp.Status == "degraded" && !failed[comp.Name] {
+			message := fmt.Sprintf("Performance degraded by %.2f%% (within threshold: %.2f%%)", comp.DeltaPercent, r.Threshold)
+			lines = append(lines, workflowCommand("warning", "Benchmark slower: "+comp.Name, message))
+		}
+	}
+	return lines
+}
+
+// workflowCommand formats a workflow command such as
+// "::error title=...::message"
+func workflowCommand(command, title, message string) string {
+	return fmt.Sprintf("::%s title=%s::%s", command, escapeProperty(title), escapeData(message))
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// AppendStepSummary appends a job summary to the file GitHub Actions names
+// in $GITHUB_STEP_SUMMARY. Other steps may have written to it already.
+func AppendStepSummary(path, summary string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open job summary: %w", err)
+	}
+	if _, err := f.WriteString(summary); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write job summary: %w", err)
+	}
+	return f.Close()
+}
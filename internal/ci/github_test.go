@@ -0,0 +1,130 @@
+package ci
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/threshold"
+)
+
+func testReport(t *testing.T) *Report {
+	t.Helper()
+	comparisons := []models.Comparison{
+		{Name: "BenchmarkFast", OldNsPerOp: 100, NewNsPerOp: 90, DeltaPercent: -10, Status: "improved"},
+		{Name: "BenchmarkSlow", OldNsPerOp: 100, NewNsPerOp: 150, DeltaPercent: 50, Status: "degraded"},
+		{Name: "BenchmarkBit", OldNsPerOp: 100, NewNsPerOp: 103, DeltaPercent: 3, Status: "degraded"},
+		{Name: "BenchmarkNew", NewNsPerOp: 10, Status: models.StatusAdded},
+	}
+	return &Report{
+		Run:         &models.BenchmarkRun{ID: "run-1", Commit: "0123456789abcdef0123", GoVersion: "go1.24.7"},
+		Baseline:    "main",
+		Comparisons: comparisons,
+		Result:      threshold.NewChecker(5).Check(comparisons),
+		Threshold:   5,
+	}
+}
+
+func TestJobSummary(t *testing.T) {
+	r := testReport(t)
+	if r.Passed() {
+		t.Fatal("Expected the report to fail")
+	}
+
+	summary := JobSummary(r)
+	for _, want := range []string{
+		"❌ 1/3 benchmarks regressed beyond 5.0% of baseline `main`",
+		"| ❌ | BenchmarkSlow | 100.00 | 150.00 | +50.00% |",
+		"| 🟡 | BenchmarkBit | 100.00 | 103.00 | +3.00% |",
+		"| 🟢 | BenchmarkFast | 100.00 | 90.00 | -10.00% |",
+		"| ➕ | BenchmarkNew | - | 10.00 | - |",
+		"Run `run-1` of commit `0123456789ab`, go1.24.7",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestJobSummaryEnvironmentDifferences(t *testing.T) {
+	r := testReport(t)
+	r.EnvironmentDifferences = []string{"Go version differs: go1.23.0 → go1.24.7"}
+
+	summary := JobSummary(r)
+	if !strings.Contains(summary, "> ⚠️ **Environments differ**") || !strings.Contains(summary, "> - Go version differs: go1.23.0 → go1.24.7\n") {
+		t.Errorf("Summary missing the environment warning:\n%s", summary)
+	}
+}
+
+func TestJobSummaryWithoutBaseline(t *testing.T) {
+	r := &Report{
+		Run: &models.BenchmarkRun{
+			ID:        "run-1",
+			GoVersion: "go1.24.7",
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkA", NsPerOp: 12.5, BytesPerOp: 8, AllocsPerOp: 1},
+				{Name: "BenchmarkB", Status: models.StatusSkipped},
+			},
+		},
+		Baseline: "main",
+	}
+	if !r.Passed() {
+		t.Error("Expected a report without baseline to pass")
+	}
+
+	summary := JobSummary(r)
+	for _, want := range []string{
+		"No baseline `main`",
+		"| BenchmarkA | 12.50 | 8 | 1 |",
+		"| BenchmarkB | SKIPPED | - | - |",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary missing %q:\n%s", want, summary)
+		}
+	}
+	if lines := Annotations(r); lines != nil {
+		t.Errorf("Expected no annotations without baseline, got %v", lines)
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	lines := Annotations(testReport(t))
+	want := []string{
+		"::error title=Benchmark regression%3A BenchmarkSlow::Performance degraded by 50.00%25 (threshold: 5.00%25)",
+		"::warning title=Benchmark slower%3A BenchmarkBit::Performance degraded by 3.00%25 (within threshold: 5.00%25)",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Annotations() = %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Annotations()[%d] = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestWorkflowCommandEscaping(t *testing.T) {
+	got := workflowCommand("error", "a:b,c", "50%\nnext")
+	want := "::error title=a%3Ab%2Cc::50%25%0Anext"
+	if got != want {
+		t.Errorf("workflowCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestAppendStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	if err := os.WriteFile(path, []byte("previous step\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AppendStepSummary(path, "## Benchmark Results\n"); err != nil {
+		t.Fatalf("AppendStepSummary failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "previous step\n## Benchmark Results\n" {
+		t.Errorf("Unexpected summary file: %q", data)
+	}
+}
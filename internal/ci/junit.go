@@ -0,0 +1,173 @@
+package ci
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the benchmarks of a run
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitTestCase is one benchmark; it fails when it regressed beyond its
+// threshold and is skipped when it has no baseline to be checked against
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnit renders the report as JUnit XML, one test case per benchmark, so
+// that CI systems such as Jenkins, GitLab or Bamboo show regressions in
+// their test UI. Benchmarks that failed the threshold check are failed test
+// cases; added benchmarks, and removed ones that were not checked, are
+// skipped. Without a baseline every measured benchmark passes.
+func JUnit(r *Report) ([]byte, error) {
+	suite := junitTestSuite{
+		Name:      "gokanon",
+		Timestamp: r.Run.Timestamp.UTC().Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "run", Value: r.Run.ID},
+			{Name: "baseline", Value: r.Baseline},
+			{Name: "threshold", Value: fmt.Sprintf("%.2f%%", r.Threshold)},
+		},
+	}
+	if r.Run.Timestamp.IsZero() {
+		suite.Timestamp = ""
+	}
+	if r.Run.Commit != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "commit", Value: r.Run.Commit})
+	}
+	if r.Run.GoVersion != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "go_version", Value: r.Run.GoVersion})
+	}
+	if len(r.EnvironmentDifferences) > 0 {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "environments_differ", Value: strings.Join(r.EnvironmentDifferences, "; ")})
+	}
+
+	classname := r.Run.Package
+	if classname == "" {
+		classname = "benchmarks"
+	}
+
+	if r.Result == nil {
+		for _, result := range r.Run.Results {
+			tc := junitTestCase{Name: result.Name, Classname: classname}
+			if result.Measured() {
+				tc.SystemOut = fmt.Sprintf("%.2f ns/op, no baseline %q to compare with", result.NsPerOp, r.Baseline)
+			} else {
+				tc.Skipped = &junitSkipped{Message: "Benchmark " + result.Status}
+			}
+			suite.add(tc)
+		}
+	} else {
+		failures := make(map[string]string)
+		for _, failure := range r.Result.Failures {
+			failures[failure.BenchmarkName] = failure.Message
+		}
+		for _, comp := range r.Comparisons {
+			tc := junitTestCase{Name: comp.Name, Classname: classname, SystemOut: compare.FormatComparison(comp)}
+			message, failed := failures[comp.Name]
+			switch {
+			case failed:
+				tc.Failure = &junitFailure{Message: message, Type: failureType(comp), Text: failureDetails(comp, message)}
+			case comp.Status == models.StatusAdded:
+				tc.Skipped = &junitSkipped{Message: "New benchmark, no baseline to compare with"}
+			case comp.Status == models.StatusRemoved:
+				tc.Skipped = &junitSkipped{Message: "Benchmark missing from the new run"}
+			}
+			suite.add(tc)
+		}
+	}
+
+	report := junitTestSuites{
+		Name:     "gokanon",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// add appends a test case to the suite and counts it
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Tests++
+	switch {
+	case tc.Failure != nil:
+		s.Failures++
+	case tc.Skipped != nil:
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, tc)
+}
+
+// failureType classifies why a benchmark failed the check
+func failureType(comp models.Comparison) string {
+	switch comp.Status {
+	case models.StatusRemoved:
+		return "removed"
+	case models.StatusFailed:
+		return "failed"
+	}
+	return "regression"
+}
+
+// failureDetails describes a failed benchmark in the body of its failure
+func failureDetails(comp models.Comparison, message string) string {
+	var sb strings.Builder
+	sb.WriteString(message + "\n")
+	unit := comp.ValueUnit()
+	sb.WriteString(fmt.Sprintf("Baseline: %.2f %s\n", comp.OldNsPerOp, unit))
+	if comp.Status != models.StatusRemoved && comp.Status != models.StatusFailed {
+		sb.WriteString(fmt.Sprintf("Current: %.2f %s (%+.2f%%)\n", comp.NewNsPerOp, unit, comp.DeltaPercent))
+	}
+	for _, metric := range []*models.MetricComparison{comp.BytesPerOp, comp.AllocsPerOp} {
+		if metric != nil {
+			sb.WriteString(fmt.Sprintf("%s: %.0f → %.0f\n", metric.Name, metric.Old, metric.New))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,83 @@
+package ci
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/threshold"
+)
+
+func TestJUnit(t *testing.T) {
+	r := testReport(t)
+	r.Run.Package = "./parser"
+
+	data, err := JUnit(r)
+	if err != nil {
+		t.Fatalf("JUnit failed: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, data)
+	}
+	if report.Tests != 4 || report.Failures != 1 || report.Skipped != 1 {
+		t.Errorf("Expected 4 tests, 1 failure and 1 skipped, got %d, %d and %d", report.Tests, report.Failures, report.Skipped)
+	}
+
+	cases := make(map[string]junitTestCase)
+	for _, tc := range report.Suites[0].Cases {
+		cases[tc.Name] = tc
+		if tc.Classname != "./parser" {
+			t.Errorf("Expected classname ./parser, got %q", tc.Classname)
+		}
+	}
+	slow := cases["BenchmarkSlow"]
+	if slow.Failure == nil || slow.Failure.Type != "regression" || !strings.Contains(slow.Failure.Message, "degraded by 50.00%") {
+		t.Errorf("Expected BenchmarkSlow to fail as a regression, got %+v", slow.Failure)
+	}
+	if cases["BenchmarkBit"].Failure != nil || cases["BenchmarkFast"].Failure != nil {
+		t.Error("Expected benchmarks within the threshold to pass")
+	}
+	if cases["BenchmarkNew"].Skipped == nil {
+		t.Error("Expected the added benchmark to be skipped")
+	}
+}
+
+func TestJUnitRemoved(t *testing.T) {
+	comparisons := []models.Comparison{{Name: "BenchmarkGone", OldNsPerOp: 100, Status: models.StatusRemoved}}
+	r := &Report{
+		Run:         &models.BenchmarkRun{ID: "run-1"},
+		Baseline:    "main",
+		Comparisons: comparisons,
+		Result:      threshold.NewChecker(5).WithFailOnRemoved(true).Check(comparisons),
+		Threshold:   5,
+	}
+
+	data, err := JUnit(r)
+	if err != nil {
+		t.Fatalf("JUnit failed: %v", err)
+	}
+	if !strings.Contains(string(data), `<failure message="Benchmark was removed" type="removed">`) {
+		t.Errorf("Expected the removed benchmark to fail:\n%s", data)
+	}
+}
+
+func TestJUnitWithoutBaseline(t *testing.T) {
+	r := &Report{
+		Run: &models.BenchmarkRun{ID: "run-1", Results: []models.BenchmarkResult{
+			{Name: "BenchmarkA", NsPerOp: 10},
+			{Name: "BenchmarkB", Status: models.StatusSkipped},
+		}},
+		Baseline: "main",
+	}
+
+	data, err := JUnit(r)
+	if err != nil {
+		t.Fatalf("JUnit failed: %v", err)
+	}
+	if !strings.Contains(string(data), `tests="2" failures="0" skipped="1"`) {
+		t.Errorf("Expected one passing and one skipped benchmark:\n%s", data)
+	}
+}
@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/alenon/gokanon/internal/cli/commands"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/plugin"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/telemetry"
 	"github.com/alenon/gokanon/internal/ui"
 )
 
@@ -17,6 +23,7 @@ Usage:
 Commands:
   run          Run benchmarks and save results
   list         List all saved benchmark results
+  show         Show everything recorded about one run
   compare      Compare two benchmark results
   export       Export comparison results to various formats
   stats        Show statistical analysis of multiple runs
@@ -29,6 +36,29 @@ Commands:
   doctor       Run diagnostics to check your setup
   interactive  Start interactive mode with auto-completion
   completion   Install shell completion scripts
+  attach       Attach an external pprof profile to a run
+  annotate     Add a note to a run, shown in listings, reports and trends
+  reanalyze    Re-run the profile analysis of saved runs
+  agent        Join a dashboard controller as a benchmark agent
+  daemon       Run benchmarks on the schedules of the configuration
+  slo          Check service level objectives
+  config       Show resolved storage and configuration locations
+  import       Import go test -bench output, perf data or benchstat CSV
+  projects     List projects tracked in the project registry
+  ci           Run benchmarks and check them against a baseline in GitHub Actions
+  bisect       Find the commit that introduced a regression
+  sync         Share history through an S3/GCS bucket
+  profile      Profile a single benchmark for a fixed time
+  snapshot     Freeze dashboard stats and trends for a release
+  stability    Find benchmarks too noisy to gate CI on
+  prune        Delete old runs and profiles by a retention policy
+  search       Search runs by benchmark, package, tag, note or commit
+  fleet        Roll up regressions across many repositories
+  tui          Full-screen terminal UI for runs, trends and comparisons
+  push         Upload runs to a perf data server
+  analyze      AI analysis of two runs, or replay cached analyses
+  telemetry    Turn anonymous usage telemetry on or off, or inspect it
+  plugins      List plugin commands found on PATH
   version      Show version information
   help         Show this help message
 
@@ -38,7 +68,12 @@ Examples:
   gokanon run -bench=BenchmarkFoo        # Run specific benchmark
   gokanon run -profile=cpu,mem           # Run with CPU and memory profiling
   gokanon run -cpu=1,2,4 -benchtime=1s   # Run with specific CPU counts and duration
+  gokanon run -stress -parallelism=1,4,8 # Measure how RunParallel benchmarks scale
+  gokanon run -startup=./cmd/server -startup-ready=tcp://localhost:8080 # Time cold starts of a program
+  gokanon run -load=http://localhost:8080/api -load-rate=200 # Latency and throughput of a service
   gokanon list                           # List all saved results
+  gokanon show latest                    # Details of the latest run
+  gokanon show -o json run-123           # A run as JSON, for scripts
   gokanon compare run-123 run-456        # Compare two specific runs
   gokanon compare --latest               # Compare last two runs
   gokanon compare --baseline=v1.0        # Compare latest run with baseline
@@ -46,6 +81,7 @@ Examples:
   gokanon stats -last=5                  # Show stats for last 5 runs
   gokanon trend -last=10                 # Show performance trends
   gokanon check --latest -threshold=10   # Check if degradation > 10%
+  gokanon check --latest -format=junit -output=report.xml  # JUnit XML for CI test reports
   gokanon flamegraph run-123             # View flame graphs in browser
   gokanon serve                          # Start interactive web dashboard
   gokanon serve -port=9000               # Start dashboard on custom port
@@ -56,8 +92,38 @@ Examples:
   gokanon baseline show -name=v1.0       # Show baseline details
   gokanon baseline delete -name=v1.0     # Delete a baseline
   gokanon doctor                         # Check your setup
+  gokanon doctor -ci -json               # CI preflight checks as JSON
   gokanon interactive                    # Start interactive mode
   gokanon completion bash                # Install bash completion
+  gokanon attach run-123 wall.prof -name=wall  # Attach a custom profile
+  gokanon annotate -kind=upgrade latest "upgraded Go to 1.22" # Explain a change in the results
+  gokanon reanalyze -all                 # Refresh suggestions after upgrading gokanon
+  gokanon serve -agents -addr=0.0.0.0    # Accept remote benchmark agents
+  gokanon agent -join http://ctl:8080 -labels os=linux,cpu=epyc # Run jobs for a controller
+  gokanon run -on cpu=epyc -controller=http://ctl:8080  # Run on a matching agent
+  gokanon daemon                         # Run the configured schedules
+  gokanon slo status                     # Show SLO compliance and burn rate
+  gokanon config path                    # Print where results and config live
+  go test -bench=. -count=5 | gokanon import # Import results produced elsewhere
+  gokanon import https://perfdata.golang.org/search?q=upload:20170101.1 # Import perf storage history
+  gokanon ci -baseline=main -threshold=10 # CI job with summary and annotations
+  gokanon bisect -benchmark=Parse -good=v1.0 # Find the commit that slowed Parse down
+  gokanon sync push -remote=s3://bucket/bench # Upload runs and baselines
+  gokanon profile BenchmarkHot -duration=30s # Focused CPU and memory profile of one benchmark
+  gokanon profile compare run-1 run-2 -type=cpu # Diff the stored profiles of two runs
+  gokanon snapshot -name=v2.3.0          # Archive the dashboard at a release
+  gokanon stability -count=30            # Find noisy benchmarks and how to stabilize them
+  gokanon prune -profiles-older-than=30d # Free disk space taken by old profiles
+  gokanon prune -keep-last=100 -older-than=90d # Delete old runs beyond the newest 100
+  gokanon search 'package:payments String' -since 30d # Find recent runs by name and metadata
+  gokanon fleet report -storage=api/.gokanon -storage=web/.gokanon # Regressions of several repos by owner
+  gokanon tui                            # Browse runs, trends and comparisons
+  gokanon push -perfdata=https://perf.internal # Upload the latest run to a perf data server
+  gokanon analyze -show-cached           # Replay cached AI analyses offline
+  gokanon telemetry status               # Show exactly what usage telemetry would send
+  gokanon plugins                        # List gokanon-<name> executables usable as commands
+
+Any other command runs a gokanon-<command> executable on PATH as a plugin.
 
 For more information about a command, use:
   gokanon <command> -h
@@ -73,11 +139,21 @@ func Execute() error {
 
 	command := os.Args[1]
 
+	// Only known commands are counted, never what was typed
+	known := true
+	defer func() {
+		if known {
+			recordUsage(command)
+		}
+	}()
+
 	switch command {
 	case "run":
 		return commands.Run()
 	case "list":
 		return commands.List()
+	case "show":
+		return commands.Show()
 	case "compare":
 		return commands.Compare()
 	case "export":
@@ -102,6 +178,52 @@ func Execute() error {
 		return commands.Interactive()
 	case "completion":
 		return commands.Completion()
+	case "attach":
+		return commands.Attach()
+	case "annotate":
+		return commands.Annotate()
+	case "reanalyze":
+		return commands.Reanalyze()
+	case "agent":
+		return commands.Agent()
+	case "daemon":
+		return commands.Daemon()
+	case "slo":
+		return commands.SLO()
+	case "config":
+		return commands.Config()
+	case "import":
+		return commands.Import()
+	case "projects":
+		return commands.Projects()
+	case "ci":
+		return commands.CI()
+	case "bisect":
+		return commands.Bisect()
+	case "sync":
+		return commands.Sync()
+	case "profile":
+		return commands.Profile()
+	case "snapshot":
+		return commands.Snapshot()
+	case "stability":
+		return commands.Stability()
+	case "prune":
+		return commands.Prune()
+	case "search":
+		return commands.Search()
+	case "fleet":
+		return commands.Fleet()
+	case "tui":
+		return commands.TUI()
+	case "push":
+		return commands.Push()
+	case "analyze":
+		return commands.Analyze()
+	case "telemetry":
+		return commands.Telemetry(Version)
+	case "plugins":
+		return commands.Plugins()
 	case "version", "-v", "--version":
 		fmt.Printf("gokanon version %s\n", Version)
 		if GitCommit != "none" {
@@ -115,6 +237,12 @@ func Execute() error {
 		fmt.Print(usageText)
 		return nil
 	default:
+		known = false
+		if path, err := plugin.Find(command); err == nil {
+			// Plugins are counted together, as their names may be proprietary
+			defer recordUsage("plugin")
+			return runPlugin(command, path)
+		}
 		return ui.NewError(
 			fmt.Sprintf("Unknown command: %s", command),
 			nil,
@@ -124,3 +252,68 @@ func Execute() error {
 		)
 	}
 }
+
+// runPlugin runs a plugin with the arguments after its name, telling it the
+// storage and configuration the built-in commands would use
+func runPlugin(name, path string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	executable, _ := os.Executable()
+	loc := config.Resolve(dir)
+	return plugin.Run(path, plugin.Context{
+		Version:     Version,
+		Executable:  executable,
+		Command:     name,
+		Args:        os.Args[2:],
+		WorkingDir:  dir,
+		Storage:     loc.Storage,
+		Config:      loc.Config,
+		ProjectRoot: loc.ProjectRoot,
+	})
+}
+
+// commandAliases maps the aliases of commands to the names they are
+// counted under
+var commandAliases = map[string]string{
+	"i":         "interactive",
+	"-v":        "version",
+	"--version": "version",
+	"-h":        "help",
+	"--help":    "help",
+}
+
+// recordUsage counts a command for telemetry, when it is on, and sends the
+// counts once they are due
+func recordUsage(command string) {
+	if alias, ok := commandAliases[command]; ok {
+		command = alias
+	}
+	telemetry.Record("command." + command)
+	telemetry.SendDue(Version)
+}
+
+// Exit statuses besides 1, which reports any other error and failed
+// checks, so that scripts can tell why gokanon failed. Invalid flags exit
+// with 2.
+const (
+	ExitNotFound     = 3 // A run, baseline or profile given does not exist
+	ExitNoBenchmarks = 4 // go test, or the output imported, reported no benchmark result
+)
+
+// ExitCode returns the exit status for an error returned by Execute. A
+// plugin's exit status is passed on.
+func ExitCode(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrRunNotFound), errors.Is(err, storage.ErrBaselineNotFound), errors.Is(err, storage.ErrProfileMissing):
+		return ExitNotFound
+	case errors.Is(err, runner.ErrNoBenchmarks):
+		return ExitNoBenchmarks
+	}
+	var pluginErr *plugin.ExitError
+	if errors.As(err, &pluginErr) {
+		return pluginErr.Code
+	}
+	return 1
+}
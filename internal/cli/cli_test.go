@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/plugin"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"other error", errors.New("boom"), 1},
+		{"missing run", fmt.Errorf("failed to load run: %w", storage.ErrRunNotFound), ExitNotFound},
+		{"missing baseline", ui.NewError("Failed to load baseline", storage.ErrBaselineNotFound), ExitNotFound},
+		{"missing profile", storage.ErrProfileMissing, ExitNotFound},
+		{"no benchmarks", fmt.Errorf("run failed: %w", runner.ErrNoBenchmarks), ExitNoBenchmarks},
+		{"plugin", &plugin.ExitError{Name: "report", Code: 7}, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
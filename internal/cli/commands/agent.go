@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/alenon/gokanon/internal/agent"
+	"github.com/alenon/gokanon/internal/dashboard"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Agent joins a dashboard controller and runs the benchmark jobs it hands out
+func Agent() error {
+	agentFlags := flag.NewFlagSet("agent", flag.ExitOnError)
+	join := agentFlags.String("join", "", "URL of the controller (a dashboard started with -agents)")
+	labelsFlag := agentFlags.String("labels", "", "Comma-separated key=value labels describing this machine (e.g. os=linux,cpu=epyc)")
+	name := agentFlags.String("name", "", "Agent name shown on the controller (default: hostname)")
+	token := agentFlags.String("token", os.Getenv("GOKANON_DASHBOARD_TOKEN"), "Controller access token (default: $GOKANON_DASHBOARD_TOKEN)")
+	packagePath := agentFlags.String("pkg", "", "Package path to benchmark (default: current directory)")
+	poll := agentFlags.Duration("poll", 5*time.Second, "How often to ask the controller for work when idle")
+	agentFlags.Parse(os.Args[2:])
+
+	if *join == "" {
+		return ui.NewError("Missing controller URL", nil,
+			"Example: gokanon agent -join http://controller:8080 -labels os=linux,cpu=epyc")
+	}
+	if *token == "" {
+		return ui.NewError("Missing controller access token", nil,
+			"Pass the token printed by 'gokanon serve -agents' with -token",
+			"Or set GOKANON_DASHBOARD_TOKEN")
+	}
+
+	labels := agent.DefaultLabels()
+	custom, err := agent.ParseLabels(*labelsFlag)
+	if err != nil {
+		return ui.NewError("Invalid labels", err, "Example: -labels os=linux,cpu=epyc")
+	}
+	for key, value := range custom {
+		labels[key] = value
+	}
+
+	if *name == "" {
+		*name, _ = os.Hostname()
+	}
+
+	run := func(req dashboard.JobRequest) (*models.BenchmarkRun, error) {
+		r := runner.NewRunner(*packagePath, req.Bench)
+		if req.Benchtime != "" {
+			r = r.WithBenchtime(req.Benchtime)
+		}
+		return r.Run()
+	}
+
+	ui.PrintHeader("Benchmark Agent")
+	fmt.Printf("%s %s\n", ui.Bold("Controller:"), *join)
+	fmt.Printf("%s %s\n", ui.Bold("Labels:"), agent.FormatLabels(labels))
+	fmt.Println("\nPress Ctrl+C to stop the agent")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	a := agent.New(*join, *token, *name, labels, run).
+		WithPollInterval(*poll).
+		WithLogger(ui.PrintInfo)
+	return a.Run(ctx)
+}
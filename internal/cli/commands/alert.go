@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/alenon/gokanon/internal/alert"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// checkAlerts evaluates the rate-of-change rules after a saved run, warning
+// about the ones that fire. Their channels are notified when a benchmark
+// starts firing, not again while it keeps firing. Failures are only warned
+// about, since the run itself succeeded.
+func checkAlerts(store *storage.Storage, rules []config.Alert) {
+	if len(rules) == 0 {
+		return
+	}
+	runs, err := store.List()
+	if err != nil {
+		ui.PrintWarning("Failed to evaluate alerts: %v", err)
+		return
+	}
+	// Failed and degraded runs would bend the trend
+	runs = models.WithoutFailed(runs)
+
+	firing, err := store.LoadFiringAlerts()
+	if err != nil {
+		ui.PrintWarning("Failed to load the alerts firing before: %v", err)
+		firing = make(map[string]map[string]time.Time)
+	}
+	next := make(map[string]map[string]time.Time)
+	for _, rule := range rules {
+		alerts, err := alert.Evaluate(rule, runs)
+		if err != nil {
+			ui.PrintWarning("Failed to evaluate alerts: %v", err)
+			if firing[rule.Name] != nil {
+				next[rule.Name] = firing[rule.Name]
+			}
+			continue
+		}
+		var started []alert.Alert
+		for _, a := range alerts {
+			ui.PrintWarning("Alert: %s", a.Message())
+			if next[rule.Name] == nil {
+				next[rule.Name] = make(map[string]time.Time)
+			}
+			since, ok := firing[rule.Name][a.Benchmark]
+			if !ok {
+				since = a.Timestamp
+				started = append(started, a)
+			}
+			next[rule.Name][a.Benchmark] = since
+		}
+		if err := alert.Notify(rule, started); err != nil {
+			ui.PrintWarning("Failed to notify alert %q: %v", rule.Name, err)
+			// Notified again after the next run
+			for _, a := range started {
+				delete(next[rule.Name], a.Benchmark)
+			}
+		}
+	}
+	if err := store.SaveFiringAlerts(next); err != nil {
+		ui.PrintWarning("Failed to record the alerts firing: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/aianalyzer"
+	"github.com/alenon/gokanon/internal/bisect"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Analyze handles the 'analyze' subcommand, asking the AI provider about
+// the comparison of two runs, or replaying the analyses cached before
+func Analyze() error {
+	analyzeFlags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	storageDir := analyzeFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	noCache := analyzeFlags.Bool("no-cache", false, "Ask the AI provider again instead of replaying a cached analysis")
+	showCached := analyzeFlags.Bool("show-cached", false, "Show the cached analyses of the given runs, or of all runs, without asking the provider")
+	withDiff := analyzeFlags.Bool("diff", false, "Include the Go code changed between the commits of the runs in the prompt, sending it to the provider")
+	analyzeFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	times := addTimeFlags(analyzeFlags, "default")
+	cfg, err := parseFlags(analyzeFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
+
+	store := storage.NewStorage(*storageDir)
+	args := analyzeFlags.Args()
+	if *showCached {
+		return showCachedAnalyses(store, args, timeFormat)
+	}
+	if len(args) != 0 && len(args) != 2 {
+		return fmt.Errorf("usage: gokanon analyze [<old-run> <new-run>] OR gokanon analyze -show-cached [<run>...]")
+	}
+	if len(args) == 0 {
+		args = []string{"previous", "latest"}
+	}
+
+	aiAnalyzer, err := aianalyzer.NewAnalyzer(aianalyzer.ConfigFromEnv(aiDefaults(cfg)))
+	if err != nil {
+		return ui.NewError("Failed to initialize AI analyzer", err,
+			"Check GOKANON_AI_PROVIDER and defaults.ai in "+config.DefaultFile)
+	}
+
+	oldRun, err := store.Resolve(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load old run: %w", err)
+	}
+	newRun, err := store.Resolve(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to load new run: %w", err)
+	}
+	// Normalized like compare does, so that both share cached analyses
+	runs, err := normalizeRuns(cfg, "", oldRun, newRun)
+	if err != nil {
+		return err
+	}
+	comparer, err := newComparer(cfg)
+	if err != nil {
+		return err
+	}
+	comparisons := comparer.Compare(runs[0], runs[1])
+	if len(comparisons) == 0 {
+		return fmt.Errorf("no benchmarks found in the two runs")
+	}
+
+	if *withDiff {
+		diff, err := codeDiff(oldRun, newRun)
+		if err != nil {
+			return ui.NewError("Failed to diff the commits of the runs", err,
+				"Run analyze inside the repository the runs were made in",
+				"Or leave out -diff to analyze the profiles only")
+		}
+		ui.PrintInfo("Including the changes of %s..%s (%d bytes) in the prompt", shortCommit(oldRun.Commit), shortCommit(newRun.Commit), len(diff))
+		aiAnalyzer.WithCodeDiff(diff)
+	}
+
+	spinner := ui.NewSpinner(fmt.Sprintf("Analyzing %s vs %s", oldRun.ID, newRun.ID))
+	spinner.Start()
+	analysis, cached, err := aiAnalyzer.WithCache(store, *noCache).ComparisonAnalysis(runs[0], runs[1], comparisons)
+	spinner.Stop()
+	if err != nil {
+		return ui.NewError("AI analysis failed", err,
+			"Check that the provider is reachable",
+			"Replay earlier analyses offline with: gokanon analyze -show-cached")
+	}
+	if analysis == nil {
+		return ui.NewError("AI analysis is disabled", nil,
+			"Enable it with: export GOKANON_AI_ENABLED=true",
+			"Or set defaults.ai.enabled in "+config.DefaultFile)
+	}
+
+	printAnalysis(*analysis, timeFormat)
+	if cached {
+		ui.PrintInfo("Replayed from the cache; use -no-cache to ask %s again", analysis.Provider)
+	}
+	return nil
+}
+
+// codeDiff returns the changes to Go code between the commits of two runs
+func codeDiff(oldRun, newRun *models.BenchmarkRun) (string, error) {
+	if oldRun.Commit == "" || newRun.Commit == "" {
+		return "", fmt.Errorf("runs %s and %s were not both made in a git repository", oldRun.ID, newRun.ID)
+	}
+	repo, err := bisect.OpenRepository(".")
+	if err != nil {
+		return "", err
+	}
+	return repo.Diff(oldRun.Commit, newRun.Commit)
+}
+
+// showCachedAnalyses prints the cached analyses of the given runs
+func showCachedAnalyses(store *storage.Storage, refs []string, timeFormat *ui.TimeFormat) error {
+	var runIDs []string
+	for _, ref := range refs {
+		run, err := store.Resolve(ref)
+		if err != nil {
+			return fmt.Errorf("failed to load run: %w", err)
+		}
+		runIDs = append(runIDs, run.ID)
+	}
+
+	analyses, err := store.ListAnalyses(runIDs...)
+	if err != nil {
+		return err
+	}
+	if len(analyses) == 0 {
+		ui.PrintInfo("No cached analyses")
+		return nil
+	}
+	for i, analysis := range analyses {
+		if i > 0 {
+			fmt.Println()
+		}
+		printAnalysis(analysis, timeFormat)
+	}
+	return nil
+}
+
+// printAnalysis prints an AI analysis below a header naming what it is about
+func printAnalysis(analysis models.AIAnalysis, timeFormat *ui.TimeFormat) {
+	runs := strings.Join(analysis.RunIDs, " vs ")
+	if runs == "" {
+		runs = "unknown run"
+	}
+	fmt.Printf("--- AI Analysis: %s of %s ---\n", analysis.Kind, runs)
+	fmt.Println(ui.Dim(fmt.Sprintf("%s/%s, %s", analysis.Provider, analysis.Model, timeFormat.Format(analysis.CreatedAt))))
+	fmt.Println(analysis.Response)
+}
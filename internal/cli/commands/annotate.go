@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Annotate handles the 'annotate' subcommand, adding a note to a saved run,
+// or listing or removing its notes
+func Annotate() error {
+	annotateFlags := flag.NewFlagSet("annotate", flag.ExitOnError)
+	storageDir := annotateFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	kind := annotateFlags.String("kind", "", "Category of the annotation (e.g. upgrade, infra, config)")
+	remove := annotateFlags.Int("remove", 0, "Remove the annotation with this number, as listed")
+	if _, err := parseFlags(annotateFlags, os.Args[2:]); err != nil {
+		return err
+	}
+
+	args := annotateFlags.Args()
+	if len(args) == 0 || len(args) > 2 || (*remove != 0 && len(args) != 1) {
+		return fmt.Errorf("usage: gokanon annotate [-kind=<kind>] <run> [\"message\"] or gokanon annotate -remove=<n> <run>")
+	}
+
+	store := storage.NewStorage(*storageDir)
+	run, err := store.Resolve(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load run: %w", err)
+	}
+
+	switch {
+	case *remove != 0:
+		if *remove < 0 || *remove > len(run.Annotations) {
+			return ui.NewError(fmt.Sprintf("No annotation %d on %s", *remove, run.ID), nil,
+				fmt.Sprintf("List the annotations with: gokanon annotate %s", run.ID))
+		}
+		removed := run.Annotations[*remove-1]
+		run.Annotations = append(run.Annotations[:*remove-1], run.Annotations[*remove:]...)
+		if err := store.SaveMetadata(run); err != nil {
+			return fmt.Errorf("failed to save run: %w", err)
+		}
+		ui.PrintSuccess("Removed annotation from %s: %s", run.ID, removed)
+		return nil
+
+	case len(args) == 1:
+		if len(run.Annotations) == 0 {
+			fmt.Printf("No annotations on %s.\n", run.ID)
+			return nil
+		}
+		for i, annotation := range run.Annotations {
+			fmt.Printf("%d. %s  %s\n", i+1, annotation.Time.Format("2006-01-02 15:04"), annotation)
+		}
+		return nil
+	}
+
+	message := strings.TrimSpace(args[1])
+	if message == "" {
+		return ui.NewError("Empty annotation", nil, "Example: gokanon annotate -kind=upgrade latest \"upgraded Go to 1.22\"")
+	}
+	annotation := models.Annotation{Time: time.Now(), Kind: strings.TrimSpace(*kind), Message: message}
+	run.Annotations = append(run.Annotations, annotation)
+	if err := store.SaveMetadata(run); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+	ui.PrintSuccess("Annotated %s: %s", run.ID, annotation)
+	return nil
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/profiler"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Attach handles the 'attach' subcommand, adding an externally collected profile to a run
+func Attach() error {
+	attachFlags := flag.NewFlagSet("attach", flag.ExitOnError)
+	storageDir := attachFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	name := attachFlags.String("name", "", "Name for the attached profile (e.g. fgprof, wall)")
+	sampleType := attachFlags.String("sample-type", "", "Sample type to analyze (default: profile's default)")
+	if _, err := parseFlags(attachFlags, os.Args[2:]); err != nil {
+		return err
+	}
+
+	args := attachFlags.Args()
+	if len(args) != 2 {
+		return fmt.Errorf("usage: gokanon attach <run> <profile-file> -name=<name> [-sample-type=<type>]")
+	}
+	runID, profilePath := args[0], args[1]
+
+	if err := storage.ValidateProfileName(*name); err != nil {
+		return ui.NewError(
+			"Invalid profile name",
+			err,
+			"Use -name to give the profile a short identifier",
+			"Example: gokanon attach run-123 wall.prof -name=wall",
+		)
+	}
+
+	store := storage.NewStorage(*storageDir)
+	run, err := store.Resolve(runID)
+	if err != nil {
+		return fmt.Errorf("failed to load run: %w", err)
+	}
+
+	data, err := os.ReadFile(profilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	// Validate the profile and sample type before storing anything
+	if err := profiler.NewAnalyzer().LoadCustomProfile(*name, data, *sampleType); err != nil {
+		types, _ := profiler.SampleTypes(data)
+		suggestions := []string{"Check that the file is a valid pprof profile"}
+		if len(types) > 0 {
+			suggestions = append(suggestions, fmt.Sprintf("Available sample types: %v", types))
+		}
+		return ui.NewError("Failed to load profile", err, suggestions...)
+	}
+
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read profile: %w", err)
+	}
+	defer f.Close()
+
+	if err := store.SaveAttachedProfile(run.ID, *name, f); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	attached := models.AttachedProfile{
+		Name:       *name,
+		Path:       store.GetAttachedProfilePath(run.ID, *name),
+		SampleType: *sampleType,
+	}
+	replaced := false
+	for i, p := range run.AttachedProfiles {
+		if p.Name == *name {
+			run.AttachedProfiles[i] = attached
+			replaced = true
+		}
+	}
+	if !replaced {
+		run.AttachedProfiles = append(run.AttachedProfiles, attached)
+	}
+
+	if _, err := analyzeStoredProfiles(store, run); err != nil {
+		return err
+	}
+
+	if err := store.Save(run); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	ui.PrintSuccess("Attached %s profile to run %s", *name, run.ID)
+	if run.ProfileSummary != nil {
+		displayProfileSummary(run.ID, run.ProfileSummary)
+	}
+
+	return nil
+}
+
+// analyzeStoredProfiles re-runs the profile analyzer over every profile stored
+// for a run and replaces its ProfileSummary with the result. It reports
+// whether any profile could be analyzed; the summary is left alone otherwise.
+func analyzeStoredProfiles(store *storage.Storage, run *models.BenchmarkRun) (bool, error) {
+	analyzer := profiler.NewAnalyzer()
+	loaded := false
+
+	if run.CPUProfile != "" {
+		if data, err := store.LoadProfile(run.ID, "cpu"); err == nil {
+			if err := analyzer.LoadCPUProfile(data); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze CPU profile: %v\n", err)
+			} else {
+				loaded = true
+			}
+		}
+	}
+
+	if run.MemoryProfile != "" {
+		if data, err := store.LoadProfile(run.ID, "memory"); err == nil {
+			if err := analyzer.LoadMemoryProfile(data); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze memory profile: %v\n", err)
+			} else {
+				loaded = true
+			}
+		}
+	}
+
+	if run.WarmupProfile != "" && run.MemoryProfile != "" {
+		if data, err := store.LoadProfile(run.ID, "warmup"); err == nil {
+			if err := analyzer.LoadWarmupProfile(data); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze warmup heap profile: %v\n", err)
+			}
+		}
+	}
+
+	if run.BlockProfile != "" {
+		if data, err := store.LoadProfile(run.ID, "block"); err == nil {
+			if err := analyzer.LoadBlockProfile(data); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze block profile: %v\n", err)
+			} else {
+				loaded = true
+			}
+		}
+	}
+
+	if run.MutexProfile != "" {
+		if data, err := store.LoadProfile(run.ID, "mutex"); err == nil {
+			if err := analyzer.LoadMutexProfile(data); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze mutex profile: %v\n", err)
+			} else {
+				loaded = true
+			}
+		}
+	}
+
+	for _, p := range run.AttachedProfiles {
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read %s profile: %v\n", p.Name, err)
+			continue
+		}
+		if err := analyzer.LoadCustomProfile(p.Name, data, p.SampleType); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		loaded = true
+	}
+
+	if !loaded {
+		return false, nil
+	}
+
+	summary, err := analyzer.Analyze()
+	if err != nil {
+		return false, fmt.Errorf("failed to analyze profiles: %w", err)
+	}
+	run.ProfileSummary = summary
+	runner.SetContention(run)
+	return true, nil
+}
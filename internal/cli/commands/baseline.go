@@ -7,6 +7,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/storage"
 	"github.com/alenon/gokanon/internal/ui"
 )
@@ -60,10 +61,12 @@ func Baseline() error {
 func baselineSave() error {
 	saveFlags := flag.NewFlagSet("baseline-save", flag.ExitOnError)
 	name := saveFlags.String("name", "", "Baseline name (required)")
-	runID := saveFlags.String("run", "", "Run ID to save as baseline (default: latest run)")
+	runID := saveFlags.String("run", "", "Run ID or reference to save as baseline (default: latest run)")
 	description := saveFlags.String("desc", "", "Baseline description")
-	storageDir := saveFlags.String("storage", ".gokanon", "Storage directory for results")
-	saveFlags.Parse(os.Args[3:])
+	storageDir := saveFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	if _, err := parseFlags(saveFlags, os.Args[3:]); err != nil {
+		return err
+	}
 
 	if *name == "" {
 		return ui.NewError(
@@ -73,13 +76,30 @@ func baselineSave() error {
 			"Example: gokanon baseline save -name=v1.0",
 		)
 	}
+	if err := storage.ValidateBaselineName(*name); err != nil {
+		return ui.NewError(
+			"Invalid baseline name",
+			err,
+			"Baseline names are file names, so they may not contain path separators or <>:\"|?*",
+			"Example: gokanon baseline save -name=v1.0",
+		)
+	}
 
 	store := storage.NewStorage(*storageDir)
 
 	// Determine which run to use
 	var targetRunID string
 	if *runID != "" {
-		targetRunID = *runID
+		run, err := store.Resolve(*runID)
+		if err != nil {
+			return ui.NewError(
+				fmt.Sprintf("Failed to find run '%s'", *runID),
+				err,
+				"Use a run ID or a reference such as latest~1 or commit:abc123",
+				"Try: gokanon list",
+			)
+		}
+		targetRunID = run.ID
 	} else {
 		// Use latest run
 		run, err := store.GetLatest()
@@ -123,8 +143,16 @@ func baselineSave() error {
 // baselineList lists all saved baselines
 func baselineList() error {
 	listFlags := flag.NewFlagSet("baseline-list", flag.ExitOnError)
-	storageDir := listFlags.String("storage", ".gokanon", "Storage directory for results")
-	listFlags.Parse(os.Args[3:])
+	storageDir := listFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	times := addTimeFlags(listFlags, "2006-01-02 15:04")
+	if _, err := parseFlags(listFlags, os.Args[3:]); err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
 
 	store := storage.NewStorage(*storageDir)
 	baselines, err := store.ListBaselines()
@@ -160,7 +188,7 @@ func baselineList() error {
 		}
 		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n",
 			baseline.Name,
-			baseline.CreatedAt.Format("2006-01-02 15:04"),
+			timeFormat.Format(baseline.CreatedAt),
 			len(baseline.Run.Results),
 			desc,
 		)
@@ -175,8 +203,16 @@ func baselineList() error {
 func baselineShow() error {
 	showFlags := flag.NewFlagSet("baseline-show", flag.ExitOnError)
 	name := showFlags.String("name", "", "Baseline name (required)")
-	storageDir := showFlags.String("storage", ".gokanon", "Storage directory for results")
-	showFlags.Parse(os.Args[3:])
+	storageDir := showFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	times := addTimeFlags(showFlags, "rfc3339")
+	if _, err := parseFlags(showFlags, os.Args[3:]); err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
 
 	if *name == "" {
 		return ui.NewError(
@@ -203,32 +239,21 @@ func baselineShow() error {
 
 	fmt.Printf("Name:        %s\n", ui.Bold(baseline.Name))
 	fmt.Printf("Run ID:      %s\n", baseline.RunID)
-	fmt.Printf("Created:     %s\n", baseline.CreatedAt.Format(time.RFC3339))
+	fmt.Printf("Created:     %s\n", timeFormat.Format(baseline.CreatedAt))
 	if baseline.Description != "" {
 		fmt.Printf("Description: %s\n", baseline.Description)
 	}
 	fmt.Println()
 
 	ui.PrintSection(ui.ChartEmoji, "Run Information")
-	fmt.Printf("  Timestamp:  %s\n", baseline.Run.Timestamp.Format(time.RFC3339))
+	fmt.Printf("  Timestamp:  %s\n", timeFormat.Format(baseline.Run.Timestamp))
 	fmt.Printf("  Duration:   %s\n", baseline.Run.Duration.String())
 	fmt.Printf("  Go Version: %s\n", baseline.Run.GoVersion)
 	fmt.Printf("  Package:    %s\n", baseline.Run.Package)
 	fmt.Println()
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Benchmark\tIterations\tns/op\tB/op\tallocs/op")
-	fmt.Fprintln(w, "---------\t----------\t-----\t----\t---------")
-	for _, result := range baseline.Run.Results {
-		fmt.Fprintf(w, "%s\t%d\t%.2f\t%d\t%d\n",
-			result.Name,
-			result.Iterations,
-			result.NsPerOp,
-			result.BytesPerOp,
-			result.AllocsPerOp,
-		)
-	}
-	w.Flush()
+	printResultsTable(baseline.Run.Results)
+	displayDescriptions(baseline.Run.Results)
 
 	return nil
 }
@@ -237,8 +262,10 @@ func baselineShow() error {
 func baselineDelete() error {
 	deleteFlags := flag.NewFlagSet("baseline-delete", flag.ExitOnError)
 	name := deleteFlags.String("name", "", "Baseline name (required)")
-	storageDir := deleteFlags.String("storage", ".gokanon", "Storage directory for results")
-	deleteFlags.Parse(os.Args[3:])
+	storageDir := deleteFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	if _, err := parseFlags(deleteFlags, os.Args[3:]); err != nil {
+		return err
+	}
 
 	if *name == "" {
 		return ui.NewError(
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/bisect"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/stats"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Bisect handles the 'bisect' subcommand
+func Bisect() error {
+	bisectFlags := flag.NewFlagSet("bisect", flag.ExitOnError)
+	benchmark := bisectFlags.String("benchmark", "", "Name of the regressed benchmark (required)")
+	good := bisectFlags.String("good", "", "Commit without the regression (required)")
+	bad := bisectFlags.String("bad", "HEAD", "Commit with the regression")
+	thresholdPercent := bisectFlags.Float64("threshold", 5.0, "Degradation from the good commit (%) beyond which a commit is regressed")
+	metric := bisectFlags.String("metric", "ns/op", "Metric to compare (ns/op or a custom metric name)")
+	packagePath := bisectFlags.String("pkg", "", "Package path (default: current directory)")
+	benchFilter := bisectFlags.String("bench", "", "Benchmark filter passed to -bench (default: only the regressed benchmark)")
+	benchtimeFlag := bisectFlags.String("benchtime", "", "Benchmark time (passed to -benchtime)")
+	count := bisectFlags.Int("count", 1, "Run the benchmark n times per commit to reduce noise")
+	bisectFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	if _, err := parseFlags(bisectFlags, os.Args[2:]); err != nil {
+		return err
+	}
+
+	if *benchmark == "" || *good == "" {
+		return ui.NewError("Missing -benchmark or -good", nil,
+			"Usage: gokanon bisect -benchmark=<name> -good=<commit> [-bad=<commit>]",
+			"Example: gokanon bisect -benchmark=ParseJSON -good=v1.2.0")
+	}
+	if *count < 1 {
+		return ui.NewError(fmt.Sprintf("Invalid -count: %d", *count), nil, "Use a count of at least 1, e.g. -count=5")
+	}
+	if *benchFilter == "" {
+		*benchFilter = benchmarkFilter(*benchmark)
+	}
+
+	repo, err := bisect.OpenRepository(".")
+	if err != nil {
+		return ui.NewError("Not in a git repository", err, "Run bisect from the repository of the benchmarked code")
+	}
+	goodCommit, between, badCommit, err := repo.Range(*good, *bad)
+	if err != nil {
+		return err
+	}
+
+	// Commits are checked out in a separate worktree so that the working
+	// tree, including uncommitted changes, is left alone
+	worktree, err := repo.AddWorktree()
+	if err != nil {
+		return ui.NewError("Failed to create a git worktree", err)
+	}
+	defer worktree.Remove()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ui.PrintHeader("Bisecting " + *benchmark)
+	fmt.Printf("Good: %s %s\n", goodCommit.Short(), goodCommit.Subject)
+	fmt.Printf("Bad:  %s %s\n", badCommit.Short(), badCommit.Subject)
+	fmt.Printf("%d commits in between, about %d steps\n\n", len(between), bisectSteps(len(between)))
+
+	unit := *metric
+	measure := func(commit bisect.Commit) (float64, error) {
+		if err := worktree.Checkout(commit); err != nil {
+			return 0, err
+		}
+		spinner := ui.NewSpinner(fmt.Sprintf("Benchmarking %s %s", commit.Short(), commit.Subject))
+		spinner.Start()
+		defer spinner.Stop()
+
+		// Only the benchmark: a failing unit test would skip the commit
+		r := runner.NewRunner(*packagePath, *benchFilter).WithDir(worktree.Dir()).WithContext(ctx).WithCount(*count).WithoutTests()
+		if *benchtimeFlag != "" {
+			r = r.WithBenchtime(*benchtimeFlag)
+		}
+		run, err := r.Run()
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if err != nil {
+			return 0, err
+		}
+		key := stats.BenchmarkKey(*benchmark)
+		for _, result := range run.Results {
+			if stats.BenchmarkKey(result.Name) != key {
+				continue
+			}
+			if !result.Measured() {
+				return 0, fmt.Errorf("benchmark %s did not run: %s", result.Name, result.Status)
+			}
+			value, ok := stats.MetricValue(result, *metric)
+			if !ok {
+				return 0, fmt.Errorf("benchmark %s does not report %s", result.Name, *metric)
+			}
+			return value, nil
+		}
+		return 0, fmt.Errorf("benchmark %s not found at this commit", *benchmark)
+	}
+
+	bisector := bisect.NewBisector(*thresholdPercent, measure).WithProgress(func(step bisect.Step, remaining int) {
+		printBisectStep(step, unit, remaining)
+	})
+
+	result, err := bisector.Run(goodCommit, between, badCommit)
+	if errors.Is(err, bisect.ErrNotRegressed) {
+		return ui.NewError(fmt.Sprintf("%s did not regress between %s and %s", *benchmark, goodCommit.Short(), badCommit.Short()), err,
+			fmt.Sprintf("Good: %.2f %s, bad: %.2f %s", result.Good.Value, unit, result.Bad.Value, unit),
+			"Lower -threshold or check the commit range")
+	}
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("bisect interrupted")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	if result.Ambiguous() {
+		ui.PrintWarning("Commits that could not be benchmarked hide the exact commit; the regression was introduced by one of:")
+		for _, commit := range result.Candidates {
+			fmt.Printf("  %s %s\n", commit.Short(), commit.Subject)
+		}
+		return nil
+	}
+	first := result.FirstStep()
+	ui.PrintSuccess("First regressed commit: %s %s", first.Commit.Short(), first.Commit.Subject)
+	fmt.Printf("  %s: %.2f %s at %s → %.2f %s (%s)\n", *benchmark,
+		result.Good.Value, unit, goodCommit.Short(), first.Value, unit, ui.FormatChange(first.DeltaPercent))
+	fmt.Printf("  Inspect it with: git show %s\n", result.First.Short())
+	return nil
+}
+
+// benchmarkFilter returns a -bench pattern that runs only the named
+// benchmark, matching each level of a sub-benchmark name exactly
+func benchmarkFilter(name string) string {
+	parts := strings.Split(stats.BenchmarkKey(name), "/")
+	for i, part := range parts {
+		if i == 0 {
+			part = "Benchmark" + part
+		}
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	return strings.Join(parts, "/")
+}
+
+// bisectSteps returns the number of measurements needed to search n
+// commits between the good and bad ones
+func bisectSteps(n int) int {
+	steps := 0
+	for ; n > 0; n /= 2 {
+		steps++
+	}
+	return steps
+}
+
+// printBisectStep prints the measurement of a commit during bisection
+func printBisectStep(step bisect.Step, unit string, remaining int) {
+	if step.Skipped {
+		ui.PrintWarning("%s %s: skipped, %s", step.Commit.Short(), step.Commit.Subject, step.Error)
+		return
+	}
+	verdict := "good     "
+	if step.Regressed {
+		verdict = "regressed"
+	}
+	fmt.Printf("  %s  %12.2f %s  %-10s %s  %s\n", step.Commit.Short(), step.Value, unit,
+		ui.FormatChange(step.DeltaPercent), verdict, step.Commit.Subject)
+	switch left := remaining - 1; {
+	case left == 1:
+		fmt.Println("  1 commit left to search")
+	case left > 1:
+		fmt.Printf("  %d commits left to search\n", left)
+	}
+}
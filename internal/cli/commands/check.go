@@ -4,75 +4,318 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"text/tabwriter"
 
+	"github.com/alenon/gokanon/internal/ci"
 	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/slo"
+	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
 	"github.com/alenon/gokanon/internal/threshold"
+	"github.com/alenon/gokanon/internal/ui"
 )
 
 // Check handles the 'check' subcommand for CI/CD
 func Check() error {
 	checkFlags := flag.NewFlagSet("check", flag.ExitOnError)
-	storageDir := checkFlags.String("storage", ".gokanon", "Storage directory for results")
+	storageDir := checkFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
 	latest := checkFlags.Bool("latest", false, "Check last two runs")
 	thresholdPercent := checkFlags.Float64("threshold", 5.0, "Maximum allowed performance degradation (%)")
-	checkFlags.Parse(os.Args[2:])
+	failOnRemoved := checkFlags.Bool("fail-on-removed", false, "Fail when a benchmark from the old run is missing in the new run")
+	memory := checkFlags.Bool("memory", false, "Also fail on B/op and allocs/op regressions beyond the threshold")
+	summaryOnly := checkFlags.Bool("summary-only", false, "Print only the outcome, the counts and the top 5 regressions and improvements")
+	checkFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	normalize := checkFlags.String("normalize", "", "Divide ns/op by this reference benchmark of each run (default: normalization.reference from the config)")
+	explain := checkFlags.Bool("explain", false, "Explain failing benchmarks: values, threshold and its source, historical variation and significance")
+	allowEnvMismatch := checkFlags.Bool("allow-env-mismatch", false, "Check runs taken with another Go release, build settings or machine, marking the output")
+	format := checkFlags.String("format", "text", "Output format: text, junit (JUnit XML for the test UI of Jenkins, GitLab or Bamboo)")
+	output := checkFlags.String("output", "", "Write the JUnit XML report to this file instead of stdout, and print the text report")
+	cfg, err := parseFlags(checkFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if *format != "text" && *format != "junit" {
+		return ui.NewError(fmt.Sprintf("Unknown format: %s", *format), nil, "Use -format=text or -format=junit")
+	}
+	if *output != "" && *format != "junit" {
+		return ui.NewError("-output requires -format=junit", nil, "Write a JUnit XML report: gokanon check --latest -format=junit -output=report.xml")
+	}
+
+	comparer, err := newComparer(cfg)
+	if err != nil {
+		return err
+	}
 
 	store := storage.NewStorage(*storageDir)
 
 	var oldID, newID string
 
 	if *latest {
-		runs, err := store.List()
+		runs, err := store.Latest(2, func(*models.BenchmarkRun) bool { return true })
 		if err != nil {
 			return fmt.Errorf("failed to list results: %w", err)
 		}
 		if len(runs) < 2 {
 			return fmt.Errorf("need at least 2 benchmark runs to check")
 		}
-		newID = runs[0].ID
-		oldID = runs[1].ID
+		if runs[0].Degraded() {
+			skipDegraded(&runs[0])
+			return nil
+		}
+		// The latest run is checked against the latest one measured in a
+		// stable environment
+		stable, err := store.Latest(2, func(run *models.BenchmarkRun) bool { return !run.Degraded() })
+		if err != nil {
+			return fmt.Errorf("failed to list results: %w", err)
+		}
+		if len(stable) < 2 {
+			return fmt.Errorf("need at least 2 benchmark runs to check, not counting environment-degraded runs")
+		}
+		newID, oldID = stable[0].ID, stable[1].ID
 	} else {
 		args := checkFlags.Args()
 		if len(args) != 2 {
-			return fmt.Errorf("usage: gokanon check <old-id> <new-id> OR gokanon check --latest")
+			return fmt.Errorf("usage: gokanon check <old-run> <new-run> OR gokanon check --latest")
 		}
 		oldID = args[0]
 		newID = args[1]
 	}
 
 	// Load benchmark runs
-	oldRun, err := store.Load(oldID)
+	oldRun, err := store.Resolve(oldID)
 	if err != nil {
 		return fmt.Errorf("failed to load old run: %w", err)
 	}
 
-	newRun, err := store.Load(newID)
+	newRun, err := store.Resolve(newID)
 	if err != nil {
 		return fmt.Errorf("failed to load new run: %w", err)
 	}
+	oldID, newID = oldRun.ID, newRun.ID
+	for _, run := range []*models.BenchmarkRun{newRun, oldRun} {
+		if run.Degraded() {
+			skipDegraded(run)
+			return nil
+		}
+	}
+
+	runs, err := normalizeRuns(cfg, *normalize, oldRun, newRun)
+	if err != nil {
+		return err
+	}
+	oldRun, newRun = runs[0], runs[1]
+
+	differences, err := checkEnvironments(oldRun, newRun, *allowEnvMismatch)
+	if err != nil {
+		return err
+	}
 
 	// Compare
-	comparer := compare.NewComparer()
 	comparisons := comparer.Compare(oldRun, newRun)
 
-	if len(comparisons) == 0 {
+	matched, _, removed := compare.Split(comparisons)
+	if len(matched) == 0 && (!*failOnRemoved || len(removed) == 0) {
 		return fmt.Errorf("no matching benchmarks found between the two runs")
 	}
 
 	// Check thresholds
-	checker := threshold.NewChecker(*thresholdPercent)
+	checker := newChecker(checkFlags, cfg, *thresholdPercent).WithFailOnRemoved(*failOnRemoved).WithMemory(*memory)
 	result := checker.Check(comparisons)
 
+	if *format == "junit" {
+		report := &ci.Report{Run: newRun, Baseline: oldID, Comparisons: comparisons, Result: result, Threshold: *thresholdPercent, EnvironmentDifferences: differences}
+		data, err := ci.JUnit(report)
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			// The report is the only output, so that it can be redirected
+			os.Stdout.Write(data)
+			if !result.Passed {
+				os.Exit(1)
+			}
+			return nil
+		}
+		if err := os.WriteFile(*output, data, 0644); err != nil {
+			return fmt.Errorf("failed to write JUnit report: %w", err)
+		}
+	}
+
 	// Display result
 	fmt.Printf("Threshold Check (max degradation: %.1f%%)\n", *thresholdPercent)
-	fmt.Printf("Comparing: %s vs %s\n\n", oldID, newID)
-	fmt.Println(threshold.FormatResult(result))
+	fmt.Printf("Comparing: %s vs %s\n", oldID, newID)
+	warnEnvironments(oldRun, newRun, differences)
+	fmt.Println()
+	if *summaryOnly {
+		printCheckSummary(comparisons, result)
+	} else {
+		fmt.Println(threshold.FormatResult(result))
+	}
+	if *explain {
+		explainCheck(store, checker, cfg.SLOs, comparisons, result)
+	}
 
 	// Exit with appropriate code for CI/CD
 	if !result.Passed {
+		if !*summaryOnly {
+			fmt.Printf("\n%s\n", compare.FormatDependencyDiff(compare.DiffDependencies(oldRun, newRun)))
+		}
 		os.Exit(1)
 	}
 
 	return nil
 }
+
+// printCheckSummary prints the outcome of a check with the counts of the
+// comparison and its top regressions and improvements
+func printCheckSummary(comparisons []models.Comparison, result *threshold.Result) {
+	fmt.Println(threshold.FormatVerdict(result))
+	fmt.Println(compare.Summary(comparisons))
+
+	regressions, improvements := compare.TopChanges(comparisons, compare.SummaryTopN)
+	if len(regressions) > 0 {
+		fmt.Println("\nTop regressions:")
+		for _, comp := range regressions {
+			fmt.Println(compare.FormatComparison(comp))
+		}
+	}
+	if len(improvements) > 0 {
+		fmt.Println("\nTop improvements:")
+		for _, comp := range improvements {
+			fmt.Println(compare.FormatComparison(comp))
+		}
+	}
+}
+
+// newChecker creates a threshold checker for the global threshold percent
+// with the per-benchmark thresholds of the project configuration
+func newChecker(fs *flag.FlagSet, cfg *config.Config, percent float64) *threshold.Checker {
+	source := "default"
+	switch flagSource(fs, "threshold") {
+	case sourceFlag:
+		source = "-threshold"
+	case sourceConfig:
+		source = "defaults.threshold in config"
+	}
+	return threshold.NewChecker(percent).WithSource(source).WithBenchmarkThresholds(cfg.Thresholds)
+}
+
+// explainCheck prints why each failing benchmark failed the check, and why
+// benchmarks beyond their threshold passed because the change may be noise.
+// The budgets of the benchmarks, their objectives, are shown with them.
+func explainCheck(store *storage.Storage, checker *threshold.Checker, objectives []config.SLO, comparisons []models.Comparison, result *threshold.Result) {
+	failed := make(map[string]bool)
+	for _, failure := range result.Failures {
+		failed[failure.BenchmarkName] = true
+	}
+
+	// Variation across the whole history tells regressions from noise
+	aggregates, err := store.Aggregates()
+	if err != nil {
+		ui.PrintWarning("Historical statistics unavailable: %v", err)
+	}
+	var runs []models.BenchmarkRun
+	if len(objectives) > 0 {
+		if runs, err = store.List(); err != nil {
+			ui.PrintWarning("Budgets unavailable: %v", err)
+			objectives = nil
+		}
+		runs = models.WithoutFailed(runs)
+	}
+
+	var explained int
+	for _, comp := range comparisons {
+		limit := checker.Limit(comp.Name)
+		if !failed[comp.Name] && comp.DeltaPercent <= limit.Percent {
+			continue
+		}
+		if explained == 0 {
+			fmt.Println("Explanation:")
+		}
+		explained++
+
+		verdict := "FAILED"
+		if !failed[comp.Name] {
+			verdict = "passed, the change may be noise"
+		}
+		fmt.Printf("\n  %s: %s\n", comp.Name, verdict)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		unit := comp.ValueUnit()
+		fmt.Fprintf(w, "    Baseline:\t%.2f %s\n", comp.OldNsPerOp, unit)
+		switch comp.Status {
+		case models.StatusRemoved:
+			fmt.Fprintf(w, "    New:\tmissing from the new run (-fail-on-removed)\n")
+		case models.StatusFailed:
+			fmt.Fprintf(w, "    New:\tbenchmark failed: %s\n", comp.Message)
+		default:
+			fmt.Fprintf(w, "    New:\t%.2f %s (%+.2f%%)\n", comp.NewNsPerOp, unit, comp.DeltaPercent)
+		}
+		for _, metric := range []*models.MetricComparison{comp.BytesPerOp, comp.AllocsPerOp} {
+			if metric != nil {
+				fmt.Fprintf(w, "    %s:\t%.0f → %.0f (%s)\n", metric.Name, metric.Old, metric.New, metric.Status)
+			}
+		}
+		fmt.Fprintf(w, "    Threshold:\t%.2f%% %s\n", limit.Percent, limit.Source)
+		for _, objective := range objectives {
+			if slo.Covers(objective, comp.Name) {
+				fmt.Fprintf(w, "    Budget:\t%s\n", budget(objective, runs))
+			}
+		}
+		fmt.Fprintf(w, "    History:\t%s\n", historicalVariation(aggregates, comp))
+		fmt.Fprintf(w, "    Significance:\t%s\n", significance(comp))
+		w.Flush()
+	}
+}
+
+// budget describes the state of an objective of a benchmark over the stored
+// runs
+func budget(objective config.SLO, runs []models.BenchmarkRun) string {
+	status, err := slo.Evaluate(objective, runs)
+	if err != nil {
+		return err.Error()
+	}
+	if status.State == slo.StateNoData {
+		return fmt.Sprintf("%s (%s), %s", status.Name, status.Objective, status.State)
+	}
+	return fmt.Sprintf("%s (%s), %s at %s, burn rate %.2f", status.Name, status.Objective,
+		status.State, slo.FormatValue(status.Value, status.Metric), status.BurnRate)
+}
+
+// historicalVariation describes the coefficient of variation of a
+// benchmark's ns/op across all stored runs, compared to the change
+func historicalVariation(aggregates *storage.Aggregates, comp models.Comparison) string {
+	if aggregates == nil || aggregates.Benchmarks[comp.Name] == nil || aggregates.Benchmarks[comp.Name].Count < 2 {
+		return "not enough runs to estimate the variation"
+	}
+	s := stats.FromAggregate(comp.Name, aggregates.Benchmarks[comp.Name])
+	description := fmt.Sprintf("CV %.1f%% over %d runs", s.CV, s.Count)
+	if s.CV > 0 && comp.DeltaPercent > 0 {
+		description += fmt.Sprintf(", the change is %.1fx the typical variation", comp.DeltaPercent/s.CV)
+	}
+	return description
+}
+
+// significance describes whether the change passed the significance test
+func significance(comp models.Comparison) string {
+	if comp.PValue == nil {
+		return "not tested, single sample per run (use -count to test); treated as significant"
+	}
+	verdict := "significant"
+	relation := "<="
+	if !comp.Significant() {
+		verdict, relation = "not significant", ">"
+	}
+	return fmt.Sprintf("p=%.3f %s %.2f with %d vs %d samples, %s",
+		*comp.PValue, relation, models.SignificanceLevel, comp.OldSamples, comp.NewSamples, verdict)
+}
+
+// skipDegraded explains why a run recorded in an unstable environment is
+// not checked, rather than failing or passing on numbers that would mislead
+func skipDegraded(run *models.BenchmarkRun) {
+	ui.PrintWarning("Run %s was recorded in a degraded environment: %s", run.ID, run.Error)
+	fmt.Println("Not checked: its results would mislead. Rerun the benchmarks on a quieter machine.")
+}
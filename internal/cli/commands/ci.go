@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/alenon/gokanon/internal/ci"
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/threshold"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// CI handles the 'ci' subcommand, running benchmarks and checking them
+// against a baseline in a GitHub Actions job
+func CI() error {
+	ciFlags := flag.NewFlagSet("ci", flag.ExitOnError)
+	baselineName := ciFlags.String("baseline", "main", "Baseline to compare against")
+	thresholdPercent := ciFlags.Float64("threshold", 5.0, "Maximum allowed performance degradation (%)")
+	failOnRemoved := ciFlags.Bool("fail-on-removed", false, "Fail when a benchmark from the baseline is missing in the new run")
+	memory := ciFlags.Bool("memory", false, "Also fail on B/op and allocs/op regressions beyond the threshold")
+	allowEnvMismatch := ciFlags.Bool("allow-env-mismatch", false, "Check against a baseline taken with another Go release, build settings or machine, marking the results")
+	benchFilter := ciFlags.String("bench", ".", "Benchmark filter (passed to -bench)")
+	packagePath := ciFlags.String("pkg", "", "Package path (default: current directory)")
+	benchtimeFlag := ciFlags.String("benchtime", "", "Benchmark time (passed to -benchtime)")
+	count := ciFlags.Int("count", 1, "Run each benchmark n times and compare the samples statistically")
+	storageDir := ciFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	ciFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	summaryPath := ciFlags.String("summary", os.Getenv("GITHUB_STEP_SUMMARY"), "File to append the Markdown job summary to (default: $GITHUB_STEP_SUMMARY)")
+	cfg, err := parseFlags(ciFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if *count < 1 {
+		return ui.NewError(fmt.Sprintf("Invalid -count: %d", *count), nil, "Use a count of at least 1, e.g. -count=10")
+	}
+
+	extractors, err := metricExtractors(cfg)
+	if err != nil {
+		return err
+	}
+	comparer, err := newComparer(cfg)
+	if err != nil {
+		return err
+	}
+
+	store := storage.NewStorage(*storageDir)
+
+	// A missing baseline is not an error: the first CI run has none yet
+	baseline, err := store.LoadBaseline(*baselineName)
+	if errors.Is(err, storage.ErrBaselineNotFound) {
+		baseline = nil
+	} else if err != nil {
+		return ui.NewError(fmt.Sprintf("Failed to load baseline '%s'", *baselineName), err)
+	}
+
+	lock, err := acquireRunLock(store, true)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ui.PrintHeader("Running Benchmarks")
+	fmt.Println()
+
+	r := runner.NewRunner(*packagePath, *benchFilter).WithContext(ctx).WithLiveStatus(store)
+	if *benchtimeFlag != "" {
+		r = r.WithBenchtime(*benchtimeFlag)
+	}
+	if *count > 1 {
+		r = r.WithCount(*count)
+	}
+	if len(extractors) > 0 {
+		r = r.WithMetricExtractors(extractors)
+	}
+
+	run, err := r.Run()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ui.NewError("Benchmark run interrupted, no results were saved", err)
+		}
+		return ui.ErrBenchmarkFailed(err)
+	}
+	if err := saveAndReport(store, *storageDir, run); err != nil {
+		return err
+	}
+	autoPrune(store, cfg.Retention)
+
+	report := &ci.Report{Run: run, Baseline: *baselineName, Threshold: *thresholdPercent}
+	fmt.Println()
+	if baseline == nil || baseline.Run == nil {
+		ui.PrintWarning("No baseline '%s' to compare with; skipping the threshold check", *baselineName)
+		fmt.Printf("Save this run as the baseline with: gokanon baseline save -name=%s -run=%s\n", *baselineName, run.ID)
+	} else {
+		runs, err := normalizeRuns(cfg, "", baseline.Run, run)
+		if err != nil {
+			return err
+		}
+		report.EnvironmentDifferences, err = checkEnvironments(runs[0], runs[1], *allowEnvMismatch)
+		if err != nil {
+			return err
+		}
+		report.Comparisons = comparer.Compare(runs[0], runs[1])
+		report.Result = newChecker(ciFlags, cfg, *thresholdPercent).WithFailOnRemoved(*failOnRemoved).WithMemory(*memory).Check(report.Comparisons)
+
+		fmt.Printf("Threshold Check against baseline '%s' (max degradation: %.1f%%)\n", *baselineName, *thresholdPercent)
+		warnEnvironments(runs[0], runs[1], report.EnvironmentDifferences)
+		fmt.Println()
+		for _, comp := range report.Comparisons {
+			fmt.Println(compare.FormatComparison(comp))
+		}
+		fmt.Println()
+		fmt.Println(threshold.FormatResult(report.Result))
+	}
+
+	for _, line := range ci.Annotations(report) {
+		fmt.Println(line)
+	}
+
+	if *summaryPath != "" {
+		if err := ci.AppendStepSummary(*summaryPath, ci.JobSummary(report)); err != nil {
+			ui.PrintWarning("Failed to write the job summary: %v", err)
+		}
+	}
+
+	if !report.Passed() {
+		return ui.NewError(
+			fmt.Sprintf("%d benchmark(s) failed the threshold check", len(report.Result.Failures)),
+			nil,
+			fmt.Sprintf("Raise -threshold if the change is expected, or update the baseline: gokanon baseline save -name=%s -run=%s", *baselineName, run.ID),
+		)
+	}
+	return nil
+}
@@ -1,16 +1,45 @@
 package commands
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/doctor"
+	"github.com/alenon/gokanon/internal/fleet"
 	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/profiler"
+	"github.com/alenon/gokanon/internal/sink"
 	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/telemetry"
+	"github.com/alenon/gokanon/internal/threshold"
+	"github.com/google/pprof/profile"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the project registry updated by runs out of the user's data directory
+	dataHome, err := os.MkdirTemp("", "gokanon-data-*")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_DATA_HOME", dataHome)
+	code := m.Run()
+	os.RemoveAll(dataHome)
+	os.Exit(code)
+}
+
 // Helper function to create test storage with sample data
 func setupTestStorage(t *testing.T) (*storage.Storage, string, func()) {
 	tempDir := t.TempDir()
@@ -51,6 +80,28 @@ func withArgs(args []string, fn func()) {
 	fn()
 }
 
+// captureOutput returns what fn printed to stdout
+func captureOutput(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	var output bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		output.ReadFrom(r)
+		close(done)
+	}()
+	fn()
+	w.Close()
+	<-done
+	return output.String()
+}
+
 func TestListWithEmptyStorage(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -269,6 +320,292 @@ func TestExportNoData(t *testing.T) {
 	})
 }
 
+func TestExportBadge(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	outputFile := filepath.Join(tempDir, "score.svg")
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-latest", "-format=badge", "-output=" + outputFile}, func() {
+		if err := Export(); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("badge not written: %v", err)
+	}
+	if !strings.Contains(string(content), "perf score") {
+		t.Errorf("unexpected badge: %s", content)
+	}
+}
+
+func TestExportNotebook(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	outputFile := filepath.Join(tempDir, "comparison.ipynb")
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-latest", "-format=ipynb", "-output=" + outputFile}, func() {
+		if err := Export(); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("notebook not written: %v", err)
+	}
+	if !strings.Contains(string(content), "BenchmarkAnother") {
+		t.Errorf("notebook does not embed the run data")
+	}
+}
+
+func TestExportRunPage(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	outputFile := filepath.Join(tempDir, "run.html")
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-run=latest", "-output=" + outputFile}, func() {
+		if err := Export(); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("run page not written: %v", err)
+	}
+	if !strings.Contains(string(content), "Benchmark Run test-run-1") {
+		t.Errorf("run page does not show the latest run")
+	}
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-run=latest", "-format=csv"}, func() {
+		if err := Export(); err == nil {
+			t.Error("Expected an error for a run page in CSV")
+		}
+	})
+}
+
+func TestExportSummaryOnly(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	outputFile := filepath.Join(tempDir, "summary.md")
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-latest", "-format=markdown", "-summary-only", "-output=" + outputFile}, func() {
+		if err := Export(); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	})
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("summary not written: %v", err)
+	}
+	if !strings.Contains(string(content), "**Top improvements**") || strings.Contains(string(content), "| Status |") {
+		t.Errorf("Expected only the summary, got:\n%s", content)
+	}
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-latest", "-format=html", "-summary-only"}, func() {
+		if err := Export(); err == nil {
+			t.Error("Expected an error for a summary in HTML")
+		}
+	})
+}
+
+func TestExportParquetHistory(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	outputFile := filepath.Join(tempDir, "history.parquet")
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-format=parquet", "-history", "-output=" + outputFile}, func() {
+		if err := Export(); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	})
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("parquet file not written: %v", err)
+	}
+	for _, id := range []string{"test-run-1", "test-run-2", "test-run-3"} {
+		if !strings.Contains(string(content), id) {
+			t.Errorf("history is missing run %s", id)
+		}
+	}
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-format=csv", "-history"}, func() {
+		if err := Export(); err == nil {
+			t.Error("expected an error for -history with a format other than parquet")
+		}
+	})
+}
+
+func TestConfigPath(t *testing.T) {
+	for _, args := range [][]string{
+		{"gokanon", "config"},
+		{"gokanon", "config", "path"},
+		{"gokanon", "config", "path", "storage"},
+	} {
+		withArgs(args, func() {
+			if err := Config(); err != nil {
+				t.Errorf("%v failed: %v", args, err)
+			}
+		})
+	}
+
+	withArgs([]string{"gokanon", "config", "path", "unknown"}, func() {
+		if err := Config(); err == nil {
+			t.Error("expected an error for an unknown location")
+		}
+	})
+}
+
+func TestParseFlagsDefaults(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, config.DefaultFile)
+	os.WriteFile(configFile, []byte("defaults:\n  storage: results\n  bench: Parse\n  count: 5\n  threshold: 7.5\n  export:\n    format: markdown\n"), 0644)
+
+	newFlags := func(name string) (*flag.FlagSet, map[string]*string) {
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		values := map[string]*string{
+			"storage": fs.String("storage", "default", ""),
+			"bench":   fs.String("bench", ".", ""),
+			"format":  fs.String("format", "html", ""),
+		}
+		fs.String("config", configFile, "")
+		fs.Int("count", 1, "")
+		fs.Float64("threshold", 5, "")
+		return fs, values
+	}
+
+	fs, values := newFlags("export")
+	if _, err := parseFlags(fs, []string{"-bench=Encode"}); err != nil {
+		t.Fatalf("parseFlags failed: %v", err)
+	}
+	if want := filepath.Join(dir, "results"); *values["storage"] != want {
+		t.Errorf("storage = %s, want %s", *values["storage"], want)
+	}
+	if *values["bench"] != "Encode" {
+		t.Errorf("bench = %s, want the flag given on the command line", *values["bench"])
+	}
+	if *values["format"] != "markdown" {
+		t.Errorf("format = %s, want markdown", *values["format"])
+	}
+	if count := fs.Lookup("count").Value.String(); count != "5" {
+		t.Errorf("count = %s, want 5", count)
+	}
+	if threshold := fs.Lookup("threshold").Value.String(); threshold != "7.5" {
+		t.Errorf("threshold = %s, want 7.5", threshold)
+	}
+	for name, want := range map[string]string{"bench": sourceFlag, "threshold": sourceConfig, "format": sourceConfig, "config": sourceDefault} {
+		if source := flagSource(fs, name); source != want {
+			t.Errorf("source of -%s = %s, want %s", name, source, want)
+		}
+	}
+
+	// A flag given with the configured value still comes from the command line
+	fs, _ = newFlags("check")
+	if _, err := parseFlags(fs, []string{"-threshold=7.5"}); err != nil {
+		t.Fatalf("parseFlags failed: %v", err)
+	}
+	if source := flagSource(fs, "threshold"); source != sourceFlag {
+		t.Errorf("source of -threshold = %s, want %s", source, sourceFlag)
+	}
+
+	// The export format does not apply to other commands' -format
+	fs, values = newFlags("status")
+	if _, err := parseFlags(fs, nil); err != nil {
+		t.Fatalf("parseFlags failed: %v", err)
+	}
+	if *values["format"] != "html" {
+		t.Errorf("format = %s, want the flag default", *values["format"])
+	}
+
+	// Nor does the bench filter apply to bisect's
+	fs, values = newFlags("bisect")
+	if _, err := parseFlags(fs, nil); err != nil {
+		t.Fatalf("parseFlags failed: %v", err)
+	}
+	if *values["bench"] != "." {
+		t.Errorf("bench = %s, want the flag default", *values["bench"])
+	}
+	if count := fs.Lookup("count").Value.String(); count != "5" {
+		t.Errorf("bisect count = %s, want 5", count)
+	}
+	if threshold := fs.Lookup("threshold").Value.String(); threshold != "7.5" {
+		t.Errorf("bisect threshold = %s, want 7.5", threshold)
+	}
+
+	// Nor do the count and threshold apply to stability's own
+	fs, _ = newFlags("stability")
+	if _, err := parseFlags(fs, nil); err != nil {
+		t.Fatalf("parseFlags failed: %v", err)
+	}
+	if count := fs.Lookup("count").Value.String(); count != "1" {
+		t.Errorf("count = %s, want the flag default", count)
+	}
+	if threshold := fs.Lookup("threshold").Value.String(); threshold != "5" {
+		t.Errorf("threshold = %s, want the flag default", threshold)
+	}
+}
+
+func TestOpenFlags(t *testing.T) {
+	for _, name := range []string{"CI", "CONTINUOUS_INTEGRATION", "GITHUB_ACTIONS", "GITLAB_CI", "CIRCLECI", "TRAVIS", "JENKINS_URL", "BUILDKITE", "DRONE"} {
+		t.Setenv(name, "")
+	}
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, config.DefaultFile)
+	os.WriteFile(configFile, []byte("defaults:\n  open: true\n"), 0644)
+
+	parse := func(args ...string) *openFlags {
+		fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+		browser := addOpenFlags(fs, "dashboard")
+		fs.String("config", configFile, "")
+		if _, err := parseFlags(fs, args); err != nil {
+			t.Fatalf("parseFlags failed: %v", err)
+		}
+		return browser
+	}
+
+	if !parse().enabled() {
+		t.Error("defaults.open did not enable -open")
+	}
+	if parse("-no-open").enabled() {
+		t.Error("-no-open did not win over defaults.open")
+	}
+	t.Setenv("CI", "true")
+	if parse("-open").enabled() {
+		t.Error("-open opened a browser in CI")
+	}
+}
+
+func TestSLOStatus(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	noConfig := filepath.Join(tempDir, "none.yaml")
+	withArgs([]string{"gokanon", "slo", "status", "-storage=" + tempDir, "-config=" + noConfig}, func() {
+		if err := SLO(); err == nil || !strings.Contains(err.Error(), "No SLOs configured") {
+			t.Errorf("expected an error without SLOs, got %v", err)
+		}
+	})
+
+	configFile := filepath.Join(tempDir, "gokanon.yaml")
+	os.WriteFile(configFile, []byte("slos:\n  - name: fast\n    benchmark: Test\n    max: 1s\n"), 0644)
+	for _, format := range []string{"table", "json"} {
+		withArgs([]string{"gokanon", "slo", "status", "-storage=" + tempDir, "-config=" + configFile, "-format=" + format}, func() {
+			if err := SLO(); err != nil {
+				t.Errorf("slo status -format=%s failed: %v", format, err)
+			}
+		})
+	}
+
+	withArgs([]string{"gokanon", "slo", "unknown"}, func() {
+		if err := SLO(); err == nil {
+			t.Error("expected an error for an unknown subcommand")
+		}
+	})
+}
+
 func TestInteractiveCommand(t *testing.T) {
 	// Interactive mode requires terminal interaction, skip actual execution
 	// Just verify the command function exists and can be called
@@ -299,6 +636,171 @@ func TestTrendCommand(t *testing.T) {
 	})
 }
 
+func TestTrendHistory(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	// The last two runs are analyzed, with statistics over all three
+	output := captureOutput(t, func() {
+		withArgs([]string{"gokanon", "trend", "-storage=" + tempDir, "-last=2", "-benchmark=BenchmarkTest"}, func() {
+			if err := Trend(); err != nil {
+				t.Errorf("Trend failed: %v", err)
+			}
+		})
+	})
+	for _, want := range []string{"(2 runs)", "All runs: mean 110.00 ns/op", "over 3 runs"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestTrendWithBaseline(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	if _, err := store.SaveBaseline("v1.0", "test-run-3", "", nil); err != nil {
+		t.Fatalf("Failed to save baseline: %v", err)
+	}
+
+	withArgs([]string{"gokanon", "trend", "-storage=" + tempDir}, func() {
+		if err := Trend(); err != nil {
+			t.Errorf("Trend with a baseline failed: %v", err)
+		}
+	})
+}
+
+func TestTrendSparkline(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "trend", "-storage=" + tempDir, "-benchmark=BenchmarkTest", "-sparkline"}, func() {
+		if err := Trend(); err != nil {
+			t.Errorf("Trend -sparkline failed: %v", err)
+		}
+	})
+}
+
+func TestTrendAnomalies(t *testing.T) {
+	tempDir := t.TempDir()
+	store := storage.NewStorage(tempDir)
+	now := time.Now()
+	for i, v := range []float64{100, 101, 99, 100, 101, 99, 150, 100} {
+		run := &models.BenchmarkRun{
+			ID:            fmt.Sprintf("anomaly-run-%d", i),
+			Timestamp:     now.Add(time.Duration(i) * time.Hour),
+			Commit:        fmt.Sprintf("%d%039d", i, 0),
+			CommitMessage: fmt.Sprintf("Change %d", i),
+			Results:       []models.BenchmarkResult{{Name: "BenchmarkParse", NsPerOp: v}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Failed to create test data: %v", err)
+		}
+	}
+
+	for _, method := range []string{"stddev", "iqr", "median"} {
+		var err error
+		output := captureOutput(t, func() {
+			withArgs([]string{"gokanon", "trend", "-storage=" + tempDir, "-anomalies", "-anomaly-method=" + method}, func() {
+				err = Trend()
+			})
+		})
+		if err != nil {
+			t.Fatalf("Trend -anomalies -anomaly-method=%s failed: %v", method, err)
+		}
+		if strings.Count(output, "Anomaly:") != 1 || !strings.Contains(output, "spike in run anomaly-run-6") ||
+			!strings.Contains(output, `commit 600000000000 "Change 6"`) {
+			t.Errorf("Expected the spike of anomaly-run-6 with its commit (%s), got:\n%s", method, output)
+		}
+	}
+
+	withArgs([]string{"gokanon", "trend", "-storage=" + tempDir, "-anomalies", "-anomaly-method=zscore"}, func() {
+		if err := Trend(); err == nil || !strings.Contains(err.Error(), "unknown anomaly method") {
+			t.Errorf("Expected an unknown method error, got %v", err)
+		}
+	})
+}
+
+func TestTrendFamily(t *testing.T) {
+	tempDir := t.TempDir()
+	store := storage.NewStorage(tempDir)
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		run := &models.BenchmarkRun{
+			ID:        "family-run-" + string(rune('1'+i)),
+			Timestamp: now.Add(time.Duration(i) * time.Hour),
+			Results: []models.BenchmarkResult{
+				{Name: "Parse/small-8", Parent: "Parse", Variant: "small", NsPerOp: float64(100 + i)},
+				{Name: "Parse/large-8", Parent: "Parse", Variant: "large", NsPerOp: float64(900 + i)},
+				{Name: "Encode-8", NsPerOp: 50},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Failed to create test data: %v", err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-benchmark=Parse"},
+		{"-benchmark=Parse", "-sparkline"},
+	} {
+		withArgs(append([]string{"gokanon", "trend", "-storage=" + tempDir}, args...), func() {
+			if err := Trend(); err != nil {
+				t.Errorf("Trend %v failed: %v", args, err)
+			}
+		})
+	}
+
+	families := map[string]string{
+		"Parse/small-8": "Parse",
+		"Encode-8":      "Encode",
+		"Parse/large-8": "Parse",
+		"ParseAll-8":    "ParseAll",
+	}
+	want := []string{"Encode-8", "Parse/large-8", "Parse/small-8", "ParseAll-8"}
+	if got := familyOrder(families); !slices.Equal(got, want) {
+		t.Errorf("familyOrder = %v, want %v", got, want)
+	}
+}
+
+func TestFailedRuns(t *testing.T) {
+	tempDir := t.TempDir()
+	store := storage.NewStorage(tempDir)
+	now := time.Now()
+	for i, status := range []string{"", models.StatusFailed} {
+		run := &models.BenchmarkRun{
+			ID:        "failed-run-" + string(rune('1'+i)),
+			Timestamp: now.Add(time.Duration(i) * time.Hour),
+			Status:    status,
+			Results:   []models.BenchmarkResult{{Name: "Parse-8", NsPerOp: float64(100 + i)}},
+		}
+		if status != "" {
+			run.Error = "BenchmarkParse: panic: runtime error: index out of range"
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Failed to create test data: %v", err)
+		}
+	}
+
+	withArgs([]string{"gokanon", "list", "-storage=" + tempDir, "-failed"}, func() {
+		if err := List(); err != nil {
+			t.Errorf("List -failed failed: %v", err)
+		}
+	})
+
+	// Trends leave the failed run out unless asked to include it
+	withArgs([]string{"gokanon", "trend", "-storage=" + tempDir}, func() {
+		if err := Trend(); err == nil {
+			t.Error("Expected trend to leave the failed run out")
+		}
+	})
+	withArgs([]string{"gokanon", "trend", "-storage=" + tempDir, "-include-failed"}, func() {
+		if err := Trend(); err != nil {
+			t.Errorf("Trend -include-failed failed: %v", err)
+		}
+	})
+}
+
 func TestServeCommand(t *testing.T) {
 	// Serve starts a web server, which we can't easily test in unit tests
 	// We'll just verify the command doesn't panic on startup
@@ -457,6 +959,44 @@ func BenchmarkSimple(b *testing.B) {
 	}
 }
 
+func TestRunCommandSkipIfUnstable(t *testing.T) {
+	tempDir := t.TempDir()
+	benchmarkCode := `package test
+
+import "testing"
+
+func BenchmarkSimple(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = i * 2
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "bench_test.go"), []byte(benchmarkCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	oldStability := environmentStability
+	defer func() { environmentStability = oldStability }()
+	environmentStability = func() doctor.CheckResult {
+		return doctor.CheckResult{Name: "Environment Stability", Message: "Results may be noisy: load average 12.00 on 4 CPUs"}
+	}
+
+	storageDir := filepath.Join(tempDir, ".gokanon")
+	withArgs([]string{"gokanon", "run", "-pkg=" + tempDir, "-storage=" + storageDir, "-skip-if-unstable"}, func() {
+		if err := Run(); err != nil {
+			t.Fatalf("Run -skip-if-unstable failed: %v", err)
+		}
+	})
+
+	runs, err := storage.NewStorage(storageDir).List()
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Expected one recorded run, got %d (%v)", len(runs), err)
+	}
+	if run := runs[0]; !run.Degraded() || len(run.Results) != 0 || !strings.Contains(run.Error, "load average 12.00") {
+		t.Errorf("Expected a degraded run without results, got status %q, error %q and %d results", run.Status, run.Error, len(run.Results))
+	}
+}
+
 func TestRunCommandMissingBenchmarks(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -496,6 +1036,30 @@ func TestRunCommandInvalidPackage(t *testing.T) {
 	})
 }
 
+func TestRunCommandInvalidModeOptions(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), ".gokanon")
+
+	for _, tt := range []struct {
+		args    []string
+		errText string
+	}{
+		{[]string{"-startup=./cmd/server", "-cpu=1,2"}, "cannot be combined with -startup"},
+		{[]string{"-startup=./cmd/server", "-startup-ready=udp://localhost:53"}, "Invalid -startup-ready"},
+		{[]string{"-startup=./cmd/server", "-startup-iterations=0"}, "Invalid -startup-iterations"},
+		{[]string{"-load=http://localhost:8080", "-startup=./cmd/server"}, "cannot be combined with -load"},
+		{[]string{"-load=localhost:8080"}, "Invalid -load"},
+		{[]string{"-load=http://localhost:8080", "-load-header=Authorization"}, "Invalid -load-header"},
+		{[]string{"-load=http://localhost:8080", "-load-concurrency=0"}, "Invalid -load-concurrency"},
+	} {
+		withArgs(append([]string{"gokanon", "run", "-storage=" + storageDir}, tt.args...), func() {
+			err := Run()
+			if err == nil || !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("%v: error = %v, want %q", tt.args, err, tt.errText)
+			}
+		})
+	}
+}
+
 func TestRunCommandInvalidProfileOption(t *testing.T) {
 	tempDir := t.TempDir()
 	storageDir := filepath.Join(tempDir, ".gokanon")
@@ -511,21 +1075,101 @@ func TestRunCommandInvalidProfileOption(t *testing.T) {
 	})
 }
 
-func TestRunCommandWithCPUFlag(t *testing.T) {
-	tempDir := t.TempDir()
+func TestRunCommandInvalidStress(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), ".gokanon")
 
-	// Create a simple benchmark file
-	testFile := filepath.Join(tempDir, "bench_test.go")
-	benchmarkCode := `package test
+	for args, want := range map[string]string{
+		"-parallelism=1,x": "Invalid -parallelism",
+		"-parallelism=8":   "Invalid -parallelism",
+		"-cpu=1,2":         "cannot be combined with -cpu",
+	} {
+		withArgs([]string{"gokanon", "run", "-stress", args, "-storage=" + storageDir}, func() {
+			if err := Run(); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: expected %q, got: %v", args, want, err)
+			}
+		})
+	}
+}
 
-import "testing"
+func TestRunCommandInvalidParallelPackages(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), ".gokanon")
+	withArgs([]string{"gokanon", "run", "-parallel-packages=0", "-storage=" + storageDir}, func() {
+		if err := Run(); err == nil || !strings.Contains(err.Error(), "Invalid -parallel-packages") {
+			t.Errorf("Expected an invalid -parallel-packages error, got: %v", err)
+		}
+	})
+}
 
-func BenchmarkSimple(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		_ = i * 2
+func TestParseParallelism(t *testing.T) {
+	levels, err := parseParallelism("16, 1,4,16")
+	if err != nil || !slices.Equal(levels, []int{1, 4, 16}) {
+		t.Errorf("parseParallelism = %v, %v, want [1 4 16]", levels, err)
+	}
+	for _, list := range []string{"", "4", "0,4", "-1,4"} {
+		if _, err := parseParallelism(list); err == nil {
+			t.Errorf("parseParallelism(%q): expected an error", list)
+		}
 	}
 }
-`
+
+func TestRunCommandInvalidSink(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), ".gokanon")
+
+	withArgs([]string{"gokanon", "run", "-bench=.", "-sink=kafka:topic", "-storage=" + storageDir}, func() {
+		err := Run()
+		if err == nil || !strings.Contains(err.Error(), "Invalid -sink") {
+			t.Errorf("Expected an invalid sink error, got: %v", err)
+		}
+	})
+}
+
+func TestRunCommandProfileWithoutStorageSink(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), ".gokanon")
+
+	withArgs([]string{"gokanon", "run", "-bench=.", "-profile=cpu", "-sink=stdout", "-storage=" + storageDir}, func() {
+		if err := Run(); err == nil || !strings.Contains(err.Error(), "storage sink") {
+			t.Errorf("Expected profiling to need the storage sink, got: %v", err)
+		}
+	})
+}
+
+func TestDeliverRun(t *testing.T) {
+	storageDir := filepath.Join(t.TempDir(), ".gokanon")
+	store := storage.NewStorage(storageDir)
+	file := filepath.Join(t.TempDir(), "run.json")
+	run := &models.BenchmarkRun{ID: "run-sinks", Timestamp: time.Now(), Results: []models.BenchmarkResult{{Name: "A", NsPerOp: 1}}}
+
+	sinks := []sink.Sink{sink.NewStorage(store), sink.NewFile(file), sink.NewWebhook("http://127.0.0.1:1/unreachable", "")}
+	err := deliverRun(sinks, storageDir, run)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3") {
+		t.Errorf("Expected the unreachable webhook to fail, got: %v", err)
+	}
+	// The other sinks still received the run, graded
+	if saved, err := store.Load("run-sinks"); err != nil {
+		t.Errorf("Expected the run in the storage: %v", err)
+	} else if saved.Results[0].Confidence != "D" {
+		t.Errorf("Confidence of a 1 ns/op result without iterations = %q, want D", saved.Results[0].Confidence)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("Expected the run in %s: %v", file, err)
+	}
+}
+
+func TestRunCommandWithCPUFlag(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create a simple benchmark file
+	testFile := filepath.Join(tempDir, "bench_test.go")
+	benchmarkCode := `package test
+
+import "testing"
+
+func BenchmarkSimple(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = i * 2
+	}
+}
+`
 	if err := os.WriteFile(testFile, []byte(benchmarkCode), 0644); err != nil {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
@@ -698,6 +1342,101 @@ func TestCompareWithTwoRunIDs(t *testing.T) {
 	})
 }
 
+func TestCompareNormalized(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	runs, _ := storage.NewStorage(tempDir).List()
+
+	// BenchmarkAnother serves as the reference benchmark
+	withArgs([]string{"gokanon", "compare", "-storage=" + tempDir, "-normalize=Another", runs[1].ID, runs[0].ID}, func() {
+		if err := Compare(); err != nil {
+			t.Errorf("Compare with -normalize failed: %v", err)
+		}
+	})
+
+	withArgs([]string{"gokanon", "compare", "-storage=" + tempDir, "-normalize=Calibrate", runs[1].ID, runs[0].ID}, func() {
+		if err := Compare(); err == nil {
+			t.Error("Expected error for a missing reference benchmark")
+		}
+	})
+}
+
+func TestCompareScaling(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	// The test runs were taken at a single GOMAXPROCS value
+	withArgs([]string{"gokanon", "compare", "-storage=" + tempDir, "-scaling", "test-run-2", "test-run-1"}, func() {
+		if err := Compare(); err == nil {
+			t.Error("Expected an error for runs without a CPU matrix")
+		}
+	})
+
+	for i, ns := range []float64{200, 120} {
+		run, err := store.Load(fmt.Sprintf("test-run-%d", i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		run.CPUMatrix = []int{1, 4}
+		run.Results = []models.BenchmarkResult{
+			{Name: "BenchmarkTest", NsPerOp: 400, Procs: 1},
+			{Name: "BenchmarkTest-4", NsPerOp: ns, Procs: 4},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	withArgs([]string{"gokanon", "compare", "-storage=" + tempDir, "-scaling", "test-run-2", "test-run-1"}, func() {
+		if err := Compare(); err != nil {
+			t.Errorf("Compare with -scaling failed: %v", err)
+		}
+	})
+}
+
+func TestCompareEnvironmentMismatch(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	run, err := store.Load("test-run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.ID = "test-run-go122"
+	run.GoVersion = "go1.22.0"
+	if err := store.Save(run); err != nil {
+		t.Fatal(err)
+	}
+
+	withArgs([]string{"gokanon", "compare", "-storage=" + tempDir, "test-run-2", "test-run-go122"}, func() {
+		err := Compare()
+		if err == nil || !strings.Contains(err.Error(), "go1.21.0 → go1.22.0") {
+			t.Errorf("Expected an error for runs of different Go releases, got %v", err)
+		}
+	})
+	withArgs([]string{"gokanon", "compare", "-storage=" + tempDir, "-allow-env-mismatch", "test-run-2", "test-run-go122"}, func() {
+		if err := Compare(); err != nil {
+			t.Errorf("Compare with -allow-env-mismatch failed: %v", err)
+		}
+	})
+
+	output := filepath.Join(tempDir, "comparison.md")
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-format=markdown", "-output=" + output, "test-run-2", "test-run-go122"}, func() {
+		if err := Export(); err == nil {
+			t.Error("Expected export to refuse runs of different Go releases")
+		}
+	})
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-format=markdown", "-output=" + output, "-allow-env-mismatch", "test-run-2", "test-run-go122"}, func() {
+		if err := Export(); err != nil {
+			t.Fatalf("Export with -allow-env-mismatch failed: %v", err)
+		}
+	})
+	content, _ := os.ReadFile(output)
+	if !strings.Contains(string(content), "**Environments differ**") {
+		t.Errorf("Export is not marked as comparing different environments:\n%s", content)
+	}
+}
+
 func TestCompareWithNonExistentRun(t *testing.T) {
 	_, tempDir, cleanup := setupTestStorage(t)
 	defer cleanup()
@@ -932,6 +1671,80 @@ func TestCheckWithTwoRunIDs(t *testing.T) {
 	})
 }
 
+func TestCheckDegraded(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	// Far slower, but measured on a busy machine
+	degraded := &models.BenchmarkRun{
+		ID:        "degraded-run",
+		Timestamp: time.Now().Add(time.Hour),
+		Status:    models.StatusDegraded,
+		Error:     "Results may be noisy: load average 12.00 on 4 CPUs",
+		Results:   []models.BenchmarkResult{{Name: "BenchmarkTest", NsPerOp: 1000}, {Name: "BenchmarkAnother", NsPerOp: 2000}},
+	}
+	if err := store.Save(degraded); err != nil {
+		t.Fatalf("Failed to save degraded run: %v", err)
+	}
+
+	for _, args := range [][]string{{"-latest"}, {"test-run-1", "degraded-run"}} {
+		var err error
+		output := captureOutput(t, func() {
+			withArgs(append([]string{"gokanon", "check", "-storage=" + tempDir, "-threshold=10.0"}, args...), func() {
+				err = Check()
+			})
+		})
+		if err != nil || !strings.Contains(output, "Not checked") || !strings.Contains(output, "load average 12.00") {
+			t.Errorf("check %v = %v, want the degraded run skipped, got:\n%s", args, err, output)
+		}
+	}
+
+	// A later run is checked against the latest run of a stable environment
+	fresh := &models.BenchmarkRun{
+		ID:        "fresh-run",
+		Timestamp: time.Now().Add(2 * time.Hour),
+		Results:   []models.BenchmarkResult{{Name: "BenchmarkTest", NsPerOp: 100}, {Name: "BenchmarkAnother", NsPerOp: 200}},
+	}
+	if err := store.Save(fresh); err != nil {
+		t.Fatalf("Failed to save fresh run: %v", err)
+	}
+	var err error
+	output := captureOutput(t, func() {
+		withArgs([]string{"gokanon", "check", "-storage=" + tempDir, "-latest", "-threshold=10.0"}, func() {
+			err = Check()
+		})
+	})
+	if err != nil || !strings.Contains(output, "Comparing: test-run-1 vs fresh-run") {
+		t.Errorf("check -latest = %v, want test-run-1 compared with fresh-run, got:\n%s", err, output)
+	}
+}
+
+func TestCheckMemory(t *testing.T) {
+	tempDir := t.TempDir()
+	store := storage.NewStorage(tempDir)
+	for i, allocs := range []int64{1, 10} {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("alloc-run-%d", i+1),
+			Timestamp: time.Now().Add(time.Duration(i) * time.Hour),
+			Results:   []models.BenchmarkResult{{Name: "BenchmarkTest", Iterations: 1000, NsPerOp: 100, AllocsPerOp: allocs}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Allocations only fail the check with -memory
+	var err error
+	output := captureOutput(t, func() {
+		withArgs([]string{"gokanon", "check", "-storage=" + tempDir, "-threshold=10.0", "-latest"}, func() {
+			err = Check()
+		})
+	})
+	if err != nil || strings.Contains(output, "allocs/op") {
+		t.Errorf("check = %v, want the timing checked only, got:\n%s", err, output)
+	}
+}
+
 func TestCheckWithInsufficientRuns(t *testing.T) {
 	tempDir := t.TempDir()
 	store := storage.NewStorage(tempDir)
@@ -956,6 +1769,65 @@ func TestCheckWithInsufficientRuns(t *testing.T) {
 	})
 }
 
+func TestProfileCompare(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	// The new run spends a third of its time in a function the old one never called
+	saveCPU := func(runID string, cpu map[string]int64) {
+		prof := &profile.Profile{SampleType: []*profile.ValueType{{Type: "cpu", Unit: "nanoseconds"}}}
+		for name, ns := range cpu {
+			id := uint64(len(prof.Function) + 1)
+			fn := &profile.Function{ID: id, Name: name}
+			loc := &profile.Location{ID: id, Line: []profile.Line{{Function: fn}}}
+			prof.Function = append(prof.Function, fn)
+			prof.Location = append(prof.Location, loc)
+			prof.Sample = append(prof.Sample, &profile.Sample{Location: []*profile.Location{loc}, Value: []int64{ns}})
+		}
+		var buf bytes.Buffer
+		if err := prof.Write(&buf); err != nil {
+			t.Fatalf("Failed to write profile: %v", err)
+		}
+		if err := store.SaveProfile(runID, "cpu", &buf); err != nil {
+			t.Fatalf("Failed to save profile: %v", err)
+		}
+	}
+	saveCPU("test-run-2", map[string]int64{"example.parse": 100})
+	saveCPU("test-run-1", map[string]int64{"example.parse": 200, "example.compact": 100})
+
+	output := captureOutput(t, func() {
+		withArgs([]string{"gokanon", "profile", "compare", "test-run-2", "test-run-1", "-type", "cpu", "-format=json", "-storage=" + tempDir}, func() {
+			if err := Profile(); err != nil {
+				t.Fatalf("profile compare failed: %v", err)
+			}
+		})
+	})
+	var diff profiler.ProfileDiff
+	if err := json.Unmarshal([]byte(output), &diff); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", output, err)
+	}
+	if len(diff.NewHot) != 1 || diff.NewHot[0].Name != "example.compact" || len(diff.Functions) != 2 {
+		t.Errorf("Unexpected diff: %+v", diff)
+	}
+
+	output = captureOutput(t, func() {
+		withArgs([]string{"gokanon", "profile", "compare", "-storage=" + tempDir, "test-run-2", "test-run-1"}, func() {
+			if err := Profile(); err != nil {
+				t.Fatalf("profile compare failed: %v", err)
+			}
+		})
+	})
+	if !strings.Contains(output, "New hot functions") || !strings.Contains(output, "example.compact") {
+		t.Errorf("Unexpected table output:\n%s", output)
+	}
+
+	withArgs([]string{"gokanon", "profile", "compare", "test-run-3", "test-run-1", "-storage=" + tempDir}, func() {
+		if err := Profile(); !errors.Is(err, storage.ErrProfileMissing) {
+			t.Errorf("Expected a missing profile error, got %v", err)
+		}
+	})
+}
+
 // ===== Flamegraph Command Tests =====
 
 func TestFlamegraphLatestWithoutProfiles(t *testing.T) {
@@ -970,6 +1842,65 @@ func TestFlamegraphLatestWithoutProfiles(t *testing.T) {
 	})
 }
 
+func TestFlamegraphExport(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	fn := &profile.Function{ID: 1, Name: "example.BenchmarkParse"}
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "cpu", Unit: "nanoseconds"}},
+		Function:   []*profile.Function{fn},
+		Location:   []*profile.Location{{ID: 1, Line: []profile.Line{{Function: fn}}}},
+	}
+	prof.Sample = []*profile.Sample{{Location: prof.Location, Value: []int64{42}}}
+	var buf bytes.Buffer
+	if err := prof.Write(&buf); err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+	if err := store.SaveProfile("test-run-1", "cpu", &buf); err != nil {
+		t.Fatalf("Failed to save profile: %v", err)
+	}
+
+	folded := filepath.Join(tempDir, "cpu.folded")
+	withArgs([]string{"gokanon", "flamegraph", "-storage=" + tempDir, "-format=collapsed", "-o=" + folded, "test-run-1"}, func() {
+		if err := Flamegraph(); err != nil {
+			t.Fatalf("Flamegraph failed: %v", err)
+		}
+	})
+	content, err := os.ReadFile(folded)
+	if err != nil {
+		t.Fatalf("collapsed stacks not written: %v", err)
+	}
+	if string(content) != "example.BenchmarkParse 42\n" {
+		t.Errorf("Unexpected collapsed stacks: %q", content)
+	}
+
+	speedscope := filepath.Join(tempDir, "out.json")
+	withArgs([]string{"gokanon", "flamegraph", "-storage=" + tempDir, "-format=speedscope", "-o=" + speedscope, "-latest"}, func() {
+		if err := Flamegraph(); err != nil {
+			t.Fatalf("Flamegraph failed: %v", err)
+		}
+	})
+	content, err = os.ReadFile(speedscope)
+	if err != nil {
+		t.Fatalf("speedscope file not written: %v", err)
+	}
+	if !strings.Contains(string(content), `"unit":"nanoseconds"`) {
+		t.Errorf("Unexpected speedscope file: %s", content)
+	}
+
+	for _, args := range [][]string{
+		{"-format=collapsed", "-profile=mem"},
+		{"-format=svg"},
+	} {
+		withArgs(append([]string{"gokanon", "flamegraph", "-storage=" + tempDir, "-latest"}, args...), func() {
+			if err := Flamegraph(); err == nil {
+				t.Errorf("Expected an error for %v", args)
+			}
+		})
+	}
+}
+
 func TestFlamegraphWithNonExistentRun(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -1009,3 +1940,1397 @@ func TestCompletionFish(t *testing.T) {
 		}
 	})
 }
+
+func TestAttachMissingArgs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	withArgs([]string{"gokanon", "attach", "-storage=" + tempDir, "-name=wall", "run-1"}, func() {
+		err := Attach()
+		if err == nil {
+			t.Error("Expected error when profile file not provided")
+		}
+	})
+}
+
+func TestAttachInvalidName(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "attach", "-storage=" + tempDir, "-name=cpu", "test-run-1", "wall.prof"}, func() {
+		err := Attach()
+		if err == nil {
+			t.Error("Expected error for reserved profile name")
+		}
+	})
+}
+
+func TestAttachInvalidProfile(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	profilePath := filepath.Join(t.TempDir(), "wall.prof")
+	if err := os.WriteFile(profilePath, []byte("not a profile"), 0644); err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+
+	withArgs([]string{"gokanon", "attach", "-storage=" + tempDir, "-name=wall", "test-run-1", profilePath}, func() {
+		err := Attach()
+		if err == nil {
+			t.Error("Expected error for invalid profile data")
+		}
+	})
+}
+
+func TestRunCommandLocked(t *testing.T) {
+	tempDir := t.TempDir()
+	store := storage.NewStorage(tempDir)
+
+	lock, err := store.AcquireRunLock(false)
+	if err != nil {
+		t.Fatalf("Failed to acquire lock: %v", err)
+	}
+	defer lock.Release()
+
+	withArgs([]string{"gokanon", "run", "-storage=" + tempDir, "-pkg=../../../examples"}, func() {
+		err := Run()
+		if err == nil {
+			t.Fatal("Expected error when storage is locked by another run")
+		}
+		if !strings.Contains(err.Error(), "PID") {
+			t.Errorf("Expected error to identify the lock holder, got: %v", err)
+		}
+	})
+}
+
+func TestRunCommandInvalidConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "gokanon.yaml")
+	if err := os.WriteFile(configPath, []byte("metrics:\n  - name: broken\n    regex: '(['\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	withArgs([]string{"gokanon", "run", "-storage=" + tempDir, "-config=" + configPath, "-pkg=../../../examples"}, func() {
+		if err := Run(); err == nil {
+			t.Error("Expected error for invalid configuration")
+		}
+	})
+}
+
+func TestImport(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	input := filepath.Join(t.TempDir(), "bench.txt")
+	output := "pkg: example.com/codec\n" +
+		"BenchmarkEncode-8   100000   1200 ns/op\n" +
+		"BenchmarkEncode-8   100000   1300 ns/op\n"
+	if err := os.WriteFile(input, []byte(output), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	args := []string{"gokanon", "import", "-storage=" + tempDir, "-config=" + filepath.Join(tempDir, "none.yaml"),
+		"-timestamp=2024-05-01T12:00:00Z", input}
+	for i := 0; i < 2; i++ {
+		withArgs(args, func() {
+			if err := Import(); err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+		})
+	}
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	if len(runs) != 5 {
+		t.Fatalf("Expected both imports stored next to the 3 runs, got %d runs", len(runs))
+	}
+	var imported int
+	for _, run := range runs {
+		if run.Package != "example.com/codec" {
+			continue
+		}
+		imported++
+		if !run.Timestamp.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected the given timestamp, got %v", run.Timestamp)
+		}
+		if len(run.Results) != 1 || len(run.Results[0].Samples) != 2 {
+			t.Errorf("Expected one result with 2 samples, got %+v", run.Results)
+		}
+	}
+	if imported != 2 {
+		t.Errorf("Expected 2 imported runs, got %d", imported)
+	}
+}
+
+func TestImportInvalidInput(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	input := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(input, []byte("PASS\n"), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	withArgs([]string{"gokanon", "import", "-storage=" + tempDir, input}, func() {
+		if err := Import(); err == nil {
+			t.Error("Expected error for input without benchmark results")
+		}
+	})
+	withArgs([]string{"gokanon", "import", "-storage=" + tempDir, "-timestamp=yesterday", input}, func() {
+		if err := Import(); err == nil {
+			t.Error("Expected error for invalid timestamp")
+		}
+	})
+}
+
+func TestImportFormats(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	input := filepath.Join(t.TempDir(), "benchstat.csv")
+	csv := "pkg: example.com/codec\n" +
+		",old.txt,,new.txt\n" +
+		",sec/op,CI,sec/op,CI,vs base,P\n" +
+		"Encode-8,1.5e-06,2%,1.2e-06,1%,-20.00%,p=0.000 n=10\n"
+	if err := os.WriteFile(input, []byte(csv), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	withArgs([]string{"gokanon", "import", "-storage=" + tempDir, "-timestamp=2024-04-01T12:00:00Z", input}, func() {
+		if err := Import(); err == nil {
+			t.Error("Expected error for a single timestamp of two runs")
+		}
+	})
+	withArgs([]string{"gokanon", "import", "-storage=" + tempDir, "-format=benchstat-csv",
+		"-timestamp=2024-04-01T12:00:00Z", "-timestamp=2024-05-01T12:00:00Z", input}, func() {
+		if err := Import(); err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+	})
+
+	// Perf storage results are read from the server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "upload: 20240301.1\nupload-time: 2024-03-01T12:00:00Z\npkg: example.com/codec\nBenchmarkEncode-8 100 1800 ns/op\n")
+	}))
+	defer server.Close()
+	withArgs([]string{"gokanon", "import", "-storage=" + tempDir, server.URL + "/search?q=upload:20240301.1"}, func() {
+		if err := Import(); err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+	})
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	var imported []models.BenchmarkRun
+	for _, run := range runs {
+		if run.Package == "example.com/codec" {
+			imported = append(imported, run)
+		}
+	}
+	if len(imported) != 3 {
+		t.Fatalf("Expected 3 imported runs, got %d", len(imported))
+	}
+	// Newest first: new.txt, old.txt, the upload
+	wantNs := []float64{1200, 1500, 1800}
+	for i, run := range imported {
+		if run.Timestamp.Month() != time.Month(5-i) || run.Results[0].NsPerOp != wantNs[i] {
+			t.Errorf("Run %d: expected %v ns/op in month %d, got %v in %v", i, wantNs[i], 5-i, run.Results[0].NsPerOp, run.Timestamp)
+		}
+	}
+	if ids, _ := store.RunIDs(); !slices.Contains(ids, imported[2].ID) || imported[2].ID > imported[0].ID {
+		t.Errorf("Expected IDs ordered by the time the runs ran, got %s after %s", imported[2].ID, imported[0].ID)
+	}
+
+	withArgs([]string{"gokanon", "import", "-storage=" + tempDir, "-format=xml", input}, func() {
+		if err := Import(); err == nil {
+			t.Error("Expected error for an unknown format")
+		}
+	})
+}
+
+func TestProjects(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	withArgs([]string{"gokanon", "projects"}, func() {
+		if err := Projects(); err != nil {
+			t.Errorf("Projects failed with an empty registry: %v", err)
+		}
+	})
+
+	registry, err := config.LoadRegistry(config.RegistryPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone := filepath.Join(t.TempDir(), "deleted")
+	registry.Touch(gone, t.TempDir(), time.Now())
+	if err := registry.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	withArgs([]string{"gokanon", "projects", "-prune"}, func() {
+		if err := Projects(); err != nil {
+			t.Fatalf("Projects -prune failed: %v", err)
+		}
+	})
+	registry, err = config.LoadRegistry(config.RegistryPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Projects) != 0 {
+		t.Errorf("Expected the missing project to be pruned, got %+v", registry.Projects)
+	}
+}
+
+func TestCompareRunReferences(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"previous", "latest"},
+		{"latest~2", "test-run-1"},
+	} {
+		withArgs(append([]string{"gokanon", "compare", "-storage=" + tempDir}, args...), func() {
+			if err := Compare(); err != nil {
+				t.Errorf("Compare %v failed: %v", args, err)
+			}
+		})
+	}
+
+	withArgs([]string{"gokanon", "compare", "-storage=" + tempDir, "latest~5", "latest"}, func() {
+		if err := Compare(); err == nil {
+			t.Error("Expected error for a reference beyond the stored runs")
+		}
+	})
+}
+
+func TestDeleteRunReference(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "delete", "-storage=" + tempDir, "latest"}, func() {
+		if err := Delete(); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+	})
+	if _, err := store.Load("test-run-1"); err == nil {
+		t.Error("Expected the latest run to be deleted")
+	}
+}
+
+func TestCI(t *testing.T) {
+	moduleDir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/ci\n\ngo 1.21\n",
+		"bench_test.go": `package ci
+
+import "testing"
+
+func BenchmarkSimple(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = i * 2
+	}
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(moduleDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	t.Chdir(moduleDir)
+
+	storageDir := filepath.Join(moduleDir, ".gokanon")
+	summary := filepath.Join(moduleDir, "summary.md")
+	args := []string{"gokanon", "ci", "-storage=" + storageDir, "-config=" + filepath.Join(moduleDir, "none.yaml"),
+		"-benchtime=100x", "-summary=" + summary}
+
+	// Without a baseline the run is saved and passes
+	withArgs(args, func() {
+		if err := CI(); err != nil {
+			t.Fatalf("CI without baseline failed: %v", err)
+		}
+	})
+	data, err := os.ReadFile(summary)
+	if err != nil {
+		t.Fatalf("Expected a job summary: %v", err)
+	}
+	if !strings.Contains(string(data), "No baseline `main`") {
+		t.Errorf("Expected the summary to note the missing baseline:\n%s", data)
+	}
+
+	// Against a much faster baseline the run regresses
+	store := storage.NewStorage(storageDir)
+	run, err := store.GetLatest()
+	if err != nil {
+		t.Fatalf("Expected the CI run to be saved: %v", err)
+	}
+	run.ID = "run-fast"
+	for i := range run.Results {
+		run.Results[i].NsPerOp /= 1000
+	}
+	if err := store.Save(run); err != nil {
+		t.Fatalf("Failed to save run: %v", err)
+	}
+	if _, err := store.SaveBaseline("main", run.ID, "", nil); err != nil {
+		t.Fatalf("Failed to save baseline: %v", err)
+	}
+
+	withArgs(args, func() {
+		if err := CI(); err == nil {
+			t.Error("Expected CI to fail for a regression beyond the threshold")
+		}
+	})
+	data, _ = os.ReadFile(summary)
+	if !strings.Contains(string(data), "regressed beyond 5.0% of baseline `main`") {
+		t.Errorf("Expected the summary to report the regression:\n%s", data)
+	}
+}
+
+func TestListTimeFormat(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "list", "-storage=" + tempDir, "-time-format=rfc3339", "-tz=UTC"}, func() {
+		if err := List(); err != nil {
+			t.Errorf("List failed: %v", err)
+		}
+	})
+
+	withArgs([]string{"gokanon", "list", "-storage=" + tempDir, "-tz=Nowhere/Special"}, func() {
+		if err := List(); err == nil {
+			t.Error("Expected an error for an unknown time zone")
+		}
+	})
+}
+
+func TestCheckExplain(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "check", "-storage=" + tempDir, "-latest", "-threshold=1000", "-explain"}, func() {
+		if err := Check(); err != nil {
+			t.Errorf("Check -explain failed: %v", err)
+		}
+	})
+}
+
+func TestExplainCheck(t *testing.T) {
+	store, _, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.Float64("threshold", 10, "")
+	fs.String("config", filepath.Join(t.TempDir(), config.DefaultFile), "")
+	cfg, err := parseFlags(fs, []string{"-threshold=10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SLOs = []config.SLO{{Name: "test-latency", Benchmark: "Test", Max: "1ms"}}
+
+	comparisons := []models.Comparison{{Name: "BenchmarkTest", OldNsPerOp: 100, NewNsPerOp: 150, DeltaPercent: 50}}
+	result := &threshold.Result{Failures: []threshold.Failure{{BenchmarkName: "BenchmarkTest", DeltaPercent: 50, Threshold: 10}}}
+	output := captureOutput(t, func() {
+		explainCheck(store, newChecker(fs, cfg, 10), cfg.SLOs, comparisons, result)
+	})
+	for _, want := range []string{"BenchmarkTest: FAILED", "10.00% global (-threshold)", "Budget:", "test-latency (p95 ≤ 1ms), ok"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in explanation, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestCheckSummaryOnly(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "check", "-storage=" + tempDir, "-latest", "-threshold=1000", "-summary-only"}, func() {
+		if err := Check(); err != nil {
+			t.Errorf("Check -summary-only failed: %v", err)
+		}
+	})
+}
+
+func TestSignificance(t *testing.T) {
+	low, high := 0.01, 0.4
+	tests := []struct {
+		name string
+		comp models.Comparison
+		want string
+	}{
+		{"untested", models.Comparison{}, "not tested"},
+		{"significant", models.Comparison{PValue: &low, OldSamples: 5, NewSamples: 5}, "p=0.010 <= 0.05 with 5 vs 5 samples, significant"},
+		{"noise", models.Comparison{PValue: &high, OldSamples: 5, NewSamples: 5}, "not significant"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := significance(tt.comp); !strings.Contains(got, tt.want) {
+				t.Errorf("significance() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoricalVariation(t *testing.T) {
+	aggregates := &storage.Aggregates{Benchmarks: map[string]*models.Aggregate{}}
+	for _, v := range []float64{90, 100, 110} {
+		if aggregates.Benchmarks["A"] == nil {
+			aggregates.Benchmarks["A"] = &models.Aggregate{}
+		}
+		aggregates.Benchmarks["A"].Add(v)
+	}
+
+	got := historicalVariation(aggregates, models.Comparison{Name: "A", DeltaPercent: 16.3})
+	if !strings.Contains(got, "CV 8.2% over 3 runs") || !strings.Contains(got, "2.0x") {
+		t.Errorf("historicalVariation() = %q", got)
+	}
+	if got := historicalVariation(aggregates, models.Comparison{Name: "B"}); !strings.Contains(got, "not enough runs") {
+		t.Errorf("historicalVariation() for an unknown benchmark = %q", got)
+	}
+}
+
+// ===== Bisect Command Tests =====
+
+func TestBisectMissingArgs(t *testing.T) {
+	withArgs([]string{"gokanon", "bisect", "-benchmark=Parse"}, func() {
+		if err := Bisect(); err == nil {
+			t.Error("Expected an error without -good")
+		}
+	})
+}
+
+func TestDoctorBaselineNeedsCI(t *testing.T) {
+	withArgs([]string{"gokanon", "doctor", "-baseline=main"}, func() {
+		if err := Doctor(); err == nil || !strings.Contains(err.Error(), "-baseline needs -ci") {
+			t.Errorf("Expected -baseline to need -ci, got: %v", err)
+		}
+	})
+}
+
+func TestStabilityInvalidArgs(t *testing.T) {
+	for args, want := range map[string]string{
+		"-count=3":        "Invalid -count",
+		"-cv-threshold=0": "Invalid threshold",
+		"-threshold=-5":   "Invalid threshold",
+	} {
+		withArgs([]string{"gokanon", "stability", args}, func() {
+			if err := Stability(); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: expected %q, got: %v", args, want, err)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tempDir := t.TempDir()
+	store := storage.NewStorage(tempDir)
+	now := time.Now()
+	for _, run := range []*models.BenchmarkRun{
+		{ID: "run-old", Timestamp: now.Add(-40 * 24 * time.Hour)},
+		{ID: "run-baseline", Timestamp: now.Add(-50 * 24 * time.Hour)},
+		{ID: "run-new", Timestamp: now.Add(-time.Hour)},
+	} {
+		run.Results = []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}}
+		run.CPUProfile = store.GetCPUProfilePath(run.ID)
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Failed to create test data: %v", err)
+		}
+		if err := store.SaveProfile(run.ID, "cpu", strings.NewReader("profile")); err != nil {
+			t.Fatalf("Failed to create test profile: %v", err)
+		}
+	}
+	if _, err := store.SaveBaseline("main", "run-baseline", "", nil); err != nil {
+		t.Fatalf("Failed to save baseline: %v", err)
+	}
+
+	withArgs([]string{"gokanon", "prune", "-storage=" + tempDir, "-profiles-older-than=30d", "-dry-run"}, func() {
+		if err := Prune(); err != nil {
+			t.Fatalf("Prune -dry-run failed: %v", err)
+		}
+	})
+	if !store.HasProfile("run-old", "cpu") {
+		t.Fatal("Expected -dry-run to keep the profiles")
+	}
+
+	withArgs([]string{"gokanon", "prune", "-storage=" + tempDir, "-profiles-older-than=30d"}, func() {
+		if err := Prune(); err != nil {
+			t.Fatalf("Prune failed: %v", err)
+		}
+	})
+	for id, kept := range map[string]bool{"run-old": false, "run-baseline": true, "run-new": true} {
+		if store.HasProfile(id, "cpu") != kept {
+			t.Errorf("%s: expected profile kept = %v", id, kept)
+		}
+	}
+	if run, err := store.Load("run-old"); err != nil || len(run.Results) != 1 || run.CPUProfile != "" {
+		t.Errorf("Expected the results of run-old to be kept without its profile, got %+v, %v", run, err)
+	}
+
+	// Records saved uncompressed by an older version are compressed alone
+	data, _ := store.RunData("run-new")
+	os.Remove(filepath.Join(tempDir, "run-new.json.gz"))
+	if err := os.WriteFile(filepath.Join(tempDir, "run-new.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	withArgs([]string{"gokanon", "prune", "-storage=" + tempDir, "-compress"}, func() {
+		if err := Prune(); err != nil {
+			t.Fatalf("Prune -compress failed: %v", err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(tempDir, "run-new.json.gz")); err != nil {
+		t.Errorf("Expected run-new compressed: %v", err)
+	}
+	if run, err := store.Load("run-new"); err != nil || len(run.Results) != 1 {
+		t.Errorf("Expected run-new unchanged, got %+v, %v", run, err)
+	}
+
+	for args, want := range map[string]string{
+		"-dry-run":                  "Nothing to prune",
+		"-profiles-older-than=soon": "Invalid -profiles-older-than",
+		"-profiles-older-than=-3d":  "Invalid -profiles-older-than",
+		"-older-than=3 months":      "Invalid -older-than",
+		"-keep-last=-1":             "Invalid -keep-last",
+	} {
+		withArgs([]string{"gokanon", "prune", "-storage=" + tempDir, args}, func() {
+			if err := Prune(); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: expected %q, got: %v", args, want, err)
+			}
+		})
+	}
+}
+
+func TestAutoPrune(t *testing.T) {
+	store, _, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	// Without auto_prune the policy is only applied by 'gokanon prune'
+	autoPrune(store, config.Retention{KeepLast: 1})
+	if ids, _ := store.RunIDs(); len(ids) != 3 {
+		t.Fatalf("Expected no runs to be pruned, got %v", ids)
+	}
+
+	autoPrune(store, config.Retention{AutoPrune: true, KeepLast: 1})
+	runs, err := store.List()
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].ID != "test-run-1" {
+		t.Errorf("Expected only the newest run to be kept, got %d runs", len(runs))
+	}
+}
+
+func TestSyncErrors(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	storageDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		args    []string
+		errText string
+	}{
+		{"unknown subcommand", []string{"gokanon", "sync", "merge"}, "Unknown sync subcommand"},
+		{"no remote", []string{"gokanon", "sync", "push", "-storage=" + storageDir}, "No remote configured"},
+		{"invalid remote", []string{"gokanon", "sync", "pull", "-remote=ftp://bucket", "-storage=" + storageDir}, "Invalid remote"},
+		{"no credentials", []string{"gokanon", "sync", "pull", "-remote=s3://bucket", "-storage=" + storageDir}, "Missing bucket credentials"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withArgs(tt.args, func() {
+				err := Sync()
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("Expected error containing %q, got %v", tt.errText, err)
+				}
+			})
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Setenv("GOKANON_AI_ENABLED", "false")
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "analyze", "-storage=" + tempDir}, func() {
+		if err := Analyze(); err == nil || !strings.Contains(err.Error(), "AI analysis is disabled") {
+			t.Errorf("Expected a disabled analysis error, got %v", err)
+		}
+	})
+
+	// Cached analyses are replayed without a provider
+	if err := store.SaveAnalysis(&models.AIAnalysis{
+		Key: "abc", Kind: "comparison", RunIDs: []string{"test-run-2", "test-run-1"},
+		Provider: "ollama", Model: "llama3.2", Response: "Parse got slower", CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{}, {"latest"}, {"test-run-3"}} {
+		output := captureOutput(t, func() {
+			withArgs(append([]string{"gokanon", "analyze", "-show-cached", "-storage=" + tempDir}, args...), func() {
+				if err := Analyze(); err != nil {
+					t.Errorf("Analyze -show-cached %v failed: %v", args, err)
+				}
+			})
+		})
+		want := len(args) == 0 || args[0] == "latest"
+		if strings.Contains(output, "Parse got slower") != want {
+			t.Errorf("Analyze -show-cached %v printed:\n%s", args, output)
+		}
+	}
+}
+
+func TestPush(t *testing.T) {
+	t.Setenv("GOKANON_PERFDATA", "")
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	var uploads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var data bytes.Buffer
+		data.ReadFrom(file)
+		uploads = append(uploads, data.String())
+		id := fmt.Sprintf("20260301.%d", len(uploads))
+		fmt.Fprintf(w, `{"uploadid":%q,"fileids":[%q]}`, id, id+"/0")
+	}))
+	defer server.Close()
+
+	withArgs([]string{"gokanon", "push", "-perfdata=" + server.URL, "-storage=" + tempDir}, func() {
+		if err := Push(); err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+	})
+	if len(uploads) != 1 || !strings.Contains(uploads[0], "gokanon-run: test-run-1\n") {
+		t.Fatalf("Uploads = %q", uploads)
+	}
+	run, err := store.Load("test-run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.PerfUploads[server.URL] != "20260301.1" {
+		t.Errorf("PerfUploads = %v", run.PerfUploads)
+	}
+
+	// -all uploads the other runs, oldest first, and skips the uploaded one
+	withArgs([]string{"gokanon", "push", "-perfdata=" + server.URL, "-all", "-storage=" + tempDir}, func() {
+		if err := Push(); err != nil {
+			t.Fatalf("Push -all failed: %v", err)
+		}
+	})
+	if len(uploads) != 3 || !strings.Contains(uploads[1], "gokanon-run: test-run-3\n") {
+		t.Errorf("Uploads = %q", uploads)
+	}
+
+	withArgs([]string{"gokanon", "push", "-storage=" + tempDir}, func() {
+		if err := Push(); err == nil || !strings.Contains(err.Error(), "No perf data server configured") {
+			t.Errorf("Expected a missing server error, got %v", err)
+		}
+	})
+}
+
+func TestProfileErrors(t *testing.T) {
+	storageDir := t.TempDir()
+	tests := []struct {
+		name    string
+		args    []string
+		errText string
+	}{
+		{"no benchmark", []string{"gokanon", "profile", "-storage=" + storageDir}, "usage: gokanon profile"},
+		{"prefix only", []string{"gokanon", "profile", "Benchmark", "-storage=" + storageDir}, "Invalid benchmark name"},
+		{"empty sub-benchmark", []string{"gokanon", "profile", "Parse/", "-storage=" + storageDir}, "Invalid benchmark name"},
+		{"invalid duration", []string{"gokanon", "profile", "Parse", "-duration=0s", "-storage=" + storageDir}, "Invalid -duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withArgs(tt.args, func() {
+				err := Profile()
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("Expected error containing %q, got %v", tt.errText, err)
+				}
+			})
+		})
+	}
+}
+
+func TestBenchmarkFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Parse", "^BenchmarkParse$"},
+		{"BenchmarkParse-8", "^BenchmarkParse$"},
+		{"Encode/size=10", "^BenchmarkEncode$/^size=10$"},
+		{"Sort/a.b", `^BenchmarkSort$/^a\.b$`},
+	}
+	for _, tt := range tests {
+		if got := benchmarkFilter(tt.name); got != tt.want {
+			t.Errorf("benchmarkFilter(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "snapshot", "-name=v2.3.0", "-desc=Release", "-storage=" + tempDir}, func() {
+		if err := Snapshot(); err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+	})
+	snapshot, err := store.LoadSnapshot("v2.3.0")
+	if err != nil {
+		t.Fatalf("Expected the snapshot to be saved: %v", err)
+	}
+	if snapshot.Description != "Release" || snapshot.Stats["totalRuns"] != 3.0 || len(snapshot.Runs) != 3 {
+		t.Errorf("Unexpected snapshot: %+v", snapshot)
+	}
+
+	// A release snapshot is only replaced on purpose
+	withArgs([]string{"gokanon", "snapshot", "-name=v2.3.0", "-storage=" + tempDir}, func() {
+		if err := Snapshot(); err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("Expected an error for an existing snapshot, got %v", err)
+		}
+	})
+	withArgs([]string{"gokanon", "snapshot", "-name=v2.3.0", "-force", "-storage=" + tempDir}, func() {
+		if err := Snapshot(); err != nil {
+			t.Errorf("Expected -force to replace the snapshot: %v", err)
+		}
+	})
+
+	withArgs([]string{"gokanon", "snapshot", "-list", "-storage=" + tempDir}, func() {
+		if err := Snapshot(); err != nil {
+			t.Errorf("Snapshot -list failed: %v", err)
+		}
+	})
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	emptyDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		args    []string
+		errText string
+	}{
+		{"no name", []string{"gokanon", "snapshot", "-storage=" + tempDir}, "Snapshot name is required"},
+		{"invalid name", []string{"gokanon", "snapshot", "-name=release/2.3", "-storage=" + tempDir}, "Invalid snapshot name"},
+		{"invalid limit", []string{"gokanon", "snapshot", "-name=v2.3.0", "-limit=0", "-storage=" + tempDir}, "Invalid -limit"},
+		{"no runs", []string{"gokanon", "snapshot", "-name=v2.3.0", "-storage=" + emptyDir}, "No benchmark runs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withArgs(tt.args, func() {
+				err := Snapshot()
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("Expected error containing %q, got %v", tt.errText, err)
+				}
+			})
+		})
+	}
+}
+
+func TestCheckJUnit(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	output := filepath.Join(t.TempDir(), "report.xml")
+	withArgs([]string{"gokanon", "check", "-storage=" + tempDir, "-latest", "-format=junit", "-output=" + output}, func() {
+		if err := Check(); err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+	})
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected a JUnit report: %v", err)
+	}
+	for _, want := range []string{`<testsuite name="gokanon" tests="2" failures="0"`, `<testcase name="BenchmarkTest" classname="./examples">`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in report:\n%s", want, data)
+		}
+	}
+
+	withArgs([]string{"gokanon", "check", "-storage=" + tempDir, "-latest", "-output=" + output}, func() {
+		if err := Check(); err == nil {
+			t.Error("Expected -output without -format=junit to fail")
+		}
+	})
+}
+
+func TestTUI(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	// The output of tests is not a terminal
+	withArgs([]string{"gokanon", "tui", "-storage=" + tempDir}, func() {
+		if err := TUI(); err == nil || !strings.Contains(err.Error(), "needs a terminal") {
+			t.Errorf("Expected an error without a terminal, got %v", err)
+		}
+	})
+	withArgs([]string{"gokanon", "tui", "-storage=" + tempDir, "-window=1"}, func() {
+		if err := TUI(); err == nil {
+			t.Error("Expected an error for a trend window of 1 run")
+		}
+	})
+	withArgs([]string{"gokanon", "tui", "-storage=" + t.TempDir()}, func() {
+		if err := TUI(); err == nil {
+			t.Error("Expected an error without runs")
+		}
+	})
+}
+
+func TestFleetReport(t *testing.T) {
+	_, apiDir, _ := setupTestStorage(t)
+	_, webDir, _ := setupTestStorage(t)
+	output := filepath.Join(t.TempDir(), "fleet.json")
+
+	withArgs([]string{"gokanon", "fleet", "report", "-storage=api=" + apiDir, "-storage=web=" + webDir,
+		"-owner=api=platform", "-format=json", "-output=" + output}, func() {
+		if err := Fleet(); err != nil {
+			t.Fatalf("Fleet report failed: %v", err)
+		}
+	})
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report fleet.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Repos != 2 || len(report.Owners) != 2 || report.Owners[0].Owner != "platform" || report.Owners[0].Repos[0].Name != "api" {
+		t.Errorf("Report = %+v", report)
+	}
+	if api := report.Owners[0].Repos[0]; api.Run != "test-run-1" || api.Against != "test-run-2" || api.Improved != 2 {
+		t.Errorf("api = %+v", api)
+	}
+
+	for _, args := range [][]string{
+		{"-storage=" + filepath.Join(t.TempDir(), "missing")},
+		{"-storage=" + apiDir, "-owner=api"},
+		{"-storage=" + apiDir, "-format=xml"},
+	} {
+		withArgs(append([]string{"gokanon", "fleet", "report"}, args...), func() {
+			if err := Fleet(); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "search", "-storage=" + tempDir, "-limit=1", "package:examples", "Another"}, func() {
+		if err := Search(); err != nil {
+			t.Errorf("Search failed: %v", err)
+		}
+	})
+	withArgs([]string{"gokanon", "search", "-storage=" + tempDir, "Another", "-since", "90m", "-json"}, func() {
+		if err := Search(); err != nil {
+			t.Errorf("Search failed: %v", err)
+		}
+	})
+	for _, args := range [][]string{{}, {"color:red"}, {"Another", "-since=soon"}} {
+		withArgs(append([]string{"gokanon", "search", "-storage=" + tempDir}, args...), func() {
+			if err := Search(); err == nil {
+				t.Errorf("Expected an error for %v", args)
+			}
+		})
+	}
+}
+
+func TestServeInvalidAccessOptions(t *testing.T) {
+	for _, args := range [][]string{
+		{"-basic-auth=nopassword"},
+		{"-read-only", "-agents"},
+		{"-read-only", "-run-pkg=./..."},
+	} {
+		withArgs(append([]string{"gokanon", "serve", "-storage=" + t.TempDir()}, args...), func() {
+			if err := Serve(); err == nil {
+				t.Errorf("Expected an error for %v", args)
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{"localhost": true, "127.0.0.1": true, "::1": true, "0.0.0.0": false, "10.0.0.5": false} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestTelemetry(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	t.Setenv("GOKANON_TELEMETRY", "")
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("GOKANON_TELEMETRY_URL", "")
+
+	status := func() string {
+		return captureOutput(t, func() {
+			withArgs([]string{"gokanon", "telemetry", "status"}, func() {
+				if err := Telemetry("dev"); err != nil {
+					t.Errorf("Telemetry status failed: %v", err)
+				}
+			})
+		})
+	}
+	if output := status(); !strings.Contains(output, "off") || !strings.Contains(output, "only counted locally") {
+		t.Errorf("Unexpected status while off:\n%s", output)
+	}
+
+	withArgs([]string{"gokanon", "telemetry", "on"}, func() {
+		if err := Telemetry("dev"); err != nil {
+			t.Fatalf("Telemetry on failed: %v", err)
+		}
+	})
+	telemetry.Record("command.list", "export.csv")
+	output := status()
+	if !strings.Contains(output, `"command.list": 1`) || !strings.Contains(output, `"version": "dev"`) {
+		t.Errorf("Status does not show the pending report:\n%s", output)
+	}
+
+	withArgs([]string{"gokanon", "telemetry", "off"}, func() {
+		if err := Telemetry("dev"); err != nil {
+			t.Fatalf("Telemetry off failed: %v", err)
+		}
+	})
+	if output := status(); strings.Contains(output, "command.list") {
+		t.Errorf("Counts were kept after telemetry was turned off:\n%s", output)
+	}
+
+	withArgs([]string{"gokanon", "telemetry", "maybe"}, func() {
+		if err := Telemetry("dev"); err == nil {
+			t.Error("Expected an error for an unknown subcommand")
+		}
+	})
+}
+
+func TestExportDeterministic(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	outputFile := filepath.Join(tempDir, "comparison.md")
+
+	export := func(args ...string) string {
+		withArgs(append([]string{"gokanon", "export", "-storage=" + tempDir, "-latest", "-format=markdown", "-output=" + outputFile}, args...), func() {
+			if err := Export(); err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+		})
+		content, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("report not written: %v", err)
+		}
+		return string(content)
+	}
+
+	report := export("-deterministic")
+	if !strings.Contains(report, "Comparing: `old` vs `new`") || strings.Contains(report, "test-run-") {
+		t.Errorf("Deterministic report names the runs:\n%s", report)
+	}
+	if strings.Index(report, "BenchmarkAnother") > strings.Index(report, "BenchmarkTest") {
+		t.Errorf("Benchmarks are not sorted by name:\n%s", report)
+	}
+	if report != export("-deterministic") {
+		t.Error("Exporting twice gave different reports")
+	}
+	if report := export("-deterministic", "-timestamps"); !strings.Contains(report, "test-run-") {
+		t.Errorf("-timestamps did not keep the run IDs:\n%s", report)
+	}
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-latest", "-format=ipynb", "-deterministic"}, func() {
+		if err := Export(); err == nil || !strings.Contains(err.Error(), "only supported") {
+			t.Errorf("Expected -deterministic to be rejected for notebooks, got %v", err)
+		}
+	})
+}
+
+func TestExportBenchstat(t *testing.T) {
+	_, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+	t.Chdir(tempDir)
+
+	withArgs([]string{"gokanon", "export", "-storage=" + tempDir, "-latest", "-format=benchstat"}, func() {
+		if err := Export(); err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+	})
+	content, err := os.ReadFile(filepath.Join(tempDir, "comparison.txt"))
+	if err != nil {
+		t.Fatalf("benchstat tables not written to comparison.txt: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "name     old time/op  new time/op  delta\n") || !strings.Contains(string(content), "Test           110ns        100ns  -9.09%") {
+		t.Errorf("Unexpected benchstat export:\n%s", content)
+	}
+}
+
+func TestDaemonRunSchedule(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+
+	var notifications []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		notifications = append(notifications, payload)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Schedules: []config.Schedule{{Name: "nightly", Cron: "0 2 * * *", Threshold: 10, Notify: []string{"webhook:" + server.URL}}}}
+	d, err := newDaemon(store, cfg)
+	if err != nil {
+		t.Fatalf("newDaemon failed: %v", err)
+	}
+	nsPerOp := []float64{100, 105, 150}
+	d.benchmark = func(ctx context.Context, s *scheduled) (*models.BenchmarkRun, error) {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("nightly-%d", len(nsPerOp)),
+			Timestamp: time.Now().Add(-time.Duration(len(nsPerOp)) * time.Minute),
+			Results:   []models.BenchmarkResult{{Name: "BenchmarkTest", NsPerOp: nsPerOp[0]}},
+			Tags:      map[string]string{scheduleTag: s.Name},
+		}
+		nsPerOp = nsPerOp[1:]
+		return run, store.Save(run)
+	}
+	nightly := d.schedules[0]
+
+	// The first run has nothing to compare with; the second is within the
+	// threshold of the first
+	for range 2 {
+		captureOutput(t, func() {
+			if err := d.runSchedule(context.Background(), nightly); err != nil {
+				t.Fatalf("runSchedule failed: %v", err)
+			}
+		})
+		if nightly.status.LastStatus != models.StatusOK {
+			t.Fatalf("LastStatus = %q, want ok", nightly.status.LastStatus)
+		}
+	}
+	if len(notifications) != 0 {
+		t.Errorf("Expected no notification without a regression, got %v", notifications)
+	}
+
+	// The third regresses against the second, not the first
+	captureOutput(t, func() {
+		d.runSchedule(context.Background(), nightly)
+	})
+	if nightly.status.LastStatus != "regressed" || nightly.status.Regressions != 1 || nightly.status.LastRunID != "nightly-1" {
+		t.Errorf("Unexpected status after a regression: %+v", nightly.status)
+	}
+	if len(notifications) != 1 || notifications[0]["schedule"] != "nightly" || notifications[0]["runId"] != "nightly-1" {
+		t.Errorf("Expected a regression notification, got %v", notifications)
+	}
+
+	// Failures are notified too
+	d.benchmark = func(ctx context.Context, s *scheduled) (*models.BenchmarkRun, error) {
+		return nil, errors.New("build failed")
+	}
+	captureOutput(t, func() {
+		if err := d.runSchedule(context.Background(), nightly); err == nil {
+			t.Error("Expected the failure to be returned")
+		}
+	})
+	if nightly.status.LastStatus != models.StatusFailed || len(notifications) != 2 || notifications[1]["status"] != models.StatusFailed {
+		t.Errorf("Expected a failure notification, got %+v and %v", nightly.status, notifications)
+	}
+
+	status, err := store.LoadDaemonStatus()
+	if err != nil || status == nil || len(status.Schedules) != 1 || status.Schedules[0].LastError != "build failed" {
+		t.Errorf("Unexpected saved status %+v (%v)", status, err)
+	}
+}
+
+func TestDaemonErrors(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "gokanon.yaml")
+	if err := os.WriteFile(configPath, []byte("schedules:\n  - name: nightly\n    cron: '0 2 * * *'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	withArgs([]string{"gokanon", "daemon", "-storage=" + tempDir, "-config=" + configPath, "-once=weekly"}, func() {
+		if err := Daemon(); err == nil || !strings.Contains(err.Error(), "Unknown schedule: weekly") {
+			t.Errorf("Expected an unknown schedule error, got %v", err)
+		}
+	})
+	withArgs([]string{"gokanon", "daemon", "-storage=" + tempDir, "-config=" + filepath.Join(tempDir, "none.yaml")}, func() {
+		if err := Daemon(); err == nil || !strings.Contains(err.Error(), "No schedules configured") {
+			t.Errorf("Expected a missing schedules error, got %v", err)
+		}
+	})
+}
+
+func TestAnnotate(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	withArgs([]string{"gokanon", "annotate", "-storage=" + tempDir, "-kind=upgrade", "latest", "upgraded Go to 1.22"}, func() {
+		captureOutput(t, func() {
+			if err := Annotate(); err != nil {
+				t.Fatalf("Annotate failed: %v", err)
+			}
+		})
+	})
+	withArgs([]string{"gokanon", "annotate", "-storage=" + tempDir, "test-run-1", "new CI runner"}, func() {
+		captureOutput(t, func() {
+			if err := Annotate(); err != nil {
+				t.Fatalf("Annotate failed: %v", err)
+			}
+		})
+	})
+	run, err := store.Load("test-run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Annotations) != 2 || run.Annotations[0].String() != "[upgrade] upgraded Go to 1.22" || run.Annotations[1].Kind != "" {
+		t.Fatalf("Annotations = %+v", run.Annotations)
+	}
+
+	// Annotations are listed with the run, and below the run table on request
+	var output string
+	withArgs([]string{"gokanon", "annotate", "-storage=" + tempDir, "test-run-1"}, func() {
+		output = captureOutput(t, func() {
+			if err := Annotate(); err != nil {
+				t.Fatalf("Annotate failed: %v", err)
+			}
+		})
+	})
+	if !strings.Contains(output, "1. ") || !strings.Contains(output, "2. ") || !strings.Contains(output, "new CI runner") {
+		t.Errorf("Expected both annotations listed, got:\n%s", output)
+	}
+	withArgs([]string{"gokanon", "list", "-storage=" + tempDir}, func() {
+		output = captureOutput(t, func() {
+			if err := List(); err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+		})
+	})
+	if lines := strings.Split(strings.TrimSpace(output), "\n"); !strings.HasPrefix(lines[len(lines)-1], "test-run-3") {
+		t.Errorf("Expected the oldest run last, without annotations, got:\n%s", output)
+	}
+	withArgs([]string{"gokanon", "list", "-storage=" + tempDir, "-annotations"}, func() {
+		output = captureOutput(t, func() {
+			if err := List(); err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+		})
+	})
+	if !strings.Contains(output, "Annotations") || !strings.Contains(output, "[upgrade] upgraded Go to 1.22") {
+		t.Errorf("Expected the annotations in the list, got:\n%s", output)
+	}
+
+	withArgs([]string{"gokanon", "annotate", "-storage=" + tempDir, "-remove=1", "test-run-1"}, func() {
+		captureOutput(t, func() {
+			if err := Annotate(); err != nil {
+				t.Fatalf("Annotate -remove failed: %v", err)
+			}
+		})
+	})
+	run, _ = store.Load("test-run-1")
+	if len(run.Annotations) != 1 || run.Annotations[0].Message != "new CI runner" {
+		t.Errorf("Annotations after removal = %+v", run.Annotations)
+	}
+
+	withArgs([]string{"gokanon", "annotate", "-storage=" + tempDir, "-remove=3", "test-run-1"}, func() {
+		if err := Annotate(); err == nil || !strings.Contains(err.Error(), "No annotation 3") {
+			t.Errorf("Expected a missing annotation error, got %v", err)
+		}
+	})
+	withArgs([]string{"gokanon", "annotate", "-storage=" + tempDir, "test-run-1", " "}, func() {
+		if err := Annotate(); err == nil || !strings.Contains(err.Error(), "Empty annotation") {
+			t.Errorf("Expected an empty annotation error, got %v", err)
+		}
+	})
+}
+
+func TestReanalyze(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	fn := &profile.Function{ID: 1, Name: "example.BenchmarkParse"}
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "cpu", Unit: "nanoseconds"}},
+		Function:   []*profile.Function{fn},
+		Location:   []*profile.Location{{ID: 1, Line: []profile.Line{{Function: fn}}}},
+	}
+	prof.Sample = []*profile.Sample{{Location: prof.Location, Value: []int64{42}}}
+	var buf bytes.Buffer
+	if err := prof.Write(&buf); err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+	if err := store.SaveProfile("test-run-1", "cpu", &buf); err != nil {
+		t.Fatalf("Failed to save profile: %v", err)
+	}
+	// A summary left by an older analyzer
+	run, err := store.Load("test-run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.CPUProfile = store.GetCPUProfilePath(run.ID)
+	run.ProfileSummary = &models.ProfileSummary{TotalCPUSamples: 1}
+	if err := store.SaveMetadata(run); err != nil {
+		t.Fatal(err)
+	}
+
+	var output string
+	withArgs([]string{"gokanon", "reanalyze", "-storage=" + tempDir, "-all"}, func() {
+		output = captureOutput(t, func() {
+			if err := Reanalyze(); err != nil {
+				t.Fatalf("Reanalyze failed: %v", err)
+			}
+		})
+	})
+	run, err = store.Load("test-run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := run.ProfileSummary
+	if summary == nil || summary.TotalCPUSamples != 42 || len(summary.CPUTopFunctions) != 1 || summary.CPUTopFunctions[0].Name != "example.BenchmarkParse" {
+		t.Fatalf("ProfileSummary = %+v", summary)
+	}
+	if !strings.Contains(output, "test-run-1") || !strings.Contains(output, "0 → 1") || !strings.Contains(output, "Skipped 2 run(s)") {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+
+	// Runs without profiles are left alone
+	withArgs([]string{"gokanon", "reanalyze", "-storage=" + tempDir, "test-run-2"}, func() {
+		captureOutput(t, func() {
+			if err := Reanalyze(); err == nil {
+				t.Error("Expected an error without stored profiles")
+			}
+		})
+	})
+	withArgs([]string{"gokanon", "reanalyze", "-storage=" + tempDir, "-all", "test-run-1"}, func() {
+		if err := Reanalyze(); err == nil {
+			t.Error("Expected a usage error for -all with runs")
+		}
+	})
+}
+
+func TestShow(t *testing.T) {
+	store, tempDir, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	run, err := store.Load("test-run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.Commit = "0123456789abcdef"
+	run.CommitMessage = "Speed up the parser"
+	run.Tags = map[string]string{"branch": "main"}
+	run.Annotations = []models.Annotation{{Time: time.Now(), Kind: "infra", Message: "new CI runner"}}
+	run.Results[0].Confidence = "B"
+	run.Results[0].Metrics = map[string]float64{"rows/op": 12}
+	run.PrunedProfiles = []string{"cpu"}
+	if err := store.SaveMetadata(run); err != nil {
+		t.Fatal(err)
+	}
+
+	var output string
+	withArgs([]string{"gokanon", "show", "-storage=" + tempDir, "latest"}, func() {
+		output = captureOutput(t, func() {
+			if err := Show(); err != nil {
+				t.Fatalf("Show failed: %v", err)
+			}
+		})
+	})
+	for _, want := range []string{"test-run-1", "go test -bench=.", "0123456789abcdef", "Speed up the parser",
+		"branch=main", "[infra] new CI runner", "BenchmarkAnother", "12 rows/op", "pruned"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+
+	withArgs([]string{"gokanon", "show", "-storage=" + tempDir, "-o", "json", "test-run-2"}, func() {
+		output = captureOutput(t, func() {
+			if err := Show(); err != nil {
+				t.Fatalf("Show failed: %v", err)
+			}
+		})
+	})
+	var shown struct {
+		ID       string                   `json:"id"`
+		Results  []models.BenchmarkResult `json:"results"`
+		Profiles []profileStatus          `json:"profiles"`
+	}
+	if err := json.Unmarshal([]byte(output), &shown); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, output)
+	}
+	if shown.ID != "test-run-2" || len(shown.Results) != 2 || len(shown.Profiles) != 0 {
+		t.Errorf("Unexpected JSON: %+v", shown)
+	}
+
+	withArgs([]string{"gokanon", "show", "-storage=" + tempDir, "-o", "yaml", "latest"}, func() {
+		if err := Show(); err == nil {
+			t.Error("Expected an error for an unknown output format")
+		}
+	})
+	withArgs([]string{"gokanon", "show", "-storage=" + tempDir, "no-such-run"}, func() {
+		if err := Show(); err == nil {
+			t.Error("Expected an error for an unknown run")
+		}
+	})
+}
+
+func TestCheckAlerts(t *testing.T) {
+	notified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notified++
+	}))
+	defer server.Close()
+
+	store := storage.NewStorage(t.TempDir())
+	now := time.Now()
+	save := func(id string, age time.Duration, nsPerOp float64, status string) {
+		run := &models.BenchmarkRun{
+			ID:        id,
+			Timestamp: now.Add(-age),
+			Status:    status,
+			Results:   []models.BenchmarkResult{{Name: "BenchmarkCheckout", Iterations: 1000, NsPerOp: nsPerOp}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rules := []config.Alert{{Name: "creep", Benchmark: "Checkout", MaxIncrease: 2, Notify: []string{"webhook:" + server.URL}}}
+
+	// A failed run does not bend a flat trend
+	save("run-1", 72*time.Hour, 100, "")
+	save("run-2", 48*time.Hour, 100, "")
+	save("run-3", 24*time.Hour, 100, "")
+	save("run-4", 12*time.Hour, 1000, models.StatusFailed)
+	captureOutput(t, func() { checkAlerts(store, rules) })
+	if notified != 0 {
+		t.Fatalf("Expected no notification for a flat trend, got %d", notified)
+	}
+
+	// Channels are notified once when the rule starts firing
+	save("run-5", 0, 130, "")
+	output := captureOutput(t, func() { checkAlerts(store, rules) })
+	if notified != 1 || !strings.Contains(output, "Alert: Checkout") {
+		t.Fatalf("Expected one notification, got %d:\n%s", notified, output)
+	}
+	output = captureOutput(t, func() { checkAlerts(store, rules) })
+	if notified != 1 || !strings.Contains(output, "Alert: Checkout") {
+		t.Errorf("Expected a warning without a new notification, got %d:\n%s", notified, output)
+	}
+	firing, err := store.LoadFiringAlerts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := firing["creep"]["Checkout"]; !ok {
+		t.Errorf("Expected the alert recorded as firing, got %v", firing)
+	}
+}
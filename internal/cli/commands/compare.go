@@ -4,9 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/alenon/gokanon/internal/aianalyzer"
 	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/models"
 	"github.com/alenon/gokanon/internal/storage"
 	"github.com/alenon/gokanon/internal/ui"
@@ -15,10 +19,29 @@ import (
 // Compare handles the 'compare' subcommand
 func Compare() error {
 	compareFlags := flag.NewFlagSet("compare", flag.ExitOnError)
-	storageDir := compareFlags.String("storage", ".gokanon", "Storage directory for results")
+	storageDir := compareFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
 	latest := compareFlags.Bool("latest", false, "Compare the last two runs")
 	baseline := compareFlags.String("baseline", "", "Compare latest run against a baseline")
-	compareFlags.Parse(os.Args[2:])
+	compareFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	dryRun := compareFlags.Bool("dry-run", false, "Only report how benchmark names were matched")
+	normalize := compareFlags.String("normalize", "", "Divide ns/op by this reference benchmark of each run (default: normalization.reference from the config)")
+	allowEnvMismatch := compareFlags.Bool("allow-env-mismatch", false, "Compare runs taken with another Go release, build settings or machine, marking the output")
+	scaling := compareFlags.Bool("scaling", false, "Compare the scalability curves (ns/op by GOMAXPROCS) of runs taken with -cpu=1,2,4,...")
+	noCache := compareFlags.Bool("no-cache", false, "Ask the AI provider again instead of replaying a cached analysis")
+	times := addTimeFlags(compareFlags, "default")
+	cfg, err := parseFlags(compareFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
+	comparer, err := newComparer(cfg)
+	if err != nil {
+		return err
+	}
 
 	store := storage.NewStorage(*storageDir)
 
@@ -53,7 +76,7 @@ func Compare() error {
 		newID = latestRun.ID
 	} else if *latest {
 		// Get the two most recent runs
-		runs, err := store.List()
+		runs, err := store.Latest(2, func(*models.BenchmarkRun) bool { return true })
 		if err != nil {
 			return fmt.Errorf("failed to list results: %w", err)
 		}
@@ -66,7 +89,7 @@ func Compare() error {
 		// Get IDs from arguments
 		args := compareFlags.Args()
 		if len(args) != 2 {
-			return fmt.Errorf("usage: gokanon compare <old-id> <new-id> OR gokanon compare --latest OR gokanon compare --baseline=<name>")
+			return fmt.Errorf("usage: gokanon compare <old-run> <new-run> OR gokanon compare --latest OR gokanon compare --baseline=<name>")
 		}
 		oldID = args[0]
 		newID = args[1]
@@ -75,45 +98,113 @@ func Compare() error {
 	// Load benchmark runs if not already loaded
 	if oldRun == nil {
 		var err error
-		oldRun, err = store.Load(oldID)
+		oldRun, err = store.Resolve(oldID)
 		if err != nil {
 			return fmt.Errorf("failed to load old run: %w", err)
 		}
+		oldID = oldRun.ID
 	}
 
 	if newRun == nil {
 		var err error
-		newRun, err = store.Load(newID)
+		newRun, err = store.Resolve(newID)
 		if err != nil {
 			return fmt.Errorf("failed to load new run: %w", err)
 		}
+		newID = newRun.ID
+	}
+
+	runs, err := normalizeRuns(cfg, *normalize, oldRun, newRun)
+	if err != nil {
+		return err
+	}
+	oldRun, newRun = runs[0], runs[1]
+
+	if *dryRun {
+		fmt.Printf("Matching: %s vs %s\n\n", oldID, newID)
+		for _, m := range comparer.Match(oldRun, newRun) {
+			fmt.Println(compare.FormatMatch(m))
+		}
+		return nil
+	}
+
+	differences, err := checkEnvironments(oldRun, newRun, *allowEnvMismatch)
+	if err != nil {
+		return err
+	}
+
+	if *scaling {
+		return printScaling(oldID, newID, oldRun, newRun, differences)
 	}
 
 	// Compare
-	comparer := compare.NewComparer()
 	comparisons := comparer.Compare(oldRun, newRun)
 
 	if len(comparisons) == 0 {
-		fmt.Println("No matching benchmarks found between the two runs.")
+		fmt.Println("No benchmarks found in the two runs.")
 		return nil
 	}
+	matched, added, removed := compare.Split(comparisons)
 
 	// Display comparison
-	fmt.Printf("Comparing: %s (%s) vs %s (%s)\n\n",
-		oldID, oldRun.Timestamp.Format("2006-01-02 15:04:05"),
-		newID, newRun.Timestamp.Format("2006-01-02 15:04:05"),
+	fmt.Printf("Comparing: %s (%s) vs %s (%s)\n",
+		oldID, timeFormat.Format(oldRun.Timestamp),
+		newID, timeFormat.Format(newRun.Timestamp),
 	)
+	if newRun.NormalizedTo != "" {
+		fmt.Printf("Normalized to: Benchmark%s (values in multiples of its ns/op)\n", newRun.NormalizedTo)
+	}
+	warnEnvironments(oldRun, newRun, differences)
+	fmt.Println()
 
-	for _, comp := range comparisons {
+	if len(matched) == 0 {
+		fmt.Println("No matching benchmarks found between the two runs.")
+	}
+	for _, comp := range matched {
 		fmt.Println(compare.FormatComparison(comp))
+		// Unchanged standard metrics would only repeat the timing
+		for _, metric := range comp.StandardMetrics() {
+			if metric.Status != "same" {
+				fmt.Println(compare.FormatMetricComparison(metric))
+			}
+		}
+		if comp.Contention != nil && comp.Contention.Status != "same" {
+			fmt.Println(compare.FormatMetricComparison(*comp.Contention))
+		}
+		for _, metric := range comp.Metrics {
+			fmt.Println(compare.FormatMetricComparison(metric))
+		}
+	}
+
+	if len(added) > 0 {
+		fmt.Printf("\nAdded benchmarks (%d):\n", len(added))
+		for _, comp := range added {
+			fmt.Println(compare.FormatComparison(comp))
+		}
+	}
+	if len(removed) > 0 {
+		fmt.Printf("\nRemoved benchmarks (%d):\n", len(removed))
+		for _, comp := range removed {
+			fmt.Println(compare.FormatComparison(comp))
+		}
 	}
 
 	fmt.Printf("\n%s\n", compare.Summary(comparisons))
+	printPackageSummaries(comparisons)
+	warnHiddenContention(matched)
+
+	// Point at dependency upgrades as suspects of regressions
+	for _, comp := range matched {
+		if comp.Status == "degraded" {
+			fmt.Printf("\n%s\n", compare.FormatDependencyDiff(compare.DiffDependencies(oldRun, newRun)))
+			break
+		}
+	}
 
 	// Add AI analysis if enabled
-	aiAnalyzer, err := aianalyzer.NewFromEnv()
+	aiAnalyzer, err := aianalyzer.NewAnalyzer(aianalyzer.ConfigFromEnv(aiDefaults(cfg)))
 	if err == nil {
-		analysis, err := aiAnalyzer.AnalyzeComparison(oldRun, newRun, comparisons)
+		analysis, err := aiAnalyzer.WithCache(store, *noCache).AnalyzeComparison(oldRun, newRun, comparisons)
 		if err == nil && analysis != "" {
 			fmt.Printf("\n--- AI Analysis ---\n%s\n", analysis)
 		}
@@ -121,3 +212,141 @@ func Compare() error {
 
 	return nil
 }
+
+// printScaling prints the ns/op of each benchmark at every GOMAXPROCS
+// value both runs measured, with its speedup over the lowest value
+func printScaling(oldID, newID string, oldRun, newRun *models.BenchmarkRun, differences []string) error {
+	scaling := compare.Scaling(oldRun, newRun)
+	if len(scaling) == 0 {
+		return ui.NewError("No scalability curves to compare", nil,
+			"Both runs need benchmarks measured at two or more GOMAXPROCS values",
+			"Take them with: gokanon run -cpu=1,2,4,8")
+	}
+
+	fmt.Printf("Scaling: %s vs %s\n", oldID, newID)
+	warnEnvironments(oldRun, newRun, differences)
+	for _, curve := range scaling {
+		fmt.Printf("\n%s\n", ui.Bold(curve.Benchmark))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  CPUs\tOld ns/op\tNew ns/op\tDelta\tOld Speedup\tNew Speedup")
+		for _, level := range curve.Levels {
+			fmt.Fprintf(w, "  %d\t%.2f\t%.2f\t%s\t%.2fx\t%.2fx\n", level.Procs, level.OldNsPerOp, level.NewNsPerOp,
+				ui.FormatChange(level.DeltaPercent), level.OldSpeedup, level.NewSpeedup)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+// printPackageSummaries aggregates the comparison by package when the
+// benchmarks span several
+func printPackageSummaries(comparisons []models.Comparison) {
+	summaries := compare.PackageSummaries(comparisons)
+	if len(summaries) < 2 {
+		return
+	}
+	fmt.Println("\nBy package:")
+	for _, summary := range summaries {
+		fmt.Printf("  %s\n", compare.FormatPackageSummary(summary))
+	}
+}
+
+// warnHiddenContention warns about benchmarks whose goroutines wait longer
+// on each other although their ns/op stayed flat, which tends to surface as
+// tail latency under production load
+func warnHiddenContention(matched []models.Comparison) {
+	hidden := compare.HiddenContention(matched)
+	if len(hidden) == 0 {
+		return
+	}
+	fmt.Println()
+	ui.PrintWarning("Contention grew while ns/op stayed flat in %d benchmark(s):", len(hidden))
+	for _, comp := range hidden {
+		fmt.Printf("  %s: %s\n", comp.Name, ui.Dim(fmt.Sprintf("%.4g → %.4g %s (%+.0f%%)",
+			comp.Contention.Old, comp.Contention.New, models.ContentionMetric, comp.Contention.DeltaPercent)))
+	}
+	fmt.Println(ui.Dim("  Goroutines wait longer on each other; expect tail latency under heavier load. See the block and mutex profiles."))
+}
+
+// checkEnvironments refuses to compare runs taken in different
+// environments, such as on another Go release or number of CPUs, unless
+// allowed. Advisory differences such as the CPU model never refuse. It
+// returns all differences for warnEnvironments to print.
+func checkEnvironments(oldRun, newRun *models.BenchmarkRun, allow bool) ([]string, error) {
+	blocking, advisory := compare.EnvironmentDifferences(oldRun, newRun)
+	if len(blocking) > 0 && !allow {
+		return nil, ui.NewError("The runs were taken in different environments: "+strings.Join(blocking, "; "), nil,
+			"Deltas may come from the environment rather than the code: run both in the same environment",
+			"Or compare anyway with -allow-env-mismatch; the results are then marked as such")
+	}
+	return append(blocking, advisory...), nil
+}
+
+// warnEnvironments stamps the output with a banner listing how the
+// environments of the runs differ, and warns about a machine that turned
+// busy or idle, which makes results noisier
+func warnEnvironments(oldRun, newRun *models.BenchmarkRun, differences []string) {
+	if len(differences) > 0 {
+		fmt.Println()
+		fmt.Println(ui.Bold(ui.Warning("⚠ ENVIRONMENTS DIFFER: deltas may come from the environment rather than the code")))
+		for _, difference := range differences {
+			fmt.Printf("  • %s\n", difference)
+		}
+	}
+	for _, m := range compare.EnvironmentMismatches(oldRun, newRun) {
+		if m.Property == "Machine load" {
+			ui.PrintWarning("%s", compare.FormatEnvironmentMismatch(m))
+		}
+	}
+}
+
+// loadConfig loads the project configuration
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, ui.NewError("Failed to load configuration", err,
+			"Check the syntax of "+path)
+	}
+	return cfg, nil
+}
+
+// newComparer creates a comparer using the matching rules of the project configuration
+func newComparer(cfg *config.Config) (*compare.Comparer, error) {
+	rules := compare.MatchRules{
+		StripCPUSuffix: cfg.Matching.CPUSuffixStripped(),
+		PackageRenames: cfg.Matching.PackageRenames,
+	}
+	for _, pattern := range cfg.Matching.StripPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid strip pattern %q: %w", pattern, err)
+		}
+		rules.StripPatterns = append(rules.StripPatterns, re)
+	}
+
+	return compare.NewComparer().WithMatchRules(rules), nil
+}
+
+// normalizeRuns divides the ns/op values of the runs by the reference
+// benchmark given with -normalize or in the configuration. Runs are returned
+// unchanged when no reference is set.
+func normalizeRuns(cfg *config.Config, reference string, runs ...*models.BenchmarkRun) ([]*models.BenchmarkRun, error) {
+	if reference == "" {
+		reference = cfg.Normalization.Reference
+	}
+	if reference == "" {
+		return runs, nil
+	}
+
+	normalized := make([]*models.BenchmarkRun, len(runs))
+	for i, run := range runs {
+		n, err := compare.Normalize(run, reference)
+		if err != nil {
+			return nil, ui.NewError("Failed to normalize results", err,
+				"Add a calibration benchmark named "+reference+" to the benchmarked package",
+				"Or compare without normalization: remove normalization.reference from the config")
+		}
+		normalized[i] = n
+	}
+	return normalized, nil
+}
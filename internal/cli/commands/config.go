@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Config handles the 'config' subcommand
+func Config() error {
+	if len(os.Args) < 3 {
+		fmt.Println("Configuration commands:")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  gokanon config <subcommand> [options]")
+		fmt.Println()
+		fmt.Println("Subcommands:")
+		fmt.Println("  path [storage|config]   Print the resolved storage directory and configuration file")
+		fmt.Println()
+		fmt.Println("Without -storage and -config flags, gokanon uses the nearest .gokanon directory")
+		fmt.Println("and .gokanon.yaml file in the working directory or its parents, falling back to")
+		fmt.Println("the storage registered for the project (see 'gokanon projects'), a per-project")
+		fmt.Println("directory under $XDG_DATA_HOME/gokanon, and $XDG_CONFIG_HOME/gokanon/config.yaml.")
+		fmt.Println()
+		return nil
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "path":
+		return configPath()
+	default:
+		return ui.NewError(
+			fmt.Sprintf("Unknown config subcommand: %s", subcommand),
+			nil,
+			"Valid subcommands: path",
+			"Run 'gokanon config' to see usage",
+		)
+	}
+}
+
+// configPath prints the default locations. With an argument it prints just
+// that path, for use in scripts.
+func configPath() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	loc := config.Resolve(dir)
+
+	if len(os.Args) > 3 {
+		switch os.Args[3] {
+		case "storage":
+			fmt.Println(loc.Storage)
+		case "config":
+			fmt.Println(loc.Config)
+		default:
+			return ui.NewError(
+				fmt.Sprintf("Unknown location: %s", os.Args[3]),
+				nil,
+				"Valid locations: storage, config",
+			)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Storage:\t%s\t%s\n", loc.Storage, ui.Dim(describeLocation(loc.Storage, loc.StorageSource)))
+	fmt.Fprintf(w, "Config:\t%s\t%s\n", loc.Config, ui.Dim(describeLocation(loc.Config, loc.ConfigSource)))
+	if loc.ProjectRoot != "" {
+		fmt.Fprintf(w, "Project:\t%s\n", loc.ProjectRoot)
+	}
+	fmt.Fprintf(w, "XDG data home:\t%s\n", loc.DataHome)
+	fmt.Fprintf(w, "XDG config home:\t%s\n", loc.ConfigHome)
+	return w.Flush()
+}
+
+// describeLocation describes where a location comes from and whether it exists
+func describeLocation(path, source string) string {
+	description := map[string]string{
+		config.SourceProject:  "project",
+		config.SourceRegistry: "registered project",
+		config.SourceUser:     "user default",
+		config.SourceLocal:    "working directory",
+		config.SourceConfig:   "set in config",
+	}[source]
+	if _, err := os.Stat(path); err != nil {
+		description += ", not created yet"
+	}
+	return "(" + description + ")"
+}
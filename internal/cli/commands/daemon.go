@@ -0,0 +1,329 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alenon/gokanon/internal/alert"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/schedule"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/threshold"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// scheduleTag is the tag recording which schedule of the daemon saved a run
+const scheduleTag = "schedule"
+
+// Daemon handles the 'daemon' subcommand: it runs the benchmarks of the
+// schedules declared in the configuration at their times, until stopped
+func Daemon() error {
+	daemonFlags := flag.NewFlagSet("daemon", flag.ExitOnError)
+	storageDir := daemonFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	daemonFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	once := daemonFlags.String("once", "", "Run this schedule now and exit, to try it out")
+	cfg, err := parseFlags(daemonFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Schedules) == 0 {
+		return ui.NewError("No schedules configured", nil,
+			"Declare them in the configuration file, e.g.:\n  schedules:\n    - name: nightly\n      cron: '0 2 * * *'\n      notify: [slack:https://hooks.slack.com/services/...]")
+	}
+	d, err := newDaemon(storage.NewStorage(*storageDir), cfg)
+	if err != nil {
+		return err
+	}
+
+	// Stop between runs, or interrupt the run in progress, on Ctrl+C or
+	// when the service manager stops the daemon
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once != "" {
+		for _, s := range d.schedules {
+			if s.Name == *once {
+				return d.runSchedule(ctx, s)
+			}
+		}
+		names := make([]string, len(d.schedules))
+		for i, s := range d.schedules {
+			names[i] = s.Name
+		}
+		return ui.NewError(fmt.Sprintf("Unknown schedule: %s", *once), nil, "Configured schedules: "+strings.Join(names, ", "))
+	}
+	return d.loop(ctx)
+}
+
+// daemon runs the scheduled benchmarks and keeps their status in the
+// storage for the dashboard
+type daemon struct {
+	store     *storage.Storage
+	cfg       *config.Config
+	schedules []*scheduled
+	status    models.DaemonStatus
+
+	// benchmark runs the benchmarks of a schedule, replaced in tests
+	benchmark func(ctx context.Context, s *scheduled) (*models.BenchmarkRun, error)
+}
+
+// scheduled is a configured schedule with its parsed expression and status
+type scheduled struct {
+	config.Schedule
+	cron   *schedule.Cron
+	status *models.ScheduleStatus
+}
+
+// newDaemon prepares the schedules of the configuration
+func newDaemon(store *storage.Storage, cfg *config.Config) (*daemon, error) {
+	d := &daemon{
+		store: store,
+		cfg:   cfg,
+		status: models.DaemonStatus{
+			PID:       os.Getpid(),
+			StartedAt: time.Now(),
+			Running:   true,
+			Schedules: make([]models.ScheduleStatus, len(cfg.Schedules)),
+		},
+	}
+	d.benchmark = d.runBenchmarks
+	for i, s := range cfg.Schedules {
+		cron, err := schedule.Parse(s.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", s.Name, err)
+		}
+		d.status.Schedules[i] = models.ScheduleStatus{Name: s.Name, Cron: s.Cron}
+		d.schedules = append(d.schedules, &scheduled{Schedule: s, cron: cron, status: &d.status.Schedules[i]})
+	}
+	return d, nil
+}
+
+// loop runs each schedule when it is due, until ctx is done. Schedules due
+// while another one runs are run after it; times missed while a run took
+// longer than the interval are skipped.
+func (d *daemon) loop(ctx context.Context) error {
+	ui.PrintHeader("Benchmark Daemon")
+	fmt.Println()
+	now := time.Now()
+	for _, s := range d.schedules {
+		s.status.Next = s.cron.Next(now)
+		if s.status.Next.IsZero() {
+			ui.PrintWarning("Schedule %s (%s) never runs", s.Name, s.Cron)
+			continue
+		}
+		fmt.Printf("  %s (%s): next run at %s\n", ui.Bold(s.Name), s.Cron, s.status.Next.Format(time.RFC3339))
+	}
+	fmt.Println()
+	d.saveStatus()
+	defer func() {
+		d.status.Running = false
+		d.saveStatus()
+	}()
+
+	for {
+		due := d.nextDue()
+		if due == nil {
+			return ui.NewError("No schedule will ever run", nil, "Check the cron expressions of the schedules")
+		}
+		timer := time.NewTimer(time.Until(due.status.Next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			ui.PrintInfo("Daemon stopped")
+			return nil
+		case <-timer.C:
+		}
+
+		// Failures are recorded in the status and notified; the daemon
+		// keeps running
+		d.runSchedule(ctx, due)
+		if ctx.Err() != nil {
+			ui.PrintInfo("Daemon stopped")
+			return nil
+		}
+		due.status.Next = due.cron.Next(time.Now())
+		d.saveStatus()
+	}
+}
+
+// nextDue returns the schedule due first
+func (d *daemon) nextDue() *scheduled {
+	var due *scheduled
+	for _, s := range d.schedules {
+		if s.status.Next.IsZero() {
+			continue
+		}
+		if due == nil || s.status.Next.Before(due.status.Next) {
+			due = s
+		}
+	}
+	return due
+}
+
+// runSchedule runs the benchmarks of a schedule, compares them with the
+// previous run of the schedule and notifies its channels of regressions
+// and failures
+func (d *daemon) runSchedule(ctx context.Context, s *scheduled) error {
+	status := s.status
+	*status = models.ScheduleStatus{Name: status.Name, Cron: status.Cron, Next: status.Next, LastRun: time.Now()}
+	ui.PrintInfo("%s: running benchmarks", s.Name)
+	d.saveStatus()
+	defer d.saveStatus()
+
+	run, err := d.benchmark(ctx, s)
+	if err != nil {
+		status.LastStatus = models.StatusFailed
+		status.LastError = err.Error()
+		ui.PrintError("%s: %v", s.Name, err)
+		d.notify(s, nil, nil, fmt.Sprintf("gokanon: scheduled run %s failed: %v", s.Name, err))
+		return err
+	}
+	status.LastRunID = run.ID
+	if run.Failed() {
+		status.LastStatus = models.StatusFailed
+		status.LastError = run.Error
+		ui.PrintError("%s: run %s terminated abnormally: %s", s.Name, run.ID, run.Error)
+		d.notify(s, run, nil, fmt.Sprintf("gokanon: scheduled run %s (%s) terminated abnormally: %s", s.Name, run.ID, run.Error))
+		return errors.New(run.Error)
+	}
+	if run.Degraded() {
+		status.LastStatus = models.StatusDegraded
+		status.LastError = run.Error
+		ui.PrintWarning("%s: run %s recorded in a degraded environment: %s", s.Name, run.ID, run.Error)
+		return nil
+	}
+
+	previous, err := d.previousRun(s, run.ID)
+	if err != nil {
+		status.LastStatus = models.StatusFailed
+		status.LastError = err.Error()
+		ui.PrintError("%s: %v", s.Name, err)
+		return err
+	}
+	status.LastStatus = models.StatusOK
+	if previous == nil {
+		ui.PrintSuccess("%s: run %s saved, the first of the schedule", s.Name, run.ID)
+		return nil
+	}
+
+	comparer, err := newComparer(d.cfg)
+	if err != nil {
+		return err
+	}
+	result := threshold.NewChecker(s.RegressionThreshold()).
+		WithSource("schedules." + s.Name + ".threshold").
+		WithBenchmarkThresholds(d.cfg.Thresholds).
+		Check(comparer.Compare(previous, run))
+	if result.Passed {
+		ui.PrintSuccess("%s: run %s saved, no regression against %s", s.Name, run.ID, previous.ID)
+		return nil
+	}
+
+	status.LastStatus = "regressed"
+	status.Regressions = len(result.Failures)
+	lines := make([]string, len(result.Failures))
+	for i, failure := range result.Failures {
+		lines[i] = failure.Message
+	}
+	ui.PrintWarning("%s: run %s regressed against %s:\n  %s", s.Name, run.ID, previous.ID, strings.Join(lines, "\n  "))
+	d.notify(s, run, result, fmt.Sprintf("gokanon: scheduled run %s (%s) regressed against %s:\n%s",
+		s.Name, run.ID, previous.ID, strings.Join(lines, "\n")))
+	return nil
+}
+
+// runBenchmarks runs and saves the benchmarks of a schedule, queueing
+// behind runs started by hand, and applies the alerts and retention policy
+// as 'gokanon run' does
+func (d *daemon) runBenchmarks(ctx context.Context, s *scheduled) (*models.BenchmarkRun, error) {
+	lock, err := acquireRunLock(d.store, true)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	pkg, bench := s.Pkg, s.Bench
+	if pkg == "" {
+		pkg = "./..."
+	}
+	if bench == "" {
+		bench = "."
+	}
+	r := runner.NewRunner(pkg, bench).WithContext(ctx).WithLiveStatus(d.store)
+	if s.Benchtime != "" {
+		r = r.WithBenchtime(s.Benchtime)
+	}
+	if s.Count > 1 {
+		r = r.WithCount(s.Count)
+	}
+	extractors, err := metricExtractors(d.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(extractors) > 0 {
+		r = r.WithMetricExtractors(extractors)
+	}
+
+	run, err := r.Run()
+	if err != nil {
+		return nil, err
+	}
+	run.Tags = map[string]string{scheduleTag: s.Name}
+	gradeRun(d.store, run)
+	if err := d.store.Save(run); err != nil {
+		return nil, fmt.Errorf("failed to save run: %w", err)
+	}
+	checkAlerts(d.store, d.cfg.Alerts)
+	autoPrune(d.store, d.cfg.Retention)
+	return run, nil
+}
+
+// previousRun returns the newest run of a schedule before the one given
+// that completed in a stable environment, or nil for the first
+func (d *daemon) previousRun(s *scheduled, runID string) (*models.BenchmarkRun, error) {
+	runs, err := d.store.Latest(1, func(run *models.BenchmarkRun) bool {
+		return run.ID != runID && run.Tags[scheduleTag] == s.Name && !run.Failed() && !run.Degraded()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return &runs[0], nil
+}
+
+// notify sends the outcome of a scheduled run to the channels of its
+// schedule: the run and check result as JSON to webhooks, text to Slack
+func (d *daemon) notify(s *scheduled, run *models.BenchmarkRun, result *threshold.Result, text string) {
+	if len(s.Notify) == 0 {
+		return
+	}
+	payload := map[string]any{"schedule": s.Name, "status": s.status.LastStatus, "message": text}
+	if run != nil {
+		payload["runId"] = run.ID
+	}
+	if result != nil {
+		payload["failures"] = result.Failures
+	}
+	if err := alert.Send(s.Notify, payload, text); err != nil {
+		ui.PrintWarning("%s: failed to notify: %v", s.Name, err)
+	}
+}
+
+// saveStatus publishes the status of the schedules for the dashboard
+func (d *daemon) saveStatus() {
+	d.status.UpdatedAt = time.Now()
+	if err := d.store.SaveDaemonStatus(&d.status); err != nil {
+		ui.PrintWarning("Failed to save the daemon status: %v", err)
+	}
+}
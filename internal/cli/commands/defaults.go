@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alenon/gokanon/internal/aianalyzer"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// parseFlags parses the arguments of a command and fills the flags that
+// were not given with the defaults of the project configuration. The
+// configuration is read from -config when the command has that flag, and
+// from the default location otherwise.
+func parseFlags(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	fs.Parse(args)
+
+	path := config.DefaultPath()
+	if f := fs.Lookup("config"); f != nil {
+		path = f.Value.String()
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for name, value := range flagDefaults(cfg.Defaults, fs.Name()) {
+		if given[name] || value == "" || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return nil, ui.NewError(fmt.Sprintf("Invalid default for -%s in configuration", name), err,
+				"Check the defaults section of "+path)
+		}
+		f := fs.Lookup(name)
+		f.Value = configuredValue{f.Value}
+	}
+	return cfg, nil
+}
+
+// configuredValue marks a flag set by parseFlags from the configuration
+type configuredValue struct {
+	flag.Value
+}
+
+// Sources of a flag value
+const (
+	sourceFlag    = "flag"
+	sourceConfig  = "config"
+	sourceDefault = "default"
+)
+
+// flagSource tells where the value of a flag parsed by parseFlags comes
+// from: the command line, the configuration or the flag's own default
+func flagSource(fs *flag.FlagSet, name string) string {
+	source := sourceDefault
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != name {
+			return
+		}
+		source = sourceFlag
+		if _, ok := f.Value.(configuredValue); ok {
+			source = sourceConfig
+		}
+	})
+	return source
+}
+
+// flagDefaults returns the configured default of each flag by flag name.
+// Flags with a meaning specific to one command only apply to that command.
+func flagDefaults(d config.Defaults, command string) map[string]string {
+	values := map[string]string{
+		"storage":   d.Storage,
+		"bench":     d.Bench,
+		"benchtime": d.Benchtime,
+	}
+	if d.Count > 0 {
+		values["count"] = strconv.Itoa(d.Count)
+	}
+	if d.Threshold > 0 {
+		values["threshold"] = strconv.FormatFloat(d.Threshold, 'f', -1, 64)
+	}
+	if d.Open {
+		values["open"] = "true"
+	}
+	switch command {
+	case "export":
+		values["format"] = d.Export.Format
+	case "bisect":
+		// Its -bench defaults to the regressed benchmark alone. Its -count
+		// and -threshold mean what they do for run and check, and apply.
+		delete(values, "bench")
+	case "stability":
+		// Its -count is the number of runs needed to judge noise and its
+		// -threshold sizes the suggested -count, unlike those of run and check
+		delete(values, "count")
+		delete(values, "threshold")
+	}
+	return values
+}
+
+// aiDefaults returns the AI analyzer settings of the project configuration.
+// Timeouts were validated when the configuration was loaded.
+func aiDefaults(cfg *config.Config) aianalyzer.Config {
+	ai := cfg.Defaults.AI
+	defaults := aianalyzer.Config{
+		Enabled:  ai.Enabled,
+		Provider: ai.Provider,
+		Model:    ai.Model,
+		BaseURL:  ai.BaseURL,
+		Retries:  ai.Retries,
+	}
+	defaults.Timeout, _ = time.ParseDuration(ai.Timeout)
+	for _, fallback := range ai.Fallback {
+		timeout, _ := time.ParseDuration(fallback.Timeout)
+		defaults.Fallbacks = append(defaults.Fallbacks, aianalyzer.Config{
+			Enabled:  true,
+			Provider: fallback.Provider,
+			Model:    fallback.Model,
+			BaseURL:  fallback.BaseURL,
+			Timeout:  timeout,
+		})
+	}
+	return defaults
+}
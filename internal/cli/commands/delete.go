@@ -5,22 +5,29 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/storage"
 )
 
 // Delete handles the 'delete' subcommand
 func Delete() error {
 	deleteFlags := flag.NewFlagSet("delete", flag.ExitOnError)
-	storageDir := deleteFlags.String("storage", ".gokanon", "Storage directory for results")
-	deleteFlags.Parse(os.Args[2:])
+	storageDir := deleteFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	if _, err := parseFlags(deleteFlags, os.Args[2:]); err != nil {
+		return err
+	}
 
 	args := deleteFlags.Args()
 	if len(args) != 1 {
-		return fmt.Errorf("usage: gokanon delete <id>")
+		return fmt.Errorf("usage: gokanon delete <run>")
 	}
 
-	id := args[0]
 	store := storage.NewStorage(*storageDir)
+	run, err := store.Resolve(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to find run: %w", err)
+	}
+	id := run.ID
 
 	if err := store.Delete(id); err != nil {
 		return fmt.Errorf("failed to delete run: %w", err)
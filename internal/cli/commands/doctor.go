@@ -1,19 +1,55 @@
 package commands
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"os"
 
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/doctor"
+	"github.com/alenon/gokanon/internal/ui"
 )
 
 // Doctor runs diagnostics to check the setup
 func Doctor() error {
-	results := doctor.RunDiagnostics()
-	doctor.PrintResults(results)
+	doctorFlags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	ci := doctorFlags.Bool("ci", false, "Run the non-interactive preflight checks of a CI pipeline")
+	jsonOutput := doctorFlags.Bool("json", false, "Print the results as JSON")
+	storageDir := doctorFlags.String("storage", config.DefaultStorageDir(), "Storage directory checked by -ci")
+	baseline := doctorFlags.String("baseline", "", "Baseline that must exist, checked by -ci")
+	doctorFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	if _, err := parseFlags(doctorFlags, os.Args[2:]); err != nil {
+		return err
+	}
+	if *baseline != "" && !*ci {
+		return ui.NewError("-baseline needs -ci", nil, "Run: gokanon doctor -ci -baseline="+*baseline)
+	}
+
+	var results []doctor.CheckResult
+	switch {
+	case *ci:
+		results = doctor.CIChecks(doctor.CIOptions{StorageDir: *storageDir, Baseline: *baseline})
+	case *jsonOutput:
+		results = doctor.Diagnostics()
+	default:
+		results = doctor.RunDiagnostics()
+	}
+
+	report := doctor.NewReport(results)
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		doctor.PrintResults(results)
+	}
 
 	// Return error if any critical checks failed
 	for _, result := range results {
-		if !result.Passed && (result.Name == "Go Installation" || result.Name == "Go Test Command") {
+		if !result.Passed && result.Critical {
 			return fmt.Errorf("critical check failed: %s", result.Name)
 		}
 	}
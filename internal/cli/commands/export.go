@@ -5,26 +5,121 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/export"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/telemetry"
 )
 
 // Export handles the 'export' subcommand
 func Export() error {
 	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
-	storageDir := exportFlags.String("storage", ".gokanon", "Storage directory for results")
+	storageDir := exportFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
 	latest := exportFlags.Bool("latest", false, "Export comparison of last two runs")
-	format := exportFlags.String("format", "html", "Export format: html, csv, markdown")
-	output := exportFlags.String("output", "", "Output file (default: comparison.<format>)")
-	exportFlags.Parse(os.Args[2:])
+	format := exportFlags.String("format", "html", "Export format: html, csv, markdown, benchstat (text tables), ipynb (Jupyter notebook), parquet, badge (SVG performance score badge)")
+	output := exportFlags.String("output", "", "Output file (default: comparison.<format>, comparison.txt for benchstat, score.svg for badges, history.parquet with -history, run-<id>.html with -run)")
+	history := exportFlags.Bool("history", false, "Export the full result history instead of two runs (parquet only)")
+	runID := exportFlags.String("run", "", "Export a single run as a self-contained page instead of a comparison (html only)")
+	summaryOnly := exportFlags.Bool("summary-only", false, "Export only the counts and the top 5 regressions and improvements (markdown only)")
+	exportFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	allowEnvMismatch := exportFlags.Bool("allow-env-mismatch", false, "Export a comparison of runs taken with another Go release, build settings or machine, marked as such")
+	normalize := exportFlags.String("normalize", "", "Divide ns/op by this reference benchmark of each run (default: normalization.reference from the config)")
+	deterministic := exportFlags.Bool("deterministic", false, "Sort benchmarks by name, round values and leave out run IDs and timestamps, for golden files (html, csv, markdown, benchstat)")
+	round := exportFlags.Int("round", 0, "Round values to N significant digits (default 3 with -deterministic)")
+	browser := addOpenFlags(exportFlags, "HTML report")
+	keepTimestamps := exportFlags.Bool("timestamps", false, "Keep the run IDs and timestamps of -deterministic exports")
+	times := addTimeFlags(exportFlags, "default")
+	cfg, err := parseFlags(exportFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
+	comparer, err := newComparer(cfg)
+	if err != nil {
+		return err
+	}
+
+	if *summaryOnly && *format != "markdown" && *format != "md" {
+		return fmt.Errorf("-summary-only is only supported with -format=markdown")
+	}
+
+	switch *format {
+	case "html", "csv", "markdown", "md", "benchstat", "ipynb", "parquet", "badge":
+		telemetry.Record("export." + *format)
+	}
+
+	if *round < 0 {
+		return fmt.Errorf("-round must be positive, got %d", *round)
+	}
+	digits := *round
+	if *deterministic && digits == 0 {
+		digits = 3
+	}
+	if *deterministic || digits > 0 {
+		switch {
+		case *runID != "" || *history:
+			return fmt.Errorf("-deterministic and -round only apply to comparisons, not to -run or -history")
+		case *format != "html" && *format != "csv" && *format != "markdown" && *format != "md" && *format != "benchstat":
+			return fmt.Errorf("-deterministic and -round are only supported with -format=html, csv, markdown or benchstat")
+		}
+	}
 
 	store := storage.NewStorage(*storageDir)
 
+	// A run page shows one run rather than a comparison
+	if *runID != "" {
+		if *format != "html" {
+			return fmt.Errorf("-run is only supported with -format=html")
+		}
+		run, err := store.Resolve(*runID)
+		if err != nil {
+			return fmt.Errorf("failed to load run: %w", err)
+		}
+		outputFile := *output
+		if outputFile == "" {
+			outputFile = fmt.Sprintf("run-%s.html", run.ID)
+		}
+		if err := export.NewExporter().ToRunHTML(run, timeFormat.Format(run.Timestamp), outputFile); err != nil {
+			return fmt.Errorf("failed to export: %w", err)
+		}
+		fmt.Printf("Run %s exported to: %s\n", run.ID, outputFile)
+		browser.openFile(outputFile)
+		return nil
+	}
+
+	// The history export covers every saved run rather than a pair of runs
+	if *history {
+		if *format != "parquet" {
+			return fmt.Errorf("-history is only supported with -format=parquet")
+		}
+		runs, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list results: %w", err)
+		}
+		if len(runs) == 0 {
+			return fmt.Errorf("no benchmark results to export")
+		}
+		outputFile := *output
+		if outputFile == "" {
+			outputFile = "history.parquet"
+		}
+		if err := export.NewExporter().ToParquet(runs, outputFile); err != nil {
+			return fmt.Errorf("failed to export: %w", err)
+		}
+		fmt.Printf("Result history of %d runs exported to: %s\n", len(runs), outputFile)
+		return nil
+	}
+
 	var oldID, newID string
 
 	if *latest {
-		runs, err := store.List()
+		runs, err := store.Latest(2, func(*models.BenchmarkRun) bool { return true })
 		if err != nil {
 			return fmt.Errorf("failed to list results: %w", err)
 		}
@@ -36,25 +131,57 @@ func Export() error {
 	} else {
 		args := exportFlags.Args()
 		if len(args) != 2 {
-			return fmt.Errorf("usage: gokanon export <old-id> <new-id> OR gokanon export --latest")
+			return fmt.Errorf("usage: gokanon export <old-run> <new-run> OR gokanon export --latest")
 		}
 		oldID = args[0]
 		newID = args[1]
 	}
 
 	// Load benchmark runs
-	oldRun, err := store.Load(oldID)
+	oldRun, err := store.Resolve(oldID)
 	if err != nil {
 		return fmt.Errorf("failed to load old run: %w", err)
 	}
 
-	newRun, err := store.Load(newID)
+	newRun, err := store.Resolve(newID)
 	if err != nil {
 		return fmt.Errorf("failed to load new run: %w", err)
 	}
+	oldID, newID = oldRun.ID, newRun.ID
+
+	// The badge shows the score of the new run, which needs no comparison
+	if *format == "badge" {
+		outputFile := *output
+		if outputFile == "" {
+			outputFile = "score.svg"
+		}
+		score, ok := stats.Score(newRun, cfg.Score.Weights)
+		if !ok {
+			return fmt.Errorf("run %s has no measured benchmarks to score", newID)
+		}
+		previous, _ := stats.Score(oldRun, cfg.Score.Weights)
+		if err := export.NewExporter().ToBadge(score, previous, outputFile); err != nil {
+			return fmt.Errorf("failed to export: %w", err)
+		}
+		fmt.Printf("Score badge exported to: %s\n", outputFile)
+		return nil
+	}
+
+	// Notebooks and Parquet files hold the runs as recorded
+	rawOld, rawNew := oldRun, newRun
+
+	runs, err := normalizeRuns(cfg, *normalize, oldRun, newRun)
+	if err != nil {
+		return err
+	}
+	oldRun, newRun = runs[0], runs[1]
+
+	differences, err := checkEnvironments(oldRun, newRun, *allowEnvMismatch)
+	if err != nil {
+		return err
+	}
 
 	// Compare
-	comparer := compare.NewComparer()
 	comparisons := comparer.Compare(oldRun, newRun)
 
 	if len(comparisons) == 0 {
@@ -65,25 +192,55 @@ func Export() error {
 	outputFile := *output
 	if outputFile == "" {
 		outputFile = fmt.Sprintf("comparison.%s", *format)
+		if *format == "benchstat" {
+			outputFile = "comparison.txt"
+		}
 	}
 
 	// Export
 	exporter := export.NewExporter()
+	exporter.SetEnvironmentDifferences(differences)
+	exporter.SetAnnotations(rawOld.Annotations, rawNew.Annotations)
+	exporter.SetSorted(*deterministic)
+	exporter.SetRounding(digits)
+
+	// Run IDs are derived from the time of the run, so deterministic
+	// exports label the runs instead
+	oldLabel, newLabel := oldID, newID
+	oldTime, newTime := timeFormat.Format(oldRun.Timestamp), timeFormat.Format(newRun.Timestamp)
+	if *deterministic && !*keepTimestamps {
+		oldLabel, newLabel = "old", "new"
+		oldTime, newTime = "", ""
+	}
 	switch *format {
 	case "html":
+		var suggestions []models.Suggestion
+		if rawNew.ProfileSummary != nil {
+			suggestions = rawNew.ProfileSummary.Suggestions
+		}
 		err = exporter.ToHTML(
 			comparisons,
-			oldID, newID,
-			oldRun.Timestamp.Format("2006-01-02 15:04:05"),
-			newRun.Timestamp.Format("2006-01-02 15:04:05"),
+			suggestions,
+			oldLabel, newLabel,
+			oldTime, newTime,
 			outputFile,
 		)
 	case "csv":
 		err = exporter.ToCSV(comparisons, outputFile)
 	case "markdown", "md":
-		err = exporter.ToMarkdown(comparisons, oldID, newID, outputFile)
+		if *summaryOnly {
+			err = exporter.ToMarkdownSummary(comparisons, oldLabel, newLabel, outputFile)
+		} else {
+			err = exporter.ToMarkdown(comparisons, oldLabel, newLabel, outputFile)
+		}
+	case "benchstat":
+		err = exporter.ToBenchstat(comparisons, outputFile)
+	case "ipynb":
+		err = exporter.ToNotebook(rawOld, rawNew, comparisons, outputFile)
+	case "parquet":
+		err = exporter.ToParquet([]models.BenchmarkRun{*rawOld, *rawNew}, outputFile)
 	default:
-		return fmt.Errorf("unsupported format: %s (supported: html, csv, markdown)", *format)
+		return fmt.Errorf("unsupported format: %s (supported: html, csv, markdown, benchstat, ipynb, parquet, badge)", *format)
 	}
 
 	if err != nil {
@@ -91,5 +248,8 @@ func Export() error {
 	}
 
 	fmt.Printf("Comparison exported to: %s\n", outputFile)
+	if *format == "html" {
+		browser.openFile(outputFile)
+	}
 	return nil
 }
@@ -4,18 +4,30 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/profiler"
 	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
 	"github.com/alenon/gokanon/internal/webserver"
 )
 
 // Flamegraph handles the 'flamegraph' subcommand
 func Flamegraph() error {
 	flamegraphFlags := flag.NewFlagSet("flamegraph", flag.ExitOnError)
-	storageDir := flamegraphFlags.String("storage", ".gokanon", "Storage directory for results")
+	storageDir := flamegraphFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
 	port := flamegraphFlags.String("port", "8080", "Port for web server")
 	latest := flamegraphFlags.Bool("latest", false, "View profiles for latest run")
-	flamegraphFlags.Parse(os.Args[2:])
+	format := flamegraphFlags.String("format", "web", "Output format: web (viewer), speedscope or collapsed (FlameGraph.pl)")
+	output := flamegraphFlags.String("o", "", "Output file for speedscope or collapsed (default: <run>.speedscope.json or <run>-<profile>.folded)")
+	profileName := flamegraphFlags.String("profile", "", "Profile to convert: cpu, mem, warmup, block, mutex or an attached profile (default: all for speedscope, cpu for collapsed)")
+	sampleType := flamegraphFlags.String("sample-type", "", "Sample type to convert (default: the profile's default, alloc_space for memory)")
+	browser := addOpenFlags(flamegraphFlags, "flame graph viewer")
+	if _, err := parseFlags(flamegraphFlags, os.Args[2:]); err != nil {
+		return err
+	}
 
 	store := storage.NewStorage(*storageDir)
 
@@ -32,17 +44,31 @@ func Flamegraph() error {
 		// Get run ID from arguments
 		args := flamegraphFlags.Args()
 		if len(args) != 1 {
-			return fmt.Errorf("usage: gokanon flamegraph <run-id> OR gokanon flamegraph --latest")
+			return fmt.Errorf("usage: gokanon flamegraph <run> OR gokanon flamegraph --latest")
 		}
 		runID = args[0]
 	}
 
 	// Load the run to verify it has profiles
-	run, err := store.Load(runID)
+	run, err := store.Resolve(runID)
 	if err != nil {
 		return fmt.Errorf("failed to load run: %w", err)
 	}
+	runID = run.ID
+
+	switch *format {
+	case "web":
+	case "speedscope", "collapsed":
+		return convertProfiles(store, run, *format, *profileName, *sampleType, *output)
+	default:
+		return ui.NewError(fmt.Sprintf("Unknown format: %s", *format), nil, "Use -format=web, -format=speedscope or -format=collapsed")
+	}
 
+	if run.CPUProfile == "" && run.MemoryProfile == "" && len(run.PrunedProfiles) > 0 {
+		return ui.NewError(fmt.Sprintf("The profiles of run %s were pruned on %s", runID, run.ProfilesPrunedAt.Format("2006-01-02")), nil,
+			"Its results and profile summary were kept; only the profile files are gone",
+			"Keep profiles longer by raising -profiles-older-than of gokanon prune")
+	}
 	if run.CPUProfile == "" && run.MemoryProfile == "" {
 		return fmt.Errorf("no profiles found for run %s\n\nRun benchmarks with profiling enabled:\n  gokanon run --profile=cpu,mem", runID)
 	}
@@ -58,5 +84,99 @@ func Flamegraph() error {
 
 	// Start web server
 	server := webserver.NewServer(store, *port)
+	browser.openWhenListening("localhost:"+*port, "http://localhost:"+*port)
 	return server.Start(runID)
 }
+
+// storedProfile is a profile file of a run
+type storedProfile struct {
+	name string
+	path string
+}
+
+// storedProfiles lists the profiles stored for a run: the collected CPU,
+// memory, warmup, block and mutex profiles, then the attached ones
+func storedProfiles(store *storage.Storage, run *models.BenchmarkRun) []storedProfile {
+	var result []storedProfile
+	for _, p := range []storedProfile{
+		{"cpu", store.GetCPUProfilePath(run.ID)},
+		{"mem", store.GetMemoryProfilePath(run.ID)},
+		{"warmup", store.GetWarmupProfilePath(run.ID)},
+		{"block", store.GetBlockProfilePath(run.ID)},
+		{"mutex", store.GetMutexProfilePath(run.ID)},
+	} {
+		if _, err := os.Stat(p.path); err == nil {
+			result = append(result, p)
+		}
+	}
+	for _, p := range run.AttachedProfiles {
+		result = append(result, storedProfile{p.Name, store.GetAttachedProfilePath(run.ID, p.Name)})
+	}
+	return result
+}
+
+// convertProfiles writes the profiles of a run in the speedscope or the
+// collapsed stack format
+func convertProfiles(store *storage.Storage, run *models.BenchmarkRun, format, name, sampleType, output string) error {
+	available := storedProfiles(store, run)
+	if len(available) == 0 {
+		return ui.NewError(fmt.Sprintf("No profiles found for run %s", run.ID), nil,
+			"Run benchmarks with profiling enabled: gokanon run --profile=cpu,mem")
+	}
+
+	// Collapsed stacks hold a single profile
+	if name == "" && format == "collapsed" {
+		name = available[0].name
+	}
+	var selected []storedProfile
+	var names []string
+	for _, p := range available {
+		names = append(names, p.name)
+		if name == "" || p.name == name || name == "memory" && p.name == "mem" {
+			selected = append(selected, p)
+		}
+	}
+	if len(selected) == 0 {
+		return ui.NewError(fmt.Sprintf("Run %s has no %s profile", run.ID, name), nil,
+			"Available profiles: "+strings.Join(names, ", "))
+	}
+
+	var profiles []profiler.ExportProfile
+	for _, p := range selected {
+		data, err := os.ReadFile(p.path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s profile: %w", p.name, err)
+		}
+		profiles = append(profiles, profiler.ExportProfile{Name: p.name, Data: data, SampleType: sampleType})
+	}
+
+	if output == "" {
+		if format == "speedscope" {
+			output = run.ID + ".speedscope.json"
+		} else {
+			output = fmt.Sprintf("%s-%s.folded", run.ID, selected[0].name)
+		}
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return ui.NewError("Failed to create output file", err)
+	}
+	defer f.Close()
+
+	if format == "speedscope" {
+		err = profiler.WriteSpeedscope(f, run.ID, profiles)
+	} else {
+		err = profiler.WriteCollapsed(f, profiles[0])
+	}
+	if err != nil {
+		return ui.NewError(fmt.Sprintf("Failed to convert the profiles of run %s", run.ID), err)
+	}
+
+	ui.PrintSuccess("Profiles exported to: %s", output)
+	if format == "speedscope" {
+		ui.PrintInfo("Open it at https://www.speedscope.app or with: speedscope %s", output)
+	} else {
+		ui.PrintInfo("Render it with: flamegraph.pl %s > flamegraph.svg", output)
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/fleet"
+	"github.com/alenon/gokanon/internal/remote"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/threshold"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Fleet handles the 'fleet' subcommand
+func Fleet() error {
+	if len(os.Args) < 3 {
+		fmt.Println("Roll up the benchmark results of many repositories:")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  gokanon fleet <subcommand> [options]")
+		fmt.Println()
+		fmt.Println("Subcommands:")
+		fmt.Println("  report   Regressions of the newest runs, grouped by repository and owner")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  gokanon fleet report                  # Every project in the project registry")
+		fmt.Println("  gokanon fleet report -storage=api/.gokanon -storage=web=frontend/.gokanon")
+		fmt.Println("  gokanon fleet report -remote=billing=s3://bench/billing -owner=billing=payments-team")
+		fmt.Println("  gokanon fleet report -baseline=main -format=markdown -output=fleet.md")
+		fmt.Println()
+		fmt.Println("Owners come from -owner, or from the owner field of each repository's")
+		fmt.Println(".gokanon.yaml.")
+		fmt.Println()
+		return nil
+	}
+
+	subcommand := os.Args[2]
+	switch subcommand {
+	case "report":
+		return fleetReport()
+	default:
+		return ui.NewError(
+			fmt.Sprintf("Unknown fleet subcommand: %s", subcommand),
+			nil,
+			"Valid subcommands: report",
+			"Run 'gokanon fleet' to see usage",
+		)
+	}
+}
+
+// fleetReport checks the newest run of each repository and prints the
+// regressions by owner
+func fleetReport() error {
+	fleetFlags := flag.NewFlagSet("fleet-report", flag.ExitOnError)
+	var storageSpecs, remoteSpecs, ownerSpecs []string
+	fleetFlags.Func("storage", "Storage directory of a repository, as dir or name=dir; repeatable (default: the projects in the registry)", func(spec string) error {
+		storageSpecs = append(storageSpecs, spec)
+		return nil
+	})
+	fleetFlags.Func("remote", "Bucket a repository syncs its history to, as url or name=url; repeatable", func(spec string) error {
+		remoteSpecs = append(remoteSpecs, spec)
+		return nil
+	})
+	fleetFlags.Func("owner", "Owner of a repository, as repo=team, overriding its configuration; repeatable", func(spec string) error {
+		ownerSpecs = append(ownerSpecs, spec)
+		return nil
+	})
+	endpoint := fleetFlags.String("endpoint", "", "Endpoint of an S3-compatible service for -remote, e.g. http://localhost:9000")
+	region := fleetFlags.String("region", "", "Bucket region for -remote (default: AWS_REGION or us-east-1)")
+	baseline := fleetFlags.String("baseline", "", "Compare the newest runs with this baseline where it exists (default: the previous run)")
+	thresholdPercent := fleetFlags.Float64("threshold", 5.0, "Maximum allowed performance degradation (%)")
+	format := fleetFlags.String("format", "text", "Output format: text, json, markdown")
+	output := fleetFlags.String("output", "", "Write the report to this file instead of stdout")
+	fleetFlags.Parse(os.Args[3:])
+
+	if *format != "text" && *format != "json" && *format != "markdown" {
+		return ui.NewError(fmt.Sprintf("Unknown format: %s", *format), nil, "Use -format=text, -format=json or -format=markdown")
+	}
+	owners := make(map[string]string)
+	for _, spec := range ownerSpecs {
+		repo, owner, ok := strings.Cut(spec, "=")
+		if !ok || repo == "" || owner == "" {
+			return ui.NewError(fmt.Sprintf("Invalid -owner: %s", spec), nil, "Use -owner=repo=team, e.g. -owner=api=platform")
+		}
+		owners[repo] = owner
+	}
+
+	var repos []fleet.Repo
+	for _, spec := range storageSpecs {
+		repo, err := localFleetRepo(spec, *thresholdPercent)
+		if err != nil {
+			return err
+		}
+		repos = append(repos, repo)
+	}
+	if len(remoteSpecs) > 0 {
+		remoteRepos, cleanup, err := remoteFleetRepos(remoteSpecs, *endpoint, *region)
+		defer cleanup()
+		if err != nil {
+			return err
+		}
+		repos = append(repos, remoteRepos...)
+	}
+	if len(storageSpecs) == 0 && len(remoteSpecs) == 0 {
+		registered, err := registeredFleetRepos(*thresholdPercent)
+		if err != nil {
+			return err
+		}
+		repos = registered
+	}
+	if len(repos) == 0 {
+		return ui.NewError("No repositories to report on", nil,
+			"Give their storage directories: gokanon fleet report -storage=api/.gokanon -storage=web/.gokanon",
+			"Or their buckets: -remote=api=s3://bench/api")
+	}
+
+	for i := range repos {
+		if owner, ok := owners[repos[i].Name]; ok {
+			repos[i].Owner = owner
+		}
+	}
+
+	report := fleet.Build(repos, fleet.Options{Threshold: *thresholdPercent, Baseline: *baseline})
+
+	var out bytes.Buffer
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		out.Write(append(data, '\n'))
+	case "markdown":
+		out.WriteString(fleet.Markdown(report))
+	default:
+		if *output == "" {
+			printFleetReport(report)
+			return nil
+		}
+		out.WriteString(fleet.Markdown(report))
+	}
+
+	if *output == "" {
+		os.Stdout.Write(out.Bytes())
+		return nil
+	}
+	if err := os.WriteFile(*output, out.Bytes(), 0644); err != nil {
+		return ui.NewError("Failed to write report", err, "Check that the directory of -output exists and is writable")
+	}
+	ui.PrintSuccess("Fleet report written to %s", *output)
+	return nil
+}
+
+// localFleetRepo resolves a -storage entry. The repository is named after
+// the project the directory belongs to, and takes the owner, matching rules
+// and thresholds from that project's configuration.
+func localFleetRepo(spec string, percent float64) (fleet.Repo, error) {
+	name, dir, ok := strings.Cut(spec, "=")
+	if !ok {
+		name, dir = "", spec
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fleet.Repo{}, fmt.Errorf("failed to resolve %s: %w", spec, err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fleet.Repo{}, ui.NewError(fmt.Sprintf("No storage directory at %s", dir), err,
+			"Point -storage at the .gokanon directory of a repository")
+	}
+
+	root := config.ProjectRoot(filepath.Dir(dir))
+	if name == "" {
+		name = filepath.Base(filepath.Dir(dir))
+		if root != "" {
+			name = filepath.Base(root)
+		}
+	}
+	return fleetRepo(name, root, dir, percent)
+}
+
+// registeredFleetRepos returns the projects of the project registry
+func registeredFleetRepos(percent float64) ([]fleet.Repo, error) {
+	path := config.RegistryPath()
+	if path == "" {
+		return nil, nil
+	}
+	registry, err := config.LoadRegistry(path)
+	if err != nil {
+		return nil, err
+	}
+	var repos []fleet.Repo
+	for _, p := range registry.Projects {
+		repo, err := fleetRepo(p.Name(), p.Root, p.Storage, percent)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
+
+// fleetRepo creates the repository of a storage directory, configured by
+// the .gokanon.yaml at the project root, if any
+func fleetRepo(name, root, dir string, percent float64) (fleet.Repo, error) {
+	cfg := &config.Config{}
+	if root != "" {
+		var err error
+		if cfg, err = loadConfig(filepath.Join(root, config.DefaultFile)); err != nil {
+			return fleet.Repo{}, err
+		}
+	}
+	comparer, err := newComparer(cfg)
+	if err != nil {
+		return fleet.Repo{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return fleet.Repo{
+		Name:     name,
+		Owner:    cfg.Owner,
+		Storage:  storage.NewStorage(dir),
+		Comparer: comparer,
+		Checker:  threshold.NewChecker(percent).WithBenchmarkThresholds(cfg.Thresholds),
+	}, nil
+}
+
+// remoteFleetRepos pulls the history of each -remote bucket into a
+// temporary storage directory, removed by the returned cleanup function
+func remoteFleetRepos(specs []string, endpoint, region string) ([]fleet.Repo, func(), error) {
+	tempDir, err := os.MkdirTemp("", "gokanon-fleet-")
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	creds, err := remote.CredentialsFromEnv()
+	if err != nil {
+		return nil, cleanup, ui.NewError("Missing bucket credentials", err,
+			"Export AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY",
+			"For Cloud Storage, create HMAC keys for a service account")
+	}
+
+	var repos []fleet.Repo
+	for i, spec := range specs {
+		name, rawURL, ok := strings.Cut(spec, "=")
+		if !ok || strings.Contains(name, "://") {
+			name, rawURL = "", spec
+		}
+		loc, err := remote.ParseLocation(rawURL, endpoint, region)
+		if err != nil {
+			return nil, cleanup, ui.NewError(fmt.Sprintf("Invalid remote: %s", spec), err, "Use s3://bucket/prefix or gs://bucket/prefix, optionally as name=url")
+		}
+		if name == "" {
+			name = loc.String()
+		}
+
+		store := storage.NewStorage(filepath.Join(tempDir, fmt.Sprintf("repo-%d", i)))
+		spinner := ui.NewSpinner(fmt.Sprintf("Pulling from %s", loc))
+		spinner.Start()
+		_, err = remote.NewSyncer(store, remote.NewClient(loc, creds)).Pull(remote.Options{})
+		spinner.Stop()
+		if err != nil {
+			return nil, cleanup, ui.NewError(fmt.Sprintf("Failed to pull from %s", loc), err,
+				"Check the bucket name, region and credentials")
+		}
+		repos = append(repos, fleet.Repo{Name: name, Storage: store})
+	}
+	return repos, cleanup, nil
+}
+
+// printFleetReport prints the regressions of the fleet by owner
+func printFleetReport(report *fleet.Report) {
+	ui.PrintHeader("Fleet Benchmark Report")
+	fmt.Println()
+	summary := fmt.Sprintf("%d of %d repositories regressed beyond %.1f%%, %d benchmarks in all",
+		report.Regressing, report.Repos, report.Threshold, report.Regressions)
+	if report.Regressing > 0 {
+		ui.PrintWarning("%s", summary)
+	} else {
+		ui.PrintSuccess("%s", summary)
+	}
+
+	for _, owner := range report.Owners {
+		ui.PrintSection(ui.ChartEmoji, fmt.Sprintf("%s (%d regressions)", fleet.OwnerName(owner.Owner), owner.Regressions))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  Repository\tNewest Run\tCompared With\tRegressions\tImproved")
+		for _, repo := range owner.Repos {
+			if repo.Error != "" {
+				run := repo.Run
+				if run == "" {
+					run = "-"
+				}
+				fmt.Fprintf(w, "  %s\t%s\t%s\t-\t-\n", repo.Name, run, ui.Dim(repo.Error))
+				continue
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%d\n", repo.Name, repo.Run, repo.Against, len(repo.Regressions), repo.Improved)
+		}
+		w.Flush()
+
+		for _, repo := range owner.Repos {
+			if len(repo.Regressions) == 0 {
+				continue
+			}
+			fmt.Printf("\n  %s\n", ui.Bold(repo.Name))
+			for _, regression := range repo.Regressions {
+				fmt.Printf("    %s %s: %.2f → %.2f %s (%s)\n", ui.Error(ui.ErrorIcon), regression.Benchmark,
+					regression.Old, regression.New, regression.Unit, ui.FormatChange(regression.DeltaPercent))
+			}
+		}
+	}
+}
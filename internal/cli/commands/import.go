@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Import handles the 'import' subcommand, storing benchmark output that was
+// produced without gokanon as a run, or the history of other tools as runs
+func Import() error {
+	importFlags := flag.NewFlagSet("import", flag.ExitOnError)
+	storageDir := importFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	importFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	format := importFlags.String("format", "auto", "Input format: auto, "+strings.Join(runner.ImportFormats, ", "))
+	var timestampFlags []string
+	importFlags.Func("timestamp", "Time the benchmarks ran, in RFC 3339 format (default: now); repeat for each imported run", func(value string) error {
+		timestampFlags = append(timestampFlags, value)
+		return nil
+	})
+	pkg := importFlags.String("pkg", "", "Package to record when the output has no pkg: line")
+	commit := importFlags.String("commit", "", "Git commit the benchmarks ran at, for commit: references")
+	cfg, err := parseFlags(importFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	args := importFlags.Args()
+	if len(args) > 1 {
+		return fmt.Errorf("usage: gokanon import [options] [file|url]")
+	}
+	timestamps := make([]time.Time, len(timestampFlags))
+	for i, value := range timestampFlags {
+		if timestamps[i], err = time.Parse(time.RFC3339, value); err != nil {
+			return ui.NewError("Invalid -timestamp", err, "Example: -timestamp=2024-05-01T12:00:00Z")
+		}
+	}
+	if *format != "auto" && !slices.Contains(runner.ImportFormats, *format) {
+		return ui.NewError("Invalid -format", fmt.Errorf("unknown format %q", *format),
+			"Use auto, "+strings.Join(runner.ImportFormats, ", "))
+	}
+
+	extractors, err := metricExtractors(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Read from stdin unless a file or the URL of a perf storage query is given
+	source := "stdin"
+	if len(args) == 1 && args[0] != "-" {
+		source = args[0]
+	}
+	data, err := readImport(source)
+	if err != nil {
+		return ui.NewError("Failed to read "+source, err)
+	}
+
+	if *format == "auto" {
+		*format = runner.DetectFormat(data)
+	}
+	var runs []*models.BenchmarkRun
+	switch *format {
+	case runner.FormatPerf:
+		runs, err = runner.ImportPerf(bytes.NewReader(data), extractors)
+	case runner.FormatBenchstatCSV:
+		runs, err = runner.ImportBenchstatCSV(bytes.NewReader(data))
+	default:
+		var run *models.BenchmarkRun
+		if run, err = runner.Import(bytes.NewReader(data), extractors); err == nil {
+			runs = []*models.BenchmarkRun{run}
+		}
+	}
+	if err != nil {
+		return ui.NewError(
+			"Failed to import benchmark results from "+source,
+			err,
+			"The input must contain go test -bench result lines, perf storage results or benchstat CSV tables",
+			"Example: go test -bench=. -benchmem | gokanon import",
+		)
+	}
+	if len(timestamps) > 0 && len(timestamps) != len(runs) {
+		return ui.NewError(
+			"Invalid -timestamp",
+			fmt.Errorf("%d timestamps given for %d runs", len(timestamps), len(runs)),
+			"Repeat -timestamp for each imported run, in the order of the input",
+		)
+	}
+
+	store := storage.NewStorage(*storageDir)
+	for i, run := range runs {
+		run.Command = "gokanon import " + source
+		if len(timestamps) > 0 {
+			run.Timestamp = timestamps[i]
+		}
+		if run.Package == "" {
+			run.Package = *pkg
+		}
+		if *commit != "" {
+			run.Commit = *commit
+		}
+		// IDs sort by the time the benchmarks ran, not by when they were imported
+		run.ID = store.NewRunIDAt(run.Timestamp)
+		if err := store.Save(run); err != nil {
+			return ui.NewError(
+				"Failed to save results",
+				err,
+				"Check file permissions on storage directory",
+				"Ensure you have write access to: "+*storageDir,
+			)
+		}
+	}
+	if err := config.RegisterProject(*storageDir); err != nil {
+		ui.PrintWarning("Failed to update the project registry: %v", err)
+	}
+
+	if len(runs) > 1 {
+		ui.PrintSuccess("Imported %d runs from %s", len(runs), source)
+		for _, run := range runs {
+			label := run.Note
+			if label == "" {
+				label = run.Tags["upload"]
+			}
+			fmt.Printf("  %s  %s  %d benchmark(s)  %s\n", ui.Bold(run.ID), run.Timestamp.Format(time.RFC3339), len(run.Results), label)
+		}
+		return nil
+	}
+	run := runs[0]
+	ui.PrintSuccess("Imported %d benchmark(s) from %s", len(run.Results), source)
+	fmt.Printf("Results saved with ID: %s\n\n", ui.Bold(run.ID))
+	printResultsTable(run.Results)
+	displayCustomMetrics(run.Results)
+	return nil
+}
+
+// readImport reads the input of an import: stdin, a file, or the results
+// of a query of a perf storage server, e.g.
+// https://perfdata.golang.org/search?q=upload:20170101.1
+func readImport(source string) ([]byte, error) {
+	switch {
+	case source == "stdin":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		client := &http.Client{Timeout: 5 * time.Minute}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("server responded %s", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	default:
+		return os.ReadFile(source)
+	}
+}
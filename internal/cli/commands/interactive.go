@@ -1,15 +1,47 @@
 package commands
 
 import (
+	"flag"
 	"os"
 
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/interactive"
+	"github.com/alenon/gokanon/internal/storage"
 	"github.com/alenon/gokanon/internal/ui"
 )
 
 // Interactive starts the interactive mode
 func Interactive() error {
-	session, err := interactive.New()
+	interactiveFlags := flag.NewFlagSet("interactive", flag.ExitOnError)
+	storageDir := interactiveFlags.String("storage", config.DefaultStorageDir(), "Storage directory whose runs, baselines and benchmarks are completed")
+	prompt := interactiveFlags.String("prompt", "", "Prompt (default: interactive.prompt of the configuration, or \"gokanon> \")")
+	history := interactiveFlags.String("history", "", "History file, or \"off\" to keep no history (default: the history shared by all projects)")
+	interactiveFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	cfg, err := parseFlags(interactiveFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	opts := interactive.Options{
+		Prompt:       cfg.Interactive.Prompt,
+		HistoryFile:  cfg.Interactive.History,
+		HistoryLimit: cfg.Interactive.HistoryLimit,
+		Source:       interactive.StorageSource(storage.NewStorage(*storageDir)),
+	}
+	if *prompt != "" {
+		opts.Prompt = *prompt
+	}
+	if *history != "" {
+		opts.HistoryFile = *history
+	}
+	switch opts.HistoryFile {
+	case "":
+		opts.HistoryFile = config.HistoryPath()
+	case config.HistoryOff:
+		opts.HistoryFile = ""
+	}
+
+	session, err := interactive.NewWithOptions(opts)
 	if err != nil {
 		return ui.NewError(
 			"Failed to start interactive mode",
@@ -70,7 +102,93 @@ func Interactive() error {
 		return Delete()
 	})
 
+	session.RegisterCommand("baseline", func(args []string) error {
+		os.Args = append([]string{"gokanon", "baseline"}, args...)
+		return Baseline()
+	})
+
+	session.RegisterCommand("attach", func(args []string) error {
+		os.Args = append([]string{"gokanon", "attach"}, args...)
+		return Attach()
+	})
+
+	session.RegisterCommand("slo", func(args []string) error {
+		os.Args = append([]string{"gokanon", "slo"}, args...)
+		return SLO()
+	})
+
+	session.RegisterCommand("config", func(args []string) error {
+		os.Args = append([]string{"gokanon", "config"}, args...)
+		return Config()
+	})
+
+	session.RegisterCommand("import", func(args []string) error {
+		os.Args = append([]string{"gokanon", "import"}, args...)
+		return Import()
+	})
+
+	session.RegisterCommand("projects", func(args []string) error {
+		os.Args = append([]string{"gokanon", "projects"}, args...)
+		return Projects()
+	})
+
+	session.RegisterCommand("bisect", func(args []string) error {
+		os.Args = append([]string{"gokanon", "bisect"}, args...)
+		return Bisect()
+	})
+
+	session.RegisterCommand("sync", func(args []string) error {
+		os.Args = append([]string{"gokanon", "sync"}, args...)
+		return Sync()
+	})
+
+	session.RegisterCommand("push", func(args []string) error {
+		os.Args = append([]string{"gokanon", "push"}, args...)
+		return Push()
+	})
+
+	session.RegisterCommand("profile", func(args []string) error {
+		os.Args = append([]string{"gokanon", "profile"}, args...)
+		return Profile()
+	})
+
+	session.RegisterCommand("snapshot", func(args []string) error {
+		os.Args = append([]string{"gokanon", "snapshot"}, args...)
+		return Snapshot()
+	})
+
+	session.RegisterCommand("stability", func(args []string) error {
+		os.Args = append([]string{"gokanon", "stability"}, args...)
+		return Stability()
+	})
+
+	session.RegisterCommand("prune", func(args []string) error {
+		os.Args = append([]string{"gokanon", "prune"}, args...)
+		return Prune()
+	})
+
+	session.RegisterCommand("search", func(args []string) error {
+		os.Args = append([]string{"gokanon", "search"}, args...)
+		return Search()
+	})
+
+	session.RegisterCommand("fleet", func(args []string) error {
+		os.Args = append([]string{"gokanon", "fleet"}, args...)
+		return Fleet()
+	})
+
+	session.RegisterCommand("tui", func(args []string) error {
+		os.Args = append([]string{"gokanon", "tui"}, args...)
+		return TUI()
+	})
+
+	session.RegisterCommand("analyze", func(args []string) error {
+		os.Args = append([]string{"gokanon", "analyze"}, args...)
+		return Analyze()
+	})
+
 	session.RegisterCommand("doctor", func(args []string) error {
+		os.Args = append([]string{"gokanon", "doctor"}, args...)
 		return Doctor()
 	})
 
@@ -6,14 +6,30 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
 )
 
 // List handles the 'list' subcommand
 func List() error {
 	listFlags := flag.NewFlagSet("list", flag.ExitOnError)
-	storageDir := listFlags.String("storage", ".gokanon", "Storage directory for results")
-	listFlags.Parse(os.Args[2:])
+	storageDir := listFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	listFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	failedOnly := listFlags.Bool("failed", false, "List only runs that terminated abnormally, with how they terminated")
+	annotations := listFlags.Bool("annotations", false, "List the annotations of the runs below the table")
+	times := addTimeFlags(listFlags, "default")
+	cfg, err := parseFlags(listFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
 
 	store := storage.NewStorage(*storageDir)
 	runs, err := store.List()
@@ -21,25 +37,119 @@ func List() error {
 		return fmt.Errorf("failed to list results: %w", err)
 	}
 
+	if *failedOnly {
+		return listFailed(runs, timeFormat)
+	}
 	if len(runs) == 0 {
 		fmt.Println("No benchmark results found.")
 		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tTimestamp\tBenchmarks\tDuration\tPackage")
-	fmt.Fprintln(w, "--\t---------\t----------\t--------\t-------")
+	fmt.Fprintln(w, "ID\tTimestamp\tBenchmarks\tScore\tGrade\tDuration\tPackage")
+	fmt.Fprintln(w, "--\t---------\t----------\t-----\t-----\t--------\t-------")
 
 	for _, run := range runs {
-		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+		benchmarks := fmt.Sprintf("%d", len(run.Results))
+		if run.Failed() {
+			benchmarks += " (run failed)"
+		} else if run.Degraded() {
+			benchmarks += " (environment degraded)"
+		} else if failed := run.CountStatus(models.StatusFailed); failed > 0 {
+			benchmarks += fmt.Sprintf(" (%d failed)", failed)
+		}
+		score := "-"
+		if v, ok := stats.Score(&run, cfg.Score.Weights); ok {
+			score = stats.FormatScore(v)
+		}
+		pkg := run.Package
+		if run.Agent != "" {
+			pkg += fmt.Sprintf(" (on %s)", run.Agent)
+		}
+		grade := stats.RunConfidence(&run)
+		if grade == "" {
+			grade = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			run.ID,
-			run.Timestamp.Format("2006-01-02 15:04:05"),
-			len(run.Results),
+			timeFormat.Format(run.Timestamp),
+			benchmarks,
+			score,
+			grade,
 			run.Duration,
-			run.Package,
+			pkg,
 		)
 	}
 	w.Flush()
 
+	// Off by default: scripts read the last lines of the table
+	if *annotations {
+		printAnnotations(runs)
+	}
 	return nil
 }
+
+// printAnnotations lists the annotations of the runs below the table, as a
+// line between rows would break its columns
+func printAnnotations(runs []models.BenchmarkRun) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	found := false
+	for _, run := range runs {
+		for _, annotation := range run.Annotations {
+			if !found {
+				ui.PrintSection("📝", "Annotations")
+				found = true
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", run.ID, annotation)
+		}
+	}
+	w.Flush()
+}
+
+// listFailed lists the runs that terminated abnormally with their error
+func listFailed(runs []models.BenchmarkRun, timeFormat *ui.TimeFormat) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	found := false
+	for _, run := range runs {
+		if !run.Failed() {
+			continue
+		}
+		if !found {
+			fmt.Fprintln(w, "ID\tTimestamp\tBenchmarks\tError")
+			fmt.Fprintln(w, "--\t---------\t----------\t-----")
+			found = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", run.ID, timeFormat.Format(run.Timestamp), len(run.Results), run.Error)
+	}
+	if !found {
+		fmt.Println("No failed runs found.")
+		return nil
+	}
+	return w.Flush()
+}
+
+// timeFlags are the -time-format and -tz options of commands that display
+// timestamps
+type timeFlags struct {
+	format *string
+	tz     *string
+}
+
+// addTimeFlags registers the time display options on a command
+func addTimeFlags(fs *flag.FlagSet, defaultFormat string) *timeFlags {
+	return &timeFlags{
+		format: fs.String("time-format", defaultFormat, "Timestamp format: default, datetime, date, rfc3339, rfc1123, kitchen, unix, relative or a Go layout"),
+		tz:     fs.String("tz", "", "Time zone for timestamps: local, UTC or an IANA name (default: as recorded)"),
+	}
+}
+
+// parse returns the time format selected by the options
+func (f *timeFlags) parse() (*ui.TimeFormat, error) {
+	timeFormat, err := ui.ParseTimeFormat(*f.format, *f.tz)
+	if err != nil {
+		return nil, ui.NewError("Invalid time display option", err,
+			"Example: -time-format=rfc3339 -tz=local",
+			"Example: -time-format='02 Jan 15:04' -tz=Europe/Berlin")
+	}
+	return timeFormat, nil
+}
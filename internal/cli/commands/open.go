@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"flag"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// serverStartTimeout bounds how long -open waits for a server to listen
+const serverStartTimeout = 10 * time.Second
+
+// openFlags are the -open and -no-open options of commands whose result is
+// viewed in a browser
+type openFlags struct {
+	open   *bool
+	noOpen *bool
+}
+
+// addOpenFlags registers the browser options on a command
+func addOpenFlags(fs *flag.FlagSet, what string) *openFlags {
+	return &openFlags{
+		open:   fs.Bool("open", false, "Open the "+what+" in the browser (default: defaults.open from the config; never in CI)"),
+		noOpen: fs.Bool("no-open", false, "Do not open the "+what+", even when defaults.open is set"),
+	}
+}
+
+// enabled reports whether the result should be opened. Nobody would see a
+// browser started in CI, so it never is there.
+func (f *openFlags) enabled() bool {
+	return *f.open && !*f.noOpen && !ui.InCI()
+}
+
+// openFile opens a written file when enabled. Failures are only warned
+// about, since the file was written anyway.
+func (f *openFlags) openFile(path string) {
+	if !f.enabled() {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if err := ui.OpenBrowser(path); err != nil {
+		ui.PrintWarning("%v", err)
+	}
+}
+
+// openWhenListening opens url once a server accepts connections on addr,
+// in the background since starting the server blocks
+func (f *openFlags) openWhenListening(addr, url string) {
+	if !f.enabled() {
+		return
+	}
+	go func() {
+		deadline := time.Now().Add(serverStartTimeout)
+		for time.Now().Before(deadline) {
+			conn, err := net.DialTimeout("tcp", addr, time.Second)
+			if err == nil {
+				conn.Close()
+				if err := ui.OpenBrowser(url); err != nil {
+					ui.PrintWarning("%v", err)
+				}
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/plugin"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Plugins handles the 'plugins' subcommand, listing the plugin commands
+// found on PATH
+func Plugins() error {
+	pluginsFlags := flag.NewFlagSet("plugins", flag.ExitOnError)
+	jsonOutput := pluginsFlags.Bool("json", false, "Print the plugins as JSON")
+	pluginsFlags.Parse(os.Args[2:])
+
+	plugins, err := plugin.List()
+	if err != nil {
+		return err
+	}
+
+	if *jsonOutput {
+		if plugins == nil {
+			plugins = []plugin.Plugin{}
+		}
+		data, err := json.MarshalIndent(plugins, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(plugins) == 0 {
+		ui.PrintInfo("No plugins found; install a %s<name> executable on your PATH to add 'gokanon <name>'", plugin.Prefix)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range plugins {
+		fmt.Fprintf(w, "%s\t%s\n", ui.Bold(p.Name), ui.Dim(p.Path))
+	}
+	return w.Flush()
+}
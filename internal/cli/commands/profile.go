@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/profiler"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+	"github.com/alenon/gokanon/internal/webserver"
+)
+
+// Profile handles the 'profile' subcommand, running a single benchmark for
+// a fixed time with CPU and memory profiling, or comparing the profiles of
+// two saved runs with 'profile compare'
+func Profile() error {
+	usage := "usage: gokanon profile <benchmark> [-duration=30s] [-pkg=<package>] [-web]\n" +
+		"       gokanon profile compare <old-run> <new-run> [-type=cpu]"
+
+	// Benchmark names never start with a lowercase letter
+	if len(os.Args) > 2 && os.Args[2] == "compare" {
+		return profileCompare(os.Args[3:])
+	}
+
+	// The benchmark comes first, as in 'gokanon profile BenchmarkHot -duration 30s'
+	var benchmark string
+	args := os.Args[2:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		benchmark, args = args[0], args[1:]
+	}
+
+	profileFlags := flag.NewFlagSet("profile", flag.ExitOnError)
+	duration := profileFlags.Duration("duration", 30*time.Second, "How long to run the benchmark")
+	packagePath := profileFlags.String("pkg", ".", "Package defining the benchmark")
+	storageDir := profileFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	cpuSampleType := profileFlags.String("cpu-sample-type", "", "Sample type to analyze in the CPU profile (e.g. cpu)")
+	memSampleType := profileFlags.String("mem-sample-type", "", "Sample type to analyze in the memory profile (default: alloc_space)")
+	gcflags := profileFlags.String("gcflags", "", "Compiler flags (passed to -gcflags and recorded with the run)")
+	web := profileFlags.Bool("web", false, "Open the flame graph viewer on the profiles afterwards")
+	port := profileFlags.String("port", "8080", "Port of the flame graph viewer")
+	wait := profileFlags.Bool("wait", false, "Wait for another run using the same storage to finish instead of failing")
+	profileFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	cfg, err := parseFlags(profileFlags, args)
+	if err != nil {
+		return err
+	}
+
+	if benchmark == "" {
+		if profileFlags.NArg() != 1 {
+			return fmt.Errorf("%s", usage)
+		}
+		benchmark = profileFlags.Arg(0)
+	}
+	name := strings.TrimPrefix(benchmark, "Benchmark")
+	if name == "" || strings.Contains(name, "//") || strings.HasSuffix(name, "/") {
+		return ui.NewError(fmt.Sprintf("Invalid benchmark name: %q", benchmark), nil,
+			"Give one benchmark, e.g. BenchmarkParse or Parse/small")
+	}
+	if *duration <= 0 {
+		return ui.NewError(fmt.Sprintf("Invalid -duration: %s", *duration), nil, "Use a positive duration, e.g. -duration=30s")
+	}
+
+	store := storage.NewStorage(*storageDir)
+	lock, err := acquireRunLock(store, *wait)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ui.PrintHeader("Profiling " + benchmark)
+	fmt.Println()
+
+	r := runner.NewRunner(*packagePath, benchmarkFilter(name)).
+		WithContext(ctx).
+		WithBenchtime(duration.String()).
+		WithoutTests().
+		WithLiveStatus(store).
+		WithProfiling(&runner.ProfileOptions{
+			EnableCPU:        true,
+			EnableMemory:     true,
+			Storage:          store,
+			CPUSampleType:    *cpuSampleType,
+			MemorySampleType: *memSampleType,
+		}).
+		WithAIDefaults(aiDefaults(cfg))
+	if *gcflags != "" {
+		r = r.WithGCFlags(*gcflags)
+	}
+
+	spinner := ui.NewSpinner(fmt.Sprintf("Running %s for %s with CPU and memory profiling", benchmark, duration))
+	spinner.Start()
+	run, err := r.Run()
+	spinner.Stop()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ui.NewError("Profiling interrupted, no results were saved", err)
+		}
+		return ui.ErrBenchmarkFailed(err)
+	}
+	if len(run.Results) == 0 {
+		// The profiles of an empty run were saved for nothing
+		os.RemoveAll(store.GetProfileDir(run.ID))
+		return ui.NewError(fmt.Sprintf("No benchmark matched %s in %s", benchmark, *packagePath), nil,
+			"Check the benchmark name with: go test -list 'Benchmark.*' "+*packagePath,
+			"Use -pkg to select the package defining it")
+	}
+
+	run.Focus = benchmark
+	if err := saveAndReport(store, *storageDir, run); err != nil {
+		return err
+	}
+
+	if !*web {
+		return nil
+	}
+	fmt.Println()
+	return webserver.NewServer(store, *port).Start(run.ID)
+}
+
+// profileCompare diffs the stored profiles of two runs by function,
+// whatever their benchmark results
+func profileCompare(args []string) error {
+	usage := "usage: gokanon profile compare <old-run> <new-run> [-type=cpu]"
+
+	// The runs come first, as in 'gokanon profile compare run-1 run-2 -type cpu'
+	var runIDs []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		runIDs, args = append(runIDs, args[0]), args[1:]
+	}
+
+	compareFlags := flag.NewFlagSet("profile-compare", flag.ExitOnError)
+	profileType := compareFlags.String("type", "cpu", "Profile to compare: cpu, mem, block, mutex or warmup")
+	sampleType := compareFlags.String("sample-type", "", "Sample type to compare (default: the profile's own, alloc_space for mem, delay for block and mutex)")
+	top := compareFlags.Int("top", 10, "Number of functions with the largest changes to show, 0 for all")
+	format := compareFlags.String("format", "table", "Output format: table, json")
+	storageDir := compareFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	compareFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	if _, err := parseFlags(compareFlags, args); err != nil {
+		return err
+	}
+	runIDs = append(runIDs, compareFlags.Args()...)
+	if len(runIDs) != 2 {
+		return fmt.Errorf("%s", usage)
+	}
+	if *top < 0 {
+		return ui.NewError(fmt.Sprintf("Invalid -top: %d", *top), nil, "Use a positive number, or 0 to show every function")
+	}
+
+	kind := *profileType
+	if kind == "memory" {
+		kind = "mem"
+	}
+	samples := *sampleType
+	switch kind {
+	case "cpu", "warmup":
+	case "mem":
+		if samples == "" {
+			samples = "alloc_space"
+		}
+	case "block", "mutex":
+		if samples == "" {
+			samples = "delay"
+		}
+	default:
+		return ui.NewError(fmt.Sprintf("Unknown profile type: %s", *profileType), nil,
+			"Use -type=cpu, mem, block, mutex or warmup")
+	}
+
+	store := storage.NewStorage(*storageDir)
+	var runs [2]*models.BenchmarkRun
+	var data [2][]byte
+	for i, id := range runIDs {
+		run, err := store.Resolve(id)
+		if err != nil {
+			return ui.NewError(fmt.Sprintf("Failed to load run %s", id), err, "List the saved runs with: gokanon list")
+		}
+		profile, err := store.LoadProfile(run.ID, kind)
+		if errors.Is(err, storage.ErrProfileMissing) {
+			return ui.NewError(fmt.Sprintf("Run %s has no %s profile", run.ID, kind), err,
+				fmt.Sprintf("Profile runs with: gokanon run -profile=%s", kind))
+		}
+		if err != nil {
+			return err
+		}
+		runs[i], data[i] = run, profile
+	}
+
+	diff, err := profiler.DiffProfiles(data[0], data[1], samples, *top)
+	if err != nil {
+		return ui.NewError("Failed to compare the profiles", err,
+			"Choose a sample type both profiles have with -sample-type")
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	case "table":
+		printProfileDiff(kind, runs[0], runs[1], diff)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s (supported: table, json)", *format)
+	}
+}
+
+// printProfileDiff prints the functions whose share of the profile changed
+// most, followed by the new hot functions
+func printProfileDiff(kind string, oldRun, newRun *models.BenchmarkRun, diff *profiler.ProfileDiff) {
+	ui.PrintHeader(fmt.Sprintf("%s profile: %s → %s", strings.ToUpper(kind), oldRun.ID, newRun.ID))
+	fmt.Printf("%s %s: %s → %s\n\n", ui.Bold("Total"), diff.SampleType,
+		profiler.FormatValue(diff.OldTotal, diff.Unit), profiler.FormatValue(diff.NewTotal, diff.Unit))
+
+	if len(diff.Functions) == 0 {
+		ui.PrintInfo("No function's share of the profile changed")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Function\tOld flat\tNew flat\tDelta\tOld cum\tNew cum")
+		fmt.Fprintln(w, "--------\t--------\t--------\t-----\t-------\t-------")
+		for _, f := range diff.Functions {
+			delta := fmt.Sprintf("%+.2f pts", f.FlatDelta())
+			if f.FlatDelta() > 0 {
+				delta = ui.Error(delta)
+			} else {
+				delta = ui.Success(delta)
+			}
+			fmt.Fprintf(w, "%s\t%.2f%%\t%.2f%%\t%s\t%.2f%%\t%.2f%%\n",
+				f.Name, f.OldFlat, f.NewFlat, delta, f.OldCum, f.NewCum)
+		}
+		w.Flush()
+	}
+
+	if len(diff.NewHot) > 0 {
+		ui.PrintSection("🔥", "New hot functions")
+		for _, f := range diff.NewHot {
+			fmt.Printf("  %s: %.2f%% flat, %.2f%% cum (%s)\n", f.Name, f.NewFlat, f.NewCum, profiler.FormatValue(f.NewValue, diff.Unit))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Projects handles the 'projects' subcommand, listing the projects in the registry
+func Projects() error {
+	projectsFlags := flag.NewFlagSet("projects", flag.ExitOnError)
+	prune := projectsFlags.Bool("prune", false, "Forget projects whose directory no longer exists")
+	projectsFlags.Parse(os.Args[2:])
+
+	path := config.RegistryPath()
+	if path == "" {
+		return ui.NewError("No user data directory for the project registry", nil,
+			"Set $XDG_DATA_HOME or $HOME")
+	}
+	registry, err := config.LoadRegistry(path)
+	if err != nil {
+		return err
+	}
+
+	if *prune {
+		var removed []string
+		for _, p := range append([]config.Project(nil), registry.Projects...) {
+			if _, err := os.Stat(p.Root); os.IsNotExist(err) {
+				registry.Remove(p.Root)
+				removed = append(removed, p.Root)
+			}
+		}
+		if len(removed) > 0 {
+			if err := registry.Save(); err != nil {
+				return err
+			}
+		}
+		for _, root := range removed {
+			fmt.Printf("Forgot %s\n", root)
+		}
+		ui.PrintSuccess("Pruned %d project(s)", len(removed))
+		fmt.Println()
+	}
+
+	if len(registry.Projects) == 0 {
+		ui.PrintInfo("No projects tracked yet")
+		fmt.Println("Projects are registered on their first 'gokanon run'.")
+		return nil
+	}
+
+	ui.PrintHeader("Tracked Projects")
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Project\tRuns\tLast Used\tRoot\tStorage")
+	fmt.Fprintln(w, "-------\t----\t---------\t----\t-------")
+	for _, p := range registry.Projects {
+		runs := "-"
+		if list, err := storage.NewStorage(p.Storage).Index(); err == nil {
+			runs = fmt.Sprintf("%d", len(list))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			p.Name(),
+			runs,
+			p.LastUsed.Format("2006-01-02 15:04:05"),
+			p.Root,
+			p.Storage,
+		)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d project(s) in %s\n", len(registry.Projects), ui.Dim(path))
+	return nil
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Prune handles the 'prune' subcommand, deleting old runs and profiles
+// according to a retention policy
+func Prune() error {
+	pruneFlags := flag.NewFlagSet("prune", flag.ExitOnError)
+	storageDir := pruneFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	keepLast := pruneFlags.Int("keep-last", 0, "Never delete the N newest runs; alone, delete all older ones")
+	olderThan := pruneFlags.String("older-than", "", "Delete runs older than this age, e.g. 90d, 2w or 36h")
+	profilesOlderThan := pruneFlags.String("profiles-older-than", "", "Delete the profiles of runs older than this age, keeping their results")
+	keepBaselines := pruneFlags.Bool("keep-baselines", true, "Keep the runs baselines point at, with their profiles")
+	dryRun := pruneFlags.Bool("dry-run", false, "Show what would be deleted without deleting anything")
+	wait := pruneFlags.Bool("wait", false, "Wait for a run in progress to finish instead of failing")
+	compressFiles := pruneFlags.Bool("compress", false, "Compress run records and profiles saved uncompressed by older versions")
+	pruneFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	cfg, err := parseFlags(pruneFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	// Flags override the configured policy one by one
+	retention := cfg.Retention
+	pruneFlags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "keep-last":
+			retention.KeepLast = *keepLast
+		case "older-than":
+			retention.OlderThan = *olderThan
+		case "profiles-older-than":
+			retention.ProfilesOlderThan = *profilesOlderThan
+		case "keep-baselines":
+			retention.KeepBaselines = keepBaselines
+		}
+	})
+	if retention.KeepLast < 0 {
+		return ui.NewError(fmt.Sprintf("Invalid -keep-last: %d", retention.KeepLast), nil, "Keep a positive number of runs, e.g. -keep-last=100")
+	}
+	if !retention.Prunes() && !*compressFiles {
+		return ui.NewError("Nothing to prune", nil,
+			"Delete runs beyond the newest 100 or older than 90 days: gokanon prune -keep-last=100 -older-than=90d",
+			"Delete the profiles of old runs, keeping their results: gokanon prune -profiles-older-than=30d",
+			"Compress the files of older versions: gokanon prune -compress",
+			"Or set a retention policy in "+config.DefaultFile)
+	}
+	policy, err := retentionPolicy(retention)
+	if err != nil {
+		return err
+	}
+
+	store := storage.NewStorage(*storageDir)
+	if !*dryRun {
+		// A run saved while pruning could be counted as one of the newest
+		lock, err := acquireRunLock(store, *wait)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+
+	if retention.Prunes() {
+		if err := prune(store, policy, *dryRun); err != nil {
+			return err
+		}
+	}
+	if !*compressFiles {
+		return nil
+	}
+	if *dryRun {
+		ui.PrintInfo("Would compress the run records and profiles saved uncompressed")
+		return nil
+	}
+	stats, err := store.Compress()
+	if err != nil {
+		return ui.NewError("Compression stopped", err, "Run gokanon prune -compress again to continue")
+	}
+	if stats.Runs == 0 && stats.Profiles == 0 {
+		ui.PrintInfo("Every run record and profile is compressed already")
+	} else {
+		ui.PrintSuccess("Compressed %d run record(s) and %d profile(s) from %s to %s",
+			stats.Runs, stats.Profiles, formatSize(stats.Before), formatSize(stats.After))
+	}
+	return nil
+}
+
+// prune deletes what the retention policy selects and lists it
+func prune(store *storage.Storage, policy storage.RetentionPolicy, dryRun bool) error {
+	pruned, err := store.Prune(policy, time.Now(), dryRun)
+	for _, run := range pruned {
+		what := "run"
+		if run.ProfilesOnly {
+			what = "profiles"
+		}
+		fmt.Printf("%s  %s  %-8s  %s\n", run.ID, run.Timestamp.Format("2006-01-02"), what, formatSize(run.Bytes))
+	}
+	if err != nil {
+		return ui.NewError("Pruning stopped", err, "The runs listed above were pruned; run gokanon prune again to continue")
+	}
+
+	runs, profiles, freed := pruneCounts(pruned)
+	switch {
+	case len(pruned) == 0:
+		ui.PrintInfo("Nothing matches the retention policy")
+	case dryRun:
+		ui.PrintInfo("Would delete %d run(s) and the profiles of %d more, freeing %s", runs, profiles, formatSize(freed))
+	default:
+		ui.PrintSuccess("Deleted %d run(s) and the profiles of %d more, freeing %s", runs, profiles, formatSize(freed))
+	}
+	return nil
+}
+
+// autoPrune applies the configured retention policy after a run was saved,
+// when auto_prune is set. The caller holds the run lock. Failing to prune
+// does not fail the run.
+func autoPrune(store *storage.Storage, retention config.Retention) {
+	if !retention.AutoPrune {
+		return
+	}
+	policy, err := retentionPolicy(retention)
+	if err != nil {
+		ui.PrintWarning("Failed to apply the retention policy: %v", err)
+		return
+	}
+	pruned, err := store.Prune(policy, time.Now(), false)
+	if err != nil {
+		ui.PrintWarning("Failed to apply the retention policy: %v", err)
+	}
+	if runs, profiles, freed := pruneCounts(pruned); len(pruned) > 0 {
+		ui.PrintInfo("Retention policy: deleted %d run(s) and the profiles of %d more, freeing %s", runs, profiles, formatSize(freed))
+	}
+}
+
+// retentionPolicy converts a configured retention to the policy storage applies
+func retentionPolicy(retention config.Retention) (storage.RetentionPolicy, error) {
+	policy := storage.RetentionPolicy{KeepLast: retention.KeepLast, KeepBaselines: retention.BaselinesKept()}
+	for _, age := range []struct {
+		flag  string
+		value string
+		dst   *time.Duration
+	}{
+		{"older-than", retention.OlderThan, &policy.OlderThan},
+		{"profiles-older-than", retention.ProfilesOlderThan, &policy.ProfilesOlderThan},
+	} {
+		if age.value == "" {
+			continue
+		}
+		d, err := config.ParseAge(age.value)
+		if err != nil {
+			return policy, ui.NewError(fmt.Sprintf("Invalid -%s: %s", age.flag, age.value), err,
+				"Use a number of days or weeks, e.g. 90d or 2w, or a Go duration such as 36h")
+		}
+		*age.dst = d
+	}
+	return policy, nil
+}
+
+// pruneCounts counts the deleted runs, the runs that only lost their
+// profiles and the bytes freed
+func pruneCounts(pruned []storage.PrunedRun) (runs, profiles int, freed int64) {
+	for _, run := range pruned {
+		if run.ProfilesOnly {
+			profiles++
+		} else {
+			runs++
+		}
+		freed += run.Bytes
+	}
+	return runs, profiles, freed
+}
+
+// formatSize formats a number of bytes on disk, e.g. "12.4 MB"
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
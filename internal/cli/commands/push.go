@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/perfdata"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Push handles the 'push' subcommand, uploading runs to a perf storage
+// server such as perfdata.golang.org
+func Push() error {
+	pushFlags := flag.NewFlagSet("push", flag.ExitOnError)
+	server := pushFlags.String("perfdata", os.Getenv("GOKANON_PERFDATA"), "URL of the perf data server (default: $GOKANON_PERFDATA or remote.perfdata in config)")
+	token := pushFlags.String("token", os.Getenv("GOKANON_PERFDATA_TOKEN"), "Bearer token sent with uploads (default: $GOKANON_PERFDATA_TOKEN)")
+	all := pushFlags.Bool("all", false, "Upload every run not uploaded to the server yet")
+	force := pushFlags.Bool("force", false, "Upload runs again even if they were uploaded before")
+	dryRun := pushFlags.Bool("dry-run", false, "Print the data that would be uploaded instead of uploading it")
+	storageDir := pushFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	pushFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	cfg, err := parseFlags(pushFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	if *server == "" {
+		*server = cfg.Remote.PerfData
+	}
+	if *server == "" && !*dryRun {
+		return ui.NewError("No perf data server configured", nil,
+			"Use -perfdata=https://perf.example.com",
+			"Or set remote.perfdata in "+config.DefaultFile)
+	}
+	if *all && pushFlags.NArg() > 0 {
+		return fmt.Errorf("usage: gokanon push -perfdata=<url> [-all | <run>...]")
+	}
+
+	store := storage.NewStorage(*storageDir)
+	runs, err := pushRuns(store, pushFlags.Args(), *all)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		for _, run := range runs {
+			if err := perfdata.Write(os.Stdout, run); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	client, err := perfdata.NewClient(*server, *token)
+	if err != nil {
+		return ui.NewError("Invalid perf data server", err, "Use an http or https URL, e.g. https://perf.example.com")
+	}
+
+	uploaded, skipped := 0, 0
+	for _, run := range runs {
+		if id, ok := run.PerfUploads[client.URL()]; ok && !*force {
+			if !*all {
+				ui.PrintInfo("%s was uploaded already as upload %s (use -force to upload it again)", run.ID, id)
+			}
+			skipped++
+			continue
+		}
+
+		spinner := ui.NewSpinner(fmt.Sprintf("Uploading %s to %s", run.ID, client.URL()))
+		spinner.Start()
+		status, err := client.Upload(run)
+		spinner.Stop()
+		if err != nil {
+			return ui.NewError(fmt.Sprintf("Failed to upload %s", run.ID), err,
+				"Check the server URL and token",
+				"Runs uploaded before the failure are recorded; run the command again to resume")
+		}
+
+		if run.PerfUploads == nil {
+			run.PerfUploads = make(map[string]string)
+		}
+		run.PerfUploads[client.URL()] = status.UploadID
+		if err := store.SaveMetadata(run); err != nil {
+			return fmt.Errorf("failed to record upload of %s: %w", run.ID, err)
+		}
+		uploaded++
+
+		ui.PrintSuccess("Uploaded %s as upload %s", run.ID, ui.Bold(status.UploadID))
+		if status.ViewURL != "" {
+			fmt.Printf("  %s\n", status.ViewURL)
+		}
+	}
+
+	if *all {
+		ui.PrintInfo("%d run(s) uploaded, %d uploaded before", uploaded, skipped)
+	}
+	return nil
+}
+
+// pushRuns resolves the runs to push: the given references, every run
+// that did not terminate abnormally from the oldest with all, or the
+// latest run
+func pushRuns(store *storage.Storage, refs []string, all bool) ([]*models.BenchmarkRun, error) {
+	if all {
+		list, err := store.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runs: %w", err)
+		}
+		runs := make([]*models.BenchmarkRun, 0, len(list))
+		for i := len(list) - 1; i >= 0; i-- {
+			if !list[i].Failed() {
+				runs = append(runs, &list[i])
+			}
+		}
+		if len(runs) == 0 {
+			return nil, ui.ErrNoResults()
+		}
+		return runs, nil
+	}
+
+	if len(refs) == 0 {
+		refs = []string{"latest"}
+	}
+	runs := make([]*models.BenchmarkRun, 0, len(refs))
+	for _, ref := range refs {
+		run, err := store.Resolve(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
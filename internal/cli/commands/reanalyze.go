@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/aianalyzer"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Reanalyze handles the 'reanalyze' subcommand, running the profile
+// analyzer again over the stored profiles of saved runs so that their
+// summaries and suggestions benefit from improvements to the analysis
+// without rerunning the benchmarks
+func Reanalyze() error {
+	reanalyzeFlags := flag.NewFlagSet("reanalyze", flag.ExitOnError)
+	storageDir := reanalyzeFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	all := reanalyzeFlags.Bool("all", false, "Re-analyze every saved run with stored profiles")
+	reanalyzeFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	cfg, err := parseFlags(reanalyzeFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	args := reanalyzeFlags.Args()
+	if *all == (len(args) > 0) {
+		return fmt.Errorf("usage: gokanon reanalyze -all OR gokanon reanalyze <run>...")
+	}
+
+	store := storage.NewStorage(*storageDir)
+	var runs []*models.BenchmarkRun
+	if *all {
+		list, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list runs: %w", err)
+		}
+		for i := range list {
+			runs = append(runs, &list[i])
+		}
+	} else {
+		for _, ref := range args {
+			run, err := store.Resolve(ref)
+			if err != nil {
+				return fmt.Errorf("failed to load run: %w", err)
+			}
+			runs = append(runs, run)
+		}
+	}
+
+	// Suggestions are enhanced by the AI provider when enabled, as for new runs
+	aiAnalyzer, err := aianalyzer.NewAnalyzer(aianalyzer.ConfigFromEnv(aiDefaults(cfg)))
+	if err != nil {
+		ui.PrintWarning("Failed to initialize AI analyzer: %v", err)
+		aiAnalyzer = nil
+	}
+
+	ui.PrintHeader("Profile Re-analysis")
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\n", ui.Bold("Run"), ui.Bold("Suggestions"), ui.Bold("Hot functions"))
+	reanalyzed, skipped := 0, 0
+	var failures []string
+	for _, run := range runs {
+		before := run.ProfileSummary
+		analyzed, err := analyzeStoredProfiles(store, run)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", run.ID, err))
+			continue
+		}
+		if !analyzed {
+			skipped++
+			continue
+		}
+		if aiAnalyzer != nil {
+			enhanced, err := aiAnalyzer.WithCache(store, false).EnhanceRunProfile(run.ID, run.ProfileSummary)
+			if err != nil {
+				ui.PrintWarning("%s: AI analysis failed: %v", run.ID, err)
+			} else {
+				run.ProfileSummary = enhanced
+			}
+		}
+		// Saved as a whole: the contention per operation of the results may change
+		if err := store.Save(run); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: failed to save run: %v", run.ID, err))
+			continue
+		}
+		reanalyzed++
+		fmt.Fprintf(w, "%s\t%s\t%s\n", run.ID,
+			summaryChange(before, run.ProfileSummary, func(s *models.ProfileSummary) int { return len(s.Suggestions) }),
+			summaryChange(before, run.ProfileSummary, func(s *models.ProfileSummary) int { return len(s.CPUTopFunctions) }))
+	}
+	if reanalyzed > 0 {
+		w.Flush()
+		fmt.Println()
+	}
+
+	for _, failure := range failures {
+		ui.PrintError("%s", failure)
+	}
+	if skipped > 0 {
+		ui.PrintInfo("Skipped %d run(s) without stored profiles", skipped)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to re-analyze %d run(s)", len(failures))
+	}
+	if reanalyzed == 0 {
+		return ui.NewError("No stored profiles to re-analyze", nil,
+			"Record profiles with: gokanon run -profile=cpu,mem",
+			"Profiles deleted by prune cannot be re-analyzed")
+	}
+	ui.PrintSuccess("Re-analyzed the profiles of %d run(s)", reanalyzed)
+	return nil
+}
+
+// summaryChange formats a count of two profile summaries, before and after
+// re-analysis, as "before → after", or the count alone when unchanged
+func summaryChange(before, after *models.ProfileSummary, count func(*models.ProfileSummary) int) string {
+	n := count(after)
+	if before == nil {
+		return fmt.Sprintf("- → %d", n)
+	}
+	if m := count(before); m != n {
+		return fmt.Sprintf("%d → %d", m, n)
+	}
+	return fmt.Sprint(n)
+}
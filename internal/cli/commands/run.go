@@ -1,15 +1,29 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/alenon/gokanon/internal/agent"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/dashboard"
+	"github.com/alenon/gokanon/internal/doctor"
+	"github.com/alenon/gokanon/internal/loadtest"
 	"github.com/alenon/gokanon/internal/models"
 	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/sink"
+	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
 	"github.com/alenon/gokanon/internal/ui"
 )
@@ -18,23 +32,159 @@ import (
 func Run() error {
 	runFlags := flag.NewFlagSet("run", flag.ExitOnError)
 	benchFilter := runFlags.String("bench", ".", "Benchmark filter (passed to -bench)")
-	packagePath := runFlags.String("pkg", "", "Package path (default: current directory)")
-	storageDir := runFlags.String("storage", ".gokanon", "Storage directory for results")
-	profileFlag := runFlags.String("profile", "", "Enable profiling: cpu, mem, or cpu,mem")
+	packagePath := runFlags.String("pkg", "", "Package path or pattern such as ./... (default: ./...)")
+	parallelPackages := runFlags.Int("parallel-packages", 1, "Benchmark up to n packages of a pattern at once (concurrent benchmarks disturb each other's timings)")
+	storageDir := runFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	profileFlag := runFlags.String("profile", "", "Enable profiling: a comma-separated list of cpu, mem, block and mutex")
+	cpuSampleType := runFlags.String("cpu-sample-type", "", "Sample type to analyze in the CPU profile (e.g. cpu)")
+	memSampleType := runFlags.String("mem-sample-type", "", "Sample type to analyze in the memory profile (default: alloc_space)")
 	verbose := runFlags.Bool("verbose", false, "Show detailed benchmark output")
-	cpuFlag := runFlags.String("cpu", "", "CPU list (passed to -cpu)")
+	cpuFlag := runFlags.String("cpu", "", "GOMAXPROCS values (passed to -cpu); a list such as 1,2,4,8 records a scalability curve per benchmark")
 	benchtimeFlag := runFlags.String("benchtime", "", "Benchmark time (passed to -benchtime)")
-	runFlags.Parse(os.Args[2:])
+	count := runFlags.Int("count", 1, "Run each benchmark n times and compare the samples statistically")
+	stress := runFlags.Bool("stress", false, "Measure the benchmarks calling RunParallel at each -parallelism level and report their scaling")
+	parallelism := runFlags.String("parallelism", "1,4,16,64", "Comma-separated RunParallel goroutine counts for -stress (passed to -cpu)")
+	startup := runFlags.String("startup", "", "Measure the startup time of this main package, e.g. ./cmd/server, instead of running benchmarks")
+	startupArgs := runFlags.String("startup-args", "", "Space-separated arguments of the processes started by -startup")
+	startupReady := runFlags.String("startup-ready", "exit", "When a process of -startup is ready: exit, an http(s) URL answering 2xx, tcp://host:port or output:<regexp>")
+	startupIterations := runFlags.Int("startup-iterations", runner.DefaultStartupIterations, "Processes started one after the other by -startup")
+	startupTimeout := runFlags.Duration("startup-timeout", runner.DefaultStartupTimeout, "Longest a process of -startup may take to become ready")
+	load := runFlags.String("load", "", "Send HTTP load to this URL, e.g. http://localhost:8080/api, instead of running benchmarks")
+	loadName := runFlags.String("load-name", "", "Name of the -load result after \"Load/\" (default: host and path of the URL)")
+	loadDuration := runFlags.Duration("load-duration", loadtest.DefaultDuration, "How long -load sends requests")
+	loadRate := runFlags.Float64("load-rate", 0, "Requests per second of -load (default: as fast as -load-concurrency allows)")
+	loadConcurrency := runFlags.Int("load-concurrency", loadtest.DefaultConcurrency, "Requests of -load in flight at most")
+	loadMethod := runFlags.String("load-method", "GET", "HTTP method of -load")
+	loadBody := runFlags.String("load-body", "", "Request body of -load, or @file to read it from a file")
+	var loadHeaders []string
+	runFlags.Func("load-header", "Request header of -load as \"Name: value\"; repeatable", func(spec string) error {
+		loadHeaders = append(loadHeaders, spec)
+		return nil
+	})
+	gcflags := runFlags.String("gcflags", "", "Compiler flags (passed to -gcflags and recorded with the run)")
+	tags := runFlags.String("tags", "", "Tag the run for search, e.g. branch=main,env=ci")
+	note := runFlags.String("note", "", "Note recorded with the run for search, e.g. \"after upgrading the JSON library\"")
+	hooks := runFlags.Bool("hooks", true, "Run the GokanonSetup and GokanonTeardown hooks declared by benchmark packages")
+	wait := runFlags.Bool("wait", false, "Wait for another run using the same storage to finish instead of failing")
+	skipIfUnstable := runFlags.Bool("skip-if-unstable", false, "Skip the benchmarks on a busy machine or with CPU frequency scaling, and flag runs throttled by heat, recording them as environment-degraded so that trends and checks ignore them")
+	runFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	on := runFlags.String("on", "", "Run on a remote agent with these labels (e.g. cpu=epyc,os=linux)")
+	controller := runFlags.String("controller", os.Getenv("GOKANON_CONTROLLER"), "Controller URL for -on (default: $GOKANON_CONTROLLER)")
+	token := runFlags.String("token", os.Getenv("GOKANON_DASHBOARD_TOKEN"), "Controller access token for -on (default: $GOKANON_DASHBOARD_TOKEN)")
+	var sinkSpecs []string
+	runFlags.Func("sink", "Send results to "+strings.Join(sink.Kinds, ", ")+"; repeatable (default: storage)", func(spec string) error {
+		sinkSpecs = append(sinkSpecs, spec)
+		return nil
+	})
+	cfg, err := parseFlags(runFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	extractors, err := metricExtractors(cfg)
+	if err != nil {
+		return err
+	}
+	runTags, err := agent.ParseLabels(*tags)
+	if err != nil {
+		return ui.NewError("Invalid -tags", err, "Use comma-separated key=value pairs, e.g. -tags=branch=main,env=ci")
+	}
+
+	store := storage.NewStorage(*storageDir)
+	defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+	sinks, err := openSinks(sinkSpecs, store)
+	if err != nil {
+		return err
+	}
+	if *profileFlag != "" && !slices.ContainsFunc(sinks, isStorageSink) {
+		return ui.NewError("-profile needs the storage sink", nil,
+			"Profiles are kept in the storage with the run: add -sink=storage")
+	}
 
 	ui.PrintHeader("Running Benchmarks")
 	fmt.Println()
 
+	// Only one run may write to a storage directory at a time
+	lock, err := acquireRunLock(store, *wait)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if *count < 1 {
+		return ui.NewError(fmt.Sprintf("Invalid -count: %d", *count), nil, "Use a count of at least 1, e.g. -count=10")
+	}
+	if *parallelPackages < 1 {
+		return ui.NewError(fmt.Sprintf("Invalid -parallel-packages: %d", *parallelPackages), nil, "Use at least 1 package at once, e.g. -parallel-packages=4")
+	}
+
+	var stressLevels []int
+	if *stress {
+		if *cpuFlag != "" {
+			return ui.NewError("-stress cannot be combined with -cpu", nil,
+				"-stress sets GOMAXPROCS to each -parallelism level: use -parallelism="+*cpuFlag)
+		}
+		stressLevels, err = parseParallelism(*parallelism)
+		if err != nil {
+			return ui.NewError("Invalid -parallelism", err, "Use positive goroutine counts, e.g. -parallelism=1,4,16,64")
+		}
+	}
+
+	var loadOpts loadtest.Options
+	if *load != "" {
+		if *profileFlag != "" || *cpuFlag != "" || *packagePath != "" || *count > 1 || *stress || *on != "" || *parallelPackages > 1 || *startup != "" {
+			return ui.NewError("-profile, -cpu, -stress, -count, -pkg, -parallel-packages, -startup and -on cannot be combined with -load", nil,
+				"-load measures a running service; use -load-duration for more samples")
+		}
+		loadOpts, err = loadOptions(*load, *loadMethod, *loadBody, loadHeaders, *loadDuration, *loadRate, *loadConcurrency)
+		if err != nil {
+			return err
+		}
+	}
+
+	var startupOpts runner.StartupOptions
+	if *startup != "" {
+		if *profileFlag != "" || *cpuFlag != "" || *packagePath != "" || *count > 1 || *stress || *on != "" || *parallelPackages > 1 {
+			return ui.NewError("-profile, -cpu, -stress, -count, -pkg, -parallel-packages and -on cannot be combined with -startup", nil,
+				"-startup times processes of the program it builds; use -startup-iterations for more samples")
+		}
+		ready, err := runner.ParseReadyProbe(*startupReady)
+		if err != nil {
+			return ui.NewError("Invalid -startup-ready", err,
+				"Use exit, a URL such as http://localhost:8080/healthz, tcp://localhost:8080 or output:<regexp>")
+		}
+		if *startupIterations < 1 {
+			return ui.NewError(fmt.Sprintf("Invalid -startup-iterations: %d", *startupIterations), nil, "Start at least 1 process, e.g. -startup-iterations=20")
+		}
+		startupOpts = runner.StartupOptions{
+			Args:       strings.Fields(*startupArgs),
+			Iterations: *startupIterations,
+			Ready:      ready,
+			Timeout:    *startupTimeout,
+		}
+	}
+
+	if *on != "" {
+		if *profileFlag != "" || *cpuFlag != "" || *packagePath != "" || *gcflags != "" || *count > 1 || *stress || *skipIfUnstable {
+			return ui.NewError("-profile, -cpu, -stress, -count, -gcflags, -pkg and -skip-if-unstable cannot be combined with -on", nil,
+				"Remote agents benchmark the package they were started with")
+		}
+		req := dashboard.JobRequest{Bench: *benchFilter, Benchtime: *benchtimeFlag}
+		run, err := runOnAgent(*controller, *token, *on, req)
+		if err != nil {
+			return err
+		}
+		annotateRun(run, runTags, *note)
+		return deliverRun(sinks, *storageDir, run)
+	}
+
 	// Parse profile options
 	var profileOpts *runner.ProfileOptions
 	if *profileFlag != "" {
-		store := storage.NewStorage(*storageDir)
 		profileOpts = &runner.ProfileOptions{
-			Storage: store,
+			Storage:          store,
+			CPUSampleType:    *cpuSampleType,
+			MemorySampleType: *memSampleType,
 		}
 
 		profiles := strings.Split(*profileFlag, ",")
@@ -45,17 +195,21 @@ func Run() error {
 				profileOpts.EnableCPU = true
 			case "mem", "memory":
 				profileOpts.EnableMemory = true
+			case "block":
+				profileOpts.EnableBlock = true
+			case "mutex":
+				profileOpts.EnableMutex = true
 			default:
 				return ui.NewError(
 					fmt.Sprintf("Unknown profile type: %s", p),
 					nil,
-					"Valid profile types: cpu, mem",
+					"Valid profile types: cpu, mem, block, mutex",
 					"Example: -profile=cpu,mem",
 				)
 			}
 		}
 
-		if profileOpts.EnableCPU || profileOpts.EnableMemory {
+		if profileOpts.EnableCPU || profileOpts.EnableMemory || profileOpts.EnableBlock || profileOpts.EnableMutex {
 			var enabled []string
 			if profileOpts.EnableCPU {
 				enabled = append(enabled, "CPU")
@@ -63,10 +217,31 @@ func Run() error {
 			if profileOpts.EnableMemory {
 				enabled = append(enabled, "Memory")
 			}
+			if profileOpts.EnableBlock {
+				enabled = append(enabled, "Block")
+			}
+			if profileOpts.EnableMutex {
+				enabled = append(enabled, "Mutex")
+			}
 			ui.PrintInfo("Profiling enabled: %s", strings.Join(enabled, ", "))
 		}
 	}
 
+	// Numbers measured on a busy or throttled machine would poison trends,
+	// so such runs are recorded as environment-degraded instead
+	throttledBefore, throttleKnown := throttleCount()
+	if *skipIfUnstable {
+		if check := environmentStability(); !check.Passed {
+			ui.PrintWarning("Skipping benchmarks: %s", check.Message)
+			run, err := runner.NewRunner(*packagePath, *benchFilter).WithLiveStatus(store).Skip(check.Message)
+			if err != nil {
+				return ui.ErrBenchmarkFailed(err)
+			}
+			annotateRun(run, runTags, *note)
+			return deliverRun(sinks, *storageDir, run)
+		}
+	}
+
 	// Run benchmarks
 	var spinner *ui.Spinner
 	if !*verbose {
@@ -74,37 +249,68 @@ func Run() error {
 		spinner.Start()
 	}
 
-	r := runner.NewRunner(*packagePath, *benchFilter)
+	// Stop the benchmarks on Ctrl+C so the lock, live status and temporary
+	// files are cleaned up on every platform
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r := runner.NewRunner(*packagePath, *benchFilter).WithContext(ctx)
+	if *load != "" {
+		r = runner.NewRunner("", "").WithContext(ctx).WithLoadTest(*loadName, loadOpts)
+		rate := "as fast as possible"
+		if loadOpts.Rate > 0 {
+			rate = fmt.Sprintf("%g req/s", loadOpts.Rate)
+		}
+		ui.PrintInfo("Load testing %s %s for %s, %d at once, %s", loadOpts.Method, loadOpts.URL, loadOpts.Duration, loadOpts.Concurrency, rate)
+	}
+	if *startup != "" {
+		r = runner.NewRunner(*startup, "").WithContext(ctx).WithStartup(startupOpts)
+		ui.PrintInfo("Timing %d starts of %s, ready on %s", startupOpts.Iterations, *startup, startupOpts.Ready)
+	}
 
 	// Set CPU and benchtime flags if provided
 	if *cpuFlag != "" {
 		r = r.WithCPU(*cpuFlag)
 	}
+	if *stress {
+		r = r.WithStress(stressLevels)
+		ui.PrintInfo("Stress matrix: GOMAXPROCS %s", *parallelism)
+	}
 	if *benchtimeFlag != "" {
 		r = r.WithBenchtime(*benchtimeFlag)
 	}
+	if *count > 1 {
+		r = r.WithCount(*count)
+	}
+	if *gcflags != "" {
+		r = r.WithGCFlags(*gcflags)
+	}
+	if !*hooks {
+		r = r.WithoutHooks()
+	}
+	if *parallelPackages > 1 {
+		r = r.WithPackageWorkers(*parallelPackages)
+	}
+
+	// Publish progress for the dashboard's live view
+	r = r.WithLiveStatus(store)
 
 	// Set up progress callback for non-verbose mode
 	if !*verbose {
-		progressCallback := func(result models.BenchmarkResult) {
-			// Format the message with full benchmark details
-			msg := fmt.Sprintf("Completed: Benchmark%s | %s iters | %s | %s | %s allocs",
-				result.Name,
-				formatIterations(result.Iterations),
-				formatNsPerOp(result.NsPerOp),
-				formatBytes(result.BytesPerOp),
-				formatCount(result.AllocsPerOp),
-			)
-			spinner.UpdateMessage(msg)
-		}
-		r = r.WithProgress(progressCallback)
+		r = r.WithProgress(func(result models.BenchmarkResult) {
+			spinner.UpdateMessage(progressMessage(result))
+		})
 	} else {
 		// In verbose mode, show raw output
 		r = r.WithVerbose(os.Stdout)
 	}
 
 	if profileOpts != nil {
-		r = r.WithProfiling(profileOpts)
+		r = r.WithProfiling(profileOpts).WithAIDefaults(aiDefaults(cfg))
+	}
+
+	if len(extractors) > 0 {
+		r = r.WithMetricExtractors(extractors)
 	}
 
 	run, err := r.Run()
@@ -114,30 +320,183 @@ func Run() error {
 	}
 
 	if err != nil {
+		if ctx.Err() != nil {
+			return ui.NewError("Benchmark run interrupted, no results were saved", err)
+		}
+		if errors.Is(err, runner.ErrNoBenchmarks) {
+			return ui.NewError("No benchmark matched", err,
+				fmt.Sprintf("Check that -bench=%s matches benchmark functions of the package", *benchFilter),
+				"List them with: go test -run=^$ -list=Benchmark ./...")
+		}
 		return ui.ErrBenchmarkFailed(err)
 	}
 
-	// Save results
-	ui.PrintInfo("Saving results...")
-	store := storage.NewStorage(*storageDir)
-	if err := store.Save(run); err != nil {
-		return ui.NewError(
-			"Failed to save results",
-			err,
-			"Check file permissions on storage directory",
-			"Ensure you have write access to: "+*storageDir,
-		)
+	if *skipIfUnstable && throttleKnown && !run.Failed() {
+		if throttled, ok := throttleCount(); ok && throttled > throttledBefore {
+			run.Status = models.StatusDegraded
+			run.Error = fmt.Sprintf("CPUs thermally throttled %d times during the run", throttled-throttledBefore)
+		}
+	}
+
+	annotateRun(run, runTags, *note)
+	err = deliverRun(sinks, *storageDir, run)
+	if slices.ContainsFunc(sinks, isStorageSink) {
+		checkAlerts(store, cfg.Alerts)
+		autoPrune(store, cfg.Retention)
+	}
+	return err
+}
+
+// Environment checks of -skip-if-unstable, replaced in tests
+var (
+	environmentStability = doctor.EnvironmentStability
+	throttleCount        = doctor.ThrottleCount
+)
+
+// loadOptions builds the options of a -load test from the flags
+func loadOptions(target, method, body string, headers []string, duration time.Duration, rate float64, concurrency int) (loadtest.Options, error) {
+	opts := loadtest.Options{
+		URL:         target,
+		Method:      strings.ToUpper(method),
+		Header:      http.Header{},
+		Duration:    duration,
+		Rate:        rate,
+		Concurrency: concurrency,
+	}
+	if concurrency < 1 {
+		return opts, ui.NewError(fmt.Sprintf("Invalid -load-concurrency: %d", concurrency), nil, "Send at least 1 request at once, e.g. -load-concurrency=10")
+	}
+	if duration <= 0 {
+		return opts, ui.NewError(fmt.Sprintf("Invalid -load-duration: %s", duration), nil, "Use a positive duration, e.g. -load-duration=30s")
+	}
+	if err := opts.Validate(); err != nil {
+		return opts, ui.NewError("Invalid -load", err, "Example: -load=http://localhost:8080/api/orders -load-rate=200")
+	}
+	for _, spec := range headers {
+		name, value, err := loadtest.ParseHeader(spec)
+		if err != nil {
+			return opts, ui.NewError("Invalid -load-header", err, "Example: -load-header='Authorization: Bearer $TOKEN'")
+		}
+		opts.Header.Add(name, value)
+	}
+	if path, ok := strings.CutPrefix(body, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return opts, ui.NewError("Failed to read -load-body", err, "Check the path after @")
+		}
+		opts.Body = data
+	} else if body != "" {
+		opts.Body = []byte(body)
+	}
+	return opts, nil
+}
+
+// annotateRun records the tags and note given on the command line with a run
+func annotateRun(run *models.BenchmarkRun, tags map[string]string, note string) {
+	if len(tags) > 0 {
+		run.Tags = tags
+	}
+	run.Note = note
+}
+
+// openSinks creates the sinks of the -sink flags, saving to the storage
+// when none is given. Writing JSON to stdout sends everything else the
+// command prints to stderr, so that the output can be piped.
+func openSinks(specs []string, store *storage.Storage) ([]sink.Sink, error) {
+	if len(specs) == 0 {
+		specs = []string{"storage"}
+	}
+	stdout := os.Stdout
+	var sinks []sink.Sink
+	for _, spec := range specs {
+		s, err := sink.Parse(spec, store, stdout)
+		if err != nil {
+			return nil, ui.NewError("Invalid -sink", err, "Example: -sink=storage -sink=webhook:https://collector.example.com/runs")
+		}
+		if _, ok := s.(*sink.Stdout); ok {
+			os.Stdout = os.Stderr
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+// isStorageSink reports whether a sink saves to the storage
+func isStorageSink(s sink.Sink) bool {
+	_, ok := s.(*sink.Storage)
+	return ok
+}
+
+// saveAndReport saves a finished run and prints its results
+func saveAndReport(store *storage.Storage, storageDir string, run *models.BenchmarkRun) error {
+	return deliverRun([]sink.Sink{sink.NewStorage(store)}, storageDir, run)
+}
+
+// deliverRun sends a finished run to its sinks and prints its results.
+// Every sink is tried, even after another one failed.
+func deliverRun(sinks []sink.Sink, storageDir string, run *models.BenchmarkRun) error {
+	gradeRun(storage.NewStorage(storageDir), run)
+
+	var saved, saveFailed bool
+	var sent []string
+	var failures []error
+	for _, s := range sinks {
+		isStorage := isStorageSink(s)
+		if isStorage {
+			ui.PrintInfo("Saving results...")
+		} else {
+			ui.PrintInfo("Sending results to %s...", s.Name())
+		}
+		if err := s.Write(run); err != nil {
+			ui.PrintError("Failed to send results to %s: %v", s.Name(), err)
+			failures = append(failures, fmt.Errorf("%s: %w", s.Name(), err))
+			saveFailed = saveFailed || isStorage
+			continue
+		}
+		if isStorage {
+			saved = true
+			if err := config.RegisterProject(storageDir); err != nil {
+				ui.PrintWarning("Failed to update the project registry: %v", err)
+			}
+		} else {
+			sent = append(sent, s.Name())
+		}
 	}
 
 	// Display results
 	fmt.Println()
-	ui.PrintSuccess("Benchmarks completed successfully!")
-	fmt.Printf("Results saved with ID: %s\n\n", ui.Bold(run.ID))
+	if run.Failed() {
+		ui.PrintError("Benchmark run terminated abnormally: %s", run.Error)
+	} else if run.Degraded() {
+		ui.PrintWarning("Environment degraded: %s", run.Error)
+		ui.PrintInfo("The run is recorded as environment-degraded, left out of trends and checks")
+	} else if failed := run.CountStatus(models.StatusFailed); failed > 0 {
+		ui.PrintWarning("Benchmarks completed with %d failure(s)", failed)
+	} else {
+		ui.PrintSuccess("Benchmarks completed successfully!")
+	}
+	if skipped := run.CountStatus(models.StatusSkipped); skipped > 0 {
+		ui.PrintInfo("%d benchmark(s) skipped", skipped)
+	}
+	if saved {
+		fmt.Printf("Results saved with ID: %s\n\n", ui.Bold(run.ID))
+	} else {
+		fmt.Printf("Run ID: %s\n\n", ui.Bold(run.ID))
+	}
 
 	ui.PrintSection(ui.ChartEmoji, "Run Information")
 	fmt.Printf("  Timestamp:  %s\n", ui.Dim(run.Timestamp.Format(time.RFC3339)))
 	fmt.Printf("  Duration:   %s\n", ui.Info(run.Duration.String()))
 	fmt.Printf("  Go Version: %s\n", ui.Info(run.GoVersion))
+	if run.Agent != "" {
+		fmt.Printf("  Agent:      %s %s\n", ui.Info(run.Agent), ui.Dim(agent.FormatLabels(run.AgentLabels)))
+	}
+	if run.Startup != nil {
+		fmt.Printf("  Startup:    %s\n", ui.Info(fmt.Sprintf("%d processes, ready on %s", run.Startup.Iterations, run.Startup.Ready)))
+	}
+	if run.Load != nil {
+		fmt.Printf("  Load:       %s\n", ui.Info(fmt.Sprintf("%s %s, %d requests, %d failed", run.Load.Method, run.Load.URL, run.Load.Requests, run.Load.Errors)))
+	}
 
 	// Display profile info if available
 	if run.CPUProfile != "" || run.MemoryProfile != "" {
@@ -151,37 +510,428 @@ func Run() error {
 	}
 	fmt.Println()
 
+	printResultsTable(run.Results)
+	displayCustomMetrics(run.Results)
+	displayDescriptions(run.Results)
+	displayFailures(run.Results)
+	displayPackages(run.Packages)
+	displayStressReport(run)
+	displayScalingReport(run)
+
+	// Display profile summary if available
+	if run.ProfileSummary != nil {
+		displayProfileSummary(run.ID, run.ProfileSummary)
+	}
+
+	fmt.Println()
+	if saved {
+		fmt.Printf("Results saved to: %s\n", storageDir)
+	}
+	for _, name := range sent {
+		fmt.Printf("Results sent to: %s\n", name)
+	}
+
+	// Hint about viewing flame graphs
+	if saved && (run.CPUProfile != "" || run.MemoryProfile != "") {
+		fmt.Printf("\nView flame graphs: gokanon flamegraph %s\n", run.ID)
+	}
+
+	if len(failures) > 0 {
+		suggestions := []string{"Check that the -sink destinations are reachable"}
+		if saveFailed {
+			suggestions = []string{
+				"Check file permissions on storage directory",
+				"Ensure you have write access to: " + storageDir,
+			}
+		}
+		return ui.NewError(fmt.Sprintf("Failed to send results to %d of %d destinations", len(failures), len(sinks)),
+			errors.Join(failures...), suggestions...)
+	}
+	if run.Failed() {
+		displayStderr(run.Stderr)
+		return ui.NewError("Benchmark run terminated abnormally", errors.New(run.Error),
+			"The results recorded until then were kept in a failed run, left out of trends",
+			"Find failed runs with: gokanon list -failed")
+	}
+	return nil
+}
+
+// gradeRun grades the confidence of the results of a finished run against
+// the history of its benchmarks in the storage
+func gradeRun(store *storage.Storage, run *models.BenchmarkRun) {
+	var history map[string]*models.Aggregate
+	if aggregates, err := store.Aggregates(); err == nil {
+		history = aggregates.Benchmarks
+	}
+	stats.GradeRun(run, history)
+}
+
+// stderrTailLines is the number of lines of standard error shown for a
+// failed run
+const stderrTailLines = 20
+
+// displayStderr prints the end of the standard error of a failed run
+func displayStderr(stderr string) {
+	lines := strings.Split(strings.TrimRight(stderr, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return
+	}
+	if len(lines) > stderrTailLines {
+		lines = lines[len(lines)-stderrTailLines:]
+	}
+	ui.PrintSection(ui.CrossEmoji, "Standard Error")
+	for _, line := range lines {
+		fmt.Printf("  %s\n", ui.Dim(line))
+	}
+}
+
+// runOnAgent queues a run on the controller for an agent matching the labels,
+// waits for it to finish and downloads the result
+func runOnAgent(controller, token, on string, req dashboard.JobRequest) (*models.BenchmarkRun, error) {
+	if controller == "" {
+		return nil, ui.NewError("Missing controller URL for -on", nil,
+			"Pass -controller http://controller:8080 or set GOKANON_CONTROLLER")
+	}
+	labels, err := agent.ParseLabels(on)
+	if err != nil || len(labels) == 0 {
+		return nil, ui.NewError("Invalid -on labels", err, "Example: -on cpu=epyc,os=linux")
+	}
+	req.Labels = labels
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := agent.NewClient(controller, token)
+	if agents, err := client.Agents(ctx); err == nil {
+		matching := 0
+		for _, a := range agents {
+			if req.Matches(a.Labels) {
+				matching++
+			}
+		}
+		if matching == 0 {
+			ui.PrintWarning("No connected agent matches %s; the run will wait for one", agent.FormatLabels(labels))
+		}
+	}
+
+	job, err := client.Submit(ctx, req)
+	if err != nil {
+		return nil, ui.NewError("Failed to queue the run on "+controller, err,
+			"Check the controller URL and access token",
+			"Start the controller with: gokanon serve -agents")
+	}
+	ui.PrintInfo("Queued %s for agents matching %s", job.ID, agent.FormatLabels(labels))
+
+	spinner := ui.NewSpinner("Waiting for an agent")
+	spinner.Start()
+	id := job.ID
+	job, err = client.Wait(ctx, id, 2*time.Second, func(job dashboard.Job) {
+		if job.Status == dashboard.JobRunning {
+			spinner.UpdateMessage("Running on " + job.Worker)
+		}
+	})
+	spinner.Stop()
+	if err != nil {
+		return nil, ui.NewError("Lost track of the remote run "+id, err,
+			"Its results are still saved on the controller once it finishes")
+	}
+	if job.Status == dashboard.JobFailed {
+		return nil, ui.ErrBenchmarkFailed(fmt.Errorf("%s failed on %s: %s", id, job.Worker, job.Error))
+	}
+
+	run, err := client.Run(ctx, job.RunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download run %s: %w", job.RunID, err)
+	}
+	return run, nil
+}
+
+// printResultsTable prints benchmark results as a table. Failed and skipped
+// benchmarks have no measurements and show their status instead. Results
+// with several samples show the 95% confidence interval of their mean.
+func printResultsTable(results []models.BenchmarkResult) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Benchmark\tIterations\tns/op\tB/op\tallocs/op")
-	fmt.Fprintln(w, "---------\t----------\t-----\t----\t---------")
-	for _, result := range run.Results {
-		fmt.Fprintf(w, "%s\t%d\t%.2f\t%d\t%d\n",
+	fmt.Fprintln(w, "Benchmark\tIterations\tns/op\tB/op\tallocs/op\tGrade")
+	fmt.Fprintln(w, "---------\t----------\t-----\t----\t---------\t-----")
+	for _, result := range results {
+		if !result.Measured() {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\n", result.Name, strings.ToUpper(result.Status))
+			continue
+		}
+		nsPerOp := fmt.Sprintf("%.2f", result.NsPerOp)
+		if len(result.Samples) > 1 && result.NsPerOp != 0 {
+			_, ci := stats.MeanCI(result.Samples)
+			nsPerOp += fmt.Sprintf(" ±%.1f%%", ci/result.NsPerOp*100)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\t%s\n",
 			result.Name,
 			result.Iterations,
-			result.NsPerOp,
+			nsPerOp,
 			result.BytesPerOp,
 			result.AllocsPerOp,
+			ui.FormatGrade(result.Confidence),
 		)
 	}
 	w.Flush()
+}
 
-	// Display profile summary if available
-	if run.ProfileSummary != nil {
-		displayProfileSummary(run.ProfileSummary)
+// displayFailures lists failed benchmarks with their failure messages
+func displayFailures(results []models.BenchmarkResult) {
+	var failed []models.BenchmarkResult
+	for _, result := range results {
+		if result.Status == models.StatusFailed {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return
 	}
 
-	fmt.Printf("\nResults saved to: %s\n", *storageDir)
+	ui.PrintSection(ui.CrossEmoji, "Failed Benchmarks")
+	for _, result := range failed {
+		if result.Message != "" {
+			fmt.Printf("  %s Benchmark%s: %s\n", ui.Error(ui.ErrorIcon), result.Name, result.Message)
+		} else {
+			fmt.Printf("  %s Benchmark%s\n", ui.Error(ui.ErrorIcon), result.Name)
+		}
+	}
+}
 
-	// Hint about viewing flame graphs
-	if run.CPUProfile != "" || run.MemoryProfile != "" {
-		fmt.Printf("\nView flame graphs: gokanon flamegraph %s\n", run.ID)
+// displayPackages lists the packages of a run benchmarked one by one, with
+// their benchmark counts and the time each go test took
+func displayPackages(packages []models.PackageRun) {
+	if len(packages) < 2 {
+		return
 	}
 
-	return nil
+	ui.PrintSection(ui.ChartEmoji, fmt.Sprintf("Packages (%d)", len(packages)))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Package\tBenchmarks\tDuration\tStatus")
+	for _, p := range packages {
+		status := ui.Success("ok")
+		if p.Failed() {
+			status = ui.Error("FAILED") + " " + ui.Dim(p.Error)
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%s\t%s\n", p.Package, p.Benchmarks, p.Duration.Round(time.Millisecond), status)
+	}
+	w.Flush()
+}
+
+// parseParallelism parses the comma-separated levels of -parallelism into
+// distinct, increasing goroutine counts
+func parseParallelism(list string) ([]int, error) {
+	var levels []int
+	for _, field := range strings.Split(list, ",") {
+		level, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || level <= 0 {
+			return nil, fmt.Errorf("invalid level %q", field)
+		}
+		if !slices.Contains(levels, level) {
+			levels = append(levels, level)
+		}
+	}
+	sort.Ints(levels)
+	if len(levels) < 2 {
+		return nil, fmt.Errorf("a stress matrix needs at least two levels, got %q", list)
+	}
+	return levels, nil
+}
+
+// displayStressReport prints the speedup curves of a stress run, pointing
+// out the benchmarks whose throughput drops before the highest level
+func displayStressReport(run *models.BenchmarkRun) {
+	if run.Stress == nil {
+		return
+	}
+	curves := stats.StressReport(run)
+
+	ui.PrintSection(ui.RocketEmoji, fmt.Sprintf("Concurrency Stress (%d CPUs)", run.Stress.CPUs))
+	if len(curves) == 0 {
+		fmt.Println(ui.Dim("  No benchmark was measured at several parallelism levels"))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Benchmark\tParallelism\tns/op\tSpeedup\tEfficiency")
+	fmt.Fprintln(w, "---------\t-----------\t-----\t-------\t----------")
+	for _, curve := range curves {
+		for i, point := range curve.Points {
+			name := ""
+			if i == 0 {
+				name = curve.Benchmark
+			}
+			fmt.Fprintf(w, "%s\t%d\t%.2f\t%.2fx\t%.0f%%\n",
+				name, point.Parallelism, point.NsPerOp, point.Speedup, point.Efficiency*100)
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	for _, curve := range curves {
+		if curve.Contended {
+			ui.PrintWarning("%s: throughput peaks at %d goroutines and falls beyond, a sign of contention",
+				curve.Benchmark, curve.Peak)
+		}
+	}
+	if maxLevel := run.Stress.Levels[len(run.Stress.Levels)-1]; maxLevel > run.Stress.CPUs {
+		fmt.Println(ui.Dim(fmt.Sprintf("  Levels above %d run more goroutines than CPUs, so efficiency drops there even without contention", run.Stress.CPUs)))
+	}
+}
+
+// displayScalingReport prints the scalability curves of a run taken with
+// several -cpu values. Stress runs have their own report.
+func displayScalingReport(run *models.BenchmarkRun) {
+	if len(run.CPUMatrix) < 2 || run.Stress != nil {
+		return
+	}
+	curves := stats.ScalingReport(run)
+
+	ui.PrintSection(ui.RocketEmoji, fmt.Sprintf("CPU Scaling (GOMAXPROCS %s)", joinInts(run.CPUMatrix)))
+	if len(curves) == 0 {
+		fmt.Println(ui.Dim("  No benchmark was measured at several GOMAXPROCS values"))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Benchmark\tCPUs\tns/op\tSpeedup\tEfficiency")
+	fmt.Fprintln(w, "---------\t----\t-----\t-------\t----------")
+	for _, curve := range curves {
+		for i, point := range curve.Points {
+			name := ""
+			if i == 0 {
+				name = curve.Benchmark
+			}
+			fmt.Fprintf(w, "%s\t%d\t%.2f\t%.2fx\t%.0f%%\n",
+				name, point.Parallelism, point.NsPerOp, point.Speedup, point.Efficiency*100)
+		}
+	}
+	w.Flush()
+	fmt.Println(ui.Dim("\n  Compare the curves of two runs with: gokanon compare -scaling <old-run> <new-run>"))
+}
+
+// joinInts formats a list of integers as a comma-separated list
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// metricExtractors builds the runner's extractors from the configured metrics
+func metricExtractors(cfg *config.Config) ([]*runner.MetricExtractor, error) {
+	var extractors []*runner.MetricExtractor
+	for _, m := range cfg.Metrics {
+		var e *runner.MetricExtractor
+		var err error
+		if m.Regex != "" {
+			e, err = runner.NewRegexExtractor(m.Name, m.Regex)
+		} else {
+			e, err = runner.NewJSONPathExtractor(m.Name, m.JSONPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		extractors = append(extractors, e)
+	}
+	return extractors, nil
+}
+
+// displayCustomMetrics displays custom and extracted metrics of the results
+func displayCustomMetrics(results []models.BenchmarkResult) {
+	hasMetrics := false
+	for _, result := range results {
+		if len(result.Metrics) > 0 {
+			hasMetrics = true
+			break
+		}
+	}
+	if !hasMetrics {
+		return
+	}
+
+	ui.PrintSection(ui.ChartEmoji, "Custom Metrics")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Benchmark\tMetric\tValue")
+	fmt.Fprintln(w, "---------\t------\t-----")
+	for _, result := range results {
+		names := make([]string, 0, len(result.Metrics))
+		for name := range result.Metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s\t%s\t%.4g\n", result.Name, name, result.Metrics[name])
+		}
+	}
+	w.Flush()
+}
+
+// displayDescriptions prints what each benchmark measures, from the doc
+// comments of the benchmark functions. Sub-benchmarks share one line.
+func displayDescriptions(results []models.BenchmarkResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result.Doc == "" || seen[result.Doc] {
+			continue
+		}
+		if len(seen) == 0 {
+			ui.PrintSection(ui.TargetEmoji, "What the Benchmarks Measure")
+		}
+		seen[result.Doc] = true
+		name, _, _ := strings.Cut(result.Name, "/")
+		fmt.Fprintf(w, "%s\t%s\n", name, ui.Dim(models.DocSynopsis(result.Doc)))
+	}
+	w.Flush()
+}
+
+// progressMessage formats a completed benchmark for the spinner
+func progressMessage(result models.BenchmarkResult) string {
+	if !result.Measured() {
+		return fmt.Sprintf("%s: Benchmark%s", strings.ToUpper(result.Status), result.Name)
+	}
+	// Format the message with full benchmark details
+	return fmt.Sprintf("Completed: Benchmark%s | %s iters | %s | %s | %s allocs",
+		result.Name,
+		formatIterations(result.Iterations),
+		formatNsPerOp(result.NsPerOp),
+		formatBytes(result.BytesPerOp),
+		formatCount(result.AllocsPerOp),
+	)
+}
+
+// acquireRunLock takes the storage run lock, optionally waiting for another
+// run to finish
+func acquireRunLock(store *storage.Storage, wait bool) (*storage.RunLock, error) {
+	lock, err := store.AcquireRunLock(false)
+	if err == nil {
+		return lock, nil
+	}
+
+	var locked *storage.LockedError
+	if !errors.As(err, &locked) {
+		return nil, ui.NewError("Failed to lock storage directory", err,
+			"Check file permissions on storage directory")
+	}
+
+	if !wait {
+		return nil, ui.NewError(
+			"Another benchmark run is in progress",
+			err,
+			"Wait for the other run to finish, or pass -wait to queue behind it",
+			"Use a different -storage directory to run concurrently",
+		)
+	}
+
+	ui.PrintInfo("Waiting for run in progress (PID %d, started %s)...",
+		locked.Holder.PID, locked.Holder.StartedAt.Format(time.RFC3339))
+	return store.AcquireRunLock(true)
 }
 
-// displayProfileSummary displays profile analysis summary
-func displayProfileSummary(summary *models.ProfileSummary) {
+// displayProfileSummary displays profile analysis summary of a run
+func displayProfileSummary(runID string, summary *models.ProfileSummary) {
 	fmt.Println("\n" + strings.Repeat("=", 80))
 	fmt.Println("PROFILE ANALYSIS")
 	fmt.Println(strings.Repeat("=", 80))
@@ -239,6 +989,25 @@ func displayProfileSummary(summary *models.ProfileSummary) {
 		}
 	}
 
+	// In-use memory growth between warmup and the end of the run
+	if len(summary.MemoryGrowth) > 0 {
+		fmt.Println("\n📈 In-Use Memory Growth After Warmup")
+		fmt.Println(strings.Repeat("-", 80))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Function\tWarmup\tEnd\tGrowth")
+		for _, g := range summary.MemoryGrowth {
+			if len(g.Function) > 50 {
+				g.Function = g.Function[:47] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t+%s\n", g.Function,
+				strings.TrimSuffix(formatBytes(g.WarmupBytes), "/op"),
+				strings.TrimSuffix(formatBytes(g.FinalBytes), "/op"),
+				strings.TrimSuffix(formatBytes(g.GrowthBytes), "/op"))
+		}
+		w.Flush()
+	}
+
 	// Memory Leaks
 	if len(summary.MemoryLeaks) > 0 {
 		fmt.Println("\n⚠️  Potential Memory Issues")
@@ -260,11 +1029,60 @@ func displayProfileSummary(summary *models.ProfileSummary) {
 				leak.Function,
 				leak.Severity,
 			)
-			fmt.Printf("   Allocations: %d (%s)\n", leak.Allocations, formatBytes(leak.Bytes))
+			if leak.Allocations > 0 {
+				fmt.Printf("   Allocations: %d (%s)\n", leak.Allocations, formatBytes(leak.Bytes))
+			}
 			fmt.Printf("   %s\n", leak.Description)
 		}
 	}
 
+	// Where goroutines waited on each other
+	if len(summary.ContentionHotspots) > 0 {
+		fmt.Printf("\n🔒 Contention Hotspots (blocked: %s, mutex wait: %s)\n",
+			time.Duration(summary.TotalBlockDelay), time.Duration(summary.TotalMutexDelay))
+		fmt.Println(strings.Repeat("-", 80))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Function\tProfile\tWaiting In\tContentions\tDelay\tShare")
+		for _, h := range summary.ContentionHotspots {
+			if len(h.Function) > 50 {
+				h.Function = h.Function[:47] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%.1f%%\n",
+				h.Function,
+				h.Profile,
+				h.Primitive,
+				h.Contentions,
+				time.Duration(h.DelayNs),
+				h.Percentage,
+			)
+		}
+		w.Flush()
+	}
+
+	// Attached custom profiles
+	for _, cp := range summary.CustomProfiles {
+		if len(cp.TopFunctions) == 0 {
+			continue
+		}
+		fmt.Printf("\n📎 %s Profile (%s, total: %d %s)\n", cp.Name, cp.SampleType, cp.Total, cp.Unit)
+		fmt.Println(strings.Repeat("-", 80))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Function\tFlat%\tCum%")
+		for _, fn := range cp.TopFunctions {
+			if len(fn.Name) > 50 {
+				fn.Name = fn.Name[:47] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%.1f%%\t%.1f%%\n",
+				fn.Name,
+				fn.FlatPercent,
+				fn.CumPercent,
+			)
+		}
+		w.Flush()
+	}
+
 	// Optimization Suggestions
 	if len(summary.Suggestions) > 0 {
 		fmt.Println("\n💡 Optimization Suggestions")
@@ -287,12 +1105,37 @@ func displayProfileSummary(summary *models.ProfileSummary) {
 			if sug.Impact != "" {
 				fmt.Printf("   Potential Impact: %s\n", sug.Impact)
 			}
+			if evidence := suggestionEvidence(sug); evidence != "" {
+				fmt.Printf("   Evidence: %s\n", evidence)
+			}
+		}
+		if runID != "" {
+			fmt.Printf("\nInspect the profiles with: gokanon flamegraph %s\n", runID)
 		}
 	}
 
 	fmt.Println("\n" + strings.Repeat("=", 80))
 }
 
+// suggestionEvidence describes what a suggestion is based on: the rule and
+// its confidence, the profile and the benchmarks showing the issue
+func suggestionEvidence(sug models.Suggestion) string {
+	var parts []string
+	switch {
+	case sug.Confidence > 0:
+		parts = append(parts, fmt.Sprintf("%s (%.0f%% confidence)", sug.RuleID, sug.Confidence*100))
+	case sug.RuleID != "":
+		parts = append(parts, sug.RuleID)
+	}
+	if sug.Profile != "" {
+		parts = append(parts, sug.Profile+" profile")
+	}
+	if len(sug.Benchmarks) > 0 {
+		parts = append(parts, "benchmarks "+strings.Join(sug.Benchmarks, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
 // formatBytes formats bytes in human-readable format
 func formatBytes(bytes int64) string {
 	if bytes == 0 {
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/agent"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Search handles the 'search' subcommand, finding runs by benchmark name,
+// package, tag, note or commit through the search index
+func Search() error {
+	searchFlags := flag.NewFlagSet("search", flag.ExitOnError)
+	storageDir := searchFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	limit := searchFlags.Int("limit", 20, "Show at most this many runs, newest first (0 for all)")
+	since := searchFlags.String("since", "", "Only search runs newer than this age, e.g. 30d, 2w or 36h")
+	jsonOutput := searchFlags.Bool("json", false, "Print the matching runs as JSON")
+	searchFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	times := addTimeFlags(searchFlags, "default")
+	if _, err := parseFlags(searchFlags, os.Args[2:]); err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
+	// Flags may follow the query, as in: gokanon search String -since 30d
+	query, err := parseSearchArgs(searchFlags)
+	if err != nil {
+		return err
+	}
+	if query == "" {
+		return ui.NewError("Missing search query", nil,
+			"Example: gokanon search String",
+			"Limit a term to a field ("+strings.Join(storage.SearchFields, ", ")+"): gokanon search 'package:payments tag:env=ci'")
+	}
+
+	hits, err := storage.NewStorage(*storageDir).Search(query)
+	if err != nil {
+		return ui.NewError("Search failed", err,
+			"Limit a term to a field with one of "+strings.Join(storage.SearchFields, ", ")+", e.g. bench:Parse")
+	}
+	if *since != "" {
+		age, err := config.ParseAge(*since)
+		if err != nil {
+			return ui.NewError(fmt.Sprintf("Invalid -since: %s", *since), err,
+				"Use a number of days or weeks, e.g. 30d or 2w, or a Go duration such as 36h")
+		}
+		hits = storage.HitsSince(hits, time.Now().Add(-age))
+	}
+
+	shown := hits
+	if *limit > 0 && len(shown) > *limit {
+		shown = shown[:*limit]
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{"query": query, "count": len(hits), "results": shown})
+	}
+	if len(hits) == 0 {
+		fmt.Println("No runs match the query.")
+		return nil
+	}
+	for _, hit := range shown {
+		line := fmt.Sprintf("%s  %s  %s", ui.Bold(hit.ID), timeFormat.Format(hit.Timestamp), hit.Package)
+		if hit.Failed {
+			line += " " + ui.Warning("(run failed)")
+		}
+		fmt.Println(line)
+		if hit.Commit != "" {
+			fmt.Printf("    %s %s\n", ui.Dim(shortCommit(hit.Commit)), hit.CommitMessage)
+		}
+		if len(hit.Tags) > 0 {
+			fmt.Printf("    %s\n", ui.Dim(agent.FormatLabels(hit.Tags)))
+		}
+		if hit.Note != "" {
+			fmt.Printf("    %s\n", hit.Note)
+		}
+		for _, bench := range hit.Matches {
+			fmt.Printf("    %-40s %12.2f ns/op\n", bench.Name, bench.NsPerOp)
+		}
+	}
+
+	if len(shown) < len(hits) {
+		ui.PrintInfo("%d of %d matching runs shown; use -limit=0 to show all", len(shown), len(hits))
+	}
+	return nil
+}
+
+// parseSearchArgs joins the words of the query, parsing the flags given
+// among or after them
+func parseSearchArgs(fs *flag.FlagSet) (string, error) {
+	var words []string
+	for args := fs.Args(); len(args) > 0; args = fs.Args() {
+		if strings.HasPrefix(args[0], "-") && args[0] != "-" {
+			if err := fs.Parse(args); err != nil {
+				return "", err
+			}
+			continue
+		}
+		words = append(words, args[0])
+		if err := fs.Parse(args[1:]); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(words, " "), nil
+}
+
+// shortCommit abbreviates a commit hash
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -1,21 +1,56 @@
 package commands
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"flag"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/dashboard"
 	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
 )
 
 // Serve starts the interactive web dashboard
 func Serve() error {
 	serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
-	storageDir := serveFlags.String("storage", ".gokanon", "Storage directory for results")
+	storageDir := serveFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
 	port := serveFlags.Int("port", 8080, "Port for web server")
 	addr := serveFlags.String("addr", "localhost", "Address to bind to (use 0.0.0.0 for all interfaces)")
-	serveFlags.Parse(os.Args[2:])
+	runPkg := serveFlags.String("run-pkg", "", "Package that may be benchmarked from the dashboard's \"Run now\" button")
+	agents := serveFlags.Bool("agents", false, "Accept remote benchmark agents that run queued jobs (see 'gokanon agent')")
+	token := serveFlags.String("token", os.Getenv("GOKANON_DASHBOARD_TOKEN"), "Token required to trigger runs and join as an agent (default: $GOKANON_DASHBOARD_TOKEN or a random token)")
+	serveFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	basicAuth := serveFlags.String("basic-auth", os.Getenv("GOKANON_DASHBOARD_BASIC_AUTH"), "Require viewers to log in with these credentials, as user:password (default: $GOKANON_DASHBOARD_BASIC_AUTH)")
+	authToken := serveFlags.String("auth-token", os.Getenv("GOKANON_DASHBOARD_AUTH_TOKEN"), "Require viewers to present this token, as a bearer token or basic auth password (default: $GOKANON_DASHBOARD_AUTH_TOKEN)")
+	readOnly := serveFlags.Bool("read-only", false, "Reject every request that could change the storage or trigger runs")
+	tz := serveFlags.String("tz", "", "Time zone for timestamps: UTC or an IANA name (default: each viewer's local zone)")
+	browser := addOpenFlags(serveFlags, "dashboard")
+	cfg, err := parseFlags(serveFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	location, err := ui.ParseTimeZone(*tz)
+	if err != nil {
+		return ui.NewError("Invalid time zone", err, "Example: -tz=UTC or -tz=Europe/Berlin")
+	}
+
+	if *basicAuth != "" {
+		if user, password, ok := strings.Cut(*basicAuth, ":"); !ok || user == "" || password == "" {
+			return ui.NewError("Invalid -basic-auth", nil, "Give a user name and password, e.g. -basic-auth=team:s3cret")
+		}
+	}
+	if *readOnly && (*runPkg != "" || *agents) {
+		return ui.NewError("-read-only cannot be combined with -run-pkg or -agents", nil,
+			"Triggered runs and agents save results: serve a read-only dashboard of the same storage separately")
+	}
 
 	store := storage.NewStorage(*storageDir)
 
@@ -28,14 +63,67 @@ func Serve() error {
 
 	// Create and start the dashboard server
 	server := dashboard.NewServer(store, *addr, *port)
+	server.SetScoreWeights(cfg.Score.Weights)
+	server.SetSLOs(cfg.SLOs)
+	if location != nil && location != time.Local {
+		// "local" means the viewer's zone, which the browser uses by default
+		server.SetTimeZone(location.String())
+	}
+
+	server.SetAuth(dashboard.Auth{BasicAuth: *basicAuth, Token: *authToken})
+	server.SetReadOnly(*readOnly)
+	if *basicAuth == "" && *authToken == "" && !isLoopback(*addr) {
+		ui.PrintWarning("The dashboard is reachable from other machines without authentication: set -basic-auth or -auth-token")
+	}
+
+	if (*runPkg != "" || *agents) && *token == "" {
+		generated, err := generateToken()
+		if err != nil {
+			return fmt.Errorf("failed to generate access token: %w", err)
+		}
+		*token = generated
+	}
+	if *runPkg != "" {
+		server.EnableRuns(*runPkg, *token)
+		fmt.Printf("Runs of %s can be triggered from the dashboard with token: %s\n", *runPkg, *token)
+	}
+	if *agents {
+		server.EnableAgents(*token)
+		fmt.Printf("Agents can join with: gokanon agent -join http://%s:%d -token %s\n", *addr, *port, *token)
+	}
 
 	fmt.Println("Starting interactive web dashboard...")
 	fmt.Printf("Dashboard will be available at: http://%s:%d\n", *addr, *port)
 	fmt.Println("\nPress Ctrl+C to stop the server")
 
+	host := *addr
+	if host == "0.0.0.0" || host == "::" || host == "" {
+		host = "localhost"
+	}
+	local := net.JoinHostPort(host, strconv.Itoa(*port))
+	browser.openWhenListening(local, "http://"+local)
+
 	if err := server.Start(); err != nil {
 		return fmt.Errorf("failed to start dashboard server: %w", err)
 	}
 
 	return nil
 }
+
+// isLoopback reports whether the dashboard binds to this machine only
+func isLoopback(addr string) bool {
+	if addr == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.IsLoopback()
+}
+
+// generateToken returns a random token for authenticating dashboard runs
+func generateToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Show handles the 'show' subcommand, printing everything recorded about
+// one saved run
+func Show() error {
+	showFlags := flag.NewFlagSet("show", flag.ExitOnError)
+	storageDir := showFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	format := showFlags.String("o", "text", "Output format: text, json")
+	showFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	times := addTimeFlags(showFlags, "rfc3339")
+	cfg, err := parseFlags(showFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return ui.NewError(fmt.Sprintf("Unknown output format: %s", *format), nil, "Valid formats: text, json")
+	}
+
+	args := showFlags.Args()
+	if len(args) > 1 {
+		return fmt.Errorf("usage: gokanon show [-o json] [<run>]")
+	}
+	ref := "latest"
+	if len(args) == 1 {
+		ref = args[0]
+	}
+
+	store := storage.NewStorage(*storageDir)
+	run, err := store.Resolve(ref)
+	if err != nil {
+		return fmt.Errorf("failed to load run: %w", err)
+	}
+	profiles := profileStatuses(run)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			*models.BenchmarkRun
+			Profiles []profileStatus `json:"profiles"`
+		}{run, profiles})
+	}
+
+	ui.PrintHeader(fmt.Sprintf("Run: %s", run.ID))
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", ui.Bold(run.ID))
+	fmt.Fprintf(w, "Timestamp:\t%s\n", timeFormat.Format(run.Timestamp))
+	fmt.Fprintf(w, "Duration:\t%s\n", run.Duration)
+	fmt.Fprintf(w, "Package:\t%s\n", run.Package)
+	fmt.Fprintf(w, "Go Version:\t%s\n", run.GoVersion)
+	if run.Command != "" {
+		fmt.Fprintf(w, "Command:\t%s\n", run.Command)
+	}
+	switch {
+	case run.Failed():
+		fmt.Fprintf(w, "Status:\t%s\n", ui.Error("failed: "+run.Error))
+	case run.Degraded():
+		fmt.Fprintf(w, "Status:\t%s\n", ui.Warning("environment degraded: "+run.Error))
+	default:
+		fmt.Fprintf(w, "Status:\t%s\n", ui.Success("ok"))
+	}
+	if v, ok := stats.Score(run, cfg.Score.Weights); ok {
+		fmt.Fprintf(w, "Score:\t%s\n", stats.FormatScore(v))
+	}
+	fmt.Fprintf(w, "Confidence:\t%s\n", ui.FormatGrade(stats.RunConfidence(run)))
+	if run.Agent != "" {
+		fmt.Fprintf(w, "Agent:\t%s\n", run.Agent)
+	}
+	if run.NormalizedTo != "" {
+		fmt.Fprintf(w, "Normalized to:\t%s\n", run.NormalizedTo)
+	}
+	if run.Note != "" {
+		fmt.Fprintf(w, "Note:\t%s\n", run.Note)
+	}
+	w.Flush()
+
+	if run.Commit != "" {
+		ui.PrintSection("🔀", "Git")
+		fmt.Printf("  Commit:  %s\n", run.Commit)
+		if run.CommitMessage != "" {
+			fmt.Printf("  Message: %s\n", run.CommitMessage)
+		}
+	}
+	printShowEnvironment(run)
+
+	if len(run.Tags) > 0 {
+		ui.PrintSection("🏷️", "Tags")
+		keys := make([]string, 0, len(run.Tags))
+		for key := range run.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("  %s=%s\n", key, run.Tags[key])
+		}
+	}
+	if len(run.Annotations) > 0 {
+		ui.PrintSection("📝", "Annotations")
+		for _, annotation := range run.Annotations {
+			fmt.Printf("  %s  %s\n", timeFormat.Format(annotation.Time), annotation)
+		}
+	}
+
+	ui.PrintSection(ui.ChartEmoji, fmt.Sprintf("Benchmarks (%d)", len(run.Results)))
+	printResultsTable(run.Results)
+	printShowMetrics(run.Results)
+	displayFailures(run.Results)
+	displayPackages(run.Packages)
+
+	ui.PrintSection(ui.FireEmoji, "Profiles")
+	if len(profiles) == 0 {
+		fmt.Println("  None collected; record them with: gokanon run -profile=cpu,mem")
+		return nil
+	}
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range profiles {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", p.Name, p.Status, p.Path)
+	}
+	w.Flush()
+	if summary := run.ProfileSummary; summary != nil {
+		fmt.Printf("  Analysis: %d hot functions, %d suggestions\n", len(summary.CPUTopFunctions), len(summary.Suggestions))
+	}
+	if slices.ContainsFunc(profiles, func(p profileStatus) bool { return p.Status == profileAvailable }) {
+		ui.PrintInfo("View them with: gokanon flamegraph %s", run.ID)
+	}
+	return nil
+}
+
+// Statuses of the profiles of a run
+const (
+	profileAvailable = "available"
+	profileMissing   = "missing" // Recorded but its file is gone
+	profilePruned    = "pruned"
+)
+
+// profileStatus tells whether a profile of a run can still be viewed
+type profileStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Path   string `json:"path,omitempty"`
+}
+
+// profileStatuses returns the profiles recorded for a run, collected,
+// attached or since pruned
+func profileStatuses(run *models.BenchmarkRun) []profileStatus {
+	statuses := []profileStatus{}
+	add := func(name, path string) {
+		status := profileAvailable
+		if _, err := os.Stat(path); err != nil {
+			status = profileMissing
+		}
+		statuses = append(statuses, profileStatus{Name: name, Status: status, Path: path})
+	}
+
+	for _, p := range []struct{ name, path string }{
+		{"cpu", run.CPUProfile},
+		{"mem", run.MemoryProfile},
+		{"warmup", run.WarmupProfile},
+		{"block", run.BlockProfile},
+		{"mutex", run.MutexProfile},
+	} {
+		if p.path != "" {
+			add(p.name, p.path)
+		}
+	}
+	for _, p := range run.AttachedProfiles {
+		add(p.Name, p.Path)
+	}
+	for _, name := range run.PrunedProfiles {
+		statuses = append(statuses, profileStatus{Name: name, Status: profilePruned})
+	}
+	return statuses
+}
+
+// printShowEnvironment prints the machine and build settings of a run
+func printShowEnvironment(run *models.BenchmarkRun) {
+	var lines []string
+	if env := run.Environment; env != nil {
+		if env.CPUModel != "" {
+			lines = append(lines, "CPU:         "+env.CPUModel)
+		}
+		if env.CPUs > 0 {
+			lines = append(lines, fmt.Sprintf("CPUs:        %d (GOMAXPROCS %d)", env.CPUs, env.GOMAXPROCS))
+		}
+		if env.OS != "" {
+			lines = append(lines, strings.TrimSpace("OS:          "+env.OS+" "+env.Kernel))
+		}
+		if env.LoadAverage > 0 {
+			lines = append(lines, fmt.Sprintf("Load:        %.2f", env.LoadAverage))
+		}
+		if env.Governor != "" {
+			lines = append(lines, "Governor:    "+env.Governor)
+		}
+		if env.CPULimit > 0 {
+			lines = append(lines, fmt.Sprintf("CPU limit:   %g", env.CPULimit))
+		}
+		if env.MemoryLimit > 0 {
+			lines = append(lines, "Mem limit:   "+ui.FormatBytes(float64(env.MemoryLimit)))
+		}
+	}
+	if tc := run.Toolchain; tc != nil && tc.GOOS != "" {
+		lines = append(lines, "Target:      "+tc.GOOS+"/"+tc.GOARCH)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	ui.PrintSection("🖥️", "Environment")
+	for _, line := range lines {
+		fmt.Println("  " + line)
+	}
+}
+
+// printShowMetrics lists the throughput and custom metrics of the results,
+// which the results table leaves out
+func printShowMetrics(results []models.BenchmarkResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	found := false
+	for _, result := range results {
+		var metrics []string
+		if result.MBPerSec > 0 {
+			metrics = append(metrics, fmt.Sprintf("%.2f MB/s", result.MBPerSec))
+		}
+		names := make([]string, 0, len(result.Metrics))
+		for name := range result.Metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			metrics = append(metrics, fmt.Sprintf("%g %s", result.Metrics[name], name))
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		if !found {
+			fmt.Println()
+			fmt.Fprintln(w, "Benchmark\tMetrics")
+			fmt.Fprintln(w, "---------\t-------")
+			found = true
+		}
+		fmt.Fprintf(w, "%s\t%s\n", result.Name, strings.Join(metrics, ", "))
+	}
+	w.Flush()
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/slo"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// SLO handles the 'slo' subcommand
+func SLO() error {
+	if len(os.Args) < 3 {
+		fmt.Println("Service level objective commands:")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  gokanon slo <subcommand> [options]")
+		fmt.Println()
+		fmt.Println("Subcommands:")
+		fmt.Println("  status   Show compliance, burn rate and breach history of each SLO")
+		fmt.Println()
+		fmt.Println("SLOs are declared under 'slos:' in .gokanon.yaml, for example:")
+		fmt.Println()
+		fmt.Println("  slos:")
+		fmt.Println("    - name: checkout-latency")
+		fmt.Println("      benchmark: Checkout")
+		fmt.Println("      percentile: 95")
+		fmt.Println("      window: 20")
+		fmt.Println("      max: 2ms")
+		fmt.Println()
+		return nil
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "status":
+		return sloStatus()
+	default:
+		return ui.NewError(
+			fmt.Sprintf("Unknown slo subcommand: %s", subcommand),
+			nil,
+			"Valid subcommands: status",
+			"Run 'gokanon slo' to see usage",
+		)
+	}
+}
+
+// sloStatus evaluates every configured SLO against the saved runs
+func sloStatus() error {
+	statusFlags := flag.NewFlagSet("slo-status", flag.ExitOnError)
+	storageDir := statusFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	configPath := statusFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	format := statusFlags.String("format", "table", "Output format: table, json")
+	cfg, err := parseFlags(statusFlags, os.Args[3:])
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.SLOs) == 0 {
+		return ui.NewError(
+			"No SLOs configured",
+			nil,
+			fmt.Sprintf("Declare objectives under 'slos:' in %s", *configPath),
+			"Run 'gokanon slo' for an example",
+		)
+	}
+
+	store := storage.NewStorage(*storageDir)
+	runs, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list results: %w", err)
+	}
+
+	statuses, err := slo.EvaluateAll(cfg.SLOs, runs)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(statuses)
+	case "table":
+		printSLOStatus(statuses)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s (supported: table, json)", *format)
+	}
+}
+
+// printSLOStatus prints a compliance table followed by the breach history
+func printSLOStatus(statuses []slo.Status) {
+	ui.PrintHeader("Service Level Objectives")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SLO\tBenchmark\tObjective\tCurrent\tSamples\tBurn rate\tStatus")
+	fmt.Fprintln(w, "---\t---------\t---------\t-------\t-------\t---------\t------")
+	for _, s := range statuses {
+		current, burnRate, state := "-", "-", ui.Dim(s.State)
+		if s.State != slo.StateNoData {
+			current = slo.FormatValue(s.Value, s.Metric)
+			burnRate = fmt.Sprintf("%.2f", s.BurnRate)
+		}
+		switch s.State {
+		case slo.StateOK:
+			state = ui.Success(ui.SuccessIcon + " " + s.State)
+		case slo.StateBreached:
+			state = ui.Error(ui.ErrorIcon + " " + s.State)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%s\t%s\n",
+			s.Name, s.Benchmark, s.Objective, current, s.Samples, s.Window, burnRate, state)
+	}
+	w.Flush()
+
+	for _, s := range statuses {
+		if len(s.Breaches) == 0 {
+			continue
+		}
+		ui.PrintSection("📉", fmt.Sprintf("Breach history of %s (last %d runs)", s.Name, len(s.History)))
+		for _, b := range s.Breaches {
+			period := b.From.Format("2006-01-02 15:04")
+			if b.Runs > 1 {
+				period += " to " + b.To.Format("2006-01-02 15:04")
+			}
+			ongoing := ""
+			if b.Ongoing {
+				ongoing = ui.Error(" (ongoing)")
+			}
+			fmt.Printf("  %s → %s: %d run(s), %s%s\n", b.FromRun, b.ToRun, b.Runs, period, ongoing)
+		}
+	}
+}
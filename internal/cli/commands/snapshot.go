@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/dashboard"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Snapshot handles the 'snapshot' subcommand, freezing the dashboard's
+// statistics and trends under a name such as a release version
+func Snapshot() error {
+	snapshotFlags := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	name := snapshotFlags.String("name", "", "Snapshot name, such as the release version (required)")
+	description := snapshotFlags.String("desc", "", "Snapshot description")
+	limit := snapshotFlags.Int("limit", 50, "Number of newest runs whose trends are frozen")
+	force := snapshotFlags.Bool("force", false, "Replace an existing snapshot with the same name")
+	list := snapshotFlags.Bool("list", false, "List the saved snapshots")
+	storageDir := snapshotFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	times := addTimeFlags(snapshotFlags, "2006-01-02 15:04")
+	snapshotFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	cfg, err := parseFlags(snapshotFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	store := storage.NewStorage(*storageDir)
+	if *list {
+		timeFormat, err := times.parse()
+		if err != nil {
+			return err
+		}
+		return listSnapshots(store, timeFormat)
+	}
+
+	if *name == "" {
+		return ui.NewError(
+			"Snapshot name is required",
+			nil,
+			"Use -name flag to specify snapshot name",
+			"Example: gokanon snapshot -name=v2.3.0",
+		)
+	}
+	if err := storage.ValidateBaselineName(*name); err != nil {
+		return ui.NewError(
+			"Invalid snapshot name",
+			err,
+			"Snapshot names are file names, so they may not contain path separators or <>:\"|?*",
+			"Example: gokanon snapshot -name=v2.3.0",
+		)
+	}
+	if *limit <= 0 {
+		return ui.NewError(fmt.Sprintf("Invalid -limit: %d", *limit), nil, "Use a positive number of runs, e.g. -limit=50")
+	}
+	if store.HasSnapshot(*name) && !*force {
+		return ui.NewError(
+			fmt.Sprintf("Snapshot '%s' already exists", *name),
+			nil,
+			"Use -force to replace it with the current data",
+			"Try: gokanon snapshot -list",
+		)
+	}
+	if _, err := store.GetLatest(); err != nil {
+		return ui.NewError(
+			"No benchmark runs to snapshot",
+			err,
+			"Run 'gokanon run' first to create a benchmark run",
+		)
+	}
+
+	server := dashboard.NewServer(store, "", 0)
+	server.SetScoreWeights(cfg.Score.Weights)
+	snapshot, err := server.Snapshot(*name, *description, *limit)
+	if err != nil {
+		return ui.NewError("Failed to take snapshot", err)
+	}
+	if err := store.SaveSnapshot(snapshot); err != nil {
+		return ui.NewError("Failed to save snapshot", err)
+	}
+
+	ui.PrintSuccess("Snapshot '%s' saved (runs: %v)", snapshot.Name, snapshot.Stats["totalRuns"])
+	ui.PrintInfo("Browse it with 'gokanon serve' at /snapshots/%s", snapshot.Name)
+	return nil
+}
+
+// listSnapshots prints the saved snapshots, newest first
+func listSnapshots(store *storage.Storage, timeFormat *ui.TimeFormat) error {
+	snapshots, err := store.ListSnapshots()
+	if err != nil {
+		return ui.NewError("Failed to list snapshots", err)
+	}
+	if len(snapshots) == 0 {
+		fmt.Println("No snapshots found.")
+		fmt.Println("Take one with: gokanon snapshot -name=<release>")
+		return nil
+	}
+
+	ui.PrintHeader("Dashboard Snapshots")
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tCreated\tRuns\tDescription")
+	fmt.Fprintln(w, "----\t-------\t----\t-----------")
+	for _, snapshot := range snapshots {
+		desc := snapshot.Description
+		if desc == "" {
+			desc = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\n",
+			snapshot.Name,
+			timeFormat.Format(snapshot.CreatedAt),
+			snapshot.Stats["totalRuns"],
+			desc,
+		)
+	}
+	w.Flush()
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/stats"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// minStabilityCount is the fewest repetitions with quartiles to find
+// outliers from
+const minStabilityCount = 5
+
+// Stability handles the 'stability' subcommand, running each benchmark
+// many times to find the ones too noisy to gate CI on
+func Stability() error {
+	stabilityFlags := flag.NewFlagSet("stability", flag.ExitOnError)
+	benchFilter := stabilityFlags.String("bench", ".", "Benchmark filter (passed to -bench)")
+	packagePath := stabilityFlags.String("pkg", "", "Package path (default: current directory)")
+	count := stabilityFlags.Int("count", 20, "Number of times to run each benchmark")
+	benchtimeFlag := stabilityFlags.String("benchtime", "", "Benchmark time of each run (passed to -benchtime)")
+	cvThreshold := stabilityFlags.Float64("cv-threshold", 5.0, "Coefficient of variation (%) above which a benchmark is too noisy")
+	threshold := stabilityFlags.Float64("threshold", 5.0, "Regression threshold (%) CI gates on, sizing the suggested -count")
+	stabilityFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	if _, err := parseFlags(stabilityFlags, os.Args[2:]); err != nil {
+		return err
+	}
+
+	if *count < minStabilityCount {
+		return ui.NewError(fmt.Sprintf("Invalid -count: %d", *count), nil,
+			fmt.Sprintf("Outliers need at least %d runs per benchmark, e.g. -count=20", minStabilityCount))
+	}
+	if *cvThreshold <= 0 || *threshold <= 0 {
+		return ui.NewError("Invalid threshold", nil, "Use positive percentages, e.g. -cv-threshold=5 -threshold=5")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r := runner.NewRunner(*packagePath, *benchFilter).WithContext(ctx).WithCount(*count).WithoutTests()
+	if *benchtimeFlag != "" {
+		r = r.WithBenchtime(*benchtimeFlag)
+	}
+	spinner := ui.NewSpinner(fmt.Sprintf("Running each benchmark %d times", *count))
+	spinner.Start()
+	run, err := r.Run()
+	spinner.Stop()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ui.NewError("Stability check interrupted", err)
+		}
+		return ui.ErrBenchmarkFailed(err)
+	}
+
+	var reports []stats.Stability
+	for _, result := range run.Results {
+		if result.Measured() {
+			reports = append(reports, stats.AnalyzeStability(result, *cvThreshold, *threshold))
+		}
+	}
+	if len(reports) == 0 {
+		return ui.NewError("No benchmark was measured", nil,
+			"Check the filter with: go test -list 'Benchmark.*' "+*packagePath)
+	}
+
+	ui.PrintSection(ui.TargetEmoji, fmt.Sprintf("Benchmark Stability (%d runs each)", *count))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BENCHMARK\tMEAN\tCV\tOUTLIERS\tVERDICT")
+	var noisy []stats.Stability
+	for _, report := range reports {
+		verdict := ui.Success(ui.SuccessIcon + " stable")
+		if !report.Stable {
+			verdict = ui.Warning(ui.WarningIcon + " noisy")
+			noisy = append(noisy, report)
+		}
+		fmt.Fprintf(w, "%s\t%.2f ns/op\t%.1f%%\t%d/%d\t%s\n", report.Benchmark, report.Mean,
+			report.CV, report.Outliers, report.Samples, verdict)
+	}
+	w.Flush()
+
+	fmt.Println()
+	if len(noisy) == 0 {
+		ui.PrintSuccess("All %d benchmarks are stable enough to gate CI on", len(reports))
+		return nil
+	}
+	ui.PrintWarning("%d of %d benchmarks are too noisy to gate CI on a %.0f%% threshold:", len(noisy), len(reports), *threshold)
+	for _, report := range noisy {
+		fmt.Printf("  %s: try -count=%d -benchtime=%s\n", report.Benchmark, report.SuggestedCount, report.SuggestedBenchtime)
+	}
+	fmt.Println(ui.Dim(fmt.Sprintf("Stable means a CV of at most %.1f%% with at most one outlier in ten runs.", *cvThreshold)))
+	return nil
+}
@@ -6,42 +6,70 @@ import (
 	"os"
 	"strings"
 
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
 	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
 )
 
 // Stats handles the 'stats' subcommand
 func Stats() error {
 	statsFlags := flag.NewFlagSet("stats", flag.ExitOnError)
-	storageDir := statsFlags.String("storage", ".gokanon", "Storage directory for results")
-	lastN := statsFlags.Int("last", 0, "Analyze last N runs (0 = all)")
+	storageDir := statsFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	lastN := statsFlags.Int("last", 0, "Analyze last N runs (0 = all, summarized from the statistics cache without medians)")
 	cvThreshold := statsFlags.Float64("cv-threshold", 10.0, "Coefficient of variation threshold for stability (%)")
-	statsFlags.Parse(os.Args[2:])
-
-	store := storage.NewStorage(*storageDir)
-	runs, err := store.List()
-	if err != nil {
-		return fmt.Errorf("failed to list results: %w", err)
+	stress := statsFlags.Bool("stress", false, "Show the scaling report of the newest 'run -stress' instead")
+	if _, err := parseFlags(statsFlags, os.Args[2:]); err != nil {
+		return err
 	}
 
-	if len(runs) == 0 {
-		return fmt.Errorf("no benchmark results found")
+	store := storage.NewStorage(*storageDir)
+	if *stress {
+		return showStressReport(store, *lastN)
 	}
 
-	// Limit to last N runs if specified
-	if *lastN > 0 && *lastN < len(runs) {
-		runs = runs[:*lastN]
-	}
+	// The whole history is summarized from the statistics cache; a window
+	// of runs is analyzed exactly, including the median
+	var statistics map[string]*stats.Stats
+	if *lastN > 0 {
+		runs, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list results: %w", err)
+		}
+		runs = models.WithoutFailed(runs)
+		if len(runs) == 0 {
+			return fmt.Errorf("no benchmark results found")
+		}
+		if *lastN < len(runs) {
+			runs = runs[:*lastN]
+		}
 
-	fmt.Printf("Statistical Analysis (%d runs)\n", len(runs))
-	fmt.Printf("Runs: %s to %s\n\n",
-		runs[len(runs)-1].Timestamp.Format("2006-01-02 15:04:05"),
-		runs[0].Timestamp.Format("2006-01-02 15:04:05"),
-	)
+		fmt.Printf("Statistical Analysis (%d runs)\n", len(runs))
+		fmt.Printf("Runs: %s to %s\n\n",
+			runs[len(runs)-1].Timestamp.Format("2006-01-02 15:04:05"),
+			runs[0].Timestamp.Format("2006-01-02 15:04:05"),
+		)
+		statistics = stats.NewAnalyzer().AnalyzeMultiple(runs)
+	} else {
+		aggregates, err := store.Aggregates()
+		if err != nil {
+			return fmt.Errorf("failed to load statistics: %w", err)
+		}
+		if len(aggregates.Benchmarks) == 0 {
+			return fmt.Errorf("no benchmark results found")
+		}
 
-	// Analyze
-	analyzer := stats.NewAnalyzer()
-	statistics := analyzer.AnalyzeMultiple(runs)
+		fmt.Printf("Statistical Analysis (%d runs)\n", len(aggregates.Runs))
+		fmt.Printf("Runs: %s to %s\n\n",
+			aggregates.First.Format("2006-01-02 15:04:05"),
+			aggregates.Last.Format("2006-01-02 15:04:05"),
+		)
+		statistics = make(map[string]*stats.Stats)
+		for name, agg := range aggregates.Benchmarks {
+			statistics[name] = stats.FromAggregate(name, agg)
+		}
+	}
 
 	// Display
 	fmt.Println("Benchmark Statistics:")
@@ -61,6 +89,32 @@ func Stats() error {
 
 	fmt.Println(strings.Repeat("-", 150))
 	fmt.Printf("\nNote: Benchmarks with CV (coefficient of variation) <= %.1f%% are considered stable.\n", *cvThreshold)
+	if *lastN == 0 {
+		fmt.Println("The median needs every value; use -last=N to analyze a window of runs exactly.")
+	}
 
 	return nil
 }
+
+// showStressReport prints the speedup curves of the newest stress run among
+// the last lastN runs, or all runs when lastN is 0
+func showStressReport(store *storage.Storage, lastN int) error {
+	runs, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list results: %w", err)
+	}
+	if lastN > 0 && lastN < len(runs) {
+		runs = runs[:lastN]
+	}
+	for i := range runs {
+		run := &runs[i]
+		if run.Stress == nil {
+			continue
+		}
+		fmt.Printf("Concurrency Stress Report\n")
+		fmt.Printf("Run: %s (%s)\n", run.ID, run.Timestamp.Format("2006-01-02 15:04:05"))
+		displayStressReport(run)
+		return nil
+	}
+	return ui.NewError("No stress run found", nil, "Record one with: gokanon run -stress -parallelism=1,4,16,64")
+}
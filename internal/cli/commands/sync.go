@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/remote"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Sync handles the 'sync' subcommand
+func Sync() error {
+	if len(os.Args) < 3 {
+		fmt.Println("Share benchmark history through an S3-compatible bucket:")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  gokanon sync <subcommand> [options]")
+		fmt.Println()
+		fmt.Println("Subcommands:")
+		fmt.Println("  push     Upload local runs and baselines to the bucket")
+		fmt.Println("  pull     Download runs and baselines from the bucket")
+		fmt.Println()
+		fmt.Println("Examples:")
+		fmt.Println("  gokanon sync push -remote=s3://my-bucket/benchmarks")
+		fmt.Println("  gokanon sync pull -remote=gs://my-bucket/benchmarks -dry-run")
+		fmt.Println("  gokanon sync push -remote=s3://bench -endpoint=http://localhost:9000")
+		fmt.Println()
+		fmt.Println("Credentials are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		fmt.Println("(HMAC keys for Cloud Storage). The remote can also be set in the")
+		fmt.Println("remote section of .gokanon.yaml.")
+		fmt.Println()
+		return nil
+	}
+
+	subcommand := os.Args[2]
+	switch subcommand {
+	case "push", "pull":
+		return syncRun(subcommand)
+	default:
+		return ui.NewError(
+			fmt.Sprintf("Unknown sync subcommand: %s", subcommand),
+			nil,
+			"Valid subcommands: push, pull",
+			"Run 'gokanon sync' to see usage",
+		)
+	}
+}
+
+// syncRun pushes or pulls the history
+func syncRun(direction string) error {
+	syncFlags := flag.NewFlagSet("sync-"+direction, flag.ExitOnError)
+	remoteURL := syncFlags.String("remote", "", "Bucket URL: s3://bucket/prefix or gs://bucket/prefix (default: remote.url in config)")
+	endpoint := syncFlags.String("endpoint", "", "Endpoint of an S3-compatible service, e.g. http://localhost:9000")
+	region := syncFlags.String("region", "", "Bucket region (default: AWS_REGION or us-east-1)")
+	dryRun := syncFlags.Bool("dry-run", false, "Show what would be transferred without changing anything")
+	force := syncFlags.Bool("force", false, "Replace conflicting copies instead of skipping them")
+	storageDir := syncFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	syncFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	cfg, err := parseFlags(syncFlags, os.Args[3:])
+	if err != nil {
+		return err
+	}
+
+	if *remoteURL == "" {
+		*remoteURL = cfg.Remote.URL
+	}
+	if *endpoint == "" {
+		*endpoint = cfg.Remote.Endpoint
+	}
+	if *region == "" {
+		*region = cfg.Remote.Region
+	}
+	if *remoteURL == "" {
+		return ui.NewError("No remote configured", nil,
+			"Use -remote=s3://bucket/prefix",
+			"Or set remote.url in "+config.DefaultFile)
+	}
+
+	loc, err := remote.ParseLocation(*remoteURL, *endpoint, *region)
+	if err != nil {
+		return ui.NewError("Invalid remote", err, "Use s3://bucket/prefix or gs://bucket/prefix")
+	}
+	creds, err := remote.CredentialsFromEnv()
+	if err != nil {
+		return ui.NewError("Missing bucket credentials", err,
+			"Export AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY",
+			"For Cloud Storage, create HMAC keys for a service account")
+	}
+
+	store := storage.NewStorage(*storageDir)
+	syncer := remote.NewSyncer(store, remote.NewClient(loc, creds))
+	opts := remote.Options{DryRun: *dryRun, Force: *force}
+
+	verb, done := "Pushing to", "Uploaded"
+	if direction == "pull" {
+		verb, done = "Pulling from", "Downloaded"
+	}
+	if *dryRun {
+		done = "Would transfer"
+	}
+	spinner := ui.NewSpinner(fmt.Sprintf("%s %s", verb, loc))
+	spinner.Start()
+	var report *remote.Report
+	if direction == "pull" {
+		report, err = syncer.Pull(opts)
+	} else {
+		report, err = syncer.Push(opts)
+	}
+	spinner.Stop()
+	if report != nil {
+		printSyncReport(report, done)
+	}
+	if err != nil {
+		return ui.NewError(fmt.Sprintf("Failed to sync with %s", loc), err,
+			"Check the bucket name, region and credentials",
+			"Items transferred before the failure are kept; run the command again to resume")
+	}
+
+	if len(report.Conflicts) > 0 {
+		ui.PrintWarning("%d conflicting items were skipped; use -force to replace them", len(report.Conflicts))
+		return nil
+	}
+	ui.PrintSuccess("%s %d items, %d unchanged", done, len(report.Transferred), report.Unchanged)
+	return nil
+}
+
+// printSyncReport lists the transferred and conflicting items of a sync
+func printSyncReport(report *remote.Report, done string) {
+	for _, item := range report.Transferred {
+		fmt.Printf("  %s %s\n", done, item)
+	}
+	for _, conflict := range report.Conflicts {
+		ui.PrintWarning("Conflict on %s: %s", conflict.Item, conflict.Reason)
+	}
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/alenon/gokanon/internal/telemetry"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// Telemetry handles the 'telemetry' subcommand, turning the anonymous usage
+// counts on or off and showing what would be sent
+func Telemetry(version string) error {
+	if len(os.Args) < 3 {
+		fmt.Println("Anonymous usage telemetry:")
+		fmt.Println()
+		fmt.Println("Usage:")
+		fmt.Println("  gokanon telemetry <subcommand>")
+		fmt.Println()
+		fmt.Println("Subcommands:")
+		fmt.Println("  on       Count which commands and export formats are used and report them daily")
+		fmt.Println("  off      Stop counting and delete the counts not reported yet")
+		fmt.Println("  status   Show whether telemetry is on and the exact report that would be sent")
+		fmt.Println()
+		fmt.Println("Telemetry is off unless turned on. It records no benchmark results, names,")
+		fmt.Println("paths or arguments. GOKANON_TELEMETRY=off or DO_NOT_TRACK=1 turn it off")
+		fmt.Println("regardless of the setting.")
+		fmt.Println()
+		return nil
+	}
+
+	collector := telemetry.Default(version)
+	subcommand := os.Args[2]
+	switch subcommand {
+	case "on":
+		settings, err := collector.Enable()
+		if err != nil {
+			return ui.NewError("Failed to turn telemetry on", err, "Set $XDG_CONFIG_HOME or $HOME")
+		}
+		ui.PrintSuccess("Telemetry is on, thank you!")
+		fmt.Printf("  Counted: commands run and export formats, under the random ID %s\n", settings.ID)
+		fmt.Println("  Inspect the report before it is sent with: gokanon telemetry status")
+		if source := telemetry.OptOut(); source != "" {
+			ui.PrintWarning("%s is set, so nothing is counted in this environment", source)
+		}
+		return nil
+	case "off":
+		if err := collector.Disable(); err != nil {
+			return ui.NewError("Failed to turn telemetry off", err)
+		}
+		ui.PrintSuccess("Telemetry is off; counts not reported yet were deleted")
+		return nil
+	case "status":
+		return telemetryStatus(collector)
+	default:
+		return ui.NewError(
+			fmt.Sprintf("Unknown telemetry subcommand: %s", subcommand),
+			nil,
+			"Valid subcommands: on, off, status",
+			"Run 'gokanon telemetry' to see usage",
+		)
+	}
+}
+
+// telemetryStatus prints the setting, where reports go, and the pending
+// report as it would be sent
+func telemetryStatus(collector *telemetry.Collector) error {
+	settings, err := collector.Settings()
+	if err != nil {
+		return err
+	}
+
+	switch source := telemetry.OptOut(); {
+	case !settings.Enabled:
+		fmt.Printf("Telemetry: %s\n", ui.Bold("off"))
+	case source != "":
+		fmt.Printf("Telemetry: %s (turned on, but %s is set)\n", ui.Bold("off"), source)
+	default:
+		fmt.Printf("Telemetry: %s since %s\n", ui.Bold("on"), settings.EnabledAt.Format("2006-01-02"))
+	}
+	if collector.Endpoint != "" {
+		fmt.Printf("Endpoint:  %s, every %s\n", collector.Endpoint, telemetry.SendInterval)
+	} else {
+		fmt.Printf("Endpoint:  %s\n", ui.Dim("none in this build; usage is only counted locally"))
+	}
+	fmt.Printf("Settings:  %s\n", collector.SettingsPath)
+	fmt.Printf("Report:    %s\n", collector.ReportPath)
+
+	report, err := collector.Pending()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	if report == nil {
+		ui.PrintInfo("Nothing counted since the last report")
+		return nil
+	}
+	fmt.Println("Next report, exactly as it would be sent:")
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -3,22 +3,52 @@ package commands
 import (
 	"flag"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
 	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/ui"
 )
 
 // Trend handles the 'trend' subcommand
 func Trend() error {
 	trendFlags := flag.NewFlagSet("trend", flag.ExitOnError)
-	storageDir := trendFlags.String("storage", ".gokanon", "Storage directory for results")
+	storageDir := trendFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
 	lastN := trendFlags.Int("last", 10, "Analyze last N runs")
 	benchmark := trendFlags.String("benchmark", "", "Specific benchmark to analyze (empty = all)")
-	trendFlags.Parse(os.Args[2:])
+	metric := trendFlags.String("metric", "ns/op", "Metric to analyze (ns/op or a custom metric name)")
+	trendFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	normalize := trendFlags.String("normalize", "", "Divide ns/op by this reference benchmark of each run (default: normalization.reference from the config)")
+	sparkline := trendFlags.Bool("sparkline", false, "Print a compact sparkline per benchmark instead of the full analysis")
+	includeFailed := trendFlags.Bool("include-failed", false, "Include runs that terminated abnormally")
+	anomalies := trendFlags.Bool("anomalies", false, "Flag runs deviating from the runs before them, with the commit that introduced the spike")
+	anomalyMethod := trendFlags.String("anomaly-method", stats.AnomalyStdDev, "How runs are compared with the window before them: stddev, iqr or median (rolling median)")
+	anomalyWindow := trendFlags.Int("anomaly-window", stats.DefaultAnomalyWindow, "Number of preceding runs each run is compared with")
+	anomalyThreshold := trendFlags.Float64("k", 0, "Deviation flagged as an anomaly, in standard deviations, IQRs or MADs (default: 3, or 1.5 for iqr)")
+	cfg, err := parseFlags(trendFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
 
+	if *anomalies {
+		// Invalid options fail before any output
+		if _, err := stats.DetectAnomalies(nil, *anomalyMethod, *anomalyWindow, *anomalyThreshold); err != nil {
+			return err
+		}
+	}
+
+	// Only the last N runs are loaded
 	store := storage.NewStorage(*storageDir)
-	runs, err := store.List()
+	runs, err := store.Latest(*lastN, func(run *models.BenchmarkRun) bool {
+		return *includeFailed || !run.Failed() && !run.Degraded()
+	})
 	if err != nil {
 		return fmt.Errorf("failed to list results: %w", err)
 	}
@@ -27,37 +57,106 @@ func Trend() error {
 		return fmt.Errorf("need at least 2 benchmark runs for trend analysis")
 	}
 
-	// Limit to last N runs
-	if *lastN > 0 && *lastN < len(runs) {
-		runs = runs[:*lastN]
-	}
-
 	// Reverse to get chronological order
 	for i := 0; i < len(runs)/2; i++ {
 		runs[i], runs[len(runs)-1-i] = runs[len(runs)-1-i], runs[i]
 	}
 
+	// The score is computed from raw ns/op before any normalization
+	var scoreTrend *stats.TrendAnalysis
+	var scores []float64
+	if *benchmark == "" && *metric == "ns/op" {
+		scoreTrend = stats.NewAnalyzer().AnalyzeScoreTrend(runs, cfg.Score.Weights)
+		for i := range runs {
+			if score, ok := stats.Score(&runs[i], cfg.Score.Weights); ok {
+				scores = append(scores, score)
+			}
+		}
+	}
+
+	// Named baselines are shown as reference values for each benchmark
+	baselines, err := store.ListBaselines()
+	if err != nil {
+		return fmt.Errorf("failed to list baselines: %w", err)
+	}
+
+	// Normalize runs to their reference benchmark. Runs recorded before the
+	// reference benchmark existed cannot be normalized and are left out.
+	unit := *metric
+	reference := *normalize
+	if reference == "" {
+		reference = cfg.Normalization.Reference
+	}
+	if reference != "" {
+		var normalized []models.BenchmarkRun
+		for i := range runs {
+			n, err := compare.Normalize(&runs[i], reference)
+			if err != nil {
+				continue
+			}
+			normalized = append(normalized, *n)
+		}
+		if skipped := len(runs) - len(normalized); skipped > 0 {
+			ui.PrintWarning("%d run(s) without reference benchmark %s left out", skipped, reference)
+		}
+		if len(normalized) < 2 {
+			return fmt.Errorf("need at least 2 runs with reference benchmark %s for trend analysis", reference)
+		}
+		runs = normalized
+		baselines = normalizeBaselines(baselines, reference)
+		if *metric == "ns/op" {
+			unit = compare.NormalizedUnit
+		}
+	}
+
 	fmt.Printf("Performance Trend Analysis (%d runs)\n", len(runs))
 	fmt.Printf("Period: %s to %s\n\n",
 		runs[0].Timestamp.Format("2006-01-02 15:04:05"),
 		runs[len(runs)-1].Timestamp.Format("2006-01-02 15:04:05"),
 	)
 
+	if scoreTrend != nil && !*sparkline {
+		printScoreTrend(scoreTrend, scores)
+	}
+
 	analyzer := stats.NewAnalyzer()
 
-	// Get all unique benchmark names
-	benchmarkNames := make(map[string]bool)
+	// The whole history, beyond the last N runs, comes from the statistics
+	// cache. It holds raw ns/op.
+	var aggregates *storage.Aggregates
+	if *metric == "ns/op" && reference == "" && !*sparkline {
+		if aggregates, err = store.Aggregates(); err != nil {
+			ui.PrintWarning("Historical statistics unavailable: %v", err)
+		}
+	}
+
+	// Get all unique benchmark names with the benchmark function they
+	// belong to. -benchmark selects a benchmark or a whole family.
+	families := make(map[string]string)
 	for _, run := range runs {
 		for _, result := range run.Results {
-			if *benchmark == "" || result.Name == *benchmark {
-				benchmarkNames[result.Name] = true
+			if !result.Measured() {
+				continue
+			}
+			if *benchmark == "" || result.Name == *benchmark || result.Family() == *benchmark {
+				families[result.Name] = result.Family()
 			}
 		}
 	}
+	names := familyOrder(families)
 
-	// Analyze trend for each benchmark
-	for name := range benchmarkNames {
-		trend := analyzer.AnalyzeTrend(runs, name)
+	if *sparkline {
+		printSparklines(runs, names, *metric, unit, scores)
+		return nil
+	}
+
+	// Analyze trend for each benchmark, variants of a family together
+	for i, name := range names {
+		if family := families[name]; i == 0 || families[names[i-1]] != family {
+			printFamily(runs, family, names[i:], families, *metric, unit)
+		}
+
+		trend := analyzer.AnalyzeMetricTrend(runs, name, *metric)
 		if trend == nil {
 			continue
 		}
@@ -78,26 +177,19 @@ func Trend() error {
 			directionColor = "⚪"
 		}
 
-		fmt.Printf("  %s Trend: %s %s (slope: %.2f ns/op per run)\n",
+		fmt.Printf("  %s Trend: %s %s (slope: %.2f %s per run)\n",
 			directionColor,
 			trend.Direction,
 			directionSymbol,
 			trend.TrendLine,
+			unit,
 		)
 
 		fmt.Printf("  Confidence: %.1f%% (R²)\n", trend.Confidence*100)
 
 		// Show data points
 		fmt.Printf("  Data points: ")
-		var values []float64
-		for _, run := range runs {
-			for _, result := range run.Results {
-				if result.Name == name {
-					values = append(values, result.NsPerOp)
-					break
-				}
-			}
-		}
+		values := benchmarkValues(runs, name, *metric)
 
 		// Show sparkline-like representation
 		if len(values) > 0 {
@@ -121,6 +213,15 @@ func Trend() error {
 				}
 			}
 			fmt.Println()
+			printBaselineDrift(baselines, name, *metric, values[len(values)-1])
+		}
+		if aggregates != nil && aggregates.Benchmarks[name] != nil && aggregates.Benchmarks[name].Count >= 2 {
+			s := stats.FromAggregate(name, aggregates.Benchmarks[name])
+			fmt.Printf("  All runs: mean %.2f %s, CV %.1f%%, range %.2f to %.2f over %d runs\n",
+				s.Mean, unit, s.CV, s.Min, s.Max, s.Count)
+		}
+		if *anomalies {
+			printAnomalies(runs, name, *metric, unit, *anomalyMethod, *anomalyWindow, *anomalyThreshold)
 		}
 
 		fmt.Println()
@@ -128,3 +229,228 @@ func Trend() error {
 
 	return nil
 }
+
+// benchmarkValues returns the values of a benchmark's metric in the runs
+// that measured it, in run order
+func benchmarkValues(runs []models.BenchmarkRun, name, metric string) []float64 {
+	var values []float64
+	for _, run := range runs {
+		for _, result := range run.Results {
+			if result.Name == name && result.Measured() {
+				if v, ok := stats.MetricValue(result, metric); ok {
+					values = append(values, v)
+				}
+				break
+			}
+		}
+	}
+	return values
+}
+
+// printAnomalies prints the runs whose value of a benchmark's metric
+// deviates from the runs before them, with the commit and time that
+// introduced each spike or drop
+func printAnomalies(runs []models.BenchmarkRun, name, metric, unit, method string, window int, threshold float64) {
+	var values []float64
+	var measured []*models.BenchmarkRun
+	for i := range runs {
+		for _, result := range runs[i].Results {
+			if result.Name == name && result.Measured() {
+				if v, ok := stats.MetricValue(result, metric); ok {
+					values = append(values, v)
+					measured = append(measured, &runs[i])
+				}
+				break
+			}
+		}
+	}
+
+	anomalies, err := stats.DetectAnomalies(values, method, window, threshold)
+	if err != nil {
+		ui.PrintWarning("%v", err)
+		return
+	}
+	if len(anomalies) == 0 {
+		fmt.Printf("  Anomalies: none (%s)\n", method)
+		return
+	}
+	for _, anomaly := range anomalies {
+		run := measured[anomaly.Index]
+		kind := "spike"
+		if anomaly.Score < 0 {
+			kind = "drop"
+		}
+		introduced := run.Timestamp.Format("2006-01-02 15:04:05")
+		if run.Commit != "" {
+			introduced += ", commit " + shortCommit(run.Commit)
+			if run.CommitMessage != "" {
+				introduced += fmt.Sprintf(" %q", run.CommitMessage)
+			}
+		}
+		fmt.Printf("  ⚠️  Anomaly: %s in run %s (%s): %.2f %s, %+.1f %s from %.2f\n",
+			kind, run.ID, introduced, anomaly.Value, unit, anomaly.Score, anomalyUnit(method), anomaly.Expected)
+	}
+}
+
+// anomalyUnit names the unit of the deviations of an anomaly method
+func anomalyUnit(method string) string {
+	switch method {
+	case stats.AnomalyIQR:
+		return "IQR"
+	case stats.AnomalyMedian:
+		return "MAD"
+	}
+	return "σ"
+}
+
+// familyOrder returns the benchmark names sorted by family and then by
+// name, so that the variants of a benchmark are listed together
+func familyOrder(families map[string]string) []string {
+	return slices.SortedFunc(maps.Keys(families), func(a, b string) int {
+		if c := strings.Compare(families[a], families[b]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+// printFamily prints a header for a benchmark family with more than one
+// variant, comparing the latest value of each variant to the fastest one.
+// names starts with the first variant of the family.
+func printFamily(runs []models.BenchmarkRun, family string, names []string, families map[string]string, metric, unit string) {
+	var variants []string
+	for _, name := range names {
+		if families[name] != family {
+			break
+		}
+		variants = append(variants, name)
+	}
+	if len(variants) < 2 {
+		return
+	}
+
+	latest := make(map[string]float64)
+	fastest := 0.0
+	for _, name := range variants {
+		values := benchmarkValues(runs, name, metric)
+		if len(values) == 0 {
+			continue
+		}
+		latest[name] = values[len(values)-1]
+		if fastest == 0 || latest[name] < fastest {
+			fastest = latest[name]
+		}
+	}
+
+	fmt.Printf("Family: %s (%d variants)\n", family, len(variants))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, name := range variants {
+		value, ok := latest[name]
+		if !ok {
+			continue
+		}
+		label := name
+		if _, variant := models.SplitBenchmarkName(name); variant != "" {
+			label = variant
+		}
+		if fastest > 0 {
+			fmt.Fprintf(w, "  %s\t%.2f %s\t%.2fx\n", label, value, unit, value/fastest)
+		} else {
+			fmt.Fprintf(w, "  %s\t%.2f %s\n", label, value, unit)
+		}
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+// printSparklines prints one sparkline per benchmark, variants of a
+// family together, with the minimum, maximum and latest values. The score
+// is shown first.
+func printSparklines(runs []models.BenchmarkRun, names []string, metric, unit string, scores []float64) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(scores) > 0 {
+		fmt.Fprintf(w, "Score\t%s\tmin %s\tmax %s\tlatest %s %s\n",
+			ui.Sparkline(scores),
+			stats.FormatScore(slices.Min(scores)),
+			stats.FormatScore(slices.Max(scores)),
+			stats.FormatScore(scores[len(scores)-1]),
+			stats.ScoreUnit,
+		)
+	}
+	for _, name := range names {
+		values := benchmarkValues(runs, name, metric)
+		if len(values) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\tmin %.2f\tmax %.2f\tlatest %.2f %s\n",
+			name,
+			ui.Sparkline(values),
+			slices.Min(values),
+			slices.Max(values),
+			values[len(values)-1],
+			unit,
+		)
+	}
+	w.Flush()
+}
+
+// normalizeBaselines normalizes the runs of baselines to a reference
+// benchmark, leaving out baselines whose run lacks it
+func normalizeBaselines(baselines []models.Baseline, reference string) []models.Baseline {
+	var normalized []models.Baseline
+	for _, baseline := range baselines {
+		if baseline.Run == nil {
+			continue
+		}
+		run, err := compare.Normalize(baseline.Run, reference)
+		if err != nil {
+			continue
+		}
+		baseline.Run = run
+		normalized = append(normalized, baseline)
+	}
+	return normalized
+}
+
+// printBaselineDrift prints how far the latest value of a benchmark has
+// drifted from its value in each named baseline
+func printBaselineDrift(baselines []models.Baseline, name, metric string, current float64) {
+	for _, baseline := range baselines {
+		if baseline.Run == nil {
+			continue
+		}
+		for _, result := range baseline.Run.Results {
+			if result.Name != name || !result.Measured() {
+				continue
+			}
+			if v, ok := stats.MetricValue(result, metric); ok && v != 0 {
+				fmt.Printf("  Baseline %s: %.2f → %.2f (%+.1f%%)\n",
+					baseline.Name, v, current, (current-v)/v*100)
+			}
+			break
+		}
+	}
+}
+
+// printScoreTrend prints the headline performance score across runs
+func printScoreTrend(trend *stats.TrendAnalysis, scores []float64) {
+	directionSymbol, directionColor := "→", "⚪"
+	switch trend.Direction {
+	case "improving":
+		directionSymbol, directionColor = "↑", "🟢"
+	case "degrading":
+		directionSymbol, directionColor = "↓", "🔴"
+	}
+
+	first, last := scores[0], scores[len(scores)-1]
+	fmt.Printf("Score (%s, higher is better)\n", stats.ScoreUnit)
+	fmt.Printf("  %s %s → %s (%+.1f%%), trend: %s %s\n",
+		directionColor,
+		stats.FormatScore(first),
+		stats.FormatScore(last),
+		(last-first)/first*100,
+		trend.Direction,
+		directionSymbol,
+	)
+	fmt.Printf("  Confidence: %.1f%% (R²)\n\n", trend.Confidence*100)
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/tui"
+	"github.com/alenon/gokanon/internal/ui"
+)
+
+// TUI handles the 'tui' subcommand
+func TUI() error {
+	tuiFlags := flag.NewFlagSet("tui", flag.ExitOnError)
+	storageDir := tuiFlags.String("storage", config.DefaultStorageDir(), "Storage directory for results")
+	tuiFlags.String("config", config.DefaultPath(), "Path to the project configuration file")
+	lastN := tuiFlags.Int("last", 0, "Only list the last N runs (0 = all)")
+	window := tuiFlags.Int("window", 10, "Runs each trend covers, ending with the selected run")
+	includeFailed := tuiFlags.Bool("include-failed", false, "Include runs that terminated abnormally")
+	times := addTimeFlags(tuiFlags, "relative")
+	cfg, err := parseFlags(tuiFlags, os.Args[2:])
+	if err != nil {
+		return err
+	}
+
+	timeFormat, err := times.parse()
+	if err != nil {
+		return err
+	}
+	if *window < 2 {
+		return ui.NewError(fmt.Sprintf("Invalid -window: %d", *window), nil, "A trend needs at least 2 runs, e.g. -window=20")
+	}
+	comparer, err := newComparer(cfg)
+	if err != nil {
+		return err
+	}
+
+	runs, err := storage.NewStorage(*storageDir).List()
+	if err != nil {
+		return fmt.Errorf("failed to list results: %w", err)
+	}
+	if !*includeFailed {
+		runs = models.WithoutFailed(runs)
+	}
+	if len(runs) == 0 {
+		return ui.ErrNoResults()
+	}
+	if *lastN > 0 && *lastN < len(runs) {
+		runs = runs[:*lastN]
+	}
+
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ui.NewError("The terminal UI needs a terminal", nil,
+			"Run it in an interactive terminal",
+			"For output to files or pipes, use 'gokanon trend' or 'gokanon compare'")
+	}
+	return tui.Run(runs, tui.Options{Comparer: comparer, TimeFormat: timeFormat, Window: *window})
+}
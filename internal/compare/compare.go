@@ -3,72 +3,332 @@ package compare
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 
 	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
 )
 
 // Comparer handles benchmark comparison
 type Comparer struct {
-	threshold float64 // Threshold percentage to consider "same"
+	threshold float64    // Threshold percentage to consider "same"
+	rules     MatchRules // Rules for pairing benchmarks across runs
 }
 
 // NewComparer creates a new comparer with default threshold
 func NewComparer() *Comparer {
 	return &Comparer{
 		threshold: 5.0, // 5% threshold
+		rules:     DefaultMatchRules(),
 	}
 }
 
-// Compare compares two benchmark runs and returns comparisons for matching benchmarks
-func (c *Comparer) Compare(oldRun, newRun *models.BenchmarkRun) []models.Comparison {
-	// Create a map of old results for quick lookup
-	oldResults := make(map[string]models.BenchmarkResult)
-	for _, result := range oldRun.Results {
-		oldResults[result.Name] = result
-	}
+// WithMatchRules sets the rules used to pair benchmarks across runs
+func (c *Comparer) WithMatchRules(rules MatchRules) *Comparer {
+	c.rules = rules
+	return c
+}
 
+// Compare compares two benchmark runs and returns comparisons for matching benchmarks.
+// Benchmarks that failed or were skipped in the new run are reported with
+// that status; benchmarks without measurements in the old run are skipped.
+// Benchmarks present in only one run are appended with status "added" or
+// "removed", so a skipped benchmark is never mistaken for a deleted one.
+//
+// Runs normalized with Normalize are compared on their relative scores;
+// the comparisons then carry NormalizedUnit.
+func (c *Comparer) Compare(oldRun, newRun *models.BenchmarkRun) []models.Comparison {
 	var comparisons []models.Comparison
+	memory := recordsMemory(oldRun) && recordsMemory(newRun)
+	// Normalized runs keep delays in nanoseconds, which other machines do
+	// not share
+	contention := recordsContention(oldRun) && sameContentionProfiles(oldRun, newRun) && newRun.NormalizedTo == ""
 
-	// Compare each new result with corresponding old result
-	for _, newResult := range newRun.Results {
-		oldResult, exists := oldResults[newResult.Name]
-		if !exists {
-			continue // Skip benchmarks that don't exist in old run
+	for _, m := range c.Match(oldRun, newRun) {
+		switch {
+		case m.Old == nil:
+			comparisons = append(comparisons, models.Comparison{
+				Name:       m.New.Name,
+				Package:    m.New.Package,
+				NewNsPerOp: m.New.NsPerOp,
+				Status:     models.StatusAdded,
+				Message:    m.New.Message,
+				Doc:        m.New.Doc,
+			})
+		case m.New == nil:
+			comparisons = append(comparisons, models.Comparison{
+				Name:       m.Old.Name,
+				Package:    m.Old.Package,
+				OldNsPerOp: m.Old.NsPerOp,
+				Status:     models.StatusRemoved,
+				Doc:        m.Old.Doc,
+			})
+		case m.Old.Measured():
+			comp := c.compareResults(*m.Old, *m.New)
+			if memory && m.New.Measured() {
+				comp.BytesPerOp = c.compareStandardMetric("B/op", float64(m.Old.BytesPerOp), float64(m.New.BytesPerOp), false)
+				comp.AllocsPerOp = c.compareStandardMetric("allocs/op", float64(m.Old.AllocsPerOp), float64(m.New.AllocsPerOp), false)
+			}
+			if contention && m.New.Measured() {
+				comp.Contention = compareContention(m.Old.ContentionNsPerOp, m.New.ContentionNsPerOp, m.New.NsPerOp)
+			}
+			comparisons = append(comparisons, comp)
 		}
+		// Otherwise there is no baseline measurement to compare against
+	}
 
-		comparison := c.compareResults(oldResult, newResult)
-		comparisons = append(comparisons, comparison)
+	if newRun.NormalizedTo != "" {
+		for i := range comparisons {
+			comparisons[i].Unit = NormalizedUnit
+		}
 	}
 
 	return comparisons
 }
 
+// Split separates comparisons of matching benchmarks from benchmarks that
+// were added to or removed from the new run
+func Split(comparisons []models.Comparison) (matched, added, removed []models.Comparison) {
+	for _, comp := range comparisons {
+		switch comp.Status {
+		case models.StatusAdded:
+			added = append(added, comp)
+		case models.StatusRemoved:
+			removed = append(removed, comp)
+		default:
+			matched = append(matched, comp)
+		}
+	}
+	return matched, added, removed
+}
+
+// trimProcs strips the GOMAXPROCS suffix from a benchmark name, e.g. "Foo-8" -> "Foo"
+func trimProcs(name string) string {
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 || i == len(name)-1 {
+		return name
+	}
+	for _, r := range name[i+1:] {
+		if r < '0' || r > '9' {
+			return name
+		}
+	}
+	return name[:i]
+}
+
+// resultDoc returns the current doc of a benchmark, falling back to the
+// doc recorded with the old result
+func resultDoc(old, new models.BenchmarkResult) string {
+	if new.Doc != "" {
+		return new.Doc
+	}
+	return old.Doc
+}
+
 // compareResults compares two individual benchmark results
 func (c *Comparer) compareResults(old, new models.BenchmarkResult) models.Comparison {
-	delta := new.NsPerOp - old.NsPerOp
-	deltaPercent := (delta / old.NsPerOp) * 100
-
-	status := "same"
-	if math.Abs(deltaPercent) > c.threshold {
-		if deltaPercent < 0 {
-			status = "improved" // Lower is better
-		} else {
-			status = "degraded"
+	if !new.Measured() {
+		return models.Comparison{
+			Name:       new.Name,
+			Package:    new.Package,
+			OldNsPerOp: old.NsPerOp,
+			Status:     new.Status,
+			Message:    new.Message,
+			Doc:        resultDoc(old, new),
 		}
 	}
 
-	return models.Comparison{
+	delta := new.NsPerOp - old.NsPerOp
+	deltaPercent := (delta / old.NsPerOp) * 100
+
+	comp := models.Comparison{
 		Name:         new.Name,
+		Package:      new.Package,
 		OldNsPerOp:   old.NsPerOp,
 		NewNsPerOp:   new.NsPerOp,
 		Delta:        delta,
 		DeltaPercent: deltaPercent,
-		Status:       status,
+		Status:       "same",
+		Doc:          resultDoc(old, new),
+		Metrics:      compareMetrics(old.Metrics, new.Metrics),
+		Confidence:   stats.WorseGrade(old.Confidence, new.Confidence),
+	}
+	if old.MBPerSec > 0 && new.MBPerSec > 0 {
+		comp.MBPerSec = c.compareStandardMetric("MB/s", old.MBPerSec, new.MBPerSec, true)
+	}
+
+	// With repeated measurements, a change only counts when the samples
+	// differ significantly
+	if len(old.Samples) > 1 && len(new.Samples) > 1 {
+		_, comp.OldCI = stats.MeanCI(old.Samples)
+		_, comp.NewCI = stats.MeanCI(new.Samples)
+		comp.OldSamples, comp.NewSamples = len(old.Samples), len(new.Samples)
+		_, p := stats.MannWhitneyU(old.Samples, new.Samples)
+		comp.PValue = &p
+	}
+
+	if math.Abs(deltaPercent) > c.threshold && comp.Significant() {
+		if deltaPercent < 0 {
+			comp.Status = "improved" // Lower is better
+		} else {
+			comp.Status = "degraded"
+		}
+	}
+
+	return comp
+}
+
+// recordsMemory reports whether a run was measured with -benchmem. A
+// zero-allocation benchmark reports 0 B/op and 0 allocs/op, just like one
+// run without -benchmem, so the run's command or other results tell.
+func recordsMemory(run *models.BenchmarkRun) bool {
+	if strings.Contains(run.Command, "-benchmem") {
+		return true
+	}
+	for _, result := range run.Results {
+		if result.BytesPerOp != 0 || result.AllocsPerOp != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// recordsContention reports whether a run profiled where goroutines
+// blocked or waited for mutexes
+func recordsContention(run *models.BenchmarkRun) bool {
+	return run.BlockProfile != "" || run.MutexProfile != ""
+}
+
+// sameContentionProfiles reports whether two runs profiled the same kinds
+// of contention, as the block delay alone is not comparable to the block
+// and mutex delay
+func sameContentionProfiles(oldRun, newRun *models.BenchmarkRun) bool {
+	return (oldRun.BlockProfile != "") == (newRun.BlockProfile != "") &&
+		(oldRun.MutexProfile != "") == (newRun.MutexProfile != "")
+}
+
+// ContentionThreshold is the percentage by which the contention per
+// operation must change to count. Sampled block and mutex delays are far
+// noisier than timings, so it is wider than the comparer's threshold.
+const ContentionThreshold = 25.0
+
+// contentionFloor is the fraction of the new ns/op below which a change of
+// contention per operation is considered noise
+const contentionFloor = 0.01
+
+// compareContention compares the block and mutex delay per operation of a
+// benchmark, lower being better. Changes within ContentionThreshold or
+// smaller than a hundredth of the new ns/op are "same".
+func compareContention(old, new, nsPerOp float64) *models.MetricComparison {
+	metric := &models.MetricComparison{
+		Name:   models.ContentionMetric,
+		Old:    old,
+		New:    new,
+		Delta:  new - old,
+		Status: "same",
+	}
+	if old != 0 {
+		metric.DeltaPercent = (metric.Delta / old) * 100
+	}
+	if math.Abs(metric.Delta) < nsPerOp*contentionFloor {
+		return metric
+	}
+	if old != 0 && math.Abs(metric.DeltaPercent) <= ContentionThreshold {
+		return metric
+	}
+	if metric.Delta < 0 {
+		metric.Status = "improved"
+	} else {
+		metric.Status = "degraded"
+	}
+	return metric
+}
+
+// compareStandardMetric compares a standard metric, changes within the
+// comparer's threshold being "same". Lower values are better unless
+// higherIsBetter. A metric rising from zero, such as a benchmark that
+// starts allocating, has no percentage and always changes status.
+func (c *Comparer) compareStandardMetric(name string, old, new float64, higherIsBetter bool) *models.MetricComparison {
+	metric := &models.MetricComparison{
+		Name:   name,
+		Old:    old,
+		New:    new,
+		Delta:  new - old,
+		Status: "same",
+	}
+	if old != 0 {
+		metric.DeltaPercent = (metric.Delta / old) * 100
+		if math.Abs(metric.DeltaPercent) <= c.threshold {
+			return metric
+		}
+	} else if new == 0 {
+		return metric
+	}
+
+	if (metric.Delta < 0) != higherIsBetter {
+		metric.Status = "improved"
+	} else {
+		metric.Status = "degraded"
 	}
+	return metric
+}
+
+// compareMetrics compares the custom metrics present in both results.
+// Whether an increase is good depends on the metric, so no status is assigned.
+func compareMetrics(old, new map[string]float64) []models.MetricComparison {
+	var names []string
+	for name := range new {
+		if _, ok := old[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var metrics []models.MetricComparison
+	for _, name := range names {
+		delta := new[name] - old[name]
+		deltaPercent := 0.0
+		if old[name] != 0 {
+			deltaPercent = (delta / math.Abs(old[name])) * 100
+		}
+		metrics = append(metrics, models.MetricComparison{
+			Name:         name,
+			Old:          old[name],
+			New:          new[name],
+			Delta:        delta,
+			DeltaPercent: deltaPercent,
+		})
+	}
+	return metrics
 }
 
 // FormatComparison formats a comparison for display
 func FormatComparison(comp models.Comparison) string {
+	unit := comp.ValueUnit()
+	switch comp.Status {
+	case models.StatusAdded:
+		line := fmt.Sprintf("+ %-40s %12.2f %s", comp.Name, comp.NewNsPerOp, unit)
+		if comp.Message != "" {
+			line += " (" + comp.Message + ")"
+		}
+		return line
+	case models.StatusRemoved:
+		return fmt.Sprintf("- %-40s %12.2f %s", comp.Name, comp.OldNsPerOp, unit)
+	case models.StatusFailed, models.StatusSkipped:
+		line := fmt.Sprintf("%s %-40s %12.2f %s → %s",
+			"!",
+			comp.Name,
+			comp.OldNsPerOp,
+			unit,
+			strings.ToUpper(comp.Status),
+		)
+		if comp.Message != "" {
+			line += ": " + comp.Message
+		}
+		return line
+	}
+
 	statusSymbol := "~"
 	switch comp.Status {
 	case "improved":
@@ -77,13 +337,59 @@ func FormatComparison(comp models.Comparison) string {
 		statusSymbol = "✗"
 	}
 
-	return fmt.Sprintf("%s %-40s %12.2f ns/op → %12.2f ns/op (%+.2f%%)",
+	line := fmt.Sprintf("%s %-40s %12.2f %s → %12.2f %s (%+.2f%%)",
 		statusSymbol,
 		comp.Name,
 		comp.OldNsPerOp,
+		unit,
 		comp.NewNsPerOp,
+		unit,
 		comp.DeltaPercent,
 	)
+	if comp.PValue != nil {
+		line += fmt.Sprintf(" ±%s → ±%s p=%.3f n=%d+%d",
+			ciPercent(comp.OldCI, comp.OldNsPerOp),
+			ciPercent(comp.NewCI, comp.NewNsPerOp),
+			*comp.PValue,
+			comp.OldSamples,
+			comp.NewSamples,
+		)
+	}
+	if comp.Confidence != "" {
+		line += fmt.Sprintf(" [grade %s]", comp.Confidence)
+	}
+	return line
+}
+
+// ciPercent formats a confidence interval half-width relative to the mean
+func ciPercent(halfWidth, mean float64) string {
+	if mean == 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%.1f%%", halfWidth/mean*100)
+}
+
+// FormatMetricComparison formats a custom or standard metric comparison
+// for display. Standard metrics are marked with their status.
+func FormatMetricComparison(metric models.MetricComparison) string {
+	symbol := " "
+	switch metric.Status {
+	case "improved":
+		symbol = "✓"
+	case "degraded":
+		symbol = "✗"
+	}
+	change := fmt.Sprintf("%+.2f%%", metric.DeltaPercent)
+	if metric.Old == 0 && metric.New != 0 {
+		change = "from 0"
+	}
+	return fmt.Sprintf("  %s %-38s %12.4g → %12.4g (%s)",
+		symbol,
+		metric.Name,
+		metric.Old,
+		metric.New,
+		change,
+	)
 }
 
 // Summary provides a summary of the comparison
@@ -91,8 +397,20 @@ func Summary(comparisons []models.Comparison) string {
 	improved := 0
 	degraded := 0
 	same := 0
+	failed := 0
+	skipped := 0
+	added := 0
+	removed := 0
+	memory := 0
+	contention := 0
 
 	for _, comp := range comparisons {
+		if MemoryDegraded(comp) {
+			memory++
+		}
+		if ContentionDegraded(comp) {
+			contention++
+		}
 		switch comp.Status {
 		case "improved":
 			improved++
@@ -100,9 +418,91 @@ func Summary(comparisons []models.Comparison) string {
 			degraded++
 		case "same":
 			same++
+		case models.StatusFailed:
+			failed++
+		case models.StatusSkipped:
+			skipped++
+		case models.StatusAdded:
+			added++
+		case models.StatusRemoved:
+			removed++
 		}
 	}
 
-	return fmt.Sprintf("Summary: %d improved, %d degraded, %d unchanged",
+	summary := fmt.Sprintf("Summary: %d improved, %d degraded, %d unchanged",
 		improved, degraded, same)
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d failed", failed)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	if added > 0 {
+		summary += fmt.Sprintf(", %d added", added)
+	}
+	if removed > 0 {
+		summary += fmt.Sprintf(", %d removed", removed)
+	}
+	if memory > 0 {
+		summary += fmt.Sprintf(", %d with more memory use", memory)
+	}
+	if contention > 0 {
+		summary += fmt.Sprintf(", %d with more contention", contention)
+	}
+	return summary
+}
+
+// SummaryTopN is the number of regressions and improvements listed in
+// summaries of a comparison
+const SummaryTopN = 5
+
+// TopChanges returns up to n degraded benchmarks, the largest slowdowns
+// first, and up to n improved benchmarks, the largest speedups first
+func TopChanges(comparisons []models.Comparison, n int) (regressions, improvements []models.Comparison) {
+	for _, comp := range comparisons {
+		switch comp.Status {
+		case "degraded":
+			regressions = append(regressions, comp)
+		case "improved":
+			improvements = append(improvements, comp)
+		}
+	}
+	sort.SliceStable(regressions, func(i, j int) bool {
+		return regressions[i].DeltaPercent > regressions[j].DeltaPercent
+	})
+	sort.SliceStable(improvements, func(i, j int) bool {
+		return improvements[i].DeltaPercent < improvements[j].DeltaPercent
+	})
+	return regressions[:min(n, len(regressions))], improvements[:min(n, len(improvements))]
+}
+
+// MemoryDegraded reports whether the B/op or allocs/op of a benchmark
+// degraded, whatever its timing did
+func MemoryDegraded(comp models.Comparison) bool {
+	for _, m := range []*models.MetricComparison{comp.BytesPerOp, comp.AllocsPerOp} {
+		if m != nil && m.Status == "degraded" {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentionDegraded reports whether a benchmark's goroutines spent more
+// time blocked or waiting for mutexes per operation, whatever its timing did
+func ContentionDegraded(comp models.Comparison) bool {
+	return comp.Contention != nil && comp.Contention.Status == "degraded"
+}
+
+// HiddenContention returns the benchmarks whose contention degraded while
+// their ns/op stayed flat. Goroutines waiting longer on each other without
+// slowing the benchmark down yet is a common precursor of tail latency in
+// production, where load is higher than in a benchmark.
+func HiddenContention(comparisons []models.Comparison) []models.Comparison {
+	var hidden []models.Comparison
+	for _, comp := range comparisons {
+		if comp.Status == "same" && ContentionDegraded(comp) {
+			hidden = append(hidden, comp)
+		}
+	}
+	return hidden
 }
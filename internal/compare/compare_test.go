@@ -1,11 +1,13 @@
 package compare
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
 )
 
 func TestNewComparer(t *testing.T) {
@@ -95,8 +97,89 @@ func TestCompareNoMatches(t *testing.T) {
 
 	comparisons := c.Compare(oldRun, newRun)
 
-	if len(comparisons) != 0 {
-		t.Errorf("Expected 0 comparisons, got %d", len(comparisons))
+	matched, added, removed := Split(comparisons)
+	if len(matched) != 0 {
+		t.Errorf("Expected 0 matched comparisons, got %d", len(matched))
+	}
+	if len(added) != 1 || added[0].Name != "BenchmarkB" || added[0].NewNsPerOp != 200.0 {
+		t.Errorf("Expected BenchmarkB to be reported as added, got %+v", added)
+	}
+	if len(removed) != 1 || removed[0].Name != "BenchmarkA" || removed[0].OldNsPerOp != 100.0 {
+		t.Errorf("Expected BenchmarkA to be reported as removed, got %+v", removed)
+	}
+}
+
+func TestCompareAddedRemoved(t *testing.T) {
+	c := NewComparer()
+
+	oldRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkKept-8", NsPerOp: 100.0},
+			{Name: "BenchmarkSkipped-8", NsPerOp: 50.0},
+			{Name: "BenchmarkDeleted-8", NsPerOp: 75.0},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkKept-8", NsPerOp: 100.0},
+			{Name: "BenchmarkSkipped", Status: models.StatusSkipped, Message: "short mode"},
+			{Name: "BenchmarkNew-8", NsPerOp: 10.0},
+		},
+	}
+
+	comparisons := c.Compare(oldRun, newRun)
+	if len(comparisons) != 4 {
+		t.Fatalf("Expected 4 comparisons, got %d: %+v", len(comparisons), comparisons)
+	}
+
+	wantStatus := []string{"same", models.StatusSkipped, models.StatusAdded, models.StatusRemoved}
+	wantName := []string{"BenchmarkKept-8", "BenchmarkSkipped", "BenchmarkNew-8", "BenchmarkDeleted-8"}
+	for i, comp := range comparisons {
+		if comp.Status != wantStatus[i] || comp.Name != wantName[i] {
+			t.Errorf("comparisons[%d] = %s (%s), want %s (%s)", i, comp.Name, comp.Status, wantName[i], wantStatus[i])
+		}
+	}
+
+	if got := FormatComparison(comparisons[2]); !strings.HasPrefix(got, "+ BenchmarkNew-8") {
+		t.Errorf("Unexpected added format: %s", got)
+	}
+	if got := FormatComparison(comparisons[3]); !strings.HasPrefix(got, "- BenchmarkDeleted-8") {
+		t.Errorf("Unexpected removed format: %s", got)
+	}
+
+	summary := Summary(comparisons)
+	if !strings.Contains(summary, "1 added, 1 removed") {
+		t.Errorf("Expected summary to count added and removed, got: %s", summary)
+	}
+}
+
+func TestCompareDocs(t *testing.T) {
+	c := NewComparer()
+
+	oldRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "Encode", NsPerOp: 100, Doc: "Old doc"},
+			{Name: "Decode", NsPerOp: 100, Doc: "Decode doc"},
+			{Name: "Gone", NsPerOp: 100, Doc: "Gone doc"},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "Encode", NsPerOp: 100, Doc: "New doc"},
+			{Name: "Decode", NsPerOp: 100},
+			{Name: "Fresh", NsPerOp: 100, Doc: "Fresh doc"},
+		},
+	}
+
+	docs := make(map[string]string)
+	for _, comp := range c.Compare(oldRun, newRun) {
+		docs[comp.Name] = comp.Doc
+	}
+	want := map[string]string{"Encode": "New doc", "Decode": "Decode doc", "Gone": "Gone doc", "Fresh": "Fresh doc"}
+	for name, doc := range want {
+		if docs[name] != doc {
+			t.Errorf("%s: Doc = %q, want %q", name, docs[name], doc)
+		}
 	}
 }
 
@@ -254,3 +337,378 @@ func TestSummaryAllDegraded(t *testing.T) {
 		t.Errorf("Expected summary %q, got %q", expected, summary)
 	}
 }
+
+func TestCompareMetrics(t *testing.T) {
+	c := NewComparer()
+
+	oldRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkCache", NsPerOp: 100.0, Metrics: map[string]float64{"hit_ratio": 0.8, "evictions": 10, "old_only": 1}},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkCache", NsPerOp: 100.0, Metrics: map[string]float64{"hit_ratio": 0.9, "evictions": 5, "new_only": 1}},
+		},
+	}
+
+	comparisons := c.Compare(oldRun, newRun)
+	if len(comparisons) != 1 {
+		t.Fatalf("Expected 1 comparison, got %d", len(comparisons))
+	}
+
+	metrics := comparisons[0].Metrics
+	if len(metrics) != 2 {
+		t.Fatalf("Expected 2 metric comparisons, got %d: %+v", len(metrics), metrics)
+	}
+
+	// Sorted by name
+	if metrics[0].Name != "evictions" || metrics[1].Name != "hit_ratio" {
+		t.Errorf("Unexpected metric order: %+v", metrics)
+	}
+	if metrics[0].DeltaPercent != -50 {
+		t.Errorf("Expected evictions delta -50%%, got %f", metrics[0].DeltaPercent)
+	}
+	if metrics[1].Old != 0.8 || metrics[1].New != 0.9 {
+		t.Errorf("Unexpected hit_ratio values: %+v", metrics[1])
+	}
+
+	formatted := FormatMetricComparison(metrics[0])
+	if !strings.Contains(formatted, "evictions") || !strings.Contains(formatted, "-50.00%") {
+		t.Errorf("Unexpected formatted metric: %s", formatted)
+	}
+}
+
+func TestCompareMetricsZeroOld(t *testing.T) {
+	metrics := compareMetrics(map[string]float64{"errors": 0}, map[string]float64{"errors": 3})
+	if len(metrics) != 1 || metrics[0].Delta != 3 || metrics[0].DeltaPercent != 0 {
+		t.Errorf("Unexpected comparison for zero old value: %+v", metrics)
+	}
+}
+
+func TestCompareFailedBenchmarks(t *testing.T) {
+	c := NewComparer()
+
+	oldRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkA-8", NsPerOp: 100.0},
+			{Name: "BenchmarkB-8", NsPerOp: 200.0},
+			{Name: "BenchmarkC", Status: models.StatusFailed},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkA-8", NsPerOp: 100.0},
+			// go test reports failures without the GOMAXPROCS suffix
+			{Name: "BenchmarkB", Status: models.StatusFailed, Message: "want 3, got 4"},
+			{Name: "BenchmarkC-8", NsPerOp: 50.0},
+		},
+	}
+
+	comparisons := c.Compare(oldRun, newRun)
+	if len(comparisons) != 2 {
+		t.Fatalf("Expected 2 comparisons (old failure has no baseline), got %d: %+v", len(comparisons), comparisons)
+	}
+
+	failed := comparisons[1]
+	if failed.Name != "BenchmarkB" || failed.Status != models.StatusFailed {
+		t.Errorf("Expected failed comparison for BenchmarkB, got %+v", failed)
+	}
+	if failed.OldNsPerOp != 200.0 || failed.Message != "want 3, got 4" {
+		t.Errorf("Unexpected failed comparison details: %+v", failed)
+	}
+
+	formatted := FormatComparison(failed)
+	if !strings.Contains(formatted, "FAILED") || !strings.Contains(formatted, "want 3, got 4") {
+		t.Errorf("Expected failure to be shown distinctly, got: %s", formatted)
+	}
+
+	summary := Summary(comparisons)
+	if !strings.Contains(summary, "1 failed") {
+		t.Errorf("Expected summary to count failures, got: %s", summary)
+	}
+}
+
+func TestCompareSkippedBenchmarks(t *testing.T) {
+	c := NewComparer()
+
+	oldRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkA-8", NsPerOp: 100.0},
+			{Name: "BenchmarkB-8", NsPerOp: 200.0},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkA-8", NsPerOp: 100.0},
+			{Name: "BenchmarkB", Status: models.StatusSkipped, Message: "needs network"},
+		},
+	}
+
+	comparisons := c.Compare(oldRun, newRun)
+	if len(comparisons) != 2 {
+		t.Fatalf("Expected skipped benchmark to be compared, got %d: %+v", len(comparisons), comparisons)
+	}
+
+	skipped := comparisons[1]
+	if skipped.Status != models.StatusSkipped || skipped.Message != "needs network" {
+		t.Errorf("Expected skipped comparison for BenchmarkB, got %+v", skipped)
+	}
+	if formatted := FormatComparison(skipped); !strings.Contains(formatted, "SKIPPED: needs network") {
+		t.Errorf("Expected skip to be shown distinctly, got: %s", formatted)
+	}
+
+	summary := Summary(comparisons)
+	if !strings.Contains(summary, "1 skipped") {
+		t.Errorf("Expected summary to count skips, got: %s", summary)
+	}
+}
+
+func TestTrimProcs(t *testing.T) {
+	tests := map[string]string{
+		"BenchmarkA-8":       "BenchmarkA",
+		"BenchmarkA":         "BenchmarkA",
+		"BenchmarkA/size-16": "BenchmarkA/size",
+		"BenchmarkA/x-y":     "BenchmarkA/x-y",
+		"BenchmarkA-":        "BenchmarkA-",
+	}
+	for input, want := range tests {
+		if got := trimProcs(input); got != want {
+			t.Errorf("trimProcs(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCompareResultsSamples(t *testing.T) {
+	c := NewComparer()
+
+	tests := []struct {
+		name           string
+		oldSamples     []float64
+		newSamples     []float64
+		expectedStatus string
+		significant    bool
+	}{
+		{
+			name:           "consistent degradation",
+			oldSamples:     []float64{100, 101, 99, 100, 102},
+			newSamples:     []float64{120, 121, 119, 122, 118},
+			expectedStatus: "degraded",
+			significant:    true,
+		},
+		{
+			name:           "noisy samples",
+			oldSamples:     []float64{80, 120, 95, 130, 75},
+			newSamples:     []float64{125, 85, 135, 90, 115},
+			expectedStatus: "same",
+			significant:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := models.BenchmarkResult{Name: "Test", Samples: tt.oldSamples}
+			new := models.BenchmarkResult{Name: "Test", Samples: tt.newSamples}
+			old.NsPerOp, _ = stats.MeanCI(tt.oldSamples)
+			new.NsPerOp, _ = stats.MeanCI(tt.newSamples)
+
+			comp := c.compareResults(old, new)
+
+			if comp.Status != tt.expectedStatus {
+				t.Errorf("Expected status %s, got %s (delta %.2f%%)", tt.expectedStatus, comp.Status, comp.DeltaPercent)
+			}
+			if comp.PValue == nil {
+				t.Fatal("Expected a p-value for sampled results")
+			}
+			if comp.Significant() != tt.significant {
+				t.Errorf("Expected significant=%v, got p=%v", tt.significant, *comp.PValue)
+			}
+			if comp.OldSamples != 5 || comp.NewSamples != 5 || comp.OldCI <= 0 || comp.NewCI <= 0 {
+				t.Errorf("Expected sample counts and confidence intervals, got %+v", comp)
+			}
+		})
+	}
+}
+
+func TestFormatComparisonSamples(t *testing.T) {
+	p := 0.008
+	comp := models.Comparison{
+		Name:         "BenchmarkTest",
+		OldNsPerOp:   100.0,
+		NewNsPerOp:   120.0,
+		DeltaPercent: 20.0,
+		Status:       "degraded",
+		OldCI:        2.0,
+		NewCI:        6.0,
+		OldSamples:   5,
+		NewSamples:   5,
+		PValue:       &p,
+	}
+
+	result := FormatComparison(comp)
+	for _, expected := range []string{"+20.00%", "±2.0%", "±5.0%", "p=0.008", "n=5+5"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected result to contain %q, got: %s", expected, result)
+		}
+	}
+}
+
+func TestCompareMemoryMetrics(t *testing.T) {
+	oldRun := &models.BenchmarkRun{
+		Command: "go test -bench . -benchmem ./...",
+		Results: []models.BenchmarkResult{
+			{Name: "Parse", NsPerOp: 100, BytesPerOp: 100, AllocsPerOp: 2, MBPerSec: 50},
+			{Name: "Hash", NsPerOp: 100},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		Results: []models.BenchmarkResult{
+			{Name: "Parse", NsPerOp: 101, BytesPerOp: 150, AllocsPerOp: 2, MBPerSec: 49.5},
+			{Name: "Hash", NsPerOp: 100, BytesPerOp: 16, AllocsPerOp: 1},
+		},
+	}
+
+	comparisons := NewComparer().Compare(oldRun, newRun)
+	parse, hash := comparisons[0], comparisons[1]
+
+	// Stable timing does not hide the memory regression
+	if parse.Status != "same" {
+		t.Errorf("Expected stable timing, got %s", parse.Status)
+	}
+	if parse.BytesPerOp == nil || parse.BytesPerOp.Status != "degraded" || parse.BytesPerOp.DeltaPercent != 50 {
+		t.Errorf("Unexpected B/op comparison: %+v", parse.BytesPerOp)
+	}
+	if parse.AllocsPerOp == nil || parse.AllocsPerOp.Status != "same" {
+		t.Errorf("Unexpected allocs/op comparison: %+v", parse.AllocsPerOp)
+	}
+	if parse.MBPerSec == nil || parse.MBPerSec.Status != "same" {
+		t.Errorf("Unexpected MB/s comparison: %+v", parse.MBPerSec)
+	}
+	if !MemoryDegraded(parse) {
+		t.Error("Expected Parse to use more memory")
+	}
+
+	// A zero-allocation benchmark starting to allocate
+	if hash.AllocsPerOp == nil || hash.AllocsPerOp.Status != "degraded" {
+		t.Errorf("Unexpected allocs/op comparison: %+v", hash.AllocsPerOp)
+	}
+	if hash.MBPerSec != nil {
+		t.Errorf("Expected no MB/s comparison without throughput, got %+v", hash.MBPerSec)
+	}
+	if got := len(hash.StandardMetrics()); got != 2 {
+		t.Errorf("Expected 2 standard metrics, got %d", got)
+	}
+	if !strings.Contains(Summary(comparisons), "2 with more memory use") {
+		t.Errorf("Unexpected summary: %s", Summary(comparisons))
+	}
+	if formatted := FormatMetricComparison(*hash.AllocsPerOp); !strings.Contains(formatted, "✗") || !strings.Contains(formatted, "from 0") {
+		t.Errorf("Unexpected formatted metric: %s", formatted)
+	}
+}
+
+func TestCompareMemoryWithoutBenchmem(t *testing.T) {
+	// Zeros of a run without -benchmem are not measurements
+	oldRun := &models.BenchmarkRun{Results: []models.BenchmarkResult{{Name: "Hash", NsPerOp: 100}}}
+	newRun := &models.BenchmarkRun{
+		Command: "go test -bench . -benchmem",
+		Results: []models.BenchmarkResult{{Name: "Hash", NsPerOp: 100, BytesPerOp: 16, AllocsPerOp: 1}},
+	}
+
+	comp := NewComparer().Compare(oldRun, newRun)[0]
+	if comp.BytesPerOp != nil || comp.AllocsPerOp != nil {
+		t.Errorf("Expected no memory comparison, got %+v", comp.StandardMetrics())
+	}
+}
+
+func TestCompareContention(t *testing.T) {
+	oldRun := &models.BenchmarkRun{
+		MutexProfile: "old/mutex.prof",
+		Results: []models.BenchmarkResult{
+			{Name: "Get", NsPerOp: 100, ContentionNsPerOp: 20},
+			{Name: "Put", NsPerOp: 100, ContentionNsPerOp: 20},
+			{Name: "Hash", NsPerOp: 100},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		MutexProfile: "new/mutex.prof",
+		Results: []models.BenchmarkResult{
+			{Name: "Get", NsPerOp: 101, ContentionNsPerOp: 60},
+			{Name: "Put", NsPerOp: 150, ContentionNsPerOp: 60},
+			{Name: "Hash", NsPerOp: 100, ContentionNsPerOp: 0.5},
+		},
+	}
+
+	comparisons := NewComparer().Compare(oldRun, newRun)
+	get, put, hash := comparisons[0], comparisons[1], comparisons[2]
+	if get.Contention == nil || get.Contention.Status != "degraded" || get.Contention.DeltaPercent != 200 {
+		t.Errorf("Unexpected Get contention: %+v", get.Contention)
+	}
+	if !ContentionDegraded(put) {
+		t.Errorf("Expected Put contention to degrade, got %+v", put.Contention)
+	}
+	// Contention rising from zero by less than 1% of ns/op is noise
+	if hash.Contention == nil || hash.Contention.Status != "same" {
+		t.Errorf("Unexpected Hash contention: %+v", hash.Contention)
+	}
+
+	// Only the benchmark whose timing stayed flat hides its contention
+	hidden := HiddenContention(comparisons)
+	if len(hidden) != 1 || hidden[0].Name != "Get" {
+		t.Errorf("Expected Get to hide contention, got %+v", hidden)
+	}
+	if !strings.Contains(Summary(comparisons), "2 with more contention") {
+		t.Errorf("Unexpected summary: %s", Summary(comparisons))
+	}
+
+	// Runs profiling different kinds of contention are not comparable
+	newRun.BlockProfile = "new/block.prof"
+	if comp := NewComparer().Compare(oldRun, newRun)[0]; comp.Contention != nil {
+		t.Errorf("Expected no contention comparison, got %+v", comp.Contention)
+	}
+}
+
+func TestCompareStandardMetric(t *testing.T) {
+	c := NewComparer()
+	tests := []struct {
+		old, new       float64
+		higherIsBetter bool
+		want           string
+	}{
+		{100, 104, false, "same"},
+		{100, 110, false, "degraded"},
+		{100, 90, false, "improved"},
+		{100, 90, true, "degraded"},
+		{100, 110, true, "improved"},
+		{0, 0, false, "same"},
+		{0, 3, false, "degraded"},
+	}
+	for _, tt := range tests {
+		if got := c.compareStandardMetric("m", tt.old, tt.new, tt.higherIsBetter); got.Status != tt.want {
+			t.Errorf("compareStandardMetric(%v, %v, %v) = %s, want %s", tt.old, tt.new, tt.higherIsBetter, got.Status, tt.want)
+		}
+	}
+}
+
+func TestTopChanges(t *testing.T) {
+	var comparisons []models.Comparison
+	for i := 1; i <= 7; i++ {
+		comparisons = append(comparisons,
+			models.Comparison{Name: fmt.Sprintf("Slow%d", i), DeltaPercent: float64(10 * i), Status: "degraded"},
+			models.Comparison{Name: fmt.Sprintf("Fast%d", i), DeltaPercent: float64(-10 * i), Status: "improved"},
+		)
+	}
+	comparisons = append(comparisons, models.Comparison{Name: "Stable", Status: "same"})
+
+	regressions, improvements := TopChanges(comparisons, 5)
+	if len(regressions) != 5 || regressions[0].Name != "Slow7" || regressions[4].Name != "Slow3" {
+		t.Errorf("Unexpected regressions: %+v", regressions)
+	}
+	if len(improvements) != 5 || improvements[0].Name != "Fast7" {
+		t.Errorf("Unexpected improvements: %+v", improvements)
+	}
+
+	regressions, improvements = TopChanges(comparisons[14:], 5)
+	if regressions != nil || improvements != nil {
+		t.Errorf("Expected no changes, got %+v and %+v", regressions, improvements)
+	}
+}
@@ -0,0 +1,83 @@
+package compare
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// ModuleChange is a dependency that differs between two runs. Old is empty
+// for added modules and New is empty for removed ones.
+type ModuleChange struct {
+	Path string `json:"path"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// DependencyDiff describes how the dependencies changed between two runs
+type DependencyDiff struct {
+	Known      bool           `json:"known"`       // Both runs recorded their dependencies
+	SumChanged bool           `json:"sum_changed"` // The go.sum files differ
+	Changes    []ModuleChange `json:"changes,omitempty"`
+}
+
+// Changed reports whether any dependency changed between the runs
+func (d DependencyDiff) Changed() bool {
+	return d.SumChanged || len(d.Changes) > 0
+}
+
+// DiffDependencies compares the dependencies recorded with two runs. The
+// changed modules are sorted by path.
+func DiffDependencies(oldRun, newRun *models.BenchmarkRun) DependencyDiff {
+	if oldRun.Dependencies == nil || newRun.Dependencies == nil {
+		return DependencyDiff{}
+	}
+	oldDeps, newDeps := oldRun.Dependencies, newRun.Dependencies
+
+	diff := DependencyDiff{
+		Known:      true,
+		SumChanged: oldDeps.GoSumHash != newDeps.GoSumHash,
+	}
+	for path, oldVersion := range oldDeps.Modules {
+		if newVersion := newDeps.Modules[path]; newVersion != oldVersion {
+			diff.Changes = append(diff.Changes, ModuleChange{Path: path, Old: oldVersion, New: newVersion})
+		}
+	}
+	for path, newVersion := range newDeps.Modules {
+		if _, ok := oldDeps.Modules[path]; !ok {
+			diff.Changes = append(diff.Changes, ModuleChange{Path: path, New: newVersion})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].Path < diff.Changes[j].Path
+	})
+	return diff
+}
+
+// FormatDependencyDiff describes a dependency diff for a regression report
+func FormatDependencyDiff(diff DependencyDiff) string {
+	switch {
+	case !diff.Known:
+		return "Dependencies: unknown (not recorded with both runs)"
+	case !diff.Changed():
+		return "Dependencies: unchanged, the regression is not caused by a dependency upgrade"
+	case len(diff.Changes) == 0:
+		return "Dependencies: go.sum changed but no required module version did (check indirect dependencies)"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Dependencies changed between the runs. Likely suspects (%d):", len(diff.Changes))
+	for _, c := range diff.Changes {
+		switch {
+		case c.Old == "":
+			fmt.Fprintf(&sb, "\n  + %s %s (added)", c.Path, c.New)
+		case c.New == "":
+			fmt.Fprintf(&sb, "\n  - %s %s (removed)", c.Path, c.Old)
+		default:
+			fmt.Fprintf(&sb, "\n  ~ %s %s → %s", c.Path, c.Old, c.New)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package compare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestDiffDependencies(t *testing.T) {
+	oldRun := &models.BenchmarkRun{Dependencies: &models.Dependencies{
+		GoSumHash: "a",
+		Modules: map[string]string{
+			"example.com/json":    "v1.0.0",
+			"example.com/same":    "v1.0.0",
+			"example.com/removed": "v0.1.0",
+		},
+	}}
+	newRun := &models.BenchmarkRun{Dependencies: &models.Dependencies{
+		GoSumHash: "b",
+		Modules: map[string]string{
+			"example.com/json":  "v1.1.0",
+			"example.com/same":  "v1.0.0",
+			"example.com/added": "v0.2.0",
+		},
+	}}
+
+	diff := DiffDependencies(oldRun, newRun)
+	want := []ModuleChange{
+		{Path: "example.com/added", New: "v0.2.0"},
+		{Path: "example.com/json", Old: "v1.0.0", New: "v1.1.0"},
+		{Path: "example.com/removed", Old: "v0.1.0"},
+	}
+	if !diff.Known || !diff.SumChanged || !reflect.DeepEqual(diff.Changes, want) {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+
+	report := FormatDependencyDiff(diff)
+	for _, line := range []string{"Likely suspects (3)", "+ example.com/added v0.2.0", "~ example.com/json v1.0.0 → v1.1.0", "- example.com/removed v0.1.0"} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report missing %q:\n%s", line, report)
+		}
+	}
+}
+
+func TestDiffDependenciesUnchangedOrUnknown(t *testing.T) {
+	deps := &models.Dependencies{GoSumHash: "a", Modules: map[string]string{"example.com/json": "v1.0.0"}}
+
+	diff := DiffDependencies(&models.BenchmarkRun{Dependencies: deps}, &models.BenchmarkRun{Dependencies: deps})
+	if !diff.Known || diff.Changed() {
+		t.Errorf("expected no changes, got %+v", diff)
+	}
+	if !strings.Contains(FormatDependencyDiff(diff), "unchanged") {
+		t.Errorf("unexpected report: %s", FormatDependencyDiff(diff))
+	}
+
+	diff = DiffDependencies(&models.BenchmarkRun{}, &models.BenchmarkRun{Dependencies: deps})
+	if diff.Known || !strings.Contains(FormatDependencyDiff(diff), "unknown") {
+		t.Errorf("expected an unknown diff, got %+v", diff)
+	}
+
+	sumOnly := &models.Dependencies{GoSumHash: "b", Modules: deps.Modules}
+	diff = DiffDependencies(&models.BenchmarkRun{Dependencies: deps}, &models.BenchmarkRun{Dependencies: sumOnly})
+	if !diff.Changed() || !strings.Contains(FormatDependencyDiff(diff), "go.sum changed") {
+		t.Errorf("expected a go.sum-only change, got %+v", diff)
+	}
+}
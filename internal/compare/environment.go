@@ -0,0 +1,164 @@
+package compare
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// BusyLoadPerCPU is the load average per CPU from which a machine counts as
+// busy: other processes then compete with the benchmarks for the CPU
+const BusyLoadPerCPU = 0.5
+
+// EnvironmentMismatch is a property of the machine that differs materially
+// between two runs
+type EnvironmentMismatch struct {
+	Property string `json:"property"`
+	Old      string `json:"old"`
+	New      string `json:"new"`
+}
+
+// EnvironmentMismatches returns the machine properties that differ
+// materially between two runs: the hardware, the CPUs and memory available
+// to the benchmarks, frequency scaling and whether the machine was busy.
+// Properties either run did not record are skipped, and so is the kernel
+// release, which rarely moves results. Nothing is reported unless both runs
+// recorded their environment.
+func EnvironmentMismatches(oldRun, newRun *models.BenchmarkRun) []EnvironmentMismatch {
+	if oldRun.Environment == nil || newRun.Environment == nil {
+		return nil
+	}
+	o, n := oldRun.Environment, newRun.Environment
+
+	properties := []struct {
+		name     string
+		old, new string
+	}{
+		{"CPU model", o.CPUModel, n.CPUModel},
+		{"CPUs", formatCount(o.CPUs), formatCount(n.CPUs)},
+		{"GOMAXPROCS", formatCount(o.GOMAXPROCS), formatCount(n.GOMAXPROCS)},
+		{"OS", o.OS, n.OS},
+		{"CPU governor", o.Governor, n.Governor},
+		{"Container CPU limit", formatCPULimit(o.CPULimit), formatCPULimit(n.CPULimit)},
+		{"Container memory limit", formatMemoryLimit(o.MemoryLimit), formatMemoryLimit(n.MemoryLimit)},
+	}
+
+	var mismatches []EnvironmentMismatch
+	for _, p := range properties {
+		if p.old != "" && p.new != "" && p.old != p.new {
+			mismatches = append(mismatches, EnvironmentMismatch{Property: p.name, Old: p.old, New: p.new})
+		}
+	}
+
+	// Load averages always differ, so only a machine turning busy or idle
+	// counts
+	oldBusy, oldOK := busy(o)
+	newBusy, newOK := busy(n)
+	if oldOK && newOK && oldBusy != newBusy {
+		mismatches = append(mismatches, EnvironmentMismatch{
+			Property: "Machine load",
+			Old:      formatLoad(o.LoadAverage, oldBusy),
+			New:      formatLoad(n.LoadAverage, newBusy),
+		})
+	}
+	return mismatches
+}
+
+// advisoryProperties are the machine properties that differ between jobs
+// of hosted CI runners without making results incomparable, such as the
+// CPU model of the same instance type
+var advisoryProperties = map[string]bool{
+	"CPU model":              true,
+	"CPU governor":           true,
+	"Container CPU limit":    true,
+	"Container memory limit": true,
+}
+
+// EnvironmentDifferences describes how the environments of two runs differ,
+// comparing only what both runs recorded. Blocking differences make
+// comparing them apples to oranges: the Go release, the build settings
+// and, unless the runs are normalized to compare machines, the CPUs and OS.
+// Advisory differences, the CPU model, governor and container limits, are
+// worth a mention only. A machine turning busy or idle only adds noise and
+// is left out.
+func EnvironmentDifferences(oldRun, newRun *models.BenchmarkRun) (blocking, advisory []string) {
+	if o, n := goRelease(oldRun.GoVersion), goRelease(newRun.GoVersion); o != "" && n != "" && o != n {
+		blocking = append(blocking, fmt.Sprintf("Go version differs: %s → %s", o, n))
+	}
+	for _, m := range ToolchainMismatches(oldRun, newRun) {
+		blocking = append(blocking, FormatToolchainMismatch(m))
+	}
+	if newRun.NormalizedTo == "" {
+		for _, m := range EnvironmentMismatches(oldRun, newRun) {
+			switch {
+			case m.Property == "Machine load":
+			case advisoryProperties[m.Property]:
+				advisory = append(advisory, FormatEnvironmentMismatch(m))
+			default:
+				blocking = append(blocking, FormatEnvironmentMismatch(m))
+			}
+		}
+	}
+	return blocking, advisory
+}
+
+// goRelease returns the release in the output of 'go version', e.g.
+// "go1.24.7" in "go version go1.24.7 linux/amd64", or the version as
+// recorded when it has another form
+func goRelease(version string) string {
+	for _, field := range strings.Fields(version) {
+		if strings.HasPrefix(field, "go1") || strings.HasPrefix(field, "devel") {
+			return field
+		}
+	}
+	return strings.TrimSpace(version)
+}
+
+// FormatEnvironmentMismatch formats a mismatch, e.g. "GOMAXPROCS differs: 8 → 4"
+func FormatEnvironmentMismatch(m EnvironmentMismatch) string {
+	return fmt.Sprintf("%s differs: %s → %s", m.Property, m.Old, m.New)
+}
+
+// formatCount formats a count, "" when not recorded
+func formatCount(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// formatCPULimit formats the CPUs of a container limit
+func formatCPULimit(cpus float64) string {
+	if cpus == 0 {
+		return "unlimited"
+	}
+	return strconv.FormatFloat(cpus, 'g', 4, 64)
+}
+
+// formatMemoryLimit formats the bytes of a container limit
+func formatMemoryLimit(bytes int64) string {
+	if bytes == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d MiB", bytes>>20)
+}
+
+// busy reports whether the machine was busy when a run started, and
+// whether that is known: the load average of an idle machine can be 0, but
+// one without CPUs recorded cannot be judged
+func busy(env *models.Environment) (isBusy, ok bool) {
+	if env.CPUs == 0 {
+		return false, false
+	}
+	return env.LoadAverage > BusyLoadPerCPU*float64(env.CPUs), true
+}
+
+// formatLoad formats the load of a machine, e.g. "busy (load 6.20)"
+func formatLoad(load float64, isBusy bool) string {
+	if isBusy {
+		return fmt.Sprintf("busy (load %.2f)", load)
+	}
+	return fmt.Sprintf("idle (load %.2f)", load)
+}
@@ -0,0 +1,95 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestEnvironmentMismatches(t *testing.T) {
+	base := models.Environment{
+		CPUModel:    "AMD EPYC 7763",
+		CPUs:        8,
+		GOMAXPROCS:  8,
+		OS:          "linux",
+		Kernel:      "6.1.0",
+		LoadAverage: 0.4,
+		Governor:    "performance",
+	}
+	changed := base
+	changed.CPUs = 4
+	changed.Kernel = "6.8.0"  // Not material
+	changed.LoadAverage = 1.5 // Still idle on 4 CPUs
+	changed.CPULimit = 2
+	changed.Governor = "" // Not recorded
+
+	oldRun := &models.BenchmarkRun{Environment: &base}
+	newRun := &models.BenchmarkRun{Environment: &changed}
+
+	want := []EnvironmentMismatch{
+		{Property: "CPUs", Old: "8", New: "4"},
+		{Property: "Container CPU limit", Old: "unlimited", New: "2"},
+	}
+	got := EnvironmentMismatches(oldRun, newRun)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("EnvironmentMismatches() = %+v, want %+v", got, want)
+	}
+	if s := FormatEnvironmentMismatch(got[0]); s != "CPUs differs: 8 → 4" {
+		t.Errorf("FormatEnvironmentMismatch() = %q", s)
+	}
+
+	// A machine turning busy
+	changed = base
+	changed.LoadAverage = 6.2
+	got = EnvironmentMismatches(oldRun, newRun)
+	want = []EnvironmentMismatch{{Property: "Machine load", Old: "idle (load 0.40)", New: "busy (load 6.20)"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvironmentMismatches() = %+v, want %+v", got, want)
+	}
+
+	if got := EnvironmentMismatches(oldRun, oldRun); len(got) != 0 {
+		t.Errorf("expected no mismatches for identical environments, got %+v", got)
+	}
+	if got := EnvironmentMismatches(&models.BenchmarkRun{}, newRun); len(got) != 0 {
+		t.Errorf("expected no mismatches without recorded environments, got %+v", got)
+	}
+}
+
+func TestEnvironmentDifferences(t *testing.T) {
+	idle := models.Environment{CPUModel: "AMD EPYC 7763", CPUs: 8, GOMAXPROCS: 8, LoadAverage: 0.5}
+	busy := idle
+	busy.LoadAverage = 12
+	oldRun := &models.BenchmarkRun{
+		GoVersion:   "go version go1.23.4 linux/amd64",
+		Toolchain:   &models.Toolchain{GOARCH: "amd64", GOAMD64: "v1"},
+		Environment: &idle,
+	}
+	newRun := &models.BenchmarkRun{
+		GoVersion:   "go version go1.24.7 linux/amd64",
+		Toolchain:   &models.Toolchain{GOARCH: "amd64", GOAMD64: "v3"},
+		Environment: &busy,
+	}
+
+	want := []string{"Go version differs: go1.23.4 → go1.24.7", "GOAMD64 differs: v1 → v3"}
+	if got, advisory := EnvironmentDifferences(oldRun, newRun); !reflect.DeepEqual(got, want) || len(advisory) != 0 {
+		t.Errorf("EnvironmentDifferences() = %q, %q, want %q", got, advisory, want)
+	}
+
+	// Hosted runners change CPU model between jobs: worth a mention only
+	other := idle
+	other.CPUModel = "Intel Xeon Platinum 8370C"
+	other.CPUs = 4
+	newRun.GoVersion, newRun.Toolchain, newRun.Environment = oldRun.GoVersion, oldRun.Toolchain, &other
+	blocking, advisory := EnvironmentDifferences(oldRun, newRun)
+	if !reflect.DeepEqual(blocking, []string{"CPUs differs: 8 → 4"}) ||
+		!reflect.DeepEqual(advisory, []string{"CPU model differs: AMD EPYC 7763 → Intel Xeon Platinum 8370C"}) {
+		t.Errorf("EnvironmentDifferences() = %q, %q", blocking, advisory)
+	}
+
+	// Runs with the same release and no recorded settings do not differ
+	same := []*models.BenchmarkRun{{GoVersion: "go1.24.7"}, {GoVersion: "go version go1.24.7 darwin/arm64"}}
+	if got, advisory := EnvironmentDifferences(same[0], same[1]); len(got) != 0 || len(advisory) != 0 {
+		t.Errorf("Expected no differences, got %q, %q", got, advisory)
+	}
+}
@@ -0,0 +1,181 @@
+package compare
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// How a pair of benchmarks was matched
+const (
+	MatchExact      = "exact"      // Same package and name
+	MatchNormalized = "normalized" // Same name after matching rules were applied
+	MatchName       = "name"       // Same normalized name in different packages
+)
+
+// MatchRules control how benchmarks of two runs are paired up. Rules only
+// apply to benchmarks that have no exact counterpart.
+type MatchRules struct {
+	StripCPUSuffix bool              // Ignore the GOMAXPROCS suffix, e.g. "Foo-8" matches "Foo-4"
+	StripPatterns  []*regexp.Regexp  // Patterns removed from names before matching
+	PackageRenames map[string]string // Old import path (or prefix) -> new import path
+}
+
+// DefaultMatchRules returns the rules used when none are configured
+func DefaultMatchRules() MatchRules {
+	return MatchRules{StripCPUSuffix: true}
+}
+
+// Match pairs a benchmark of the old run with one of the new run.
+// Old is nil for added benchmarks and New is nil for removed ones.
+type Match struct {
+	Old  *models.BenchmarkResult
+	New  *models.BenchmarkResult
+	Rule string // One of MatchExact, MatchNormalized, MatchName; empty when unmatched
+}
+
+// Match pairs the benchmarks of two runs. Exact matches are tried first,
+// then names normalized by the matching rules, then normalized names
+// regardless of package. Fallback matches are only made when they are
+// unambiguous. Matched pairs and added benchmarks are returned in the order
+// of the new run, followed by removed benchmarks in the order of the old run.
+func (c *Comparer) Match(oldRun, newRun *models.BenchmarkRun) []Match {
+	oldMatched := make([]bool, len(oldRun.Results))
+	newMatch := make([]int, len(newRun.Results))
+	rules := make([]string, len(newRun.Results))
+	for i := range newMatch {
+		newMatch[i] = -1
+	}
+
+	exactKey := func(r models.BenchmarkResult) string {
+		return r.Package + "\x00" + r.Name
+	}
+	normalizedKey := func(r models.BenchmarkResult) string {
+		return c.renamePackage(r.Package) + "\x00" + c.normalizeName(r.Name)
+	}
+	nameKey := func(r models.BenchmarkResult) string {
+		return c.normalizeName(r.Name)
+	}
+
+	// Exact matches take precedence, first come first served
+	oldExact := make(map[string][]int)
+	for i, r := range oldRun.Results {
+		k := exactKey(r)
+		oldExact[k] = append(oldExact[k], i)
+	}
+	for i, r := range newRun.Results {
+		candidates := oldExact[exactKey(r)]
+		if len(candidates) == 0 {
+			continue
+		}
+		newMatch[i] = candidates[0]
+		rules[i] = MatchExact
+		oldMatched[candidates[0]] = true
+		oldExact[exactKey(r)] = candidates[1:]
+	}
+
+	c.matchUnique(oldRun, newRun, oldMatched, newMatch, rules, normalizedKey, MatchNormalized, false)
+	// Ignoring packages is only safe for names that are unique in each run
+	c.matchUnique(oldRun, newRun, oldMatched, newMatch, rules, nameKey, MatchName, true)
+
+	var matches, added []Match
+	for i := range newRun.Results {
+		newResult := &newRun.Results[i]
+		if newMatch[i] < 0 {
+			added = append(added, Match{New: newResult})
+			continue
+		}
+		matches = append(matches, Match{Old: &oldRun.Results[newMatch[i]], New: newResult, Rule: rules[i]})
+	}
+	matches = append(matches, added...)
+
+	for i := range oldRun.Results {
+		if !oldMatched[i] {
+			matches = append(matches, Match{Old: &oldRun.Results[i]})
+		}
+	}
+
+	return matches
+}
+
+// matchUnique pairs unmatched benchmarks whose keys are unique among the
+// unmatched benchmarks of both runs, or among all benchmarks if global is set
+func (c *Comparer) matchUnique(oldRun, newRun *models.BenchmarkRun, oldMatched []bool, newMatch []int, rules []string, key func(models.BenchmarkResult) string, rule string, global bool) {
+	oldByKey := make(map[string][]int)
+	for i, r := range oldRun.Results {
+		if global || !oldMatched[i] {
+			k := key(r)
+			oldByKey[k] = append(oldByKey[k], i)
+		}
+	}
+	newByKey := make(map[string][]int)
+	for i, r := range newRun.Results {
+		if global || newMatch[i] < 0 {
+			k := key(r)
+			newByKey[k] = append(newByKey[k], i)
+		}
+	}
+
+	for k, newIdx := range newByKey {
+		oldIdx := oldByKey[k]
+		if len(newIdx) != 1 || len(oldIdx) != 1 || newMatch[newIdx[0]] >= 0 || oldMatched[oldIdx[0]] {
+			continue
+		}
+		newMatch[newIdx[0]] = oldIdx[0]
+		rules[newIdx[0]] = rule
+		oldMatched[oldIdx[0]] = true
+	}
+}
+
+// normalizeName applies the name rules to a benchmark name
+func (c *Comparer) normalizeName(name string) string {
+	for _, re := range c.rules.StripPatterns {
+		name = re.ReplaceAllString(name, "")
+	}
+	if c.rules.StripCPUSuffix {
+		name = trimProcs(name)
+	}
+	return name
+}
+
+// renamePackage maps an import path through the package renames. A rename
+// also applies to the packages nested below the old path; the longest
+// matching old path wins.
+func (c *Comparer) renamePackage(pkg string) string {
+	renamed, longest := pkg, -1
+	for from, to := range c.rules.PackageRenames {
+		if len(from) <= longest {
+			continue
+		}
+		if pkg == from {
+			renamed, longest = to, len(from)
+		} else if rest, ok := strings.CutPrefix(pkg, from+"/"); ok {
+			renamed, longest = to+"/"+rest, len(from)
+		}
+	}
+	return renamed
+}
+
+// FormatMatch formats a match for the dry-run report
+func FormatMatch(m Match) string {
+	switch {
+	case m.Old == nil:
+		return fmt.Sprintf("+ %-50s (added)", qualifiedName(*m.New))
+	case m.New == nil:
+		return fmt.Sprintf("- %-50s (removed)", qualifiedName(*m.Old))
+	case m.Rule == MatchExact:
+		return fmt.Sprintf("= %s", qualifiedName(*m.New))
+	default:
+		return fmt.Sprintf("~ %s → %s (%s)", qualifiedName(*m.Old), qualifiedName(*m.New), m.Rule)
+	}
+}
+
+// qualifiedName returns the benchmark name prefixed with its package, if known
+func qualifiedName(r models.BenchmarkResult) string {
+	if r.Package == "" {
+		return r.Name
+	}
+	return r.Package + "." + r.Name
+}
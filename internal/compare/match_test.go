@@ -0,0 +1,157 @@
+package compare
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// matchPairs renders matches as "old>new:rule" strings for easy comparison
+func matchPairs(matches []Match) []string {
+	var pairs []string
+	for _, m := range matches {
+		var oldName, newName string
+		if m.Old != nil {
+			oldName = qualifiedName(*m.Old)
+		}
+		if m.New != nil {
+			newName = qualifiedName(*m.New)
+		}
+		pairs = append(pairs, oldName+">"+newName+":"+m.Rule)
+	}
+	return pairs
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules MatchRules
+		old   []models.BenchmarkResult
+		new   []models.BenchmarkResult
+		want  []string
+	}{
+		{
+			name:  "exact",
+			rules: DefaultMatchRules(),
+			old:   []models.BenchmarkResult{{Name: "A-8"}, {Name: "B-8"}},
+			new:   []models.BenchmarkResult{{Name: "B-8"}, {Name: "A-8"}},
+			want:  []string{"B-8>B-8:exact", "A-8>A-8:exact"},
+		},
+		{
+			name:  "cpu suffix",
+			rules: DefaultMatchRules(),
+			old:   []models.BenchmarkResult{{Name: "A-4"}},
+			new:   []models.BenchmarkResult{{Name: "A-8"}},
+			want:  []string{"A-4>A-8:normalized"},
+		},
+		{
+			name:  "cpu suffix disabled",
+			rules: MatchRules{},
+			old:   []models.BenchmarkResult{{Name: "A-4"}},
+			new:   []models.BenchmarkResult{{Name: "A-8"}},
+			want:  []string{">A-8:", "A-4>:"},
+		},
+		{
+			name:  "ambiguous normalized names stay unmatched",
+			rules: DefaultMatchRules(),
+			old:   []models.BenchmarkResult{{Name: "A-2"}, {Name: "A-4"}},
+			new:   []models.BenchmarkResult{{Name: "A-8"}},
+			want:  []string{">A-8:", "A-2>:", "A-4>:"},
+		},
+		{
+			name:  "strip patterns",
+			rules: MatchRules{StripPatterns: []*regexp.Regexp{regexp.MustCompile(`/v[0-9]+$`)}},
+			old:   []models.BenchmarkResult{{Name: "Parse/v1"}},
+			new:   []models.BenchmarkResult{{Name: "Parse/v2"}},
+			want:  []string{"Parse/v1>Parse/v2:normalized"},
+		},
+		{
+			name:  "package move",
+			rules: DefaultMatchRules(),
+			old:   []models.BenchmarkResult{{Name: "A-8", Package: "example.com/old"}},
+			new:   []models.BenchmarkResult{{Name: "A-8", Package: "example.com/new"}},
+			want:  []string{"example.com/old.A-8>example.com/new.A-8:name"},
+		},
+		{
+			name: "package rename disambiguates",
+			rules: MatchRules{PackageRenames: map[string]string{
+				"example.com/old": "example.com/new",
+			}},
+			old: []models.BenchmarkResult{
+				{Name: "Parse", Package: "example.com/old/json"},
+				{Name: "Parse", Package: "example.com/xml"},
+			},
+			new: []models.BenchmarkResult{
+				{Name: "Parse", Package: "example.com/new/json"},
+				{Name: "Parse", Package: "example.com/yaml"},
+			},
+			want: []string{
+				"example.com/old/json.Parse>example.com/new/json.Parse:normalized",
+				">example.com/yaml.Parse:",
+				"example.com/xml.Parse>:",
+			},
+		},
+		{
+			name:  "results recorded without package",
+			rules: DefaultMatchRules(),
+			old:   []models.BenchmarkResult{{Name: "A-8"}},
+			new:   []models.BenchmarkResult{{Name: "A-8", Package: "example.com/p"}},
+			want:  []string{"A-8>example.com/p.A-8:name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer().WithMatchRules(tt.rules)
+			got := matchPairs(c.Match(
+				&models.BenchmarkRun{Results: tt.old},
+				&models.BenchmarkRun{Results: tt.new},
+			))
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenamePackage(t *testing.T) {
+	c := NewComparer().WithMatchRules(MatchRules{PackageRenames: map[string]string{
+		"example.com/a":     "example.com/b",
+		"example.com/a/sub": "example.com/c",
+	}})
+
+	tests := map[string]string{
+		"example.com/a":       "example.com/b",
+		"example.com/a/x":     "example.com/b/x",
+		"example.com/a/sub/y": "example.com/c/y",
+		"example.com/ab":      "example.com/ab",
+		"":                    "",
+	}
+	for input, want := range tests {
+		if got := c.renamePackage(input); got != want {
+			t.Errorf("renamePackage(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFormatMatch(t *testing.T) {
+	old := models.BenchmarkResult{Name: "A-4", Package: "example.com/p"}
+	new := models.BenchmarkResult{Name: "A-8", Package: "example.com/p"}
+
+	tests := []struct {
+		match Match
+		want  string
+	}{
+		{Match{Old: &old, New: &old, Rule: MatchExact}, "= example.com/p.A-4"},
+		{Match{Old: &old, New: &new, Rule: MatchNormalized}, "~ example.com/p.A-4 → example.com/p.A-8 (normalized)"},
+		{Match{New: &new}, "+ example.com/p.A-8"},
+		{Match{Old: &old}, "- example.com/p.A-4"},
+	}
+	for _, tt := range tests {
+		if got := FormatMatch(tt.match); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("FormatMatch() = %q, want prefix %q", got, tt.want)
+		}
+	}
+}
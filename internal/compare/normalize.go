@@ -0,0 +1,75 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// NormalizedUnit is the unit of ns/op values divided by the reference benchmark
+const NormalizedUnit = "x ref"
+
+// Normalize returns a copy of run in which the ns/op of every measured
+// benchmark is divided by the ns/op of the reference benchmark of the same
+// run. The resulting scores are relative to a calibration loop and can be
+// compared across machines of different speed. The reference itself is
+// dropped from the results.
+//
+// The reference is matched by name, ignoring the "Benchmark" prefix and the
+// GOMAXPROCS suffix. When the run used several -cpu values, each benchmark
+// is divided by the reference measured with the same GOMAXPROCS.
+func Normalize(run *models.BenchmarkRun, reference string) (*models.BenchmarkRun, error) {
+	reference = benchmarkKey(reference)
+
+	refs := make(map[string]float64) // GOMAXPROCS suffix -> reference ns/op
+	var only float64                 // The reference, if it was measured once
+	for _, result := range run.Results {
+		if benchmarkKey(result.Name) != reference || !result.Measured() || result.NsPerOp <= 0 {
+			continue
+		}
+		refs[procsSuffix(result.Name)] = result.NsPerOp
+		only = result.NsPerOp
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("run %s has no measurement of reference benchmark %q", run.ID, reference)
+	}
+
+	normalized := *run
+	normalized.NormalizedTo = reference
+	normalized.Results = make([]models.BenchmarkResult, 0, len(run.Results))
+	for _, result := range run.Results {
+		if benchmarkKey(result.Name) == reference {
+			continue
+		}
+		if result.Measured() {
+			ref, ok := refs[procsSuffix(result.Name)]
+			if !ok && len(refs) > 1 {
+				return nil, fmt.Errorf("run %s has no reference %q measured like %s", run.ID, reference, result.Name)
+			}
+			if !ok {
+				ref = only
+			}
+			result.NsPerOp /= ref
+			if result.Samples != nil {
+				samples := make([]float64, len(result.Samples))
+				for i, sample := range result.Samples {
+					samples[i] = sample / ref
+				}
+				result.Samples = samples
+			}
+		}
+		normalized.Results = append(normalized.Results, result)
+	}
+	return &normalized, nil
+}
+
+// benchmarkKey strips the "Benchmark" prefix and GOMAXPROCS suffix from a name
+func benchmarkKey(name string) string {
+	return trimProcs(strings.TrimPrefix(name, "Benchmark"))
+}
+
+// procsSuffix returns the GOMAXPROCS suffix of a benchmark name, e.g. "-8"
+func procsSuffix(name string) string {
+	return name[len(trimProcs(name)):]
+}
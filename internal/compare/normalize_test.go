@@ -0,0 +1,98 @@
+package compare
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestNormalize(t *testing.T) {
+	run := &models.BenchmarkRun{
+		ID: "run-1",
+		Results: []models.BenchmarkResult{
+			{Name: "Calibrate-8", NsPerOp: 50},
+			{Name: "Parse-8", NsPerOp: 200},
+			{Name: "Broken-8", Status: models.StatusFailed},
+		},
+	}
+
+	for _, reference := range []string{"Calibrate", "BenchmarkCalibrate", "Calibrate-8"} {
+		normalized, err := Normalize(run, reference)
+		if err != nil {
+			t.Fatalf("Normalize(%q) failed: %v", reference, err)
+		}
+		if normalized.NormalizedTo != "Calibrate" {
+			t.Errorf("NormalizedTo = %q, want Calibrate", normalized.NormalizedTo)
+		}
+		if len(normalized.Results) != 2 {
+			t.Fatalf("expected the reference to be dropped, got %+v", normalized.Results)
+		}
+		if normalized.Results[0].NsPerOp != 4 {
+			t.Errorf("Parse normalized to %v, want 4", normalized.Results[0].NsPerOp)
+		}
+		if normalized.Results[1].Status != models.StatusFailed {
+			t.Errorf("failed result not kept: %+v", normalized.Results[1])
+		}
+	}
+
+	if run.Results[1].NsPerOp != 200 || run.NormalizedTo != "" {
+		t.Error("Normalize modified the original run")
+	}
+}
+
+func TestNormalizePerGOMAXPROCS(t *testing.T) {
+	run := &models.BenchmarkRun{Results: []models.BenchmarkResult{
+		{Name: "Calibrate-1", NsPerOp: 100},
+		{Name: "Calibrate-4", NsPerOp: 25},
+		{Name: "Sum-1", NsPerOp: 300},
+		{Name: "Sum-4", NsPerOp: 100},
+	}}
+
+	normalized, err := Normalize(run, "Calibrate")
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if normalized.Results[0].NsPerOp != 3 || normalized.Results[1].NsPerOp != 4 {
+		t.Errorf("unexpected normalized values: %+v", normalized.Results)
+	}
+
+	run.Results = append(run.Results, models.BenchmarkResult{Name: "Sum-2", NsPerOp: 10})
+	if _, err := Normalize(run, "Calibrate"); err == nil {
+		t.Error("expected an error without a reference for the same GOMAXPROCS")
+	}
+}
+
+func TestNormalizeMissingReference(t *testing.T) {
+	run := &models.BenchmarkRun{ID: "run-1", Results: []models.BenchmarkResult{
+		{Name: "Calibrate-8", Status: models.StatusSkipped},
+		{Name: "Parse-8", NsPerOp: 200},
+	}}
+	if _, err := Normalize(run, "Calibrate"); err == nil || !strings.Contains(err.Error(), "run-1") {
+		t.Errorf("expected an error naming the run, got %v", err)
+	}
+}
+
+func TestCompareNormalizedRuns(t *testing.T) {
+	// The new machine is twice as fast overall, but Parse only got 1.5x faster
+	oldRun, _ := Normalize(&models.BenchmarkRun{Results: []models.BenchmarkResult{
+		{Name: "Calibrate-8", NsPerOp: 100},
+		{Name: "Parse-8", NsPerOp: 300},
+	}}, "Calibrate")
+	newRun, _ := Normalize(&models.BenchmarkRun{Results: []models.BenchmarkResult{
+		{Name: "Calibrate-8", NsPerOp: 50},
+		{Name: "Parse-8", NsPerOp: 200},
+	}}, "Calibrate")
+
+	comparisons := NewComparer().Compare(oldRun, newRun)
+	if len(comparisons) != 1 {
+		t.Fatalf("expected 1 comparison, got %+v", comparisons)
+	}
+	comp := comparisons[0]
+	if comp.Status != "degraded" || comp.Unit != NormalizedUnit {
+		t.Errorf("unexpected comparison: %+v", comp)
+	}
+	if line := FormatComparison(comp); !strings.Contains(line, "3.00 x ref → ") {
+		t.Errorf("FormatComparison() = %q, want normalized unit", line)
+	}
+}
@@ -0,0 +1,87 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// PackageSummary aggregates the comparisons of the benchmarks of a package
+type PackageSummary struct {
+	Package   string
+	Improved  int
+	Degraded  int
+	Unchanged int
+	Failed    int
+	Added     int
+	Removed   int
+
+	// Geometric mean of the ratios of new to old ns/op of the compared
+	// benchmarks, as a percentage change; 0 without compared benchmarks
+	GeomeanDelta float64
+}
+
+// PackageSummaries aggregates comparisons by package, in import path order.
+// Benchmarks of runs that recorded no package are grouped under "".
+func PackageSummaries(comparisons []models.Comparison) []PackageSummary {
+	byPackage := make(map[string]*PackageSummary)
+	logSums := make(map[string]float64)
+	compared := make(map[string]int)
+	for _, comp := range comparisons {
+		summary, ok := byPackage[comp.Package]
+		if !ok {
+			summary = &PackageSummary{Package: comp.Package}
+			byPackage[comp.Package] = summary
+		}
+		switch comp.Status {
+		case "improved":
+			summary.Improved++
+		case "degraded":
+			summary.Degraded++
+		case "same":
+			summary.Unchanged++
+		case models.StatusFailed:
+			summary.Failed++
+		case models.StatusAdded:
+			summary.Added++
+		case models.StatusRemoved:
+			summary.Removed++
+		}
+		if comp.OldNsPerOp > 0 && comp.NewNsPerOp > 0 {
+			logSums[comp.Package] += math.Log(comp.NewNsPerOp / comp.OldNsPerOp)
+			compared[comp.Package]++
+		}
+	}
+
+	summaries := make([]PackageSummary, 0, len(byPackage))
+	for pkg, summary := range byPackage {
+		if n := compared[pkg]; n > 0 {
+			summary.GeomeanDelta = (math.Exp(logSums[pkg]/float64(n)) - 1) * 100
+		}
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Package < summaries[j].Package })
+	return summaries
+}
+
+// FormatPackageSummary formats the summary of a package as a single line
+func FormatPackageSummary(s PackageSummary) string {
+	pkg := s.Package
+	if pkg == "" {
+		pkg = "(unknown package)"
+	}
+	line := fmt.Sprintf("%s: %+.2f%% geomean, %d improved, %d degraded, %d unchanged",
+		pkg, s.GeomeanDelta, s.Improved, s.Degraded, s.Unchanged)
+	if s.Failed > 0 {
+		line += fmt.Sprintf(", %d failed", s.Failed)
+	}
+	if s.Added > 0 {
+		line += fmt.Sprintf(", %d added", s.Added)
+	}
+	if s.Removed > 0 {
+		line += fmt.Sprintf(", %d removed", s.Removed)
+	}
+	return line
+}
@@ -0,0 +1,37 @@
+package compare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestPackageSummaries(t *testing.T) {
+	comparisons := []models.Comparison{
+		{Name: "B", Package: "example.com/b", OldNsPerOp: 100, NewNsPerOp: 200, Status: "degraded"},
+		{Name: "A1", Package: "example.com/a", OldNsPerOp: 100, NewNsPerOp: 50, Status: "improved"},
+		{Name: "A2", Package: "example.com/a", OldNsPerOp: 100, NewNsPerOp: 200, Status: "degraded"},
+		{Name: "A3", Package: "example.com/a", NewNsPerOp: 10, Status: models.StatusAdded},
+	}
+
+	summaries := PackageSummaries(comparisons)
+	if len(summaries) != 2 || summaries[0].Package != "example.com/a" || summaries[1].Package != "example.com/b" {
+		t.Fatalf("PackageSummaries() = %+v", summaries)
+	}
+	a := summaries[0]
+	if a.Improved != 1 || a.Degraded != 1 || a.Added != 1 {
+		t.Errorf("Summary of a = %+v", a)
+	}
+	// Halving and doubling cancel out, added benchmarks are not compared
+	if math.Abs(a.GeomeanDelta) > 1e-9 {
+		t.Errorf("Geomean delta of a = %v, want 0", a.GeomeanDelta)
+	}
+	if b := summaries[1]; math.Abs(b.GeomeanDelta-100) > 1e-9 {
+		t.Errorf("Geomean delta of b = %v, want 100", b.GeomeanDelta)
+	}
+
+	if got, want := FormatPackageSummary(a), "example.com/a: +0.00% geomean, 1 improved, 1 degraded, 0 unchanged, 1 added"; got != want {
+		t.Errorf("FormatPackageSummary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+package compare
+
+import (
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
+)
+
+// ScalingLevel compares a benchmark at one GOMAXPROCS value
+type ScalingLevel struct {
+	Procs        int     `json:"procs"`
+	OldNsPerOp   float64 `json:"oldNsPerOp"`
+	NewNsPerOp   float64 `json:"newNsPerOp"`
+	DeltaPercent float64 `json:"deltaPercent"`
+	OldSpeedup   float64 `json:"oldSpeedup"` // Throughput relative to the lowest level of the old run
+	NewSpeedup   float64 `json:"newSpeedup"` // Throughput relative to the lowest level of the new run
+}
+
+// ScalingComparison compares the scalability curves of a benchmark in two
+// runs with a CPU matrix
+type ScalingComparison struct {
+	Benchmark string         `json:"benchmark"`
+	Levels    []ScalingLevel `json:"levels"` // GOMAXPROCS values measured in both runs, increasing
+}
+
+// Scaling compares the scalability curves of the benchmarks measured at
+// several GOMAXPROCS values in both runs, sorted by name. Speedups are
+// relative to the lowest GOMAXPROCS value both runs measured.
+func Scaling(oldRun, newRun *models.BenchmarkRun) []ScalingComparison {
+	oldCurves := make(map[string]stats.StressCurve)
+	for _, curve := range scalingCurves(oldRun) {
+		oldCurves[curve.Benchmark] = curve
+	}
+
+	var comparisons []ScalingComparison
+	for _, curve := range scalingCurves(newRun) {
+		old, ok := oldCurves[curve.Benchmark]
+		if !ok {
+			continue
+		}
+		oldPoints := make(map[int]float64, len(old.Points))
+		for _, p := range old.Points {
+			oldPoints[p.Parallelism] = p.NsPerOp
+		}
+
+		var levels []ScalingLevel
+		for _, p := range curve.Points {
+			oldNs, ok := oldPoints[p.Parallelism]
+			if !ok {
+				continue
+			}
+			levels = append(levels, ScalingLevel{
+				Procs:        p.Parallelism,
+				OldNsPerOp:   oldNs,
+				NewNsPerOp:   p.NsPerOp,
+				DeltaPercent: (p.NsPerOp - oldNs) / oldNs * 100,
+			})
+		}
+		if len(levels) < 2 {
+			continue
+		}
+		base := levels[0]
+		for i := range levels {
+			levels[i].OldSpeedup = base.OldNsPerOp / levels[i].OldNsPerOp
+			levels[i].NewSpeedup = base.NewNsPerOp / levels[i].NewNsPerOp
+		}
+		comparisons = append(comparisons, ScalingComparison{Benchmark: curve.Benchmark, Levels: levels})
+	}
+	return comparisons
+}
+
+// scalingCurves returns the curves of a run's CPU matrix, or of its stress
+// matrix for stress runs
+func scalingCurves(run *models.BenchmarkRun) []stats.StressCurve {
+	if run.Stress != nil {
+		return stats.StressReport(run)
+	}
+	return stats.ScalingReport(run)
+}
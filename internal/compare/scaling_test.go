@@ -0,0 +1,46 @@
+package compare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestScaling(t *testing.T) {
+	oldRun := &models.BenchmarkRun{
+		CPUMatrix: []int{1, 2, 4},
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkEncode", NsPerOp: 400, Procs: 1},
+			{Name: "BenchmarkEncode-2", NsPerOp: 200, Procs: 2},
+			{Name: "BenchmarkEncode-4", NsPerOp: 100, Procs: 4},
+			{Name: "BenchmarkRemoved", NsPerOp: 10, Procs: 1},
+			{Name: "BenchmarkRemoved-2", NsPerOp: 5, Procs: 2},
+		},
+	}
+	newRun := &models.BenchmarkRun{
+		CPUMatrix: []int{1, 4, 8},
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkEncode", NsPerOp: 400, Procs: 1},
+			{Name: "BenchmarkEncode-4", NsPerOp: 200, Procs: 4},
+			{Name: "BenchmarkEncode-8", NsPerOp: 300, Procs: 8},
+		},
+	}
+
+	scaling := Scaling(oldRun, newRun)
+	if len(scaling) != 1 || scaling[0].Benchmark != "BenchmarkEncode" {
+		t.Fatalf("Scaling() = %+v", scaling)
+	}
+	// Only the GOMAXPROCS values both runs measured are compared
+	levels := scaling[0].Levels
+	if len(levels) != 2 || levels[0].Procs != 1 || levels[1].Procs != 4 {
+		t.Fatalf("Levels = %+v", levels)
+	}
+	if l := levels[1]; math.Abs(l.DeltaPercent-100) > 1e-9 || l.OldSpeedup != 4 || l.NewSpeedup != 2 {
+		t.Errorf("Level 4 = %+v", l)
+	}
+
+	if Scaling(&models.BenchmarkRun{Results: oldRun.Results}, newRun) != nil {
+		t.Error("Expected no comparison for a run without a CPU matrix")
+	}
+}
@@ -0,0 +1,66 @@
+package compare
+
+import (
+	"fmt"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// ToolchainMismatch is a build setting that differs between two runs
+type ToolchainMismatch struct {
+	Setting string `json:"setting"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+}
+
+// ToolchainMismatches returns the build settings that differ between two
+// runs. Nothing is reported unless both runs recorded their settings, and
+// only settings recorded in both are compared: a run imported from
+// benchmark output knows its platform alone. GOFLAGS and -gcflags, empty
+// when not set, are compared only when both runs recorded all settings.
+func ToolchainMismatches(oldRun, newRun *models.BenchmarkRun) []ToolchainMismatch {
+	if oldRun.Toolchain == nil || newRun.Toolchain == nil {
+		return nil
+	}
+	o, n := oldRun.Toolchain, newRun.Toolchain
+	// go env always reports CGO_ENABLED, so runs without it recorded less
+	complete := o.CGOEnabled != "" && n.CGOEnabled != ""
+
+	settings := []struct {
+		name     string
+		old, new string
+		optional bool // Empty when not set rather than when not recorded
+	}{
+		{"GOOS", o.GOOS, n.GOOS, false},
+		{"GOARCH", o.GOARCH, n.GOARCH, false},
+		{"GOAMD64", o.GOAMD64, n.GOAMD64, false},
+		{"GOARM", o.GOARM, n.GOARM, false},
+		{"GOARM64", o.GOARM64, n.GOARM64, false},
+		{"CGO_ENABLED", o.CGOEnabled, n.CGOEnabled, false},
+		{"GOFLAGS", o.GOFLAGS, n.GOFLAGS, true},
+		{"-gcflags", o.GCFlags, n.GCFlags, true},
+	}
+
+	var mismatches []ToolchainMismatch
+	for _, s := range settings {
+		recorded := complete
+		if !s.optional {
+			recorded = s.old != "" && s.new != ""
+		}
+		if recorded && s.old != s.new {
+			mismatches = append(mismatches, ToolchainMismatch{Setting: s.name, Old: s.old, New: s.new})
+		}
+	}
+	return mismatches
+}
+
+// FormatToolchainMismatch formats a mismatch, e.g. "GOAMD64 differs: v1 → v3"
+func FormatToolchainMismatch(m ToolchainMismatch) string {
+	unsetIfEmpty := func(s string) string {
+		if s == "" {
+			return "(unset)"
+		}
+		return s
+	}
+	return fmt.Sprintf("%s differs: %s → %s", m.Setting, unsetIfEmpty(m.Old), unsetIfEmpty(m.New))
+}
@@ -0,0 +1,48 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestToolchainMismatches(t *testing.T) {
+	base := models.Toolchain{GOOS: "linux", GOARCH: "amd64", GOAMD64: "v1", CGOEnabled: "1"}
+	changed := base
+	changed.GOAMD64 = "v3"
+	changed.GCFlags = "-B"
+
+	oldRun := &models.BenchmarkRun{Toolchain: &base}
+	newRun := &models.BenchmarkRun{Toolchain: &changed}
+
+	want := []ToolchainMismatch{
+		{Setting: "GOAMD64", Old: "v1", New: "v3"},
+		{Setting: "-gcflags", Old: "", New: "-B"},
+	}
+	got := ToolchainMismatches(oldRun, newRun)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ToolchainMismatches() = %+v, want %+v", got, want)
+	}
+	if s := FormatToolchainMismatch(got[1]); s != "-gcflags differs: (unset) → -B" {
+		t.Errorf("FormatToolchainMismatch() = %q", s)
+	}
+
+	if got := ToolchainMismatches(oldRun, oldRun); len(got) != 0 {
+		t.Errorf("expected no mismatches for identical settings, got %+v", got)
+	}
+	if got := ToolchainMismatches(&models.BenchmarkRun{}, newRun); len(got) != 0 {
+		t.Errorf("expected no mismatches without recorded settings, got %+v", got)
+	}
+
+	// An imported run records its platform alone
+	imported := &models.BenchmarkRun{Toolchain: &models.Toolchain{GOOS: "linux", GOARCH: "amd64"}}
+	if got := ToolchainMismatches(imported, newRun); len(got) != 0 {
+		t.Errorf("expected no mismatches for settings recorded in one run only, got %+v", got)
+	}
+	imported.Toolchain.GOARCH = "arm64"
+	want = []ToolchainMismatch{{Setting: "GOARCH", Old: "arm64", New: "amd64"}}
+	if got := ToolchainMismatches(imported, newRun); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToolchainMismatches() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,568 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/schedule"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the project configuration file, looked up in the working
+// directory and its parents
+const DefaultFile = ".gokanon.yaml"
+
+// Config holds project-level gokanon settings
+type Config struct {
+	// Owner is the team owning the project, grouping it in fleet reports
+	Owner string `yaml:"owner"`
+
+	// Metrics are extractors for domain metrics printed by benchmarks
+	Metrics []MetricExtractor `yaml:"metrics"`
+
+	// Matching holds the rules for pairing benchmarks across runs
+	Matching Matching `yaml:"matching"`
+
+	// Normalization divides results by a reference benchmark when comparing
+	Normalization Normalization `yaml:"normalization"`
+
+	// Score weights benchmarks in the per-run performance score
+	Score Score `yaml:"score"`
+
+	// SLOs are service level objectives on benchmark results
+	SLOs []SLO `yaml:"slos"`
+
+	// Alerts are rate-of-change rules evaluated after every saved run
+	Alerts []Alert `yaml:"alerts"`
+
+	// Schedules are the benchmark runs of 'gokanon daemon'
+	Schedules []Schedule `yaml:"schedules"`
+
+	// Defaults are used for command-line flags that are not given
+	Defaults Defaults `yaml:"defaults"`
+
+	// Thresholds override the -threshold of check and ci for individual
+	// benchmarks: benchmark name or pattern -> maximum degradation (%)
+	Thresholds map[string]float64 `yaml:"thresholds"`
+
+	// Remote is the bucket that sync shares the history through
+	Remote Remote `yaml:"remote"`
+
+	// Retention is the policy of 'gokanon prune'
+	Retention Retention `yaml:"retention"`
+
+	// Interactive configures the prompt and history of 'gokanon interactive'
+	Interactive Interactive `yaml:"interactive"`
+}
+
+// Interactive configures the interactive session
+type Interactive struct {
+	Prompt       string `yaml:"prompt,omitempty"`        // Prompt (default "gokanon> ")
+	History      string `yaml:"history,omitempty"`       // History file, relative to the configuration file; "off" keeps no history
+	HistoryLimit int    `yaml:"history_limit,omitempty"` // Lines kept in the history file (default 1000)
+}
+
+// Retention selects the runs and profiles 'gokanon prune' deletes, and
+// whether they are pruned after every run. Ages are days (90d), weeks (2w)
+// or Go durations (36h).
+type Retention struct {
+	AutoPrune         bool   `yaml:"auto_prune,omitempty"`          // Prune after every saved run
+	KeepLast          int    `yaml:"keep_last,omitempty"`           // Never delete the newest runs
+	OlderThan         string `yaml:"older_than,omitempty"`          // Delete runs older than this age
+	ProfilesOlderThan string `yaml:"profiles_older_than,omitempty"` // Delete only the profiles of runs older than this age
+	KeepBaselines     *bool  `yaml:"keep_baselines,omitempty"`      // Keep the runs baselines point at (default true)
+}
+
+// BaselinesKept reports whether the runs baselines point at are kept
+func (r Retention) BaselinesKept() bool {
+	return r.KeepBaselines == nil || *r.KeepBaselines
+}
+
+// Prunes reports whether the policy deletes anything
+func (r Retention) Prunes() bool {
+	return r.KeepLast > 0 || r.OlderThan != "" || r.ProfilesOlderThan != ""
+}
+
+// ParseAge parses an age such as 30d or 2w, or a Go duration such as 36h
+func ParseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid number of %s: %q", suffix, n)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	age, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if age < 0 {
+		return 0, fmt.Errorf("negative age %s", s)
+	}
+	return age, nil
+}
+
+// Remote configures the S3-compatible bucket used by sync, and the perf
+// data server used by push. Credentials are only read from the
+// environment so that they are never committed.
+type Remote struct {
+	URL      string `yaml:"url,omitempty"`      // s3://bucket/prefix or gs://bucket/prefix
+	Endpoint string `yaml:"endpoint,omitempty"` // S3-compatible service, e.g. "http://localhost:9000" for MinIO
+	Region   string `yaml:"region,omitempty"`   // Bucket region (default AWS_REGION or us-east-1)
+	PerfData string `yaml:"perfdata,omitempty"` // perf storage server that 'gokanon push' uploads to
+}
+
+// Defaults are project-wide values for command-line flags, so that long
+// flag lists need not be repeated. A flag given on the command line always
+// takes precedence.
+type Defaults struct {
+	Storage   string         `yaml:"storage,omitempty"`   // -storage, relative to the configuration file
+	Bench     string         `yaml:"bench,omitempty"`     // -bench filter
+	Benchtime string         `yaml:"benchtime,omitempty"` // -benchtime, e.g. "2s" or "1000x"
+	Count     int            `yaml:"count,omitempty"`     // -count of run, ci and bisect
+	Threshold float64        `yaml:"threshold,omitempty"` // -threshold of check, ci and bisect, in percent
+	Open      bool           `yaml:"open,omitempty"`      // -open of serve, flamegraph and export, ignored in CI
+	Export    ExportDefaults `yaml:"export,omitempty"`
+	AI        AIDefaults     `yaml:"ai,omitempty"`
+}
+
+// ExportDefaults are defaults of the export command
+type ExportDefaults struct {
+	Format string `yaml:"format,omitempty"` // -format, e.g. "markdown"
+}
+
+// AIDefaults configure the AI analysis. The GOKANON_AI_* environment
+// variables take precedence; API keys are only read from the environment
+// so that they are never committed.
+type AIDefaults struct {
+	Enabled  bool         `yaml:"enabled,omitempty"`
+	Provider string       `yaml:"provider,omitempty"` // e.g. "ollama" or "anthropic"
+	Model    string       `yaml:"model,omitempty"`
+	BaseURL  string       `yaml:"base_url,omitempty"`
+	Timeout  string       `yaml:"timeout,omitempty"`  // Timeout of a request, e.g. "2m" (default 60s)
+	Retries  int          `yaml:"retries,omitempty"`  // Attempts after a failed request, backing off exponentially
+	Fallback []AIProvider `yaml:"fallback,omitempty"` // Providers asked in order when the provider fails
+}
+
+// AIProvider is a fallback AI provider. Its API key is read from
+// GOKANON_AI_<PROVIDER>_API_KEY.
+type AIProvider struct {
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model,omitempty"`
+	BaseURL  string `yaml:"base_url,omitempty"`
+	Timeout  string `yaml:"timeout,omitempty"` // Overrides the timeout of the provider
+}
+
+// MetricExtractor describes how to extract a domain metric from benchmark
+// output. Exactly one of Regex or JSONPath must be set.
+type MetricExtractor struct {
+	Name     string `yaml:"name"`                // Metric name stored on results
+	Regex    string `yaml:"regex,omitempty"`     // Pattern whose first capture group is the value
+	JSONPath string `yaml:"json_path,omitempty"` // Dotted path into a JSON object printed on one line
+	Unit     string `yaml:"unit,omitempty"`      // Optional unit for display
+}
+
+// Matching configures how benchmarks are paired when comparing runs, so
+// history survives renames and moves between packages
+type Matching struct {
+	StripCPUSuffix *bool             `yaml:"strip_cpu_suffix,omitempty"` // Ignore "-N" GOMAXPROCS suffixes (default true)
+	StripPatterns  []string          `yaml:"strip_patterns,omitempty"`   // Regexes removed from names before matching
+	PackageRenames map[string]string `yaml:"package_renames,omitempty"`  // Old import path -> new import path
+}
+
+// Normalization configures machine-independent comparisons. When Reference
+// is set, every benchmark's ns/op is divided by the ns/op of the reference
+// benchmark (a calibration loop) measured in the same run.
+type Normalization struct {
+	Reference string `yaml:"reference,omitempty"` // Name of the reference benchmark, e.g. "Calibrate"
+}
+
+// Score configures the performance score, the weighted geometric mean of
+// benchmark throughput tracked as a single number per run
+type Score struct {
+	Weights map[string]float64 `yaml:"weights,omitempty"` // Benchmark name or pattern -> weight (default 1, 0 excludes)
+}
+
+// SLO is a service level objective on a benchmark, such as "the p95 ns/op
+// of Checkout over the last 20 runs is at most 2ms". Exactly one of Max or
+// Min must be set; Min is for metrics where higher is better, and then the
+// complementary percentile (p5 for 95) must stay above it.
+type SLO struct {
+	Name       string  `yaml:"name"`                 // Unique name shown in reports
+	Benchmark  string  `yaml:"benchmark"`            // Benchmark name, with or without the "Benchmark" prefix
+	Metric     string  `yaml:"metric,omitempty"`     // "ns/op" (default) or a custom metric name
+	Percentile float64 `yaml:"percentile,omitempty"` // Percentile of the window that must meet the bound (default 95)
+	Window     int     `yaml:"window,omitempty"`     // Number of most recent runs evaluated (default 20)
+	Max        string  `yaml:"max,omitempty"`        // Upper bound; ns/op also accepts durations such as "2ms"
+	Min        string  `yaml:"min,omitempty"`        // Lower bound
+}
+
+// TargetPercentile returns the percentile of the objective
+func (s SLO) TargetPercentile() float64 {
+	if s.Percentile == 0 {
+		return 95
+	}
+	return s.Percentile
+}
+
+// WindowRuns returns the number of runs the objective is evaluated over
+func (s SLO) WindowRuns() int {
+	if s.Window == 0 {
+		return 20
+	}
+	return s.Window
+}
+
+// MetricName returns the metric the objective applies to
+func (s SLO) MetricName() string {
+	if s.Metric == "" {
+		return "ns/op"
+	}
+	return s.Metric
+}
+
+// Bound returns the limit of the objective and whether it is an upper bound
+func (s SLO) Bound() (limit float64, upper bool, err error) {
+	value, upper := s.Max, true
+	if s.Min != "" {
+		value, upper = s.Min, false
+	}
+
+	if s.MetricName() == "ns/op" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return float64(d.Nanoseconds()), upper, nil
+		}
+	}
+	limit, err = strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, upper, fmt.Errorf("invalid bound %q", value)
+	}
+	return limit, upper, nil
+}
+
+// Alert is a rate-of-change rule, such as "the ns/op of Checkout increased
+// by more than 2% per day over the last 7 days". It catches slow regressions
+// that no single comparison flags. Exactly one of MaxIncrease or MaxDecrease
+// must be set; MaxDecrease is for metrics where higher is better.
+type Alert struct {
+	Name        string   `yaml:"name"`                   // Unique name shown in notifications
+	Benchmark   string   `yaml:"benchmark"`              // Benchmark name or pattern, such as "Checkout*"
+	Metric      string   `yaml:"metric,omitempty"`       // "ns/op" (default) or a custom metric name
+	MaxIncrease float64  `yaml:"max_increase,omitempty"` // Largest increase (%) per period
+	MaxDecrease float64  `yaml:"max_decrease,omitempty"` // Largest decrease (%) per period
+	Per         string   `yaml:"per,omitempty"`          // Period of the rate (default 1d)
+	Over        string   `yaml:"over,omitempty"`         // Runs this old or newer are fitted (default 7d)
+	MinRuns     int      `yaml:"min_runs,omitempty"`     // Fewest runs the trend is fitted on (default 3)
+	Notify      []string `yaml:"notify,omitempty"`       // Channels: "webhook:<url>" or "slack:<url>"
+}
+
+// Schedule is a benchmark run 'gokanon daemon' starts on a cron schedule.
+// Each run is compared with the previous run of the schedule, and
+// regressions beyond the threshold are sent to the notification channels.
+type Schedule struct {
+	Name      string   `yaml:"name"`                // Unique name, recorded as the run's schedule tag
+	Cron      string   `yaml:"cron"`                // Five cron fields, a shorthand such as @daily, or "@every 6h"
+	Pkg       string   `yaml:"pkg,omitempty"`       // Package or pattern (default ./...)
+	Bench     string   `yaml:"bench,omitempty"`     // Benchmark filter (default .)
+	Benchtime string   `yaml:"benchtime,omitempty"` // -benchtime of go test
+	Count     int      `yaml:"count,omitempty"`     // Repetitions of each benchmark
+	Threshold float64  `yaml:"threshold,omitempty"` // Regression (%) notified (default 5)
+	Notify    []string `yaml:"notify,omitempty"`    // Channels: "webhook:<url>" or "slack:<url>"
+}
+
+// RegressionThreshold returns the regression (%) notified
+func (s Schedule) RegressionThreshold() float64 {
+	if s.Threshold == 0 {
+		return 5
+	}
+	return s.Threshold
+}
+
+// MetricName returns the metric the rule applies to
+func (a Alert) MetricName() string {
+	if a.Metric == "" {
+		return "ns/op"
+	}
+	return a.Metric
+}
+
+// Period returns the period of the rate
+func (a Alert) Period() (time.Duration, error) {
+	if a.Per == "" {
+		return 24 * time.Hour, nil
+	}
+	return ParseAge(a.Per)
+}
+
+// Window returns the age of the oldest runs fitted
+func (a Alert) Window() (time.Duration, error) {
+	if a.Over == "" {
+		return 7 * 24 * time.Hour, nil
+	}
+	return ParseAge(a.Over)
+}
+
+// Runs returns the fewest runs the trend is fitted on
+func (a Alert) Runs() int {
+	if a.MinRuns == 0 {
+		return 3
+	}
+	return a.MinRuns
+}
+
+// CPUSuffixStripped reports whether GOMAXPROCS suffixes are ignored
+func (m Matching) CPUSuffixStripped() bool {
+	return m.StripCPUSuffix == nil || *m.StripCPUSuffix
+}
+
+// Load reads the configuration from path. A missing file yields an empty
+// configuration so that gokanon works without any setup.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	// The storage directory is relative to the project, not to wherever
+	// gokanon is invoked
+	if cfg.Defaults.Storage != "" && !filepath.IsAbs(cfg.Defaults.Storage) {
+		cfg.Defaults.Storage = filepath.Join(filepath.Dir(path), cfg.Defaults.Storage)
+	}
+	if h := cfg.Interactive.History; h != "" && h != HistoryOff && !filepath.IsAbs(h) {
+		cfg.Interactive.History = filepath.Join(filepath.Dir(path), h)
+	}
+
+	return &cfg, nil
+}
+
+// validateTimeout checks that a timeout, if set, is a positive duration
+func validateTimeout(timeout string) error {
+	if timeout == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("must be positive, got %s", timeout)
+	}
+	return nil
+}
+
+// Validate checks the configuration for errors
+func (c *Config) Validate() error {
+	seen := make(map[string]bool)
+	for i, m := range c.Metrics {
+		if m.Name == "" {
+			return fmt.Errorf("metrics[%d]: name is required", i)
+		}
+		if seen[m.Name] {
+			return fmt.Errorf("metrics[%d]: duplicate metric name %q", i, m.Name)
+		}
+		seen[m.Name] = true
+
+		if (m.Regex == "") == (m.JSONPath == "") {
+			return fmt.Errorf("metric %q: exactly one of regex or json_path must be set", m.Name)
+		}
+		if m.Regex != "" {
+			re, err := regexp.Compile(m.Regex)
+			if err != nil {
+				return fmt.Errorf("metric %q: invalid regex: %w", m.Name, err)
+			}
+			if re.NumSubexp() < 1 {
+				return fmt.Errorf("metric %q: regex must have a capture group for the value", m.Name)
+			}
+		}
+	}
+
+	for i, pattern := range c.Matching.StripPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("matching.strip_patterns[%d]: invalid regex: %w", i, err)
+		}
+	}
+	for from, to := range c.Matching.PackageRenames {
+		if from == "" || to == "" {
+			return fmt.Errorf("matching.package_renames: empty import path in %q -> %q", from, to)
+		}
+	}
+	names := make(map[string]bool)
+	for i, slo := range c.SLOs {
+		if slo.Name == "" || slo.Benchmark == "" {
+			return fmt.Errorf("slos[%d]: name and benchmark are required", i)
+		}
+		if names[slo.Name] {
+			return fmt.Errorf("slos[%d]: duplicate SLO name %q", i, slo.Name)
+		}
+		names[slo.Name] = true
+
+		if (slo.Max == "") == (slo.Min == "") {
+			return fmt.Errorf("slo %q: exactly one of max or min must be set", slo.Name)
+		}
+		if _, _, err := slo.Bound(); err != nil {
+			return fmt.Errorf("slo %q: %w", slo.Name, err)
+		}
+		if p := slo.TargetPercentile(); p <= 0 || p >= 100 {
+			return fmt.Errorf("slo %q: percentile must be between 0 and 100, got %v", slo.Name, p)
+		}
+		if slo.Window < 0 {
+			return fmt.Errorf("slo %q: window must be positive", slo.Name)
+		}
+	}
+	names = make(map[string]bool)
+	for i, alert := range c.Alerts {
+		if alert.Name == "" || alert.Benchmark == "" {
+			return fmt.Errorf("alerts[%d]: name and benchmark are required", i)
+		}
+		if names[alert.Name] {
+			return fmt.Errorf("alerts[%d]: duplicate alert name %q", i, alert.Name)
+		}
+		names[alert.Name] = true
+
+		if _, err := path.Match(alert.Benchmark, ""); err != nil {
+			return fmt.Errorf("alert %q: invalid benchmark pattern: %w", alert.Name, err)
+		}
+		if alert.MaxIncrease < 0 || alert.MaxDecrease < 0 {
+			return fmt.Errorf("alert %q: max_increase and max_decrease must be positive", alert.Name)
+		}
+		if (alert.MaxIncrease == 0) == (alert.MaxDecrease == 0) {
+			return fmt.Errorf("alert %q: exactly one of max_increase or max_decrease must be set", alert.Name)
+		}
+		if per, err := alert.Period(); err != nil || per == 0 {
+			return fmt.Errorf("alert %q: invalid per %q", alert.Name, alert.Per)
+		}
+		if over, err := alert.Window(); err != nil || over == 0 {
+			return fmt.Errorf("alert %q: invalid over %q", alert.Name, alert.Over)
+		}
+		if alert.MinRuns < 0 || alert.MinRuns == 1 {
+			return fmt.Errorf("alert %q: min_runs must be at least 2", alert.Name)
+		}
+		for _, channel := range alert.Notify {
+			if err := validateChannel(channel); err != nil {
+				return fmt.Errorf("alert %q: %w", alert.Name, err)
+			}
+		}
+	}
+	names = make(map[string]bool)
+	for i, s := range c.Schedules {
+		if s.Name == "" || s.Cron == "" {
+			return fmt.Errorf("schedules[%d]: name and cron are required", i)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("schedules[%d]: duplicate schedule name %q", i, s.Name)
+		}
+		names[s.Name] = true
+
+		if _, err := schedule.Parse(s.Cron); err != nil {
+			return fmt.Errorf("schedule %q: %w", s.Name, err)
+		}
+		if s.Count < 0 || s.Threshold < 0 {
+			return fmt.Errorf("schedule %q: count and threshold must be positive", s.Name)
+		}
+		for _, channel := range s.Notify {
+			if err := validateChannel(channel); err != nil {
+				return fmt.Errorf("schedule %q: %w", s.Name, err)
+			}
+		}
+	}
+	for pattern, weight := range c.Score.Weights {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("score.weights: invalid pattern %q: %w", pattern, err)
+		}
+		if weight < 0 {
+			return fmt.Errorf("score.weights: negative weight %v for %q", weight, pattern)
+		}
+	}
+	if c.Interactive.HistoryLimit < 0 {
+		return fmt.Errorf("interactive.history_limit must be positive")
+	}
+	for pattern, percent := range c.Thresholds {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("thresholds: invalid pattern %q: %w", pattern, err)
+		}
+		if percent < 0 {
+			return fmt.Errorf("thresholds: negative threshold %v for %q", percent, pattern)
+		}
+	}
+	if c.Remote.URL != "" && !strings.HasPrefix(c.Remote.URL, "s3://") && !strings.HasPrefix(c.Remote.URL, "gs://") {
+		return fmt.Errorf("remote.url must start with s3:// or gs://, got %q", c.Remote.URL)
+	}
+	if c.Remote.Endpoint != "" && !strings.HasPrefix(c.Remote.Endpoint, "http://") && !strings.HasPrefix(c.Remote.Endpoint, "https://") {
+		return fmt.Errorf("remote.endpoint must be an http or https URL, got %q", c.Remote.Endpoint)
+	}
+	if c.Remote.PerfData != "" && !strings.HasPrefix(c.Remote.PerfData, "http://") && !strings.HasPrefix(c.Remote.PerfData, "https://") {
+		return fmt.Errorf("remote.perfdata must be an http or https URL, got %q", c.Remote.PerfData)
+	}
+	if c.Retention.KeepLast < 0 {
+		return fmt.Errorf("retention.keep_last must be positive, got %d", c.Retention.KeepLast)
+	}
+	for name, age := range map[string]string{"older_than": c.Retention.OlderThan, "profiles_older_than": c.Retention.ProfilesOlderThan} {
+		if _, err := ParseAge(age); age != "" && err != nil {
+			return fmt.Errorf("retention.%s: %w", name, err)
+		}
+	}
+	if c.Retention.AutoPrune && !c.Retention.Prunes() {
+		return fmt.Errorf("retention.auto_prune needs keep_last, older_than or profiles_older_than")
+	}
+	if c.Defaults.Count < 0 {
+		return fmt.Errorf("defaults.count must be positive, got %d", c.Defaults.Count)
+	}
+	if c.Defaults.Threshold < 0 {
+		return fmt.Errorf("defaults.threshold must be positive, got %v", c.Defaults.Threshold)
+	}
+	if c.Defaults.Bench != "" {
+		if _, err := regexp.Compile(c.Defaults.Bench); err != nil {
+			return fmt.Errorf("defaults.bench: invalid regex: %w", err)
+		}
+	}
+	if err := validateTimeout(c.Defaults.AI.Timeout); err != nil {
+		return fmt.Errorf("defaults.ai.timeout: %w", err)
+	}
+	if c.Defaults.AI.Retries < 0 {
+		return fmt.Errorf("defaults.ai.retries must be positive, got %d", c.Defaults.AI.Retries)
+	}
+	for i, fallback := range c.Defaults.AI.Fallback {
+		if fallback.Provider == "" {
+			return fmt.Errorf("defaults.ai.fallback[%d]: provider is required", i)
+		}
+		if err := validateTimeout(fallback.Timeout); err != nil {
+			return fmt.Errorf("defaults.ai.fallback[%d].timeout: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateChannel checks a notification channel: webhook:<url> or
+// slack:<url>
+func validateChannel(channel string) error {
+	kind, url, _ := strings.Cut(channel, ":")
+	if kind != "webhook" && kind != "slack" {
+		return fmt.Errorf("unknown notification channel %q, want webhook:<url> or slack:<url>", channel)
+	}
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("%s channel needs an http or https URL, got %q", kind, url)
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), DefaultFile))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Metrics) != 0 {
+		t.Errorf("Expected empty config, got %+v", cfg)
+	}
+}
+
+func TestLoadMetrics(t *testing.T) {
+	path := writeConfig(t, `
+metrics:
+  - name: cache_hit_ratio
+    regex: 'cache_hit_ratio=([0-9.]+)'
+    unit: ratio
+  - name: p99
+    json_path: latency.p99_ms
+    unit: ms
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.Metrics) != 2 {
+		t.Fatalf("Expected 2 metrics, got %d", len(cfg.Metrics))
+	}
+	if cfg.Metrics[0].Name != "cache_hit_ratio" || cfg.Metrics[0].Unit != "ratio" {
+		t.Errorf("Unexpected first metric: %+v", cfg.Metrics[0])
+	}
+	if cfg.Metrics[1].JSONPath != "latency.p99_ms" {
+		t.Errorf("Unexpected second metric: %+v", cfg.Metrics[1])
+	}
+}
+
+func TestLoadMatching(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+matching:
+  strip_cpu_suffix: false
+  strip_patterns: ['/v[0-9]+$']
+  package_renames:
+    example.com/old: example.com/new
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Matching.CPUSuffixStripped() {
+		t.Error("Expected CPU suffix stripping to be disabled")
+	}
+	if len(cfg.Matching.StripPatterns) != 1 || cfg.Matching.PackageRenames["example.com/old"] != "example.com/new" {
+		t.Errorf("Unexpected matching config: %+v", cfg.Matching)
+	}
+
+	if !(Matching{}).CPUSuffixStripped() {
+		t.Error("Expected CPU suffix stripping by default")
+	}
+}
+
+func TestLoadNormalization(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+normalization:
+  reference: Calibrate
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Normalization.Reference != "Calibrate" {
+		t.Errorf("Reference = %q, want Calibrate", cfg.Normalization.Reference)
+	}
+}
+
+func TestLoadScoreWeights(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+score:
+  weights:
+    Parse: 2
+    "Encode/*": 0
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Score.Weights["Parse"] != 2 || len(cfg.Score.Weights) != 2 {
+		t.Errorf("Weights = %v", cfg.Score.Weights)
+	}
+}
+
+func TestLoadSLOs(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+slos:
+  - name: checkout-latency
+    benchmark: BenchmarkCheckout
+    max: 2ms
+  - name: throughput
+    benchmark: Ingest
+    metric: events/s
+    percentile: 90
+    window: 10
+    min: 5000
+`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.SLOs) != 2 {
+		t.Fatalf("expected 2 SLOs, got %+v", cfg.SLOs)
+	}
+
+	latency := cfg.SLOs[0]
+	if latency.TargetPercentile() != 95 || latency.WindowRuns() != 20 || latency.MetricName() != "ns/op" {
+		t.Errorf("defaults not applied: %+v", latency)
+	}
+	if limit, upper, err := latency.Bound(); err != nil || limit != 2e6 || !upper {
+		t.Errorf("Bound() = %v, %v, %v; want 2e6, true", limit, upper, err)
+	}
+
+	throughput := cfg.SLOs[1]
+	if limit, upper, err := throughput.Bound(); err != nil || limit != 5000 || upper {
+		t.Errorf("Bound() = %v, %v, %v; want 5000, false", limit, upper, err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	path := writeConfig(t, `
+defaults:
+  storage: results
+  bench: ^BenchmarkParse
+  benchtime: 2s
+  count: 5
+  threshold: 7.5
+  export:
+    format: markdown
+  ai:
+    enabled: true
+    provider: anthropic
+    timeout: 2m
+    retries: 2
+    fallback:
+      - provider: openai
+        model: gpt-4o
+        timeout: 30s
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	d := cfg.Defaults
+	if want := filepath.Join(filepath.Dir(path), "results"); d.Storage != want {
+		t.Errorf("Storage = %s, want %s relative to the config file", d.Storage, want)
+	}
+	if d.Bench != "^BenchmarkParse" || d.Benchtime != "2s" || d.Count != 5 || d.Threshold != 7.5 {
+		t.Errorf("Unexpected defaults: %+v", d)
+	}
+	if d.Export.Format != "markdown" || !d.AI.Enabled || d.AI.Provider != "anthropic" {
+		t.Errorf("Unexpected export or AI defaults: %+v", d)
+	}
+	if d.AI.Timeout != "2m" || d.AI.Retries != 2 || len(d.AI.Fallback) != 1 || d.AI.Fallback[0].Model != "gpt-4o" {
+		t.Errorf("Unexpected AI retry and fallback defaults: %+v", d.AI)
+	}
+
+	// Absolute storage directories are kept
+	storage := filepath.Join(t.TempDir(), "results")
+	cfg, err = Load(writeConfig(t, "defaults:\n  storage: "+storage))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Defaults.Storage != storage {
+		t.Errorf("Storage = %s, want %s", cfg.Defaults.Storage, storage)
+	}
+}
+
+func TestLoadRetention(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "retention:\n  auto_prune: true\n  keep_last: 100\n  older_than: 90d\n  keep_baselines: false\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	r := cfg.Retention
+	if !r.AutoPrune || r.KeepLast != 100 || r.OlderThan != "90d" || r.BaselinesKept() || !r.Prunes() {
+		t.Errorf("Unexpected retention: %+v", r)
+	}
+	if empty := (Retention{}); !empty.BaselinesKept() || empty.Prunes() {
+		t.Error("Expected baselines to be kept and nothing pruned by default")
+	}
+}
+
+func TestLoadSchedules(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "schedules:\n  - name: nightly\n    cron: '0 2 * * *'\n    pkg: ./internal/...\n    count: 5\n    notify: [slack:https://hooks.example.com/x]\n  - name: hourly\n    cron: '@every 1h'\n    threshold: 10\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Schedules) != 2 {
+		t.Fatalf("Expected 2 schedules, got %d", len(cfg.Schedules))
+	}
+	nightly, hourly := cfg.Schedules[0], cfg.Schedules[1]
+	if nightly.Name != "nightly" || nightly.Pkg != "./internal/..." || nightly.Count != 5 || len(nightly.Notify) != 1 || nightly.RegressionThreshold() != 5 {
+		t.Errorf("Unexpected schedule: %+v", nightly)
+	}
+	if hourly.Cron != "@every 1h" || hourly.RegressionThreshold() != 10 {
+		t.Errorf("Unexpected schedule: %+v", hourly)
+	}
+}
+
+func TestLoadInteractive(t *testing.T) {
+	path := writeConfig(t, "interactive:\n  prompt: 'bench> '\n  history: .gokanon/history\n  history_limit: 200\n")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	i := cfg.Interactive
+	if want := filepath.Join(filepath.Dir(path), ".gokanon", "history"); i.Prompt != "bench> " || i.History != want || i.HistoryLimit != 200 {
+		t.Errorf("Unexpected interactive settings: %+v, want history %s", i, want)
+	}
+
+	// Turning the history off is not a path
+	cfg, err = Load(writeConfig(t, "interactive:\n  history: off\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Interactive.History != HistoryOff {
+		t.Errorf("History = %s, want %s", cfg.Interactive.History, HistoryOff)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errText string
+	}{
+		{"bad yaml", "metrics: [", "failed to parse"},
+		{"missing name", "metrics:\n  - regex: 'x=(\\d+)'", "name is required"},
+		{"duplicate name", "metrics:\n  - name: a\n    regex: 'a=(\\d+)'\n  - name: a\n    regex: 'b=(\\d+)'", "duplicate"},
+		{"no source", "metrics:\n  - name: a", "exactly one"},
+		{"both sources", "metrics:\n  - name: a\n    regex: 'a=(\\d+)'\n    json_path: a", "exactly one"},
+		{"bad regex", "metrics:\n  - name: a\n    regex: '(['", "invalid regex"},
+		{"no capture group", "metrics:\n  - name: a\n    regex: 'a=\\d+'", "capture group"},
+		{"bad strip pattern", "matching:\n  strip_patterns: ['([']", "strip_patterns[0]"},
+		{"empty rename", "matching:\n  package_renames:\n    example.com/old: ''", "empty import path"},
+		{"bad weight pattern", "score:\n  weights:\n    '[': 1", "invalid pattern"},
+		{"slo without benchmark", "slos:\n  - name: a\n    max: 2ms", "name and benchmark"},
+		{"duplicate slo", "slos:\n  - name: a\n    benchmark: A\n    max: 1\n  - name: a\n    benchmark: B\n    max: 1", "duplicate SLO"},
+		{"slo without bound", "slos:\n  - name: a\n    benchmark: A", "exactly one of max or min"},
+		{"slo bad bound", "slos:\n  - name: a\n    benchmark: A\n    max: fast", "invalid bound"},
+		{"slo bad percentile", "slos:\n  - name: a\n    benchmark: A\n    max: 1\n    percentile: 100", "percentile"},
+		{"alert without benchmark", "alerts:\n  - name: a\n    max_increase: 2", "name and benchmark"},
+		{"alert without limit", "alerts:\n  - name: a\n    benchmark: A", "exactly one of max_increase or max_decrease"},
+		{"alert bad period", "alerts:\n  - name: a\n    benchmark: A\n    max_increase: 2\n    per: daily", "invalid per"},
+		{"alert one run", "alerts:\n  - name: a\n    benchmark: A\n    max_increase: 2\n    min_runs: 1", "min_runs"},
+		{"alert bad channel", "alerts:\n  - name: a\n    benchmark: A\n    max_increase: 2\n    notify: [email:me@example.com]", "unknown notification channel"},
+		{"schedule without cron", "schedules:\n  - name: nightly", "name and cron"},
+		{"duplicate schedule", "schedules:\n  - name: a\n    cron: '@daily'\n  - name: a\n    cron: '@hourly'", "duplicate schedule"},
+		{"schedule bad cron", "schedules:\n  - name: a\n    cron: '0 25 * * *'", "invalid hour"},
+		{"schedule bad channel", "schedules:\n  - name: a\n    cron: '@daily'\n    notify: [slack:hooks.example.com]", "http or https URL"},
+		{"negative weight", "score:\n  weights:\n    Parse: -1", "negative weight"},
+		{"bad threshold pattern", "thresholds:\n  '[': 5", "invalid pattern"},
+		{"negative threshold override", "thresholds:\n  Parse: -1", "negative threshold"},
+		{"negative count", "defaults:\n  count: -1", "defaults.count"},
+		{"negative threshold", "defaults:\n  threshold: -5", "defaults.threshold"},
+		{"bad bench filter", "defaults:\n  bench: '(['", "defaults.bench"},
+		{"bad AI timeout", "defaults:\n  ai:\n    timeout: soon", "defaults.ai.timeout"},
+		{"negative AI retries", "defaults:\n  ai:\n    retries: -1", "defaults.ai.retries"},
+		{"fallback without provider", "defaults:\n  ai:\n    fallback:\n      - model: gpt-4o", "defaults.ai.fallback[0]"},
+		{"bad fallback timeout", "defaults:\n  ai:\n    fallback:\n      - provider: openai\n        timeout: -1s", "defaults.ai.fallback[0].timeout"},
+		{"bad remote scheme", "remote:\n  url: https://bucket", "remote.url"},
+		{"bad remote endpoint", "remote:\n  url: s3://bucket\n  endpoint: localhost:9000", "remote.endpoint"},
+		{"bad perf data server", "remote:\n  perfdata: perf.internal", "remote.perfdata"},
+		{"negative keep_last", "retention:\n  keep_last: -1", "retention.keep_last"},
+		{"bad retention age", "retention:\n  older_than: 3 months", "retention.older_than"},
+		{"auto_prune without policy", "retention:\n  auto_prune: true", "retention.auto_prune"},
+		{"negative history limit", "interactive:\n  history_limit: -1", "interactive.history_limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("Expected error containing %q, got: %v", tt.errText, err)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for input, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+		"0d":  0,
+	} {
+		if got, err := ParseAge(input); err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "d", "1.5d", "-2w", "-1h", "month"} {
+		if _, err := ParseAge(input); err == nil {
+			t.Errorf("ParseAge(%q) should fail", input)
+		}
+	}
+}
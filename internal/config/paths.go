@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// StorageDirName is the per-project storage directory
+const StorageDirName = ".gokanon"
+
+// userConfigFile is the configuration file in the user config directory
+const userConfigFile = "config.yaml"
+
+// historyFile is the history of interactive sessions in the user data directory
+const historyFile = "history"
+
+// telemetryFile holds the telemetry setting in the user config directory
+const telemetryFile = "telemetry.json"
+
+// telemetryReportFile holds the usage counts not reported yet in the user
+// data directory. It has no .json extension since the directory is also the
+// storage of runs made outside of any project.
+const telemetryReportFile = "telemetry-report"
+
+// Sources of a resolved location
+const (
+	SourceProject  = "project"  // Found in the working directory or a parent
+	SourceRegistry = "registry" // Registered for the project containing the working directory
+	SourceUser     = "user"     // Per-user default under the XDG base directories
+	SourceLocal    = "local"    // Working directory, when no home directory is known
+	SourceConfig   = "config"   // Set under defaults in the configuration file
+)
+
+// Locations are the storage directory and configuration file used when no
+// -storage or -config flag is given
+type Locations struct {
+	Storage       string `json:"storage"`
+	StorageSource string `json:"storage_source"`
+	Config        string `json:"config"`
+	ConfigSource  string `json:"config_source"`
+	DataHome      string `json:"data_home"`              // $XDG_DATA_HOME or its default
+	ConfigHome    string `json:"config_home"`            // $XDG_CONFIG_HOME or its default
+	ProjectRoot   string `json:"project_root,omitempty"` // Root of the project containing the directory
+}
+
+// Resolve resolves the default locations for the working directory dir.
+// A .gokanon directory or .gokanon.yaml file in dir or one of its parents
+// takes precedence. Otherwise results are stored where the project registry
+// says, or in a directory of the project under $XDG_DATA_HOME/gokanon, or
+// outside of any project in $XDG_DATA_HOME/gokanon itself. Configuration
+// is then read from $XDG_CONFIG_HOME/gokanon/config.yaml. A storage
+// directory set under defaults in the configuration overrides all of these.
+func Resolve(dir string) Locations {
+	loc := Locations{
+		DataHome:   dataHome(),
+		ConfigHome: configHome(),
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	switch project := findUp(dir, DefaultFile); {
+	case project != "":
+		loc.Config, loc.ConfigSource = project, SourceProject
+	case loc.ConfigHome != "":
+		loc.Config, loc.ConfigSource = filepath.Join(loc.ConfigHome, "gokanon", userConfigFile), SourceUser
+	default:
+		loc.Config, loc.ConfigSource = DefaultFile, SourceLocal
+	}
+
+	var registered *Project
+	if path := RegistryPath(); path != "" {
+		// An unreadable registry is reported when a run updates it
+		if r, err := LoadRegistry(path); err == nil {
+			registered = r.Lookup(dir)
+		}
+	}
+
+	switch project := findUp(dir, StorageDirName); {
+	case project != "":
+		loc.Storage, loc.StorageSource = project, SourceProject
+		loc.ProjectRoot = filepath.Dir(project)
+	case registered != nil:
+		loc.Storage, loc.StorageSource = registered.Storage, SourceRegistry
+		loc.ProjectRoot = registered.Root
+	case loc.DataHome != "":
+		loc.Storage, loc.StorageSource = filepath.Join(loc.DataHome, "gokanon"), SourceUser
+		if loc.ProjectRoot = ProjectRoot(dir); loc.ProjectRoot != "" {
+			loc.Storage = projectStorage(loc.DataHome, loc.ProjectRoot)
+		}
+	default:
+		loc.Storage, loc.StorageSource = StorageDirName, SourceLocal
+		loc.ProjectRoot = ProjectRoot(dir)
+	}
+
+	// An invalid configuration is reported when a command loads it
+	if cfg, err := Load(loc.Config); err == nil && cfg.Defaults.Storage != "" {
+		loc.Storage, loc.StorageSource = cfg.Defaults.Storage, SourceConfig
+	}
+
+	return loc
+}
+
+// resolveWorkingDir resolves the locations for the working directory
+func resolveWorkingDir() Locations {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	return Resolve(dir)
+}
+
+// DefaultStorageDir returns the storage directory used when -storage is not given
+func DefaultStorageDir() string {
+	return resolveWorkingDir().Storage
+}
+
+// DefaultPath returns the configuration file used when -config is not given
+func DefaultPath() string {
+	return resolveWorkingDir().Config
+}
+
+// HistoryOff is the history file setting that keeps no history
+const HistoryOff = "off"
+
+// HistoryPath returns the history file of interactive sessions under
+// $XDG_DATA_HOME/gokanon, shared by all projects, or an empty string when
+// there is no user data directory
+func HistoryPath() string {
+	home := dataHome()
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, "gokanon", historyFile)
+}
+
+// TelemetrySettingsPath returns the file recording whether telemetry is on,
+// under $XDG_CONFIG_HOME/gokanon, or an empty string when there is no user
+// config directory
+func TelemetrySettingsPath() string {
+	home := configHome()
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, "gokanon", telemetryFile)
+}
+
+// TelemetryReportPath returns the file of the usage counts not reported
+// yet, under $XDG_DATA_HOME/gokanon, or an empty string when there is no
+// user data directory
+func TelemetryReportPath() string {
+	home := dataHome()
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, "gokanon", telemetryReportFile)
+}
+
+// findUp returns the path of name in dir or its nearest parent containing
+// it, or an empty string. Any file type matches, so that a misplaced file
+// is reported when used rather than silently skipped.
+func findUp(dir, name string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// dataHome returns the base directory for user data files
+func dataHome() string {
+	return baseDir("XDG_DATA_HOME", "LocalAppData", ".local", "share")
+}
+
+// configHome returns the base directory for user configuration files
+func configHome() string {
+	return baseDir("XDG_CONFIG_HOME", "AppData", ".config")
+}
+
+// baseDir returns the XDG base directory set in env, which the
+// specification requires to be absolute. Otherwise it falls back to the
+// Windows directory in windowsEnv or to a directory under the home directory.
+func baseDir(env, windowsEnv string, homeRelative ...string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv(windowsEnv); dir != "" {
+			return dir
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(append([]string{home}, homeRelative...)...)
+}
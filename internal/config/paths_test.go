@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv("XDG_CONFIG_HOME", "relative/config") // Ignored: must be absolute
+
+	project := t.TempDir()
+	nested := filepath.Join(project, "internal", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside any project the per-user locations are used
+	loc := Resolve(nested)
+	if want := filepath.Join(home, "data", "gokanon"); loc.Storage != want || loc.StorageSource != SourceUser {
+		t.Errorf("Storage = %s (%s), want %s (user)", loc.Storage, loc.StorageSource, want)
+	}
+	if want := filepath.Join(home, ".config", "gokanon", "config.yaml"); loc.Config != want || loc.ConfigSource != SourceUser {
+		t.Errorf("Config = %s (%s), want %s (user)", loc.Config, loc.ConfigSource, want)
+	}
+
+	// Project files in a parent directory take precedence
+	if err := os.Mkdir(filepath.Join(project, StorageDirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, DefaultFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	loc = Resolve(nested)
+	if want := filepath.Join(project, StorageDirName); loc.Storage != want || loc.StorageSource != SourceProject {
+		t.Errorf("Storage = %s (%s), want %s (project)", loc.Storage, loc.StorageSource, want)
+	}
+	if want := filepath.Join(project, DefaultFile); loc.Config != want || loc.ConfigSource != SourceProject {
+		t.Errorf("Config = %s (%s), want %s (project)", loc.Config, loc.ConfigSource, want)
+	}
+}
+
+func TestResolveConfiguredStorage(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	project := t.TempDir()
+	if err := os.Mkdir(filepath.Join(project, StorageDirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, DefaultFile), []byte("defaults:\n  storage: bench-results\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The storage set in the configuration wins over the .gokanon directory
+	loc := Resolve(project)
+	if want := filepath.Join(project, "bench-results"); loc.Storage != want || loc.StorageSource != SourceConfig {
+		t.Errorf("Storage = %s (%s), want %s (config)", loc.Storage, loc.StorageSource, want)
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// projectsDir is the directory under the user data directory holding the
+// registry and the storage of projects without a .gokanon directory
+const projectsDir = "projects"
+
+// registryFile is the project registry in the projects directory
+const registryFile = "registry.json"
+
+// Project is a project tracked in the registry
+type Project struct {
+	Root     string    `json:"root"`    // Repository or module root
+	Storage  string    `json:"storage"` // Storage directory of its results
+	Added    time.Time `json:"added"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Name returns the display name of the project: its root directory's name
+func (p Project) Name() string {
+	return filepath.Base(p.Root)
+}
+
+// Registry maps project roots to their storage directories, so that the
+// right history is found from anywhere inside a project even when its
+// results are not stored in a .gokanon directory
+type Registry struct {
+	path     string
+	Projects []Project `json:"projects"`
+}
+
+// RegistryPath returns the path of the project registry, or an empty
+// string when there is no user data directory
+func RegistryPath() string {
+	home := dataHome()
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, "gokanon", projectsDir, registryFile)
+}
+
+// LoadRegistry loads the registry at path. A missing file is an empty registry.
+func LoadRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project registry: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse project registry %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Save writes the registry, sorted by root
+func (r *Registry) Save() error {
+	sort.Slice(r.Projects, func(i, j int) bool { return r.Projects[i].Root < r.Projects[j].Root })
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal project registry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	// Write atomically so concurrent runs never leave a truncated registry
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to write project registry: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the project containing dir, the one with the nearest
+// root if projects are nested, or nil
+func (r *Registry) Lookup(dir string) *Project {
+	var found *Project
+	for i, p := range r.Projects {
+		if !within(dir, p.Root) {
+			continue
+		}
+		if found == nil || len(p.Root) > len(found.Root) {
+			found = &r.Projects[i]
+		}
+	}
+	return found
+}
+
+// Touch records that the project at root was used. A project seen for the
+// first time is added with storage; known projects keep their storage.
+func (r *Registry) Touch(root, storage string, now time.Time) *Project {
+	for i := range r.Projects {
+		if r.Projects[i].Root == root {
+			r.Projects[i].LastUsed = now
+			return &r.Projects[i]
+		}
+	}
+	r.Projects = append(r.Projects, Project{Root: root, Storage: storage, Added: now, LastUsed: now})
+	return &r.Projects[len(r.Projects)-1]
+}
+
+// Remove removes the project at root, reporting whether it was tracked
+func (r *Registry) Remove(root string) bool {
+	for i, p := range r.Projects {
+		if p.Root == root {
+			r.Projects = append(r.Projects[:i], r.Projects[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterProject adds the project containing the working directory to the
+// registry with the storage directory its results were saved to, or marks
+// it as used if it is already tracked. Directories outside any project are
+// not registered.
+func RegisterProject(storage string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return registerProject(dir, storage, time.Now())
+}
+
+func registerProject(dir, storage string, now time.Time) error {
+	loc := Resolve(dir)
+	path := RegistryPath()
+	if loc.ProjectRoot == "" || path == "" {
+		return nil
+	}
+	storage, err := filepath.Abs(storage)
+	if err != nil {
+		return fmt.Errorf("failed to resolve storage directory: %w", err)
+	}
+
+	r, err := LoadRegistry(path)
+	if err != nil {
+		return err
+	}
+	r.Touch(loc.ProjectRoot, storage, now)
+	return r.Save()
+}
+
+// ProjectRoot returns the root of the project containing dir: the nearest
+// git repository, or else the nearest Go module. It returns an empty string
+// outside of any project.
+func ProjectRoot(dir string) string {
+	for _, marker := range []string{".git", "go.mod"} {
+		if path := findUp(dir, marker); path != "" {
+			return filepath.Dir(path)
+		}
+	}
+	return ""
+}
+
+// projectStorage returns the default storage directory under the user data
+// directory for the project at root. The hash of the root keeps projects
+// with the same name apart.
+func projectStorage(dataHome, root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dataHome, "gokanon", projectsDir, filepath.Base(root)+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// within reports whether dir is root or a directory below it
+func within(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
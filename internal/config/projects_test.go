@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects", registryFile)
+	r, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("Expected a missing registry to load empty, got %v", err)
+	}
+
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := first.Add(time.Hour)
+	r.Touch("/src/app", "/data/app", first)
+	r.Touch("/src/app/tools", "/data/tools", first)
+	if p := r.Touch("/src/app", "/elsewhere", later); p.Storage != "/data/app" || !p.LastUsed.Equal(later) || !p.Added.Equal(first) {
+		t.Errorf("Expected a known project to keep its storage and be marked used, got %+v", p)
+	}
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	r, err = LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/src/app", "/src/app"},
+		{"/src/app/internal/x", "/src/app"},
+		{"/src/app/tools/cmd", "/src/app/tools"}, // Nearest root wins
+		{"/src/application", ""},
+		{"/src", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if p := r.Lookup(filepath.FromSlash(tt.dir)); p != nil {
+			got = p.Root
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%s) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+
+	if !r.Remove("/src/app/tools") || r.Remove("/src/app/tools") {
+		t.Error("Expected Remove to report whether the project was tracked")
+	}
+	if p := r.Lookup("/src/app/tools/cmd"); p == nil || p.Root != "/src/app" {
+		t.Errorf("Expected the enclosing project after removal, got %+v", p)
+	}
+}
+
+func TestResolveProjects(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	project := t.TempDir()
+	nested := filepath.Join(project, "internal", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A project without .gokanon gets its own directory under the data home
+	loc := Resolve(nested)
+	if loc.ProjectRoot != project || loc.StorageSource != SourceUser {
+		t.Fatalf("Expected project %s with user storage, got %+v", project, loc)
+	}
+	if want := projectStorage(filepath.Join(home, "data"), project); loc.Storage != want {
+		t.Errorf("Storage = %s, want %s", loc.Storage, want)
+	}
+
+	// Once registered, its storage is found from anywhere inside it
+	storage := filepath.Join(t.TempDir(), "results")
+	if err := registerProject(nested, storage, time.Now()); err != nil {
+		t.Fatalf("registerProject failed: %v", err)
+	}
+	loc = Resolve(project)
+	if loc.Storage != storage || loc.StorageSource != SourceRegistry {
+		t.Errorf("Storage = %s (%s), want %s (registry)", loc.Storage, loc.StorageSource, storage)
+	}
+
+	// Directories outside any project are not registered
+	outside := t.TempDir()
+	if err := registerProject(outside, storage, time.Now()); err != nil {
+		t.Fatalf("registerProject failed: %v", err)
+	}
+	r, err := LoadRegistry(RegistryPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Projects) != 1 {
+		t.Errorf("Expected only the project to be registered, got %+v", r.Projects)
+	}
+}
+
+func TestProjectRoot(t *testing.T) {
+	repo := t.TempDir()
+	module := filepath.Join(repo, "services", "api")
+	if err := os.MkdirAll(filepath.Join(module, "cmd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(module, "go.mod"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The repository is preferred over a nested module
+	if got := ProjectRoot(filepath.Join(module, "cmd")); got != repo {
+		t.Errorf("ProjectRoot = %q, want %q", got, repo)
+	}
+}
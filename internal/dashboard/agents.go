@@ -0,0 +1,262 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+const (
+	// AgentTimeout is how long an agent may stay silent before it is
+	// considered gone and its running jobs fail
+	AgentTimeout = time.Minute
+
+	// maxResultSize bounds the size of runs uploaded by agents
+	maxResultSize = 32 << 20
+)
+
+// Agent is a remote worker that runs queued benchmark jobs
+type Agent struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	RegisteredAt time.Time         `json:"registeredAt"`
+	LastSeen     time.Time         `json:"lastSeen"`
+}
+
+// AgentRegistration is sent by an agent when it joins the controller
+type AgentRegistration struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// JobResult is uploaded by an agent when it finishes a job
+type JobResult struct {
+	Run   *models.BenchmarkRun `json:"run,omitempty"`
+	Error string               `json:"error,omitempty"`
+}
+
+// agentRegistry tracks the agents connected to the controller
+type agentRegistry struct {
+	mu     sync.Mutex
+	agents map[string]*Agent
+	nextID int
+}
+
+func newAgentRegistry() *agentRegistry {
+	return &agentRegistry{agents: make(map[string]*Agent)}
+}
+
+// register adds an agent and returns it
+func (a *agentRegistry) register(reg AgentRegistration) Agent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nextID++
+	now := time.Now()
+	agent := &Agent{
+		ID:           fmt.Sprintf("agent-%d", a.nextID),
+		Name:         reg.Name,
+		Labels:       reg.Labels,
+		RegisteredAt: now,
+		LastSeen:     now,
+	}
+	if agent.Name == "" {
+		agent.Name = agent.ID
+	}
+	a.agents[agent.ID] = agent
+	return *agent
+}
+
+// touch records that an agent is alive. It returns false for unknown agents.
+func (a *agentRegistry) touch(id string) (Agent, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	agent, ok := a.agents[id]
+	if !ok {
+		return Agent{}, false
+	}
+	agent.LastSeen = time.Now()
+	return *agent, true
+}
+
+// expire removes the agents that have not been seen within AgentTimeout
+func (a *agentRegistry) expire(now time.Time) []Agent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var expired []Agent
+	for id, agent := range a.agents {
+		if now.Sub(agent.LastSeen) > AgentTimeout {
+			expired = append(expired, *agent)
+			delete(a.agents, id)
+		}
+	}
+	return expired
+}
+
+// list returns the registered agents sorted by name
+func (a *agentRegistry) list() []Agent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	agents := make([]Agent, 0, len(a.agents))
+	for _, agent := range a.agents {
+		agents = append(agents, *agent)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		if agents[i].Name != agents[j].Name {
+			return agents[i].Name < agents[j].Name
+		}
+		return agents[i].ID < agents[j].ID
+	})
+	return agents
+}
+
+// workerName identifies an agent in the job list
+func workerName(agent Agent) string {
+	return agent.Name + " (" + agent.ID + ")"
+}
+
+// expireAgents drops silent agents and fails the jobs they were running
+func (s *Server) expireAgents() {
+	for _, agent := range s.agents.expire(time.Now()) {
+		s.jobs.Abandon(workerName(agent), errors.New("agent stopped responding"))
+	}
+}
+
+// handleAgents lists the connected agents (GET) or registers a new one (POST)
+func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		agents := []Agent{}
+		if s.agents != nil {
+			s.expireAgents()
+			agents = s.agents.list()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(agents)
+
+	case http.MethodPost:
+		if !s.agentRequest(w, r) {
+			return
+		}
+
+		var reg AgentRegistration
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&reg); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid registration: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		agent := s.agents.register(reg)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(agent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAgentDetail serves the agent protocol:
+//
+//	POST /api/agents/{id}/heartbeat       keep the agent registered
+//	POST /api/agents/{id}/claim           take the next job (204 if none)
+//	POST /api/agents/{id}/jobs/{job}      upload the result of a job
+func (s *Server) handleAgentDetail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.agentRequest(w, r) {
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/agents/"), "/")
+	s.expireAgents()
+	agent, ok := s.agents.touch(parts[0])
+	if !ok {
+		http.Error(w, "Unknown agent, register again", http.StatusGone)
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "heartbeat":
+		w.WriteHeader(http.StatusNoContent)
+
+	case len(parts) == 2 && parts[1] == "claim":
+		job, ok := s.jobs.Claim(workerName(agent), agent.Labels)
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+
+	case len(parts) == 3 && parts[1] == "jobs":
+		var result JobResult
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxResultSize)).Decode(&result); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid result: %v", err), http.StatusBadRequest)
+			return
+		}
+		if result.Error == "" && result.Run == nil {
+			http.Error(w, "Result has neither a run nor an error", http.StatusBadRequest)
+			return
+		}
+		if result.Run != nil && !validRunID(result.Run.ID) {
+			http.Error(w, fmt.Sprintf("Invalid run ID %q", result.Run.ID), http.StatusBadRequest)
+			return
+		}
+		if !s.jobs.RunningOn(parts[2], workerName(agent)) {
+			http.Error(w, "Job is not running on this agent", http.StatusConflict)
+			return
+		}
+
+		var runErr error
+		if result.Error != "" {
+			runErr = errors.New(result.Error)
+		} else {
+			// The controller, not the agent, vouches for where the run came from
+			result.Run.Agent = agent.Name
+			result.Run.AgentLabels = agent.Labels
+			if err := s.storage.Save(result.Run); err != nil {
+				runErr = fmt.Errorf("failed to save results: %w", err)
+			}
+		}
+		if err := s.jobs.Finish(parts[2], result.Run, runErr); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// validRunID reports whether an uploaded run ID is safe to use as a file name
+func validRunID(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
+
+// agentRequest rejects agent calls when agents are disabled or the token is wrong
+func (s *Server) agentRequest(w http.ResponseWriter, r *http.Request) bool {
+	if s.agents == nil {
+		http.Error(w, "Agents are disabled (start the dashboard with -agents)", http.StatusNotFound)
+		return false
+	}
+	if !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// agentCall performs an authenticated request against the server's handler
+func agentCall(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestAgentProtocol(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	server := NewServer(store, "localhost", 8080)
+	h := server.Handler()
+
+	if w := agentCall(t, h, http.MethodPost, "/api/agents", "secret", `{}`); w.Code != http.StatusNotFound {
+		t.Fatalf("register status = %v while agents are disabled, want %v", w.Code, http.StatusNotFound)
+	}
+
+	server.EnableAgents("secret")
+
+	if w := agentCall(t, h, http.MethodPost, "/api/agents", "guess", `{}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("register status = %v with a wrong token, want %v", w.Code, http.StatusUnauthorized)
+	}
+
+	w := agentCall(t, h, http.MethodPost, "/api/agents", "secret", `{"name": "epyc-1", "labels": {"cpu": "epyc"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register status = %v, want %v: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var agent Agent
+	json.NewDecoder(w.Body).Decode(&agent)
+	if agent.ID == "" || agent.Labels["cpu"] != "epyc" {
+		t.Fatalf("unexpected agent: %+v", agent)
+	}
+	base := "/api/agents/" + agent.ID
+
+	if w := agentCall(t, h, http.MethodPost, base+"/claim", "secret", ""); w.Code != http.StatusNoContent {
+		t.Errorf("claim status = %v with an empty queue, want %v", w.Code, http.StatusNoContent)
+	}
+
+	if w := agentCall(t, h, http.MethodPost, "/api/jobs", "secret", `{"bench": "Sum"}`); w.Code != http.StatusAccepted {
+		t.Fatalf("submit status = %v, want %v", w.Code, http.StatusAccepted)
+	}
+
+	w = agentCall(t, h, http.MethodPost, base+"/claim", "secret", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("claim status = %v, want %v", w.Code, http.StatusOK)
+	}
+	var job Job
+	json.NewDecoder(w.Body).Decode(&job)
+	if job.Request.Bench != "Sum" || job.Status != JobRunning || job.Worker != "epyc-1 ("+agent.ID+")" {
+		t.Fatalf("unexpected claimed job: %+v", job)
+	}
+
+	result := `{"run": {"id": "run-remote", "timestamp": "2024-01-01T00:00:00Z", "results": [{"name": "Sum-8", "ns_per_op": 10}]}}`
+	if w := agentCall(t, h, http.MethodPost, "/api/agents/other/jobs/"+job.ID, "secret", result); w.Code != http.StatusGone {
+		t.Errorf("upload status = %v from an unknown agent, want %v", w.Code, http.StatusGone)
+	}
+	escape := `{"run": {"id": "../escape"}}`
+	if w := agentCall(t, h, http.MethodPost, base+"/jobs/"+job.ID, "secret", escape); w.Code != http.StatusBadRequest {
+		t.Errorf("upload status = %v for a path-like run ID, want %v", w.Code, http.StatusBadRequest)
+	}
+	if w := agentCall(t, h, http.MethodPost, base+"/jobs/"+job.ID, "secret", result); w.Code != http.StatusNoContent {
+		t.Fatalf("upload status = %v, want %v: %s", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	if w := agentCall(t, h, http.MethodPost, base+"/jobs/"+job.ID, "secret", result); w.Code != http.StatusConflict {
+		t.Errorf("second upload status = %v, want %v", w.Code, http.StatusConflict)
+	}
+
+	saved, err := store.Load("run-remote")
+	if err != nil {
+		t.Fatalf("uploaded run was not saved: %v", err)
+	}
+	if len(saved.Results) != 1 || saved.Results[0].Name != "Sum-8" {
+		t.Errorf("unexpected saved run: %+v", saved)
+	}
+
+	jobs := server.jobs.Jobs()
+	if jobs[0].Status != JobDone || jobs[0].RunID != "run-remote" {
+		t.Errorf("unexpected job after upload: %+v", jobs[0])
+	}
+}
+
+func TestLabeledJobsGoToMatchingAgents(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	server := NewServer(store, "localhost", 8080)
+	server.EnableAgents("secret")
+	h := server.Handler()
+
+	register := func(body string) string {
+		w := agentCall(t, h, http.MethodPost, "/api/agents", "secret", body)
+		var agent Agent
+		json.NewDecoder(w.Body).Decode(&agent)
+		return "/api/agents/" + agent.ID
+	}
+	xeon := register(`{"name": "xeon", "labels": {"cpu": "xeon", "os": "linux"}}`)
+	epyc := register(`{"name": "epyc", "labels": {"cpu": "epyc", "os": "linux"}}`)
+
+	agentCall(t, h, http.MethodPost, "/api/jobs", "secret", `{"bench": "Sum", "labels": {"cpu": "epyc"}}`)
+
+	if w := agentCall(t, h, http.MethodPost, xeon+"/claim", "secret", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("non-matching agent claim status = %v, want %v", w.Code, http.StatusNoContent)
+	}
+
+	w := agentCall(t, h, http.MethodPost, epyc+"/claim", "secret", "")
+	var job Job
+	json.NewDecoder(w.Body).Decode(&job)
+	if job.Request.Bench != "Sum" {
+		t.Fatalf("matching agent did not get the job: %v %s", w.Code, w.Body.String())
+	}
+
+	// The agent cannot pick where the run is recorded as coming from
+	result := `{"run": {"id": "run-epyc", "agent": "spoofed"}}`
+	if w := agentCall(t, h, http.MethodPost, epyc+"/jobs/"+job.ID, "secret", result); w.Code != http.StatusNoContent {
+		t.Fatalf("upload status = %v, want %v", w.Code, http.StatusNoContent)
+	}
+
+	run, err := store.Load("run-epyc")
+	if err != nil {
+		t.Fatalf("failed to load run: %v", err)
+	}
+	if run.Agent != "epyc" || run.AgentLabels["cpu"] != "epyc" {
+		t.Errorf("run not attributed to the agent: agent=%q labels=%v", run.Agent, run.AgentLabels)
+	}
+
+	w = agentCall(t, h, http.MethodGet, "/api/jobs/"+job.ID, "", "")
+	json.NewDecoder(w.Body).Decode(&job)
+	if job.Status != JobDone || job.RunID != "run-epyc" {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	if w := agentCall(t, h, http.MethodGet, "/api/jobs/job-missing", "", ""); w.Code != http.StatusNotFound {
+		t.Errorf("missing job status = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestLocalWorkerSkipsLabeledJobs(t *testing.T) {
+	q := NewJobQueue(nil)
+	q.Submit(JobRequest{Bench: "Labeled", Labels: map[string]string{"cpu": "epyc"}})
+	q.Submit(JobRequest{Bench: "Any"})
+
+	job, ok := q.Claim(LocalWorker, nil)
+	if !ok || job.Request.Bench != "Any" {
+		t.Errorf("Claim() = %+v, %v; want the job without labels", job, ok)
+	}
+}
+
+func TestExpireAgents(t *testing.T) {
+	server := NewServer(storage.NewStorage(t.TempDir()), "localhost", 8080)
+	server.EnableAgents("secret")
+
+	agent := server.agents.register(AgentRegistration{Name: "gone"})
+	server.jobs.Submit(JobRequest{})
+	if _, ok := server.jobs.Claim(workerName(agent), nil); !ok {
+		t.Fatal("expected a job to claim")
+	}
+
+	// Pretend the agent has been silent for too long
+	server.agents.agents[agent.ID].LastSeen = time.Now().Add(-2 * AgentTimeout)
+	server.expireAgents()
+
+	if agents := server.agents.list(); len(agents) != 0 {
+		t.Errorf("expected the agent to expire, got %+v", agents)
+	}
+	job := server.jobs.Jobs()[0]
+	if job.Status != JobFailed || !strings.Contains(job.Error, "stopped responding") {
+		t.Errorf("expected the abandoned job to fail, got %+v", job)
+	}
+}
+
+func TestJobQueueClaim(t *testing.T) {
+	q := NewJobQueue(nil)
+	if _, ok := q.Claim("a", nil); ok {
+		t.Fatal("claimed a job from an empty queue")
+	}
+
+	first, _ := q.Submit(JobRequest{Bench: "First"})
+	q.Submit(JobRequest{Bench: "Second"})
+
+	job, ok := q.Claim("a", nil)
+	if !ok || job.ID != first.ID || job.Worker != "a" {
+		t.Fatalf("Claim() = %+v, %v; want the oldest job", job, ok)
+	}
+	if !q.RunningOn(job.ID, "a") || q.RunningOn(job.ID, "b") {
+		t.Error("RunningOn() does not reflect the claiming worker")
+	}
+
+	if err := q.Finish(job.ID, &models.BenchmarkRun{ID: "run-1"}, nil); err != nil {
+		t.Fatalf("Finish() failed: %v", err)
+	}
+	if err := q.Finish(job.ID, &models.BenchmarkRun{ID: "run-1"}, nil); err == nil {
+		t.Error("expected an error finishing a job twice")
+	}
+	if err := q.Finish("job-missing", nil, nil); err == nil {
+		t.Error("expected an error finishing an unknown job")
+	}
+}
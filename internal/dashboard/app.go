@@ -11,13 +11,61 @@ const App = {
         trends: null,
         selectedRun: null
     },
+    liveTimer: null,
+    refreshTimer: null,
+
+    // Timestamps are shown in the zone chosen with 'gokanon serve -tz',
+    // or the browser's zone when none was chosen
+    timeOptions: {
+        timeZone: document.querySelector('meta[name="gokanon-timezone"]')?.content || undefined
+    },
+
+    // Name of the snapshot shown at /snapshots/<name>, whose frozen stats,
+    // runs and trends replace the live ones; empty for the live dashboard
+    snapshot: document.querySelector('meta[name="gokanon-snapshot"]')?.content || '',
 
     init() {
         this.setupEventListeners();
         this.checkEmbedMode();
+        this.checkSnapshotMode();
         this.loadTheme();
         this.loadData();
+        this.loadSnapshots();
         this.loadURLParams();
+        if (this.snapshot) return;
+        this.loadJobs();
+        this.subscribeEvents();
+    },
+
+    // api returns the URL of the stats, runs or trends endpoint, those of the
+    // snapshot when one is shown
+    api(name) {
+        if (!this.snapshot) return '/api/' + name;
+        return '/api/snapshots/' + encodeURIComponent(this.snapshot) + '/' + name;
+    },
+
+    // Reload the dashboard when a run is saved or deleted, e.g. when
+    // 'gokanon run' finishes, as pushed by the server over server-sent events
+    subscribeEvents() {
+        if (!window.EventSource) return;
+        const source = new EventSource('/api/events');
+        let connected = false;
+        source.addEventListener('open', () => {
+            // Runs saved while the stream was down were missed
+            if (connected) this.refreshRuns();
+            connected = true;
+        });
+        source.addEventListener('run', () => this.refreshRuns());
+    },
+
+    // refreshRuns reloads the runs, stats and charts, coalescing the events
+    // of runs saved in quick succession
+    refreshRuns() {
+        clearTimeout(this.refreshTimer);
+        this.refreshTimer = setTimeout(() => {
+            this.loadData();
+            if (this.data.trends) this.loadTrends();
+        }, 300);
     },
 
     setupEventListeners() {
@@ -38,6 +86,17 @@ const App = {
             });
         });
 
+        // Stats date range
+        document.getElementById('applyDateRangeBtn').addEventListener('click', () => {
+            this.loadStats();
+        });
+
+        document.getElementById('clearDateRangeBtn').addEventListener('click', () => {
+            document.getElementById('statsFrom').value = '';
+            document.getElementById('statsTo').value = '';
+            this.loadStats();
+        });
+
         // Search
         document.getElementById('searchBtn').addEventListener('click', () => {
             this.performSearch();
@@ -54,15 +113,92 @@ const App = {
             this.loadTrends();
         });
 
+        document.getElementById('metricSelect').addEventListener('change', () => {
+            if (this.data.trends) this.createTrendsChart();
+        });
+
         // History filter
         document.getElementById('historyFilter').addEventListener('input', (e) => {
             this.filterHistory(e.target.value);
         });
 
         // Compare
+        document.getElementById('compareLinkBtn').addEventListener('click', () => {
+            navigator.clipboard.writeText(window.location.href);
+        });
         document.getElementById('compareBtn').addEventListener('click', () => {
             this.compareRuns();
         });
+
+        // Run now
+        document.getElementById('runNowBtn').addEventListener('click', () => {
+            document.getElementById('runToken').value = localStorage.getItem('gokanonToken') || '';
+            document.getElementById('runError').textContent = '';
+            document.getElementById('runModal').classList.add('active');
+        });
+
+        document.querySelector('[data-modal="runModal"]').addEventListener('click', () => {
+            document.getElementById('runModal').classList.remove('active');
+        });
+
+        document.getElementById('runSubmitBtn').addEventListener('click', () => {
+            this.submitRun();
+        });
+
+        // Run details
+        document.querySelector('[data-modal="runDetailModal"]').addEventListener('click', () => {
+            document.getElementById('runDetailModal').classList.remove('active');
+        });
+        document.getElementById('runSaveBtn').addEventListener('click', () => {
+            this.saveRunMetadata();
+        });
+        document.getElementById('runBaselineBtn').addEventListener('click', () => {
+            this.saveRunBaseline();
+        });
+        document.getElementById('runDeleteBtn').addEventListener('click', () => {
+            this.deleteRun();
+        });
+    },
+
+    // checkSnapshotMode hides the controls that only apply to live data
+    checkSnapshotMode() {
+        if (!this.snapshot) return;
+        document.title = 'GoKanon Dashboard - ' + this.snapshot;
+        document.getElementById('snapshotTitle').textContent = '📸 Snapshot ' + this.snapshot;
+        document.getElementById('snapshotNotice').style.display = 'block';
+        document.getElementById('refreshBtn').style.display = 'none';
+        document.querySelector('.date-range-picker').style.display = 'none';
+        document.querySelector('label[for="limitSelect"]').style.display = 'none';
+        document.getElementById('limitSelect').style.display = 'none';
+        document.querySelector('.tab-btn[data-tab="live"]').style.display = 'none';
+    },
+
+    // loadSnapshots fills the snapshot picker, which is hidden until a
+    // snapshot is taken with 'gokanon snapshot'
+    async loadSnapshots() {
+        try {
+            const response = await fetch('/api/snapshots');
+            const snapshots = await response.json();
+            if (snapshots.length === 0) return;
+
+            const select = document.getElementById('snapshotSelect');
+            select.innerHTML = '<option value="">Live data</option>' + snapshots.map(snapshot =>
+                '<option value="' + snapshot.name + '">📸 ' + snapshot.name + '</option>').join('');
+            select.value = this.snapshot;
+            select.style.display = '';
+            select.addEventListener('change', () => {
+                window.location.href = select.value ? '/snapshots/' + encodeURIComponent(select.value) : '/';
+            });
+
+            const current = snapshots.find(snapshot => snapshot.name === this.snapshot);
+            if (current) {
+                document.getElementById('snapshotTitle').textContent = '📸 Snapshot ' + current.name +
+                    ', taken ' + new Date(current.createdAt).toLocaleString(undefined, this.timeOptions) +
+                    (current.description ? ': ' + current.description : '');
+            }
+        } catch (error) {
+            console.error('Failed to load snapshots:', error);
+        }
     },
 
     checkEmbedMode() {
@@ -108,18 +244,37 @@ const App = {
         });
     },
 
+    async loadStats() {
+        const params = new URLSearchParams();
+        const from = document.getElementById('statsFrom').value;
+        const to = document.getElementById('statsTo').value;
+        if (from) params.set('from', from);
+        if (to) params.set('to', to);
+
+        const query = params.toString();
+        const statsRes = await fetch(this.api('stats') + (query ? '?' + query : ''));
+        if (!statsRes.ok) {
+            alert('Invalid date range: ' + (await statsRes.text()));
+            return;
+        }
+        this.data.stats = await statsRes.json();
+        this.updateStats();
+        this.updateRecentRuns();
+    },
+
     async loadData() {
         try {
             // Load stats
-            const statsRes = await fetch('/api/stats');
-            this.data.stats = await statsRes.json();
-            this.updateStats();
+            await this.loadStats();
 
             // Load runs
-            const runsRes = await fetch('/api/runs');
+            const runsRes = await fetch(this.api('runs'));
             this.data.runs = await runsRes.json();
             this.updateRecentRuns();
+            this.loadSLOs();
+            this.loadStress();
             this.createOverviewChart();
+            this.createSkipRateChart();
             this.populateCompareSelects();
             this.populateBenchmarkSelect();
             this.updateHistory();
@@ -143,6 +298,212 @@ const App = {
         } else {
             document.getElementById('dateRange').textContent = 'N/A';
         }
+
+        // Performance score of the newest run, and its change from the one before
+        const change = document.getElementById('scoreChange');
+        document.getElementById('scoreValue').textContent = stats.score ? this.formatScore(stats.score) : 'N/A';
+        change.textContent = '';
+        change.className = '';
+        if (stats.scoreChange !== undefined) {
+            change.textContent = (stats.scoreChange >= 0 ? '▲' : '▼') + Math.abs(stats.scoreChange).toFixed(1) + '%';
+            change.className = stats.scoreChange >= 1 ? 'delta-improved' :
+                stats.scoreChange <= -1 ? 'delta-degraded' : 'delta-same';
+        }
+    },
+
+    // docTitle returns a title attribute showing what a benchmark measures,
+    // from the doc comment of its function
+    docTitle(result) {
+        if (!result || !result.doc) return '';
+        return ' title="' + this.escapeHtml(result.doc) + '"';
+    },
+
+    // escapeHtml escapes text recorded by users, such as notes, for HTML
+    escapeHtml(text) {
+        return String(text).replace(/&/g, '&amp;').replace(/"/g, '&quot;').replace(/</g, '&lt;');
+    },
+
+    formatScore(score) {
+        if (score >= 1e9) return (score / 1e9).toFixed(2) + 'G';
+        if (score >= 1e6) return (score / 1e6).toFixed(2) + 'M';
+        if (score >= 1e3) return (score / 1e3).toFixed(2) + 'k';
+        return score.toFixed(2);
+    },
+
+    async loadSLOs() {
+        // SLOs are evaluated on the live runs only
+        if (this.snapshot) return;
+        try {
+            const response = await fetch('/api/slos');
+            this.updateSLOs(await response.json());
+        } catch (error) {
+            console.error('Failed to load SLOs:', error);
+        }
+    },
+
+    updateSLOs(slos) {
+        const container = document.getElementById('sloPanel');
+
+        if (slos.length === 0) {
+            container.innerHTML = '';
+            return;
+        }
+
+        let html = '<h2>Service Level Objectives</h2><table><thead><tr>' +
+            '<th>SLO</th>' +
+            '<th>Objective</th>' +
+            '<th>Current</th>' +
+            '<th>Burn Rate</th>' +
+            '<th>Status</th>' +
+            '<th>History</th>' +
+            '</tr></thead><tbody>';
+
+        slos.forEach(slo => {
+            let status = '<span class="delta-same">no data</span>';
+            let current = '-';
+            let burnRate = '-';
+            if (slo.state !== 'no data') {
+                current = this.formatSLOValue(slo.value, slo.metric) +
+                    ' <small>(' + slo.samples + '/' + slo.window + ' runs)</small>';
+                burnRate = slo.burnRate.toFixed(2);
+                status = slo.state === 'ok' ? '<span class="delta-improved">✓ ok</span>' :
+                    '<span class="delta-degraded">✗ breached</span>';
+            }
+
+            const history = slo.history.map(point =>
+                '<span class="' + (point.compliant ? '' : 'breached') + '" title="' + point.runId + ': ' +
+                this.formatSLOValue(point.value, slo.metric) + '" onclick="App.viewRun(\'' + point.runId + '\')"></span>'
+            ).join('');
+            const breaches = slo.breaches.length + ' breach' + (slo.breaches.length === 1 ? '' : 'es');
+
+            html += '<tr>' +
+                '<td>' + slo.name + '<br><small>' + slo.benchmark + '</small></td>' +
+                '<td>' + slo.objective + '</td>' +
+                '<td>' + current + '</td>' +
+                '<td>' + burnRate + '</td>' +
+                '<td>' + status + '</td>' +
+                '<td><div class="slo-history">' + history + '</div><small>' + breaches + '</small></td>' +
+                '</tr>';
+        });
+
+        html += '</tbody></table>';
+        container.innerHTML = html;
+    },
+
+    async loadStress() {
+        // Stress runs are looked up among the live runs only
+        if (this.snapshot) return;
+        try {
+            const response = await fetch('/api/stress');
+            this.updateStress(await response.json());
+        } catch (error) {
+            console.error('Failed to load stress report:', error);
+        }
+    },
+
+    // updateStress charts the speedup of each benchmark of the newest stress
+    // run against linear scaling, and tabulates where throughput peaks
+    updateStress(report) {
+        const panel = document.getElementById('stressPanel');
+        if (this.charts.stress) {
+            this.charts.stress.destroy();
+            this.charts.stress = null;
+        }
+        if (!report.curves || report.curves.length === 0) {
+            panel.style.display = 'none';
+            return;
+        }
+        panel.style.display = '';
+
+        const date = new Date(report.timestamp).toLocaleString(undefined, App.timeOptions);
+        document.getElementById('stressInfo').textContent = 'Run ' + report.runId + ' (' + date + ') on ' +
+            report.cpus + ' CPUs, GOMAXPROCS ' + report.levels.join(', ');
+
+        const isDark = document.documentElement.getAttribute('data-theme') === 'dark';
+        const textColor = isDark ? '#e9ecef' : '#212529';
+        const gridColor = isDark ? '#404040' : '#dee2e6';
+        const colors = ['#4dabf7', '#51cf66', '#ff6b6b', '#ffd43b', '#a78bfa', '#fb923c'];
+
+        const base = report.levels[0];
+        const datasets = [{
+            label: 'Linear',
+            data: report.levels.map(level => ({ x: level, y: level / base })),
+            borderColor: gridColor,
+            borderDash: [6, 4],
+            pointRadius: 0
+        }];
+        report.curves.forEach((curve, i) => {
+            datasets.push({
+                label: curve.benchmark,
+                data: curve.points.map(p => ({ x: p.parallelism, y: p.speedup })),
+                borderColor: colors[i % colors.length],
+                backgroundColor: colors[i % colors.length] + '33'
+            });
+        });
+
+        this.charts.stress = new Chart(document.getElementById('stressChart'), {
+            type: 'line',
+            data: { datasets: datasets },
+            options: {
+                responsive: true,
+                maintainAspectRatio: true,
+                plugins: {
+                    legend: {
+                        labels: { color: textColor }
+                    },
+                    tooltip: {
+                        callbacks: {
+                            label: function(context) {
+                                return context.dataset.label + ': ' + context.parsed.y.toFixed(2) + 'x at ' +
+                                    context.parsed.x + ' goroutines';
+                            }
+                        }
+                    }
+                },
+                scales: {
+                    y: {
+                        beginAtZero: true,
+                        title: { display: true, text: 'Speedup', color: textColor },
+                        ticks: { color: textColor },
+                        grid: { color: gridColor }
+                    },
+                    x: {
+                        type: 'logarithmic',
+                        title: { display: true, text: 'Goroutines (GOMAXPROCS)', color: textColor },
+                        ticks: { color: textColor },
+                        grid: { color: gridColor }
+                    }
+                }
+            }
+        });
+
+        let html = '<table><thead><tr>' +
+            '<th>Benchmark</th>' +
+            '<th>Peak</th>' +
+            '<th>Best Speedup</th>' +
+            '<th>Efficiency at ' + report.levels[report.levels.length - 1] + '</th>' +
+            '</tr></thead><tbody>';
+        report.curves.forEach(curve => {
+            const peak = curve.points.find(p => p.parallelism === curve.peak);
+            const last = curve.points[curve.points.length - 1];
+            html += '<tr>' +
+                '<td>' + curve.benchmark + '</td>' +
+                '<td' + (curve.contended ? ' class="delta-degraded" title="Throughput falls beyond this level"' : '') + '>' +
+                curve.peak + '</td>' +
+                '<td>' + peak.speedup.toFixed(2) + 'x</td>' +
+                '<td>' + Math.round(last.efficiency * 100) + '%</td>' +
+                '</tr>';
+        });
+        html += '</tbody></table>';
+        document.getElementById('stressTable').innerHTML = html;
+    },
+
+    formatSLOValue(value, metric) {
+        if (metric !== 'ns/op') return value.toFixed(2) + ' ' + metric;
+        if (value >= 1e9) return (value / 1e9).toFixed(2) + 's';
+        if (value >= 1e6) return (value / 1e6).toFixed(2) + 'ms';
+        if (value >= 1e3) return (value / 1e3).toFixed(2) + 'µs';
+        return value.toFixed(2) + 'ns';
     },
 
     updateRecentRuns() {
@@ -158,11 +519,11 @@ const App = {
             const date = new Date(run.timestamp);
             return '<div class="run-item" onclick="App.viewRun(\'' + run.id + '\')">' +
                 '<div>' +
-                '<strong>' + run.package + '</strong><br>' +
+                '<strong>' + this.escapeHtml(run.package) + '</strong><br>' +
                 '<small>' + run.numTests + ' tests</small>' +
                 '</div>' +
                 '<div>' +
-                '<small>' + date.toLocaleString() + '</small>' +
+                '<small>' + date.toLocaleString(undefined, App.timeOptions) + '</small>' +
                 '</div>' +
                 '</div>';
         }).join('');
@@ -177,7 +538,7 @@ const App = {
 
         const labels = runs.map(run => {
             const date = new Date(run.timestamp);
-            return date.toLocaleDateString();
+            return date.toLocaleDateString(undefined, App.timeOptions);
         });
 
         const avgData = runs.map(run => run.avgNsPerOp || 0);
@@ -202,6 +563,13 @@ const App = {
                     backgroundColor: 'rgba(77, 171, 247, 0.1)',
                     tension: 0.4,
                     fill: true
+                }, {
+                    label: 'Score (ops/s)',
+                    data: runs.map(run => run.score ?? null),
+                    borderColor: '#51cf66',
+                    backgroundColor: 'rgba(81, 207, 102, 0.1)',
+                    tension: 0.4,
+                    yAxisID: 'score'
                 }]
             },
             options: {
@@ -214,6 +582,9 @@ const App = {
                     tooltip: {
                         callbacks: {
                             label: function(context) {
+                                if (context.dataset.yAxisID === 'score') {
+                                    return 'Score: ' + App.formatScore(context.parsed.y) + ' ops/s';
+                                }
                                 return 'Avg: ' + context.parsed.y.toFixed(2) + ' ns/op';
                             }
                         }
@@ -225,6 +596,73 @@ const App = {
                         ticks: { color: textColor },
                         grid: { color: gridColor }
                     },
+                    score: {
+                        position: 'right',
+                        ticks: {
+                            color: textColor,
+                            callback: value => App.formatScore(value)
+                        },
+                        grid: { drawOnChartArea: false }
+                    },
+                    x: {
+                        ticks: { color: textColor },
+                        grid: { color: gridColor }
+                    }
+                }
+            }
+        });
+    },
+
+    createSkipRateChart() {
+        const runs = this.data.runs.slice(0, 20).reverse();
+        if (runs.length === 0) {
+            return;
+        }
+
+        const ctx = document.getElementById('skipRateChart');
+        if (this.charts.skipRate) {
+            this.charts.skipRate.destroy();
+        }
+
+        const isDark = document.documentElement.getAttribute('data-theme') === 'dark';
+        const textColor = isDark ? '#e9ecef' : '#212529';
+        const gridColor = isDark ? '#404040' : '#dee2e6';
+
+        this.charts.skipRate = new Chart(ctx, {
+            type: 'bar',
+            data: {
+                labels: runs.map(run => new Date(run.timestamp).toLocaleDateString(undefined, App.timeOptions)),
+                datasets: [{
+                    label: 'Skipped benchmarks (%)',
+                    data: runs.map(run => run.skipRate || 0),
+                    backgroundColor: 'rgba(255, 212, 59, 0.6)',
+                    borderColor: '#ffd43b'
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: true,
+                plugins: {
+                    legend: {
+                        labels: { color: textColor }
+                    },
+                    tooltip: {
+                        callbacks: {
+                            label: function(context) {
+                                const run = runs[context.dataIndex];
+                                return (run.numSkipped || 0) + ' of ' + run.numTests + ' skipped (' +
+                                    context.parsed.y.toFixed(1) + '%)';
+                            }
+                        }
+                    }
+                },
+                scales: {
+                    y: {
+                        beginAtZero: true,
+                        max: 100,
+                        ticks: { color: textColor },
+                        grid: { color: gridColor }
+                    },
                     x: {
                         ticks: { color: textColor },
                         grid: { color: gridColor }
@@ -239,9 +677,10 @@ const App = {
         const limit = document.getElementById('limitSelect').value;
 
         try {
-            const url = '/api/trends?limit=' + limit + (benchmark ? '&benchmark=' + encodeURIComponent(benchmark) : '');
+            const url = this.api('trends') + '?limit=' + limit + (benchmark ? '&benchmark=' + encodeURIComponent(benchmark) : '');
             const res = await fetch(url);
             this.data.trends = await res.json();
+            this.populateMetricSelect();
             this.createTrendsChart();
             this.updateTrendsStats();
         } catch (error) {
@@ -249,33 +688,90 @@ const App = {
         }
     },
 
+    populateMetricSelect() {
+        const select = document.getElementById('metricSelect');
+        const current = select.value;
+        const metrics = new Set();
+        for (const points of Object.values(this.data.trends.trends || {})) {
+            points.forEach(p => Object.keys(p.metrics || {}).forEach(m => metrics.add(m)));
+        }
+
+        select.innerHTML = '<option value="ns/op">ns/op</option>' +
+            Array.from(metrics).sort().map(m => '<option value="' + m + '">' + m + '</option>').join('');
+        select.value = metrics.has(current) ? current : 'ns/op';
+    },
+
+    metricValue(point, metric) {
+        if (metric === 'ns/op') return point.nsPerOp;
+        return point.metrics ? point.metrics[metric] : undefined;
+    },
+
     createTrendsChart() {
         const trends = this.data.trends.trends;
         if (!trends || Object.keys(trends).length === 0) {
             return;
         }
 
+        const metric = document.getElementById('metricSelect').value;
+
         const colors = ['#4dabf7', '#51cf66', '#ff6b6b', '#ffd43b', '#a78bfa', '#fb923c'];
         const datasets = [];
+        const benchmarkColors = {};
         let colorIndex = 0;
+        let first = null;
+        let last = null;
 
         for (const [name, points] of Object.entries(trends)) {
-            if (points.length === 0) continue;
+            const values = points.filter(p => this.metricValue(p, metric) !== undefined);
+            if (values.length === 0) continue;
+
+            benchmarkColors[name] = colors[colorIndex % colors.length];
+            values.forEach(p => {
+                const t = new Date(p.timestamp);
+                if (first === null || t < first) first = t;
+                if (last === null || t > last) last = t;
+            });
 
+            // Anomalies are detected on ns/op and drawn as larger red points
+            const anomalous = values.map(p => metric === 'ns/op' && p.anomaly !== undefined);
             datasets.push({
-                label: name,
-                data: points.map(p => ({
+                label: this.trendLabel(name),
+                data: values.map(p => ({
                     x: new Date(p.timestamp),
-                    y: p.nsPerOp
+                    y: this.metricValue(p, metric),
+                    anomaly: metric === 'ns/op' ? p.anomaly : undefined
                 })),
                 borderColor: colors[colorIndex % colors.length],
                 backgroundColor: colors[colorIndex % colors.length] + '33',
+                pointRadius: anomalous.map(a => a ? 7 : 3),
+                pointBackgroundColor: anomalous.map(a => a ? '#e03131' : colors[colorIndex % colors.length] + '33'),
+                pointBorderColor: anomalous.map(a => a ? '#e03131' : colors[colorIndex % colors.length]),
+                pointStyle: anomalous.map(a => a ? 'triangle' : 'circle'),
                 tension: 0.4,
                 fill: false
             });
             colorIndex++;
         }
 
+        // Dashed reference line at each named baseline's value
+        for (const baseline of this.data.trends.baselines || []) {
+            for (const [name, point] of Object.entries(baseline.values)) {
+                const value = this.metricValue(point, metric);
+                if (value === undefined || !benchmarkColors[name]) continue;
+
+                datasets.push({
+                    label: this.trendLabel(name) + ' @ ' + baseline.name,
+                    data: [{ x: first, y: value }, { x: last, y: value }],
+                    borderColor: benchmarkColors[name],
+                    borderDash: [6, 4],
+                    borderWidth: 1.5,
+                    pointRadius: 0,
+                    tension: 0,
+                    fill: false
+                });
+            }
+        }
+
         const ctx = document.getElementById('trendsChart');
         if (this.charts.trends) {
             this.charts.trends.destroy();
@@ -285,9 +781,38 @@ const App = {
         const textColor = isDark ? '#e9ecef' : '#212529';
         const gridColor = isDark ? '#404040' : '#dee2e6';
 
+        // Annotated runs are drawn as dashed vertical lines labeled with
+        // their notes, stacked when a run has several
+        const annotations = this.data.trends.annotations || [];
+        const annotationMarkers = {
+            id: 'annotationMarkers',
+            afterDatasetsDraw(chart) {
+                const area = chart.chartArea;
+                const draw = chart.ctx;
+                const stacked = {};
+                draw.save();
+                draw.strokeStyle = '#845ef7';
+                draw.fillStyle = '#845ef7';
+                draw.font = '11px sans-serif';
+                draw.setLineDash([4, 4]);
+                annotations.forEach(annotation => {
+                    const x = chart.scales.x.getPixelForValue(new Date(annotation.timestamp).getTime());
+                    if (x < area.left || x > area.right) return;
+                    const row = stacked[x] = (stacked[x] || 0) + 1;
+                    draw.beginPath();
+                    draw.moveTo(x, area.top);
+                    draw.lineTo(x, area.bottom);
+                    draw.stroke();
+                    draw.fillText('📝 ' + App.formatAnnotation(annotation), x + 4, area.top + 12 * row);
+                });
+                draw.restore();
+            }
+        };
+
         this.charts.trends = new Chart(ctx, {
             type: 'line',
             data: { datasets: datasets },
+            plugins: [annotationMarkers],
             options: {
                 responsive: true,
                 maintainAspectRatio: true,
@@ -298,7 +823,11 @@ const App = {
                     tooltip: {
                         callbacks: {
                             label: function(context) {
-                                return context.dataset.label + ': ' + context.parsed.y.toFixed(2) + ' ns/op';
+                                const label = context.dataset.label + ': ' + context.parsed.y.toFixed(2) + ' ' + metric;
+                                const anomaly = context.raw.anomaly;
+                                if (!anomaly) return label;
+                                return label + ' ⚠️ anomaly: ' + (anomaly.score > 0 ? '+' : '') + anomaly.score.toFixed(1) +
+                                    'σ from ' + anomaly.expected.toFixed(2);
                             }
                         }
                     }
@@ -343,7 +872,8 @@ const App = {
                 '<p><strong>Median:</strong> ' + stat.median.toFixed(2) + ' ns/op</p>' +
                 '<p><strong>Std Dev:</strong> ' + stat.stdDev.toFixed(2) + '</p>' +
                 '<p><strong>CV:</strong> ' + (stat.cv * 100).toFixed(2) + '%</p>' +
-                '<p><strong>Trend:</strong> ' + stat.trend + '</p>';
+                '<p><strong>Trend:</strong> ' + stat.trend + '</p>' +
+                (stat.anomalies ? '<p><strong>Anomalies:</strong> ⚠️ ' + stat.anomalies + '</p>' : '');
 
             container.appendChild(card);
         }
@@ -353,15 +883,45 @@ const App = {
         const select = document.getElementById('benchmarkSelect');
         const benchmarks = this.data.stats.benchmarks || [];
 
+        const families = this.data.stats.families || {};
+
         select.innerHTML = '<option value="">All Benchmarks</option>';
+
+        // A family charts all variants of a table-driven benchmark together
+        let parent = select;
+        const familyNames = Object.keys(families).sort();
+        if (familyNames.length > 0) {
+            const group = document.createElement('optgroup');
+            group.label = 'Families';
+            familyNames.forEach(family => {
+                const option = document.createElement('option');
+                option.value = family;
+                option.textContent = family + ' (' + families[family].length + ' variants)';
+                group.appendChild(option);
+            });
+            select.appendChild(group);
+
+            parent = document.createElement('optgroup');
+            parent.label = 'Benchmarks';
+            select.appendChild(parent);
+        }
         benchmarks.forEach(name => {
             const option = document.createElement('option');
             option.value = name;
             option.textContent = name;
-            select.appendChild(option);
+            parent.appendChild(option);
         });
     },
 
+    // trendLabel names a charted benchmark, by its variant alone when a
+    // whole family is charted
+    trendLabel(name) {
+        const benchmark = document.getElementById('benchmarkSelect').value;
+        const variants = (this.data.trends.families || {})[benchmark];
+        if (!variants || !name.startsWith(benchmark + '/')) return name;
+        return name.slice(benchmark.length + 1);
+    },
+
     updateHistory() {
         const container = document.getElementById('historyTable');
         const runs = this.data.runs;
@@ -383,11 +943,15 @@ const App = {
         runs.forEach(run => {
             const date = new Date(run.timestamp);
             html += '<tr onclick="App.viewRun(\'' + run.id + '\')">' +
-                '<td>' + run.id.substring(0, 8) + '</td>' +
-                '<td>' + date.toLocaleString() + '</td>' +
-                '<td>' + run.package + '</td>' +
-                '<td>' + run.goVersion + '</td>' +
-                '<td>' + run.numTests + '</td>' +
+                '<td>' + run.id.substring(0, 8) + ' ' + this.gradeBadge(run.confidence) + '</td>' +
+                '<td>' + date.toLocaleString(undefined, App.timeOptions) + '</td>' +
+                '<td>' + this.escapeHtml(run.package) + (run.agent ? ' <small>(on ' + this.escapeHtml(run.agent) + ')</small>' : '') +
+                    (run.note ? '<br><small>' + this.escapeHtml(run.note) + '</small>' : '') +
+                    (run.annotations || []).map(a => '<br><small>📝 ' + this.escapeHtml(this.formatAnnotation(a)) + '</small>').join('') +
+                    Object.keys(run.tags || {}).sort().map(key =>
+                        ' <span class="run-tag">' + this.escapeHtml(key + '=' + run.tags[key]) + '</span>').join('') + '</td>' +
+                '<td>' + this.escapeHtml(run.goVersion) + '</td>' +
+                '<td>' + run.numTests + (run.numFailed ? ' (' + run.numFailed + ' failed)' : '') + '</td>' +
                 '<td>' + (run.avgNsPerOp ? run.avgNsPerOp.toFixed(2) : 'N/A') + '</td>' +
                 '</tr>';
         });
@@ -416,7 +980,7 @@ const App = {
 
         runs.forEach(run => {
             const date = new Date(run.timestamp);
-            const text = run.id.substring(0, 8) + ' - ' + run.package + ' (' + date.toLocaleDateString() + ')';
+            const text = run.id.substring(0, 8) + ' - ' + run.package + ' (' + date.toLocaleDateString(undefined, App.timeOptions) + ')';
 
             const option1 = document.createElement('option');
             option1.value = run.id;
@@ -429,8 +993,12 @@ const App = {
             select2.appendChild(option2);
         });
 
-        // Select first and second by default
-        if (runs.length >= 2) {
+        // Select first and second by default, or the runs of a comparison
+        // link
+        if (this.data.comparison) {
+            select1.value = this.data.comparison.old;
+            select2.value = this.data.comparison.new;
+        } else if (runs.length >= 2) {
             select1.selectedIndex = 0;
             select2.selectedIndex = 1;
         }
@@ -439,6 +1007,7 @@ const App = {
     async compareRuns() {
         const id1 = document.getElementById('compareRun1').value;
         const id2 = document.getElementById('compareRun2').value;
+        const metric = document.getElementById('compareMetric').value;
 
         if (!id1 || !id2) {
             alert('Please select two runs to compare');
@@ -450,48 +1019,204 @@ const App = {
             return;
         }
 
-        try {
-            const [run1Res, run2Res] = await Promise.all([
-                fetch('/api/runs/' + id1),
-                fetch('/api/runs/' + id2)
-            ]);
-
-            const run1 = await run1Res.json();
-            const run2 = await run2Res.json();
+        if (await this.loadComparison(id1 + '..' + id2, metric)) {
+            // Update URL for sharing
+            const url = new URL(window.location);
+            url.searchParams.set('tab', 'compare');
+            url.searchParams.set('compare', id1 + '..' + id2);
+            url.searchParams.set('metric', metric);
+            window.history.pushState({}, '', url);
+        }
+    },
 
-            this.displayComparison(run1, run2);
+    // loadComparison shows the comparison of a link, such as
+    // compare=runA..runB, after the server validated it. It reports whether
+    // the comparison was shown.
+    async loadComparison(compare, metric) {
+        try {
+            const params = new URLSearchParams({ compare: compare, metric: metric || 'ns' });
+            const res = await fetch('/api/compare?' + params);
+            if (!res.ok) {
+                alert('Invalid comparison: ' + (await res.text()));
+                return false;
+            }
+            const comparison = await res.json();
+
+            this.data.comparison = { old: comparison.old.id, new: comparison.new.id, metric: comparison.metric };
+            document.getElementById('compareRun1').value = comparison.old.id;
+            document.getElementById('compareRun2').value = comparison.new.id;
+            document.getElementById('compareMetric').value = comparison.metric;
+            this.displayComparison(comparison.old, comparison.new, comparison.metric);
+            this.loadScaling(comparison.old, comparison.new);
+            return true;
         } catch (error) {
             console.error('Failed to compare runs:', error);
             alert('Failed to load run data');
+            return false;
+        }
+    },
+
+    // loadScaling shows the scalability curves of two runs taken with
+    // several GOMAXPROCS values, and hides them for other runs
+    async loadScaling(run1, run2) {
+        this.data.scaling = [];
+        if (run1.cpu_matrix && run2.cpu_matrix) {
+            try {
+                const params = new URLSearchParams({ compare: run1.id + '..' + run2.id });
+                const response = await fetch('/api/scaling?' + params);
+                this.data.scaling = (await response.json()).curves;
+            } catch (error) {
+                console.error('Failed to load scaling curves:', error);
+            }
+        }
+
+        const panel = document.getElementById('scalingPanel');
+        if (this.data.scaling.length === 0) {
+            if (this.charts.scaling) {
+                this.charts.scaling.destroy();
+                this.charts.scaling = null;
+            }
+            panel.style.display = 'none';
+            return;
         }
+        panel.style.display = '';
+
+        const select = document.getElementById('scalingBenchmark');
+        select.innerHTML = this.data.scaling.map((curve, i) =>
+            '<option value="' + i + '">' + curve.benchmark + '</option>').join('');
+        select.onchange = () => this.updateScaling(this.data.scaling[select.value]);
+        this.updateScaling(this.data.scaling[0]);
     },
 
-    displayComparison(run1, run2) {
+    // updateScaling charts the ns/op of a benchmark at each GOMAXPROCS
+    // value in both runs of the comparison
+    updateScaling(curve) {
+        if (this.charts.scaling) {
+            this.charts.scaling.destroy();
+        }
+
+        const isDark = document.documentElement.getAttribute('data-theme') === 'dark';
+        const textColor = isDark ? '#e9ecef' : '#212529';
+        const gridColor = isDark ? '#404040' : '#dee2e6';
+
+        this.charts.scaling = new Chart(document.getElementById('scalingChart'), {
+            type: 'line',
+            data: {
+                datasets: [{
+                    label: 'Baseline',
+                    data: curve.levels.map(l => ({ x: l.procs, y: l.oldNsPerOp })),
+                    borderColor: '#adb5bd',
+                    borderDash: [6, 4]
+                }, {
+                    label: 'Compared',
+                    data: curve.levels.map(l => ({ x: l.procs, y: l.newNsPerOp })),
+                    borderColor: '#4dabf7',
+                    backgroundColor: '#4dabf733'
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: true,
+                plugins: {
+                    legend: {
+                        labels: { color: textColor }
+                    },
+                    tooltip: {
+                        callbacks: {
+                            label: function(context) {
+                                return context.dataset.label + ': ' + context.parsed.y.toFixed(2) + ' ns/op at GOMAXPROCS ' +
+                                    context.parsed.x;
+                            }
+                        }
+                    }
+                },
+                scales: {
+                    y: {
+                        beginAtZero: true,
+                        title: { display: true, text: 'ns/op', color: textColor },
+                        ticks: { color: textColor },
+                        grid: { color: gridColor }
+                    },
+                    x: {
+                        type: 'logarithmic',
+                        title: { display: true, text: 'GOMAXPROCS', color: textColor },
+                        ticks: { color: textColor },
+                        grid: { color: gridColor }
+                    }
+                }
+            }
+        });
+
+        let html = '<table><thead><tr>' +
+            '<th>CPUs</th>' +
+            '<th>Baseline ns/op</th>' +
+            '<th>Compared ns/op</th>' +
+            '<th>Delta</th>' +
+            '<th>Speedup</th>' +
+            '</tr></thead><tbody>';
+        curve.levels.forEach(l => {
+            const deltaClass = l.deltaPercent > 5 ? 'delta-degraded' : (l.deltaPercent < -5 ? 'delta-improved' : 'delta-same');
+            html += '<tr>' +
+                '<td>' + l.procs + '</td>' +
+                '<td>' + l.oldNsPerOp.toFixed(2) + '</td>' +
+                '<td>' + l.newNsPerOp.toFixed(2) + '</td>' +
+                '<td class="' + deltaClass + '">' + (l.deltaPercent > 0 ? '+' : '') + l.deltaPercent.toFixed(2) + '%</td>' +
+                '<td>' + l.oldSpeedup.toFixed(2) + 'x → ' + l.newSpeedup.toFixed(2) + 'x</td>' +
+                '</tr>';
+        });
+        html += '</tbody></table>';
+        document.getElementById('scalingTable').innerHTML = html;
+    },
+
+    // compareMetrics are the result fields and units of the metrics a
+    // comparison can show
+    compareMetrics: {
+        ns: { field: 'ns_per_op', unit: 'ns/op', less: 'faster', more: 'slower' },
+        bytes: { field: 'bytes_per_op', unit: 'B/op', less: 'less memory', more: 'more memory' },
+        allocs: { field: 'allocs_per_op', unit: 'allocs/op', less: 'fewer allocations', more: 'more allocations' }
+    },
+
+    displayComparison(run1, run2, metric) {
+        const m = this.compareMetrics[metric] || this.compareMetrics.ns;
         const container = document.getElementById('compareResults');
 
         // Create a map of benchmarks
         const benchMap = new Map();
 
-        run1.Results.forEach(result => {
-            benchMap.set(result.Name, { old: result, new: null });
+        run1.results.forEach(result => {
+            benchMap.set(result.name, { old: result, new: null });
         });
 
-        run2.Results.forEach(result => {
-            if (benchMap.has(result.Name)) {
-                benchMap.get(result.Name).new = result;
+        run2.results.forEach(result => {
+            if (benchMap.has(result.name)) {
+                benchMap.get(result.name).new = result;
             } else {
-                benchMap.set(result.Name, { old: null, new: result });
+                benchMap.set(result.name, { old: null, new: result });
             }
         });
 
         let html = '<h3>Comparison Results</h3>';
-        html += '<p>Baseline: ' + run1.ID.substring(0, 8) + ' vs ' + run2.ID.substring(0, 8) + '</p>';
+        html += '<p>Baseline: ' + run1.id.substring(0, 8) + ' vs ' + run2.id.substring(0, 8) + ' (' + m.unit + ')</p>';
 
         benchMap.forEach((data, name) => {
-            if (!data.old || !data.new) return;
+            if (!data.new) return;
+
+            // Failed and skipped benchmarks have no measurements to compare
+            if (data.new.status && data.new.status !== 'ok') {
+                html += '<div class="comparison-item">' +
+                    '<div><strong' + App.docTitle(data.new) + '>' + name + '</strong></div>' +
+                    '<div class="delta-degraded">' + data.new.status.toUpperCase() +
+                    (data.new.message ? ': ' + data.new.message : '') + '</div>' +
+                    '</div>';
+                return;
+            }
+            if (!data.old || (data.old.status && data.old.status !== 'ok')) return;
 
-            const delta = data.new.NsPerOp - data.old.NsPerOp;
-            const deltaPercent = (delta / data.old.NsPerOp) * 100;
+            // Memory statistics are omitted when zero
+            const oldValue = data.old[m.field] || 0;
+            const newValue = data.new[m.field] || 0;
+            const delta = newValue - oldValue;
+            const deltaPercent = oldValue === 0 ? (newValue === 0 ? 0 : Infinity) : (delta / oldValue) * 100;
 
             let deltaClass = 'delta-same';
             let deltaText = 'No change';
@@ -499,28 +1224,71 @@ const App = {
             if (Math.abs(deltaPercent) > 5) {
                 if (deltaPercent < 0) {
                     deltaClass = 'delta-improved';
-                    deltaText = deltaPercent.toFixed(2) + '% faster';
+                    deltaText = deltaPercent.toFixed(2) + '% ' + m.less;
+                } else if (oldValue === 0) {
+                    deltaClass = 'delta-degraded';
+                    deltaText = newValue + ' ' + m.unit + ', none before';
                 } else {
                     deltaClass = 'delta-degraded';
-                    deltaText = '+' + deltaPercent.toFixed(2) + '% slower';
+                    deltaText = '+' + deltaPercent.toFixed(2) + '% ' + m.more;
                 }
             }
 
             html += '<div class="comparison-item">' +
-                '<div><strong>' + name + '</strong></div>' +
+                '<div><strong' + App.docTitle(data.new) + '>' + name + '</strong> ' +
+                    this.gradeBadge(this.worseGrade(data.old.confidence, data.new.confidence)) + '</div>' +
                 '<div class="' + deltaClass + '">' + deltaText + '</div>' +
                 '</div>';
         });
 
+        html += this.renderSuggestions(run2);
         container.innerHTML = html;
     },
 
+    renderSuggestions(run) {
+        const suggestions = (run.profile_summary && run.profile_summary.suggestions) || [];
+        if (suggestions.length === 0) return '';
+
+        let html = '<h3>Optimization Suggestions</h3>';
+        suggestions.forEach(sug => {
+            const evidence = [];
+            if (sug.rule_id) {
+                evidence.push(this.escapeHtml(sug.rule_id) + (sug.confidence ? ' (' + Math.round(sug.confidence * 100) + '% confidence)' : ''));
+            }
+            if (sug.profile) {
+                evidence.push('<a href="/api/runs/' + encodeURIComponent(run.id) + '/profiles/' + encodeURIComponent(sug.profile) + '">' +
+                    this.escapeHtml(sug.profile) + ' profile</a>');
+            }
+            if (sug.benchmarks && sug.benchmarks.length > 0) {
+                evidence.push('benchmarks ' + this.escapeHtml(sug.benchmarks.join(', ')));
+            }
+
+            // Suggestions may come from an AI provider: escape every field
+            const type = sug.type ? '[' + this.escapeHtml(String(sug.type).toUpperCase()) + '] ' : '';
+            html += '<div class="suggestion-item severity-' + this.escapeHtml(sug.severity || '') + '">' +
+                '<div><strong>' + type + this.escapeHtml(sug.function || '') + '</strong></div>' +
+                '<div>' + this.escapeHtml(sug.issue || '') + '</div>' +
+                '<div>' + this.escapeHtml(sug.suggestion || '') + '</div>' +
+                (sug.impact ? '<div><em>' + this.escapeHtml(sug.impact) + '</em></div>' : '') +
+                (evidence.length > 0 ? '<div class="suggestion-evidence">' + evidence.join(' · ') + '</div>' : '') +
+                '</div>';
+        });
+        return html;
+    },
+
     async performSearch() {
         const query = document.getElementById('searchInput').value.trim();
         if (!query) return;
 
         try {
-            const res = await fetch('/api/search?q=' + encodeURIComponent(query));
+            const since = document.getElementById('searchSince').value;
+            const res = await fetch('/api/search?q=' + encodeURIComponent(query) +
+                (since ? '&since=' + encodeURIComponent(since) : ''));
+            if (!res.ok) {
+                document.getElementById('searchResults').innerHTML =
+                    '<div class="search-result-item">' + this.escapeHtml(await res.text()) + '</div>';
+                return;
+            }
             const data = await res.json();
             this.displaySearchResults(data);
         } catch (error) {
@@ -541,12 +1309,13 @@ const App = {
             if (result.type === 'run') {
                 return '<div class="search-result-item" onclick="App.viewRun(\'' + result.id + '\')">' +
                     '<strong>Run: ' + result.id.substring(0, 8) + '</strong><br>' +
-                    '<small>' + result.package + ' - ' + date.toLocaleString() + '</small>' +
+                    '<small>' + this.escapeHtml(result.package) + ' - ' + date.toLocaleString(undefined, App.timeOptions) + '</small>' +
+                    (result.note || result.commitMessage ? '<br><small>' + this.escapeHtml(result.note || result.commitMessage) + '</small>' : '') +
                     '</div>';
             } else {
                 return '<div class="search-result-item" onclick="App.viewRun(\'' + result.runId + '\')">' +
-                    '<strong>Benchmark: ' + result.name + '</strong><br>' +
-                    '<small>' + result.nsPerOp.toFixed(2) + ' ns/op - ' + date.toLocaleString() + '</small>' +
+                    '<strong>Benchmark: ' + this.escapeHtml(result.name) + '</strong><br>' +
+                    '<small>' + result.nsPerOp.toFixed(2) + ' ns/op - ' + date.toLocaleString(undefined, App.timeOptions) + '</small>' +
                     '</div>';
             }
         }).join('');
@@ -565,16 +1334,112 @@ const App = {
             url.searchParams.set('run', id);
             window.history.pushState({}, '', url);
 
-            alert('Run Details:\\n' +
-                'ID: ' + run.ID + '\\n' +
-                'Package: ' + run.Package + '\\n' +
-                'Tests: ' + run.Results.length + '\\n' +
-                'Go Version: ' + run.GoVersion);
+            this.showRunDetails(run);
         } catch (error) {
             console.error('Failed to load run:', error);
         }
     },
 
+    // showRunDetails opens the run modal, with the controls to edit, delete
+    // or save the run as a baseline when the dashboard lets runs be managed
+    async showRunDetails(run) {
+        this.data.selectedRun = run;
+        const date = new Date(run.timestamp);
+        document.getElementById('runDetailId').textContent = run.id;
+        document.getElementById('runDetailInfo').innerHTML =
+            '<div><strong>Date:</strong> ' + date.toLocaleString(undefined, App.timeOptions) + '</div>' +
+            '<div><strong>Package:</strong> ' + this.escapeHtml(run.package) + '</div>' +
+            '<div><strong>Go Version:</strong> ' + this.escapeHtml(run.go_version || 'N/A') + '</div>' +
+            '<div><strong>Tests:</strong> ' + run.results.length + '</div>' +
+            this.gradeCounts(run.results) +
+            (run.commit ? '<div><strong>Commit:</strong> ' + this.escapeHtml(run.commit.substring(0, 12)) +
+                (run.commit_message ? ' ' + this.escapeHtml(run.commit_message) : '') + '</div>' : '') +
+            (run.note ? '<div><strong>Note:</strong> ' + this.escapeHtml(run.note) + '</div>' : '') +
+            (run.annotations || []).map(a => '<div><strong>📝 ' + new Date(a.time).toLocaleDateString() + ':</strong> ' +
+                this.escapeHtml(this.formatAnnotation(a)) + '</div>').join('') +
+            (run.tags ? '<div><strong>Tags:</strong> ' + this.escapeHtml(this.formatLabels(run.tags)) + '</div>' : '');
+
+        document.getElementById('runNote').value = run.note || '';
+        document.getElementById('runTags').value = this.formatLabels(run.tags);
+        document.getElementById('runBaselineName').value = '';
+        document.getElementById('manageToken').value = localStorage.getItem('gokanonToken') || '';
+        document.getElementById('manageError').textContent = '';
+        document.getElementById('runManage').style.display = 'none';
+        document.getElementById('runDetailModal').classList.add('active');
+
+        if (this.snapshot) return;
+        try {
+            const response = await fetch('/api/baselines');
+            const data = await response.json();
+            document.getElementById('runManage').style.display = data.manage ? '' : 'none';
+        } catch (error) {
+            console.error('Failed to load baselines:', error);
+        }
+    },
+
+    // manageRun sends a request changing runs or baselines, with the access
+    // token when one was entered; viewers who logged in need none. It
+    // returns whether the request succeeded, showing the error otherwise.
+    async manageRun(url, method, body) {
+        const token = document.getElementById('manageToken').value;
+        const errorEl = document.getElementById('manageError');
+        errorEl.textContent = '';
+
+        const headers = {'Content-Type': 'application/json'};
+        if (token) headers['Authorization'] = 'Bearer ' + token;
+        try {
+            const response = await fetch(url, {
+                method: method,
+                headers: headers,
+                body: body ? JSON.stringify(body) : undefined
+            });
+            if (!response.ok) {
+                errorEl.textContent = (await response.text()).trim();
+                return false;
+            }
+            if (token) localStorage.setItem('gokanonToken', token);
+            return true;
+        } catch (error) {
+            errorEl.textContent = 'Request failed: ' + error;
+            return false;
+        }
+    },
+
+    async saveRunMetadata() {
+        const run = this.data.selectedRun;
+        const ok = await this.manageRun('/api/runs/' + encodeURIComponent(run.id), 'PATCH', {
+            note: document.getElementById('runNote').value,
+            tags: this.parseLabels(document.getElementById('runTags').value)
+        });
+        if (!ok) return;
+        document.getElementById('runDetailModal').classList.remove('active');
+        this.loadData();
+    },
+
+    async saveRunBaseline() {
+        const run = this.data.selectedRun;
+        const name = document.getElementById('runBaselineName').value.trim();
+        if (!name) {
+            document.getElementById('manageError').textContent = 'Enter a baseline name';
+            return;
+        }
+        let ok = await this.manageRun('/api/baselines', 'POST', {name: name, run: run.id});
+        const errorEl = document.getElementById('manageError');
+        if (!ok && errorEl.textContent.includes('already exists') &&
+            confirm('Baseline ' + name + ' already exists. Replace it with run ' + run.id + '?')) {
+            ok = await this.manageRun('/api/baselines', 'POST', {name: name, run: run.id, replace: true});
+        }
+        if (ok) errorEl.textContent = 'Saved baseline ' + name;
+    },
+
+    async deleteRun() {
+        const run = this.data.selectedRun;
+        if (!confirm('Delete run ' + run.id + ' and its profiles? This cannot be undone.')) return;
+        if (!await this.manageRun('/api/runs/' + encodeURIComponent(run.id), 'DELETE')) return;
+        document.getElementById('runDetailModal').classList.remove('active');
+        this.loadData();
+    },
+
     switchTab(tabName) {
         // Update buttons
         document.querySelectorAll('.tab-btn').forEach(btn => {
@@ -596,17 +1461,332 @@ const App = {
         if (tabName === 'trends' && !this.data.trends) {
             this.loadTrends();
         }
+
+        // Poll the run in progress only while the live tab is shown
+        clearInterval(this.liveTimer);
+        this.liveTimer = null;
+        if (tabName === 'live') {
+            this.loadLive();
+            this.liveTimer = setInterval(() => this.loadLive(), 2000);
+        }
+    },
+
+    async loadLive() {
+        try {
+            const response = await fetch('/api/live');
+            const live = await response.json();
+            this.updateLive(live);
+        } catch (error) {
+            console.error('Failed to load live run:', error);
+        }
+        this.loadJobs();
+    },
+
+    async loadJobs() {
+        try {
+            const response = await fetch('/api/jobs');
+            const data = await response.json();
+            document.getElementById('runNowBtn').style.display = data.enabled ? '' : 'none';
+            document.getElementById('runPackage').value = data.package || '(agent checkout)';
+            document.getElementById('runLabelsOption').style.display = data.agents ? '' : 'none';
+            this.updateJobs(data.jobs || []);
+        } catch (error) {
+            console.error('Failed to load jobs:', error);
+        }
+        this.loadAgents();
+    },
+
+    async loadAgents() {
+        try {
+            const response = await fetch('/api/agents');
+            this.updateAgents(await response.json());
+        } catch (error) {
+            console.error('Failed to load agents:', error);
+        }
+        this.loadDaemon();
+    },
+
+    async loadDaemon() {
+        try {
+            const response = await fetch('/api/daemon');
+            this.updateDaemon(await response.json());
+        } catch (error) {
+            console.error('Failed to load daemon status:', error);
+        }
+    },
+
+    async submitRun() {
+        const token = document.getElementById('runToken').value;
+        const errorEl = document.getElementById('runError');
+        errorEl.textContent = '';
+
+        try {
+            const response = await fetch('/api/jobs', {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json',
+                    'Authorization': 'Bearer ' + token
+                },
+                body: JSON.stringify({
+                    bench: document.getElementById('runBench').value,
+                    benchtime: document.getElementById('runBenchtime').value,
+                    labels: this.parseLabels(document.getElementById('runLabels').value)
+                })
+            });
+
+            if (!response.ok) {
+                errorEl.textContent = (await response.text()).trim();
+                return;
+            }
+
+            localStorage.setItem('gokanonToken', token);
+            document.getElementById('runModal').classList.remove('active');
+            this.switchTab('live');
+        } catch (error) {
+            errorEl.textContent = 'Failed to queue run: ' + error;
+        }
+    },
+
+    parseLabels(text) {
+        const labels = {};
+        text.split(',').forEach(pair => {
+            const index = pair.indexOf('=');
+            if (index > 0) {
+                labels[pair.substring(0, index).trim()] = pair.substring(index + 1).trim();
+            }
+        });
+        return labels;
+    },
+
+    // gradeBadge renders the confidence grade of a result or run, A to D
+    gradeBadge(grade) {
+        if (!grade) return '';
+        return '<span class="grade grade-' + grade + '" title="Confidence grade">' + grade + '</span>';
+    },
+
+    worseGrade(a, b) {
+        const grades = ['A', 'B', 'C', 'D'];
+        return grades.indexOf(a) > grades.indexOf(b) ? a : b;
+    },
+
+    gradeCounts(results) {
+        const counts = {};
+        results.forEach(result => {
+            if (result.confidence) counts[result.confidence] = (counts[result.confidence] || 0) + 1;
+        });
+        const grades = Object.keys(counts).sort();
+        if (grades.length === 0) return '';
+        return '<div><strong>Confidence:</strong> ' +
+            grades.map(grade => this.gradeBadge(grade) + ' ×' + counts[grade]).join(' ') + '</div>';
+    },
+
+    formatAnnotation(annotation) {
+        return (annotation.kind ? '[' + annotation.kind + '] ' : '') + annotation.message;
+    },
+
+    formatLabels(labels) {
+        return Object.keys(labels || {}).sort()
+            .map(key => key + '=' + labels[key]).join(', ');
+    },
+
+    updateJobs(jobs) {
+        const container = document.getElementById('jobQueue');
+
+        if (jobs.length === 0) {
+            container.innerHTML = '';
+            return;
+        }
+
+        let html = '<h2>Triggered Runs</h2><table><thead><tr>' +
+            '<th>Job</th>' +
+            '<th>Filter</th>' +
+            '<th>Benchtime</th>' +
+            '<th>Labels</th>' +
+            '<th>Queued</th>' +
+            '<th>Worker</th>' +
+            '<th>Status</th>' +
+            '</tr></thead><tbody>';
+
+        jobs.forEach(job => {
+            let status = job.status;
+            if (job.status === 'done') {
+                status = 'done (' + job.runId + ')';
+            } else if (job.status === 'failed') {
+                status = '<span class="delta-degraded">failed: ' + job.error + '</span>';
+            }
+            html += '<tr>' +
+                '<td>' + job.id + '</td>' +
+                '<td>' + job.request.bench + '</td>' +
+                '<td>' + (job.request.benchtime || 'default') + '</td>' +
+                '<td>' + (this.formatLabels(job.request.labels) || 'any') + '</td>' +
+                '<td>' + new Date(job.createdAt).toLocaleString(undefined, App.timeOptions) + '</td>' +
+                '<td>' + (job.worker || '-') + '</td>' +
+                '<td>' + status + '</td>' +
+                '</tr>';
+        });
+
+        html += '</tbody></table>';
+        container.innerHTML = html;
+    },
+
+    updateAgents(agents) {
+        const container = document.getElementById('agentList');
+
+        if (agents.length === 0) {
+            container.innerHTML = '';
+            return;
+        }
+
+        let html = '<h2>Agents</h2><table><thead><tr>' +
+            '<th>Agent</th>' +
+            '<th>Labels</th>' +
+            '<th>Registered</th>' +
+            '<th>Last Seen</th>' +
+            '</tr></thead><tbody>';
+
+        agents.forEach(agent => {
+            const labels = this.formatLabels(agent.labels);
+            html += '<tr>' +
+                '<td>' + agent.name + ' (' + agent.id + ')</td>' +
+                '<td>' + (labels || '-') + '</td>' +
+                '<td>' + new Date(agent.registeredAt).toLocaleString(undefined, App.timeOptions) + '</td>' +
+                '<td>' + new Date(agent.lastSeen).toLocaleString(undefined, App.timeOptions) + '</td>' +
+                '</tr>';
+        });
+
+        html += '</tbody></table>';
+        container.innerHTML = html;
+    },
+
+    updateDaemon(data) {
+        const container = document.getElementById('daemonStatus');
+
+        if (!data.configured) {
+            container.innerHTML = '';
+            return;
+        }
+
+        const daemon = data.status;
+        const state = daemon.running
+            ? 'running (pid ' + daemon.pid + ')'
+            : '<span class="delta-degraded">stopped</span>';
+        let html = '<h2>Scheduled Runs</h2><p>Daemon ' + state + ', updated ' +
+            new Date(daemon.updated_at).toLocaleString(undefined, App.timeOptions) + '</p>' +
+            '<table><thead><tr>' +
+            '<th>Schedule</th>' +
+            '<th>Cron</th>' +
+            '<th>Next</th>' +
+            '<th>Last Run</th>' +
+            '<th>Status</th>' +
+            '</tr></thead><tbody>';
+
+        (daemon.schedules || []).forEach(schedule => {
+            const formatTime = time => time && !time.startsWith('0001-')
+                ? new Date(time).toLocaleString(undefined, App.timeOptions) : '-';
+            let status = schedule.last_status || '-';
+            if (schedule.last_status === 'regressed') {
+                status = '<span class="delta-degraded">regressed (' + schedule.regressions + ')</span>';
+            } else if (schedule.last_status === 'failed') {
+                status = '<span class="delta-degraded">failed: ' + schedule.last_error + '</span>';
+            }
+            html += '<tr>' +
+                '<td>' + schedule.name + '</td>' +
+                '<td>' + schedule.cron + '</td>' +
+                '<td>' + (daemon.running ? formatTime(schedule.next) : '-') + '</td>' +
+                '<td>' + formatTime(schedule.last_run) + (schedule.last_run_id ? ' (' + schedule.last_run_id + ')' : '') + '</td>' +
+                '<td>' + status + '</td>' +
+                '</tr>';
+        });
+
+        html += '</tbody></table>';
+        container.innerHTML = html;
+    },
+
+    updateLive(live) {
+        const status = document.getElementById('liveStatus');
+        const results = document.getElementById('liveResults');
+
+        if (!live.running) {
+            status.innerHTML = '<p>No benchmark run in progress.</p>';
+            results.innerHTML = '';
+            return;
+        }
+
+        const run = live.run;
+        const completed = run.completed || [];
+        let html = '<div class="live-summary">' +
+            '<span><strong>Package:</strong> ' + this.escapeHtml(run.package) + '</span>' +
+            '<span><strong>PID:</strong> ' + run.pid + '</span>' +
+            '<span><strong>Elapsed:</strong> ' + this.formatSeconds(live.elapsedSeconds) + '</span>' +
+            '<span><strong>Completed:</strong> ' + completed.length +
+                (run.expected ? ' of ~' + run.expected : '') + '</span>' +
+            '<span><strong>Remaining:</strong> ' +
+                (live.remainingSeconds !== undefined ? '~' + this.formatSeconds(live.remainingSeconds) : 'unknown') + '</span>' +
+            '</div>';
+
+        if (run.current) {
+            const runningFor = (Date.now() - new Date(run.current_started_at).getTime()) / 1000;
+            html += '<div class="live-current">▶ Benchmark' + run.current +
+                ' <span class="delta-same">(' + this.formatSeconds(runningFor) + ')</span></div>';
+        }
+
+        if (run.expected) {
+            const percent = Math.min(100, completed.length / run.expected * 100);
+            html += '<div class="progress-bar"><div class="progress-fill" style="width: ' + percent.toFixed(1) + '%"></div></div>';
+        }
+        status.innerHTML = html;
+
+        if (completed.length === 0) {
+            results.innerHTML = '';
+            return;
+        }
+
+        let table = '<table><thead><tr>' +
+            '<th>Benchmark</th>' +
+            '<th>ns/op</th>' +
+            '<th>B/op</th>' +
+            '<th>allocs/op</th>' +
+            '<th>Status</th>' +
+            '</tr></thead><tbody>';
+
+        completed.forEach(result => {
+            const measured = !result.status || result.status === 'ok';
+            table += '<tr>' +
+                '<td>' + result.name + '</td>' +
+                '<td>' + (measured ? result.ns_per_op.toFixed(2) : '-') + '</td>' +
+                '<td>' + (measured ? (result.bytes_per_op || 0) : '-') + '</td>' +
+                '<td>' + (measured ? (result.allocs_per_op || 0) : '-') + '</td>' +
+                '<td>' + (measured ? 'ok' : result.status + (result.message ? ': ' + result.message : '')) + '</td>' +
+                '</tr>';
+        });
+
+        table += '</tbody></table>';
+        results.innerHTML = table;
+    },
+
+    formatSeconds(seconds) {
+        seconds = Math.max(0, Math.round(seconds));
+        if (seconds < 60) return seconds + 's';
+        const minutes = Math.floor(seconds / 60);
+        return minutes + 'm ' + (seconds % 60) + 's';
     },
 
     loadURLParams() {
         const params = new URLSearchParams(window.location.search);
         const runId = params.get('run');
         const tab = params.get('tab');
+        const compare = params.get('compare');
 
         if (tab) {
             this.switchTab(tab);
         }
 
+        // A comparison link, e.g. ?compare=runA..runB&metric=allocs
+        if (compare) {
+            this.switchTab('compare');
+            this.loadComparison(compare, params.get('metric'));
+        }
+
         if (runId) {
             setTimeout(() => this.viewRun(runId), 500);
         }
@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Auth is the authentication viewers of the dashboard must pass. Either
+// field may be empty; with both empty the dashboard is open.
+type Auth struct {
+	// Basic authentication credentials, as "user:password"
+	BasicAuth string
+
+	// Token accepted as a bearer token, or as the password of basic
+	// authentication with any user name so that browsers can log in with it
+	Token string
+}
+
+// enabled reports whether viewers must authenticate
+func (a Auth) enabled() bool {
+	return a.BasicAuth != "" || a.Token != ""
+}
+
+// SetAuth requires viewers to authenticate for every page and API call but
+// /api/health, which monitoring probes. Agents and clients triggering runs
+// are let in with the token they already present.
+func (s *Server) SetAuth(auth Auth) {
+	s.auth = auth
+}
+
+// SetReadOnly rejects every request that could change the storage or
+// start benchmarks, whatever the token, so the dashboard can be exposed on
+// shared infrastructure
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
+// protect wraps the dashboard handler with authentication and the
+// read-only mode
+func (s *Server) protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.auth.enabled() && r.URL.Path != "/api/health" && !s.authenticated(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="gokanon", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if s.readOnly && r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions {
+			http.Error(w, "The dashboard is read-only", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticated reports whether a request carries the viewer credentials,
+// or the token of runs and agents
+func (s *Server) authenticated(r *http.Request) bool {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return equalSecret(token, s.auth.Token) || s.authorized(r)
+	}
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	return equalSecret(user+":"+password, s.auth.BasicAuth) || equalSecret(password, s.auth.Token)
+}
+
+// equalSecret compares a presented secret with a configured one in
+// constant time. An unconfigured secret matches nothing.
+func equalSecret(presented, secret string) bool {
+	return secret != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(secret)) == 1
+}
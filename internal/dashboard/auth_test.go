@@ -0,0 +1,70 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+func TestAuth(t *testing.T) {
+	server := NewServer(storage.NewStorage(t.TempDir()), "localhost", 8080)
+	server.EnableAgents("agent-token")
+	server.SetAuth(Auth{BasicAuth: "team:s3cret", Token: "view-token"})
+	handler := server.Handler()
+
+	tests := []struct {
+		name   string
+		path   string
+		setup  func(r *http.Request)
+		status int
+	}{
+		{"no credentials", "/api/runs", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"basic auth", "/api/runs", func(r *http.Request) { r.SetBasicAuth("team", "s3cret") }, http.StatusOK},
+		{"wrong password", "/api/runs", func(r *http.Request) { r.SetBasicAuth("team", "guess") }, http.StatusUnauthorized},
+		{"token as password", "/", func(r *http.Request) { r.SetBasicAuth("anyone", "view-token") }, http.StatusOK},
+		{"bearer token", "/api/runs", func(r *http.Request) { r.Header.Set("Authorization", "Bearer view-token") }, http.StatusOK},
+		{"agent token", "/api/jobs", func(r *http.Request) { r.Header.Set("Authorization", "Bearer agent-token") }, http.StatusOK},
+		{"wrong token", "/api/runs", func(r *http.Request) { r.Header.Set("Authorization", "Bearer guess") }, http.StatusUnauthorized},
+		{"health probe", "/api/health", func(r *http.Request) {}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			tt.setup(req)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("status code = %v, want %v", w.Code, tt.status)
+			}
+			if w.Code == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Basic ") {
+				t.Errorf("Expected a basic auth challenge, got %q", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	server := NewServer(storage.NewStorage(t.TempDir()), "localhost", 8080)
+	server.EnableAgents("agent-token")
+	server.SetReadOnly(true)
+	handler := server.Handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"bench":"."}`))
+	req.Header.Set("Authorization", "Bearer agent-token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("POST status code = %v, want %v", w.Code, http.StatusForbidden)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/runs", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("GET status code = %v, want %v", w.Code, http.StatusOK)
+	}
+}
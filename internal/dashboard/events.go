@@ -0,0 +1,157 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Types of run events
+const (
+	RunAdded   = "added"
+	RunDeleted = "deleted"
+)
+
+// RunEvent notifies dashboard clients that a run was saved or deleted
+type RunEvent struct {
+	Type string `json:"type"` // RunAdded or RunDeleted
+	ID   string `json:"id"`
+}
+
+// eventsKeepAlive is how often an idle event stream sends a comment, so
+// that proxies do not close it
+const eventsKeepAlive = 30 * time.Second
+
+// runEvents broadcasts the runs saved to or deleted from the storage,
+// whether by 'gokanon run', a dashboard job or an agent. Other processes
+// write to the storage, so it is polled for changes while clients are
+// subscribed.
+type runEvents struct {
+	store    *storage.Storage
+	interval time.Duration
+
+	mu      sync.Mutex
+	clients map[chan RunEvent]struct{}
+	stop    chan struct{}
+}
+
+// newRunEvents creates a broadcaster polling store every interval
+func newRunEvents(store *storage.Storage, interval time.Duration) *runEvents {
+	return &runEvents{store: store, interval: interval, clients: make(map[chan RunEvent]struct{})}
+}
+
+// subscribe returns a channel receiving the events until unsubscribe is
+// called. Polling starts with the first subscriber.
+func (e *runEvents) subscribe() chan RunEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan RunEvent, 16)
+	e.clients[ch] = struct{}{}
+	if e.stop == nil {
+		e.stop = make(chan struct{})
+		known, _ := e.store.RunIDs()
+		go e.watch(known, e.stop)
+	}
+	return ch
+}
+
+// unsubscribe stops sending events to ch. Polling stops with the last
+// subscriber.
+func (e *runEvents) unsubscribe(ch chan RunEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.clients, ch)
+	if len(e.clients) == 0 && e.stop != nil {
+		close(e.stop)
+		e.stop = nil
+	}
+}
+
+// watch polls the storage for runs that are not in known, or no longer
+// there, until stop is closed
+func (e *runEvents) watch(known []string, stop chan struct{}) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ids, err := e.store.RunIDs()
+		if err != nil {
+			continue
+		}
+		for _, id := range ids {
+			if _, found := slices.BinarySearch(known, id); !found {
+				e.broadcast(RunEvent{Type: RunAdded, ID: id})
+			}
+		}
+		for _, id := range known {
+			if _, found := slices.BinarySearch(ids, id); !found {
+				e.broadcast(RunEvent{Type: RunDeleted, ID: id})
+			}
+		}
+		known = ids
+	}
+}
+
+// broadcast sends an event to every subscriber. A client too slow to keep
+// up misses it rather than holding up the others.
+func (e *runEvents) broadcast(event RunEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.clients {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// handleEvents streams run events to the browser as server-sent events,
+// so that the dashboard updates when a run finishes
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events := s.events.subscribe()
+	defer s.events.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// Browsers reconnect after this many milliseconds when the stream drops
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event := <-events:
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: run\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
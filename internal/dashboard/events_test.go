@@ -0,0 +1,104 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// readEvent returns the data of the next run event in an event stream
+func readEvent(t *testing.T, reader *bufio.Reader) RunEvent {
+	t.Helper()
+	isRun := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event stream: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "event: run" {
+			isRun = true
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok && isRun {
+			var event RunEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("Invalid event data %q: %v", data, err)
+			}
+			return event
+		}
+	}
+}
+
+func TestEventsStream(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	existing := &models.BenchmarkRun{ID: "run-1", Timestamp: time.Now()}
+	if err := store.Save(existing); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	server := NewServer(store, "localhost", 8080)
+	server.events = newRunEvents(store, 10*time.Millisecond)
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %s, want text/event-stream", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	// Runs saved before subscribing are not reported, new ones are
+	if err := store.Save(&models.BenchmarkRun{ID: "run-2", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if event := readEvent(t, reader); event != (RunEvent{Type: RunAdded, ID: "run-2"}) {
+		t.Errorf("Expected run-2 to be added, got %+v", event)
+	}
+
+	if err := store.Delete("run-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if event := readEvent(t, reader); event != (RunEvent{Type: RunDeleted, ID: "run-1"}) {
+		t.Errorf("Expected run-1 to be deleted, got %+v", event)
+	}
+
+	// Polling stops when the last client disconnects
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		server.events.mu.Lock()
+		stopped := server.events.stop == nil
+		server.events.mu.Unlock()
+		if stopped {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected polling to stop after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEventsMethodNotAllowed(t *testing.T) {
+	server := NewServer(storage.NewStorage(t.TempDir()), "localhost", 8080)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/api/events", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", w.Code)
+	}
+}
@@ -17,6 +17,7 @@ const indexHTML = `<!DOCTYPE html>
             <div class="header-content">
                 <h1>📊 GoKanon Dashboard</h1>
                 <div class="header-controls">
+                    <select id="snapshotSelect" class="form-select" title="Browse release snapshots" style="display: none;"></select>
                     <button id="darkModeToggle" class="btn btn-icon" title="Toggle dark mode">
                         <span class="icon-sun">☀️</span>
                         <span class="icon-moon">🌙</span>
@@ -24,6 +25,9 @@ const indexHTML = `<!DOCTYPE html>
                     <button id="refreshBtn" class="btn btn-primary" title="Refresh data">
                         🔄 Refresh
                     </button>
+                    <button id="runNowBtn" class="btn btn-primary" title="Trigger a benchmark run" style="display: none;">
+                        ▶ Run now
+                    </button>
                 </div>
             </div>
         </header>
@@ -34,10 +38,24 @@ const indexHTML = `<!DOCTYPE html>
             <a href="/" target="_blank">Open Full Dashboard</a>
         </div>
 
+        <!-- Snapshot Notice -->
+        <div id="snapshotNotice" class="snapshot-notice" style="display: none;">
+            <span id="snapshotTitle"></span>
+            <a href="/">Back to Live Dashboard</a>
+        </div>
+
         <!-- Main Content -->
         <main class="main-content">
             <!-- Stats Overview -->
             <section class="stats-section">
+                <div class="date-range-picker">
+                    <label for="statsFrom">From</label>
+                    <input type="date" id="statsFrom" />
+                    <label for="statsTo">To</label>
+                    <input type="date" id="statsTo" />
+                    <button id="applyDateRangeBtn" class="btn btn-primary">Apply</button>
+                    <button id="clearDateRangeBtn" class="btn btn-secondary">Clear</button>
+                </div>
                 <div class="stats-grid">
                     <div class="stat-card">
                         <div class="stat-icon">🏃</div>
@@ -67,13 +85,26 @@ const indexHTML = `<!DOCTYPE html>
                             <div class="stat-label">Date Range</div>
                         </div>
                     </div>
+                    <div class="stat-card">
+                        <div class="stat-icon">🏆</div>
+                        <div class="stat-content">
+                            <div class="stat-value" id="scoreValue">-</div>
+                            <div class="stat-label">Performance Score <span id="scoreChange"></span></div>
+                        </div>
+                    </div>
                 </div>
             </section>
 
             <!-- Search and Filter -->
             <section class="search-section">
                 <div class="search-bar">
-                    <input type="text" id="searchInput" placeholder="Search benchmarks, packages, or run IDs..." />
+                    <input type="text" id="searchInput" placeholder="Search benchmarks, packages, tags, notes or commits, e.g. package:payments String" />
+                    <select id="searchSince" class="form-select" title="Only search runs this recent">
+                        <option value="">Any time</option>
+                        <option value="7d">Last 7 days</option>
+                        <option value="30d">Last 30 days</option>
+                        <option value="90d">Last 90 days</option>
+                    </select>
                     <button id="searchBtn" class="btn btn-primary">🔍 Search</button>
                 </div>
                 <div id="searchResults" class="search-results"></div>
@@ -86,6 +117,7 @@ const indexHTML = `<!DOCTYPE html>
                     <button class="tab-btn" data-tab="trends">Trends</button>
                     <button class="tab-btn" data-tab="history">History</button>
                     <button class="tab-btn" data-tab="compare">Compare</button>
+                    <button class="tab-btn" data-tab="live">Live</button>
                 </div>
 
                 <!-- Tab Content -->
@@ -96,6 +128,17 @@ const indexHTML = `<!DOCTYPE html>
                             <h2>Recent Benchmark Performance</h2>
                             <canvas id="overviewChart"></canvas>
                         </div>
+                        <div class="chart-container">
+                            <h2>Skip Rate</h2>
+                            <canvas id="skipRateChart"></canvas>
+                        </div>
+                        <div id="sloPanel" class="slo-panel"></div>
+                        <div id="stressPanel" class="chart-container" style="display: none;">
+                            <h2>Concurrency Stress</h2>
+                            <p id="stressInfo" class="stress-info"></p>
+                            <canvas id="stressChart"></canvas>
+                            <div id="stressTable" class="table-container"></div>
+                        </div>
                         <div class="recent-runs">
                             <h2>Recent Runs</h2>
                             <div id="recentRunsList"></div>
@@ -116,6 +159,10 @@ const indexHTML = `<!DOCTYPE html>
                                 <option value="50" selected>50 runs</option>
                                 <option value="100">100 runs</option>
                             </select>
+                            <label for="metricSelect">Metric:</label>
+                            <select id="metricSelect" class="form-select">
+                                <option value="ns/op">ns/op</option>
+                            </select>
                             <button id="loadTrendsBtn" class="btn btn-primary">Load Trends</button>
                         </div>
                         <div class="chart-container">
@@ -144,13 +191,113 @@ const indexHTML = `<!DOCTYPE html>
                                 <label for="compareRun2">Compare With:</label>
                                 <select id="compareRun2" class="form-select"></select>
                             </div>
+                            <div class="compare-select-group">
+                                <label for="compareMetric">Metric:</label>
+                                <select id="compareMetric" class="form-select">
+                                    <option value="ns">Time (ns/op)</option>
+                                    <option value="bytes">Memory (B/op)</option>
+                                    <option value="allocs">Allocations (allocs/op)</option>
+                                </select>
+                            </div>
                             <button id="compareBtn" class="btn btn-primary">Compare</button>
+                            <button id="compareLinkBtn" class="btn btn-secondary" title="Copy a link to this comparison">🔗 Copy link</button>
                         </div>
                         <div id="compareResults" class="compare-results"></div>
+                        <div id="scalingPanel" class="chart-container" style="display: none;">
+                            <h2>CPU Scaling</h2>
+                            <div class="compare-select-group">
+                                <label for="scalingBenchmark">Benchmark:</label>
+                                <select id="scalingBenchmark" class="form-select"></select>
+                            </div>
+                            <canvas id="scalingChart"></canvas>
+                            <div id="scalingTable" class="table-container"></div>
+                        </div>
+                    </div>
+
+                    <!-- Live Tab -->
+                    <div id="live" class="tab-pane">
+                        <div id="liveStatus" class="live-status">
+                            <p>No benchmark run in progress.</p>
+                        </div>
+                        <div id="liveResults" class="table-container"></div>
+                        <div id="jobQueue" class="job-queue"></div>
+                        <div id="agentList" class="job-queue"></div>
+                        <div id="daemonStatus" class="job-queue"></div>
                     </div>
                 </div>
             </section>
 
+            <!-- Run Modal -->
+            <div id="runModal" class="modal">
+                <div class="modal-content">
+                    <div class="modal-header">
+                        <h2>Run Benchmarks</h2>
+                        <button class="modal-close" data-modal="runModal">&times;</button>
+                    </div>
+                    <div class="modal-body">
+                        <div class="run-option">
+                            <label for="runPackage">Package:</label>
+                            <input type="text" id="runPackage" readonly />
+                        </div>
+                        <div class="run-option">
+                            <label for="runBench">Benchmark Filter:</label>
+                            <input type="text" id="runBench" value="." />
+                        </div>
+                        <div class="run-option">
+                            <label for="runBenchtime">Benchtime:</label>
+                            <input type="text" id="runBenchtime" placeholder="default (e.g. 1s, 100x)" />
+                        </div>
+                        <div class="run-option" id="runLabelsOption" style="display: none;">
+                            <label for="runLabels">Agent Labels:</label>
+                            <input type="text" id="runLabels" placeholder="any agent (e.g. cpu=epyc,os=linux)" />
+                        </div>
+                        <div class="run-option">
+                            <label for="runToken">Access Token:</label>
+                            <input type="password" id="runToken" />
+                        </div>
+                        <p id="runError" class="delta-degraded"></p>
+                        <button id="runSubmitBtn" class="btn btn-primary">Queue Run</button>
+                    </div>
+                </div>
+            </div>
+
+            <!-- Run Details Modal -->
+            <div id="runDetailModal" class="modal">
+                <div class="modal-content">
+                    <div class="modal-header">
+                        <h2>Run <span id="runDetailId"></span></h2>
+                        <button class="modal-close" data-modal="runDetailModal">&times;</button>
+                    </div>
+                    <div class="modal-body">
+                        <div id="runDetailInfo" class="run-detail-info"></div>
+                        <div id="runManage" style="display: none;">
+                            <div class="run-option">
+                                <label for="runNote">Note:</label>
+                                <input type="text" id="runNote" placeholder="e.g. before the allocator rewrite" />
+                            </div>
+                            <div class="run-option">
+                                <label for="runTags">Tags:</label>
+                                <input type="text" id="runTags" placeholder="e.g. branch=main,release=v2" />
+                            </div>
+                            <div class="run-option">
+                                <label for="runBaselineName">Save as Baseline:</label>
+                                <input type="text" id="runBaselineName" placeholder="baseline name (e.g. v1.0)" />
+                            </div>
+                            <div class="run-option">
+                                <label for="manageToken">Access Token:</label>
+                                <input type="password" id="manageToken" placeholder="not needed when logged in" />
+                            </div>
+                            <p id="manageError" class="delta-degraded"></p>
+                            <div class="run-actions">
+                                <button id="runSaveBtn" class="btn btn-primary">Save Note &amp; Tags</button>
+                                <button id="runBaselineBtn" class="btn btn-secondary">Save as Baseline</button>
+                                <button id="runDeleteBtn" class="btn btn-danger">Delete Run</button>
+                            </div>
+                        </div>
+                    </div>
+                </div>
+            </div>
+
             <!-- Share Modal -->
             <div id="shareModal" class="modal">
                 <div class="modal-content">
@@ -298,6 +445,11 @@ body {
     color: white;
 }
 
+.btn-danger {
+    background-color: var(--danger-color);
+    color: white;
+}
+
 .btn-icon {
     padding: 0.5rem;
     font-size: 1.2rem;
@@ -323,6 +475,21 @@ body {
     margin-left: 1rem;
 }
 
+/* Snapshot Notice */
+.snapshot-notice {
+    background-color: var(--accent-color);
+    color: #fff;
+    padding: 0.5rem 2rem;
+    text-align: center;
+    font-size: 0.9rem;
+}
+
+.snapshot-notice a {
+    color: #fff;
+    font-weight: 600;
+    margin-left: 1rem;
+}
+
 /* Main Content */
 .main-content {
     flex: 1;
@@ -379,6 +546,27 @@ body {
     color: var(--text-secondary);
 }
 
+.date-range-picker {
+    display: flex;
+    align-items: center;
+    gap: 0.5rem;
+    margin-bottom: 1rem;
+    flex-wrap: wrap;
+}
+
+.date-range-picker label {
+    font-size: 0.9rem;
+    color: var(--text-secondary);
+}
+
+.date-range-picker input {
+    padding: 0.5rem;
+    border: 1px solid var(--border-color);
+    border-radius: 6px;
+    background-color: var(--bg-card);
+    color: var(--text-primary);
+}
+
 /* Search Section */
 .search-section {
     margin-bottom: 2rem;
@@ -643,6 +831,61 @@ tr:hover {
     color: var(--text-secondary);
 }
 
+.suggestion-item {
+    background-color: var(--bg-secondary);
+    padding: 1rem;
+    border-radius: 6px;
+    margin-bottom: 0.5rem;
+    border-left: 4px solid var(--text-secondary);
+}
+
+.suggestion-item.severity-high {
+    border-left-color: var(--danger-color);
+}
+
+.suggestion-item.severity-medium {
+    border-left-color: var(--warning-color);
+}
+
+.suggestion-evidence {
+    color: var(--text-secondary);
+    font-size: 0.875rem;
+    margin-top: 0.5rem;
+}
+
+/* Live run */
+.live-status {
+    margin-bottom: 2rem;
+}
+
+.live-summary {
+    display: flex;
+    gap: 2rem;
+    flex-wrap: wrap;
+    margin-bottom: 1rem;
+}
+
+.live-current {
+    background-color: var(--bg-secondary);
+    padding: 1rem;
+    border-radius: 6px;
+    margin-bottom: 1rem;
+    font-family: monospace;
+}
+
+.progress-bar {
+    height: 8px;
+    background-color: var(--bg-secondary);
+    border-radius: 4px;
+    overflow: hidden;
+}
+
+.progress-fill {
+    height: 100%;
+    background-color: var(--accent-color);
+    transition: width 0.3s ease;
+}
+
 /* Modal */
 .modal {
     display: none;
@@ -693,6 +936,99 @@ tr:hover {
     margin-bottom: 1.5rem;
 }
 
+.run-option {
+    margin-bottom: 1rem;
+}
+
+.run-option label {
+    display: block;
+    margin-bottom: 0.5rem;
+    font-weight: 500;
+}
+
+.run-option input {
+    width: 100%;
+    padding: 0.5rem;
+    border: 1px solid var(--border-color);
+    border-radius: 6px;
+    background-color: var(--bg-secondary);
+    color: var(--text-primary);
+    font-family: monospace;
+}
+
+.run-detail-info {
+    margin-bottom: 1.5rem;
+    line-height: 1.6;
+}
+
+.run-actions {
+    display: flex;
+    flex-wrap: wrap;
+    gap: 0.5rem;
+}
+
+.grade {
+    display: inline-block;
+    padding: 0 0.35rem;
+    border-radius: 4px;
+    font-size: 0.75rem;
+    font-weight: 700;
+}
+
+.grade-A { background-color: #d3f9d8; color: #2b8a3e; }
+.grade-B { background-color: #d0ebff; color: #1864ab; }
+.grade-C { background-color: #fff3bf; color: #e67700; }
+.grade-D { background-color: #ffe3e3; color: #c92a2a; }
+
+.run-tag {
+    display: inline-block;
+    margin-right: 0.25rem;
+    padding: 0 0.4rem;
+    border-radius: 4px;
+    background-color: var(--bg-secondary);
+    font-size: 0.8rem;
+}
+
+.job-queue {
+    margin-top: 2rem;
+}
+
+.job-queue h2 {
+    margin-bottom: 1rem;
+    font-size: 1.5rem;
+}
+
+/* SLO Panel */
+.slo-panel {
+    margin-bottom: 2rem;
+}
+
+.stress-info {
+    color: var(--text-secondary);
+    margin-bottom: 1rem;
+}
+
+.slo-panel h2 {
+    margin-bottom: 1rem;
+    font-size: 1.5rem;
+}
+
+.slo-history {
+    display: flex;
+    gap: 2px;
+}
+
+.slo-history span {
+    width: 8px;
+    height: 16px;
+    border-radius: 2px;
+    background-color: var(--success-color);
+}
+
+.slo-history span.breached {
+    background-color: var(--danger-color);
+}
+
 .share-option label {
     display: block;
     margin-bottom: 0.5rem;
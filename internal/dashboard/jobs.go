@@ -0,0 +1,303 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/runner"
+)
+
+// Job statuses
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+const (
+	// maxPendingJobs bounds the number of runs waiting in the queue
+	maxPendingJobs = 16
+
+	// maxJobHistory is the number of finished jobs kept for display
+	maxJobHistory = 50
+)
+
+// ErrQueueFull is returned when too many runs are already waiting
+var ErrQueueFull = errors.New("run queue is full")
+
+// benchtimePattern matches the values accepted by go test -benchtime
+var benchtimePattern = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h)|[0-9]+x)$`)
+
+// JobRequest holds the options of a run triggered from the dashboard
+type JobRequest struct {
+	Bench     string            `json:"bench"`               // Benchmark filter passed to -bench
+	Benchtime string            `json:"benchtime,omitempty"` // Optional -benchtime value
+	Labels    map[string]string `json:"labels,omitempty"`    // Only agents with all these labels may run the job
+}
+
+// Matches reports whether a worker with the given labels may run the job
+func (r JobRequest) Matches(labels map[string]string) bool {
+	for key, value := range r.Labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks the request before it is queued
+func (r *JobRequest) Validate() error {
+	if r.Bench == "" {
+		r.Bench = "."
+	}
+	if _, err := regexp.Compile(r.Bench); err != nil {
+		return fmt.Errorf("invalid benchmark filter: %w", err)
+	}
+	if r.Benchtime != "" && !benchtimePattern.MatchString(r.Benchtime) {
+		return fmt.Errorf("invalid benchtime %q (e.g. 1s, 500ms, 100x)", r.Benchtime)
+	}
+	for key, value := range r.Labels {
+		if key == "" || value == "" {
+			return fmt.Errorf("invalid label %q=%q", key, value)
+		}
+	}
+	return nil
+}
+
+// Job is a benchmark run triggered from the dashboard
+type Job struct {
+	ID         string     `json:"id"`
+	Request    JobRequest `json:"request"`
+	Status     string     `json:"status"`
+	Worker     string     `json:"worker,omitempty"` // "local" or the name of the agent running the job
+	RunID      string     `json:"runId,omitempty"`  // Saved run, once done
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  time.Time  `json:"startedAt,omitempty"`
+	FinishedAt time.Time  `json:"finishedAt,omitempty"`
+}
+
+// LocalWorker is the worker name of jobs run by the dashboard process itself
+const LocalWorker = "local"
+
+// RunFunc executes a job and returns the saved run
+type RunFunc func(req JobRequest) (*models.BenchmarkRun, error)
+
+// JobQueue holds triggered benchmark runs. Jobs are claimed in order by the
+// local worker, if any, and by remote agents.
+type JobQueue struct {
+	mu     sync.Mutex
+	queued *sync.Cond // Signalled when a job is submitted
+	jobs   []*Job     // Oldest first
+	nextID int
+}
+
+// NewJobQueue creates a queue. If run is not nil, jobs are also executed
+// with it by a background worker, one at a time.
+func NewJobQueue(run RunFunc) *JobQueue {
+	q := &JobQueue{}
+	q.queued = sync.NewCond(&q.mu)
+	if run != nil {
+		go q.work(run)
+	}
+	return q
+}
+
+// Submit validates and queues a run
+func (q *JobQueue) Submit(req JobRequest) (Job, error) {
+	if err := req.Validate(); err != nil {
+		return Job{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending := 0
+	for _, job := range q.jobs {
+		if job.Status == JobQueued {
+			pending++
+		}
+	}
+	if pending >= maxPendingJobs {
+		return Job{}, ErrQueueFull
+	}
+
+	q.nextID++
+	job := &Job{
+		ID:        fmt.Sprintf("job-%d", q.nextID),
+		Request:   req,
+		Status:    JobQueued,
+		CreatedAt: time.Now(),
+	}
+	q.jobs = append(q.jobs, job)
+	q.prune()
+	q.queued.Broadcast()
+	return *job, nil
+}
+
+// Jobs returns a snapshot of the known jobs, newest first
+func (q *JobQueue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, 0, len(q.jobs))
+	for i := len(q.jobs) - 1; i >= 0; i-- {
+		jobs = append(jobs, *q.jobs[i])
+	}
+	return jobs
+}
+
+// Job returns the job with the given ID
+func (q *JobQueue) Job(id string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return *job, true
+		}
+	}
+	return Job{}, false
+}
+
+// Claim marks the oldest queued job that a worker with the given labels may
+// run as running on worker and returns it. It returns false if no such job
+// is waiting.
+func (q *JobQueue) Claim(worker string, labels map[string]string) (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job := q.next(labels)
+	if job == nil {
+		return Job{}, false
+	}
+	q.start(job, worker)
+	return *job, true
+}
+
+// Finish records the outcome of a running job
+func (q *JobQueue) Finish(id string, run *models.BenchmarkRun, err error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID != id {
+			continue
+		}
+		if job.Status != JobRunning {
+			return fmt.Errorf("job %s is not running", id)
+		}
+		job.FinishedAt = time.Now()
+		if err != nil {
+			job.Status = JobFailed
+			job.Error = err.Error()
+			return nil
+		}
+		job.Status = JobDone
+		job.RunID = run.ID
+		return nil
+	}
+	return fmt.Errorf("job %s not found", id)
+}
+
+// RunningOn reports whether a job is running on worker
+func (q *JobQueue) RunningOn(id, worker string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return job.Status == JobRunning && job.Worker == worker
+		}
+	}
+	return false
+}
+
+// Abandon fails the running jobs of a worker that went away
+func (q *JobQueue) Abandon(worker string, reason error) {
+	q.mu.Lock()
+	var running []string
+	for _, job := range q.jobs {
+		if job.Status == JobRunning && job.Worker == worker {
+			running = append(running, job.ID)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, id := range running {
+		q.Finish(id, nil, reason)
+	}
+}
+
+// work executes queued jobs without labels locally until the process exits
+func (q *JobQueue) work(run RunFunc) {
+	for {
+		q.mu.Lock()
+		job := q.next(nil)
+		for job == nil {
+			q.queued.Wait()
+			job = q.next(nil)
+		}
+		q.start(job, LocalWorker)
+		id, req := job.ID, job.Request
+		q.mu.Unlock()
+
+		result, err := run(req)
+		q.Finish(id, result, err)
+	}
+}
+
+// next returns the oldest queued job a worker with the given labels may run,
+// or nil. The caller must hold the lock.
+func (q *JobQueue) next(labels map[string]string) *Job {
+	for _, job := range q.jobs {
+		if job.Status == JobQueued && job.Request.Matches(labels) {
+			return job
+		}
+	}
+	return nil
+}
+
+// start marks a job as running on worker. The caller must hold the lock.
+func (q *JobQueue) start(job *Job, worker string) {
+	job.Status = JobRunning
+	job.Worker = worker
+	job.StartedAt = time.Now()
+}
+
+// prune drops the oldest finished jobs beyond the history limit.
+// The caller must hold the lock.
+func (q *JobQueue) prune() {
+	for len(q.jobs) > maxJobHistory && (q.jobs[0].Status == JobDone || q.jobs[0].Status == JobFailed) {
+		q.jobs = q.jobs[1:]
+	}
+}
+
+// runJob executes a triggered run of the configured package and saves it.
+// It waits for runs started from the command line to finish first.
+func (s *Server) runJob(req JobRequest) (*models.BenchmarkRun, error) {
+	lock, err := s.storage.AcquireRunLock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	r := runner.NewRunner(s.runPackage, req.Bench).WithLiveStatus(s.storage)
+	if req.Benchtime != "" {
+		r = r.WithBenchtime(req.Benchtime)
+	}
+
+	run, err := r.Run()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Save(run); err != nil {
+		return nil, fmt.Errorf("failed to save results: %w", err)
+	}
+	return run, nil
+}
@@ -0,0 +1,188 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// waitForJobs polls the queue until every job has finished
+func waitForJobs(t *testing.T, q *JobQueue) []Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		jobs := q.Jobs()
+		finished := true
+		for _, job := range jobs {
+			if job.Status == JobQueued || job.Status == JobRunning {
+				finished = false
+			}
+		}
+		if finished {
+			return jobs
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("jobs did not finish in time")
+	return nil
+}
+
+func TestJobRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     JobRequest
+		wantErr bool
+	}{
+		{"defaults", JobRequest{}, false},
+		{"filter and benchtime", JobRequest{Bench: "^BenchmarkSum$", Benchtime: "500ms"}, false},
+		{"iterations", JobRequest{Benchtime: "100x"}, false},
+		{"bad filter", JobRequest{Bench: "(["}, true},
+		{"bad benchtime", JobRequest{Benchtime: "fast"}, true},
+		{"flag injection", JobRequest{Benchtime: "1s -exec=evil"}, true},
+		{"labels", JobRequest{Labels: map[string]string{"cpu": "epyc"}}, false},
+		{"empty label value", JobRequest{Labels: map[string]string{"cpu": ""}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJobRequestMatches(t *testing.T) {
+	req := JobRequest{Labels: map[string]string{"cpu": "epyc", "os": "linux"}}
+
+	tests := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{map[string]string{"cpu": "epyc", "os": "linux"}, true},
+		{map[string]string{"cpu": "epyc", "os": "linux", "arch": "amd64"}, true},
+		{map[string]string{"cpu": "epyc"}, false},
+		{map[string]string{"cpu": "xeon", "os": "linux"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := req.Matches(tt.labels); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.labels, got, tt.want)
+		}
+	}
+
+	if !(JobRequest{}).Matches(nil) {
+		t.Error("a job without labels should match any worker")
+	}
+}
+
+func TestJobQueue(t *testing.T) {
+	var order []string
+	q := NewJobQueue(func(req JobRequest) (*models.BenchmarkRun, error) {
+		order = append(order, req.Bench)
+		if req.Bench == "Fail" {
+			return nil, errors.New("build failed")
+		}
+		return &models.BenchmarkRun{ID: "run-" + req.Bench}, nil
+	})
+
+	for _, bench := range []string{"A", "Fail", "B"} {
+		if _, err := q.Submit(JobRequest{Bench: bench}); err != nil {
+			t.Fatalf("Submit(%s) failed: %v", bench, err)
+		}
+	}
+
+	jobs := waitForJobs(t, q)
+	if strings.Join(order, ",") != "A,Fail,B" {
+		t.Errorf("jobs ran in order %v, want A,Fail,B", order)
+	}
+	if len(jobs) != 3 {
+		t.Fatalf("got %d jobs, want 3", len(jobs))
+	}
+
+	// Newest first
+	if jobs[0].Status != JobDone || jobs[0].RunID != "run-B" {
+		t.Errorf("unexpected job B: %+v", jobs[0])
+	}
+	if jobs[1].Status != JobFailed || jobs[1].Error != "build failed" {
+		t.Errorf("unexpected job Fail: %+v", jobs[1])
+	}
+	if jobs[2].StartedAt.IsZero() || jobs[2].FinishedAt.IsZero() {
+		t.Errorf("expected timestamps on job A: %+v", jobs[2])
+	}
+}
+
+func TestHandleJobs(t *testing.T) {
+	server := NewServer(storage.NewStorage(t.TempDir()), "localhost", 8080)
+
+	post := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.handleJobs(w, req)
+		return w
+	}
+
+	if w := post("secret", `{"bench": "."}`); w.Code != http.StatusNotFound {
+		t.Errorf("status = %v, want %v while runs are disabled", w.Code, http.StatusNotFound)
+	}
+
+	// Enable runs without starting real benchmarks
+	server.runPackage = "./examples"
+	server.token = "secret"
+	server.jobs = NewJobQueue(func(req JobRequest) (*models.BenchmarkRun, error) {
+		return &models.BenchmarkRun{ID: "run-1"}, nil
+	})
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{"missing token", "", `{"bench": "."}`, http.StatusUnauthorized},
+		{"wrong token", "guess", `{"bench": "."}`, http.StatusUnauthorized},
+		{"invalid json", "secret", `{`, http.StatusBadRequest},
+		{"invalid benchtime", "secret", `{"benchtime": "soon"}`, http.StatusBadRequest},
+		{"labels without agents", "secret", `{"labels": {"cpu": "epyc"}}`, http.StatusBadRequest},
+		{"accepted", "secret", `{"bench": "Sum", "benchtime": "10x"}`, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := post(tt.token, tt.body); w.Code != tt.want {
+				t.Errorf("status = %v, want %v: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	waitForJobs(t, server.jobs)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs", nil)
+	w := httptest.NewRecorder()
+	server.handleJobs(w, req)
+
+	var response struct {
+		Enabled bool   `json:"enabled"`
+		Package string `json:"package"`
+		Jobs    []Job  `json:"jobs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.Enabled || response.Package != "./examples" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if len(response.Jobs) != 1 || response.Jobs[0].Status != JobDone || response.Jobs[0].Request.Bench != "Sum" {
+		t.Errorf("unexpected jobs: %+v", response.Jobs)
+	}
+}
@@ -0,0 +1,194 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// RunUpdate edits the metadata of a run. Fields left out are unchanged;
+// tags replace the run's tags, an empty object removing them all.
+type RunUpdate struct {
+	Note *string           `json:"note"`
+	Tags map[string]string `json:"tags"`
+}
+
+// BaselineRequest saves a run as a baseline
+type BaselineRequest struct {
+	Name        string `json:"name"`
+	Run         string `json:"run"` // Run ID or reference such as latest
+	Description string `json:"description"`
+	Replace     bool   `json:"replace"` // Replace an existing baseline of that name
+}
+
+// manageable reports whether runs and baselines can be managed from the
+// dashboard. It takes authentication, so that a dashboard anyone can reach
+// cannot be emptied.
+func (s *Server) manageable() bool {
+	return !s.readOnly && (s.auth.enabled() || s.token != "")
+}
+
+// mayManage checks that a request may change runs and baselines, writing
+// the error response when it may not. Viewers let in by protect may;
+// without viewer authentication, the token of runs and agents is required.
+// Browsers resend basic credentials to any site, so requests from other
+// sites are refused, and so are bodies other than JSON, which another site
+// could post with a form.
+func (s *Server) mayManage(w http.ResponseWriter, r *http.Request) bool {
+	if !s.manageable() {
+		http.Error(w, "Managing runs needs authentication (start the dashboard with -auth-token, -basic-auth, -run-pkg or -agents)", http.StatusForbidden)
+		return false
+	}
+	if !s.auth.enabled() && !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-site requests cannot manage runs", http.StatusForbidden)
+		return false
+	}
+	if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return false
+		}
+	}
+	return true
+}
+
+// sameOrigin reports whether a request comes from the dashboard itself, or
+// from a client other than a browser, which sends neither Sec-Fetch-Site
+// nor Origin
+func sameOrigin(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin" || site == "none"
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+	return true
+}
+
+// deleteRun deletes a run with its profiles
+func (s *Server) deleteRun(w http.ResponseWriter, run *models.BenchmarkRun) {
+	if err := s.storage.Delete(run.ID); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete run: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// updateRun edits the note and tags of a run and returns it
+func (s *Server) updateRun(w http.ResponseWriter, r *http.Request, run *models.BenchmarkRun) {
+	var update RunUpdate
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&update); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := validateTags(update.Tags); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if update.Note != nil {
+		run.Note = strings.TrimSpace(*update.Note)
+	}
+	if update.Tags != nil {
+		run.Tags = update.Tags
+		if len(run.Tags) == 0 {
+			run.Tags = nil
+		}
+	}
+	if err := s.storage.SaveMetadata(run); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to save run: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// validateTags checks that tags can be written as the key=value list of
+// 'gokanon run -tags'
+func validateTags(tags map[string]string) error {
+	for key, value := range tags {
+		if key == "" || value == "" || strings.TrimSpace(key) != key || strings.TrimSpace(value) != value ||
+			strings.ContainsAny(key, "=,") || strings.Contains(value, ",") {
+			return fmt.Errorf("invalid tag %q (expected key=value)", key+"="+value)
+		}
+	}
+	return nil
+}
+
+// handleBaselines lists the baselines (GET), and whether the viewer's
+// dashboard lets runs be managed, or saves a run as a baseline (POST)
+func (s *Server) handleBaselines(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		baselines, err := s.storage.ListBaselines()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list baselines: %v", err), http.StatusInternalServerError)
+			return
+		}
+		// The runs of baselines are served by /api/runs
+		summaries := make([]models.Baseline, 0, len(baselines))
+		for _, baseline := range baselines {
+			baseline.Run = nil
+			summaries = append(summaries, baseline)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"manage":    s.manageable(),
+			"baselines": summaries,
+		})
+
+	case http.MethodPost:
+		if !s.mayManage(w, r) {
+			return
+		}
+		var req BaselineRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := storage.ValidateBaselineName(req.Name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		run, err := s.storage.Resolve(req.Run)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load run: %v", err), http.StatusNotFound)
+			return
+		}
+
+		save := s.storage.CreateBaseline
+		if req.Replace {
+			save = s.storage.SaveBaseline
+		}
+		baseline, err := save(req.Name, run.ID, strings.TrimSpace(req.Description), nil)
+		if errors.Is(err, storage.ErrBaselineExists) {
+			http.Error(w, fmt.Sprintf("Baseline %q already exists", req.Name), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to save baseline: %v", err), http.StatusInternalServerError)
+			return
+		}
+		baseline.Run = nil
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(baseline)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,214 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// newManagedServer returns the handler of a dashboard with a saved run that
+// viewers log in to with basic authentication
+func newManagedServer(t *testing.T) (*storage.Storage, http.Handler) {
+	t.Helper()
+	store := storage.NewStorage(t.TempDir())
+	run := &models.BenchmarkRun{
+		ID:        "run-1",
+		Timestamp: time.Now(),
+		Package:   "./pkg",
+		Tags:      map[string]string{"branch": "main"},
+		Results:   []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: 100}},
+	}
+	if err := store.Save(run); err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(store, "localhost", 8080)
+	server.SetAuth(Auth{BasicAuth: "team:s3cret"})
+	return store, server.Handler()
+}
+
+// manageRequest serves a request of a logged-in viewer
+func manageRequest(handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.SetBasicAuth("team", "s3cret")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpdateRun(t *testing.T) {
+	store, handler := newManagedServer(t)
+
+	w := manageRequest(handler, http.MethodPatch, "/api/runs/run-1", `{"note":" before the rewrite ","tags":{"branch":"feature","release":"v2"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code = %v, want %v: %s", w.Code, http.StatusOK, w.Body)
+	}
+	run, err := store.Load("run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Note != "before the rewrite" || run.Tags["branch"] != "feature" || run.Tags["release"] != "v2" {
+		t.Errorf("Run not updated: note %q, tags %v", run.Note, run.Tags)
+	}
+	if len(run.Results) != 1 {
+		t.Errorf("Results changed: %v", run.Results)
+	}
+
+	// The search index follows the new note
+	hits, err := store.Search("note:rewrite")
+	if err != nil || len(hits) != 1 {
+		t.Errorf("Search for the new note = %v, %v", hits, err)
+	}
+
+	// Fields left out are unchanged, an empty object removes the tags
+	manageRequest(handler, http.MethodPatch, "/api/runs/run-1", `{"tags":{}}`)
+	run, _ = store.Load("run-1")
+	if run.Note != "before the rewrite" || run.Tags != nil {
+		t.Errorf("Run after removing the tags: note %q, tags %v", run.Note, run.Tags)
+	}
+
+	for _, body := range []string{`{"tags":{"a=b":"c"}}`, `{"tags":{"key":""}}`, `{"tags":{"key":"a,b"}}`, `not json`} {
+		if w := manageRequest(handler, http.MethodPatch, "/api/runs/run-1", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status code = %v, want %v", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestDeleteRun(t *testing.T) {
+	store, handler := newManagedServer(t)
+
+	if w := manageRequest(handler, http.MethodDelete, "/api/runs/missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("status code = %v, want %v", w.Code, http.StatusNotFound)
+	}
+	if w := manageRequest(handler, http.MethodDelete, "/api/runs/latest", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("status code = %v, want %v: %s", w.Code, http.StatusNoContent, w.Body)
+	}
+	if store.Exists("run-1") {
+		t.Error("Run was not deleted")
+	}
+}
+
+func TestCreateBaseline(t *testing.T) {
+	store, handler := newManagedServer(t)
+
+	w := manageRequest(handler, http.MethodPost, "/api/baselines", `{"name":"v1.0","run":"latest","description":"release"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status code = %v, want %v: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	baseline, err := store.LoadBaseline("v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseline.RunID != "run-1" || baseline.Description != "release" {
+		t.Errorf("Baseline = %+v", baseline)
+	}
+
+	// Existing baselines are only replaced on request
+	if w := manageRequest(handler, http.MethodPost, "/api/baselines", `{"name":"v1.0","run":"run-1"}`); w.Code != http.StatusConflict {
+		t.Errorf("status code = %v, want %v", w.Code, http.StatusConflict)
+	}
+	if w := manageRequest(handler, http.MethodPost, "/api/baselines", `{"name":"v1.0","run":"run-1","replace":true}`); w.Code != http.StatusCreated {
+		t.Errorf("status code = %v, want %v", w.Code, http.StatusCreated)
+	}
+	if w := manageRequest(handler, http.MethodPost, "/api/baselines", `{"name":"../v2","run":"run-1"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("status code = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+
+	w = manageRequest(handler, http.MethodGet, "/api/baselines", "")
+	var response struct {
+		Manage    bool              `json:"manage"`
+		Baselines []models.Baseline `json:"baselines"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if !response.Manage || len(response.Baselines) != 1 || response.Baselines[0].Run != nil {
+		t.Errorf("Baselines = %+v", response)
+	}
+}
+
+func TestManageAuthorization(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	if err := store.Save(&models.BenchmarkRun{ID: "run-1", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An open dashboard cannot be managed
+	handler := NewServer(store, "localhost", 8080).Handler()
+	req := httptest.NewRequest(http.MethodDelete, "/api/runs/run-1", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status code = %v, want %v", w.Code, http.StatusForbidden)
+	}
+
+	// Without viewer authentication, the token of runs and agents is needed
+	server := NewServer(store, "localhost", 8080)
+	server.EnableAgents("agent-token")
+	handler = server.Handler()
+	for _, tt := range []struct {
+		token  string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"guess", http.StatusUnauthorized},
+		{"agent-token", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodPatch, "/api/runs/run-1", strings.NewReader(`{"note":"checked"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("token %q: status code = %v, want %v", tt.token, w.Code, tt.status)
+		}
+	}
+
+	// Profiles cannot be deleted one by one
+	req = httptest.NewRequest(http.MethodDelete, "/api/runs/run-1/profiles/cpu", nil)
+	req.Header.Set("Authorization", "Bearer agent-token")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status code = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestManageCrossSite(t *testing.T) {
+	store, handler := newManagedServer(t)
+
+	// A form another site posts carries the viewer's basic credentials
+	for _, tt := range []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"text/plain form", map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"urlencoded form", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusUnsupportedMediaType},
+		{"cross-site fetch", map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"other origin", map[string]string{"Content-Type": "application/json", "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"same origin", map[string]string{"Content-Type": "application/json; charset=utf-8", "Sec-Fetch-Site": "same-origin", "Origin": "http://example.com"}, http.StatusCreated},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/baselines", strings.NewReader(`{"name":"v1.0","run":"latest","replace":true}`))
+		req.SetBasicAuth("team", "s3cret")
+		for key, value := range tt.headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: status code = %v, want %v: %s", tt.name, w.Code, tt.status, w.Body)
+		}
+		if _, err := store.LoadBaseline("v1.0"); (err == nil) != (tt.status == http.StatusCreated) {
+			t.Errorf("%s: baseline saved = %v", tt.name, err == nil)
+		}
+	}
+}
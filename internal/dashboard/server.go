@@ -1,16 +1,26 @@
 package dashboard
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/export"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/slo"
+	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
 )
 
@@ -19,6 +29,30 @@ type Server struct {
 	storage *storage.Storage
 	addr    string
 	port    int
+
+	// Runs triggered from the dashboard; jobs is nil when disabled
+	runPackage string
+	token      string
+	jobs       *JobQueue
+
+	// Remote agents; nil when disabled
+	agents *agentRegistry
+
+	// Benchmark weights of the performance score
+	scoreWeights map[string]float64
+
+	// Service level objectives shown in the SLO panel
+	slos []config.SLO
+
+	// IANA time zone timestamps are shown in; empty for the browser's zone
+	timeZone string
+
+	// Notifications of saved and deleted runs pushed to browsers
+	events *runEvents
+
+	// Authentication of viewers and whether requests may change anything
+	auth     Auth
+	readOnly bool
 }
 
 // NewServer creates a new dashboard server
@@ -27,29 +61,85 @@ func NewServer(stor *storage.Storage, addr string, port int) *Server {
 		storage: stor,
 		addr:    addr,
 		port:    port,
+		events:  newRunEvents(stor, time.Second),
 	}
 }
 
-// Start starts the dashboard web server
-func (s *Server) Start() error {
+// EnableRuns allows clients presenting token to trigger benchmark runs of pkg
+func (s *Server) EnableRuns(pkg, token string) {
+	s.runPackage = pkg
+	s.token = token
+	s.jobs = NewJobQueue(s.runJob)
+}
+
+// EnableAgents allows remote agents presenting token to register and run
+// queued jobs. Without EnableRuns, jobs are only run by agents.
+func (s *Server) EnableAgents(token string) {
+	s.token = token
+	s.agents = newAgentRegistry()
+	if s.jobs == nil {
+		s.jobs = NewJobQueue(nil)
+	}
+}
+
+// SetScoreWeights sets the benchmark weights of the performance score
+func (s *Server) SetScoreWeights(weights map[string]float64) {
+	s.scoreWeights = weights
+}
+
+// SetSLOs sets the service level objectives evaluated by the SLO panel
+func (s *Server) SetSLOs(slos []config.SLO) {
+	s.slos = slos
+}
+
+// SetTimeZone sets the IANA time zone, such as "UTC", that the dashboard
+// shows timestamps in. Without one, viewers see their browser's zone.
+func (s *Server) SetTimeZone(name string) {
+	s.timeZone = name
+}
+
+// Handler returns the HTTP handler serving the dashboard and its API
+func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	// API endpoints
 	mux.HandleFunc("/api/runs", s.handleRuns)
 	mux.HandleFunc("/api/runs/", s.handleRunDetail)
 	mux.HandleFunc("/api/trends", s.handleTrends)
+	mux.HandleFunc("/api/compare", s.handleCompare)
+	mux.HandleFunc("/api/baselines", s.handleBaselines)
 	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.HandleFunc("/api/health", s.handleHealth)
 	mux.HandleFunc("/api/search", s.handleSearch)
+	mux.HandleFunc("/api/live", s.handleLive)
+	mux.HandleFunc("/api/daemon", s.handleDaemon)
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/jobs", s.handleJobs)
+	mux.HandleFunc("/api/jobs/", s.handleJobDetail)
+	mux.HandleFunc("/api/agents", s.handleAgents)
+	mux.HandleFunc("/api/agents/", s.handleAgentDetail)
+	mux.HandleFunc("/api/badge/score.svg", s.handleScoreBadge)
+	mux.HandleFunc("/api/slos", s.handleSLOs)
+	mux.HandleFunc("/api/stress", s.handleStress)
+	mux.HandleFunc("/api/scaling", s.handleScaling)
+	mux.HandleFunc("/api/snapshots", s.handleSnapshots)
+	mux.HandleFunc("/api/snapshots/", s.handleSnapshotDetail)
 
 	// Frontend
 	mux.HandleFunc("/", s.handleIndex)
 	mux.HandleFunc("/static/", s.handleStatic)
+	mux.HandleFunc("/snapshots/", s.handleSnapshotPage)
+
+	return s.protect(mux)
+}
 
+// Start starts the dashboard web server
+func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%d", s.addr, s.port)
 	log.Printf("🚀 Dashboard server starting at http://%s\n", addr)
 	log.Printf("📊 Open your browser to view interactive benchmarks\n")
 
-	return http.ListenAndServe(addr, mux)
+	return http.ListenAndServe(addr, s.Handler())
 }
 
 // handleRuns returns a list of all benchmark runs
@@ -65,7 +155,12 @@ func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create a summary view for the list
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.runSummaries(runs))
+}
+
+// runSummaries returns the summary view of runs listed by the dashboard
+func (s *Server) runSummaries(runs []models.BenchmarkRun) []map[string]interface{} {
 	summaries := make([]map[string]interface{}, 0, len(runs))
 	for _, run := range runs {
 		summary := map[string]interface{}{
@@ -76,20 +171,50 @@ func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
 			"duration":  run.Duration.String(),
 			"numTests":  len(run.Results),
 		}
+		if run.Note != "" {
+			summary["note"] = run.Note
+		}
+		if len(run.Tags) > 0 {
+			summary["tags"] = run.Tags
+		}
+		if len(run.Annotations) > 0 {
+			summary["annotations"] = run.Annotations
+		}
+		if run.Agent != "" {
+			summary["agent"] = run.Agent
+			summary["agentLabels"] = run.AgentLabels
+		}
 
-		// Calculate average performance metrics
+		if score, ok := stats.Score(&run, s.scoreWeights); ok {
+			summary["score"] = score
+		}
+		if grade := stats.RunConfidence(&run); grade != "" {
+			summary["confidence"] = grade
+		}
+
+		summary["numFailed"] = run.CountStatus(models.StatusFailed)
+		summary["numSkipped"] = run.CountStatus(models.StatusSkipped)
+		summary["skipRate"] = 0.0
 		if len(run.Results) > 0 {
+			summary["skipRate"] = float64(run.CountStatus(models.StatusSkipped)) / float64(len(run.Results)) * 100
+		}
+
+		// Calculate average performance metrics
+		if measured := len(run.Results) - run.CountStatus(models.StatusFailed) - run.CountStatus(models.StatusSkipped); measured > 0 {
 			var totalNsPerOp float64
 			var totalBytesPerOp int64
 			var totalAllocsPerOp int64
 
 			for _, result := range run.Results {
+				if !result.Measured() {
+					continue
+				}
 				totalNsPerOp += result.NsPerOp
 				totalBytesPerOp += result.BytesPerOp
 				totalAllocsPerOp += result.AllocsPerOp
 			}
 
-			count := float64(len(run.Results))
+			count := float64(measured)
 			summary["avgNsPerOp"] = totalNsPerOp / count
 			summary["avgBytesPerOp"] = float64(totalBytesPerOp) / count
 			summary["avgAllocsPerOp"] = float64(totalAllocsPerOp) / count
@@ -97,14 +222,19 @@ func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
 
 		summaries = append(summaries, summary)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(summaries)
+	return summaries
 }
 
-// handleRunDetail returns details for a specific run
+// handleRunDetail returns details for a specific run (GET), deletes it
+// (DELETE) or edits its note and tags (PATCH)
 func (s *Server) handleRunDetail(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete, http.MethodPatch:
+		if !s.mayManage(w, r) {
+			return
+		}
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -117,16 +247,101 @@ func (s *Server) handleRunDetail(w http.ResponseWriter, r *http.Request) {
 	}
 	id := parts[3]
 
-	run, err := s.storage.Load(id)
+	// Symbolic references such as latest or baseline:v1.0 work here too
+	run, err := s.storage.Resolve(id)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to load run: %v", err), http.StatusNotFound)
 		return
 	}
 
+	switch {
+	case len(parts) == 6 && parts[4] == "profiles" && r.Method == http.MethodGet:
+		s.serveProfile(w, run.ID, parts[5])
+		return
+	case len(parts) > 4 && parts[4] != "":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	case r.Method == http.MethodDelete:
+		s.deleteRun(w, run)
+		return
+	case r.Method == http.MethodPatch:
+		s.updateRun(w, r, run)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(run)
 }
 
+// comparisonMetrics are the metrics the comparison view shows, by their
+// name in comparison links
+var comparisonMetrics = []string{"ns", "bytes", "allocs"}
+
+// handleCompare validates the comparison of a dashboard link,
+// ?compare=<old>..<new>&metric=<metric>, and returns both runs so that the
+// view is restored exactly. Runs are IDs or references such as latest~1;
+// the metric defaults to ns.
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	oldRef, newRef, ok := strings.Cut(r.URL.Query().Get("compare"), "..")
+	if !ok || oldRef == "" || newRef == "" {
+		http.Error(w, "compare must be two runs separated by '..', e.g. compare=latest~1..latest", http.StatusBadRequest)
+		return
+	}
+	metric := r.URL.Query().Get("metric")
+	if metric == "" {
+		metric = "ns"
+	}
+	if !slices.Contains(comparisonMetrics, metric) {
+		http.Error(w, fmt.Sprintf("Unknown metric %q, use one of %s", metric, strings.Join(comparisonMetrics, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	oldRun, err := s.storage.Resolve(oldRef)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load run %s: %v", oldRef, err), http.StatusNotFound)
+		return
+	}
+	newRun, err := s.storage.Resolve(newRef)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load run %s: %v", newRef, err), http.StatusNotFound)
+		return
+	}
+	if oldRun.ID == newRun.ID {
+		http.Error(w, fmt.Sprintf("Both sides of the comparison are run %s", oldRun.ID), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"old":    oldRun,
+		"new":    newRun,
+		"metric": metric,
+	})
+}
+
+// serveProfile sends a profile of a run, such as the one an optimization
+// suggestion refers to, for download and use with go tool pprof
+func (s *Server) serveProfile(w http.ResponseWriter, runID, profileType string) {
+	if !s.storage.HasProfile(runID, profileType) {
+		http.Error(w, fmt.Sprintf("No %s profile for run %s", profileType, runID), http.StatusNotFound)
+		return
+	}
+	data, err := s.storage.LoadProfile(runID, profileType)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", runID+"-"+profileType+".prof"))
+	w.Write(data)
+}
+
 // handleTrends returns trend data across multiple runs
 func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -144,15 +359,24 @@ func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	runs, err := s.storage.List()
+	response, err := s.trends(benchName, limit)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list runs: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Limit the number of runs
-	if len(runs) > limit {
-		runs = runs[:limit]
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// trends returns the trend data of the limit newest runs, for the
+// benchmarks or the family named benchName, or for all when it is empty
+func (s *Server) trends(benchName string, limit int) (map[string]interface{}, error) {
+	runs, err := s.storage.Latest(limit, func(run *models.BenchmarkRun) bool {
+		return !run.Failed() && !run.Degraded()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
 	}
 
 	// Reverse to get chronological order
@@ -162,15 +386,30 @@ func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
 
 	// Build trend data
 	trendData := make(map[string][]map[string]interface{})
+	families := make(map[string]string)
+	// Annotated runs are drawn as vertical markers
+	annotations := make([]map[string]interface{}, 0)
 
 	for _, run := range runs {
 		timestamp := run.Timestamp.Format(time.RFC3339)
+		for _, annotation := range run.Annotations {
+			annotations = append(annotations, map[string]interface{}{
+				"timestamp": timestamp,
+				"runId":     run.ID,
+				"kind":      annotation.Kind,
+				"message":   annotation.Message,
+			})
+		}
 
 		for _, result := range run.Results {
-			// Filter by benchmark name if specified
-			if benchName != "" && result.Name != benchName {
+			// Filter by benchmark name or family if specified
+			if benchName != "" && result.Name != benchName && result.Family() != benchName {
 				continue
 			}
+			if !result.Measured() {
+				continue
+			}
+			families[result.Name] = result.Family()
 
 			if _, exists := trendData[result.Name]; !exists {
 				trendData[result.Name] = make([]map[string]interface{}, 0)
@@ -183,6 +422,7 @@ func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
 				"bytesPerOp":  result.BytesPerOp,
 				"allocsPerOp": result.AllocsPerOp,
 				"mbPerSec":    result.MBPerSec,
+				"metrics":     result.Metrics,
 			})
 		}
 	}
@@ -190,6 +430,8 @@ func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
 	// Calculate trend statistics
 	response := make(map[string]interface{})
 	response["trends"] = trendData
+	response["families"] = groupFamilies(families)
+	response["annotations"] = annotations
 
 	// Add statistical analysis for each benchmark
 	statsData := make(map[string]interface{})
@@ -209,56 +451,119 @@ func (s *Server) handleTrends(w http.ResponseWriter, r *http.Request) {
 		// Calculate trend (simple linear regression)
 		slope := calculateSlope(values)
 
+		// Runs deviating from the runs before them are marked on the chart
+		anomalies, _ := stats.DetectAnomalies(values, stats.AnomalyStdDev, 0, 0)
+		for _, anomaly := range anomalies {
+			points[anomaly.Index]["anomaly"] = anomaly
+		}
+
 		statsData[name] = map[string]interface{}{
-			"mean":   stat["mean"],
-			"median": stat["median"],
-			"stdDev": stat["stdDev"],
-			"cv":     stat["cv"],
-			"min":    stat["min"],
-			"max":    stat["max"],
-			"slope":  slope,
-			"trend":  getTrendDirection(slope),
+			"mean":      stat["mean"],
+			"median":    stat["median"],
+			"stdDev":    stat["stdDev"],
+			"cv":        stat["cv"],
+			"min":       stat["min"],
+			"max":       stat["max"],
+			"slope":     slope,
+			"trend":     getTrendDirection(slope),
+			"anomalies": len(anomalies),
 		}
 	}
 	response["statistics"] = statsData
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	// Named baselines are drawn as reference lines for the charted benchmarks
+	baselines, err := s.storage.ListBaselines()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list baselines: %w", err)
+	}
+	baselineData := make([]map[string]interface{}, 0, len(baselines))
+	for _, baseline := range baselines {
+		if baseline.Run == nil {
+			continue
+		}
+		values := make(map[string]interface{})
+		for _, result := range baseline.Run.Results {
+			if _, charted := trendData[result.Name]; !charted || !result.Measured() {
+				continue
+			}
+			values[result.Name] = map[string]interface{}{
+				"nsPerOp":     result.NsPerOp,
+				"bytesPerOp":  result.BytesPerOp,
+				"allocsPerOp": result.AllocsPerOp,
+				"mbPerSec":    result.MBPerSec,
+				"metrics":     result.Metrics,
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		baselineData = append(baselineData, map[string]interface{}{
+			"name":   baseline.Name,
+			"runId":  baseline.RunID,
+			"values": values,
+		})
+	}
+	response["baselines"] = baselineData
+	return response, nil
 }
 
-// handleStats returns statistical summaries
+// handleStats returns statistical summaries, optionally limited to runs
+// between the ?from= and ?to= dates
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	from, err := parseDateParam(r.URL.Query().Get("from"), false)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid from parameter: %v", err), http.StatusBadRequest)
+		return
+	}
+	to, err := parseDateParam(r.URL.Query().Get("to"), true)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid to parameter: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		http.Error(w, "Invalid date range: to is before from", http.StatusBadRequest)
+		return
+	}
+
 	runs, err := s.storage.List()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to list runs: %v", err), http.StatusInternalServerError)
 		return
 	}
+	runs = filterRunsByDate(runs, from, to)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.stats(runs))
+}
 
+// stats returns the statistical summary of runs, newest first
+func (s *Server) stats(runs []models.BenchmarkRun) map[string]interface{} {
 	if len(runs) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+		return map[string]interface{}{
 			"totalRuns":  0,
 			"totalTests": 0,
 			"benchmarks": []string{},
+			"families":   map[string][]string{},
 			"dateRange":  map[string]string{},
 			"recentRuns": []interface{}{},
-		})
-		return
+		}
 	}
 
 	// Collect all unique benchmark names
 	benchmarkNames := make(map[string]bool)
+	families := make(map[string]string)
 	totalTests := 0
 
 	for _, run := range runs {
 		totalTests += len(run.Results)
 		for _, result := range run.Results {
 			benchmarkNames[result.Name] = true
+			families[result.Name] = result.Family()
 		}
 	}
 
@@ -293,64 +598,396 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 		"totalRuns":  len(runs),
 		"totalTests": totalTests,
 		"benchmarks": uniqueBenchmarks,
+		"families":   groupFamilies(families),
 		"dateRange": map[string]string{
 			"oldest": oldest.Format(time.RFC3339),
 			"newest": newest.Format(time.RFC3339),
 		},
 		"recentRuns": recentRuns,
 	}
+	if score, previous := s.latestScores(runs); score > 0 {
+		response["score"] = score
+		if previous > 0 {
+			response["scoreChange"] = (score - previous) / previous * 100
+		}
+	}
+	return response
+}
+
+// latestScores returns the performance scores of the two newest runs that
+// have one, or zero where there is no such run. Runs are newest first.
+func (s *Server) latestScores(runs []models.BenchmarkRun) (latest, previous float64) {
+	for i := range runs {
+		score, ok := stats.Score(&runs[i], s.scoreWeights)
+		if !ok {
+			continue
+		}
+		if latest == 0 {
+			latest = score
+			continue
+		}
+		return latest, score
+	}
+	return latest, 0
+}
+
+// handleScoreBadge serves an SVG badge with the latest performance score
+func (s *Server) handleScoreBadge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Only the two newest runs with a score are loaded
+	runs, err := s.storage.Latest(2, func(run *models.BenchmarkRun) bool {
+		_, ok := stats.Score(run, s.scoreWeights)
+		return ok
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list runs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	latest, previous := s.latestScores(runs)
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-cache")
+	io.WriteString(w, export.ScoreBadge(latest, previous))
+}
+
+// handleHealth returns the state of the storage for monitoring: run
+// counts, disk usage, the freshness of the statistics cache and when a run
+// was last saved. It responds 503 when runs cannot be loaded, so that an
+// uptime check alerts on it.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	health, err := s.storage.Health()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read storage: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+
+	status := "ok"
+	w.Header().Set("Content-Type", "application/json")
+	if !health.Healthy() {
+		status = "degraded"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(struct {
+		Status string `json:"status"`
+		*storage.Health
+	}{status, health})
+}
+
+// handleSLOs returns the compliance and breach history of every SLO
+func (s *Server) handleSLOs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	runs, err := s.storage.List()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list runs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	statuses, err := slo.EvaluateAll(s.slos, runs)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to evaluate SLOs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// handleStress returns the speedup curves of the stress run named by
+// ?run=, or of the newest one. The curves are empty without stress runs.
+func (s *Server) handleStress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var run *models.BenchmarkRun
+	if ref := r.URL.Query().Get("run"); ref != "" {
+		resolved, err := s.storage.Resolve(ref)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load run: %v", err), http.StatusNotFound)
+			return
+		}
+		if resolved.Stress == nil {
+			http.Error(w, fmt.Sprintf("Run %s is not a stress run", resolved.ID), http.StatusNotFound)
+			return
+		}
+		run = resolved
+	} else {
+		runs, err := s.storage.Latest(1, func(run *models.BenchmarkRun) bool { return run.Stress != nil })
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list runs: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if len(runs) > 0 {
+			run = &runs[0]
+		}
+	}
+
+	response := map[string]interface{}{
+		"curves": []stats.StressCurve{},
+	}
+	if run != nil {
+		response["runId"] = run.ID
+		response["timestamp"] = run.Timestamp
+		response["levels"] = run.Stress.Levels
+		response["cpus"] = run.Stress.CPUs
+		if curves := stats.StressReport(run); curves != nil {
+			response["curves"] = curves
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// handleSearch searches for benchmark runs and results
+// handleScaling compares the scalability curves of two runs taken with a
+// CPU matrix, named by ?compare=old..new. The curves are empty when the runs
+// have no benchmark measured at several GOMAXPROCS values in common.
+func (s *Server) handleScaling(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	oldRef, newRef, ok := strings.Cut(r.URL.Query().Get("compare"), "..")
+	if !ok || oldRef == "" || newRef == "" {
+		http.Error(w, "compare must be two runs separated by '..', e.g. compare=latest~1..latest", http.StatusBadRequest)
+		return
+	}
+	oldRun, err := s.storage.Resolve(oldRef)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load run %s: %v", oldRef, err), http.StatusNotFound)
+		return
+	}
+	newRun, err := s.storage.Resolve(newRef)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load run %s: %v", newRef, err), http.StatusNotFound)
+		return
+	}
+
+	curves := compare.Scaling(oldRun, newRun)
+	if curves == nil {
+		curves = []compare.ScalingComparison{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"old":    oldRun.ID,
+		"new":    newRun.ID,
+		"curves": curves,
+	})
+}
+
+// handleLive returns the status of the benchmark run in progress, if any
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	live, err := s.storage.LoadLiveRun()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load live run: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"running": live != nil,
+	}
+	if live != nil {
+		now := time.Now()
+		response["run"] = live
+		response["elapsedSeconds"] = now.Sub(live.StartedAt).Seconds()
+		if remaining, ok := live.Remaining(now); ok {
+			response["remainingSeconds"] = remaining.Seconds()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleDaemon reports the schedules of the benchmark daemon, if one has
+// run against this storage
+func (s *Server) handleDaemon(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, err := s.storage.LoadDaemonStatus()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load daemon status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"configured": status != nil,
+	}
+	if status != nil {
+		response["status"] = status
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleJobs lists triggered runs (GET) or queues a new one (POST)
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		response := map[string]interface{}{
+			"enabled": s.jobs != nil,
+			"agents":  s.agents != nil,
+			"jobs":    []Job{},
+		}
+		if s.jobs != nil {
+			response["package"] = s.runPackage
+			response["jobs"] = s.jobs.Jobs()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+
+	case http.MethodPost:
+		if s.jobs == nil {
+			http.Error(w, "Remote runs are disabled (start the dashboard with -run-pkg or -agents)", http.StatusNotFound)
+			return
+		}
+		if !s.authorized(r) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req JobRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if len(req.Labels) > 0 && s.agents == nil {
+			http.Error(w, "Labeled runs need agents (start the dashboard with -agents)", http.StatusBadRequest)
+			return
+		}
+
+		job, err := s.jobs.Submit(req)
+		if errors.Is(err, ErrQueueFull) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleJobDetail returns a single triggered run
+func (s *Server) handleJobDetail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.jobs == nil {
+		http.Error(w, "Remote runs are disabled", http.StatusNotFound)
+		return
+	}
+
+	job, ok := s.jobs.Job(strings.TrimPrefix(r.URL.Path, "/api/jobs/"))
+	if !ok {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// authorized checks the request's bearer token
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// handleSearch searches the names, packages, tags, notes and commits of runs
+// through the search index, of runs newer than the optional since age. See
+// storage.ParseSearchQuery for the syntax.
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	query := strings.ToLower(r.URL.Query().Get("q"))
+	query := r.URL.Query().Get("q")
 	if query == "" {
 		http.Error(w, "Missing search query parameter 'q'", http.StatusBadRequest)
 		return
 	}
 
-	runs, err := s.storage.List()
+	terms, err := storage.ParseSearchQuery(query)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list runs: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Invalid search query: %v", err), http.StatusBadRequest)
+		return
+	}
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		age, err := config.ParseAge(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid since: %v", err), http.StatusBadRequest)
+			return
+		}
+		since = time.Now().Add(-age)
+	}
+	index, err := s.storage.SearchIndex()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to search runs: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	// Runs whose benchmarks match are listed by benchmark, the others
+	// matched on their metadata
 	results := make([]map[string]interface{}, 0)
-
-	for _, run := range runs {
-		// Search in package name, ID, or benchmark names
-		if strings.Contains(strings.ToLower(run.Package), query) ||
-			strings.Contains(strings.ToLower(run.ID), query) {
-
+	for _, hit := range storage.HitsSince(index.Search(terms), since) {
+		if len(hit.Matches) == 0 {
 			results = append(results, map[string]interface{}{
-				"type":      "run",
-				"id":        run.ID,
-				"timestamp": run.Timestamp.Format(time.RFC3339),
-				"package":   run.Package,
-				"numTests":  len(run.Results),
+				"type":          "run",
+				"id":            hit.ID,
+				"timestamp":     hit.Timestamp.Format(time.RFC3339),
+				"package":       hit.Package,
+				"numTests":      len(hit.Benchmarks),
+				"commit":        hit.Commit,
+				"commitMessage": hit.CommitMessage,
+				"tags":          hit.Tags,
+				"note":          hit.Note,
 			})
 			continue
 		}
-
-		// Search in benchmark result names
-		for _, result := range run.Results {
-			if strings.Contains(strings.ToLower(result.Name), query) {
-				results = append(results, map[string]interface{}{
-					"type":      "benchmark",
-					"runId":     run.ID,
-					"timestamp": run.Timestamp.Format(time.RFC3339),
-					"name":      result.Name,
-					"nsPerOp":   result.NsPerOp,
-				})
-			}
+		for _, bench := range hit.Matches {
+			results = append(results, map[string]interface{}{
+				"type":      "benchmark",
+				"runId":     hit.ID,
+				"timestamp": hit.Timestamp.Format(time.RFC3339),
+				"name":      bench.Name,
+				"nsPerOp":   bench.NsPerOp,
+			})
 		}
 	}
 
@@ -369,8 +1006,23 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.writeIndex(w, "")
+}
+
+// writeIndex writes the dashboard HTML, showing the named snapshot instead
+// of the live data when snapshot is not empty
+func (s *Server) writeIndex(w http.ResponseWriter, snapshot string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	io.WriteString(w, indexHTML)
+	page := indexHTML
+	if s.timeZone != "" {
+		meta := fmt.Sprintf(`<meta name="gokanon-timezone" content="%s">`, html.EscapeString(s.timeZone))
+		page = strings.Replace(page, "</head>", "    "+meta+"\n</head>", 1)
+	}
+	if snapshot != "" {
+		meta := fmt.Sprintf(`<meta name="gokanon-snapshot" content="%s">`, html.EscapeString(snapshot))
+		page = strings.Replace(page, "</head>", "    "+meta+"\n</head>", 1)
+	}
+	io.WriteString(w, page)
 }
 
 // handleStatic serves static assets (CSS, JS)
@@ -391,6 +1043,65 @@ func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// parseDateParam parses a date query parameter given either as RFC 3339 or as
+// a plain YYYY-MM-DD date. Plain dates used as an upper bound cover the whole
+// day. An empty value yields the zero time.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// filterRunsByDate returns the runs with timestamps within [from, to].
+// A zero bound is unbounded.
+func filterRunsByDate(runs []models.BenchmarkRun, from, to time.Time) []models.BenchmarkRun {
+	if from.IsZero() && to.IsZero() {
+		return runs
+	}
+
+	filtered := make([]models.BenchmarkRun, 0, len(runs))
+	for _, run := range runs {
+		if !from.IsZero() && run.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && run.Timestamp.After(to) {
+			continue
+		}
+		filtered = append(filtered, run)
+	}
+	return filtered
+}
+
+// groupFamilies maps each benchmark function with more than one variant
+// to the sorted names of its variants, given the family of each benchmark
+func groupFamilies(families map[string]string) map[string][]string {
+	variants := make(map[string][]string)
+	for name, family := range families {
+		variants[family] = append(variants[family], name)
+	}
+	grouped := make(map[string][]string)
+	for family, names := range variants {
+		if len(names) > 1 {
+			sort.Strings(names)
+			grouped[family] = names
+		}
+	}
+	return grouped
+}
+
 // getTrendDirection returns the trend direction based on slope
 func getTrendDirection(slope float64) string {
 	if slope > 5 {
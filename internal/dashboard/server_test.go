@@ -3,12 +3,20 @@ package dashboard
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/slo"
+	"github.com/alenon/gokanon/internal/stats"
 	"github.com/alenon/gokanon/internal/storage"
 )
 
@@ -260,7 +268,193 @@ func TestHandleRuns(t *testing.T) {
 	}
 }
 
+// TestHandleRunsSkipRate tests that skipped benchmarks are counted per run
+func TestHandleRunsSkipRate(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+
+	run := &models.BenchmarkRun{
+		ID:        "test-run-skips",
+		Timestamp: time.Now(),
+		Package:   "test/package",
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkA", NsPerOp: 100.0, Status: models.StatusOK},
+			{Name: "BenchmarkB", NsPerOp: 200.0, Status: models.StatusOK},
+			{Name: "BenchmarkC", NsPerOp: 300.0, Status: models.StatusOK},
+			{Name: "BenchmarkD", Status: models.StatusSkipped, Message: "needs network"},
+		},
+	}
+	if err := store.Save(run); err != nil {
+		t.Fatalf("failed to save test run: %v", err)
+	}
+
+	server := NewServer(store, "localhost", 8080)
+	req := httptest.NewRequest(http.MethodGet, "/api/runs", nil)
+	w := httptest.NewRecorder()
+	server.handleRuns(w, req)
+
+	var runs []map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&runs); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(runs))
+	}
+
+	if got := runs[0]["numSkipped"]; got != float64(1) {
+		t.Errorf("numSkipped = %v, want 1", got)
+	}
+	if got := runs[0]["skipRate"]; got != float64(25) {
+		t.Errorf("skipRate = %v, want 25", got)
+	}
+}
+
+// TestHandleLive tests the live run endpoint
+func TestHandleLive(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	server := NewServer(store, "localhost", 8080)
+
+	get := func() map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/api/live", nil)
+		w := httptest.NewRecorder()
+		server.handleLive(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status code = %v, want %v", w.Code, http.StatusOK)
+		}
+		var response map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	if response := get(); response["running"] != false {
+		t.Errorf("running = %v, want false without a run in progress", response["running"])
+	}
+
+	live := &models.LiveRun{
+		PID:       os.Getpid(),
+		Package:   "./examples",
+		StartedAt: time.Now().Add(-10 * time.Second),
+		Current:   "Fibonacci",
+		Completed: []models.BenchmarkResult{{Name: "Sum-8", NsPerOp: 12}},
+		Expected:  3,
+	}
+	if err := store.SaveLiveRun(live); err != nil {
+		t.Fatalf("failed to save live run: %v", err)
+	}
+
+	response := get()
+	if response["running"] != true {
+		t.Fatalf("running = %v, want true", response["running"])
+	}
+	run, ok := response["run"].(map[string]interface{})
+	if !ok || run["current"] != "Fibonacci" {
+		t.Errorf("unexpected run in response: %v", response["run"])
+	}
+	if remaining, ok := response["remainingSeconds"].(float64); !ok || remaining < 15 {
+		t.Errorf("remainingSeconds = %v, want about 20", response["remainingSeconds"])
+	}
+}
+
 // TestHandleRunsMethodNotAllowed tests method validation
+func TestHandleHealth(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewStorage(tmpDir)
+	for i, status := range []string{"", models.StatusFailed} {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("run-%d", i),
+			Timestamp: time.Now(),
+			Status:    status,
+			Results:   []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run: %v", err)
+		}
+	}
+	server := NewServer(store, "localhost", 8080)
+
+	get := func() (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		server.handleHealth(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	code, body := get()
+	if code != http.StatusOK || body["status"] != "ok" {
+		t.Fatalf("Expected healthy storage, got %d %v", code, body)
+	}
+	if body["runs"] != 2.0 || body["failed_runs"] != 1.0 || body["disk_usage_bytes"].(float64) <= 0 || body["last_ingest"] == nil {
+		t.Errorf("Unexpected health: %v", body)
+	}
+
+	// Unreadable runs degrade the storage
+	if err := os.WriteFile(tmpDir+"/run-broken.json", []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, body = get()
+	if code != http.StatusServiceUnavailable || body["status"] != "degraded" || body["index_fresh"] != false {
+		t.Errorf("Expected degraded storage with a stale index, got %d %v", code, body)
+	}
+}
+
+func TestHandleCompare(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	now := time.Now()
+	for i, id := range []string{"run-a", "run-b"} {
+		run := &models.BenchmarkRun{
+			ID:        id,
+			Timestamp: now.Add(time.Duration(i) * time.Hour),
+			Results:   []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100, AllocsPerOp: int64(2 + i)}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run: %v", err)
+		}
+	}
+	server := NewServer(store, "localhost", 8080)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/compare?"+query, nil))
+		return w
+	}
+
+	w := get("compare=run-a..run-b&metric=allocs")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code = %v, want %v: %s", w.Code, http.StatusOK, w.Body)
+	}
+	var comparison struct {
+		Old, New models.BenchmarkRun
+		Metric   string
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &comparison); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if comparison.Old.ID != "run-a" || comparison.New.ID != "run-b" || comparison.Metric != "allocs" {
+		t.Errorf("Unexpected comparison: %s..%s %s", comparison.Old.ID, comparison.New.ID, comparison.Metric)
+	}
+
+	// References resolve to the runs they point at, and ns is the default
+	w = get("compare=previous..latest")
+	json.Unmarshal(w.Body.Bytes(), &comparison)
+	if w.Code != http.StatusOK || comparison.Old.ID != "run-a" || comparison.New.ID != "run-b" || comparison.Metric != "ns" {
+		t.Errorf("Expected previous..latest to resolve to run-a..run-b, got %d %s", w.Code, w.Body)
+	}
+
+	for query, code := range map[string]int{
+		"compare=run-a":                       http.StatusBadRequest,
+		"compare=..run-b":                     http.StatusBadRequest,
+		"compare=run-a..run-a":                http.StatusBadRequest,
+		"compare=run-a..run-b&metric=latency": http.StatusBadRequest,
+		"compare=run-a..run-missing":          http.StatusNotFound,
+	} {
+		if w := get(query); w.Code != code {
+			t.Errorf("%s: status code = %v, want %v", query, w.Code, code)
+		}
+	}
+}
+
 func TestHandleRunsMethodNotAllowed(t *testing.T) {
 	tmpDir := t.TempDir()
 	store := storage.NewStorage(tmpDir)
@@ -318,6 +512,36 @@ func TestHandleRunDetail(t *testing.T) {
 	}
 }
 
+// TestHandleRunProfile tests downloading a profile of a run
+func TestHandleRunProfile(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	if err := store.Save(&models.BenchmarkRun{ID: "run-1", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("failed to save test run: %v", err)
+	}
+	if err := store.SaveProfile("run-1", "cpu", strings.NewReader("pprof data")); err != nil {
+		t.Fatalf("failed to save profile: %v", err)
+	}
+	server := NewServer(store, "localhost", 8080)
+
+	w := httptest.NewRecorder()
+	server.handleRunDetail(w, httptest.NewRequest(http.MethodGet, "/api/runs/latest/profiles/cpu", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code = %v, want %v", w.Code, http.StatusOK)
+	}
+	if w.Body.String() != "pprof data" {
+		t.Errorf("body = %q, want the profile", w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "run-1-cpu.prof") {
+		t.Errorf("Content-Disposition = %q, want run-1-cpu.prof", cd)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleRunDetail(w, httptest.NewRequest(http.MethodGet, "/api/runs/run-1/profiles/memory", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status code = %v, want %v for a missing profile", w.Code, http.StatusNotFound)
+	}
+}
+
 // TestHandleRunDetailNotFound tests 404 handling
 func TestHandleRunDetailNotFound(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -386,6 +610,292 @@ func TestHandleStats(t *testing.T) {
 	}
 }
 
+// TestHandleStatsDateRange tests the from/to filters of the /api/stats endpoint
+func TestHandleStatsDateRange(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewStorage(tmpDir)
+
+	dates := []string{"2024-01-10T12:00:00Z", "2024-02-10T12:00:00Z", "2024-03-10T12:00:00Z"}
+	for i, date := range dates {
+		ts, _ := time.Parse(time.RFC3339, date)
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("test-run-%d", i),
+			Timestamp: ts,
+			Results: []models.BenchmarkResult{
+				{Name: fmt.Sprintf("BenchmarkTest%d", i), NsPerOp: 100.0},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run %d: %v", i, err)
+		}
+	}
+
+	server := NewServer(store, "localhost", 8080)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantRuns  float64
+		wantOlder string
+	}{
+		{"no filter", "", http.StatusOK, 3, "2024-01-10T12:00:00Z"},
+		{"from only", "?from=2024-02-01T00:00:00Z", http.StatusOK, 2, "2024-02-10T12:00:00Z"},
+		{"to only", "?to=2024-02-10T12:00:00Z", http.StatusOK, 2, "2024-01-10T12:00:00Z"},
+		{"both bounds", "?from=2024-02-01T00:00:00Z&to=2024-02-28T00:00:00Z", http.StatusOK, 1, "2024-02-10T12:00:00Z"},
+		{"empty window", "?from=2025-01-01T00:00:00Z", http.StatusOK, 0, ""},
+		{"invalid from", "?from=yesterday", http.StatusBadRequest, 0, ""},
+		{"invalid to", "?to=2024-13-45", http.StatusBadRequest, 0, ""},
+		{"reversed range", "?from=2024-03-01T00:00:00Z&to=2024-01-01T00:00:00Z", http.StatusBadRequest, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/stats"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			server.handleStats(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status code = %v, want %v", w.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var stats map[string]interface{}
+			if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if stats["totalRuns"] != tt.wantRuns {
+				t.Errorf("totalRuns = %v, want %v", stats["totalRuns"], tt.wantRuns)
+			}
+			if tt.wantOlder != "" {
+				dateRange := stats["dateRange"].(map[string]interface{})
+				oldest, _ := time.Parse(time.RFC3339, dateRange["oldest"].(string))
+				want, _ := time.Parse(time.RFC3339, tt.wantOlder)
+				if !oldest.Equal(want) {
+					t.Errorf("dateRange.oldest = %v, want %v", oldest, want)
+				}
+			}
+		})
+	}
+}
+
+// TestParseDateParam tests parsing of date query parameters
+// TestScore tests the performance score in stats, run summaries and the badge
+func TestScore(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	for i, ns := range []float64{200, 100} {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("test-run-%d", i),
+			Timestamp: time.Now().Add(time.Duration(i) * time.Hour),
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkFast-8", NsPerOp: ns},
+				{Name: "BenchmarkIgnored-8", NsPerOp: 1e6},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run %d: %v", i, err)
+		}
+	}
+
+	server := NewServer(store, "localhost", 8080)
+	server.SetScoreWeights(map[string]float64{"Ignored": 0})
+	h := server.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	var stats map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	near := func(v interface{}, want float64) bool {
+		f, ok := v.(float64)
+		return ok && math.Abs(f-want) <= 1e-6*want
+	}
+	if !near(stats["score"], 1e7) || !near(stats["scoreChange"], 100) {
+		t.Errorf("score = %v, scoreChange = %v; want 1e7 and 100", stats["score"], stats["scoreChange"])
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/runs", nil))
+	var runs []map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&runs)
+	if len(runs) != 2 || !near(runs[1]["score"], 5e6) {
+		t.Errorf("unexpected run summaries: %v", runs)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/badge/score.svg", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("badge Content-Type = %q", ct)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "10.00M ops/s ▲100.0%") {
+		t.Errorf("badge does not show the score: %s", body)
+	}
+}
+
+// TestHandleSLOs tests SLO evaluation in the dashboard API
+func TestHandleSLOs(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	for i, ns := range []float64{1e6, 3e6} {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("test-run-%d", i),
+			Timestamp: time.Now().Add(time.Duration(i) * time.Hour),
+			Results:   []models.BenchmarkResult{{Name: "BenchmarkCheckout-8", NsPerOp: ns}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run %d: %v", i, err)
+		}
+	}
+
+	server := NewServer(store, "localhost", 8080)
+	h := server.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/slos", nil))
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("expected no SLOs without configuration, got %s", body)
+	}
+
+	server.SetSLOs([]config.SLO{{Name: "checkout", Benchmark: "Checkout", Max: "2ms", Percentile: 50, Window: 1}})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/slos", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var statuses []slo.Status
+	if err := json.NewDecoder(w.Body).Decode(&statuses); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].State != slo.StateBreached || len(statuses[0].History) != 2 {
+		t.Errorf("unexpected SLO status: %+v", statuses)
+	}
+}
+
+func TestHandleStress(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	server := NewServer(store, "localhost", 8080)
+	h := server.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stress", nil))
+	if body := strings.TrimSpace(w.Body.String()); body != `{"curves":[]}` {
+		t.Errorf("expected no curves without stress runs, got %s", body)
+	}
+
+	runs := []*models.BenchmarkRun{
+		{
+			ID:        "stress-run",
+			Timestamp: time.Now(),
+			Stress:    &models.StressMatrix{Levels: []int{1, 4}, CPUs: 4, Benchmarks: []string{"Get"}},
+			Results:   []models.BenchmarkResult{{Name: "Get", NsPerOp: 400}, {Name: "Get-4", NsPerOp: 200}},
+		},
+		{
+			ID:        "plain-run",
+			Timestamp: time.Now().Add(time.Hour),
+			Results:   []models.BenchmarkResult{{Name: "Get-8", NsPerOp: 100}},
+		},
+	}
+	for _, run := range runs {
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save run %s: %v", run.ID, err)
+		}
+	}
+
+	// The newest stress run is reported, not the newest run
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stress", nil))
+	var report struct {
+		RunID  string              `json:"runId"`
+		Curves []stats.StressCurve `json:"curves"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.RunID != "stress-run" || len(report.Curves) != 1 || report.Curves[0].Points[1].Speedup != 2 {
+		t.Errorf("unexpected stress report: %+v", report)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/stress?run=plain-run", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status code = %v, want %v for a run without a stress matrix", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleScaling(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	h := NewServer(store, "localhost", 8080).Handler()
+
+	for i, ns := range []float64{100, 150} {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("run-%d", i+1),
+			Timestamp: time.Now().Add(time.Duration(i) * time.Hour),
+			CPUMatrix: []int{1, 4},
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkGet", NsPerOp: 400, Procs: 1},
+				{Name: "BenchmarkGet-4", NsPerOp: ns, Procs: 4},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save run %s: %v", run.ID, err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/scaling?compare=run-1..latest", nil))
+	var response struct {
+		New    string                      `json:"new"`
+		Curves []compare.ScalingComparison `json:"curves"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.New != "run-2" || len(response.Curves) != 1 || response.Curves[0].Levels[1].NewSpeedup != 400.0/150 {
+		t.Errorf("unexpected scaling comparison: %+v", response)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/scaling?compare=run-1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status code = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestParseDateParam(t *testing.T) {
+	day := time.Date(2024, 2, 10, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		value    string
+		endOfDay bool
+		want     time.Time
+		wantErr  bool
+	}{
+		{"empty", "", false, time.Time{}, false},
+		{"plain date start", "2024-02-10", false, day, false},
+		{"plain date end", "2024-02-10", true, day.AddDate(0, 0, 1).Add(-time.Nanosecond), false},
+		{"rfc3339", "2024-02-10T08:30:00Z", true, time.Date(2024, 2, 10, 8, 30, 0, 0, time.UTC), false},
+		{"invalid", "02/10/2024", false, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDateParam(tt.value, tt.endOfDay)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDateParam() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDateParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestHandleSearch tests the /api/search endpoint
 func TestHandleSearch(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -429,6 +939,16 @@ func TestHandleSearch(t *testing.T) {
 			query:         "nonexistent",
 			expectedCount: 0,
 		},
+		{
+			name:          "search by field",
+			query:         "package:test+bench:Concat",
+			expectedCount: 1,
+		},
+		{
+			name:          "search recent runs",
+			query:         "String&since=1d",
+			expectedCount: 2,
+		},
 	}
 
 	for _, tt := range tests {
@@ -461,13 +981,15 @@ func TestHandleSearchMissingQuery(t *testing.T) {
 	store := storage.NewStorage(tmpDir)
 	server := NewServer(store, "localhost", 8080)
 
-	req := httptest.NewRequest(http.MethodGet, "/api/search", nil)
-	w := httptest.NewRecorder()
+	for _, path := range []string{"/api/search", "/api/search?q=color:red", "/api/search?q=String&since=soon"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
 
-	server.handleSearch(w, req)
+		server.handleSearch(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("status code = %v, want %v", w.Code, http.StatusBadRequest)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status code = %v, want %v", path, w.Code, http.StatusBadRequest)
+		}
 	}
 }
 
@@ -542,6 +1064,223 @@ func TestHandleTrends(t *testing.T) {
 	}
 }
 
+// TestHandleTrendsBaselines tests the baseline reference values of the trends endpoint
+func TestHandleTrendsBaselines(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewStorage(tmpDir)
+
+	for i := 0; i < 3; i++ {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("test-run-%d", i),
+			Timestamp: time.Now().Add(-time.Duration(3-i) * time.Hour),
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkTest", NsPerOp: 100.0 + float64(i)*10.0},
+				{Name: "BenchmarkOther", NsPerOp: 50.0},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run %d: %v", i, err)
+		}
+	}
+	if _, err := store.SaveBaseline("v1.0", "test-run-0", "", nil); err != nil {
+		t.Fatalf("failed to save baseline: %v", err)
+	}
+
+	server := NewServer(store, "localhost", 8080)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/trends?benchmark=BenchmarkTest", nil)
+	w := httptest.NewRecorder()
+	server.handleTrends(w, req)
+
+	var result struct {
+		Baselines []struct {
+			Name   string `json:"name"`
+			RunID  string `json:"runId"`
+			Values map[string]struct {
+				NsPerOp float64 `json:"nsPerOp"`
+			} `json:"values"`
+		} `json:"baselines"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(result.Baselines) != 1 {
+		t.Fatalf("got %d baselines, want 1", len(result.Baselines))
+	}
+	baseline := result.Baselines[0]
+	if baseline.Name != "v1.0" || baseline.RunID != "test-run-0" {
+		t.Errorf("baseline = %s (%s), want v1.0 (test-run-0)", baseline.Name, baseline.RunID)
+	}
+	if len(baseline.Values) != 1 || baseline.Values["BenchmarkTest"].NsPerOp != 100 {
+		t.Errorf("baseline values = %+v, want only BenchmarkTest at 100 ns/op", baseline.Values)
+	}
+}
+
+// TestHandleTrendsAnomalies tests that runs deviating from the runs
+// before them are marked for the chart
+func TestHandleTrendsAnomalies(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewStorage(tmpDir)
+
+	values := []float64{100, 101, 99, 100, 101, 160, 100}
+	for i, v := range values {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("test-run-%d", i),
+			Timestamp: time.Now().Add(-time.Duration(len(values)-i) * time.Hour),
+			Results:   []models.BenchmarkResult{{Name: "BenchmarkTest", NsPerOp: v}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run %d: %v", i, err)
+		}
+	}
+
+	server := NewServer(store, "localhost", 8080)
+	req := httptest.NewRequest(http.MethodGet, "/api/trends", nil)
+	w := httptest.NewRecorder()
+	server.handleTrends(w, req)
+
+	var result struct {
+		Trends map[string][]struct {
+			RunID   string `json:"runId"`
+			Anomaly *struct {
+				Score    float64 `json:"score"`
+				Expected float64 `json:"expected"`
+			} `json:"anomaly"`
+		} `json:"trends"`
+		Statistics map[string]struct {
+			Anomalies int `json:"anomalies"`
+		} `json:"statistics"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	var marked []string
+	for _, point := range result.Trends["BenchmarkTest"] {
+		if point.Anomaly != nil {
+			marked = append(marked, point.RunID)
+			if point.Anomaly.Score <= 0 || point.Anomaly.Expected != 100.2 {
+				t.Errorf("anomaly = %+v, want a spike above 100.2", point.Anomaly)
+			}
+		}
+	}
+	if len(marked) != 1 || marked[0] != "test-run-5" {
+		t.Errorf("marked runs = %v, want [test-run-5]", marked)
+	}
+	if result.Statistics["BenchmarkTest"].Anomalies != 1 {
+		t.Errorf("statistics = %+v, want 1 anomaly", result.Statistics["BenchmarkTest"])
+	}
+}
+
+// TestHandleTrendsAnnotations tests that annotated runs are returned for
+// the chart markers
+func TestHandleTrendsAnnotations(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	for i := range 2 {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("test-run-%d", i),
+			Timestamp: time.Now().Add(-time.Duration(2-i) * time.Hour),
+			Results:   []models.BenchmarkResult{{Name: "BenchmarkTest", NsPerOp: 100}},
+		}
+		if i == 1 {
+			run.Annotations = []models.Annotation{{Time: time.Now(), Kind: "upgrade", Message: "upgraded Go to 1.22"}}
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run %d: %v", i, err)
+		}
+	}
+
+	server := NewServer(store, "localhost", 8080)
+	req := httptest.NewRequest(http.MethodGet, "/api/trends", nil)
+	w := httptest.NewRecorder()
+	server.handleTrends(w, req)
+
+	var result struct {
+		Annotations []map[string]string `json:"annotations"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Annotations) != 1 || result.Annotations[0]["runId"] != "test-run-1" ||
+		result.Annotations[0]["kind"] != "upgrade" || result.Annotations[0]["message"] != "upgraded Go to 1.22" {
+		t.Errorf("annotations = %v, want the note of test-run-1", result.Annotations)
+	}
+}
+
+// TestHandleTrendsFamily tests charting the variants of a benchmark together
+func TestHandleTrendsFamily(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewStorage(tmpDir)
+
+	for i := 0; i < 2; i++ {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("test-run-%d", i),
+			Timestamp: time.Now().Add(-time.Duration(2-i) * time.Hour),
+			Results: []models.BenchmarkResult{
+				{Name: "Parse/small-8", Parent: "Parse", Variant: "small", NsPerOp: 100},
+				{Name: "Parse/large-8", Parent: "Parse", Variant: "large", NsPerOp: 900},
+				{Name: "Encode-8", NsPerOp: 50},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("failed to save test run %d: %v", i, err)
+		}
+	}
+
+	server := NewServer(store, "localhost", 8080)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/trends?benchmark=Parse", nil)
+	w := httptest.NewRecorder()
+	server.handleTrends(w, req)
+
+	var result struct {
+		Trends   map[string][]interface{} `json:"trends"`
+		Families map[string][]string      `json:"families"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(result.Trends) != 2 || result.Trends["Parse/small-8"] == nil || result.Trends["Parse/large-8"] == nil {
+		t.Errorf("trends = %v, want both Parse variants", result.Trends)
+	}
+	if want := []string{"Parse/large-8", "Parse/small-8"}; !slices.Equal(result.Families["Parse"], want) {
+		t.Errorf("families = %v, want Parse: %v", result.Families, want)
+	}
+
+	// The stats list families for the benchmark selector
+	w = httptest.NewRecorder()
+	server.handleStats(w, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	var stats struct {
+		Families map[string][]string `json:"families"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(stats.Families) != 1 || len(stats.Families["Parse"]) != 2 {
+		t.Errorf("stats families = %v, want only Parse with 2 variants", stats.Families)
+	}
+}
+
+// TestHandleIndexTimeZone tests that the time zone reaches the frontend
+func TestHandleIndexTimeZone(t *testing.T) {
+	server := NewServer(storage.NewStorage(t.TempDir()), "localhost", 8080)
+
+	w := httptest.NewRecorder()
+	server.handleIndex(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if strings.Contains(w.Body.String(), "gokanon-timezone") {
+		t.Error("Expected no time zone without SetTimeZone")
+	}
+
+	server.SetTimeZone("America/New_York")
+	w = httptest.NewRecorder()
+	server.handleIndex(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(w.Body.String(), `<meta name="gokanon-timezone" content="America/New_York">`) {
+		t.Error("Expected the time zone in the page")
+	}
+}
+
 // TestHandleIndex tests the index HTML endpoint
 func TestHandleIndex(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -860,3 +1599,49 @@ func TestConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestHandleDaemon(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	server := NewServer(store, "localhost", 8080)
+
+	get := func() map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/api/daemon", nil)
+		w := httptest.NewRecorder()
+		server.handleDaemon(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status code = %v, want %v", w.Code, http.StatusOK)
+		}
+		var response map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	if response := get(); response["configured"] != false {
+		t.Errorf("configured = %v, want false before the daemon ran", response["configured"])
+	}
+
+	status := &models.DaemonStatus{
+		PID:       os.Getpid(),
+		StartedAt: time.Now(),
+		Running:   true,
+		Schedules: []models.ScheduleStatus{{Name: "nightly", Cron: "0 2 * * *", LastStatus: "regressed", Regressions: 2}},
+	}
+	if err := store.SaveDaemonStatus(status); err != nil {
+		t.Fatalf("failed to save daemon status: %v", err)
+	}
+
+	response := get()
+	if response["configured"] != true {
+		t.Fatalf("configured = %v, want true", response["configured"])
+	}
+	daemon := response["status"].(map[string]interface{})
+	if daemon["running"] != true {
+		t.Errorf("running = %v, want true for this process", daemon["running"])
+	}
+	schedules := daemon["schedules"].([]interface{})
+	if len(schedules) != 1 || schedules[0].(map[string]interface{})["last_status"] != "regressed" {
+		t.Errorf("unexpected schedules: %v", schedules)
+	}
+}
@@ -0,0 +1,160 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Snapshot freezes the current statistics of all runs and the trends of the
+// limit newest runs under name, to be browsed at /snapshots/<name> once
+// newer runs have replaced them
+func (s *Server) Snapshot(name, description string, limit int) (*models.Snapshot, error) {
+	if err := storage.ValidateBaselineName(name); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.storage.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	trends, err := s.trends("", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip through JSON, so that a new snapshot holds the same values
+	// as one loaded from the storage
+	var snapshot models.Snapshot
+	data, err := json.Marshal(models.Snapshot{
+		Name:        name,
+		CreatedAt:   time.Now(),
+		Description: description,
+		Stats:       s.stats(runs),
+		Trends:      trends,
+		Runs:        s.runSummaries(runs),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// handleSnapshots lists the saved snapshots, newest first
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	snapshots, err := s.storage.ListSnapshots()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list snapshots: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	list := make([]map[string]interface{}, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		list = append(list, map[string]interface{}{
+			"name":        snapshot.Name,
+			"createdAt":   snapshot.CreatedAt.Format(time.RFC3339),
+			"description": snapshot.Description,
+			"totalRuns":   snapshot.Stats["totalRuns"],
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleSnapshotDetail serves a snapshot at /api/snapshots/<name>, and its
+// parts in the shape of the live API at /api/snapshots/<name>/stats, /runs
+// and /trends, so the dashboard shows a snapshot as it showed the live data
+func (s *Server) handleSnapshotDetail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, part, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/snapshots/"), "/")
+	snapshot, err := s.storage.LoadSnapshot(name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load snapshot: %v", err), http.StatusNotFound)
+		return
+	}
+
+	var response interface{}
+	switch part {
+	case "":
+		response = snapshot
+	case "stats":
+		response = snapshot.Stats
+	case "runs":
+		response = snapshot.Runs
+	case "trends":
+		response = filterTrends(snapshot.Trends, r.URL.Query().Get("benchmark"))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleSnapshotPage serves the dashboard showing the snapshot named in
+// the path, /snapshots/<name>
+func (s *Server) handleSnapshotPage(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/snapshots/")
+	if name == "" {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	if storage.ValidateBaselineName(name) != nil || !s.storage.HasSnapshot(name) {
+		http.NotFound(w, r)
+		return
+	}
+	s.writeIndex(w, name)
+}
+
+// filterTrends returns the frozen trends of the benchmark or the family
+// named benchmark, or all trends when it is empty
+func filterTrends(trends map[string]interface{}, benchmark string) map[string]interface{} {
+	if benchmark == "" {
+		return trends
+	}
+
+	names := map[string]bool{benchmark: true}
+	if families, ok := trends["families"].(map[string]interface{}); ok {
+		variants, _ := families[benchmark].([]interface{})
+		for _, variant := range variants {
+			if name, ok := variant.(string); ok {
+				names[name] = true
+			}
+		}
+	}
+
+	filtered := make(map[string]interface{}, len(trends))
+	for key, value := range trends {
+		filtered[key] = value
+	}
+	for _, key := range []string{"trends", "statistics"} {
+		data, _ := trends[key].(map[string]interface{})
+		kept := make(map[string]interface{})
+		for name, value := range data {
+			if names[name] {
+				kept[name] = value
+			}
+		}
+		filtered[key] = kept
+	}
+	return filtered
+}
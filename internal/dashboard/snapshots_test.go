@@ -0,0 +1,165 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// saveSnapshotRuns saves runs of a table-driven benchmark and another one
+func saveSnapshotRuns(t *testing.T, store *storage.Storage, ids ...string) {
+	t.Helper()
+	for i, id := range ids {
+		run := &models.BenchmarkRun{
+			ID:        id,
+			Timestamp: time.Now().Add(time.Duration(i) * time.Minute),
+			Package:   "test/package",
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkParse/small", NsPerOp: 100 + float64(i)},
+				{Name: "BenchmarkParse/large", NsPerOp: 1000 + float64(i)},
+				{Name: "BenchmarkEncode", NsPerOp: 50},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Failed to save run %s: %v", id, err)
+		}
+	}
+}
+
+// getJSON serves a GET request and decodes its JSON response
+func getJSON(t *testing.T, handler http.Handler, path string, v interface{}) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: invalid JSON: %v", path, err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	saveSnapshotRuns(t, store, "run-1", "run-2")
+	server := NewServer(store, "localhost", 8080)
+
+	snapshot, err := server.Snapshot("v2.3.0", "Shipped on Friday", 50)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if err := store.SaveSnapshot(snapshot); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+
+	// Runs saved after the release do not change the snapshot
+	saveSnapshotRuns(t, store, "run-3")
+	handler := server.Handler()
+
+	var stats map[string]interface{}
+	getJSON(t, handler, "/api/snapshots/v2.3.0/stats", &stats)
+	if stats["totalRuns"] != 2.0 {
+		t.Errorf("Expected the 2 runs of the snapshot, got %v", stats["totalRuns"])
+	}
+
+	var runs []map[string]interface{}
+	getJSON(t, handler, "/api/snapshots/v2.3.0/runs", &runs)
+	if len(runs) != 2 || runs[0]["id"] != "run-2" {
+		t.Errorf("Expected runs run-2 and run-1, got %v", runs)
+	}
+
+	var trends map[string]interface{}
+	getJSON(t, handler, "/api/snapshots/v2.3.0/trends", &trends)
+	points := trends["trends"].(map[string]interface{})["BenchmarkParse/small"].([]interface{})
+	if len(points) != 2 {
+		t.Errorf("Expected 2 frozen trend points, got %d", len(points))
+	}
+
+	// A family selects all its variants
+	getJSON(t, handler, "/api/snapshots/v2.3.0/trends?benchmark=BenchmarkParse", &trends)
+	charted := trends["trends"].(map[string]interface{})
+	if len(charted) != 2 || charted["BenchmarkEncode"] != nil {
+		t.Errorf("Expected the 2 variants of BenchmarkParse, got %v", charted)
+	}
+	if len(trends["statistics"].(map[string]interface{})) != 2 {
+		t.Errorf("Expected the statistics of the 2 variants, got %v", trends["statistics"])
+	}
+
+	var list []map[string]interface{}
+	getJSON(t, handler, "/api/snapshots", &list)
+	if len(list) != 1 || list[0]["name"] != "v2.3.0" || list[0]["description"] != "Shipped on Friday" {
+		t.Errorf("Unexpected snapshot list: %v", list)
+	}
+}
+
+func TestSnapshotInvalidName(t *testing.T) {
+	server := NewServer(storage.NewStorage(t.TempDir()), "localhost", 8080)
+	if _, err := server.Snapshot("release/2.3", "", 50); err == nil {
+		t.Error("Expected an error for a name with a path separator")
+	}
+}
+
+func TestHandleSnapshotPage(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	if err := store.SaveSnapshot(&models.Snapshot{Name: "v2.3.0", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveSnapshot failed: %v", err)
+	}
+	handler := NewServer(store, "localhost", 8080).Handler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/snapshots/v2.3.0", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `<meta name="gokanon-snapshot" content="v2.3.0">`) {
+		t.Error("Expected the snapshot name in the page")
+	}
+
+	for path, want := range map[string]int{
+		"/snapshots/v9.9.9":           http.StatusNotFound,
+		"/snapshots/":                 http.StatusFound,
+		"/api/snapshots/v9.9.9":       http.StatusNotFound,
+		"/api/snapshots/v2.3.0/other": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("GET %s: expected status %d, got %d", path, want, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/snapshots", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for POST, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestFilterTrends(t *testing.T) {
+	trends := map[string]interface{}{
+		"trends":     map[string]interface{}{"BenchmarkA/x": 1, "BenchmarkA/y": 2, "BenchmarkB": 3},
+		"statistics": map[string]interface{}{"BenchmarkA/x": 1, "BenchmarkB": 3},
+		"families":   map[string]interface{}{"BenchmarkA": []interface{}{"BenchmarkA/x", "BenchmarkA/y"}},
+		"baselines":  []interface{}{},
+	}
+
+	for benchmark, want := range map[string]int{"": 3, "BenchmarkA": 2, "BenchmarkB": 1, "BenchmarkC": 0} {
+		filtered := filterTrends(trends, benchmark)
+		if got := len(filtered["trends"].(map[string]interface{})); got != want {
+			t.Errorf("%s: expected %d trends, got %d", benchmark, want, got)
+		}
+		if filtered["baselines"] == nil {
+			t.Errorf("%s: expected the baselines to be kept", benchmark)
+		}
+	}
+	if fmt.Sprint(trends["trends"]) != "map[BenchmarkA/x:1 BenchmarkA/y:2 BenchmarkB:3]" {
+		t.Errorf("filterTrends modified its input: %v", trends["trends"])
+	}
+}
@@ -0,0 +1,172 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Linux sources of the environment stability check, variables for tests
+var (
+	loadavgPath  = "/proc/loadavg"
+	governorPath = "/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"
+	throttleGlob = "/sys/devices/system/cpu/cpu*/thermal_throttle/core_throttle_count"
+)
+
+// maxLoadPerCPU is the load average per CPU above which other processes
+// compete with benchmarks for the CPU
+const maxLoadPerCPU = 0.5
+
+// CIOptions selects what the CI preflight checks
+type CIOptions struct {
+	StorageDir string // Storage the pipeline saves runs to
+	Baseline   string // Baseline the pipeline compares against, if any
+}
+
+// Report is the machine-readable outcome of diagnostic checks
+type Report struct {
+	Passed   bool          `json:"passed"`   // No critical check failed
+	Failures int           `json:"failures"` // Failed critical checks
+	Warnings int           `json:"warnings"` // Failed checks that only make results less reliable
+	Checks   []CheckResult `json:"checks"`
+}
+
+// CIChecks runs the checks a CI pipeline needs before benchmarking,
+// without prompting or printing: the Go toolchain, a writable and readable
+// storage, the baseline when one is given, and whether the machine is
+// quiet enough for stable results
+func CIChecks(opts CIOptions) []CheckResult {
+	// Runs that cannot be read break the comparison at the end of the job
+	integrity := storageIntegrity(opts.StorageDir)
+	integrity.Critical = true
+
+	results := []CheckResult{
+		checkGoInstallation(),
+		checkGoTest(),
+		checkStorageWritable(opts.StorageDir),
+		integrity,
+	}
+	if opts.Baseline != "" {
+		results = append(results, checkBaseline(opts.StorageDir, opts.Baseline))
+	}
+	return append(results, checkEnvironmentStability())
+}
+
+// NewReport summarizes the results of checks
+func NewReport(results []CheckResult) Report {
+	report := Report{Passed: true, Checks: results}
+	for _, result := range results {
+		switch {
+		case result.Passed:
+		case result.Critical:
+			report.Failures++
+			report.Passed = false
+		default:
+			report.Warnings++
+		}
+	}
+	return report
+}
+
+// checkStorageWritable creates the storage directory if needed and writes
+// a file to it, as a pipeline would fail to save its run only at the end
+func checkStorageWritable(dir string) CheckResult {
+	result := CheckResult{Name: "Storage Writable", Critical: true}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		result.Message = fmt.Sprintf("Cannot create storage directory: %v", err)
+		result.Suggestions = []string{"Point -storage at a writable directory, e.g. -storage=$RUNNER_TEMP/gokanon"}
+		return result
+	}
+	file, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		result.Message = fmt.Sprintf("Cannot write to %s: %v", dir, err)
+		result.Suggestions = []string{"Check file permissions on " + dir}
+		return result
+	}
+	file.Close()
+	os.Remove(file.Name())
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("Storage directory is writable: %s", dir)
+	return result
+}
+
+// checkBaseline checks that the baseline a pipeline compares against exists
+func checkBaseline(dir, name string) CheckResult {
+	result := CheckResult{Name: "Baseline", Critical: true}
+	if !storage.NewStorage(dir).HasBaseline(name) {
+		result.Message = fmt.Sprintf("Baseline %q not found in %s", name, dir)
+		result.Suggestions = []string{
+			fmt.Sprintf("Save it from a run of the main branch: gokanon baseline save -name=%s", name),
+			"Or restore the storage from the cache or bucket the pipeline shares it through",
+		}
+		return result
+	}
+	result.Passed = true
+	result.Message = fmt.Sprintf("Baseline %q exists", name)
+	return result
+}
+
+// EnvironmentStability checks whether the machine is quiet enough for
+// stable results, as the CI preflight does
+func EnvironmentStability() CheckResult {
+	return checkEnvironmentStability()
+}
+
+// ThrottleCount returns how many times the CPUs were thermally throttled
+// since boot. Comparing counts taken before and after benchmarks tells
+// whether they were slowed down by heat. The counters are Linux-specific;
+// without them ok is false.
+func ThrottleCount() (count int64, ok bool) {
+	paths, _ := filepath.Glob(throttleGlob)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			count += n
+			ok = true
+		}
+	}
+	return count, ok
+}
+
+// checkEnvironmentStability warns about a busy machine or CPU frequency
+// scaling, both of which make timings vary between runs. It only fails
+// softly: noisy results are still results. The sources are Linux-specific;
+// elsewhere the check passes without them.
+func checkEnvironmentStability() CheckResult {
+	result := CheckResult{Name: "Environment Stability", Passed: true}
+	var findings []string
+
+	if data, err := os.ReadFile(loadavgPath); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) > 0 {
+			if load, err := strconv.ParseFloat(fields[0], 64); err == nil && load > maxLoadPerCPU*float64(runtime.NumCPU()) {
+				findings = append(findings, fmt.Sprintf("load average %.2f on %d CPUs", load, runtime.NumCPU()))
+				result.Suggestions = append(result.Suggestions, "Run benchmarks on a dedicated runner or when nothing else runs")
+			}
+		}
+	}
+	if data, err := os.ReadFile(governorPath); err == nil {
+		if governor := strings.TrimSpace(string(data)); governor != "performance" {
+			findings = append(findings, fmt.Sprintf("CPU frequency governor %q", governor))
+			result.Suggestions = append(result.Suggestions, "Pin the CPU frequency: sudo cpupower frequency-set -g performance")
+		}
+	}
+
+	if len(findings) > 0 {
+		result.Passed = false
+		result.Message = "Results may be noisy: " + strings.Join(findings, ", ")
+		result.Suggestions = append(result.Suggestions, "Check which benchmarks are too noisy to gate on: gokanon stability")
+		return result
+	}
+	result.Message = "No busy CPUs or frequency scaling detected"
+	return result
+}
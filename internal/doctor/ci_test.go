@@ -0,0 +1,125 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+func TestCIChecks(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "storage")
+	store := storage.NewStorage(dir)
+	if err := store.Save(&models.BenchmarkRun{ID: "run-1", Results: []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	results := CIChecks(CIOptions{StorageDir: dir, Baseline: "main"})
+	byName := make(map[string]CheckResult)
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	for _, name := range []string{"Storage Writable", "Storage Integrity"} {
+		if result := byName[name]; !result.Passed || !result.Critical {
+			t.Errorf("Expected %s to pass as a critical check, got %+v", name, result)
+		}
+	}
+	baseline := byName["Baseline"]
+	if baseline.Passed || !baseline.Critical || !strings.Contains(baseline.Suggestions[0], "-name=main") {
+		t.Errorf("Expected the missing baseline to fail, got %+v", baseline)
+	}
+	if _, ok := byName["Environment Stability"]; !ok {
+		t.Error("Missing environment stability check")
+	}
+
+	if _, err := store.SaveBaseline("main", "run-1", "", nil); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+	if result := checkBaseline(dir, "main"); !result.Passed {
+		t.Errorf("Expected the baseline to be found, got %+v", result)
+	}
+	// Without a baseline to compare against, none is checked
+	for _, result := range CIChecks(CIOptions{StorageDir: dir}) {
+		if result.Name == "Baseline" {
+			t.Error("Expected no baseline check without a baseline")
+		}
+	}
+}
+
+func TestCheckStorageWritable_NotWritable(t *testing.T) {
+	// A file where the storage directory should be
+	file := filepath.Join(t.TempDir(), "storage")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if result := checkStorageWritable(file); result.Passed || !result.Critical {
+		t.Errorf("Expected a critical failure, got %+v", result)
+	}
+}
+
+func TestCheckEnvironmentStability(t *testing.T) {
+	dir := t.TempDir()
+	oldLoadavg, oldGovernor := loadavgPath, governorPath
+	defer func() { loadavgPath, governorPath = oldLoadavg, oldGovernor }()
+	loadavgPath = filepath.Join(dir, "loadavg")
+	governorPath = filepath.Join(dir, "scaling_governor")
+
+	// Without the Linux sources there is nothing to warn about
+	if result := checkEnvironmentStability(); !result.Passed {
+		t.Errorf("Expected a pass without sources, got %+v", result)
+	}
+
+	os.WriteFile(loadavgPath, []byte("0.00 0.01 0.05 1/100 1234\n"), 0644)
+	os.WriteFile(governorPath, []byte("performance\n"), 0644)
+	if result := checkEnvironmentStability(); !result.Passed {
+		t.Errorf("Expected a quiet machine to pass, got %+v", result)
+	}
+
+	os.WriteFile(loadavgPath, []byte("1000.00 900.00 800.00 5/900 1234\n"), 0644)
+	os.WriteFile(governorPath, []byte("powersave\n"), 0644)
+	result := checkEnvironmentStability()
+	if result.Passed || result.Critical {
+		t.Fatalf("Expected a warning, got %+v", result)
+	}
+	if !strings.Contains(result.Message, "load average 1000.00") || !strings.Contains(result.Message, `"powersave"`) {
+		t.Errorf("Unexpected message: %s", result.Message)
+	}
+}
+
+func TestThrottleCount(t *testing.T) {
+	dir := t.TempDir()
+	oldGlob := throttleGlob
+	defer func() { throttleGlob = oldGlob }()
+	throttleGlob = filepath.Join(dir, "cpu*", "core_throttle_count")
+
+	if _, ok := ThrottleCount(); ok {
+		t.Error("Expected no count without the counters")
+	}
+
+	for cpu, count := range []string{"3\n", "4\n"} {
+		path := filepath.Join(dir, "cpu"+string(rune('0'+cpu)), "core_throttle_count")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(count), 0644)
+	}
+	if count, ok := ThrottleCount(); !ok || count != 7 {
+		t.Errorf("ThrottleCount = %d, %v, want 7 summed over the CPUs", count, ok)
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	report := NewReport([]CheckResult{
+		{Name: "Go Installation", Passed: true, Critical: true},
+		{Name: "Environment Stability", Passed: false},
+	})
+	if !report.Passed || report.Failures != 0 || report.Warnings != 1 {
+		t.Errorf("Expected a passing report with a warning, got %+v", report)
+	}
+
+	report = NewReport([]CheckResult{{Name: "Baseline", Passed: false, Critical: true}})
+	if report.Passed || report.Failures != 1 {
+		t.Errorf("Expected a failing report, got %+v", report)
+	}
+}
@@ -8,25 +8,32 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/alenon/gokanon/internal/config"
 	"github.com/alenon/gokanon/internal/storage"
 	"github.com/alenon/gokanon/internal/ui"
 )
 
 // CheckResult represents the result of a diagnostic check
 type CheckResult struct {
-	Name        string
-	Passed      bool
-	Message     string
-	Suggestions []string
+	Name        string   `json:"name"`
+	Passed      bool     `json:"passed"`
+	Critical    bool     `json:"critical"` // A failure makes gokanon unusable rather than results less reliable
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
 }
 
 // RunDiagnostics runs all diagnostic checks
 func RunDiagnostics() []CheckResult {
-	results := []CheckResult{}
-
 	ui.PrintHeader("Running gokanon diagnostics...")
 	fmt.Println()
 
+	return Diagnostics()
+}
+
+// Diagnostics runs all diagnostic checks without printing anything
+func Diagnostics() []CheckResult {
+	results := []CheckResult{}
+
 	// Check 1: Go installation
 	results = append(results, checkGoInstallation())
 
@@ -97,9 +104,10 @@ func checkGoInstallation() CheckResult {
 
 	if err != nil {
 		return CheckResult{
-			Name:    "Go Installation",
-			Passed:  false,
-			Message: "Go is not installed or not in PATH",
+			Name:     "Go Installation",
+			Passed:   false,
+			Critical: true,
+			Message:  "Go is not installed or not in PATH",
 			Suggestions: []string{
 				"Install Go from https://golang.org/dl/",
 				"Ensure Go is in your PATH environment variable",
@@ -109,9 +117,10 @@ func checkGoInstallation() CheckResult {
 
 	version := strings.TrimSpace(string(output))
 	return CheckResult{
-		Name:    "Go Installation",
-		Passed:  true,
-		Message: version,
+		Name:     "Go Installation",
+		Passed:   true,
+		Critical: true,
+		Message:  version,
 	}
 }
 
@@ -123,9 +132,10 @@ func checkGoTest() CheckResult {
 	err := cmd.Start()
 	if err != nil {
 		return CheckResult{
-			Name:    "Go Test Command",
-			Passed:  false,
-			Message: "Cannot execute 'go test' command",
+			Name:     "Go Test Command",
+			Passed:   false,
+			Critical: true,
+			Message:  "Cannot execute 'go test' command",
 			Suggestions: []string{
 				"Ensure Go toolchain is properly installed",
 				"Check if current directory is a valid Go module",
@@ -135,14 +145,15 @@ func checkGoTest() CheckResult {
 	cmd.Process.Kill()
 
 	return CheckResult{
-		Name:    "Go Test Command",
-		Passed:  true,
-		Message: "'go test' command is available",
+		Name:     "Go Test Command",
+		Passed:   true,
+		Critical: true,
+		Message:  "'go test' command is available",
 	}
 }
 
 func checkStorageDirectory() CheckResult {
-	storageDir := ".gokanon"
+	storageDir := config.DefaultStorageDir()
 
 	info, err := os.Stat(storageDir)
 	if err != nil {
@@ -185,8 +196,14 @@ func checkStorageDirectory() CheckResult {
 }
 
 func checkStorageIntegrity() CheckResult {
-	store := storage.NewStorage(".gokanon")
-	runs, err := store.List()
+	return storageIntegrity(config.DefaultStorageDir())
+}
+
+// storageIntegrity checks that every run of a storage can be loaded and
+// matches the checksum recorded when it was saved, from the same health
+// report the dashboard serves at /api/health
+func storageIntegrity(dir string) CheckResult {
+	health, err := storage.NewStorage(dir).Health()
 	if err != nil {
 		return CheckResult{
 			Name:    "Storage Integrity",
@@ -199,32 +216,45 @@ func checkStorageIntegrity() CheckResult {
 		}
 	}
 
-	if len(runs) == 0 {
+	if len(health.UnreadableRuns) > 0 {
 		return CheckResult{
 			Name:    "Storage Integrity",
-			Passed:  true,
-			Message: "No benchmark runs stored yet",
+			Passed:  false,
+			Message: fmt.Sprintf("%d run(s) cannot be loaded: %s", len(health.UnreadableRuns), strings.Join(health.UnreadableRuns, ", ")),
+			Suggestions: []string{
+				"Try deleting corrupted runs manually: gokanon delete " + health.UnreadableRuns[0],
+				"Backup and recreate .gokanon directory if needed",
+			},
 		}
 	}
-
-	// Check if we can load a recent run
-	_, err = store.Load(runs[0].ID)
-	if err != nil {
+	if len(health.CorruptRuns) > 0 {
 		return CheckResult{
 			Name:    "Storage Integrity",
 			Passed:  false,
-			Message: fmt.Sprintf("Storage may be corrupted: %v", err),
+			Message: fmt.Sprintf("%d run(s) changed since they were saved: %s", len(health.CorruptRuns), strings.Join(health.CorruptRuns, ", ")),
 			Suggestions: []string{
-				"Try deleting corrupted runs manually",
-				"Backup and recreate .gokanon directory if needed",
+				"Restore them from a backup or with 'gokanon sync pull'",
+				"Or delete them: gokanon delete " + health.CorruptRuns[0],
 			},
 		}
 	}
 
+	if health.Runs == 0 {
+		return CheckResult{
+			Name:    "Storage Integrity",
+			Passed:  true,
+			Message: "No benchmark runs stored yet",
+		}
+	}
+
+	message := fmt.Sprintf("Storage is healthy with %d run(s)", health.Runs)
+	if health.FailedRuns > 0 {
+		message += fmt.Sprintf(" (%d failed)", health.FailedRuns)
+	}
 	return CheckResult{
 		Name:    "Storage Integrity",
 		Passed:  true,
-		Message: fmt.Sprintf("Storage is healthy with %d run(s)", len(runs)),
+		Message: fmt.Sprintf("%s, %.1f MB on disk", message, float64(health.DiskUsage)/(1<<20)),
 	}
 }
 
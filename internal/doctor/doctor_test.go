@@ -2,8 +2,13 @@ package doctor
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
 )
 
 func TestCheckGoInstallation(t *testing.T) {
@@ -179,6 +184,27 @@ func TestCheckStorageIntegrity_CorruptedRun(t *testing.T) {
 	}
 }
 
+func TestStorageIntegrity_ChangedRun(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.NewStorage(dir)
+	if err := store.Save(&models.BenchmarkRun{ID: "run-1", Timestamp: time.Now(), Results: []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}}}); err != nil {
+		t.Fatal(err)
+	}
+	if result := storageIntegrity(dir); !result.Passed {
+		t.Fatalf("Expected a saved run to pass, got: %s", result.Message)
+	}
+
+	// Edited by hand and saved uncompressed, the run still loads but no
+	// longer matches its checksum
+	data, _ := store.RunData("run-1")
+	os.Remove(filepath.Join(dir, "run-1.json.gz"))
+	os.WriteFile(filepath.Join(dir, "run-1.json"), []byte(strings.Replace(string(data), "100", "10", 1)), 0644)
+	result := storageIntegrity(dir)
+	if result.Passed || !strings.Contains(result.Message, "changed since they were saved: run-1") || len(result.Suggestions) == 0 {
+		t.Errorf("Expected the changed run reported, got: %s", result.Message)
+	}
+}
+
 func TestCheckBenchmarkFiles_NoFiles(t *testing.T) {
 	// Create temp directory with no test files
 	oldDir, _ := os.Getwd()
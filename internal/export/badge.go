@@ -0,0 +1,70 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"os"
+
+	"github.com/alenon/gokanon/internal/stats"
+)
+
+// Badge colors, matching the shields.io palette
+const (
+	badgeGreen = "#4c1"
+	badgeRed   = "#e05d44"
+	badgeBlue  = "#007ec6"
+	badgeGrey  = "#9f9f9f"
+)
+
+// ScoreBadge renders a shields.io style SVG badge showing a performance
+// score and its change from the previous score. A previous score of 0 means
+// there is nothing to compare with; a score of 0 renders "no data".
+func ScoreBadge(score, previous float64) string {
+	value, color := "no data", badgeGrey
+	if score > 0 {
+		value, color = stats.FormatScore(score)+" "+stats.ScoreUnit, badgeBlue
+	}
+	if score > 0 && previous > 0 {
+		change := (score - previous) / previous * 100
+		switch {
+		case change >= 1:
+			value, color = fmt.Sprintf("%s ▲%.1f%%", value, change), badgeGreen
+		case change <= -1:
+			value, color = fmt.Sprintf("%s ▼%.1f%%", value, -change), badgeRed
+		}
+	}
+	return renderBadge("perf score", value, color)
+}
+
+// renderBadge renders a two-part flat badge
+func renderBadge(label, value, color string) string {
+	// Approximate Verdana 11px glyph widths; good enough for short texts
+	labelWidth := 10 + 7*len([]rune(label))
+	valueWidth := 10 + 7*len([]rune(value))
+	width := labelWidth + valueWidth
+	label, value = html.EscapeString(label), html.EscapeString(value)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+  <title>%[4]s: %[5]s</title>
+  <linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+  <clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[2]d" height="20" fill="#555"/>
+    <rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[7]d" y="14">%[4]s</text>
+    <text x="%[8]d" y="14">%[5]s</text>
+  </g>
+</svg>
+`, width, labelWidth, valueWidth, label, value, color, labelWidth/2, labelWidth+valueWidth/2)
+}
+
+// ToBadge writes a score badge to an SVG file
+func (e *Exporter) ToBadge(score, previous float64, filename string) error {
+	if err := os.WriteFile(filename, []byte(ScoreBadge(score, previous)), 0644); err != nil {
+		return fmt.Errorf("failed to write badge: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// benchstatRow is a row of a benchstat table
+type benchstatRow struct {
+	name           string
+	old, oldSpread string // The spread, such as " ± 2%", is aligned on its own
+	new, newSpread string
+	delta, note    string
+}
+
+// ToBenchstat exports comparisons as the text tables of benchstat, one per
+// metric, so that they can be pasted into commit messages and read by
+// tools expecting that format. Added, removed, failed and skipped
+// benchmarks have no delta and are left out.
+func (e *Exporter) ToBenchstat(comparisons []models.Comparison, filename string) error {
+	comparisons = e.prepare(comparisons)
+	matched, _, _ := compare.Split(comparisons)
+
+	var measured []models.Comparison
+	for _, comp := range matched {
+		if comp.Status != models.StatusFailed && comp.Status != models.StatusSkipped {
+			measured = append(measured, comp)
+		}
+	}
+
+	unit := valueUnit(measured)
+	timeMetric, timeScale := unit, formatPlain
+	if unit == "ns/op" {
+		timeMetric, timeScale = "time/op", formatDuration
+	}
+
+	var tables []string
+	var rows []benchstatRow
+	for _, comp := range measured {
+		row := benchstatRow{name: benchstatName(comp.Name), old: timeScale(comp.OldNsPerOp), new: timeScale(comp.NewNsPerOp)}
+		if comp.OldSamples > 1 && comp.NewSamples > 1 {
+			row.oldSpread = spread(comp.OldCI, comp.OldNsPerOp)
+			row.newSpread = spread(comp.NewCI, comp.NewNsPerOp)
+		}
+		row.delta, row.note = benchstatDelta(comp.OldNsPerOp, comp.NewNsPerOp, comp.DeltaPercent, comp.PValue, comp.OldSamples, comp.NewSamples)
+		rows = append(rows, row)
+	}
+	if len(rows) > 0 {
+		tables = append(tables, benchstatTable(timeMetric, rows))
+	}
+
+	for _, metric := range []struct {
+		name   string
+		scale  func(float64) string
+		metric func(models.Comparison) *models.MetricComparison
+	}{
+		{"alloc/op", formatBytesDecimal, func(c models.Comparison) *models.MetricComparison { return c.BytesPerOp }},
+		{"allocs/op", formatCount, func(c models.Comparison) *models.MetricComparison { return c.AllocsPerOp }},
+		{"speed", formatSpeed, func(c models.Comparison) *models.MetricComparison { return c.MBPerSec }},
+	} {
+		rows = nil
+		for _, comp := range measured {
+			m := metric.metric(comp)
+			if m == nil {
+				continue
+			}
+			row := benchstatRow{name: benchstatName(comp.Name), old: metric.scale(m.Old), new: metric.scale(m.New)}
+			row.delta, row.note = benchstatDelta(m.Old, m.New, m.DeltaPercent, nil, 0, 0)
+			rows = append(rows, row)
+		}
+		if len(rows) > 0 {
+			tables = append(tables, benchstatTable(metric.name, rows))
+		}
+	}
+
+	return os.WriteFile(filename, []byte(strings.Join(tables, "\n")), 0644)
+}
+
+// benchstatTable formats a table with the name left-aligned and the values
+// right-aligned, their spreads aligned after them, as benchstat does
+func benchstatTable(metric string, rows []benchstatRow) string {
+	oldHeader, newHeader := "old "+metric, "new "+metric
+	nameWidth, deltaWidth := len("name"), len("delta")
+	var oldWidth, oldSpreadWidth, newWidth, newSpreadWidth int
+	for _, row := range rows {
+		nameWidth = max(nameWidth, width(row.name))
+		oldWidth, oldSpreadWidth = max(oldWidth, width(row.old)), max(oldSpreadWidth, width(row.oldSpread))
+		newWidth, newSpreadWidth = max(newWidth, width(row.new)), max(newSpreadWidth, width(row.newSpread))
+		deltaWidth = max(deltaWidth, width(row.delta))
+	}
+	// A header wider than its values widens the values
+	oldWidth += max(0, width(oldHeader)-oldWidth-oldSpreadWidth)
+	newWidth += max(0, width(newHeader)-newWidth-newSpreadWidth)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s  %s  %s  delta\n", pad("name", nameWidth, false),
+		pad(oldHeader, oldWidth+oldSpreadWidth, false), pad(newHeader, newWidth+newSpreadWidth, false)))
+	for _, row := range rows {
+		line := fmt.Sprintf("%s  %s%s  %s%s  %s", pad(row.name, nameWidth, false),
+			pad(row.old, oldWidth, true), pad(row.oldSpread, oldSpreadWidth, false),
+			pad(row.new, newWidth, true), pad(row.newSpread, newSpreadWidth, false),
+			pad(row.delta, deltaWidth, true))
+		if row.note != "" {
+			line += "  " + row.note
+		}
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// benchstatName removes the "Benchmark" prefix, as benchstat does
+func benchstatName(name string) string {
+	return strings.TrimPrefix(name, "Benchmark")
+}
+
+// benchstatDelta returns the delta cell and note of a row: "~" when the
+// values are equal or the change is not significant, and the p-value and
+// sample counts when known
+func benchstatDelta(old, new, deltaPercent float64, pValue *float64, oldSamples, newSamples int) (string, string) {
+	if old == new {
+		return "~", "(all equal)"
+	}
+	delta := fmt.Sprintf("%+.2f%%", deltaPercent)
+	if pValue == nil {
+		return delta, ""
+	}
+	note := fmt.Sprintf("(p=%.3f n=%d+%d)", *pValue, oldSamples, newSamples)
+	if *pValue > models.SignificanceLevel {
+		return "~", note
+	}
+	return delta, note
+}
+
+// spread formats a confidence interval as a percentage of the value
+func spread(ci, value float64) string {
+	if value == 0 {
+		return " ± 0%"
+	}
+	return fmt.Sprintf(" ± %.0f%%", ci/value*100)
+}
+
+// width returns the number of characters of s, "µ" and "±" included
+func width(s string) int {
+	return len([]rune(s))
+}
+
+// pad pads s with spaces to n characters, on the left when right is set
+func pad(s string, n int, right bool) string {
+	padding := strings.Repeat(" ", max(0, n-width(s)))
+	if right {
+		return padding + s
+	}
+	return s + padding
+}
+
+// formatDuration formats nanoseconds with three significant digits in
+// the largest unit the value reaches
+func formatDuration(ns float64) string {
+	return formatScaled(ns, 1000, []string{"ns", "µs", "ms", "s"})
+}
+
+// formatBytesDecimal formats bytes in the decimal units of benchstat
+func formatBytesDecimal(bytes float64) string {
+	return formatScaled(bytes, 1000, []string{"B", "kB", "MB", "GB", "TB"})
+}
+
+// formatCount formats an allocation count, scaled from a million on
+func formatCount(count float64) string {
+	if math.Abs(count) < 1e6 {
+		return fmt.Sprintf("%.0f", count)
+	}
+	return formatScaled(count, 1000, []string{"", "k", "M", "G"})
+}
+
+// formatSpeed formats a throughput in MB/s
+func formatSpeed(mbPerSec float64) string {
+	return formatScaled(mbPerSec, 1000, []string{"MB/s", "GB/s", "TB/s"})
+}
+
+// formatPlain formats a value of another unit, such as a normalized ratio
+func formatPlain(v float64) string {
+	return fmt.Sprintf("%.3g", v)
+}
+
+// formatScaled divides v by base until it is below base or the units run
+// out, and formats it with three significant digits
+func formatScaled(v, base float64, units []string) string {
+	i := 0
+	for math.Abs(v) >= base && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	switch abs := math.Abs(v); {
+	case abs >= 100:
+		return fmt.Sprintf("%.0f%s", v, units[i])
+	case abs >= 10:
+		return fmt.Sprintf("%.1f%s", v, units[i])
+	default:
+		return fmt.Sprintf("%.2f%s", v, units[i])
+	}
+}
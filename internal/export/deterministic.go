@@ -0,0 +1,85 @@
+package export
+
+import (
+	"math"
+	"sort"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// SetSorted sorts the benchmarks of comparison reports by name rather than
+// in the order they ran in
+func (e *Exporter) SetSorted(sorted bool) {
+	e.sorted = sorted
+}
+
+// SetRounding rounds the values of comparison reports to digits
+// significant digits, so that noise below that precision does not change
+// the report. Zero keeps the values as measured.
+func (e *Exporter) SetRounding(digits int) {
+	e.digits = digits
+}
+
+// prepare returns the comparisons to report, sorted and rounded as set.
+// The comparisons given are not modified.
+func (e *Exporter) prepare(comparisons []models.Comparison) []models.Comparison {
+	if !e.sorted && e.digits == 0 {
+		return comparisons
+	}
+	prepared := make([]models.Comparison, len(comparisons))
+	for i, comp := range comparisons {
+		if e.digits > 0 {
+			comp.OldNsPerOp = roundSignificant(comp.OldNsPerOp, e.digits)
+			comp.NewNsPerOp = roundSignificant(comp.NewNsPerOp, e.digits)
+			comp.Delta = roundSignificant(comp.Delta, e.digits)
+			comp.DeltaPercent = roundSignificant(comp.DeltaPercent, e.digits)
+			comp.OldCI = roundSignificant(comp.OldCI, e.digits)
+			comp.NewCI = roundSignificant(comp.NewCI, e.digits)
+			comp.BytesPerOp = e.roundMetric(comp.BytesPerOp)
+			comp.AllocsPerOp = e.roundMetric(comp.AllocsPerOp)
+			comp.MBPerSec = e.roundMetric(comp.MBPerSec)
+			comp.Contention = e.roundMetric(comp.Contention)
+			metrics := make([]models.MetricComparison, len(comp.Metrics))
+			for j := range comp.Metrics {
+				metrics[j] = *e.roundMetric(&comp.Metrics[j])
+			}
+			comp.Metrics = metrics
+		}
+		prepared[i] = comp
+	}
+	if e.sorted {
+		sort.SliceStable(prepared, func(i, j int) bool {
+			if prepared[i].Name != prepared[j].Name {
+				return prepared[i].Name < prepared[j].Name
+			}
+			return prepared[i].Package < prepared[j].Package
+		})
+	}
+	return prepared
+}
+
+// roundMetric returns a rounded copy of a metric comparison
+func (e *Exporter) roundMetric(metric *models.MetricComparison) *models.MetricComparison {
+	if metric == nil {
+		return nil
+	}
+	rounded := *metric
+	rounded.Old = roundSignificant(metric.Old, e.digits)
+	rounded.New = roundSignificant(metric.New, e.digits)
+	rounded.Delta = roundSignificant(metric.Delta, e.digits)
+	rounded.DeltaPercent = roundSignificant(metric.DeltaPercent, e.digits)
+	return &rounded
+}
+
+// roundSignificant rounds v to digits significant digits
+func roundSignificant(v float64, digits int) float64 {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	scale := math.Pow(10, float64(digits)-math.Ceil(math.Log10(math.Abs(v))))
+	rounded := math.Round(v*scale) / scale
+	if rounded == 0 {
+		return 0 // Never -0, which prints as "-0.00"
+	}
+	return rounded
+}
@@ -7,19 +7,83 @@ import (
 	"os"
 	"strings"
 
+	"github.com/alenon/gokanon/internal/compare"
 	"github.com/alenon/gokanon/internal/models"
 )
 
 // Exporter handles exporting benchmark comparisons to various formats
-type Exporter struct{}
+type Exporter struct {
+	// How the environments of the compared runs differ, when they were
+	// compared anyway
+	environmentDifferences []string
+
+	// Notes added with 'gokanon annotate' to the compared runs
+	oldAnnotations, newAnnotations []models.Annotation
+
+	sorted bool // Benchmarks are sorted by name
+	digits int  // Significant digits values are rounded to, 0 for none
+}
 
 // NewExporter creates a new exporter
 func NewExporter() *Exporter {
 	return &Exporter{}
 }
 
+// SetEnvironmentDifferences stamps comparison reports with a banner warning
+// that the runs were taken in different environments, listing how
+func (e *Exporter) SetEnvironmentDifferences(differences []string) {
+	e.environmentDifferences = differences
+}
+
+// SetAnnotations lists the annotations of the compared runs in comparison
+// reports
+func (e *Exporter) SetAnnotations(oldAnnotations, newAnnotations []models.Annotation) {
+	e.oldAnnotations, e.newAnnotations = oldAnnotations, newAnnotations
+}
+
+// writeMarkdownAnnotations writes the annotations of the compared runs as
+// a list
+func (e *Exporter) writeMarkdownAnnotations(sb *strings.Builder, oldID, newID string) {
+	if len(e.oldAnnotations) == 0 && len(e.newAnnotations) == 0 {
+		return
+	}
+	sb.WriteString("**Annotations**\n\n")
+	for _, run := range []struct {
+		id          string
+		annotations []models.Annotation
+	}{{oldID, e.oldAnnotations}, {newID, e.newAnnotations}} {
+		for _, annotation := range run.annotations {
+			sb.WriteString(fmt.Sprintf("- `%s`: %s\n", run.id, annotation))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// markdownGrade formats the confidence grade of a comparison as a code span
+// following the benchmark name
+func markdownGrade(grade string) string {
+	if grade == "" {
+		return ""
+	}
+	return " `" + grade + "`"
+}
+
+// writeMarkdownEnvironmentWarning writes the banner of runs taken in
+// different environments as a Markdown quote
+func (e *Exporter) writeMarkdownEnvironmentWarning(sb *strings.Builder) {
+	if len(e.environmentDifferences) == 0 {
+		return
+	}
+	sb.WriteString("> ⚠️ **Environments differ**: deltas may come from the environment rather than the code.\n>\n")
+	for _, difference := range e.environmentDifferences {
+		sb.WriteString(fmt.Sprintf("> - %s\n", difference))
+	}
+	sb.WriteString("\n")
+}
+
 // ToCSV exports comparisons to CSV format
 func (e *Exporter) ToCSV(comparisons []models.Comparison, filename string) error {
+	comparisons = e.prepare(comparisons)
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create CSV file: %w", err)
@@ -30,7 +94,12 @@ func (e *Exporter) ToCSV(comparisons []models.Comparison, filename string) error
 	defer writer.Flush()
 
 	// Write header
-	header := []string{"Benchmark", "Old (ns/op)", "New (ns/op)", "Delta (ns/op)", "Delta (%)", "Status"}
+	unit := valueUnit(comparisons)
+	header := []string{"Benchmark", "Old (" + unit + ")", "New (" + unit + ")", "Delta (" + unit + ")", "Delta (%)", "Status"}
+	for _, name := range standardMetricNames {
+		header = append(header, "Old "+name, "New "+name, name+" Status")
+	}
+	header = append(header, "Confidence")
 	if err := writer.Write(header); err != nil {
 		return err
 	}
@@ -45,6 +114,14 @@ func (e *Exporter) ToCSV(comparisons []models.Comparison, filename string) error
 			fmt.Sprintf("%.2f", comp.DeltaPercent),
 			comp.Status,
 		}
+		for _, metric := range []*models.MetricComparison{comp.BytesPerOp, comp.AllocsPerOp, comp.MBPerSec} {
+			if metric == nil {
+				record = append(record, "", "", "")
+				continue
+			}
+			record = append(record, fmt.Sprintf("%.2f", metric.Old), fmt.Sprintf("%.2f", metric.New), metric.Status)
+		}
+		record = append(record, comp.Confidence)
 		if err := writer.Write(record); err != nil {
 			return err
 		}
@@ -55,14 +132,19 @@ func (e *Exporter) ToCSV(comparisons []models.Comparison, filename string) error
 
 // ToMarkdown exports comparisons to Markdown format
 func (e *Exporter) ToMarkdown(comparisons []models.Comparison, oldID, newID string, filename string) error {
+	comparisons = e.prepare(comparisons)
 	var sb strings.Builder
+	matched, added, removed := compare.Split(comparisons)
+	unit := valueUnit(comparisons)
 
 	sb.WriteString("# Benchmark Comparison\n\n")
 	sb.WriteString(fmt.Sprintf("Comparing: `%s` vs `%s`\n\n", oldID, newID))
-	sb.WriteString("| Status | Benchmark | Old (ns/op) | New (ns/op) | Delta | Delta (%) |\n")
+	e.writeMarkdownEnvironmentWarning(&sb)
+	e.writeMarkdownAnnotations(&sb, oldID, newID)
+	sb.WriteString(fmt.Sprintf("| Status | Benchmark | Old (%s) | New (%s) | Delta | Delta (%%) |\n", unit, unit))
 	sb.WriteString("|--------|-----------|-------------|-------------|-------|----------|\n")
 
-	for _, comp := range comparisons {
+	for _, comp := range matched {
 		status := "⚪"
 		switch comp.Status {
 		case "improved":
@@ -73,7 +155,7 @@ func (e *Exporter) ToMarkdown(comparisons []models.Comparison, oldID, newID stri
 
 		sb.WriteString(fmt.Sprintf("| %s | %s | %.2f | %.2f | %.2f | %+.2f%% |\n",
 			status,
-			comp.Name,
+			comp.Name+markdownGrade(comp.Confidence),
 			comp.OldNsPerOp,
 			comp.NewNsPerOp,
 			comp.Delta,
@@ -81,18 +163,108 @@ func (e *Exporter) ToMarkdown(comparisons []models.Comparison, oldID, newID stri
 		))
 	}
 
+	if changes := standardMetricChanges(matched); len(changes) > 0 {
+		sb.WriteString("\n## Memory and Throughput Changes\n\n")
+		sb.WriteString("| Status | Benchmark | Metric | Old | New | Delta (%) |\n")
+		sb.WriteString("|--------|-----------|--------|-----|-----|-----------|\n")
+		for _, change := range changes {
+			status := "🟢"
+			if change.Status == "degraded" {
+				status = "🔴"
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %.2f | %.2f | %s |\n",
+				status, change.Benchmark, change.Name, change.Old, change.New, change.Change()))
+		}
+	}
+
+	if len(added) > 0 {
+		sb.WriteString("\n## Added Benchmarks\n\n")
+		sb.WriteString(fmt.Sprintf("| Benchmark | New (%s) |\n", unit))
+		sb.WriteString("|-----------|-------------|\n")
+		for _, comp := range added {
+			sb.WriteString(fmt.Sprintf("| %s | %.2f |\n", comp.Name, comp.NewNsPerOp))
+		}
+	}
+
+	if len(removed) > 0 {
+		sb.WriteString("\n## Removed Benchmarks\n\n")
+		sb.WriteString(fmt.Sprintf("| Benchmark | Old (%s) |\n", unit))
+		sb.WriteString("|-----------|-------------|\n")
+		for _, comp := range removed {
+			sb.WriteString(fmt.Sprintf("| %s | %.2f |\n", comp.Name, comp.OldNsPerOp))
+		}
+	}
+
+	// Describe what the benchmarks measure, once per benchmark function
+	described := make(map[string]bool)
+	for _, comp := range comparisons {
+		if comp.Doc == "" || described[comp.Doc] {
+			continue
+		}
+		if len(described) == 0 {
+			sb.WriteString("\n## Benchmark Descriptions\n\n")
+		}
+		described[comp.Doc] = true
+		name, _, _ := strings.Cut(comp.Name, "/")
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", name, models.DocSynopsis(comp.Doc)))
+	}
+
 	// Add summary
 	improved, degraded, same := countStatus(comparisons)
 	sb.WriteString(fmt.Sprintf("\n## Summary\n\n"))
 	sb.WriteString(fmt.Sprintf("- 🟢 Improved: %d\n", improved))
 	sb.WriteString(fmt.Sprintf("- 🔴 Degraded: %d\n", degraded))
 	sb.WriteString(fmt.Sprintf("- ⚪ Unchanged: %d\n", same))
+	if len(added) > 0 {
+		sb.WriteString(fmt.Sprintf("- ➕ Added: %d\n", len(added)))
+	}
+	if len(removed) > 0 {
+		sb.WriteString(fmt.Sprintf("- ➖ Removed: %d\n", len(removed)))
+	}
 
 	return os.WriteFile(filename, []byte(sb.String()), 0644)
 }
 
-// ToHTML exports comparisons to HTML format
-func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTimestamp, newTimestamp string, filename string) error {
+// ToMarkdownSummary exports only the counts of a comparison and its top
+// regressions and improvements, for chat messages and commit statuses
+// where full tables are too noisy
+func (e *Exporter) ToMarkdownSummary(comparisons []models.Comparison, oldID, newID string, filename string) error {
+	comparisons = e.prepare(comparisons)
+	var sb strings.Builder
+	unit := valueUnit(comparisons)
+
+	counts := strings.TrimPrefix(compare.Summary(comparisons), "Summary: ")
+	sb.WriteString(fmt.Sprintf("**Benchmarks** `%s` → `%s`: %s\n", oldID, newID, counts))
+	if len(e.environmentDifferences) > 0 {
+		sb.WriteString("\n")
+		e.writeMarkdownEnvironmentWarning(&sb)
+	}
+
+	regressions, improvements := compare.TopChanges(comparisons, compare.SummaryTopN)
+	for _, top := range []struct {
+		title, icon string
+		comps       []models.Comparison
+	}{
+		{"Top regressions", "🔴", regressions},
+		{"Top improvements", "🟢", improvements},
+	} {
+		if len(top.comps) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n**%s**\n", top.title))
+		for _, comp := range top.comps {
+			sb.WriteString(fmt.Sprintf("- %s `%s` %+.2f%% (%.2f → %.2f %s)\n",
+				top.icon, comp.Name, comp.DeltaPercent, comp.OldNsPerOp, comp.NewNsPerOp, unit))
+		}
+	}
+
+	return os.WriteFile(filename, []byte(sb.String()), 0644)
+}
+
+// ToHTML exports comparisons to HTML format, with the optimization
+// suggestions from the profiles of the new run
+func (e *Exporter) ToHTML(comparisons []models.Comparison, suggestions []models.Suggestion, oldID, newID, oldTimestamp, newTimestamp string, filename string) error {
+	comparisons = e.prepare(comparisons)
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -144,6 +316,25 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
             animation: slideDown 0.5s ease-out;
         }
 
+        .environment-warning {
+            background: #fef3c7;
+            border: 2px solid var(--warning-color);
+            border-radius: 16px;
+            padding: 24px 32px;
+            margin-bottom: 30px;
+            box-shadow: var(--shadow);
+            color: #92400e;
+        }
+
+        .environment-warning h2 {
+            font-size: 1.5em;
+            margin-bottom: 8px;
+        }
+
+        .environment-warning ul {
+            margin: 12px 0 0 20px;
+        }
+
         @keyframes slideDown {
             from {
                 opacity: 0;
@@ -280,6 +471,13 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
             color: var(--text-primary);
         }
 
+        .section-title {
+            color: white;
+            font-size: 1.5rem;
+            font-weight: 700;
+            margin-top: 40px;
+        }
+
         .chart-wrapper {
             position: relative;
             height: 400px;
@@ -341,6 +539,69 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
             font-size: 0.9rem;
         }
 
+        .table-controls {
+            display: flex;
+            flex-wrap: wrap;
+            gap: 16px;
+            align-items: center;
+            margin-top: 30px;
+            color: white;
+            font-weight: 600;
+        }
+
+        .table-controls input[type="search"] {
+            flex: 1;
+            min-width: 200px;
+            padding: 10px 14px;
+            border: none;
+            border-radius: 8px;
+            font-size: 0.95rem;
+        }
+
+        .table-controls select,
+        .pagination button {
+            padding: 8px 12px;
+            border: none;
+            border-radius: 8px;
+            font-size: 0.875rem;
+            background: var(--card-bg);
+            color: var(--text-primary);
+            cursor: pointer;
+        }
+
+        .pagination {
+            display: flex;
+            justify-content: center;
+            align-items: center;
+            gap: 16px;
+            margin-top: -10px;
+            color: white;
+            font-weight: 600;
+        }
+
+        .pagination button:disabled {
+            opacity: 0.5;
+            cursor: default;
+        }
+
+        th.sortable {
+            cursor: pointer;
+            user-select: none;
+        }
+
+        th.sortable.asc::after {
+            content: " ▲";
+        }
+
+        th.sortable.desc::after {
+            content: " ▼";
+        }
+
+        .no-match td {
+            text-align: center;
+            color: var(--text-secondary);
+        }
+
         .badge {
             display: inline-block;
             padding: 4px 12px;
@@ -349,6 +610,8 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
             font-weight: 600;
         }
 
+` + gradeStyle + `
+
         .badge.improved {
             background-color: #d1fae5;
             color: var(--success-color);
@@ -364,6 +627,8 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
             color: var(--neutral-color);
         }
 
+` + suggestionsStyle + `
+
         .footer {
             text-align: center;
             padding: 40px 20px;
@@ -426,16 +691,39 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
             <h1>📊 Benchmark Comparison Report</h1>
             <p class="subtitle">Performance Analysis & Regression Detection</p>
         </header>
+{{if .EnvironmentDifferences}}
+        <div class="environment-warning" role="alert">
+            <h2>⚠️ Environments differ</h2>
+            <p>The runs were taken in different environments: deltas may come from the environment rather than the code.</p>
+            <ul>
+{{- range .EnvironmentDifferences}}
+                <li>{{.}}</li>
+{{- end}}
+            </ul>
+        </div>
+{{end}}
 
         <div class="metadata">
             <div class="metadata-item">
                 <strong>📦 Old Run:</strong>
-                <span>{{.OldID}} ({{.OldTimestamp}})</span>
+                <span>{{.OldID}}{{if .OldTimestamp}} ({{.OldTimestamp}}){{end}}</span>
             </div>
             <div class="metadata-item">
                 <strong>📦 New Run:</strong>
-                <span>{{.NewID}} ({{.NewTimestamp}})</span>
+                <span>{{.NewID}}{{if .NewTimestamp}} ({{.NewTimestamp}}){{end}}</span>
+            </div>
+{{- range .OldAnnotations}}
+            <div class="metadata-item">
+                <strong>📝 {{$.OldID}}:</strong>
+                <span>{{.}}</span>
+            </div>
+{{- end}}
+{{- range .NewAnnotations}}
+            <div class="metadata-item">
+                <strong>📝 {{$.NewID}}:</strong>
+                <span>{{.}}</span>
             </div>
+{{- end}}
         </div>
 
         <div class="summary">
@@ -470,24 +758,35 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
             </div>
         </div>
 
+        <div class="table-controls">
+            <input type="search" id="benchmarkSearch" placeholder="Search benchmarks..." aria-label="Search benchmarks">
+            <label><input type="checkbox" id="regressionsOnly"> Show only regressions</label>
+            <select id="pageSize" aria-label="Benchmarks per page">
+                <option value="25">25 per page</option>
+                <option value="50" selected>50 per page</option>
+                <option value="100">100 per page</option>
+                <option value="0">All</option>
+            </select>
+        </div>
+
         <table>
             <thead>
                 <tr>
-                    <th>Status</th>
-                    <th>Benchmark</th>
-                    <th>Old (ns/op)</th>
-                    <th>New (ns/op)</th>
-                    <th>Delta (ns/op)</th>
-                    <th>Delta (%)</th>
+                    <th class="sortable" data-sort="status">Status</th>
+                    <th class="sortable" data-sort="name">Benchmark</th>
+                    <th class="sortable" data-sort="old">Old ({{.Unit}})</th>
+                    <th class="sortable" data-sort="new">New ({{.Unit}})</th>
+                    <th class="sortable" data-sort="delta">Delta ({{.Unit}})</th>
+                    <th class="sortable" data-sort="percent">Delta (%)</th>
                 </tr>
             </thead>
-            <tbody>
+            <tbody id="comparisonRows">
                 {{range .Comparisons}}
-                <tr>
+                <tr data-name="{{.Name}}" data-status="{{.Status}}" data-old="{{.OldNsPerOp}}" data-new="{{.NewNsPerOp}}" data-delta="{{.Delta}}" data-percent="{{.DeltaPercent}}"{{if regression .}} data-regression{{end}}>
                     <td class="status">
                         {{if eq .Status "improved"}}✅{{else if eq .Status "degraded"}}❌{{else}}⚪{{end}}
                     </td>
-                    <td class="benchmark-name">{{.Name}}</td>
+                    <td class="benchmark-name"{{if .Doc}} title="{{.Doc}}"{{end}}>{{.Name}}{{with .Confidence}} <span class="grade grade-{{.}}" title="Confidence grade">{{.}}</span>{{end}}</td>
                     <td class="metric">{{printf "%.2f" .OldNsPerOp}}</td>
                     <td class="metric">{{printf "%.2f" .NewNsPerOp}}</td>
                     <td class="metric">{{printf "%+.2f" .Delta}}</td>
@@ -496,8 +795,89 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
                     </td>
                 </tr>
                 {{end}}
+                <tr class="no-match" hidden>
+                    <td colspan="6">No benchmark matches the filters</td>
+                </tr>
+            </tbody>
+        </table>
+
+        <div class="pagination">
+            <button type="button" id="prevPage">‹ Previous</button>
+            <span id="pageInfo"></span>
+            <button type="button" id="nextPage">Next ›</button>
+        </div>
+
+        {{if .MetricChanges}}
+        <h2 class="section-title">💾 Memory and Throughput Changes</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>Status</th>
+                    <th>Benchmark</th>
+                    <th>Metric</th>
+                    <th>Old</th>
+                    <th>New</th>
+                    <th>Delta (%)</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .MetricChanges}}
+                <tr>
+                    <td class="status">{{if eq .Status "improved"}}✅{{else}}❌{{end}}</td>
+                    <td class="benchmark-name">{{.Benchmark}}</td>
+                    <td>{{.Name}}</td>
+                    <td class="metric">{{printf "%.2f" .Old}}</td>
+                    <td class="metric">{{printf "%.2f" .New}}</td>
+                    <td>
+                        <span class="badge {{.Status}}">{{.Change}}</span>
+                    </td>
+                </tr>
+                {{end}}
             </tbody>
         </table>
+        {{end}}
+
+        {{if .Added}}
+        <h2 class="section-title">➕ Added Benchmarks</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>Benchmark</th>
+                    <th>New ({{.Unit}})</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Added}}
+                <tr>
+                    <td class="benchmark-name"{{if .Doc}} title="{{.Doc}}"{{end}}>{{.Name}}</td>
+                    <td class="metric">{{printf "%.2f" .NewNsPerOp}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+
+        {{if .Removed}}
+        <h2 class="section-title">➖ Removed Benchmarks</h2>
+        <table>
+            <thead>
+                <tr>
+                    <th>Benchmark</th>
+                    <th>Old ({{.Unit}})</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Removed}}
+                <tr>
+                    <td class="benchmark-name"{{if .Doc}} title="{{.Doc}}"{{end}}>{{.Name}}</td>
+                    <td class="metric">{{printf "%.2f" .OldNsPerOp}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+
+        {{template "suggestions" (suggestionList .NewID .Suggestions)}}
 
         <div class="footer">
             <p>Generated by <a href="https://github.com/alenon/gokanon" target="_blank">gokanon</a></p>
@@ -506,6 +886,75 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
     </div>
 
     <script>
+        // Search, sort and paginate the comparison table, so that large
+        // suites stay usable
+        (function() {
+            const tbody = document.getElementById('comparisonRows');
+            const table = tbody.closest('table');
+            const noMatch = tbody.querySelector('.no-match');
+            const rows = Array.from(tbody.rows).filter(row => row !== noMatch);
+            const search = document.getElementById('benchmarkSearch');
+            const regressionsOnly = document.getElementById('regressionsOnly');
+            const pageSize = document.getElementById('pageSize');
+            const prev = document.getElementById('prevPage');
+            const next = document.getElementById('nextPage');
+            const info = document.getElementById('pageInfo');
+            const headers = table.querySelectorAll('th.sortable');
+            let sortKey = null;
+            let ascending = true;
+            let page = 0;
+
+            function value(row, key) {
+                const v = row.dataset[key];
+                return key === 'name' || key === 'status' ? v : parseFloat(v);
+            }
+
+            function render() {
+                const query = search.value.trim().toLowerCase();
+                let visible = rows.filter(row =>
+                    row.dataset.name.toLowerCase().includes(query) &&
+                    (!regressionsOnly.checked || row.hasAttribute('data-regression'))
+                );
+                if (sortKey) {
+                    visible.sort((a, b) => {
+                        const x = value(a, sortKey), y = value(b, sortKey);
+                        const order = typeof x === 'string' ? x.localeCompare(y) : x - y;
+                        return ascending ? order : -order;
+                    });
+                }
+
+                const size = parseInt(pageSize.value, 10) || visible.length || 1;
+                const pages = Math.max(1, Math.ceil(visible.length / size));
+                page = Math.min(page, pages - 1);
+                const start = page * size;
+                const shown = new Set(visible.slice(start, start + size));
+
+                rows.forEach(row => { row.hidden = !shown.has(row); });
+                visible.forEach(row => tbody.insertBefore(row, noMatch));
+                noMatch.hidden = visible.length > 0;
+
+                info.textContent = visible.length === 0 ? 'No benchmarks' :
+                    'Benchmarks ' + (start + 1) + '–' + Math.min(start + size, visible.length) +
+                    ' of ' + visible.length + (visible.length < rows.length ? ' (' + rows.length + ' in total)' : '');
+                prev.disabled = page === 0;
+                next.disabled = page >= pages - 1;
+            }
+
+            headers.forEach(th => th.addEventListener('click', () => {
+                ascending = sortKey === th.dataset.sort ? !ascending : true;
+                sortKey = th.dataset.sort;
+                headers.forEach(h => h.classList.remove('asc', 'desc'));
+                th.classList.add(ascending ? 'asc' : 'desc');
+                render();
+            }));
+            search.addEventListener('input', () => { page = 0; render(); });
+            regressionsOnly.addEventListener('change', () => { page = 0; render(); });
+            pageSize.addEventListener('change', () => { page = 0; render(); });
+            prev.addEventListener('click', () => { page--; render(); });
+            next.addEventListener('click', () => { page++; render(); });
+            render();
+        })();
+
         // Prepare data for charts
         const comparisons = [
             {{range .Comparisons}}
@@ -528,14 +977,14 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
                 labels: comparisons.map(c => c.name.length > 30 ? c.name.substring(0, 30) + '...' : c.name),
                 datasets: [
                     {
-                        label: 'Old (ns/op)',
+                        label: 'Old ({{.Unit}})',
                         data: comparisons.map(c => c.oldValue),
                         backgroundColor: 'rgba(107, 114, 128, 0.7)',
                         borderColor: 'rgba(107, 114, 128, 1)',
                         borderWidth: 2
                     },
                     {
-                        label: 'New (ns/op)',
+                        label: 'New ({{.Unit}})',
                         data: comparisons.map(c => c.newValue),
                         backgroundColor: comparisons.map(c =>
                             c.status === 'improved' ? 'rgba(16, 185, 129, 0.7)' :
@@ -650,12 +1099,13 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
 </body>
 </html>`
 
-	t, err := template.New("report").Parse(tmpl)
+	t, err := parseTemplate("report", tmpl)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
 	improved, degraded, same := countStatus(comparisons)
+	matched, added, removed := compare.Split(comparisons)
 
 	data := struct {
 		OldID        string
@@ -663,18 +1113,36 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
 		OldTimestamp string
 		NewTimestamp string
 		Comparisons  []models.Comparison
+		Added        []models.Comparison
+		Removed      []models.Comparison
+		Unit         string
 		Improved     int
 		Degraded     int
 		Same         int
+		Suggestions  []models.Suggestion
+
+		MetricChanges          []metricChange
+		EnvironmentDifferences []string
+		OldAnnotations         []models.Annotation
+		NewAnnotations         []models.Annotation
 	}{
 		OldID:        oldID,
 		NewID:        newID,
 		OldTimestamp: oldTimestamp,
 		NewTimestamp: newTimestamp,
-		Comparisons:  comparisons,
+		Comparisons:  matched,
+		Added:        added,
+		Removed:      removed,
+		Unit:         valueUnit(comparisons),
 		Improved:     improved,
 		Degraded:     degraded,
 		Same:         same,
+		Suggestions:  suggestions,
+
+		MetricChanges:          standardMetricChanges(matched),
+		EnvironmentDifferences: e.environmentDifferences,
+		OldAnnotations:         e.oldAnnotations,
+		NewAnnotations:         e.newAnnotations,
 	}
 
 	file, err := os.Create(filename)
@@ -686,6 +1154,147 @@ func (e *Exporter) ToHTML(comparisons []models.Comparison, oldID, newID, oldTime
 	return t.Execute(file, data)
 }
 
+// gradeStyle styles the confidence grades of results in HTML reports
+const gradeStyle = `        .grade {
+            display: inline-block;
+            padding: 0 6px;
+            border-radius: 6px;
+            font-size: 0.75rem;
+            font-weight: 700;
+        }
+
+        .grade-A { background-color: #d1fae5; color: #047857; }
+        .grade-B { background-color: #dbeafe; color: #1d4ed8; }
+        .grade-C { background-color: #fef3c7; color: #b45309; }
+        .grade-D { background-color: #fee2e2; color: #b91c1c; }`
+
+// suggestionsStyle styles the optimization suggestions of HTML reports
+const suggestionsStyle = `        .badge.high {
+            background-color: #fee2e2;
+            color: var(--danger-color);
+        }
+
+        .badge.medium {
+            background-color: #fef3c7;
+            color: var(--warning-color);
+        }
+
+        .badge.low {
+            background-color: #f3f4f6;
+            color: var(--neutral-color);
+        }
+
+        .suggestion {
+            background: var(--card-bg);
+            border-radius: 16px;
+            padding: 24px 30px;
+            margin: 20px 0;
+            box-shadow: var(--shadow);
+        }
+
+        .suggestion h3 {
+            font-size: 1.1rem;
+            margin: 10px 0;
+            word-break: break-all;
+        }
+
+        .suggestion p {
+            margin: 6px 0;
+        }
+
+        .suggestion .evidence {
+            color: var(--text-secondary);
+            font-size: 0.875rem;
+        }`
+
+// suggestionsTemplate renders optimization suggestions in HTML reports, given
+// a suggestionList
+const suggestionsTemplate = `{{define "suggestions"}}
+        {{if .Suggestions}}
+        <h2 class="section-title">💡 Optimization Suggestions</h2>
+        {{range .Suggestions}}
+        <div class="suggestion">
+            <span class="badge {{.Severity}}">{{.Severity}}</span>
+            <span class="badge same">{{.Type}}</span>
+            <h3>{{.Function}}</h3>
+            <p><strong>Issue:</strong> {{.Issue}}</p>
+            <p><strong>Suggestion:</strong> {{.Suggestion}}</p>
+            {{if .Impact}}<p><strong>Potential Impact:</strong> {{.Impact}}</p>{{end}}
+            <p class="evidence">
+                {{if .RuleID}}Rule <code>{{.RuleID}}</code>{{if .Confidence}} ({{percent .Confidence}} confidence){{end}}{{end}}
+                {{if .Profile}} · {{.Profile}} profile of {{$.RunID}}{{end}}
+                {{if .Benchmarks}} · Benchmarks: {{join .Benchmarks ", "}}{{end}}
+            </p>
+        </div>
+        {{end}}
+        {{end}}{{end}}`
+
+// suggestionList holds the suggestions of a run for suggestionsTemplate
+type suggestionList struct {
+	RunID       string
+	Suggestions []models.Suggestion
+}
+
+// parseTemplate parses an HTML report template, which can render
+// suggestions with {{template "suggestions" (suggestionList id suggestions)}}
+func parseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(template.FuncMap{
+		"percent": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+		"join":    strings.Join,
+		"bytes":   humanBytes,
+		"regression": func(comp models.Comparison) bool {
+			return comp.Status == "degraded" || compare.MemoryDegraded(comp)
+		},
+		"suggestionList": func(runID string, suggestions []models.Suggestion) suggestionList {
+			return suggestionList{RunID: runID, Suggestions: suggestions}
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return t.Parse(suggestionsTemplate)
+}
+
+// standardMetricNames are the names of the standard metrics compared
+// besides the timing, in the order of Comparison.StandardMetrics
+var standardMetricNames = []string{"B/op", "allocs/op", "MB/s"}
+
+// metricChange is a standard metric of a benchmark that changed status
+type metricChange struct {
+	Benchmark string
+	models.MetricComparison
+}
+
+// Change formats the relative change of the metric
+func (m metricChange) Change() string {
+	if m.Old == 0 {
+		return "from 0"
+	}
+	return fmt.Sprintf("%+.2f%%", m.DeltaPercent)
+}
+
+// standardMetricChanges lists the standard metrics that improved or
+// degraded, which may differ from the timing of their benchmark
+func standardMetricChanges(comparisons []models.Comparison) []metricChange {
+	var changes []metricChange
+	for _, comp := range comparisons {
+		for _, metric := range comp.StandardMetrics() {
+			if metric.Status != "same" {
+				changes = append(changes, metricChange{Benchmark: comp.Name, MetricComparison: metric})
+			}
+		}
+	}
+	return changes
+}
+
+// valueUnit returns the unit of the ns/op values of the comparisons
+func valueUnit(comparisons []models.Comparison) string {
+	if len(comparisons) == 0 {
+		return "ns/op"
+	}
+	return comparisons[0].ValueUnit()
+}
+
 // countStatus counts the number of each status type
 func countStatus(comparisons []models.Comparison) (improved, degraded, same int) {
 	for _, comp := range comparisons {
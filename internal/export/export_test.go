@@ -1,10 +1,16 @@
 package export
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alenon/gokanon/internal/models"
 )
@@ -185,6 +191,95 @@ func TestToMarkdown(t *testing.T) {
 	}
 }
 
+func TestToHTMLSuggestions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.html")
+	comparisons := []models.Comparison{
+		{Name: "Parse", OldNsPerOp: 100, NewNsPerOp: 90, Delta: -10, DeltaPercent: -10, Status: "improved"},
+	}
+	suggestions := []models.Suggestion{{
+		RuleID:     "cpu-hot-function",
+		Type:       "cpu",
+		Severity:   "high",
+		Confidence: 0.75,
+		Function:   "pkg.parse",
+		Issue:      "Function consumes 55.0% of CPU time",
+		Suggestion: "Consider optimizing this hot function",
+		Profile:    "cpu",
+		Benchmarks: []string{"Decode", "Parse"},
+	}}
+
+	if err := NewExporter().ToHTML(comparisons, suggestions, "old", "new", "t1", "t2", filename); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read HTML file: %v", err)
+	}
+
+	for _, want := range []string{
+		"Optimization Suggestions",
+		`<span class="badge high">high</span>`,
+		"<h3>pkg.parse</h3>",
+		"<code>cpu-hot-function</code> (75% confidence)",
+		"cpu profile of new",
+		"Benchmarks: Decode, Parse",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected HTML to contain %q", want)
+		}
+	}
+}
+
+func TestToRunHTML(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "run.html")
+	run := &models.BenchmarkRun{
+		ID:        "run-1",
+		Package:   "./parser",
+		GoVersion: "go1.24.0",
+		Commit:    "abc123",
+		Results: []models.BenchmarkResult{
+			{Name: "Parse-8", Iterations: 1000, NsPerOp: 1234.5, BytesPerOp: 64, AllocsPerOp: 2, Metrics: map[string]float64{"items/op": 3}},
+			{Name: "Decode-8", Status: models.StatusFailed, Message: "panic: boom"},
+		},
+		ProfileSummary: &models.ProfileSummary{
+			CPUTopFunctions: []models.FunctionProfile{{Name: "parser.scan", FlatPercent: 55, CumPercent: 60}},
+			MemoryGrowth:    []models.MemoryGrowth{{Function: "parser.cache", WarmupBytes: 1024, FinalBytes: 3 << 20, GrowthBytes: 3<<20 - 1024}},
+			Suggestions:     []models.Suggestion{{RuleID: "cpu-hot-function", Type: "cpu", Severity: "high", Function: "parser.scan", Profile: "cpu"}},
+		},
+	}
+
+	if err := NewExporter().ToRunHTML(run, "2024-01-01 10:00:00", filename); err != nil {
+		t.Fatalf("ToRunHTML failed: %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read HTML file: %v", err)
+	}
+	html := string(content)
+
+	for _, want := range []string{
+		"Benchmark Run run-1",
+		"1 passed, 1 failed",
+		"abc123",
+		"1234.50",
+		"3 items/op",
+		`<tr class="failed">`,
+		"failed: panic: boom",
+		"parser.scan",
+		"55.0%",
+		"3.0 MB",
+		"Optimization Suggestions",
+		"cpu profile of run-1",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected run page to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<script src") || strings.Contains(html, "cdn.") {
+		t.Error("Run page must not load external resources")
+	}
+}
+
 func TestToHTML(t *testing.T) {
 	e := NewExporter()
 	tempDir := t.TempDir()
@@ -209,7 +304,7 @@ func TestToHTML(t *testing.T) {
 		},
 	}
 
-	err := e.ToHTML(comparisons, "old-id", "new-id", "2024-01-01 10:00:00", "2024-01-01 11:00:00", filename)
+	err := e.ToHTML(comparisons, nil, "old-id", "new-id", "2024-01-01 10:00:00", "2024-01-01 11:00:00", filename)
 	if err != nil {
 		t.Fatalf("ToHTML failed: %v", err)
 	}
@@ -282,7 +377,7 @@ func TestToHTMLWithSummary(t *testing.T) {
 		{Status: "same"},
 	}
 
-	err := e.ToHTML(comparisons, "old", "new", "time1", "time2", filename)
+	err := e.ToHTML(comparisons, nil, "old", "new", "time1", "time2", filename)
 	if err != nil {
 		t.Fatalf("ToHTML failed: %v", err)
 	}
@@ -312,6 +407,48 @@ func TestToHTMLWithSummary(t *testing.T) {
 	}
 }
 
+func TestExportAddedRemoved(t *testing.T) {
+	e := NewExporter()
+	tempDir := t.TempDir()
+
+	comparisons := []models.Comparison{
+		{Name: "BenchmarkKept", OldNsPerOp: 100.0, NewNsPerOp: 100.0, Status: "same"},
+		{Name: "BenchmarkNew", NewNsPerOp: 42.0, Status: models.StatusAdded},
+		{Name: "BenchmarkGone", OldNsPerOp: 77.0, Status: models.StatusRemoved},
+	}
+
+	mdFile := filepath.Join(tempDir, "report.md")
+	if err := e.ToMarkdown(comparisons, "old", "new", mdFile); err != nil {
+		t.Fatalf("ToMarkdown failed: %v", err)
+	}
+	md, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatalf("Failed to read markdown file: %v", err)
+	}
+	for _, want := range []string{"## Added Benchmarks", "| BenchmarkNew | 42.00 |", "## Removed Benchmarks", "| BenchmarkGone | 77.00 |", "Added: 1", "Removed: 1"} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("Expected markdown to contain %q", want)
+		}
+	}
+	if strings.Contains(string(md), "| ⚪ | BenchmarkGone") {
+		t.Error("Expected removed benchmark to be listed only in its own section")
+	}
+
+	htmlFile := filepath.Join(tempDir, "report.html")
+	if err := e.ToHTML(comparisons, nil, "old", "new", "time1", "time2", htmlFile); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	html, err := os.ReadFile(htmlFile)
+	if err != nil {
+		t.Fatalf("Failed to read HTML file: %v", err)
+	}
+	for _, want := range []string{"Added Benchmarks", "BenchmarkNew", "Removed Benchmarks", "BenchmarkGone"} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("Expected HTML to contain %q", want)
+		}
+	}
+}
+
 func TestCountStatus(t *testing.T) {
 	comparisons := []models.Comparison{
 		{Status: "improved"},
@@ -365,6 +502,186 @@ func TestExportInvalidPath(t *testing.T) {
 	}
 }
 
+func TestToMarkdownDescriptions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "docs.md")
+	comparisons := []models.Comparison{
+		{Name: "Encode/small", Status: "same", Doc: "BenchmarkEncode measures encoding. It allocates once."},
+		{Name: "Encode/large", Status: "same", Doc: "BenchmarkEncode measures encoding. It allocates once."},
+		{Name: "Decode", Status: "same"},
+	}
+
+	if err := NewExporter().ToMarkdown(comparisons, "old-id", "new-id", filename); err != nil {
+		t.Fatalf("ToMarkdown failed: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+	if !strings.Contains(string(content), "## Benchmark Descriptions\n\n- **Encode**: BenchmarkEncode measures encoding.\n\n") {
+		t.Errorf("Expected one description for Encode, got:\n%s", content)
+	}
+}
+
+func TestExportMemoryChanges(t *testing.T) {
+	tempDir := t.TempDir()
+	comparisons := []models.Comparison{
+		{
+			Name: "Parse", OldNsPerOp: 100, NewNsPerOp: 101, DeltaPercent: 1, Status: "same",
+			BytesPerOp:  &models.MetricComparison{Name: "B/op", Old: 100, New: 150, Delta: 50, DeltaPercent: 50, Status: "degraded"},
+			AllocsPerOp: &models.MetricComparison{Name: "allocs/op", Old: 2, New: 2, Status: "same"},
+		},
+	}
+	e := NewExporter()
+
+	mdFile := filepath.Join(tempDir, "memory.md")
+	if err := e.ToMarkdown(comparisons, "old-id", "new-id", mdFile); err != nil {
+		t.Fatalf("ToMarkdown failed: %v", err)
+	}
+	content, _ := os.ReadFile(mdFile)
+	if !strings.Contains(string(content), "| 🔴 | Parse | B/op | 100.00 | 150.00 | +50.00% |") {
+		t.Errorf("Expected the B/op regression, got:\n%s", content)
+	}
+	if strings.Contains(string(content), "| allocs/op |") {
+		t.Errorf("Unchanged allocs/op should not be listed:\n%s", content)
+	}
+
+	csvFile := filepath.Join(tempDir, "memory.csv")
+	if err := e.ToCSV(comparisons, csvFile); err != nil {
+		t.Fatalf("ToCSV failed: %v", err)
+	}
+	content, _ = os.ReadFile(csvFile)
+	if !strings.Contains(string(content), "Old B/op,New B/op,B/op Status") || !strings.Contains(string(content), "100.00,150.00,degraded,2.00,2.00,same,,,") {
+		t.Errorf("Unexpected CSV:\n%s", content)
+	}
+
+	htmlFile := filepath.Join(tempDir, "memory.html")
+	if err := e.ToHTML(comparisons, nil, "old-id", "new-id", "old", "new", htmlFile); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, _ = os.ReadFile(htmlFile)
+	if !strings.Contains(string(content), "Memory and Throughput Changes") || !strings.Contains(string(content), "<td>B/op</td>") {
+		t.Error("Expected the memory changes in the HTML report")
+	}
+}
+
+func TestToMarkdownSummary(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "summary.md")
+	comparisons := []models.Comparison{
+		{Name: "A", OldNsPerOp: 100, NewNsPerOp: 90, DeltaPercent: -10, Status: "improved"},
+		{Name: "B", OldNsPerOp: 200, NewNsPerOp: 240, DeltaPercent: 20, Status: "degraded"},
+		{Name: "C", Status: "same"},
+	}
+
+	if err := NewExporter().ToMarkdownSummary(comparisons, "old-id", "new-id", filename); err != nil {
+		t.Fatalf("ToMarkdownSummary failed: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+	want := "**Benchmarks** `old-id` → `new-id`: 1 improved, 1 degraded, 1 unchanged\n" +
+		"\n**Top regressions**\n- 🔴 `B` +20.00% (200.00 → 240.00 ns/op)\n" +
+		"\n**Top improvements**\n- 🟢 `A` -10.00% (100.00 → 90.00 ns/op)\n"
+	if string(content) != want {
+		t.Errorf("Summary = %q, want %q", content, want)
+	}
+}
+
+func TestEnvironmentDifferencesBanner(t *testing.T) {
+	dir := t.TempDir()
+	comparisons := []models.Comparison{{Name: "A", OldNsPerOp: 100, NewNsPerOp: 90, DeltaPercent: -10, Status: "improved"}}
+	exporter := NewExporter()
+	exporter.SetEnvironmentDifferences([]string{"Go version differs: go1.23.0 → go1.24.0", "CPU model: Intel → AMD"})
+
+	htmlFile := filepath.Join(dir, "report.html")
+	if err := exporter.ToHTML(comparisons, nil, "old-id", "new-id", "", "", htmlFile); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, _ := os.ReadFile(htmlFile)
+	for _, want := range []string{`class="environment-warning"`, "Environments differ", "<li>Go version differs: go1.23.0 → go1.24.0</li>", "<li>CPU model: Intel → AMD</li>"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("HTML report missing %q", want)
+		}
+	}
+
+	mdFile := filepath.Join(dir, "report.md")
+	if err := exporter.ToMarkdown(comparisons, "old-id", "new-id", mdFile); err != nil {
+		t.Fatalf("ToMarkdown failed: %v", err)
+	}
+	content, _ = os.ReadFile(mdFile)
+	if !strings.Contains(string(content), "> ⚠️ **Environments differ**") || !strings.Contains(string(content), "> - CPU model: Intel → AMD\n") {
+		t.Errorf("Markdown report missing the environment warning:\n%s", content)
+	}
+
+	// Runs of the same environment get no banner
+	if err := NewExporter().ToHTML(comparisons, nil, "old-id", "new-id", "", "", htmlFile); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, _ = os.ReadFile(htmlFile)
+	if strings.Contains(string(content), `class="environment-warning"`) {
+		t.Error("HTML report of the same environment has the environment warning")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	comparisons := []models.Comparison{{Name: "A", OldNsPerOp: 100, NewNsPerOp: 90, DeltaPercent: -10, Status: "improved"}}
+	exporter := NewExporter()
+	exporter.SetAnnotations(nil, []models.Annotation{{Kind: "upgrade", Message: "upgraded Go to <1.22>"}})
+
+	htmlFile := filepath.Join(dir, "report.html")
+	if err := exporter.ToHTML(comparisons, nil, "old-id", "new-id", "", "", htmlFile); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, _ := os.ReadFile(htmlFile)
+	if !strings.Contains(string(content), "<strong>📝 new-id:</strong>") || !strings.Contains(string(content), "[upgrade] upgraded Go to &lt;1.22&gt;") {
+		t.Errorf("HTML report missing the escaped annotation")
+	}
+
+	mdFile := filepath.Join(dir, "report.md")
+	if err := exporter.ToMarkdown(comparisons, "old-id", "new-id", mdFile); err != nil {
+		t.Fatalf("ToMarkdown failed: %v", err)
+	}
+	content, _ = os.ReadFile(mdFile)
+	if !strings.Contains(string(content), "**Annotations**\n\n- `new-id`: [upgrade] upgraded Go to <1.22>\n") {
+		t.Errorf("Markdown report missing the annotation:\n%s", content)
+	}
+}
+
+func TestToHTMLTableControls(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "controls.html")
+	comparisons := []models.Comparison{
+		{Name: "Slower", DeltaPercent: 20, Status: "degraded"},
+		{Name: "Allocates", Status: "same", AllocsPerOp: &models.MetricComparison{Name: "allocs/op", New: 1, Delta: 1, Status: "degraded"}},
+		{Name: "Stable", Status: "same"},
+	}
+
+	if err := NewExporter().ToHTML(comparisons, nil, "old-id", "new-id", "old", "new", filename); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+	html := string(content)
+
+	for _, want := range []string{`id="benchmarkSearch"`, `id="regressionsOnly"`, `id="pageSize"`, `data-sort="percent"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected HTML to contain %q", want)
+		}
+	}
+	// Memory regressions count as regressions for the filter
+	if got := strings.Count(html, "data-regression>"); got != 2 {
+		t.Errorf("Expected 2 rows marked as regressions, got %d", got)
+	}
+}
+
+func TestToHTMLDocTooltip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "docs.html")
+	comparisons := []models.Comparison{
+		{Name: "Encode", OldNsPerOp: 100, NewNsPerOp: 100, Status: "same", Doc: `Encode "quoted" <doc>`},
+	}
+
+	if err := NewExporter().ToHTML(comparisons, nil, "old-id", "new-id", "t1", "t2", filename); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+	if !strings.Contains(string(content), `title="Encode &#34;quoted&#34; &lt;doc&gt;"`) {
+		t.Errorf("Expected the doc as an escaped tooltip")
+	}
+}
+
 func TestToMarkdownSpecialCharacters(t *testing.T) {
 	e := NewExporter()
 	tempDir := t.TempDir()
@@ -396,3 +713,293 @@ func TestToMarkdownSpecialCharacters(t *testing.T) {
 		t.Error("Expected benchmark name with pipe character")
 	}
 }
+
+func TestExportNormalizedUnit(t *testing.T) {
+	e := NewExporter()
+	tempDir := t.TempDir()
+
+	comparisons := []models.Comparison{
+		{Name: "Parse", OldNsPerOp: 3, NewNsPerOp: 4, Status: "degraded", Unit: "x ref"},
+	}
+
+	csvFile := filepath.Join(tempDir, "normalized.csv")
+	mdFile := filepath.Join(tempDir, "normalized.md")
+	htmlFile := filepath.Join(tempDir, "normalized.html")
+	if err := e.ToCSV(comparisons, csvFile); err != nil {
+		t.Fatalf("ToCSV failed: %v", err)
+	}
+	if err := e.ToMarkdown(comparisons, "old", "new", mdFile); err != nil {
+		t.Fatalf("ToMarkdown failed: %v", err)
+	}
+	if err := e.ToHTML(comparisons, nil, "old", "new", "", "", htmlFile); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+
+	for _, file := range []string{csvFile, mdFile, htmlFile} {
+		content, _ := os.ReadFile(file)
+		if !strings.Contains(string(content), "Old (x ref)") || strings.Contains(string(content), "ns/op") {
+			t.Errorf("%s does not use the normalized unit", filepath.Base(file))
+		}
+	}
+}
+
+func TestScoreBadge(t *testing.T) {
+	tests := []struct {
+		score, previous float64
+		want, color     string
+	}{
+		{2.5e6, 2e6, "2.50M ops/s ▲25.0%", badgeGreen},
+		{2e6, 2.5e6, "2.00M ops/s ▼20.0%", badgeRed},
+		{2e6, 2.001e6, "2.00M ops/s<", badgeBlue},
+		{2e6, 0, "2.00M ops/s<", badgeBlue},
+		{0, 0, "no data", badgeGrey},
+	}
+
+	for _, tt := range tests {
+		badge := ScoreBadge(tt.score, tt.previous)
+		if !strings.HasPrefix(badge, "<svg") || !strings.Contains(badge, tt.want) || !strings.Contains(badge, tt.color) {
+			t.Errorf("ScoreBadge(%v, %v) missing %q in %s color: %s", tt.score, tt.previous, tt.want, tt.color, badge)
+		}
+	}
+}
+
+func TestToBadge(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "score.svg")
+	if err := NewExporter().ToBadge(1500, 0, file); err != nil {
+		t.Fatalf("ToBadge failed: %v", err)
+	}
+	content, _ := os.ReadFile(file)
+	if !strings.Contains(string(content), "1.50k ops/s") {
+		t.Errorf("badge does not show the score: %s", content)
+	}
+}
+
+func TestToNotebook(t *testing.T) {
+	oldRun := &models.BenchmarkRun{
+		ID:      "run-1",
+		Results: []models.BenchmarkResult{{Name: "BenchmarkIt's", NsPerOp: 100}},
+	}
+	newRun := &models.BenchmarkRun{
+		ID:      "run-2",
+		Results: []models.BenchmarkResult{{Name: "BenchmarkIt's", NsPerOp: 90}},
+	}
+	comparisons := []models.Comparison{
+		{Name: "BenchmarkIt's", OldNsPerOp: 100, NewNsPerOp: 90, Delta: -10, DeltaPercent: -10, Status: "improved"},
+	}
+
+	file := filepath.Join(t.TempDir(), "comparison.ipynb")
+	if err := NewExporter().ToNotebook(oldRun, newRun, comparisons, file); err != nil {
+		t.Fatalf("ToNotebook failed: %v", err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("notebook not written: %v", err)
+	}
+	var nb struct {
+		Cells []struct {
+			CellType string   `json:"cell_type"`
+			Source   []string `json:"source"`
+		} `json:"cells"`
+		NBFormat int `json:"nbformat"`
+	}
+	if err := json.Unmarshal(content, &nb); err != nil {
+		t.Fatalf("notebook is not valid JSON: %v", err)
+	}
+	if nb.NBFormat != 4 || len(nb.Cells) < 2 {
+		t.Fatalf("unexpected notebook: nbformat %d with %d cells", nb.NBFormat, len(nb.Cells))
+	}
+
+	// The embedded data must parse as JSON once taken out of the Python literal
+	source := strings.Join(nb.Cells[1].Source, "")
+	start := strings.Index(source, "r'''") + len("r'''")
+	end := strings.LastIndex(source, "'''")
+	var data notebookData
+	if err := json.Unmarshal([]byte(source[start:end]), &data); err != nil {
+		t.Fatalf("embedded data is not valid JSON: %v", err)
+	}
+	if len(data.Runs) != 2 || data.Runs[0].Results[0].Name != "BenchmarkIt's" || len(data.Comparisons) != 1 {
+		t.Errorf("unexpected embedded data: %+v", data)
+	}
+	if !strings.Contains(string(content), "import pandas as pd") {
+		t.Error("notebook is missing the pandas starter cells")
+	}
+}
+
+func TestHistoryRows(t *testing.T) {
+	runs := []models.BenchmarkRun{
+		{
+			ID:        "run-2",
+			Timestamp: time.Unix(20, 0),
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkA", NsPerOp: 90, BytesPerOp: 64, AllocsPerOp: 2, Metrics: map[string]float64{"hits": 3}},
+				{Name: "BenchmarkB", Status: "skipped"},
+			},
+		},
+		{
+			ID:        "run-1",
+			Timestamp: time.Unix(10, 0),
+			Results:   []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: 100, MBPerSec: 5}},
+		},
+	}
+
+	want := []HistoryRow{
+		{"run-1", 10e6, "BenchmarkA", "ns/op", 100},
+		{"run-1", 10e6, "BenchmarkA", "MB/s", 5},
+		{"run-2", 20e6, "BenchmarkA", "ns/op", 90},
+		{"run-2", 20e6, "BenchmarkA", "B/op", 64},
+		{"run-2", 20e6, "BenchmarkA", "allocs/op", 2},
+		{"run-2", 20e6, "BenchmarkA", "hits", 3},
+	}
+	if got := HistoryRows(runs); !reflect.DeepEqual(got, want) {
+		t.Errorf("HistoryRows() = %+v, want %+v", got, want)
+	}
+}
+
+func TestToParquet(t *testing.T) {
+	runs := []models.BenchmarkRun{{
+		ID:        "run-1",
+		Timestamp: time.Unix(10, 0),
+		Results:   []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: 100}},
+	}}
+
+	file := filepath.Join(t.TempDir(), "history.parquet")
+	if err := NewExporter().ToParquet(runs, file); err != nil {
+		t.Fatalf("ToParquet failed: %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("parquet file not written: %v", err)
+	}
+
+	if !bytes.HasPrefix(content, []byte("PAR1")) || !bytes.HasSuffix(content, []byte("PAR1")) {
+		t.Fatal("missing parquet magic bytes")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(content[len(content)-8:]))
+	if footerLen <= 0 || footerLen > len(content)-12 {
+		t.Fatalf("invalid footer length %d", footerLen)
+	}
+	footer := content[len(content)-8-footerLen : len(content)-8]
+	for _, column := range []string{"run_id", "ts", "benchmark", "metric", "value"} {
+		if !bytes.Contains(footer, []byte(column)) {
+			t.Errorf("footer is missing column %s", column)
+		}
+	}
+
+	// PLAIN encoded values follow each page header
+	value := binary.LittleEndian.AppendUint64(nil, math.Float64bits(100))
+	name := append(binary.LittleEndian.AppendUint32(nil, uint32(len("BenchmarkA"))), "BenchmarkA"...)
+	if !bytes.Contains(content, value) || !bytes.Contains(content, name) {
+		t.Error("column data is not PLAIN encoded")
+	}
+}
+
+func TestThriftWriter(t *testing.T) {
+	w := newThriftWriter()
+	w.i32(1, 3)        // short header: delta 1, type i32, zigzag 6
+	w.i64(20, -1)      // long header: type i64, zigzag id 40, zigzag value 1
+	w.string(21, "ab") // delta 1, binary
+	w.structField(22, func() {
+		w.bool(1, true)
+	})
+
+	want := []byte{0x15, 0x06, 0x06, 0x28, 0x01, 0x18, 0x02, 'a', 'b', 0x1c, 0x11, 0x00, 0x00}
+	if got := w.bytes(); !bytes.Equal(got, want) {
+		t.Errorf("encoding = % x, want % x", got, want)
+	}
+}
+
+func TestDeterministicExport(t *testing.T) {
+	dir := t.TempDir()
+	comparisons := []models.Comparison{
+		{Name: "B", OldNsPerOp: 200.123, NewNsPerOp: 240.456, Delta: 40.333, DeltaPercent: 20.154, Status: "degraded",
+			BytesPerOp: &models.MetricComparison{Name: "B/op", Old: 1023.7, New: 1024.2, Status: "same"}},
+		{Name: "A", OldNsPerOp: 100.04, NewNsPerOp: 99.96, Delta: -0.08, DeltaPercent: -0.0004, Status: "same"},
+	}
+	exporter := NewExporter()
+	exporter.SetSorted(true)
+	exporter.SetRounding(3)
+
+	filename := filepath.Join(dir, "comparison.csv")
+	if err := exporter.ToCSV(comparisons, filename); err != nil {
+		t.Fatalf("ToCSV failed: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	want := []string{
+		"A,100.00,100.00,-0.08,-0.00,same,,,,,,,,,,",
+		"B,200.00,240.00,40.30,20.20,degraded,1020.00,1020.00,same,,,,,,,",
+	}
+	if len(lines) != 3 || lines[1] != want[0] || lines[2] != want[1] {
+		t.Errorf("CSV rows = %q, want %q", lines[1:], want)
+	}
+
+	// The comparisons given are left as they were
+	if comparisons[0].Name != "B" || comparisons[0].OldNsPerOp != 200.123 || comparisons[0].BytesPerOp.Old != 1023.7 {
+		t.Errorf("Comparisons were modified: %+v", comparisons[0])
+	}
+
+	// Labels without timestamps leave no parentheses behind
+	filename = filepath.Join(dir, "comparison.html")
+	if err := exporter.ToHTML(comparisons, nil, "old", "new", "", "", filename); err != nil {
+		t.Fatalf("ToHTML failed: %v", err)
+	}
+	content, _ = os.ReadFile(filename)
+	html := string(content)
+	a, b := strings.Index(html, `data-name="A"`), strings.Index(html, `data-name="B"`)
+	if !strings.Contains(html, "<span>old</span>") || a < 0 || b < a || !strings.Contains(html, `data-old="200"`) {
+		t.Errorf("HTML report is not deterministic")
+	}
+}
+
+func TestRoundSignificant(t *testing.T) {
+	for _, tt := range []struct {
+		v      float64
+		digits int
+		want   float64
+	}{
+		{123456, 3, 123000},
+		{0.0012345, 2, 0.0012},
+		{-98.76, 3, -98.8},
+		{0, 3, 0},
+		{-0.0001, 1, -0.0001},
+	} {
+		if got := roundSignificant(tt.v, tt.digits); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("roundSignificant(%v, %d) = %v, want %v", tt.v, tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestToBenchstat(t *testing.T) {
+	pValue, noise := 0.001, 0.4
+	comparisons := []models.Comparison{
+		{Name: "BenchmarkDecode-8", OldNsPerOp: 1234, NewNsPerOp: 1100, DeltaPercent: -10.86, Status: "improved",
+			OldCI: 24, NewCI: 11, OldSamples: 10, NewSamples: 10, PValue: &pValue,
+			BytesPerOp:  &models.MetricComparison{Old: 4096, New: 4096},
+			AllocsPerOp: &models.MetricComparison{Old: 12, New: 10, DeltaPercent: -16.67}},
+		{Name: "BenchmarkEncode-8", OldNsPerOp: 2.5e6, NewNsPerOp: 2.52e6, DeltaPercent: 0.8, Status: "same",
+			OldCI: 5e4, NewCI: 5e4, OldSamples: 10, NewSamples: 10, PValue: &noise},
+		{Name: "BenchmarkParse", OldNsPerOp: 50, NewNsPerOp: 60, DeltaPercent: 20, Status: "degraded"},
+		{Name: "BenchmarkNew", NewNsPerOp: 10, Status: models.StatusAdded},
+	}
+
+	filename := filepath.Join(t.TempDir(), "comparison.txt")
+	if err := NewExporter().ToBenchstat(comparisons, filename); err != nil {
+		t.Fatalf("ToBenchstat failed: %v", err)
+	}
+	content, _ := os.ReadFile(filename)
+	want := `name      old time/op  new time/op  delta
+Decode-8  1.23µs ± 2%  1.10µs ± 1%  -10.86%  (p=0.001 n=10+10)
+Encode-8  2.50ms ± 2%  2.52ms ± 2%        ~  (p=0.400 n=10+10)
+Parse     50.0ns       60.0ns       +20.00%
+
+name      old alloc/op  new alloc/op  delta
+Decode-8        4.10kB        4.10kB      ~  (all equal)
+
+name      old allocs/op  new allocs/op  delta
+Decode-8             12             10  -16.67%
+`
+	if string(content) != want {
+		t.Errorf("Benchstat export =\n%s\nwant\n%s", content, want)
+	}
+}
@@ -0,0 +1,149 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// notebook is a Jupyter notebook in nbformat 4. Cells are maps because
+// code cells require keys, such as a null execution_count, that markdown
+// cells must not have.
+type notebook struct {
+	Cells         []map[string]any `json:"cells"`
+	Metadata      map[string]any   `json:"metadata"`
+	NBFormat      int              `json:"nbformat"`
+	NBFormatMinor int              `json:"nbformat_minor"`
+}
+
+// notebookData is the data embedded in the notebook
+type notebookData struct {
+	Runs        []*models.BenchmarkRun `json:"runs"`
+	Comparisons []models.Comparison    `json:"comparisons"`
+}
+
+// ToNotebook exports two runs and their comparisons to a Jupyter notebook.
+// The data is embedded as JSON, followed by starter cells loading it into
+// pandas DataFrames and plotting it with matplotlib.
+func (e *Exporter) ToNotebook(oldRun, newRun *models.BenchmarkRun, comparisons []models.Comparison, filename string) error {
+	nb, err := buildNotebook(oldRun, newRun, comparisons)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(nb, "", " ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notebook: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write notebook: %w", err)
+	}
+	return nil
+}
+
+// buildNotebook builds the cells of a comparison notebook
+func buildNotebook(oldRun, newRun *models.BenchmarkRun, comparisons []models.Comparison) (*notebook, error) {
+	data, err := json.MarshalIndent(notebookData{
+		Runs:        []*models.BenchmarkRun{oldRun, newRun},
+		Comparisons: comparisons,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run data: %w", err)
+	}
+	// Single quotes only occur inside JSON strings, where \u0027 is an
+	// equivalent escape; using it keeps the raw ''' string literal intact
+	embedded := strings.ReplaceAll(string(data), "'", `\u0027`)
+
+	nb := &notebook{
+		Metadata: map[string]any{
+			"kernelspec": map[string]string{
+				"display_name": "Python 3",
+				"language":     "python",
+				"name":         "python3",
+			},
+			"language_info": map[string]string{"name": "python"},
+		},
+		NBFormat:      4,
+		NBFormatMinor: 5,
+	}
+
+	nb.addMarkdown(fmt.Sprintf(`# Benchmark comparison: %s vs %s
+
+| | Run | Timestamp | Go version |
+|---|---|---|---|
+| Old | %s | %s | %s |
+| New | %s | %s | %s |
+
+Exported by gokanon. The first code cell holds the raw run data; the
+cells below load it into pandas DataFrames as starting points.`,
+		oldRun.ID, newRun.ID,
+		oldRun.ID, oldRun.Timestamp.Format("2006-01-02 15:04:05"), oldRun.GoVersion,
+		newRun.ID, newRun.Timestamp.Format("2006-01-02 15:04:05"), newRun.GoVersion,
+	))
+	nb.addCode(`import json
+
+import matplotlib.pyplot as plt
+import pandas as pd
+
+data = json.loads(r'''` + embedded + `''')`)
+	nb.addMarkdown("## Results\n\nOne row per benchmark result and run.")
+	nb.addCode(`results = pd.DataFrame(
+    [
+        {"run": run["id"], "timestamp": run["timestamp"], **result}
+        for run in data["runs"]
+        for result in run["results"]
+    ]
+)
+results`)
+	nb.addMarkdown("## Comparisons\n\nOne row per benchmark, sorted by relative change. Negative deltas are faster.")
+	nb.addCode(`comparisons = pd.DataFrame(data["comparisons"])
+comparisons.sort_values("delta_percent")`)
+	nb.addCode(`changes = comparisons.set_index("name")["delta_percent"].sort_values()
+ax = changes.plot.barh(
+    figsize=(8, max(2, 0.4 * len(changes))),
+    color=["tab:green" if v < 0 else "tab:red" for v in changes],
+)
+ax.axvline(0, color="grey", linewidth=0.8)
+ax.set_xlabel("Change (%)")
+ax.set_title("Relative change per benchmark")
+plt.tight_layout()
+plt.show()`)
+	nb.addCode(`ns_per_op = results.pivot_table(index="name", columns="run", values="ns_per_op")
+ax = ns_per_op.plot.bar(figsize=(10, 4))
+ax.set_ylabel("ns/op")
+ax.set_title("ns/op per run")
+plt.tight_layout()
+plt.show()`)
+	return nb, nil
+}
+
+// addMarkdown appends a markdown cell
+func (nb *notebook) addMarkdown(source string) {
+	nb.Cells = append(nb.Cells, map[string]any{
+		"id":        fmt.Sprintf("cell-%d", len(nb.Cells)+1),
+		"cell_type": "markdown",
+		"metadata":  map[string]any{},
+		"source":    sourceLines(source),
+	})
+}
+
+// addCode appends a code cell that has not been executed
+func (nb *notebook) addCode(source string) {
+	nb.Cells = append(nb.Cells, map[string]any{
+		"id":              fmt.Sprintf("cell-%d", len(nb.Cells)+1),
+		"cell_type":       "code",
+		"metadata":        map[string]any{},
+		"source":          sourceLines(source),
+		"outputs":         []any{},
+		"execution_count": nil,
+	})
+}
+
+// sourceLines splits cell source into lines, each but the last keeping its
+// newline, as notebooks store it
+func sourceLines(source string) []string {
+	return strings.SplitAfter(source, "\n")
+}
@@ -0,0 +1,256 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// HistoryRow is one measurement of the long-format result history: the
+// value of one metric of one benchmark in one run
+type HistoryRow struct {
+	RunID     string
+	Timestamp int64 // Microseconds since the Unix epoch, UTC
+	Benchmark string
+	Metric    string
+	Value     float64
+}
+
+// HistoryRows flattens runs into one row per run, benchmark and metric,
+// oldest run first. Failed and skipped benchmarks are left out; memory and
+// throughput metrics only appear when they were measured.
+func HistoryRows(runs []models.BenchmarkRun) []HistoryRow {
+	sorted := make([]models.BenchmarkRun, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var rows []HistoryRow
+	for _, run := range sorted {
+		ts := run.Timestamp.UnixMicro()
+		for _, result := range run.Results {
+			if !result.Measured() {
+				continue
+			}
+			add := func(metric string, value float64) {
+				rows = append(rows, HistoryRow{run.ID, ts, result.Name, metric, value})
+			}
+
+			add("ns/op", result.NsPerOp)
+			if result.BytesPerOp != 0 || result.AllocsPerOp != 0 {
+				add("B/op", float64(result.BytesPerOp))
+				add("allocs/op", float64(result.AllocsPerOp))
+			}
+			if result.MBPerSec != 0 {
+				add("MB/s", result.MBPerSec)
+			}
+			metrics := make([]string, 0, len(result.Metrics))
+			for metric := range result.Metrics {
+				metrics = append(metrics, metric)
+			}
+			sort.Strings(metrics)
+			for _, metric := range metrics {
+				add(metric, result.Metrics[metric])
+			}
+		}
+	}
+	return rows
+}
+
+// ToParquet exports the result history of runs to a Parquet file with the
+// columns run_id, ts, benchmark, metric and value, ready to be loaded into
+// a data warehouse
+func (e *Exporter) ToParquet(runs []models.BenchmarkRun, filename string) error {
+	if err := os.WriteFile(filename, encodeParquet(HistoryRows(runs)), 0644); err != nil {
+		return fmt.Errorf("failed to write parquet file: %w", err)
+	}
+	return nil
+}
+
+// parquetRowGroupSize is the maximum number of rows per row group
+const parquetRowGroupSize = 1 << 16
+
+// Parquet enum values, from parquet.thrift
+const (
+	parquetInt64     = 2 // Type
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetRequired = 0 // FieldRepetitionType
+
+	parquetUTF8            = 0 // ConvertedType
+	parquetTimestampMicros = 10
+
+	parquetPlain = 0 // Encoding
+	parquetRLE   = 3
+
+	parquetUncompressed = 0 // CompressionCodec
+	parquetDataPage     = 0 // PageType
+)
+
+// parquetColumn describes a required, flat column
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	logicalType   func(w *thriftWriter) // Writes the LogicalType union
+	encode        func(buf *bytes.Buffer, row HistoryRow)
+}
+
+// parquetColumns are the columns of a history file
+var parquetColumns = []parquetColumn{
+	{
+		name: "run_id", physicalType: parquetByteArray, convertedType: parquetUTF8,
+		logicalType: stringLogicalType,
+		encode:      func(buf *bytes.Buffer, row HistoryRow) { plainByteArray(buf, row.RunID) },
+	},
+	{
+		name: "ts", physicalType: parquetInt64, convertedType: parquetTimestampMicros,
+		logicalType: func(w *thriftWriter) {
+			w.structField(8, func() { // TIMESTAMP
+				w.bool(1, true)           // isAdjustedToUTC
+				w.structField(2, func() { // unit
+					w.structField(2, func() {}) // MICROS
+				})
+			})
+		},
+		encode: func(buf *bytes.Buffer, row HistoryRow) {
+			buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(row.Timestamp)))
+		},
+	},
+	{
+		name: "benchmark", physicalType: parquetByteArray, convertedType: parquetUTF8,
+		logicalType: stringLogicalType,
+		encode:      func(buf *bytes.Buffer, row HistoryRow) { plainByteArray(buf, row.Benchmark) },
+	},
+	{
+		name: "metric", physicalType: parquetByteArray, convertedType: parquetUTF8,
+		logicalType: stringLogicalType,
+		encode:      func(buf *bytes.Buffer, row HistoryRow) { plainByteArray(buf, row.Metric) },
+	},
+	{
+		name: "value", physicalType: parquetDouble, convertedType: -1,
+		encode: func(buf *bytes.Buffer, row HistoryRow) {
+			buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(row.Value)))
+		},
+	},
+}
+
+func stringLogicalType(w *thriftWriter) {
+	w.structField(1, func() {}) // STRING
+}
+
+// plainByteArray appends a PLAIN encoded byte array: its length and bytes
+func plainByteArray(buf *bytes.Buffer, s string) {
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+	buf.WriteString(s)
+}
+
+// parquetChunk locates a column chunk in the file
+type parquetChunk struct {
+	offset int64
+	size   int64
+	rows   int
+}
+
+// encodeParquet encodes rows as an uncompressed Parquet file with PLAIN
+// encoded, required columns and one data page per column chunk
+func encodeParquet(rows []HistoryRow) []byte {
+	var out bytes.Buffer
+	out.WriteString("PAR1")
+
+	var rowGroups [][]parquetChunk
+	for start := 0; start < len(rows); start += parquetRowGroupSize {
+		group := rows[start:min(start+parquetRowGroupSize, len(rows))]
+		chunks := make([]parquetChunk, len(parquetColumns))
+		for i, col := range parquetColumns {
+			var data bytes.Buffer
+			for _, row := range group {
+				col.encode(&data, row)
+			}
+
+			// Required columns of a flat schema have no repetition or
+			// definition levels, so the page holds just the values
+			header := newThriftWriter()
+			header.i32(1, parquetDataPage)
+			header.i32(2, int32(data.Len()))
+			header.i32(3, int32(data.Len()))
+			header.structField(5, func() {
+				header.i32(1, int32(len(group)))
+				header.i32(2, parquetPlain)
+				header.i32(3, parquetRLE)
+				header.i32(4, parquetRLE)
+			})
+			headerBytes := header.bytes()
+
+			chunks[i] = parquetChunk{
+				offset: int64(out.Len()),
+				size:   int64(len(headerBytes) + data.Len()),
+				rows:   len(group),
+			}
+			out.Write(headerBytes)
+			out.Write(data.Bytes())
+		}
+		rowGroups = append(rowGroups, chunks)
+	}
+
+	footer := parquetFileMetaData(rowGroups, len(rows))
+	out.Write(footer)
+	out.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	out.WriteString("PAR1")
+	return out.Bytes()
+}
+
+// parquetFileMetaData encodes the file footer describing the schema and
+// the location of every column chunk
+func parquetFileMetaData(rowGroups [][]parquetChunk, numRows int) []byte {
+	w := newThriftWriter()
+	w.i32(1, 1) // version
+	w.structList(2, len(parquetColumns)+1, func(i int) {
+		if i == 0 {
+			w.string(4, "schema")
+			w.i32(5, int32(len(parquetColumns)))
+			return
+		}
+		col := parquetColumns[i-1]
+		w.i32(1, col.physicalType)
+		w.i32(3, parquetRequired)
+		w.string(4, col.name)
+		if col.convertedType >= 0 {
+			w.i32(6, col.convertedType)
+		}
+		if col.logicalType != nil {
+			w.structField(10, func() { col.logicalType(w) })
+		}
+	})
+	w.i64(3, int64(numRows))
+	w.structList(4, len(rowGroups), func(g int) {
+		chunks := rowGroups[g]
+		var groupSize int64
+		w.structList(1, len(chunks), func(i int) {
+			col, chunk := parquetColumns[i], chunks[i]
+			groupSize += chunk.size
+			w.i64(2, chunk.offset) // file_offset
+			w.structField(3, func() {
+				w.i32(1, col.physicalType)
+				w.i32List(2, parquetPlain, parquetRLE)
+				w.stringList(3, col.name)
+				w.i32(4, parquetUncompressed)
+				w.i64(5, int64(chunk.rows))
+				w.i64(6, chunk.size)
+				w.i64(7, chunk.size)
+				w.i64(9, chunk.offset) // data_page_offset
+			})
+		})
+		w.i64(2, groupSize)
+		w.i64(3, int64(chunks[0].rows))
+	})
+	w.string(6, "gokanon")
+	return w.bytes()
+}
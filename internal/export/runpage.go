@@ -0,0 +1,391 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// runPageResult is a row of the results table of a run page
+type runPageResult struct {
+	models.BenchmarkResult
+	Failed  bool
+	Metrics string // Custom metrics as "value unit" pairs
+}
+
+// ToRunHTML exports a single run as a self-contained HTML page, with its
+// metadata, results, profile summary and optimization suggestions. The
+// page loads nothing from the network, so it can be attached to a ticket.
+func (e *Exporter) ToRunHTML(run *models.BenchmarkRun, timestamp string, filename string) error {
+	t, err := parseTemplate("run", runPageTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var results []runPageResult
+	for _, r := range run.Results {
+		results = append(results, runPageResult{
+			BenchmarkResult: r,
+			Failed:          r.Status == models.StatusFailed,
+			Metrics:         formatMetrics(r.Metrics),
+		})
+	}
+
+	data := struct {
+		Run       *models.BenchmarkRun
+		Timestamp string
+		Duration  string
+		Results   []runPageResult
+		Summary   *models.ProfileSummary
+		Passed    int
+		Failed    int
+		Skipped   int
+	}{
+		Run:       run,
+		Timestamp: timestamp,
+		Duration:  run.Duration.Round(time.Millisecond).String(),
+		Results:   results,
+		Summary:   run.ProfileSummary,
+		Passed:    run.CountStatus(models.StatusOK),
+		Failed:    run.CountStatus(models.StatusFailed),
+		Skipped:   run.CountStatus(models.StatusSkipped),
+	}
+	if data.Summary == nil {
+		data.Summary = &models.ProfileSummary{}
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create HTML file: %w", err)
+	}
+	defer file.Close()
+
+	return t.Execute(file, data)
+}
+
+// formatMetrics lists custom metrics sorted by unit
+func formatMetrics(metrics map[string]float64) string {
+	units := make([]string, 0, len(metrics))
+	for unit := range metrics {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+
+	parts := make([]string, 0, len(units))
+	for _, unit := range units {
+		parts = append(parts, fmt.Sprintf("%.4g %s", metrics[unit], unit))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// humanBytes formats a number of bytes with a binary unit
+func humanBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+const runPageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Benchmark Run {{.Run.ID}}</title>
+    <style>
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
+        }
+
+        :root {
+            --primary-color: #4f46e5;
+            --success-color: #10b981;
+            --danger-color: #ef4444;
+            --warning-color: #f59e0b;
+            --neutral-color: #6b7280;
+            --card-bg: #ffffff;
+            --text-primary: #111827;
+            --text-secondary: #6b7280;
+            --border-color: #e5e7eb;
+            --shadow: 0 4px 6px -1px rgba(0, 0, 0, 0.1), 0 2px 4px -1px rgba(0, 0, 0, 0.06);
+        }
+
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'Roboto', 'Oxygen', 'Ubuntu', 'Cantarell', sans-serif;
+            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            min-height: 100vh;
+            padding: 20px;
+            color: var(--text-primary);
+        }
+
+        .container {
+            max-width: 1400px;
+            margin: 0 auto;
+        }
+
+        header, .card {
+            background: var(--card-bg);
+            border-radius: 16px;
+            padding: 30px;
+            margin-bottom: 30px;
+            box-shadow: var(--shadow);
+        }
+
+        h1 {
+            font-size: 2rem;
+            font-weight: 800;
+            margin-bottom: 10px;
+            word-break: break-all;
+        }
+
+        .card h2 {
+            font-size: 1.25rem;
+            margin-bottom: 15px;
+        }
+
+        .metadata {
+            display: grid;
+            grid-template-columns: repeat(auto-fit, minmax(280px, 1fr));
+            gap: 10px 20px;
+            margin-top: 20px;
+        }
+
+        .metadata strong {
+            font-weight: 600;
+        }
+
+        .metadata span, .muted {
+            color: var(--text-secondary);
+            word-break: break-all;
+        }
+
+        table {
+            width: 100%;
+            border-collapse: collapse;
+            background: var(--card-bg);
+            border-radius: 16px;
+            overflow: hidden;
+            box-shadow: var(--shadow);
+            margin-bottom: 30px;
+        }
+
+        .card table {
+            box-shadow: none;
+            margin-bottom: 0;
+        }
+
+        th, td {
+            padding: 12px 16px;
+            text-align: left;
+            border-bottom: 1px solid var(--border-color);
+        }
+
+        th {
+            font-size: 0.8rem;
+            text-transform: uppercase;
+            letter-spacing: 0.5px;
+            color: var(--text-secondary);
+        }
+
+        td.metric {
+            font-family: 'SF Mono', 'Monaco', 'Cascadia Code', monospace;
+        }
+
+        tr.failed td {
+            background-color: #fee2e2;
+        }
+
+        .badge {
+            display: inline-block;
+            padding: 4px 12px;
+            border-radius: 12px;
+            font-size: 0.875rem;
+            font-weight: 600;
+        }
+
+        .badge.same {
+            background-color: #f3f4f6;
+            color: var(--neutral-color);
+        }
+
+` + suggestionsStyle + `
+
+` + gradeStyle + `
+
+        .section-title {
+            color: white;
+            font-size: 1.5rem;
+            font-weight: 700;
+            margin: 30px 0 0;
+        }
+
+        .footer {
+            text-align: center;
+            padding: 40px 20px;
+            color: white;
+            font-size: 0.875rem;
+        }
+
+        .footer a {
+            color: white;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <header>
+            <h1>📦 Benchmark Run {{.Run.ID}}</h1>
+            <p class="muted">{{.Passed}} passed{{if .Failed}}, {{.Failed}} failed{{end}}{{if .Skipped}}, {{.Skipped}} skipped{{end}}</p>
+            <div class="metadata">
+                <div><strong>Date:</strong> <span>{{.Timestamp}}</span></div>
+                <div><strong>Package:</strong> <span>{{.Run.Package}}</span></div>
+                <div><strong>Go version:</strong> <span>{{.Run.GoVersion}}</span></div>
+                <div><strong>Duration:</strong> <span>{{.Duration}}</span></div>
+                {{if .Run.Commit}}<div><strong>Commit:</strong> <span>{{.Run.Commit}}</span></div>{{end}}
+                {{with .Run.Toolchain}}<div><strong>Platform:</strong> <span>{{.GOOS}}/{{.GOARCH}}</span></div>{{end}}
+                {{if .Run.Agent}}<div><strong>Agent:</strong> <span>{{.Run.Agent}}</span></div>{{end}}
+                {{if .Run.Command}}<div><strong>Command:</strong> <span>{{.Run.Command}}</span></div>{{end}}
+                {{range .Run.Annotations}}<div><strong>📝 {{.Time.Format "2006-01-02"}}:</strong> <span>{{.}}</span></div>{{end}}
+            </div>
+        </header>
+
+        <table>
+            <thead>
+                <tr>
+                    <th>Benchmark</th>
+                    <th>Iterations</th>
+                    <th>ns/op</th>
+                    <th>B/op</th>
+                    <th>allocs/op</th>
+                    <th>MB/s</th>
+                    <th>Metrics</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Results}}
+                {{if .Measured}}
+                <tr>
+                    <td{{if .Doc}} title="{{.Doc}}"{{end}}>{{.Name}}{{with .Confidence}} <span class="grade grade-{{.}}" title="Confidence grade">{{.}}</span>{{end}}</td>
+                    <td class="metric">{{.Iterations}}</td>
+                    <td class="metric">{{printf "%.2f" .NsPerOp}}</td>
+                    <td class="metric">{{.BytesPerOp}}</td>
+                    <td class="metric">{{.AllocsPerOp}}</td>
+                    <td class="metric">{{if .MBPerSec}}{{printf "%.2f" .MBPerSec}}{{end}}</td>
+                    <td class="metric">{{.Metrics}}</td>
+                </tr>
+                {{else}}
+                <tr{{if .Failed}} class="failed"{{end}}>
+                    <td{{if .Doc}} title="{{.Doc}}"{{end}}>{{.Name}}</td>
+                    <td colspan="6">{{.Status}}{{if .Message}}: {{.Message}}{{end}}</td>
+                </tr>
+                {{end}}
+                {{end}}
+            </tbody>
+        </table>
+
+        {{with .Summary}}
+        {{if .CPUTopFunctions}}
+        <div class="card">
+            <h2>🔥 CPU Hot Functions{{if .TotalCPUSamples}} <span class="muted">({{.TotalCPUSamples}} samples)</span>{{end}}</h2>
+            <table>
+                <thead><tr><th>Function</th><th>Flat%</th><th>Cum%</th></tr></thead>
+                <tbody>
+                    {{range .CPUTopFunctions}}
+                    <tr><td>{{.Name}}</td><td class="metric">{{printf "%.1f%%" .FlatPercent}}</td><td class="metric">{{printf "%.1f%%" .CumPercent}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .MemoryTopFunctions}}
+        <div class="card">
+            <h2>💾 Memory Allocations{{if .TotalMemoryBytes}} <span class="muted">({{bytes .TotalMemoryBytes}})</span>{{end}}</h2>
+            <table>
+                <thead><tr><th>Function</th><th>Flat%</th><th>Bytes</th></tr></thead>
+                <tbody>
+                    {{range .MemoryTopFunctions}}
+                    <tr><td>{{.Name}}</td><td class="metric">{{printf "%.1f%%" .FlatPercent}}</td><td class="metric">{{bytes .FlatValue}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .MemoryGrowth}}
+        <div class="card">
+            <h2>📈 In-Use Memory Growth After Warmup</h2>
+            <table>
+                <thead><tr><th>Function</th><th>After warmup</th><th>At end</th><th>Growth</th></tr></thead>
+                <tbody>
+                    {{range .MemoryGrowth}}
+                    <tr><td>{{.Function}}</td><td class="metric">{{bytes .WarmupBytes}}</td><td class="metric">{{bytes .FinalBytes}}</td><td class="metric">{{bytes .GrowthBytes}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .MemoryLeaks}}
+        <div class="card">
+            <h2>⚠️ Potential Memory Leaks</h2>
+            <table>
+                <thead><tr><th>Severity</th><th>Function</th><th>Description</th></tr></thead>
+                <tbody>
+                    {{range .MemoryLeaks}}
+                    <tr><td><span class="badge {{.Severity}}">{{.Severity}}</span></td><td>{{.Function}}</td><td>{{.Description}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .HotPaths}}
+        <div class="card">
+            <h2>🛤️ Hot Paths</h2>
+            <table>
+                <thead><tr><th>Share</th><th>Call path</th></tr></thead>
+                <tbody>
+                    {{range .HotPaths}}
+                    <tr><td class="metric">{{printf "%.1f%%" .Percentage}}</td><td>{{join .Path " → "}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{range .CustomProfiles}}
+        <div class="card">
+            <h2>📎 {{.Name}} <span class="muted">({{.SampleType}}, {{.Unit}})</span></h2>
+            <table>
+                <thead><tr><th>Function</th><th>Flat%</th><th>Cum%</th></tr></thead>
+                <tbody>
+                    {{range .TopFunctions}}
+                    <tr><td>{{.Name}}</td><td class="metric">{{printf "%.1f%%" .FlatPercent}}</td><td class="metric">{{printf "%.1f%%" .CumPercent}}</td></tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+        {{end}}
+
+        {{template "suggestions" (suggestionList .Run.ID .Summary.Suggestions)}}
+
+        <div class="footer">
+            <p>Generated by <a href="https://github.com/alenon/gokanon" target="_blank">gokanon</a></p>
+        </div>
+    </div>
+</body>
+</html>`
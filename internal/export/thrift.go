@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type codes
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftStruct    = 12
+)
+
+// thriftWriter encodes Thrift structs with the compact protocol, which
+// Parquet uses for its page headers and file metadata. Fields must be
+// written in increasing id order within each struct.
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // Id of the last field written, per open struct
+}
+
+// newThriftWriter returns a writer with the top-level struct open
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+// bytes closes the top-level struct and returns the encoding
+func (w *thriftWriter) bytes() []byte {
+	w.buf.WriteByte(0)
+	return w.buf.Bytes()
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+// varint writes a zigzag-encoded varint
+func (w *thriftWriter) varint(v int64) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(v<<1)^uint64(v>>63)))
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) bool(id int16, v bool) {
+	if v {
+		w.fieldHeader(id, thriftBoolTrue)
+	} else {
+		w.fieldHeader(id, thriftBoolFalse)
+	}
+}
+
+func (w *thriftWriter) string(id int16, s string) {
+	w.fieldHeader(id, thriftBinary)
+	w.rawString(s)
+}
+
+func (w *thriftWriter) rawString(s string) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	w.buf.WriteString(s)
+}
+
+// structField writes a nested struct whose fields are written by fields
+func (w *thriftWriter) structField(id int16, fields func()) {
+	w.fieldHeader(id, thriftStruct)
+	w.structBody(fields)
+}
+
+func (w *thriftWriter) structBody(fields func()) {
+	w.last = append(w.last, 0)
+	fields()
+	w.buf.WriteByte(0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) listHeader(id int16, elemType byte, n int) {
+	w.fieldHeader(id, thriftList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.buf.Write(binary.AppendUvarint(nil, uint64(n)))
+	}
+}
+
+// structList writes a list of n structs, the fields of the i-th written by fields(i)
+func (w *thriftWriter) structList(id int16, n int, fields func(i int)) {
+	w.listHeader(id, thriftStruct, n)
+	for i := 0; i < n; i++ {
+		w.structBody(func() { fields(i) })
+	}
+}
+
+func (w *thriftWriter) i32List(id int16, values ...int32) {
+	w.listHeader(id, thriftI32, len(values))
+	for _, v := range values {
+		w.varint(int64(v))
+	}
+}
+
+func (w *thriftWriter) stringList(id int16, values ...string) {
+	w.listHeader(id, thriftBinary, len(values))
+	for _, s := range values {
+		w.rawString(s)
+	}
+}
@@ -0,0 +1,244 @@
+// Package fleet rolls up the benchmark stores of many repositories into one
+// report of their regressions, grouped by the teams owning them
+package fleet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/alenon/gokanon/internal/threshold"
+)
+
+// Repo is the benchmark store of a repository
+type Repo struct {
+	Name     string
+	Owner    string // Team owning the repository, empty when unknown
+	Storage  *storage.Storage
+	Comparer *compare.Comparer  // Matching rules of the repository (default rules when nil)
+	Checker  *threshold.Checker // Thresholds of the repository (Options.Threshold when nil)
+}
+
+// Options control how the newest run of each repository is checked
+type Options struct {
+	Threshold float64 // Maximum allowed degradation (%)
+
+	// Baseline the newest run is compared with in the repositories that
+	// have it; the others, or all when empty, compare with the previous run
+	Baseline string
+}
+
+// Report is the roll-up of the regressions of a fleet of repositories
+type Report struct {
+	Generated   time.Time     `json:"generated"`
+	Threshold   float64       `json:"threshold"`
+	Repos       int           `json:"repos"`
+	Regressing  int           `json:"regressing"`  // Repositories with at least one regression
+	Regressions int           `json:"regressions"` // Regressed benchmarks across the fleet
+	Owners      []OwnerReport `json:"owners"`
+}
+
+// OwnerReport groups the repositories of a team
+type OwnerReport struct {
+	Owner       string       `json:"owner"` // Empty for repositories without an owner
+	Regressions int          `json:"regressions"`
+	Repos       []RepoReport `json:"repos"`
+}
+
+// RepoReport is the outcome of checking the newest run of a repository
+type RepoReport struct {
+	Name        string       `json:"name"`
+	Owner       string       `json:"owner,omitempty"`
+	Run         string       `json:"run,omitempty"`      // Newest run
+	RunTime     time.Time    `json:"run_time,omitempty"` // When the newest run was taken
+	Against     string       `json:"against,omitempty"`  // Baseline name or previous run the newest run is compared with
+	Compared    int          `json:"compared"`           // Benchmarks in both runs
+	Improved    int          `json:"improved"`
+	Regressions []Regression `json:"regressions,omitempty"`
+	Error       string       `json:"error,omitempty"` // Why the repository could not be checked
+}
+
+// Regression is a benchmark that degraded beyond its threshold
+type Regression struct {
+	Benchmark    string  `json:"benchmark"`
+	Package      string  `json:"package,omitempty"`
+	Old          float64 `json:"old"`
+	New          float64 `json:"new"`
+	Unit         string  `json:"unit"`
+	DeltaPercent float64 `json:"delta_percent"`
+	Message      string  `json:"message"`
+}
+
+// Build checks the newest successful run of each repository and groups
+// the outcome by owner. Repositories that cannot be checked, such as
+// those with a single run, are reported with the reason.
+func Build(repos []Repo, opts Options) *Report {
+	report := &Report{Generated: time.Now(), Threshold: opts.Threshold, Repos: len(repos)}
+
+	byOwner := make(map[string]*OwnerReport)
+	for _, repo := range repos {
+		repoReport := checkRepo(repo, opts)
+		if len(repoReport.Regressions) > 0 {
+			report.Regressing++
+			report.Regressions += len(repoReport.Regressions)
+		}
+
+		owner, ok := byOwner[repo.Owner]
+		if !ok {
+			owner = &OwnerReport{Owner: repo.Owner}
+			byOwner[repo.Owner] = owner
+		}
+		owner.Regressions += len(repoReport.Regressions)
+		owner.Repos = append(owner.Repos, repoReport)
+	}
+
+	for _, owner := range byOwner {
+		// The most regressing repositories first
+		sort.SliceStable(owner.Repos, func(i, j int) bool {
+			a, b := owner.Repos[i], owner.Repos[j]
+			if len(a.Regressions) != len(b.Regressions) {
+				return len(a.Regressions) > len(b.Regressions)
+			}
+			return a.Name < b.Name
+		})
+		report.Owners = append(report.Owners, *owner)
+	}
+	// Owners in name order, the repositories without one last
+	sort.Slice(report.Owners, func(i, j int) bool {
+		a, b := report.Owners[i].Owner, report.Owners[j].Owner
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+	return report
+}
+
+// checkRepo compares the newest successful run of a repository with its
+// baseline or previous run
+func checkRepo(repo Repo, opts Options) RepoReport {
+	report := RepoReport{Name: repo.Name, Owner: repo.Owner}
+
+	// The newest run is compared with the one before, unless with a baseline
+	successful, err := repo.Storage.Latest(2, func(run *models.BenchmarkRun) bool { return !run.Failed() })
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	if len(successful) == 0 {
+		report.Error = "no runs"
+		return report
+	}
+	newRun := &successful[0]
+	report.Run, report.RunTime = newRun.ID, newRun.Timestamp
+
+	var oldRun *models.BenchmarkRun
+	switch {
+	case opts.Baseline != "" && repo.Storage.HasBaseline(opts.Baseline):
+		baseline, err := repo.Storage.LoadBaseline(opts.Baseline)
+		if err == nil && baseline.Run == nil {
+			baseline.Run, err = repo.Storage.Load(baseline.RunID)
+		}
+		if err != nil {
+			report.Error = fmt.Sprintf("failed to load baseline %s: %v", opts.Baseline, err)
+			return report
+		}
+		oldRun, report.Against = baseline.Run, "baseline "+opts.Baseline
+	case len(successful) > 1:
+		oldRun, report.Against = &successful[1], successful[1].ID
+	default:
+		report.Error = "a single run, nothing to compare with"
+		return report
+	}
+
+	comparer := repo.Comparer
+	if comparer == nil {
+		comparer = compare.NewComparer()
+	}
+	checker := repo.Checker
+	if checker == nil {
+		checker = threshold.NewChecker(opts.Threshold)
+	}
+	comparisons := comparer.Compare(oldRun, newRun)
+	result := checker.Check(comparisons)
+
+	byName := make(map[string]models.Comparison, len(comparisons))
+	for _, comp := range comparisons {
+		byName[comp.Name] = comp
+		if comp.Status == "improved" {
+			report.Improved++
+		}
+		if comp.OldNsPerOp > 0 && comp.NewNsPerOp > 0 {
+			report.Compared++
+		}
+	}
+	for _, failure := range result.Failures {
+		comp := byName[failure.BenchmarkName]
+		report.Regressions = append(report.Regressions, Regression{
+			Benchmark:    failure.BenchmarkName,
+			Package:      comp.Package,
+			Old:          comp.OldNsPerOp,
+			New:          comp.NewNsPerOp,
+			Unit:         comp.ValueUnit(),
+			DeltaPercent: failure.DeltaPercent,
+			Message:      failure.Message,
+		})
+	}
+	// The largest regressions first
+	sort.SliceStable(report.Regressions, func(i, j int) bool {
+		return report.Regressions[i].DeltaPercent > report.Regressions[j].DeltaPercent
+	})
+	return report
+}
+
+// OwnerName returns the display name of an owner
+func OwnerName(owner string) string {
+	if owner == "" {
+		return "(no owner)"
+	}
+	return owner
+}
+
+// Markdown renders the report for a wiki page, an issue or a chat message
+func Markdown(report *Report) string {
+	var sb strings.Builder
+	sb.WriteString("# Fleet Benchmark Report\n\n")
+	fmt.Fprintf(&sb, "Generated %s. %d of %d repositories regressed beyond %.1f%%, %d benchmarks in all.\n",
+		report.Generated.Format("2006-01-02 15:04"), report.Regressing, report.Repos, report.Threshold, report.Regressions)
+
+	for _, owner := range report.Owners {
+		fmt.Fprintf(&sb, "\n## %s (%d regressions)\n\n", OwnerName(owner.Owner), owner.Regressions)
+		sb.WriteString("| Repository | Newest run | Compared with | Regressions | Improved |\n")
+		sb.WriteString("|------------|------------|---------------|-------------|----------|\n")
+		for _, repo := range owner.Repos {
+			if repo.Error != "" {
+				fmt.Fprintf(&sb, "| %s | %s | ⚠️ %s | - | - |\n", repo.Name, orDash(repo.Run), repo.Error)
+				continue
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %d | %d |\n", repo.Name, repo.Run, repo.Against, len(repo.Regressions), repo.Improved)
+		}
+		for _, repo := range owner.Repos {
+			if len(repo.Regressions) == 0 {
+				continue
+			}
+			fmt.Fprintf(&sb, "\n### %s\n\n", repo.Name)
+			for _, regression := range repo.Regressions {
+				fmt.Fprintf(&sb, "- `%s`: %.2f → %.2f %s (%+.2f%%)\n",
+					regression.Benchmark, regression.Old, regression.New, regression.Unit, regression.DeltaPercent)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// orDash returns s, or a dash when s is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package fleet
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// newStore saves runs measuring BenchmarkEncode at each ns/op, oldest first
+func newStore(t *testing.T, nsPerOp ...float64) *storage.Storage {
+	t.Helper()
+	store := storage.NewStorage(t.TempDir())
+	start := time.Now().Add(-time.Hour)
+	for i, ns := range nsPerOp {
+		run := &models.BenchmarkRun{
+			ID:        "run-" + string(rune('a'+i)),
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Results:   []models.BenchmarkResult{{Name: "Encode", Package: "example.com/codec", NsPerOp: ns}},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestBuild(t *testing.T) {
+	repos := []Repo{
+		{Name: "web", Owner: "frontend", Storage: newStore(t, 100, 100)},
+		{Name: "api", Owner: "platform", Storage: newStore(t, 100, 150)},
+		{Name: "billing", Owner: "platform", Storage: newStore(t, 100, 300)},
+		{Name: "tools", Storage: newStore(t, 100)},
+	}
+	report := Build(repos, Options{Threshold: 10})
+
+	if report.Repos != 4 || report.Regressing != 2 || report.Regressions != 2 {
+		t.Errorf("Report totals = %d repos, %d regressing, %d regressions", report.Repos, report.Regressing, report.Regressions)
+	}
+	var owners []string
+	for _, owner := range report.Owners {
+		owners = append(owners, owner.Owner)
+	}
+	if got := strings.Join(owners, ","); got != "frontend,platform," {
+		t.Fatalf("Owners = %q, want frontend, platform and the repositories without owner last", got)
+	}
+
+	platform := report.Owners[1]
+	if platform.Regressions != 2 || len(platform.Repos) != 2 {
+		t.Fatalf("Platform = %+v", platform)
+	}
+	// The most regressing repositories first, then by name
+	api := platform.Repos[0]
+	if api.Name != "api" || api.Against != "run-a" || api.Run != "run-b" || api.Compared != 1 {
+		t.Errorf("api = %+v", api)
+	}
+	if len(api.Regressions) != 1 || api.Regressions[0].Benchmark != "Encode" || api.Regressions[0].DeltaPercent != 50 ||
+		api.Regressions[0].Package != "example.com/codec" {
+		t.Errorf("Regressions of api = %+v", api.Regressions)
+	}
+
+	if tools := report.Owners[2].Repos[0]; tools.Error == "" || tools.Run != "run-a" {
+		t.Errorf("Expected tools to be reported as not checked, got %+v", tools)
+	}
+
+	markdown := Markdown(report)
+	for _, want := range []string{"## platform (2 regressions)", "## (no owner)", "### billing", "`Encode`: 100.00 → 300.00 ns/op (+200.00%)"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown lacks %q:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestBuildBaseline(t *testing.T) {
+	store := newStore(t, 100, 200, 210)
+	if _, err := store.SaveBaseline("main", "run-a", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	other := newStore(t, 100, 105)
+
+	report := Build([]Repo{{Name: "a", Storage: store}, {Name: "b", Storage: other}}, Options{Threshold: 10, Baseline: "main"})
+	repos := report.Owners[0].Repos
+	// Repositories without the baseline compare with the previous run
+	if repos[0].Name != "a" || repos[0].Against != "baseline main" || len(repos[0].Regressions) != 1 {
+		t.Errorf("a = %+v", repos[0])
+	}
+	if repos[1].Against != "run-a" || len(repos[1].Regressions) != 0 {
+		t.Errorf("b = %+v", repos[1])
+	}
+}
@@ -0,0 +1,243 @@
+package interactive
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/alenon/gokanon/internal/storage"
+	"github.com/chzyer/readline"
+)
+
+// Source lists the names completed in arguments: run IDs, baseline names
+// and benchmark names. It is queried on every completion, so that runs
+// saved during the session are completed too.
+type Source interface {
+	RunIDs() []string     // Newest first
+	Baselines() []string  // In name order
+	Benchmarks() []string // In name order
+}
+
+// StorageSource returns a source listing the runs, baselines and
+// benchmarks of a storage directory
+func StorageSource(store *storage.Storage) Source {
+	return storageSource{store: store}
+}
+
+type storageSource struct {
+	store *storage.Storage
+}
+
+// RunIDs returns the IDs of the saved runs without loading them. An
+// unreadable storage completes nothing rather than failing the prompt.
+func (s storageSource) RunIDs() []string {
+	ids, err := s.store.RunIDs()
+	if err != nil {
+		return nil
+	}
+	slices.Reverse(ids)
+	return ids
+}
+
+// Baselines returns the names of the saved baselines
+func (s storageSource) Baselines() []string {
+	baselines, err := s.store.ListBaselines()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(baselines))
+	for _, baseline := range baselines {
+		names = append(names, baseline.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Benchmarks returns the names of the benchmarks measured by any run,
+// read from the statistics cache
+func (s storageSource) Benchmarks() []string {
+	aggregates, err := s.store.Aggregates()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(aggregates.Benchmarks))
+	for name := range aggregates.Benchmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completer completes the commands of the session with their flags, and
+// the run IDs, baseline and benchmark names of source in their arguments
+func completer(source Source) *readline.PrefixCompleter {
+	runIDs := names(source, Source.RunIDs)
+	baselines := names(source, Source.Baselines)
+	benchmarks := names(source, Source.Benchmarks)
+
+	return readline.NewPrefixCompleter(
+		readline.PcItem("run",
+			readline.PcItem("-bench="),
+			flagValues("-bench", benchmarks),
+			readline.PcItem("-pkg="),
+			readline.PcItem("-profile="),
+			readline.PcItem("-benchtime="),
+			readline.PcItem("-count="),
+			readline.PcItem("-startup="),
+			readline.PcItem("-startup-ready="),
+			readline.PcItem("-load="),
+			readline.PcItem("-load-duration="),
+			readline.PcItem("-load-rate="),
+		),
+		readline.PcItem("list"),
+		readline.PcItem("compare",
+			readline.PcItem("--latest"),
+			readline.PcItem("-dry-run"),
+			flagValues("--baseline", baselines),
+			readline.PcItemDynamic(runIDs, readline.PcItemDynamic(runIDs)),
+		),
+		readline.PcItem("export",
+			readline.PcItem("--latest"),
+			readline.PcItem("-format=html"),
+			readline.PcItem("-format=csv"),
+			readline.PcItem("-format=markdown"),
+			readline.PcItem("-format=json"),
+			flagValues("-run", runIDs),
+			readline.PcItemDynamic(runIDs, readline.PcItemDynamic(runIDs)),
+		),
+		readline.PcItem("stats",
+			readline.PcItem("-last="),
+		),
+		readline.PcItem("trend",
+			readline.PcItem("-last="),
+			flagValues("-benchmark", benchmarks),
+		),
+		readline.PcItem("check",
+			readline.PcItem("--latest"),
+			readline.PcItem("-threshold="),
+			readline.PcItem("-fail-on-removed"),
+			readline.PcItem("-explain"),
+			readline.PcItemDynamic(runIDs, readline.PcItemDynamic(runIDs)),
+		),
+		readline.PcItem("flamegraph",
+			readline.PcItem("-format=speedscope"),
+			readline.PcItem("-format=collapsed"),
+			readline.PcItem("-o="),
+			readline.PcItemDynamic(runIDs),
+		),
+		readline.PcItem("serve",
+			readline.PcItem("-port="),
+		),
+		readline.PcItem("delete",
+			readline.PcItemDynamic(runIDs),
+		),
+		readline.PcItem("baseline",
+			readline.PcItem("save",
+				readline.PcItem("-name="),
+				flagValues("-run", runIDs),
+			),
+			readline.PcItem("list"),
+			readline.PcItem("show", flagValues("-name", baselines)),
+			readline.PcItem("delete", flagValues("-name", baselines)),
+		),
+		readline.PcItem("attach",
+			readline.PcItemDynamic(runIDs),
+		),
+		readline.PcItem("slo"),
+		readline.PcItem("config"),
+		readline.PcItem("import",
+			readline.PcItem("-format=perf"),
+			readline.PcItem("-format=benchstat-csv"),
+		),
+		readline.PcItem("projects"),
+		readline.PcItem("bisect",
+			flagValues("-benchmark", benchmarks),
+		),
+		readline.PcItem("sync",
+			readline.PcItem("push"),
+			readline.PcItem("pull"),
+		),
+		readline.PcItem("push",
+			readline.PcItemDynamic(runIDs),
+			readline.PcItem("-perfdata="),
+			readline.PcItem("-all"),
+			readline.PcItem("-force"),
+			readline.PcItem("-dry-run"),
+		),
+		readline.PcItem("profile",
+			readline.PcItemDynamic(benchmarks,
+				readline.PcItem("-duration="),
+				readline.PcItem("-pkg="),
+				readline.PcItem("-web"),
+			),
+			readline.PcItem("-duration="),
+			readline.PcItem("-pkg="),
+			readline.PcItem("-web"),
+		),
+		readline.PcItem("snapshot"),
+		readline.PcItem("stability"),
+		readline.PcItem("prune",
+			readline.PcItem("-keep-last="),
+			readline.PcItem("-older-than="),
+			readline.PcItem("-profiles-older-than="),
+			readline.PcItem("-compress"),
+			readline.PcItem("-dry-run"),
+		),
+		readline.PcItem("search",
+			readline.PcItem("-limit="),
+			readline.PcItem("-since="),
+			readline.PcItem("-json"),
+		),
+		readline.PcItem("fleet",
+			readline.PcItem("report",
+				readline.PcItem("-storage="),
+				readline.PcItem("-remote="),
+				readline.PcItem("-owner="),
+				readline.PcItem("-baseline="),
+				readline.PcItem("-threshold="),
+				readline.PcItem("-format="),
+			),
+		),
+		readline.PcItem("tui",
+			readline.PcItem("-last="),
+			readline.PcItem("-window="),
+		),
+		readline.PcItem("analyze",
+			readline.PcItemDynamic(runIDs, readline.PcItemDynamic(runIDs)),
+			readline.PcItem("-no-cache"),
+			readline.PcItem("-show-cached"),
+			readline.PcItem("-diff"),
+		),
+		readline.PcItem("doctor",
+			readline.PcItem("-ci"),
+			readline.PcItem("-json"),
+			flagValues("-baseline", baselines),
+		),
+		readline.PcItem("help"),
+		readline.PcItem("clear"),
+		readline.PcItem("exit"),
+		readline.PcItem("quit"),
+	)
+}
+
+// names returns a completion function listing the names of source, or
+// none without a source
+func names(source Source, list func(Source) []string) readline.DynamicCompleteFunc {
+	return func(string) []string {
+		if source == nil {
+			return nil
+		}
+		return list(source)
+	}
+}
+
+// flagValues completes a flag taking one of the names listed by values,
+// as in -name=v1.0
+func flagValues(flag string, values readline.DynamicCompleteFunc) *readline.PrefixCompleter {
+	return readline.PcItemDynamic(func(line string) []string {
+		var items []string
+		for _, value := range values(line) {
+			items = append(items, flag+"="+value)
+		}
+		return items
+	})
+}
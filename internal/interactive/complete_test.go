@@ -0,0 +1,130 @@
+package interactive
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+type fakeSource struct{}
+
+func (fakeSource) RunIDs() []string     { return []string{"run-2", "run-1"} }
+func (fakeSource) Baselines() []string  { return []string{"main", "v1.0"} }
+func (fakeSource) Benchmarks() []string { return []string{"BenchmarkEncode", "BenchmarkParse"} }
+
+// complete returns the completions of line, with the text already typed
+func complete(t *testing.T, source Source, line string) []string {
+	t.Helper()
+	candidates, offset := completer(source).Do([]rune(line), len([]rune(line)))
+	typed := line[len(line)-offset:]
+	var completions []string
+	for _, candidate := range candidates {
+		completions = append(completions, typed+string(candidate))
+	}
+	return completions
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"comp", []string{"compare "}},
+		{"compare run-", []string{"run-2 ", "run-1 "}},
+		{"compare run-2 run-1", []string{"run-1 "}},
+		{"compare --baseline=v", []string{"--baseline=v1.0 "}},
+		{"delete run-1", []string{"run-1 "}},
+		{"baseline show -name=m", []string{"-name=main "}},
+		{"trend -benchmark=BenchmarkP", []string{"-benchmark=BenchmarkParse "}},
+		{"profile BenchmarkE", []string{"BenchmarkEncode "}},
+		{"profile BenchmarkEncode -w", []string{"-web "}},
+		{"run -bench=BenchmarkE", []string{"-bench=BenchmarkEncode "}},
+	}
+	for _, tt := range tests {
+		if got := complete(t, fakeSource{}, tt.line); !slices.Equal(got, tt.want) {
+			t.Errorf("complete(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	// Without a source only commands and flags are completed
+	if got := complete(t, nil, "delete "); len(got) != 0 {
+		t.Errorf("complete without a source = %q, want nothing", got)
+	}
+}
+
+func TestStorageSource(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	now := time.Now()
+	for i, id := range []string{"run-a", "run-b"} {
+		run := &models.BenchmarkRun{
+			ID:        id,
+			Timestamp: now.Add(time.Duration(i) * time.Minute),
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkParse", NsPerOp: 100},
+				{Name: "BenchmarkEncode", NsPerOp: 50},
+			},
+		}
+		if err := store.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.SaveBaseline("v1.0", "run-a", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	source := StorageSource(store)
+	if got := source.RunIDs(); !slices.Equal(got, []string{"run-b", "run-a"}) {
+		t.Errorf("RunIDs() = %q, want the newest first", got)
+	}
+	if got := source.Baselines(); !slices.Equal(got, []string{"v1.0"}) {
+		t.Errorf("Baselines() = %q", got)
+	}
+	if got := source.Benchmarks(); !slices.Equal(got, []string{"BenchmarkEncode", "BenchmarkParse"}) {
+		t.Errorf("Benchmarks() = %q", got)
+	}
+
+	// A missing storage directory completes nothing
+	empty := StorageSource(storage.NewStorage(filepath.Join(t.TempDir(), "missing")))
+	if len(empty.RunIDs()) != 0 || len(empty.Baselines()) != 0 || len(empty.Benchmarks()) != 0 {
+		t.Error("Expected no completions from a missing storage directory")
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	skipIfRace(t)
+
+	history := filepath.Join(t.TempDir(), "state", "history")
+	session, err := NewWithOptions(Options{Prompt: "bench> ", HistoryFile: history, Source: fakeSource{}})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	defer session.Close()
+
+	if err := session.rl.SaveHistory("compare run-2 run-1"); err != nil {
+		t.Fatal(err)
+	}
+	session.Close()
+
+	// The history is there in the next session
+	session, err = NewWithOptions(Options{HistoryFile: history})
+	if err != nil {
+		t.Fatalf("NewWithOptions() error = %v", err)
+	}
+	defer session.Close()
+	if cfg := session.rl.Config; cfg.HistoryFile != history || cfg.HistoryLimit != defaultHistoryLimit {
+		t.Errorf("History file %s, limit %d", cfg.HistoryFile, cfg.HistoryLimit)
+	}
+	data, err := os.ReadFile(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "compare run-2 run-1" {
+		t.Errorf("History = %q", got)
+	}
+}
@@ -3,6 +3,8 @@ package interactive
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/alenon/gokanon/internal/ui"
@@ -18,55 +20,48 @@ type Session struct {
 // CommandHandler is a function that handles a command
 type CommandHandler func(args []string) error
 
-// New creates a new interactive session
+// DefaultPrompt is the prompt of a session without a configured one
+const DefaultPrompt = "gokanon> "
+
+// defaultHistoryLimit is the number of lines kept in the history file
+const defaultHistoryLimit = 1000
+
+// Options configure an interactive session
+type Options struct {
+	Prompt       string // Prompt (default DefaultPrompt)
+	HistoryFile  string // File the history is kept in across sessions; empty keeps it in memory
+	HistoryLimit int    // Lines kept in the history file (default 1000)
+	Source       Source // Run IDs, baselines and benchmarks to complete; nil completes commands and flags only
+}
+
+// New creates a new interactive session completing commands and flags,
+// without a history file
 func New() (*Session, error) {
-	completer := readline.NewPrefixCompleter(
-		readline.PcItem("run",
-			readline.PcItem("-bench="),
-			readline.PcItem("-pkg="),
-			readline.PcItem("-profile="),
-			readline.PcItem("-benchtime="),
-			readline.PcItem("-count="),
-		),
-		readline.PcItem("list"),
-		readline.PcItem("compare",
-			readline.PcItem("--latest"),
-		),
-		readline.PcItem("export",
-			readline.PcItem("--latest"),
-			readline.PcItem("-format=html"),
-			readline.PcItem("-format=csv"),
-			readline.PcItem("-format=markdown"),
-			readline.PcItem("-format=json"),
-		),
-		readline.PcItem("stats",
-			readline.PcItem("-last="),
-		),
-		readline.PcItem("trend",
-			readline.PcItem("-last="),
-		),
-		readline.PcItem("check",
-			readline.PcItem("--latest"),
-			readline.PcItem("-threshold="),
-		),
-		readline.PcItem("flamegraph"),
-		readline.PcItem("serve",
-			readline.PcItem("-port="),
-		),
-		readline.PcItem("delete"),
-		readline.PcItem("doctor"),
-		readline.PcItem("help"),
-		readline.PcItem("clear"),
-		readline.PcItem("exit"),
-		readline.PcItem("quit"),
-	)
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions creates a new interactive session
+func NewWithOptions(opts Options) (*Session, error) {
+	if opts.Prompt == "" {
+		opts.Prompt = DefaultPrompt
+	}
+	if opts.HistoryLimit <= 0 {
+		opts.HistoryLimit = defaultHistoryLimit
+	}
+	if opts.HistoryFile != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.HistoryFile), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create history directory: %w", err)
+		}
+	}
 
 	rl, err := readline.NewEx(&readline.Config{
-		Prompt:          ui.Info("gokanon> "),
-		HistoryFile:     "/tmp/gokanon_history.tmp",
-		AutoComplete:    completer,
-		InterruptPrompt: "^C",
-		EOFPrompt:       "exit",
+		Prompt:            ui.Info(opts.Prompt),
+		HistoryFile:       opts.HistoryFile,
+		HistoryLimit:      opts.HistoryLimit,
+		HistorySearchFold: true,
+		AutoComplete:      completer(opts.Source),
+		InterruptPrompt:   "^C",
+		EOFPrompt:         "exit",
 	})
 
 	if err != nil {
@@ -107,6 +102,10 @@ func (s *Session) Run() error {
 			continue
 		}
 
+		if line == "exit" || line == "quit" {
+			break
+		}
+
 		// Handle built-in commands
 		if s.handleBuiltIn(line) {
 			continue
@@ -191,6 +190,23 @@ func (s *Session) printHelp() {
 		{"flamegraph", "View CPU/memory flame graphs"},
 		{"serve", "Start interactive web dashboard"},
 		{"delete", "Delete a benchmark result"},
+		{"baseline", "Manage baseline benchmarks"},
+		{"attach", "Attach an external pprof profile to a run"},
+		{"slo", "Check service level objectives"},
+		{"config", "Show resolved storage and configuration locations"},
+		{"import", "Import go test -bench output from a file or stdin"},
+		{"projects", "List projects tracked in the project registry"},
+		{"bisect", "Find the commit that introduced a regression"},
+		{"sync", "Share history through an S3/GCS bucket"},
+		{"profile", "Profile a single benchmark for a fixed time"},
+		{"snapshot", "Freeze dashboard stats and trends for a release"},
+		{"stability", "Find benchmarks too noisy to gate CI on"},
+		{"prune", "Delete old runs and profiles by a retention policy"},
+		{"search", "Search runs by benchmark, package, tag, note or commit"},
+		{"fleet", "Roll up regressions across many repositories"},
+		{"tui", "Full-screen terminal UI for runs, trends and comparisons"},
+		{"push", "Upload runs to a perf data server"},
+		{"analyze", "AI analysis of two runs, or replay cached analyses"},
 		{"doctor", "Run diagnostics"},
 		{"help", "Show this help message"},
 		{"clear", "Clear the screen"},
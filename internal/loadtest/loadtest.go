@@ -0,0 +1,319 @@
+// Package loadtest sends HTTP load to a service for a fixed duration, in
+// the manner of hey and vegeta, and summarizes the latencies and throughput
+// it measured as a benchmark result, so that service-level and micro
+// benchmarks share one history
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Defaults of a load test
+const (
+	DefaultDuration    = 10 * time.Second
+	DefaultConcurrency = 10
+	DefaultTimeout     = 10 * time.Second
+)
+
+// Metric units of a load test result. Latencies are per request, like
+// ns/op is per benchmark iteration.
+const (
+	MetricP50    = "p50-ns/op"
+	MetricP90    = "p90-ns/op"
+	MetricP99    = "p99-ns/op"
+	MetricMax    = "max-ns/op"
+	MetricRate   = "req/s"
+	MetricErrors = "errors/op" // Fraction of the requests that failed
+)
+
+// Options configure a load test
+type Options struct {
+	URL         string
+	Method      string // Default GET
+	Header      http.Header
+	Body        []byte
+	Duration    time.Duration // Default 10s
+	Rate        float64       // Requests per second across all workers; 0 sends as fast as Concurrency allows
+	Concurrency int           // Requests in flight at most (default 10)
+	Timeout     time.Duration // Longest a request may take (default 10s)
+	Client      *http.Client  // Default: a client with keep-alive connections for every worker
+}
+
+// Report is what a load test measured
+type Report struct {
+	Duration    time.Duration
+	Requests    int         // Requests completed, failed ones included
+	Errors      int         // Requests without a response, or with a status of 400 or more
+	StatusCodes map[int]int // Responses by status code
+	FirstError  string      // Why the first failed request failed
+
+	// Latencies of the completed requests, sorted
+	Latencies []time.Duration
+
+	// Mean latency of the requests completed in each second of the test,
+	// the samples that runs are compared with
+	Intervals []float64
+}
+
+// ParseHeader parses a header given as "Name: value"
+func ParseHeader(spec string) (name, value string, err error) {
+	name, value, ok := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("invalid header %q, expected \"Name: value\"", spec)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// Validate checks the options before any request is sent
+func (o *Options) Validate() error {
+	u, err := url.Parse(o.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q: use an http or https URL", o.URL)
+	}
+	if o.Rate < 0 {
+		return fmt.Errorf("negative rate %v", o.Rate)
+	}
+	if o.Concurrency < 0 {
+		return fmt.Errorf("negative concurrency %d", o.Concurrency)
+	}
+	return nil
+}
+
+// Run sends requests until the duration has passed or ctx is done.
+// Requests still in flight then are abandoned and not counted.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultDuration
+	}
+	if opts.Concurrency == 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	client := opts.Client
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = opts.Concurrency
+		client = &http.Client{Transport: transport}
+		defer transport.CloseIdleConnections()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	// With a rate, workers wait for their turn; without, they send the
+	// next request as soon as the previous one completed
+	var turns <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(max(time.Duration(float64(time.Second)/opts.Rate), 1))
+		defer ticker.Stop()
+		turns = ticker.C
+	}
+
+	type sample struct {
+		done    time.Duration // Since the start of the test
+		latency time.Duration
+		status  int
+		err     error
+	}
+	var mu sync.Mutex
+	var samples []sample
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if turns != nil {
+					select {
+					case <-turns:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				sent := time.Now()
+				status, err := send(ctx, client, opts)
+				if ctx.Err() != nil {
+					// Cut short by the end of the test
+					return
+				}
+				now := time.Now()
+				mu.Lock()
+				samples = append(samples, sample{now.Sub(start), now.Sub(sent), status, err})
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Duration: time.Since(start), StatusCodes: make(map[int]int)}
+	seconds := int(math.Ceil(report.Duration.Seconds()))
+	sums := make([]float64, seconds)
+	counts := make([]int, seconds)
+	for _, s := range samples {
+		report.Requests++
+		if s.status != 0 {
+			report.StatusCodes[s.status]++
+		}
+		if s.err != nil {
+			report.Errors++
+			if report.FirstError == "" {
+				report.FirstError = s.err.Error()
+			}
+			continue
+		}
+		report.Latencies = append(report.Latencies, s.latency)
+		second := min(int(s.done/time.Second), seconds-1)
+		sums[second] += float64(s.latency)
+		counts[second]++
+	}
+	sort.Slice(report.Latencies, func(i, j int) bool { return report.Latencies[i] < report.Latencies[j] })
+	for i, count := range counts {
+		if count > 0 {
+			report.Intervals = append(report.Intervals, sums[i]/float64(count))
+		}
+	}
+	return report, nil
+}
+
+// send sends one request and reads the response. Responses with a status
+// of 400 or more are failures.
+func send(ctx context.Context, client *http.Client, opts Options) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if opts.Body != nil {
+		body = bytes.NewReader(opts.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, opts.Method, opts.URL, body)
+	if err != nil {
+		return 0, err
+	}
+	for name, values := range opts.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Reading the body reuses the connection and counts its transfer
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, fmt.Errorf("%s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Percentile returns the latency below which p percent of the successful
+// requests completed, or 0 without any
+func (r *Report) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(r.Latencies)))) - 1
+	return r.Latencies[max(0, min(i, len(r.Latencies)-1))]
+}
+
+// Throughput returns the successful requests per second
+func (r *Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(len(r.Latencies)) / r.Duration.Seconds()
+}
+
+// Mean returns the mean latency of the successful requests
+func (r *Report) Mean() time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, latency := range r.Latencies {
+		sum += latency
+	}
+	return sum / time.Duration(len(r.Latencies))
+}
+
+// Result converts the report into a benchmark result named "Load/<name>":
+// the mean latency per request as ns/op, the latency of each second of the
+// test as samples, and the percentiles, throughput and error rate as
+// metrics. A test without a single successful request is a failed result.
+func (r *Report) Result(name string) models.BenchmarkResult {
+	result := models.BenchmarkResult{
+		Name:       "Load/" + name,
+		Parent:     "Load",
+		Variant:    name,
+		Iterations: int64(r.Requests),
+	}
+	if len(r.Latencies) == 0 {
+		result.Status = models.StatusFailed
+		result.Message = "no successful request"
+		if r.FirstError != "" {
+			result.Message += ": " + r.FirstError
+		}
+		return result
+	}
+
+	result.NsPerOp = float64(r.Mean())
+	if len(r.Intervals) > 1 {
+		result.Samples = r.Intervals
+	}
+	result.Metrics = map[string]float64{
+		MetricP50:    float64(r.Percentile(50)),
+		MetricP90:    float64(r.Percentile(90)),
+		MetricP99:    float64(r.Percentile(99)),
+		MetricMax:    float64(r.Latencies[len(r.Latencies)-1]),
+		MetricRate:   r.Throughput(),
+		MetricErrors: float64(r.Errors) / float64(r.Requests),
+	}
+	return result
+}
+
+// Name returns the default result name of a URL: its host and path
+func Name(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host + strings.TrimSuffix(u.Path, "/")
+}
@@ -0,0 +1,120 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestRun(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method != http.MethodPost || r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		time.Sleep(time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	report, err := Run(context.Background(), Options{
+		URL:         server.URL + "/orders/",
+		Method:      http.MethodPost,
+		Header:      http.Header{"X-Token": {"secret"}},
+		Body:        []byte(`{"id":1}`),
+		Duration:    1500 * time.Millisecond,
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Requests == 0 || report.Errors != 0 || report.StatusCodes[http.StatusOK] != report.Requests {
+		t.Fatalf("Report: %d requests, %d errors (%s), status codes %v", report.Requests, report.Errors, report.FirstError, report.StatusCodes)
+	}
+	if int64(report.Requests) > requests.Load() {
+		t.Errorf("%d requests counted, %d served", report.Requests, requests.Load())
+	}
+	if len(report.Intervals) != 2 {
+		t.Errorf("Intervals = %v, want one per second", report.Intervals)
+	}
+
+	result := report.Result(Name(server.URL + "/orders/"))
+	if !strings.HasPrefix(result.Name, "Load/127.0.0.1:") || !strings.HasSuffix(result.Name, "/orders") || result.Parent != "Load" {
+		t.Errorf("Result name %q, parent %q", result.Name, result.Parent)
+	}
+	if result.NsPerOp < float64(time.Millisecond) || len(result.Samples) != 2 || result.Iterations != int64(report.Requests) {
+		t.Errorf("Result = %+v", result)
+	}
+	m := result.Metrics
+	if !(m[MetricP50] <= m[MetricP90] && m[MetricP90] <= m[MetricP99] && m[MetricP99] <= m[MetricMax]) {
+		t.Errorf("Percentiles out of order: %v", m)
+	}
+	if m[MetricRate] <= 0 || m[MetricErrors] != 0 {
+		t.Errorf("Throughput %v, error rate %v", m[MetricRate], m[MetricErrors])
+	}
+}
+
+func TestRunRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	report, err := Run(context.Background(), Options{URL: server.URL, Duration: 500 * time.Millisecond, Rate: 40, Concurrency: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 40 requests per second for half a second
+	if report.Requests < 10 || report.Requests > 21 {
+		t.Errorf("%d requests, want about 20", report.Requests)
+	}
+}
+
+func TestRunFailing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	report, err := Run(context.Background(), Options{URL: server.URL, Duration: 100 * time.Millisecond, Concurrency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Requests == 0 || report.Errors != report.Requests || report.StatusCodes[http.StatusServiceUnavailable] != report.Requests {
+		t.Errorf("Report: %d requests, %d errors, status codes %v", report.Requests, report.Errors, report.StatusCodes)
+	}
+	result := report.Result("maintenance")
+	if result.Status != models.StatusFailed || !strings.Contains(result.Message, "503") {
+		t.Errorf("Result status %q, message %q", result.Status, result.Message)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, opts := range []Options{
+		{URL: "localhost:8080"},
+		{URL: "ftp://example.com"},
+		{URL: "http://localhost", Rate: -1},
+	} {
+		if _, err := Run(context.Background(), opts); err == nil {
+			t.Errorf("Run(%+v) succeeded, want an error", opts)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := ParseHeader("Authorization: Bearer abc:def")
+	if err != nil || name != "Authorization" || value != "Bearer abc:def" {
+		t.Errorf("ParseHeader = %q, %q, %v", name, value, err)
+	}
+	for _, spec := range []string{"Authorization", ": value", "Bad Name: value"} {
+		if _, _, err := ParseHeader(spec); err == nil {
+			t.Errorf("ParseHeader(%q) succeeded, want an error", spec)
+		}
+	}
+}
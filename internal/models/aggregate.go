@@ -0,0 +1,41 @@
+package models
+
+import "math"
+
+// Aggregate holds running statistics of a benchmark's ns/op across runs.
+// Values are added one at a time with Welford's algorithm, so the
+// statistics are updated without revisiting earlier runs.
+type Aggregate struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	M2    float64 `json:"m2"` // Sum of squared differences from the mean
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+// Add adds a value to the statistics
+func (a *Aggregate) Add(x float64) {
+	if a.Count == 0 || x < a.Min {
+		a.Min = x
+	}
+	if a.Count == 0 || x > a.Max {
+		a.Max = x
+	}
+	a.Count++
+	delta := x - a.Mean
+	a.Mean += delta / float64(a.Count)
+	a.M2 += delta * (x - a.Mean)
+}
+
+// Variance returns the population variance of the values
+func (a *Aggregate) Variance() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return a.M2 / float64(a.Count)
+}
+
+// StdDev returns the population standard deviation of the values
+func (a *Aggregate) StdDev() float64 {
+	return math.Sqrt(a.Variance())
+}
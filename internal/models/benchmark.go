@@ -1,15 +1,114 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+// Benchmark result statuses
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// StatusDegraded is the status of a run recorded in an unstable
+// environment, such as a busy or throttled machine, by 'run
+// -skip-if-unstable'
+const StatusDegraded = "environment-degraded"
 
 // BenchmarkResult represents a single benchmark result
 type BenchmarkResult struct {
 	Name        string  `json:"name"`
+	Package     string  `json:"package,omitempty"` // Import path of the package defining the benchmark
 	Iterations  int64   `json:"iterations"`
 	NsPerOp     float64 `json:"ns_per_op"`
 	BytesPerOp  int64   `json:"bytes_per_op,omitempty"`
 	AllocsPerOp int64   `json:"allocs_per_op,omitempty"`
 	MBPerSec    float64 `json:"mb_per_sec,omitempty"`
+
+	Metrics map[string]float64 `json:"metrics,omitempty"` // Custom units reported via b.ReportMetric
+	Status  string             `json:"status,omitempty"`  // "ok", "failed", "skipped" (empty = ok)
+	Message string             `json:"message,omitempty"` // Failure or skip reason
+
+	Samples []float64 `json:"samples,omitempty"` // ns/op of each repetition with -count; NsPerOp is their mean
+
+	Confidence string `json:"confidence,omitempty"` // Grade from A (trustworthy) to D (too short or noisy to act on), set when the run was taken
+
+	// Block and mutex delay of the benchmark function per operation, set
+	// when contention was profiled. The delay of the benchmark function,
+	// calibration rounds included, is spread over the iterations of all its
+	// results, so the value is meant for comparing runs rather than as an
+	// absolute.
+	ContentionNsPerOp float64 `json:"contention_ns_per_op,omitempty"`
+
+	Doc string `json:"doc,omitempty"` // Doc comment of the benchmark function, describing what it measures
+
+	// Sub-benchmarks started with b.Run, such as table-driven cases, are
+	// variants of the benchmark function that ran them
+	Parent  string `json:"parent,omitempty"`  // Benchmark function, e.g. "Parse" for "Parse/small-8"
+	Variant string `json:"variant,omitempty"` // Sub-benchmark path without the GOMAXPROCS suffix, e.g. "small"
+
+	Procs int `json:"procs,omitempty"` // GOMAXPROCS the result was measured at, set in runs with a CPU matrix
+}
+
+// ContentionMetric is the name of the ContentionNsPerOp metric
+const ContentionMetric = "contention-ns/op"
+
+// Measured reports whether the result holds measurements, i.e. the
+// benchmark neither failed nor was skipped
+func (r BenchmarkResult) Measured() bool {
+	return r.Status == "" || r.Status == StatusOK
+}
+
+// Family returns the benchmark function the result belongs to: its parent
+// for sub-benchmarks and its own name otherwise, without the GOMAXPROCS
+// suffix. Results recorded before parents were stored are split by name.
+func (r BenchmarkResult) Family() string {
+	if r.Parent != "" {
+		return r.Parent
+	}
+	family, _ := SplitBenchmarkName(r.Name)
+	return family
+}
+
+// SplitBenchmarkName splits a benchmark name into the benchmark function
+// and the sub-benchmark variant, dropping the GOMAXPROCS suffix:
+// "Parse/small-8" yields "Parse" and "small". Top-level benchmarks have no
+// variant.
+func SplitBenchmarkName(name string) (family, variant string) {
+	if i := strings.LastIndexByte(name, '-'); i >= 0 && isDigits(name[i+1:]) {
+		name = name[:i]
+	}
+	family, variant, _ = strings.Cut(name, "/")
+	return family, variant
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DocSynopsis returns the first sentence of a benchmark doc comment on a
+// single line, for tables and tooltips
+func DocSynopsis(doc string) string {
+	doc = strings.TrimSpace(doc)
+	if i := strings.Index(doc, "\n\n"); i >= 0 {
+		doc = doc[:i]
+	}
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		return doc[:i+1]
+	}
+	return doc
 }
 
 // BenchmarkRun represents a complete benchmark run with metadata
@@ -23,17 +122,264 @@ type BenchmarkRun struct {
 	Duration       time.Duration     `json:"duration"`
 	CPUProfile     string            `json:"cpu_profile,omitempty"`     // Path to CPU profile file
 	MemoryProfile  string            `json:"memory_profile,omitempty"`  // Path to memory profile file
+	WarmupProfile  string            `json:"warmup_profile,omitempty"`  // Path to the heap profile taken after warmup
+	BlockProfile   string            `json:"block_profile,omitempty"`   // Path to block profile file
+	MutexProfile   string            `json:"mutex_profile,omitempty"`   // Path to mutex profile file
 	ProfileSummary *ProfileSummary   `json:"profile_summary,omitempty"` // Summary of profile analysis
+
+	AttachedProfiles []AttachedProfile `json:"attached_profiles,omitempty"` // Externally collected profiles
+
+	// Profiles deleted by 'gokanon prune' to save space; their summary is kept
+	PrunedProfiles   []string  `json:"pruned_profiles,omitempty"`
+	ProfilesPrunedAt time.Time `json:"profiles_pruned_at,omitzero"`
+
+	Agent       string            `json:"agent,omitempty"`        // Agent that produced a distributed run
+	AgentLabels map[string]string `json:"agent_labels,omitempty"` // Labels of that agent
+
+	NormalizedTo string `json:"normalized_to,omitempty"` // Reference benchmark that ns/op values are relative to
+
+	Focus string `json:"focus,omitempty"` // Benchmark a 'gokanon profile' session was limited to
+
+	Stress *StressMatrix `json:"stress,omitempty"` // Parallelism levels of a 'run -stress' session
+
+	CPUMatrix []int `json:"cpu_matrix,omitempty"` // GOMAXPROCS values of a 'run -cpu' list, each benchmark measured at every one
+
+	Startup *Startup `json:"startup,omitempty"` // How a 'run -startup' session started the processes it timed
+
+	Load *LoadTest `json:"load,omitempty"` // Load a 'run -load' session sent to a service
+
+	Packages []PackageRun `json:"packages,omitempty"` // Packages benchmarked one by one, when the package pattern matched several
+
+	Dependencies *Dependencies `json:"dependencies,omitempty"` // Module versions the benchmarks were built with
+	Toolchain    *Toolchain    `json:"toolchain,omitempty"`    // Build settings the benchmarks were compiled with
+	Commit       string        `json:"commit,omitempty"`       // Git commit checked out when the benchmarks ran
+	Environment  *Environment  `json:"environment,omitempty"`  // Machine the benchmarks ran on
+
+	CommitMessage string            `json:"commit_message,omitempty"` // Subject line of Commit
+	Tags          map[string]string `json:"tags,omitempty"`           // Tags given with 'run -tags', e.g. branch=main
+	Note          string            `json:"note,omitempty"`           // Free-form note given with 'run -note'
+	Annotations   []Annotation      `json:"annotations,omitempty"`    // Notes added after the run with 'gokanon annotate'
+
+	PerfUploads map[string]string `json:"perf_uploads,omitempty"` // Upload ID on each perf data server the run was pushed to, by server URL
+
+	// A run whose test binary terminated abnormally, such as on a panic or
+	// when killed, is kept for diagnosis with the results recorded until
+	// then, but left out of trends. So is a run recorded in an unstable
+	// environment, whose numbers would mislead.
+	Status string `json:"status,omitempty"` // "failed" when terminated abnormally, "environment-degraded" when unstable, empty otherwise
+	Error  string `json:"error,omitempty"`  // How the run terminated, or what made the environment unstable
+	Stderr string `json:"stderr,omitempty"` // Standard error of go test, truncated to its end
+}
+
+// StressMatrix records the parallelism levels a 'run -stress' session
+// measured. Each benchmark ran once per level with GOMAXPROCS set to it,
+// and so with that many RunParallel goroutines, its results named with the
+// usual "-N" suffix, which go test leaves out for a level of 1.
+type StressMatrix struct {
+	Levels     []int    `json:"levels"`
+	CPUs       int      `json:"cpus"`                 // Logical CPUs of the machine the levels ran on
+	Benchmarks []string `json:"benchmarks,omitempty"` // Benchmark functions calling RunParallel, without the "Benchmark" prefix
+}
+
+// Startup records how a 'run -startup' session measured the startup time
+// of a program: how often it started a process from the built binary, with
+// which arguments, and what told that a process was ready
+type Startup struct {
+	Args       []string `json:"args,omitempty"`
+	Ready      string   `json:"ready"` // "exit", an http(s) URL, tcp://host:port or output:<pattern>
+	Iterations int      `json:"iterations"`
+}
+
+// LoadTest records the HTTP load a 'run -load' session sent to a service
+// and the responses it received
+type LoadTest struct {
+	URL         string        `json:"url"`
+	Method      string        `json:"method"`
+	Duration    time.Duration `json:"duration"`
+	Rate        float64       `json:"rate,omitempty"` // Requests per second; 0 when sent as fast as the concurrency allowed
+	Concurrency int           `json:"concurrency"`
+	Requests    int           `json:"requests"`
+	Errors      int           `json:"errors,omitempty"`
+	StatusCodes map[int]int   `json:"status_codes,omitempty"`
+}
+
+// Toolchain records the Go build settings that affect generated code. Only
+// the microarchitecture level of the target GOARCH is recorded.
+type Toolchain struct {
+	GOOS       string `json:"goos,omitempty"`
+	GOARCH     string `json:"goarch,omitempty"`
+	GOAMD64    string `json:"goamd64,omitempty"`
+	GOARM      string `json:"goarm,omitempty"`
+	GOARM64    string `json:"goarm64,omitempty"`
+	CGOEnabled string `json:"cgo_enabled,omitempty"`
+	GOFLAGS    string `json:"goflags,omitempty"`
+	GCFlags    string `json:"gcflags,omitempty"` // Explicit -gcflags, from the command line or GOFLAGS
+}
+
+// Environment fingerprints the machine a run executed on, so that
+// comparisons can flag runs from different hardware or conditions. Fields
+// that could not be read on the platform are left empty.
+type Environment struct {
+	CPUModel    string  `json:"cpu_model,omitempty"`
+	CPUs        int     `json:"cpus,omitempty"`         // Logical CPUs
+	GOMAXPROCS  int     `json:"gomaxprocs,omitempty"`   // As seen by gokanon, including GOMAXPROCS from the environment
+	OS          string  `json:"os,omitempty"`           // Operating system of the machine, e.g. "linux"
+	Kernel      string  `json:"kernel,omitempty"`       // Kernel release
+	LoadAverage float64 `json:"load_average,omitempty"` // One-minute load average when the run started
+	Governor    string  `json:"governor,omitempty"`     // CPU frequency scaling governor
+	CPULimit    float64 `json:"cpu_limit,omitempty"`    // CPUs allowed by the container's cgroup, 0 when unlimited
+	MemoryLimit int64   `json:"memory_limit,omitempty"` // Bytes allowed by the container's cgroup, 0 when unlimited
+}
+
+// Dependencies records the module dependencies of the benchmarked module
+type Dependencies struct {
+	Module    string            `json:"module,omitempty"`      // Path of the main module
+	Modules   map[string]string `json:"modules,omitempty"`     // Required module path -> version, after replacements
+	GoSumHash string            `json:"go_sum_hash,omitempty"` // SHA-256 of go.sum
+}
+
+// Failed reports whether the run terminated abnormally
+func (r *BenchmarkRun) Failed() bool {
+	return r.Status == StatusFailed
+}
+
+// Degraded reports whether the run was recorded in an unstable environment
+func (r *BenchmarkRun) Degraded() bool {
+	return r.Status == StatusDegraded
+}
+
+// WithoutFailed returns the runs that did not terminate abnormally nor run
+// in an unstable environment
+func WithoutFailed(runs []BenchmarkRun) []BenchmarkRun {
+	var kept []BenchmarkRun
+	for i := range runs {
+		if !runs[i].Failed() && !runs[i].Degraded() {
+			kept = append(kept, runs[i])
+		}
+	}
+	return kept
+}
+
+// CountStatus returns the number of results with the given status
+func (r *BenchmarkRun) CountStatus(status string) int {
+	count := 0
+	for _, result := range r.Results {
+		s := result.Status
+		if s == "" {
+			s = StatusOK
+		}
+		if s == status {
+			count++
+		}
+	}
+	return count
+}
+
+// AttachedProfile describes a profile file attached to a run in addition to
+// the CPU and memory profiles collected by the runner (e.g. fgprof, wall-clock)
+type AttachedProfile struct {
+	Name       string `json:"name"`                  // Identifier used for storage and display
+	Path       string `json:"path"`                  // Path to the stored profile file
+	SampleType string `json:"sample_type,omitempty"` // Sample type to analyze (empty = profile default)
+}
+
+// PackageRun summarizes the benchmarks of one package of a run covering
+// several, each of which is benchmarked by its own go test
+type PackageRun struct {
+	Package    string        `json:"package"`          // Import path
+	Benchmarks int           `json:"benchmarks"`       // Number of results
+	Duration   time.Duration `json:"duration"`         // Time go test took, build included
+	Status     string        `json:"status,omitempty"` // StatusFailed when go test terminated abnormally, e.g. on a build failure
+	Error      string        `json:"error,omitempty"`  // Why go test terminated abnormally
+}
+
+// Failed reports whether go test terminated abnormally for the package
+func (p PackageRun) Failed() bool {
+	return p.Status == StatusFailed
 }
 
 // Comparison represents the difference between two benchmark results
 type Comparison struct {
 	Name         string  `json:"name"`
+	Package      string  `json:"package,omitempty"` // Import path of the new benchmark, or of the old one when removed
 	OldNsPerOp   float64 `json:"old_ns_per_op"`
 	NewNsPerOp   float64 `json:"new_ns_per_op"`
 	Delta        float64 `json:"delta"`
 	DeltaPercent float64 `json:"delta_percent"`
-	Status       string  `json:"status"` // "improved", "degraded", "same"
+	Status       string  `json:"status"` // "improved", "degraded", "same", "failed", "skipped", "added", "removed"
+
+	Message string             `json:"message,omitempty"` // Failure or skip reason of the new result
+	Doc     string             `json:"doc,omitempty"`     // Doc comment of the benchmark function
+	Metrics []MetricComparison `json:"metrics,omitempty"` // Custom metrics present in both results
+	Unit    string             `json:"unit,omitempty"`    // Unit of the ns/op fields when not ns/op, e.g. for normalized runs
+
+	// Standard metrics, each with its own status so that a memory
+	// regression is not hidden by stable timing. B/op and allocs/op are set
+	// when both runs were measured with -benchmem, MB/s when both results
+	// report a throughput.
+	BytesPerOp  *MetricComparison `json:"bytes_per_op,omitempty"`
+	AllocsPerOp *MetricComparison `json:"allocs_per_op,omitempty"`
+	MBPerSec    *MetricComparison `json:"mb_per_sec,omitempty"`
+
+	// Change of the block and mutex delay per operation, set when both runs
+	// profiled the same kinds of contention
+	Contention *MetricComparison `json:"contention,omitempty"`
+
+	// Set when both results have multiple samples
+	OldCI      float64  `json:"old_ci,omitempty"`      // Half-width of the 95% confidence interval of OldNsPerOp
+	NewCI      float64  `json:"new_ci,omitempty"`      // Half-width of the 95% confidence interval of NewNsPerOp
+	OldSamples int      `json:"old_samples,omitempty"` // Number of old samples
+	NewSamples int      `json:"new_samples,omitempty"` // Number of new samples
+	PValue     *float64 `json:"p_value,omitempty"`     // Mann-Whitney U test p-value of the samples
+
+	Confidence string `json:"confidence,omitempty"` // Worse confidence grade of the old and new results
+}
+
+// SignificanceLevel is the p-value below which a difference between
+// samples is considered statistically significant
+const SignificanceLevel = 0.05
+
+// Significant reports whether the change is statistically significant.
+// Changes without samples to test are assumed to be significant.
+func (c Comparison) Significant() bool {
+	return c.PValue == nil || *c.PValue <= SignificanceLevel
+}
+
+// StandardMetrics returns the standard metric comparisons that are set, in
+// the order B/op, allocs/op, MB/s
+func (c Comparison) StandardMetrics() []MetricComparison {
+	var metrics []MetricComparison
+	for _, m := range []*MetricComparison{c.BytesPerOp, c.AllocsPerOp, c.MBPerSec} {
+		if m != nil {
+			metrics = append(metrics, *m)
+		}
+	}
+	return metrics
+}
+
+// ValueUnit returns the unit of the OldNsPerOp and NewNsPerOp values
+func (c Comparison) ValueUnit() string {
+	if c.Unit != "" {
+		return c.Unit
+	}
+	return "ns/op"
+}
+
+// Comparison statuses for benchmarks present in only one of the compared runs
+const (
+	StatusAdded   = "added"
+	StatusRemoved = "removed"
+)
+
+// MetricComparison represents the change of a custom or standard metric
+// between two results
+type MetricComparison struct {
+	Name         string  `json:"name"`
+	Old          float64 `json:"old"`
+	New          float64 `json:"new"`
+	Delta        float64 `json:"delta"`
+	DeltaPercent float64 `json:"delta_percent"`
+	Status       string  `json:"status,omitempty"` // "improved", "degraded" or "same" for standard metrics
 }
 
 // ProfileSummary contains analyzed profile data
@@ -41,10 +387,35 @@ type ProfileSummary struct {
 	CPUTopFunctions    []FunctionProfile `json:"cpu_top_functions,omitempty"`
 	MemoryTopFunctions []FunctionProfile `json:"memory_top_functions,omitempty"`
 	MemoryLeaks        []MemoryLeak      `json:"memory_leaks,omitempty"`
+	MemoryGrowth       []MemoryGrowth    `json:"memory_growth,omitempty"`
 	HotPaths           []HotPath         `json:"hot_paths,omitempty"`
 	Suggestions        []Suggestion      `json:"suggestions,omitempty"`
 	TotalCPUSamples    int64             `json:"total_cpu_samples,omitempty"`
 	TotalMemoryBytes   int64             `json:"total_memory_bytes,omitempty"`
+	CustomProfiles     []CustomProfile   `json:"custom_profiles,omitempty"`
+
+	ContentionHotspots []ContentionHotspot `json:"contention_hotspots,omitempty"`
+	TotalBlockDelay    int64               `json:"total_block_delay,omitempty"` // Nanoseconds goroutines spent blocked
+	TotalMutexDelay    int64               `json:"total_mutex_delay,omitempty"` // Nanoseconds goroutines waited for contended mutexes
+
+	// Block and mutex delay in nanoseconds by benchmark function, without
+	// the Benchmark prefix
+	BenchmarkContention map[string]int64 `json:"benchmark_contention,omitempty"`
+}
+
+// TotalContention returns the nanoseconds goroutines spent blocked or
+// waiting for contended mutexes
+func (s *ProfileSummary) TotalContention() int64 {
+	return s.TotalBlockDelay + s.TotalMutexDelay
+}
+
+// CustomProfile contains the analysis of a profile using an arbitrary sample type
+type CustomProfile struct {
+	Name         string            `json:"name"`
+	SampleType   string            `json:"sample_type"`
+	Unit         string            `json:"unit"`
+	Total        int64             `json:"total"`
+	TopFunctions []FunctionProfile `json:"top_functions,omitempty"`
 }
 
 // FunctionProfile represents a function's profile metrics
@@ -65,6 +436,30 @@ type MemoryLeak struct {
 	Description string `json:"description"`
 }
 
+// MemoryGrowth is the change of a function's in-use heap memory between
+// the heap profile taken after warmup and the one taken at the end of a run.
+// Memory that keeps growing with the number of iterations is retained
+// beyond them, the clearest sign of a leak.
+type MemoryGrowth struct {
+	Function    string `json:"function"`
+	WarmupBytes int64  `json:"warmup_bytes"` // In use after warmup
+	FinalBytes  int64  `json:"final_bytes"`  // In use at the end of the run
+	GrowthBytes int64  `json:"growth_bytes"`
+}
+
+// ContentionHotspot is a function where goroutines waited on
+// synchronization, from the block or mutex profile. In the block profile it
+// is the function that waited; in the mutex profile the one that held the
+// lock others waited for.
+type ContentionHotspot struct {
+	Function    string  `json:"function"`
+	Profile     string  `json:"profile"`     // "block" or "mutex"
+	Primitive   string  `json:"primitive"`   // Runtime or sync function waited in, e.g. "sync.(*Mutex).Lock"
+	Contentions int64   `json:"contentions"` // Number of contention events
+	DelayNs     int64   `json:"delay_ns"`    // Total time waited
+	Percentage  float64 `json:"percentage"`  // Share of the profile's total delay
+}
+
 // HotPath represents a critical execution path
 type HotPath struct {
 	Path        []string `json:"path"`        // Call stack
@@ -75,12 +470,27 @@ type HotPath struct {
 
 // Suggestion represents an optimization suggestion
 type Suggestion struct {
-	Type       string `json:"type"`     // "cpu", "memory", "algorithm"
-	Severity   string `json:"severity"` // "low", "medium", "high"
-	Function   string `json:"function"`
-	Issue      string `json:"issue"`
-	Suggestion string `json:"suggestion"`
-	Impact     string `json:"impact"` // Expected performance improvement
+	RuleID     string   `json:"rule_id,omitempty"`    // Rule that produced the suggestion, e.g. "cpu-hot-function"
+	Type       string   `json:"type"`                 // "cpu", "memory", "algorithm"
+	Severity   string   `json:"severity"`             // "low", "medium", "high"
+	Confidence float64  `json:"confidence,omitempty"` // How strongly the profile supports the issue, 0-1
+	Function   string   `json:"function"`
+	Issue      string   `json:"issue"`
+	Suggestion string   `json:"suggestion"`
+	Impact     string   `json:"impact"`               // Expected performance improvement
+	Profile    string   `json:"profile,omitempty"`    // Profile of the run showing the issue: "cpu", "memory", "warmup", "block" or "mutex"
+	Benchmarks []string `json:"benchmarks,omitempty"` // Benchmarks whose samples include the function
+}
+
+// Score ranks a suggestion by its severity weighted by its confidence.
+// Suggestions without a confidence, such as AI ones, count as half sure.
+func (s Suggestion) Score() float64 {
+	weight := map[string]float64{"high": 3, "medium": 2, "low": 1}[s.Severity]
+	confidence := s.Confidence
+	if confidence == 0 {
+		confidence = 0.5
+	}
+	return weight * confidence
 }
 
 // Baseline represents a saved baseline benchmark run
@@ -92,3 +502,92 @@ type Baseline struct {
 	Run         *BenchmarkRun     `json:"run,omitempty"`  // Full benchmark run data
 	Tags        map[string]string `json:"tags,omitempty"` // Additional metadata tags
 }
+
+// AIAnalysis is an analysis answered by an AI provider, kept so that the
+// same question is not asked again and can be replayed offline
+type AIAnalysis struct {
+	Key        string    `json:"key"`         // Identifies the runs, provider, model and prompt
+	Kind       string    `json:"kind"`        // "comparison" or "profile"
+	RunIDs     []string  `json:"run_ids"`     // Runs analyzed, the older first
+	Provider   string    `json:"provider"`    // e.g. "ollama"
+	Model      string    `json:"model"`       // e.g. "llama3.2"
+	PromptHash string    `json:"prompt_hash"` // SHA-256 of the prompt, hex-encoded
+	Response   string    `json:"response"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Snapshot freezes the dashboard's statistics and trends at a point in time,
+// such as a release, so they can be browsed after newer runs replace them
+type Snapshot struct {
+	Name        string                   `json:"name"`                  // Snapshot identifier (e.g., "v2.3.0")
+	CreatedAt   time.Time                `json:"created_at"`            // When the snapshot was taken
+	Description string                   `json:"description,omitempty"` // Optional description
+	Stats       map[string]interface{}   `json:"stats"`                 // Dashboard statistics (as served by /api/stats)
+	Trends      map[string]interface{}   `json:"trends"`                // Dashboard trends (as served by /api/trends)
+	Runs        []map[string]interface{} `json:"runs"`                  // Run summaries (as served by /api/runs)
+}
+
+// Annotation is a note added to a saved run, e.g. to record a change of
+// the environment that explains its results
+type Annotation struct {
+	Time    time.Time `json:"time"`           // When the annotation was added
+	Kind    string    `json:"kind,omitempty"` // Category, e.g. upgrade, infra or config
+	Message string    `json:"message"`
+}
+
+// String returns the message, prefixed by the kind if any
+func (a Annotation) String() string {
+	if a.Kind == "" {
+		return a.Message
+	}
+	return "[" + a.Kind + "] " + a.Message
+}
+
+// LiveRun describes a benchmark run in progress, as shown by the dashboard's live view
+type LiveRun struct {
+	PID              int               `json:"pid"`
+	Package          string            `json:"package"`
+	StartedAt        time.Time         `json:"started_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	Current          string            `json:"current,omitempty"`            // Benchmark currently executing
+	CurrentStartedAt time.Time         `json:"current_started_at,omitempty"` // When the current benchmark started
+	Completed        []BenchmarkResult `json:"completed"`                    // Results so far, in output order
+	Expected         int               `json:"expected,omitempty"`           // Results of the previous run of the package, 0 if unknown
+}
+
+// DaemonStatus describes the scheduled runs of 'gokanon daemon', as shown
+// by the dashboard
+type DaemonStatus struct {
+	PID       int              `json:"pid"`
+	StartedAt time.Time        `json:"started_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Running   bool             `json:"running"` // Whether the daemon process is alive, set when the status is loaded
+	Schedules []ScheduleStatus `json:"schedules"`
+}
+
+// ScheduleStatus is the state of a schedule of the daemon
+type ScheduleStatus struct {
+	Name        string    `json:"name"`
+	Cron        string    `json:"cron"`
+	Next        time.Time `json:"next"`                  // When the schedule is next due
+	LastRun     time.Time `json:"last_run,omitempty"`    // When the last run started
+	LastRunID   string    `json:"last_run_id,omitempty"` // Run saved by the last run, if any
+	LastStatus  string    `json:"last_status,omitempty"` // "ok", "regressed", "failed" or "environment-degraded"
+	LastError   string    `json:"last_error,omitempty"`
+	Regressions int       `json:"regressions,omitempty"` // Benchmarks of the last run beyond the threshold
+}
+
+// Remaining estimates the time left from the average duration of the
+// benchmarks completed so far. It reports false when no estimate is possible.
+func (l *LiveRun) Remaining(now time.Time) (time.Duration, bool) {
+	done := len(l.Completed)
+	if done == 0 || l.Expected == 0 {
+		return 0, false
+	}
+	left := l.Expected - done
+	if left <= 0 {
+		return 0, true
+	}
+	perBenchmark := now.Sub(l.StartedAt) / time.Duration(done)
+	return perBenchmark * time.Duration(left), true
+}
@@ -126,3 +126,159 @@ func TestComparisonStatuses(t *testing.T) {
 		})
 	}
 }
+
+func TestBenchmarkResultMeasured(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{"", true},
+		{StatusOK, true},
+		{StatusFailed, false},
+		{StatusSkipped, false},
+	}
+
+	for _, tt := range tests {
+		result := BenchmarkResult{Name: "BenchmarkTest", Status: tt.status}
+		if got := result.Measured(); got != tt.want {
+			t.Errorf("Measured() with status %q = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestBenchmarkRunCountStatus(t *testing.T) {
+	run := &BenchmarkRun{
+		Results: []BenchmarkResult{
+			{Name: "Legacy"},
+			{Name: "OK", Status: StatusOK},
+			{Name: "Failed", Status: StatusFailed},
+			{Name: "Skipped", Status: StatusSkipped},
+			{Name: "Failed2", Status: StatusFailed},
+		},
+	}
+
+	if got := run.CountStatus(StatusOK); got != 2 {
+		t.Errorf("CountStatus(ok) = %d, want 2 (empty status counts as ok)", got)
+	}
+	if got := run.CountStatus(StatusFailed); got != 2 {
+		t.Errorf("CountStatus(failed) = %d, want 2", got)
+	}
+	if got := run.CountStatus(StatusSkipped); got != 1 {
+		t.Errorf("CountStatus(skipped) = %d, want 1", got)
+	}
+}
+
+func TestWithoutFailed(t *testing.T) {
+	runs := []BenchmarkRun{
+		{ID: "run-1"},
+		{ID: "run-2", Status: StatusFailed},
+		{ID: "run-3", Status: StatusOK},
+		{ID: "run-4", Status: StatusDegraded},
+	}
+
+	kept := WithoutFailed(runs)
+	if len(kept) != 2 || kept[0].ID != "run-1" || kept[1].ID != "run-3" {
+		t.Errorf("WithoutFailed = %+v, want run-1 and run-3", kept)
+	}
+	if !runs[3].Degraded() || runs[3].Failed() {
+		t.Error("Expected the degraded run to be degraded but not failed")
+	}
+	if !runs[1].Failed() || runs[0].Failed() {
+		t.Error("Expected only the run with a failed status to have failed")
+	}
+}
+
+func TestLiveRunRemaining(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start.Add(30 * time.Second)
+	done := []BenchmarkResult{{Name: "A"}, {Name: "B"}, {Name: "C"}}
+
+	tests := []struct {
+		name   string
+		live   LiveRun
+		want   time.Duration
+		wantOK bool
+	}{
+		{"no results yet", LiveRun{StartedAt: start, Expected: 5}, 0, false},
+		{"unknown total", LiveRun{StartedAt: start, Completed: done}, 0, false},
+		{"two left", LiveRun{StartedAt: start, Completed: done, Expected: 5}, 20 * time.Second, true},
+		{"more than expected", LiveRun{StartedAt: start, Completed: done, Expected: 2}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.live.Remaining(now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Remaining() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDocSynopsis(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"", ""},
+		{"BenchmarkEncode measures encoding", "BenchmarkEncode measures encoding"},
+		{"BenchmarkEncode measures encoding. It allocates once.", "BenchmarkEncode measures encoding."},
+		{"BenchmarkEncode measures\nencoding 1 KiB\nmessages", "BenchmarkEncode measures encoding 1 KiB messages"},
+		{"BenchmarkEncode measures encoding\n\nSecond paragraph.", "BenchmarkEncode measures encoding"},
+		{"  Uses v1.2 of the codec  ", "Uses v1.2 of the codec"},
+	}
+
+	for _, tt := range tests {
+		if got := DocSynopsis(tt.doc); got != tt.want {
+			t.Errorf("DocSynopsis(%q) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestSplitBenchmarkName(t *testing.T) {
+	tests := []struct {
+		name    string
+		family  string
+		variant string
+	}{
+		{"Encode", "Encode", ""},
+		{"Encode-8", "Encode", ""},
+		{"Parse-Fast-16", "Parse-Fast", ""},
+		{"Parse/small-8", "Parse", "small"},
+		{"Parse/size=1K", "Parse", "size=1K"},
+		{"Table/sub/nested-4", "Table", "sub/nested"},
+	}
+
+	for _, tt := range tests {
+		family, variant := SplitBenchmarkName(tt.name)
+		if family != tt.family || variant != tt.variant {
+			t.Errorf("SplitBenchmarkName(%q) = %q, %q, want %q, %q", tt.name, family, variant, tt.family, tt.variant)
+		}
+	}
+}
+
+func TestBenchmarkResultFamily(t *testing.T) {
+	// Results stored before parents were recorded are split by name
+	if got := (BenchmarkResult{Name: "Parse/small-8"}).Family(); got != "Parse" {
+		t.Errorf("Family() = %q, want Parse", got)
+	}
+	if got := (BenchmarkResult{Name: "Parse/small-8", Parent: "Parser"}).Family(); got != "Parser" {
+		t.Errorf("Family() = %q, want the recorded parent", got)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	var a Aggregate
+	if a.Variance() != 0 {
+		t.Errorf("Expected no variance without values, got %v", a.Variance())
+	}
+	for _, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		a.Add(x)
+	}
+	if a.Count != 8 || a.Mean != 5 || a.Min != 2 || a.Max != 9 {
+		t.Errorf("Unexpected aggregate: %+v", a)
+	}
+	if a.Variance() != 4 || a.StdDev() != 2 {
+		t.Errorf("Variance = %v, StdDev = %v, want 4 and 2", a.Variance(), a.StdDev())
+	}
+}
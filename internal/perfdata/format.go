@@ -0,0 +1,144 @@
+// Package perfdata uploads runs to a golang.org/x/perf/storage server,
+// such as perfdata.golang.org, so that gokanon can run benchmarks for a
+// perf data server that remains the system of record.
+package perfdata
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Labels that gokanon sets on uploaded runs. Tags named like one of them
+// or like a label of the Go benchmark format are not uploaded.
+const (
+	LabelRun     = "gokanon-run"  // ID of the run in the gokanon storage
+	LabelRunTime = "run-time"     // When the run started, RFC 3339
+	LabelNote    = "gokanon-note" // Note given with 'run -note'
+)
+
+// reservedLabels are set from the run rather than from its tags
+var reservedLabels = map[string]bool{
+	"goos": true, "goarch": true, "cpu": true, "pkg": true, "commit": true,
+	LabelRun: true, LabelRunTime: true, LabelNote: true,
+	// Set by the storage server on upload
+	"upload": true, "upload-time": true, "upload-part": true, "upload-file": true, "by": true,
+}
+
+// Write writes a run in the Go benchmark data format that perf storage
+// servers accept. The run's platform, commit, ID and tags become
+// configuration lines, which the server indexes as labels; each sample of
+// a result becomes a benchmark line, so that repetitions survive the
+// upload. Benchmarks that failed or were skipped are left out.
+func Write(w io.Writer, run *models.BenchmarkRun) error {
+	bw := bufio.NewWriter(w)
+	label := func(key, value string) {
+		if value = oneLine(value); value != "" {
+			fmt.Fprintf(bw, "%s: %s\n", key, value)
+		}
+	}
+
+	if tc := run.Toolchain; tc != nil {
+		label("goos", tc.GOOS)
+		label("goarch", tc.GOARCH)
+	} else if run.Environment != nil {
+		label("goos", run.Environment.OS)
+	}
+	if run.Environment != nil {
+		label("cpu", run.Environment.CPUModel)
+	}
+	label("commit", run.Commit)
+	label(LabelRun, run.ID)
+	if !run.Timestamp.IsZero() {
+		label(LabelRunTime, run.Timestamp.UTC().Format(time.RFC3339))
+	}
+	label(LabelNote, run.Note)
+	for _, name := range slices.Sorted(maps.Keys(run.Tags)) {
+		if key := LabelKey(name); key != "" && !reservedLabels[key] {
+			label(key, run.Tags[name])
+		}
+	}
+
+	pkg := ""
+	for _, result := range run.Results {
+		if !result.Measured() {
+			continue
+		}
+		if result.Package != pkg {
+			pkg = result.Package
+			fmt.Fprintf(bw, "pkg: %s\n", pkg)
+		}
+		writeResult(bw, result)
+	}
+	return bw.Flush()
+}
+
+// writeResult writes one benchmark line per sample of a result
+func writeResult(w io.Writer, result models.BenchmarkResult) {
+	name := "Benchmark" + strings.Join(strings.Fields(result.Name), "_")
+	iterations := max(result.Iterations, 1)
+
+	// Units other than ns/op were not recorded per repetition, so every
+	// line repeats them
+	var rest strings.Builder
+	if result.MBPerSec > 0 {
+		fmt.Fprintf(&rest, "\t%s MB/s", formatValue(result.MBPerSec))
+	}
+	if result.BytesPerOp > 0 || result.AllocsPerOp > 0 {
+		fmt.Fprintf(&rest, "\t%d B/op\t%d allocs/op", result.BytesPerOp, result.AllocsPerOp)
+	}
+	if result.ContentionNsPerOp > 0 {
+		fmt.Fprintf(&rest, "\t%s %s", formatValue(result.ContentionNsPerOp), models.ContentionMetric)
+	}
+	for _, metric := range slices.Sorted(maps.Keys(result.Metrics)) {
+		if unit := strings.Join(strings.Fields(metric), "_"); unit != "" {
+			fmt.Fprintf(&rest, "\t%s %s", formatValue(result.Metrics[metric]), unit)
+		}
+	}
+
+	samples := result.Samples
+	if len(samples) == 0 {
+		samples = []float64{result.NsPerOp}
+	}
+	for _, ns := range samples {
+		fmt.Fprintf(w, "%s\t%d\t%s ns/op%s\n", name, iterations, formatValue(ns), rest.String())
+	}
+}
+
+// formatValue formats a value the way go test does, without an exponent
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// LabelKey turns a tag name into a label key: configuration keys start
+// with a lowercase letter and hold no upper case letters, spaces or
+// colons. It returns "" for a name that cannot be one.
+func LabelKey(name string) string {
+	key := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r), r == ':':
+			return '-'
+		case unicode.IsUpper(r):
+			return unicode.ToLower(r)
+		}
+		return r
+	}, strings.TrimSpace(name))
+	key = strings.Trim(key, "-")
+	if key == "" || !unicode.IsLower([]rune(key)[0]) {
+		return ""
+	}
+	return key
+}
+
+// oneLine keeps a label value on a single line
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
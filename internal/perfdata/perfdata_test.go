@@ -0,0 +1,128 @@
+package perfdata
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/runner"
+)
+
+func testRun() *models.BenchmarkRun {
+	return &models.BenchmarkRun{
+		ID:          "run-1",
+		Timestamp:   time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Commit:      "abc123",
+		Toolchain:   &models.Toolchain{GOOS: "linux", GOARCH: "amd64"},
+		Environment: &models.Environment{CPUModel: "Example CPU @ 3.00GHz"},
+		Tags:        map[string]string{"Branch": "main", "commit": "ignored", "Build Mode": "release\nstripped"},
+		Results: []models.BenchmarkResult{
+			{Name: "Parse-8", Package: "example.com/codec", Iterations: 1000, NsPerOp: 110, Samples: []float64{100, 120}, BytesPerOp: 64, AllocsPerOp: 2},
+			{Name: "Broken-8", Package: "example.com/codec", Status: models.StatusFailed},
+			{Name: "Encode/large-8", Package: "example.com/codec", Iterations: 50, NsPerOp: 2500000000, MBPerSec: 12.5, Metrics: map[string]float64{"items/s": 5000}},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var out strings.Builder
+	if err := Write(&out, testRun()); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"goos: linux\n", "goarch: amd64\n", "cpu: Example CPU @ 3.00GHz\n", "commit: abc123\n",
+		"gokanon-run: run-1\n", "run-time: 2026-03-01T12:00:00Z\n",
+		"branch: main\n", "build-mode: release stripped\n", "pkg: example.com/codec\n",
+		"BenchmarkParse-8\t1000\t100 ns/op\t64 B/op\t2 allocs/op\n",
+		"BenchmarkParse-8\t1000\t120 ns/op\t64 B/op\t2 allocs/op\n",
+		"BenchmarkEncode/large-8\t50\t2500000000 ns/op\t12.5 MB/s\t5000 items/s\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Output lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Broken") || strings.Contains(text, "ignored") {
+		t.Errorf("Output holds a failed benchmark or a reserved tag:\n%s", text)
+	}
+
+	// What the server returns for the upload imports as the run
+	runs, err := runner.ImportPerf(strings.NewReader("upload: 7\n"+text), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := runs[0]
+	if !run.Timestamp.Equal(testRun().Timestamp) || run.Commit != "abc123" || run.Tags[LabelRun] != "run-1" || run.Tags["branch"] != "main" {
+		t.Errorf("Imported run: time %v, commit %q, tags %v", run.Timestamp, run.Commit, run.Tags)
+	}
+	if len(run.Results) != 2 || len(run.Results[0].Samples) != 2 || run.Results[0].AllocsPerOp != 2 {
+		t.Errorf("Imported results = %+v", run.Results)
+	}
+}
+
+func TestLabelKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"branch":      "branch",
+		"Build Mode":  "build-mode",
+		" CI:job ":    "ci-job",
+		"1st-attempt": "",
+		"-":           "",
+	} {
+		if got := LabelKey(name); got != want {
+			t.Errorf("LabelKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestUpload(t *testing.T) {
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/upload" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unexpected request", http.StatusForbidden)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		uploaded = string(data)
+		json.NewEncoder(w).Encode(UploadStatus{UploadID: "20260301.1", FileIDs: []string{"20260301.1/0"}})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := client.Upload(testRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.UploadID != "20260301.1" || len(status.FileIDs) != 1 {
+		t.Errorf("Status = %+v", status)
+	}
+	if !strings.Contains(uploaded, "gokanon-run: run-1\n") {
+		t.Errorf("Uploaded file:\n%s", uploaded)
+	}
+
+	// Errors of the server are reported
+	client, _ = NewClient(server.URL, "wrong")
+	if _, err := client.Upload(testRun()); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Upload with a wrong token: %v", err)
+	}
+}
+
+func TestNewClientInvalidURL(t *testing.T) {
+	for _, url := range []string{"", "perf.internal", "ftp://perf.internal"} {
+		if _, err := NewClient(url, ""); err == nil {
+			t.Errorf("NewClient(%q) succeeded, want an error", url)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package perfdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// requestTimeout bounds how long an upload waits for the server
+const requestTimeout = 60 * time.Second
+
+// UploadStatus is the response of a perf storage server to an upload
+type UploadStatus struct {
+	UploadID string   `json:"uploadid"`          // Identifies the upload in queries, as "upload:<id>"
+	FileIDs  []string `json:"fileids"`           // One per file uploaded
+	ViewURL  string   `json:"viewurl,omitempty"` // Page of the uploaded results, if the server has one
+}
+
+// Client uploads runs to a perf storage server
+type Client struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewClient creates a client of the perf storage server at baseURL, e.g.
+// "https://perfdata.golang.org". A non-empty token is sent as a bearer
+// token, for servers behind an authenticating proxy.
+func NewClient(baseURL, token string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("perf data server must be an http or https URL, got %q", baseURL)
+	}
+	return &Client{
+		url:    strings.TrimSuffix(baseURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// URL returns the base URL of the server
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Upload uploads a run as one file, so that the upload ID the server
+// assigns identifies the run there
+func (c *Client) Upload(run *models.BenchmarkRun) (*UploadStatus, error) {
+	var file bytes.Buffer
+	if err := Write(&file, run); err != nil {
+		return nil, fmt.Errorf("failed to format run: %w", err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", run.ID+".txt")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(file.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.url+"/upload", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s responded with status %d: %s", req.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var status UploadStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", req.URL, err)
+	}
+	if status.UploadID == "" {
+		return nil, fmt.Errorf("%s responded without an upload ID", req.URL)
+	}
+	return &status, nil
+}
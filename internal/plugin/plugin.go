@@ -0,0 +1,131 @@
+// Package plugin runs gokanon-<name> executables found on PATH as
+// 'gokanon <name>', so that commands can be added without forking the CLI.
+// A plugin gets its arguments as is, the storage and configuration it
+// should use in GOKANON_* environment variables, and the same as a JSON
+// Context on stdin.
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix is the prefix of plugin executables
+const Prefix = "gokanon-"
+
+// namePattern is what a plugin name may look like, so that a command is
+// never taken for a path
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Context is what a plugin is told about the invocation, as JSON on stdin
+type Context struct {
+	Version     string   `json:"version"`    // gokanon version
+	Executable  string   `json:"executable"` // gokanon binary, to run other commands with
+	Command     string   `json:"command"`    // Plugin name, as typed after gokanon
+	Args        []string `json:"args"`
+	WorkingDir  string   `json:"working_dir"`
+	Storage     string   `json:"storage"` // Storage directory the built-in commands would use
+	Config      string   `json:"config"`  // Configuration file the built-in commands would use
+	ProjectRoot string   `json:"project_root,omitempty"`
+}
+
+// Plugin is a plugin executable
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// ExitError reports that a plugin exited with a non-zero status, which
+// gokanon exits with too
+type ExitError struct {
+	Name string
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("plugin %s exited with status %d", e.Name, e.Code)
+}
+
+// Find returns the executable of the plugin name on PATH
+func Find(name string) (string, error) {
+	if !namePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+	return exec.LookPath(Prefix + name)
+}
+
+// List returns the plugins on PATH sorted by name. A plugin found in
+// several directories is the one of the first, as for Find.
+func List() ([]Plugin, error) {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // Missing directories on PATH are common
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), Prefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if !ok || seen[name] || !namePattern.MatchString(name) {
+				continue
+			}
+			path, err := Find(name)
+			if err != nil || filepath.Dir(path) != filepath.Clean(dir) {
+				continue // Not executable
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// Run runs a plugin with the terminal's stdout and stderr, writing the
+// context to its stdin. A non-zero exit status is returned as *ExitError.
+func Run(path string, ctx Context) error {
+	input, err := json.Marshal(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal plugin context: %w", err)
+	}
+
+	cmd := exec.Command(path, ctx.Args...)
+	cmd.Stdin = strings.NewReader(string(input) + "\n")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GOKANON_VERSION="+ctx.Version,
+		"GOKANON_BIN="+ctx.Executable,
+		"GOKANON_STORAGE="+ctx.Storage,
+		"GOKANON_CONFIG="+ctx.Config,
+		"GOKANON_PROJECT_ROOT="+ctx.ProjectRoot,
+	)
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code <= 0 {
+			code = 1 // Killed by a signal
+		}
+		return &ExitError{Name: ctx.Command, Code: code}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", ctx.Command, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// installPlugin writes a shell script plugin into dir
+func installPlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, Prefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindAndList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	hello := installPlugin(t, first, "hello", "exit 0\n")
+	installPlugin(t, second, "hello", "exit 1\n") // Shadowed by the first
+	report := installPlugin(t, second, "report", "exit 0\n")
+	if err := os.WriteFile(filepath.Join(first, Prefix+"notes"), []byte("not executable"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	if path, err := Find("hello"); err != nil || path != hello {
+		t.Errorf("Find(hello) = %s, %v, want %s", path, err, hello)
+	}
+	if _, err := Find("../hello"); err == nil {
+		t.Error("Find accepted a path as a plugin name")
+	}
+	if _, err := Find("notes"); err == nil {
+		t.Error("Find returned a file that is not executable")
+	}
+
+	plugins, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	want := []Plugin{{Name: "hello", Path: hello}, {Name: "report", Path: report}}
+	if len(plugins) != len(want) || plugins[0] != want[0] || plugins[1] != want[1] {
+		t.Errorf("List() = %+v, want %+v", plugins, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	path := installPlugin(t, dir, "hello", `cat > "`+out+`"
+echo "$GOKANON_STORAGE $*" >> "`+out+`"
+exit "$1"
+`)
+
+	ctx := Context{Version: "1.2.3", Command: "hello", Args: []string{"0", "--flag"}, Storage: "/tmp/results", Config: "/tmp/.gokanon.yaml"}
+	if err := Run(path, ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var got Context
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("Plugin got invalid context %q: %v", lines[0], err)
+	}
+	if got.Version != "1.2.3" || got.Storage != "/tmp/results" || len(got.Args) != 2 {
+		t.Errorf("Plugin got context %+v", got)
+	}
+	if lines[len(lines)-1] != "/tmp/results 0 --flag" {
+		t.Errorf("Plugin got environment and arguments %q", lines[len(lines)-1])
+	}
+
+	// The exit status is passed on
+	ctx.Args = []string{"3"}
+	var exitErr *ExitError
+	if err := Run(path, ctx); !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Errorf("Run = %v, want exit status 3", err)
+	}
+}
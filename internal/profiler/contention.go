@@ -0,0 +1,155 @@
+package profiler
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/google/pprof/profile"
+)
+
+// maxContentionHotspots is the number of hotspots reported per profile
+const maxContentionHotspots = 10
+
+// loadContentionProfile parses a block or mutex profile, which records the
+// number of contentions and the delay they caused
+func loadContentionProfile(name string, data []byte) (*profile.Profile, error) {
+	prof, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s profile: %w", name, err)
+	}
+	if !hasSampleType(prof, "contentions") || !hasSampleType(prof, "delay") {
+		return nil, fmt.Errorf("invalid %s profile: expected contentions and delay samples, got %s",
+			name, strings.Join(sampleTypeNames(prof), ", "))
+	}
+	return prof, nil
+}
+
+// contentionHotspots attributes the delay of a block or mutex profile to
+// the functions that waited, in the block profile, or held the contended
+// lock, in the mutex profile: the innermost frames outside the runtime,
+// sync and testing packages. Samples waiting in the testing package, such
+// as a benchmark waiting for its RunParallel goroutines, are the harness
+// rather than the code under test, and are left out of the total as well.
+// The top hotspots are returned by delay with the total.
+func contentionHotspots(name string, prof *profile.Profile) ([]models.ContentionHotspot, int64) {
+	contentionsIdx, _ := sampleTypeIndex(prof, "contentions")
+	delayIdx, _ := sampleTypeIndex(prof, "delay")
+
+	type key struct{ function, primitive string }
+	stats := make(map[key]*models.ContentionHotspot)
+	var total int64
+	for _, sample := range prof.Sample {
+		function, primitive := contentionFrames(sample)
+		if function == "" {
+			continue
+		}
+		delay := sample.Value[delayIdx]
+		total += delay
+
+		k := key{function, primitive}
+		stat, ok := stats[k]
+		if !ok {
+			stat = &models.ContentionHotspot{
+				Function:  cleanFunctionName(function),
+				Profile:   name,
+				Primitive: cleanFunctionName(primitive),
+			}
+			stats[k] = stat
+		}
+		stat.Contentions += sample.Value[contentionsIdx]
+		stat.DelayNs += delay
+	}
+	if total == 0 {
+		return nil, 0
+	}
+
+	hotspots := make([]models.ContentionHotspot, 0, len(stats))
+	for _, stat := range stats {
+		stat.Percentage = float64(stat.DelayNs) / float64(total) * 100
+		hotspots = append(hotspots, *stat)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].DelayNs != hotspots[j].DelayNs {
+			return hotspots[i].DelayNs > hotspots[j].DelayNs
+		}
+		return hotspots[i].Function < hotspots[j].Function
+	})
+	if len(hotspots) > maxContentionHotspots {
+		hotspots = hotspots[:maxContentionHotspots]
+	}
+	return hotspots, total
+}
+
+// contentionByBenchmark attributes the delay counted by contentionHotspots
+// to the benchmark functions whose goroutines waited, keyed by name without
+// the Benchmark prefix. Samples outside any benchmark are left out.
+func contentionByBenchmark(prof *profile.Profile) map[string]int64 {
+	delayIdx, _ := sampleTypeIndex(prof, "delay")
+
+	delays := make(map[string]int64)
+	for _, sample := range prof.Sample {
+		if function, _ := contentionFrames(sample); function == "" {
+			continue
+		}
+		if benchmark := sampleBenchmark(sample); benchmark != "" {
+			delays[benchmark] += sample.Value[delayIdx]
+		}
+	}
+	return delays
+}
+
+// sampleBenchmark returns the outermost benchmark function on the stack of
+// a sample, without the Benchmark prefix
+func sampleBenchmark(sample *profile.Sample) string {
+	var benchmark string
+	for _, loc := range sample.Location {
+		for _, line := range loc.Line {
+			if line.Function == nil {
+				continue
+			}
+			if b := benchmarkName(cleanFunctionName(line.Function.Name)); b != "" {
+				benchmark = b
+			}
+		}
+	}
+	return benchmark
+}
+
+// contentionFrames returns the innermost function of a sample outside the
+// synchronization and testing internals, and the outermost of those
+// internals below it: the primitive it waited in, such as
+// sync.(*Mutex).Lock. The function is empty when there is none, or when
+// the sample waited in the testing package.
+func contentionFrames(sample *profile.Sample) (function, primitive string) {
+	for _, loc := range sample.Location {
+		for _, line := range loc.Line {
+			if line.Function == nil {
+				continue
+			}
+			name := line.Function.Name
+			if strings.HasPrefix(name, "testing.") {
+				return "", ""
+			}
+			if !isSyncInternal(name) {
+				return name, primitive
+			}
+			primitive = name
+		}
+	}
+	return "", ""
+}
+
+// isSyncInternal reports whether a function belongs to the runtime, the
+// sync packages or the testing package, whose frames are where goroutines
+// wait rather than why
+func isSyncInternal(function string) bool {
+	for _, prefix := range []string{"runtime.", "sync.", "sync/", "internal/", "testing."} {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
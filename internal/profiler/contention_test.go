@@ -0,0 +1,98 @@
+package profiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+// createContentionProfile creates a block or mutex profile with one
+// contention per stack, each listed from leaf to root, and its delay
+func createContentionProfile(t *testing.T, delays []int64, stacks ...[]string) []byte {
+	t.Helper()
+	prof := createStackProfile(stacks...)
+	prof.SampleType = []*profile.ValueType{
+		{Type: "contentions", Unit: "count"},
+		{Type: "delay", Unit: "nanoseconds"},
+	}
+	for i, sample := range prof.Sample {
+		sample.Value = []int64{1, delays[i]}
+	}
+	return encodeProfile(t, prof)
+}
+
+func TestContentionHotspots(t *testing.T) {
+	analyzer := NewAnalyzer()
+	block := createContentionProfile(t, []int64{600, 200, 200, 5000},
+		[]string{"runtime.semacquire", "sync.(*Mutex).lockSlow", "sync.(*Mutex).Lock", "example.com/pkg.(*Cache).Get", "example.com/pkg.BenchmarkGet.func1"},
+		[]string{"runtime.chanrecv1", "example.com/pkg.consume", "example.com/pkg.BenchmarkGet"},
+		[]string{"runtime.semacquire", "sync.(*Mutex).Lock", "example.com/pkg.(*Cache).Get"},
+		// The testing package waiting for the benchmark is left out
+		[]string{"runtime.chanrecv1", "testing.(*B).RunParallel", "example.com/pkg.BenchmarkGet"},
+	)
+	if err := analyzer.LoadBlockProfile(block); err != nil {
+		t.Fatalf("LoadBlockProfile failed: %v", err)
+	}
+	mutex := createContentionProfile(t, []int64{300},
+		[]string{"sync.(*Mutex).Unlock", "example.com/pkg.(*Cache).Put"},
+	)
+	if err := analyzer.LoadMutexProfile(mutex); err != nil {
+		t.Fatalf("LoadMutexProfile failed: %v", err)
+	}
+
+	summary, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if summary.TotalBlockDelay != 1000 || summary.TotalMutexDelay != 300 {
+		t.Errorf("Expected delays of 1000 and 300ns, got %d and %d", summary.TotalBlockDelay, summary.TotalMutexDelay)
+	}
+
+	// Only samples on a benchmark's stack are attributed to it
+	if len(summary.BenchmarkContention) != 1 || summary.BenchmarkContention["Get"] != 800 {
+		t.Errorf("Expected 800ns of contention in Get, got %v", summary.BenchmarkContention)
+	}
+
+	hotspots := summary.ContentionHotspots
+	if len(hotspots) != 3 {
+		t.Fatalf("Expected 3 hotspots, got %+v", hotspots)
+	}
+	get := hotspots[0]
+	if get.Function != "pkg.(*Cache).Get" || get.Profile != "block" || get.Primitive != "sync.(*Mutex).Lock" ||
+		get.Contentions != 2 || get.DelayNs != 800 || get.Percentage != 80 {
+		t.Errorf("Unexpected top block hotspot: %+v", get)
+	}
+	if hotspots[1].Function != "pkg.consume" || hotspots[1].Primitive != "runtime.chanrecv1" {
+		t.Errorf("Unexpected second block hotspot: %+v", hotspots[1])
+	}
+	put := hotspots[2]
+	if put.Function != "pkg.(*Cache).Put" || put.Profile != "mutex" || put.Percentage != 100 {
+		t.Errorf("Unexpected mutex hotspot: %+v", put)
+	}
+
+	// Both dominant hotspots warrant a suggestion
+	var contention []string
+	for _, s := range summary.Suggestions {
+		if s.RuleID == RuleContention {
+			contention = append(contention, s.Profile+" "+s.Function)
+		}
+	}
+	if strings.Join(contention, ",") != "block pkg.(*Cache).Get,mutex pkg.(*Cache).Put" {
+		t.Errorf("Unexpected contention suggestions: %v", contention)
+	}
+}
+
+func TestLoadContentionProfileInvalid(t *testing.T) {
+	analyzer := NewAnalyzer()
+	if err := analyzer.LoadBlockProfile([]byte("invalid")); err == nil {
+		t.Error("Expected an error for invalid profile data")
+	}
+
+	// A CPU profile has no contention samples
+	cpu := encodeProfile(t, createStackProfile([]string{"pkg.parse"}))
+	err := analyzer.LoadMutexProfile(cpu)
+	if err == nil || !strings.Contains(err.Error(), "expected contentions and delay") {
+		t.Errorf("Expected an error for a CPU profile, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package profiler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/pprof/profile"
+)
+
+// speedscopeSchema identifies the speedscope file format
+const speedscopeSchema = "https://www.speedscope.app/file-format-schema.json"
+
+// ExportProfile is a stored profile to convert for external viewers
+type ExportProfile struct {
+	Name       string // Name shown for the profile (e.g. "cpu")
+	Data       []byte // Raw pprof data
+	SampleType string // Sample type to export (empty = default, alloc_space for heap profiles, delay for contention)
+}
+
+// speedscopeFile is the speedscope JSON file format
+type speedscopeFile struct {
+	Schema             string                  `json:"$schema"`
+	Name               string                  `json:"name"`
+	Exporter           string                  `json:"exporter"`
+	ActiveProfileIndex int                     `json:"activeProfileIndex"`
+	Shared             speedscopeShared        `json:"shared"`
+	Profiles           []speedscopeSampledProf `json:"profiles"`
+}
+
+type speedscopeShared struct {
+	Frames []speedscopeFrame `json:"frames"`
+}
+
+type speedscopeFrame struct {
+	Name string `json:"name"`
+	File string `json:"file,omitempty"`
+}
+
+type speedscopeSampledProf struct {
+	Type       string  `json:"type"`
+	Name       string  `json:"name"`
+	Unit       string  `json:"unit"`
+	StartValue int64   `json:"startValue"`
+	EndValue   int64   `json:"endValue"`
+	Samples    [][]int `json:"samples"`
+	Weights    []int64 `json:"weights"`
+}
+
+// WriteSpeedscope converts profiles into a single speedscope file, one
+// speedscope profile per pprof profile, sharing their frames
+func WriteSpeedscope(w io.Writer, name string, profiles []ExportProfile) error {
+	file := speedscopeFile{
+		Schema:   speedscopeSchema,
+		Name:     name,
+		Exporter: "gokanon",
+		Shared:   speedscopeShared{Frames: []speedscopeFrame{}},
+	}
+	frames := make(map[speedscopeFrame]int)
+
+	for _, p := range profiles {
+		prof, idx, err := parseExportProfile(p)
+		if err != nil {
+			return fmt.Errorf("%s profile: %w", p.Name, err)
+		}
+
+		sp := speedscopeSampledProf{
+			Type:    "sampled",
+			Name:    fmt.Sprintf("%s (%s)", p.Name, prof.SampleType[idx].Type),
+			Unit:    speedscopeUnit(prof.SampleType[idx].Unit),
+			Samples: [][]int{},
+			Weights: []int64{},
+		}
+		for _, sample := range prof.Sample {
+			weight := sample.Value[idx]
+			if weight <= 0 {
+				continue
+			}
+			var stack []int
+			for _, f := range sampleFrames(sample) {
+				i, ok := frames[f]
+				if !ok {
+					i = len(file.Shared.Frames)
+					frames[f] = i
+					file.Shared.Frames = append(file.Shared.Frames, f)
+				}
+				stack = append(stack, i)
+			}
+			sp.Samples = append(sp.Samples, stack)
+			sp.Weights = append(sp.Weights, weight)
+			sp.EndValue += weight
+		}
+		file.Profiles = append(file.Profiles, sp)
+	}
+
+	return json.NewEncoder(w).Encode(file)
+}
+
+// FoldedStacks are the distinct stacks of a profile with their weight in
+// the selected sample type
+type FoldedStacks struct {
+	SampleType string
+	Unit       string
+	Stacks     []FoldedStack // Sorted by their function names
+}
+
+// FoldedStack is a stack of functions, from the root to the leaf, and the
+// total weight of the samples with that stack
+type FoldedStack struct {
+	Functions []string
+	Weight    int64
+}
+
+// Fold merges the samples of a profile with the same stack
+func Fold(p ExportProfile) (*FoldedStacks, error) {
+	prof, idx, err := parseExportProfile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	folded := &FoldedStacks{SampleType: prof.SampleType[idx].Type, Unit: prof.SampleType[idx].Unit}
+	for _, sample := range prof.Sample {
+		weight := sample.Value[idx]
+		if weight <= 0 {
+			continue
+		}
+		var functions []string
+		for _, f := range sampleFrames(sample) {
+			functions = append(functions, f.Name)
+		}
+		key := strings.Join(functions, "\x00")
+		if i, ok := index[key]; ok {
+			folded.Stacks[i].Weight += weight
+			continue
+		}
+		index[key] = len(folded.Stacks)
+		folded.Stacks = append(folded.Stacks, FoldedStack{Functions: functions, Weight: weight})
+	}
+
+	sort.Slice(folded.Stacks, func(i, j int) bool {
+		return slices.Compare(folded.Stacks[i].Functions, folded.Stacks[j].Functions) < 0
+	})
+	return folded, nil
+}
+
+// WriteCollapsed converts a profile into the collapsed stack format read by
+// FlameGraph.pl: one "root;caller;leaf weight" line per distinct stack
+func WriteCollapsed(w io.Writer, p ExportProfile) error {
+	folded, err := Fold(p)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, stack := range folded.Stacks {
+		names := make([]string, len(stack.Functions))
+		for i, name := range stack.Functions {
+			names[i] = strings.ReplaceAll(name, ";", ":")
+		}
+		fmt.Fprintf(bw, "%s %d\n", strings.Join(names, ";"), stack.Weight)
+	}
+	return bw.Flush()
+}
+
+// parseExportProfile parses the profile data and selects the sample type to
+// export. Heap profiles default to alloc_space, as in the analysis, and
+// block and mutex profiles to the delay rather than the contentions.
+func parseExportProfile(p ExportProfile) (*profile.Profile, int, error) {
+	prof, err := profile.Parse(bytes.NewReader(p.Data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	sampleType := p.SampleType
+	if sampleType == "" {
+		for _, preferred := range []string{"alloc_space", "delay"} {
+			if hasSampleType(prof, preferred) {
+				sampleType = preferred
+				break
+			}
+		}
+	}
+	idx, err := sampleTypeIndex(prof, sampleType)
+	if err != nil {
+		return nil, 0, err
+	}
+	return prof, idx, nil
+}
+
+// hasSampleType reports whether a profile records the named sample type
+func hasSampleType(prof *profile.Profile, name string) bool {
+	for _, st := range prof.SampleType {
+		if st.Type == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleFrames returns the functions of a sample from the root to the
+// leaf, inlined functions included
+func sampleFrames(sample *profile.Sample) []speedscopeFrame {
+	var frames []speedscopeFrame
+	for i := len(sample.Location) - 1; i >= 0; i-- {
+		lines := sample.Location[i].Line
+		for j := len(lines) - 1; j >= 0; j-- {
+			fn := lines[j].Function
+			if fn == nil {
+				continue
+			}
+			frames = append(frames, speedscopeFrame{Name: fn.Name, File: fn.Filename})
+		}
+	}
+	return frames
+}
+
+// speedscopeUnit maps a pprof sample unit to a speedscope value unit
+func speedscopeUnit(unit string) string {
+	switch unit {
+	case "nanoseconds", "microseconds", "milliseconds", "seconds", "bytes":
+		return unit
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,98 @@
+package profiler
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+// encodeProfile serializes a profile as stored by the runner
+func encodeProfile(t *testing.T, prof *profile.Profile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := prof.Write(&buf); err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteSpeedscope(t *testing.T) {
+	cpu := encodeProfile(t, createStackProfile(
+		[]string{"pkg.parse", "pkg.BenchmarkParse"},
+		[]string{"pkg.parse", "pkg.BenchmarkParse"},
+		[]string{"pkg.helper", "pkg.BenchmarkParse"},
+	))
+	mem := encodeProfile(t, createStackProfile([]string{"pkg.buffer", "pkg.BenchmarkParse"}))
+
+	var buf bytes.Buffer
+	err := WriteSpeedscope(&buf, "run-1", []ExportProfile{{Name: "cpu", Data: cpu}, {Name: "mem", Data: mem}})
+	if err != nil {
+		t.Fatalf("WriteSpeedscope failed: %v", err)
+	}
+
+	var file speedscopeFile
+	if err := json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if file.Schema != speedscopeSchema || file.Name != "run-1" {
+		t.Errorf("Unexpected header: %+v", file)
+	}
+	// Frames are shared between profiles
+	if len(file.Shared.Frames) != 4 {
+		t.Errorf("Expected 4 shared frames, got %+v", file.Shared.Frames)
+	}
+	if len(file.Profiles) != 2 {
+		t.Fatalf("Expected 2 profiles, got %d", len(file.Profiles))
+	}
+
+	cpuProf := file.Profiles[0]
+	if cpuProf.Type != "sampled" || cpuProf.Name != "cpu (samples)" || cpuProf.Unit != "none" {
+		t.Errorf("Unexpected CPU profile: %+v", cpuProf)
+	}
+	if len(cpuProf.Samples) != 3 || cpuProf.EndValue != 3 {
+		t.Errorf("Expected 3 samples weighing 3, got %+v", cpuProf)
+	}
+	// Stacks are listed from the root
+	root := file.Shared.Frames[cpuProf.Samples[0][0]]
+	leaf := file.Shared.Frames[cpuProf.Samples[0][1]]
+	if root.Name != "pkg.BenchmarkParse" || leaf.Name != "pkg.parse" {
+		t.Errorf("Expected BenchmarkParse -> parse, got %s -> %s", root.Name, leaf.Name)
+	}
+}
+
+func TestWriteSpeedscopeInvalidProfile(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSpeedscope(&buf, "run-1", []ExportProfile{{Name: "cpu", Data: []byte("invalid")}})
+	if err == nil {
+		t.Error("Expected an error for invalid profile data")
+	}
+}
+
+func TestWriteCollapsed(t *testing.T) {
+	data := encodeProfile(t, createStackProfile(
+		[]string{"pkg.parse", "pkg.BenchmarkParse"},
+		[]string{"pkg.parse", "pkg.BenchmarkParse"},
+		[]string{"pkg.helper", "pkg.BenchmarkParse"},
+	))
+
+	var buf bytes.Buffer
+	if err := WriteCollapsed(&buf, ExportProfile{Name: "cpu", Data: data}); err != nil {
+		t.Fatalf("WriteCollapsed failed: %v", err)
+	}
+	want := "pkg.BenchmarkParse;pkg.helper 1\npkg.BenchmarkParse;pkg.parse 2\n"
+	if buf.String() != want {
+		t.Errorf("Collapsed stacks = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteCollapsedSampleType(t *testing.T) {
+	data := encodeProfile(t, createStackProfile([]string{"pkg.parse"}))
+
+	var buf bytes.Buffer
+	err := WriteCollapsed(&buf, ExportProfile{Name: "cpu", Data: data, SampleType: "alloc_space"})
+	if err == nil {
+		t.Error("Expected an error for a missing sample type")
+	}
+}
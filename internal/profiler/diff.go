@@ -0,0 +1,147 @@
+package profiler
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// HotThreshold is the flat share (%) of a profile from which a function
+// absent from the other profile is reported as a new hot function
+const HotThreshold = 1.0
+
+// FunctionDelta is the change of a function's share of a profile between
+// two runs. Shares rather than values are compared, since profiles of runs
+// of different lengths have different totals.
+type FunctionDelta struct {
+	Name     string  `json:"name"`
+	OldFlat  float64 `json:"old_flat_percent"` // Share of the old profile spent in the function itself
+	NewFlat  float64 `json:"new_flat_percent"`
+	OldCum   float64 `json:"old_cum_percent"` // Share of the old profile spent in the function and its callees
+	NewCum   float64 `json:"new_cum_percent"`
+	OldValue int64   `json:"old_flat_value"` // Flat value in the unit of the profile
+	NewValue int64   `json:"new_flat_value"`
+}
+
+// FlatDelta returns the change of the function's flat share, in points
+func (d FunctionDelta) FlatDelta() float64 {
+	return d.NewFlat - d.OldFlat
+}
+
+// ProfileDiff compares two profiles of the same kind
+type ProfileDiff struct {
+	SampleType string          `json:"sample_type"`
+	Unit       string          `json:"unit"`
+	OldTotal   int64           `json:"old_total"`
+	NewTotal   int64           `json:"new_total"`
+	Functions  []FunctionDelta `json:"functions"` // Largest changes of the flat share first
+	NewHot     []FunctionDelta `json:"new_hot"`   // Functions absent from the old profile with at least HotThreshold of the new one
+}
+
+// DiffProfiles compares the functions of two raw profiles by their share
+// of the named sample type; an empty name selects the profile's default.
+// At most limit functions are returned by change, all when limit is 0.
+func DiffProfiles(oldData, newData []byte, sampleType string, limit int) (*ProfileDiff, error) {
+	oldProf, oldIdx, err := parseDiffProfile("old", oldData, sampleType)
+	if err != nil {
+		return nil, err
+	}
+	newProf, newIdx, err := parseDiffProfile("new", newData, sampleType)
+	if err != nil {
+		return nil, err
+	}
+	if oldType, newType := oldProf.SampleType[oldIdx].Type, newProf.SampleType[newIdx].Type; oldType != newType {
+		return nil, fmt.Errorf("profiles have different sample types: %s and %s", oldType, newType)
+	}
+
+	oldStats, oldTotal := aggregateFunctions(oldProf, oldIdx, true)
+	newStats, newTotal := aggregateFunctions(newProf, newIdx, true)
+	diff := &ProfileDiff{
+		SampleType: newProf.SampleType[newIdx].Type,
+		Unit:       newProf.SampleType[newIdx].Unit,
+		OldTotal:   oldTotal,
+		NewTotal:   newTotal,
+		Functions:  []FunctionDelta{},
+		NewHot:     []FunctionDelta{},
+	}
+
+	names := make(map[string]bool)
+	for name := range oldStats {
+		names[name] = true
+	}
+	for name := range newStats {
+		names[name] = true
+	}
+	for name := range names {
+		delta := FunctionDelta{Name: cleanFunctionName(name)}
+		if stat, ok := oldStats[name]; ok {
+			delta.OldFlat, delta.OldCum, delta.OldValue = share(stat.flat, oldTotal), share(stat.cum, oldTotal), stat.flat
+		}
+		if stat, ok := newStats[name]; ok {
+			delta.NewFlat, delta.NewCum, delta.NewValue = share(stat.flat, newTotal), share(stat.cum, newTotal), stat.flat
+		}
+		if _, inOld := oldStats[name]; !inOld && delta.NewFlat >= HotThreshold {
+			diff.NewHot = append(diff.NewHot, delta)
+		}
+		if delta.FlatDelta() != 0 {
+			diff.Functions = append(diff.Functions, delta)
+		}
+	}
+
+	sort.Slice(diff.Functions, func(i, j int) bool {
+		a, b := math.Abs(diff.Functions[i].FlatDelta()), math.Abs(diff.Functions[j].FlatDelta())
+		if a != b {
+			return a > b
+		}
+		return diff.Functions[i].Name < diff.Functions[j].Name
+	})
+	if limit > 0 && len(diff.Functions) > limit {
+		diff.Functions = diff.Functions[:limit]
+	}
+	sort.Slice(diff.NewHot, func(i, j int) bool {
+		if diff.NewHot[i].NewFlat != diff.NewHot[j].NewFlat {
+			return diff.NewHot[i].NewFlat > diff.NewHot[j].NewFlat
+		}
+		return diff.NewHot[i].Name < diff.NewHot[j].Name
+	})
+	return diff, nil
+}
+
+// parseDiffProfile parses one side of a profile comparison
+func parseDiffProfile(side string, data []byte, sampleType string) (*profile.Profile, int, error) {
+	prof, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse %s profile: %w", side, err)
+	}
+	idx, err := sampleTypeIndex(prof, sampleType)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid %s profile: %w", side, err)
+	}
+	return prof, idx, nil
+}
+
+// share returns value as a percentage of total
+func share(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total) * 100
+}
+
+// FormatValue formats a profile value in its unit, such as a duration for
+// nanoseconds or a size for bytes
+func FormatValue(value int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return time.Duration(value).String()
+	case "bytes":
+		return formatBytes(value)
+	case "count", "":
+		return fmt.Sprintf("%d", value)
+	}
+	return fmt.Sprintf("%d %s", value, unit)
+}
@@ -0,0 +1,104 @@
+package profiler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+// createTestCPUProfileOf creates a CPU profile of leaf functions with the
+// given cpu nanoseconds, each called by main.run
+func createTestCPUProfileOf(cpu map[string]int64) []byte {
+	run := &profile.Function{ID: 1, Name: "main.run"}
+	runLoc := &profile.Location{ID: 1, Address: 0x1000, Line: []profile.Line{{Function: run}}}
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		Function: []*profile.Function{run},
+		Location: []*profile.Location{runLoc},
+	}
+	id := uint64(1)
+	for name, ns := range cpu {
+		id++
+		fn := &profile.Function{ID: id, Name: name}
+		loc := &profile.Location{ID: id, Address: 0x1000 * id, Line: []profile.Line{{Function: fn}}}
+		prof.Function = append(prof.Function, fn)
+		prof.Location = append(prof.Location, loc)
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: []*profile.Location{loc, runLoc},
+			Value:    []int64{ns / 10000000, ns},
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := prof.Write(&buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDiffProfiles(t *testing.T) {
+	oldData := createTestCPUProfileOf(map[string]int64{"main.parse": 600e6, "main.encode": 400e6})
+	newData := createTestCPUProfileOf(map[string]int64{"main.parse": 900e6, "main.encode": 600e6, "example.com/json.compact": 500e6})
+
+	diff, err := DiffProfiles(oldData, newData, "", 0)
+	if err != nil {
+		t.Fatalf("DiffProfiles failed: %v", err)
+	}
+	if diff.SampleType != "cpu" || diff.OldTotal != 1e9 || diff.NewTotal != 2e9 {
+		t.Errorf("Unexpected totals: %+v", diff)
+	}
+
+	// Shares are compared: parse went from 60% to 45% of a longer profile
+	want := []struct {
+		name  string
+		delta float64
+	}{{"json.compact", 25}, {"main.parse", -15}, {"main.encode", -10}}
+	if len(diff.Functions) != len(want) {
+		t.Fatalf("Functions = %+v, want %d", diff.Functions, len(want))
+	}
+	for i, w := range want {
+		if f := diff.Functions[i]; f.Name != w.name || f.FlatDelta() < w.delta-0.01 || f.FlatDelta() > w.delta+0.01 {
+			t.Errorf("Functions[%d] = %s %+.2f, want %s %+.2f", i, f.Name, f.FlatDelta(), w.name, w.delta)
+		}
+	}
+	if parse := diff.Functions[1]; parse.OldCum != 60 || parse.OldValue != 600e6 {
+		t.Errorf("Unexpected parse delta: %+v", parse)
+	}
+	if len(diff.NewHot) != 1 || diff.NewHot[0].Name != "json.compact" || diff.NewHot[0].NewFlat != 25 {
+		t.Errorf("NewHot = %+v, want json.compact", diff.NewHot)
+	}
+
+	// The limit keeps the largest changes
+	if diff, _ := DiffProfiles(oldData, newData, "cpu", 1); len(diff.Functions) != 1 || diff.Functions[0].Name != "json.compact" {
+		t.Errorf("Limited functions = %+v", diff.Functions)
+	}
+
+	if _, err := DiffProfiles(oldData, newData, "alloc_space", 0); err == nil || !strings.Contains(err.Error(), "alloc_space") {
+		t.Errorf("Expected an error for a missing sample type, got %v", err)
+	}
+	if _, err := DiffProfiles(oldData, []byte("garbage"), "", 0); err == nil || !strings.Contains(err.Error(), "new profile") {
+		t.Errorf("Expected an error for an invalid profile, got %v", err)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	for _, tt := range []struct {
+		value int64
+		unit  string
+		want  string
+	}{
+		{1500000000, "nanoseconds", "1.5s"},
+		{2048, "bytes", "2.0 KB"},
+		{42, "count", "42"},
+		{7, "widgets", "7 widgets"},
+	} {
+		if got := FormatValue(tt.value, tt.unit); got != tt.want {
+			t.Errorf("FormatValue(%d, %q) = %q, want %q", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
@@ -13,8 +13,21 @@ import (
 
 // Analyzer analyzes pprof profiles
 type Analyzer struct {
-	cpuProfile    *profile.Profile
-	memoryProfile *profile.Profile
+	cpuProfile       *profile.Profile
+	memoryProfile    *profile.Profile
+	warmupProfile    *profile.Profile // Heap profile taken after warmup
+	blockProfile     *profile.Profile // Where goroutines blocked on synchronization
+	mutexProfile     *profile.Profile // Holders of contended mutexes
+	cpuSampleType    string           // Sample type used for CPU analysis (empty = first)
+	memorySampleType string           // Sample type used for memory analysis (empty = alloc_space)
+	customProfiles   []*customProfile
+}
+
+// customProfile is a loaded profile analyzed with a user-selected sample type
+type customProfile struct {
+	name        string
+	prof        *profile.Profile
+	sampleIndex int
 }
 
 // NewAnalyzer creates a new profile analyzer
@@ -42,6 +55,73 @@ func (a *Analyzer) LoadMemoryProfile(data []byte) error {
 	return nil
 }
 
+// LoadWarmupProfile loads the baseline heap profile, taken by a separate
+// go test after a single iteration of every benchmark. Compared with the
+// memory profile taken at the end of the run, it shows which functions
+// keep more memory in use as iterations go on.
+func (a *Analyzer) LoadWarmupProfile(data []byte) error {
+	prof, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse warmup heap profile: %w", err)
+	}
+	a.warmupProfile = prof
+	return nil
+}
+
+// LoadBlockProfile loads a block profile from data, recording where
+// goroutines blocked on synchronization primitives
+func (a *Analyzer) LoadBlockProfile(data []byte) error {
+	prof, err := loadContentionProfile("block", data)
+	if err != nil {
+		return err
+	}
+	a.blockProfile = prof
+	return nil
+}
+
+// LoadMutexProfile loads a mutex profile from data, recording the holders
+// of contended mutexes
+func (a *Analyzer) LoadMutexProfile(data []byte) error {
+	prof, err := loadContentionProfile("mutex", data)
+	if err != nil {
+		return err
+	}
+	a.mutexProfile = prof
+	return nil
+}
+
+// SetCPUSampleType selects the sample type used when analyzing the CPU profile
+func (a *Analyzer) SetCPUSampleType(sampleType string) {
+	a.cpuSampleType = sampleType
+}
+
+// SetMemorySampleType selects the sample type used when analyzing the memory profile
+func (a *Analyzer) SetMemorySampleType(sampleType string) {
+	a.memorySampleType = sampleType
+}
+
+// LoadCustomProfile loads an arbitrary profile (e.g. fgprof, wall-clock) to be
+// analyzed using the named sample type. An empty sampleType selects the
+// profile's default.
+func (a *Analyzer) LoadCustomProfile(name string, data []byte, sampleType string) error {
+	prof, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s profile: %w", name, err)
+	}
+
+	idx, err := sampleTypeIndex(prof, sampleType)
+	if err != nil {
+		return fmt.Errorf("invalid %s profile: %w", name, err)
+	}
+
+	a.customProfiles = append(a.customProfiles, &customProfile{
+		name:        name,
+		prof:        prof,
+		sampleIndex: idx,
+	})
+	return nil
+}
+
 // Analyze generates a complete profile summary
 func (a *Analyzer) Analyze() (*models.ProfileSummary, error) {
 	summary := &models.ProfileSummary{}
@@ -69,11 +149,42 @@ func (a *Analyzer) Analyze() (*models.ProfileSummary, error) {
 		summary.MemoryTopFunctions = memFuncs
 		summary.TotalMemoryBytes = totalBytes
 
-		// Detect potential memory leaks
-		leaks := a.detectMemoryLeaks()
-		summary.MemoryLeaks = leaks
+		// Detect potential memory leaks: from the growth of in-use memory
+		// since warmup when measured, otherwise from allocation patterns
+		if growth, ok := a.memoryGrowth(); ok {
+			summary.MemoryGrowth = growth
+			summary.MemoryLeaks = leaksFromGrowth(growth)
+		} else {
+			summary.MemoryLeaks = a.detectMemoryLeaks()
+		}
 	}
 
+	// Analyze where goroutines waited on each other
+	if a.blockProfile != nil {
+		hotspots, total := contentionHotspots("block", a.blockProfile)
+		summary.ContentionHotspots = append(summary.ContentionHotspots, hotspots...)
+		summary.TotalBlockDelay = total
+	}
+	if a.mutexProfile != nil {
+		hotspots, total := contentionHotspots("mutex", a.mutexProfile)
+		summary.ContentionHotspots = append(summary.ContentionHotspots, hotspots...)
+		summary.TotalMutexDelay = total
+	}
+	for _, prof := range []*profile.Profile{a.blockProfile, a.mutexProfile} {
+		if prof == nil {
+			continue
+		}
+		for benchmark, delay := range contentionByBenchmark(prof) {
+			if summary.BenchmarkContention == nil {
+				summary.BenchmarkContention = make(map[string]int64)
+			}
+			summary.BenchmarkContention[benchmark] += delay
+		}
+	}
+
+	// Analyze attached custom profiles
+	summary.CustomProfiles = a.analyzeCustomProfiles()
+
 	// Generate optimization suggestions
 	suggestions := a.generateSuggestions(summary)
 	summary.Suggestions = suggestions
@@ -87,70 +198,61 @@ func (a *Analyzer) analyzeCPUProfile() ([]models.FunctionProfile, int64, error)
 		return nil, 0, nil
 	}
 
-	// Get total samples
-	var totalSamples int64
-	for _, sample := range a.cpuProfile.Sample {
-		totalSamples += sample.Value[0]
+	idx, err := a.cpuSampleIndex()
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if totalSamples == 0 {
+	result, totalSamples := topFunctions(a.cpuProfile, idx, true)
+	return result, totalSamples, nil
+}
+
+// analyzeMemoryProfile extracts top memory-allocating functions
+func (a *Analyzer) analyzeMemoryProfile() ([]models.FunctionProfile, int64, error) {
+	if a.memoryProfile == nil {
 		return nil, 0, nil
 	}
 
-	// Aggregate by function
-	funcStats := make(map[string]*funcStat)
-	for _, sample := range a.cpuProfile.Sample {
-		value := sample.Value[0]
-		if len(sample.Location) == 0 {
-			continue
-		}
+	sampleType := a.memorySampleType
+	if sampleType == "" {
+		sampleType = "alloc_space"
+	}
 
-		// Get the leaf function (top of stack)
-		loc := sample.Location[0]
-		if len(loc.Line) == 0 {
-			continue
-		}
+	idx, err := sampleTypeIndex(a.memoryProfile, sampleType)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s not found in memory profile", sampleType)
+	}
 
-		fn := loc.Line[0].Function
-		if fn == nil {
-			continue
-		}
+	result, totalBytes := topFunctions(a.memoryProfile, idx, false)
+	return result, totalBytes, nil
+}
 
-		funcName := fn.Name
-		if stat, exists := funcStats[funcName]; exists {
-			stat.flat += value
-			stat.cum += value
-		} else {
-			funcStats[funcName] = &funcStat{
-				name: funcName,
-				flat: value,
-				cum:  value,
-			}
-		}
+// analyzeCustomProfiles extracts top functions for each attached profile
+func (a *Analyzer) analyzeCustomProfiles() []models.CustomProfile {
+	var result []models.CustomProfile
+	for _, cp := range a.customProfiles {
+		st := cp.prof.SampleType[cp.sampleIndex]
+		top, total := topFunctions(cp.prof, cp.sampleIndex, true)
+		result = append(result, models.CustomProfile{
+			Name:         cp.name,
+			SampleType:   st.Type,
+			Unit:         st.Unit,
+			Total:        total,
+			TopFunctions: top,
+		})
+	}
+	return result
+}
 
-		// Add to callers as well (cumulative)
-		for i := 1; i < len(sample.Location); i++ {
-			if len(sample.Location[i].Line) == 0 {
-				continue
-			}
-			callerFn := sample.Location[i].Line[0].Function
-			if callerFn == nil {
-				continue
-			}
-			callerName := callerFn.Name
-			if stat, exists := funcStats[callerName]; exists {
-				stat.cum += value
-			} else {
-				funcStats[callerName] = &funcStat{
-					name: callerName,
-					flat: 0,
-					cum:  value,
-				}
-			}
-		}
+// topFunctions aggregates sample values by function and returns the top 10 by flat value.
+// When cumulative is set, callers up the stack are credited with the sample's value too.
+func topFunctions(prof *profile.Profile, idx int, cumulative bool) ([]models.FunctionProfile, int64) {
+	funcStats, total := aggregateFunctions(prof, idx, cumulative)
+	if total == 0 {
+		return nil, 0
 	}
 
-	// Convert to slice and sort by flat time
+	// Convert to slice and sort by flat value
 	var stats []*funcStat
 	for _, stat := range funcStats {
 		stats = append(stats, stat)
@@ -170,54 +272,35 @@ func (a *Analyzer) analyzeCPUProfile() ([]models.FunctionProfile, int64, error)
 		stat := stats[i]
 		result = append(result, models.FunctionProfile{
 			Name:        cleanFunctionName(stat.name),
-			FlatPercent: float64(stat.flat) / float64(totalSamples) * 100,
-			CumPercent:  float64(stat.cum) / float64(totalSamples) * 100,
+			FlatPercent: float64(stat.flat) / float64(total) * 100,
+			CumPercent:  float64(stat.cum) / float64(total) * 100,
 			FlatValue:   stat.flat,
 			CumValue:    stat.cum,
 		})
 	}
 
-	return result, totalSamples, nil
+	return result, total
 }
 
-// analyzeMemoryProfile extracts top memory-allocating functions
-func (a *Analyzer) analyzeMemoryProfile() ([]models.FunctionProfile, int64, error) {
-	if a.memoryProfile == nil {
-		return nil, 0, nil
-	}
-
-	// Find the alloc_space index (bytes allocated)
-	allocSpaceIdx := -1
-	for i, st := range a.memoryProfile.SampleType {
-		if st.Type == "alloc_space" {
-			allocSpaceIdx = i
-			break
-		}
-	}
-
-	if allocSpaceIdx == -1 {
-		return nil, 0, fmt.Errorf("alloc_space not found in memory profile")
-	}
-
-	// Get total bytes
-	var totalBytes int64
-	for _, sample := range a.memoryProfile.Sample {
-		totalBytes += sample.Value[allocSpaceIdx]
-	}
-
-	if totalBytes == 0 {
-		return nil, 0, nil
+// aggregateFunctions sums sample values by function and returns them with
+// the profile's total. When cumulative is set, callers up the stack are
+// credited with the sample's value too.
+func aggregateFunctions(prof *profile.Profile, idx int, cumulative bool) (map[string]*funcStat, int64) {
+	// Get total value
+	var total int64
+	for _, sample := range prof.Sample {
+		total += sample.Value[idx]
 	}
 
 	// Aggregate by function
 	funcStats := make(map[string]*funcStat)
-	for _, sample := range a.memoryProfile.Sample {
-		value := sample.Value[allocSpaceIdx]
+	for _, sample := range prof.Sample {
+		value := sample.Value[idx]
 		if len(sample.Location) == 0 {
 			continue
 		}
 
-		// Get the leaf function
+		// Get the leaf function (top of stack)
 		loc := sample.Location[0]
 		if len(loc.Line) == 0 {
 			continue
@@ -239,36 +322,84 @@ func (a *Analyzer) analyzeMemoryProfile() ([]models.FunctionProfile, int64, erro
 				cum:  value,
 			}
 		}
+
+		if !cumulative {
+			continue
+		}
+
+		// Add to callers as well (cumulative)
+		for i := 1; i < len(sample.Location); i++ {
+			if len(sample.Location[i].Line) == 0 {
+				continue
+			}
+			callerFn := sample.Location[i].Line[0].Function
+			if callerFn == nil {
+				continue
+			}
+			callerName := callerFn.Name
+			if stat, exists := funcStats[callerName]; exists {
+				stat.cum += value
+			} else {
+				funcStats[callerName] = &funcStat{
+					name: callerName,
+					flat: 0,
+					cum:  value,
+				}
+			}
+		}
 	}
+	return funcStats, total
+}
 
-	// Convert to slice and sort
-	var stats []*funcStat
-	for _, stat := range funcStats {
-		stats = append(stats, stat)
+// cpuSampleIndex returns the index of the sample type used for CPU analysis
+func (a *Analyzer) cpuSampleIndex() (int, error) {
+	if a.cpuSampleType == "" {
+		return 0, nil
 	}
-	sort.Slice(stats, func(i, j int) bool {
-		return stats[i].flat > stats[j].flat
-	})
+	return sampleTypeIndex(a.cpuProfile, a.cpuSampleType)
+}
 
-	// Take top 10
-	topCount := 10
-	if len(stats) < topCount {
-		topCount = len(stats)
+// sampleTypeIndex finds the index of the named sample type in a profile.
+// An empty name selects the profile's default sample type, or the last one
+// if no default is recorded (matching pprof's own behavior).
+func sampleTypeIndex(prof *profile.Profile, name string) (int, error) {
+	if len(prof.SampleType) == 0 {
+		return 0, fmt.Errorf("profile has no sample types")
 	}
 
-	var result []models.FunctionProfile
-	for i := 0; i < topCount; i++ {
-		stat := stats[i]
-		result = append(result, models.FunctionProfile{
-			Name:        cleanFunctionName(stat.name),
-			FlatPercent: float64(stat.flat) / float64(totalBytes) * 100,
-			CumPercent:  float64(stat.cum) / float64(totalBytes) * 100,
-			FlatValue:   stat.flat,
-			CumValue:    stat.cum,
-		})
+	if name == "" {
+		name = prof.DefaultSampleType
+	}
+	if name == "" {
+		return len(prof.SampleType) - 1, nil
 	}
 
-	return result, totalBytes, nil
+	for i, st := range prof.SampleType {
+		if st.Type == name {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("sample type %q not found in profile (available: %s)",
+		name, strings.Join(sampleTypeNames(prof), ", "))
+}
+
+// sampleTypeNames returns the names of all sample types in a profile
+func sampleTypeNames(prof *profile.Profile) []string {
+	names := make([]string, 0, len(prof.SampleType))
+	for _, st := range prof.SampleType {
+		names = append(names, st.Type)
+	}
+	return names
+}
+
+// SampleTypes returns the sample type names available in raw profile data
+func SampleTypes(data []byte) ([]string, error) {
+	prof, err := profile.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse profile: %w", err)
+	}
+	return sampleTypeNames(prof), nil
 }
 
 // identifyHotPaths identifies critical execution paths from CPU profile
@@ -277,12 +408,17 @@ func (a *Analyzer) identifyHotPaths() []models.HotPath {
 		return nil
 	}
 
+	idx, err := a.cpuSampleIndex()
+	if err != nil {
+		return nil
+	}
+
 	// Track call stacks and their frequencies
 	pathCounts := make(map[string]*pathStat)
 
 	var totalSamples int64
 	for _, sample := range a.cpuProfile.Sample {
-		value := sample.Value[0]
+		value := sample.Value[idx]
 		totalSamples += value
 
 		// Build call stack path
@@ -439,72 +575,90 @@ func (a *Analyzer) detectMemoryLeaks() []models.MemoryLeak {
 	return result
 }
 
-// generateSuggestions generates optimization suggestions based on profile data
-func (a *Analyzer) generateSuggestions(summary *models.ProfileSummary) []models.Suggestion {
-	var suggestions []models.Suggestion
-
-	// CPU suggestions
-	if len(summary.CPUTopFunctions) > 0 {
-		top := summary.CPUTopFunctions[0]
-		if top.FlatPercent > 30 {
-			suggestions = append(suggestions, models.Suggestion{
-				Type:       "cpu",
-				Severity:   "high",
-				Function:   top.Name,
-				Issue:      fmt.Sprintf("Function consumes %.1f%% of CPU time", top.FlatPercent),
-				Suggestion: "Consider optimizing this hot function - profile it in isolation, look for unnecessary allocations, consider algorithmic improvements",
-				Impact:     fmt.Sprintf("Could improve overall performance by up to %.0f%%", top.FlatPercent*0.7),
-			})
-		}
+// minMemoryGrowth is the in-use growth below which a function is not
+// reported; heap profiles are sampled, so smaller changes are noise
+const minMemoryGrowth = 256 * 1024
+
+// memoryGrowth compares the in-use memory of each function between the
+// warmup and final heap profiles, largest growth first. It returns false
+// when there is no warmup profile or a profile lacks in-use samples.
+func (a *Analyzer) memoryGrowth() ([]models.MemoryGrowth, bool) {
+	if a.warmupProfile == nil {
+		return nil, false
+	}
+	warmupIdx, err := sampleTypeIndex(a.warmupProfile, "inuse_space")
+	if err != nil {
+		return nil, false
+	}
+	finalIdx, err := sampleTypeIndex(a.memoryProfile, "inuse_space")
+	if err != nil {
+		return nil, false
 	}
 
-	// Memory suggestions
-	if len(summary.MemoryTopFunctions) > 0 {
-		top := summary.MemoryTopFunctions[0]
-		if top.FlatPercent > 40 {
-			suggestions = append(suggestions, models.Suggestion{
-				Type:       "memory",
-				Severity:   "high",
-				Function:   top.Name,
-				Issue:      fmt.Sprintf("Function allocates %.1f%% of total memory", top.FlatPercent),
-				Suggestion: "Consider using sync.Pool for reusable objects, or pre-allocate slices/maps with appropriate capacity",
-				Impact:     "Could significantly reduce allocation pressure and GC overhead",
-			})
+	warmup := inuseByFunction(a.warmupProfile, warmupIdx)
+	result := []models.MemoryGrowth{}
+	for name, final := range inuseByFunction(a.memoryProfile, finalIdx) {
+		if final-warmup[name] < minMemoryGrowth {
+			continue
 		}
+		result = append(result, models.MemoryGrowth{
+			Function:    cleanFunctionName(name),
+			WarmupBytes: warmup[name],
+			FinalBytes:  final,
+			GrowthBytes: final - warmup[name],
+		})
 	}
 
-	// Memory leak suggestions
-	for _, leak := range summary.MemoryLeaks {
-		if leak.Severity == "high" {
-			suggestions = append(suggestions, models.Suggestion{
-				Type:       "memory",
-				Severity:   "high",
-				Function:   leak.Function,
-				Issue:      "Potential memory leak detected",
-				Suggestion: "Review this function for retained references, unclosed resources, or unbounded caches",
-				Impact:     "Could prevent memory growth and improve stability",
-			})
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].GrowthBytes != result[j].GrowthBytes {
+			return result[i].GrowthBytes > result[j].GrowthBytes
 		}
+		return result[i].Function < result[j].Function
+	})
+	if len(result) > 10 {
+		result = result[:10]
 	}
+	return result, true
+}
 
-	// Hot path suggestions
-	if len(summary.HotPaths) > 0 {
-		for _, path := range summary.HotPaths {
-			if path.Percentage > 25 {
-				suggestions = append(suggestions, models.Suggestion{
-					Type:       "cpu",
-					Severity:   "medium",
-					Function:   strings.Join(path.Path, " -> "),
-					Issue:      fmt.Sprintf("Hot path consuming %.1f%% of execution", path.Percentage),
-					Suggestion: "Analyze this call chain for optimization opportunities - consider caching, lazy evaluation, or algorithmic improvements",
-					Impact:     fmt.Sprintf("Optimizing this path could improve performance by %.0f-%.0f%%", path.Percentage*0.5, path.Percentage*0.8),
-				})
-				break // Only suggest one hot path
-			}
+// inuseByFunction sums the sample values of a heap profile by the function
+// that allocated them
+func inuseByFunction(prof *profile.Profile, idx int) map[string]int64 {
+	values := make(map[string]int64)
+	for _, sample := range prof.Sample {
+		if len(sample.Location) == 0 || len(sample.Location[0].Line) == 0 {
+			continue
+		}
+		if fn := sample.Location[0].Line[0].Function; fn != nil {
+			values[fn.Name] += sample.Value[idx]
 		}
 	}
+	return values
+}
 
-	return suggestions
+// leaksFromGrowth reports the functions whose in-use memory grew since
+// warmup as potential leaks
+func leaksFromGrowth(growth []models.MemoryGrowth) []models.MemoryLeak {
+	var result []models.MemoryLeak
+	for _, g := range growth {
+		severity := "low"
+		if g.GrowthBytes > 10*1024*1024 {
+			severity = "high"
+		} else if g.GrowthBytes > 1024*1024 {
+			severity = "medium"
+		}
+		result = append(result, models.MemoryLeak{
+			Function: g.Function,
+			Bytes:    g.GrowthBytes,
+			Severity: severity,
+			Description: fmt.Sprintf("In-use memory grew by %s after warmup (%s → %s) - retained across iterations",
+				formatBytes(g.GrowthBytes), formatBytes(g.WarmupBytes), formatBytes(g.FinalBytes)),
+		})
+		if len(result) == 5 {
+			break
+		}
+	}
+	return result
 }
 
 // Helper types
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/alenon/gokanon/internal/models"
 	"github.com/google/pprof/profile"
 )
 
@@ -561,3 +562,205 @@ func TestMemoryLeakDetection(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadCustomProfile(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	// Select the second sample type of the CPU-shaped profile by name
+	if err := analyzer.LoadCustomProfile("wall", createTestCPUProfile(), "cpu"); err != nil {
+		t.Fatalf("LoadCustomProfile() failed: %v", err)
+	}
+
+	summary, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+
+	if len(summary.CustomProfiles) != 1 {
+		t.Fatalf("Expected 1 custom profile, got %d", len(summary.CustomProfiles))
+	}
+
+	cp := summary.CustomProfiles[0]
+	if cp.Name != "wall" {
+		t.Errorf("Expected name wall, got %s", cp.Name)
+	}
+	if cp.SampleType != "cpu" || cp.Unit != "nanoseconds" {
+		t.Errorf("Expected cpu/nanoseconds sample type, got %s/%s", cp.SampleType, cp.Unit)
+	}
+	if cp.Total != 1500000 {
+		t.Errorf("Expected total 1500000, got %d", cp.Total)
+	}
+	if len(cp.TopFunctions) != 2 || cp.TopFunctions[0].Name != "main.foo" {
+		t.Errorf("Unexpected top functions: %+v", cp.TopFunctions)
+	}
+}
+
+func TestLoadCustomProfileDefaultSampleType(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	// Without an explicit sample type the last one is used
+	if err := analyzer.LoadCustomProfile("heap", createTestMemoryProfile(), ""); err != nil {
+		t.Fatalf("LoadCustomProfile() failed: %v", err)
+	}
+
+	summary, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+
+	if got := summary.CustomProfiles[0].SampleType; got != "inuse_space" {
+		t.Errorf("Expected default sample type inuse_space, got %s", got)
+	}
+}
+
+func TestLoadCustomProfileUnknownSampleType(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	err := analyzer.LoadCustomProfile("wall", createTestCPUProfile(), "wall_time")
+	if err == nil {
+		t.Fatal("Expected error for unknown sample type")
+	}
+	if !strings.Contains(err.Error(), "samples, cpu") {
+		t.Errorf("Expected error to list available sample types, got: %v", err)
+	}
+}
+
+func TestSetSampleTypes(t *testing.T) {
+	analyzer := NewAnalyzer()
+	analyzer.SetCPUSampleType("cpu")
+	analyzer.SetMemorySampleType("inuse_space")
+
+	if err := analyzer.LoadCPUProfile(createTestCPUProfile()); err != nil {
+		t.Fatalf("LoadCPUProfile() failed: %v", err)
+	}
+	if err := analyzer.LoadMemoryProfile(createTestMemoryProfile()); err != nil {
+		t.Fatalf("LoadMemoryProfile() failed: %v", err)
+	}
+
+	summary, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+
+	if summary.TotalCPUSamples != 1500000 {
+		t.Errorf("Expected CPU total from cpu sample type (1500000), got %d", summary.TotalCPUSamples)
+	}
+	if summary.TotalMemoryBytes != 614400 {
+		t.Errorf("Expected memory total from inuse_space (614400), got %d", summary.TotalMemoryBytes)
+	}
+
+	analyzer.SetMemorySampleType("bogus")
+	if _, err := analyzer.Analyze(); err == nil {
+		t.Error("Expected error for unknown memory sample type")
+	}
+}
+
+func TestSampleTypes(t *testing.T) {
+	types, err := SampleTypes(createTestMemoryProfile())
+	if err != nil {
+		t.Fatalf("SampleTypes() failed: %v", err)
+	}
+	expected := []string{"alloc_objects", "alloc_space", "inuse_objects", "inuse_space"}
+	if strings.Join(types, ",") != strings.Join(expected, ",") {
+		t.Errorf("SampleTypes() = %v, want %v", types, expected)
+	}
+
+	if _, err := SampleTypes([]byte("garbage")); err == nil {
+		t.Error("Expected error for invalid profile data")
+	}
+}
+
+// createTestHeapProfile creates a heap profile with the given in-use bytes
+// per function
+func createTestHeapProfile(inuse map[string]int64) []byte {
+	prof := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "alloc_objects", Unit: "count"},
+			{Type: "alloc_space", Unit: "bytes"},
+			{Type: "inuse_objects", Unit: "count"},
+			{Type: "inuse_space", Unit: "bytes"},
+		},
+		PeriodType: &profile.ValueType{Type: "space", Unit: "bytes"},
+		Period:     524288,
+	}
+	id := uint64(0)
+	for name, bytes := range inuse {
+		id++
+		fn := &profile.Function{ID: id, Name: name}
+		loc := &profile.Location{ID: id, Address: 0x1000 * id, Line: []profile.Line{{Function: fn}}}
+		prof.Function = append(prof.Function, fn)
+		prof.Location = append(prof.Location, loc)
+		prof.Sample = append(prof.Sample, &profile.Sample{
+			Location: []*profile.Location{loc},
+			Value:    []int64{1, bytes, 1, bytes},
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := prof.Write(&buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMemoryGrowth(t *testing.T) {
+	const mb = 1024 * 1024
+	analyzer := NewAnalyzer()
+	if err := analyzer.LoadWarmupProfile(createTestHeapProfile(map[string]int64{
+		"main.cache":  2 * mb,
+		"main.buffer": 4 * mb,
+	})); err != nil {
+		t.Fatalf("LoadWarmupProfile() failed: %v", err)
+	}
+	if err := analyzer.LoadMemoryProfile(createTestHeapProfile(map[string]int64{
+		"main.cache":   20 * mb, // Grows with iterations
+		"main.buffer":  4 * mb,  // Steady
+		"main.session": 2 * mb,  // New after warmup
+		"main.tiny":    1024,    // Below the noise floor
+	})); err != nil {
+		t.Fatalf("LoadMemoryProfile() failed: %v", err)
+	}
+
+	summary, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+
+	want := []models.MemoryGrowth{
+		{Function: "main.cache", WarmupBytes: 2 * mb, FinalBytes: 20 * mb, GrowthBytes: 18 * mb},
+		{Function: "main.session", FinalBytes: 2 * mb, GrowthBytes: 2 * mb},
+	}
+	if len(summary.MemoryGrowth) != len(want) {
+		t.Fatalf("MemoryGrowth = %+v, want %+v", summary.MemoryGrowth, want)
+	}
+	for i := range want {
+		if summary.MemoryGrowth[i] != want[i] {
+			t.Errorf("MemoryGrowth[%d] = %+v, want %+v", i, summary.MemoryGrowth[i], want[i])
+		}
+	}
+
+	// The growth replaces the allocation heuristic as the leak signal
+	if len(summary.MemoryLeaks) != 2 {
+		t.Fatalf("Expected 2 leaks, got %+v", summary.MemoryLeaks)
+	}
+	if leak := summary.MemoryLeaks[0]; leak.Function != "main.cache" || leak.Severity != "high" || leak.Bytes != 18*mb {
+		t.Errorf("Unexpected leak: %+v", leak)
+	}
+	if leak := summary.MemoryLeaks[1]; leak.Severity != "medium" {
+		t.Errorf("Expected a medium leak for 2 MB growth, got %+v", leak)
+	}
+}
+
+func TestMemoryGrowthWithoutWarmup(t *testing.T) {
+	analyzer := NewAnalyzer()
+	if err := analyzer.LoadMemoryProfile(createTestHeapProfile(map[string]int64{"main.cache": 20 * 1024 * 1024})); err != nil {
+		t.Fatalf("LoadMemoryProfile() failed: %v", err)
+	}
+	summary, err := analyzer.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze() failed: %v", err)
+	}
+	if summary.MemoryGrowth != nil {
+		t.Errorf("Expected no growth without a warmup profile, got %+v", summary.MemoryGrowth)
+	}
+}
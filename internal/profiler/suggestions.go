@@ -0,0 +1,299 @@
+package profiler
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/google/pprof/profile"
+)
+
+// Identifiers of the suggestion rules. They are stable so that suggestions
+// can be recognized across runs and tools.
+const (
+	RuleCPUHotFunction    = "cpu-hot-function"
+	RuleCPUHotPath        = "cpu-hot-path"
+	RuleAllocationHotspot = "memory-allocation-hotspot"
+	RuleMemoryGrowth      = "memory-growth"
+	RuleMemoryLeak        = "memory-leak"
+	RuleContention        = "contention-hotspot"
+)
+
+// Thresholds above which the rules report an issue
+const (
+	cpuHotFunctionPercent = 30.0
+	cpuHotPathPercent     = 25.0
+	allocationPercent     = 40.0
+	contentionPercent     = 30.0
+)
+
+// rule inspects a profile summary and returns the suggestions it warrants
+type rule func(summary *models.ProfileSummary) []models.Suggestion
+
+// rules are the suggestion rules, applied in order
+var rules = []rule{
+	cpuHotFunctionRule,
+	cpuHotPathRule,
+	allocationHotspotRule,
+	memoryLeakRule,
+	contentionRule,
+}
+
+// generateSuggestions applies the rules to the summary, merges the
+// suggestions addressing the same issue and links each to the benchmarks
+// whose samples show it. The most important suggestions come first.
+func (a *Analyzer) generateSuggestions(summary *models.ProfileSummary) []models.Suggestion {
+	var suggestions []models.Suggestion
+	for _, r := range rules {
+		suggestions = append(suggestions, r(summary)...)
+	}
+	suggestions = dedupSuggestions(suggestions)
+
+	// Growth is attributed to the functions of the final heap profile
+	memory := benchmarksByFunction(a.memoryProfile)
+	benchmarks := map[string]map[string][]string{
+		"cpu":    benchmarksByFunction(a.cpuProfile),
+		"memory": memory,
+		"warmup": memory,
+		"block":  benchmarksByFunction(a.blockProfile),
+		"mutex":  benchmarksByFunction(a.mutexProfile),
+	}
+	for i := range suggestions {
+		s := &suggestions[i]
+		s.Benchmarks = benchmarks[s.Profile][issueFunction(*s)]
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score() > suggestions[j].Score()
+	})
+	return suggestions
+}
+
+// cpuHotFunctionRule reports the functions taking a large share of CPU time
+func cpuHotFunctionRule(summary *models.ProfileSummary) []models.Suggestion {
+	var result []models.Suggestion
+	for _, fn := range summary.CPUTopFunctions {
+		if fn.FlatPercent <= cpuHotFunctionPercent {
+			continue
+		}
+		result = append(result, models.Suggestion{
+			RuleID:     RuleCPUHotFunction,
+			Type:       "cpu",
+			Severity:   severityAbove(fn.FlatPercent, 50),
+			Confidence: confidenceAbove(fn.FlatPercent, cpuHotFunctionPercent, 60),
+			Function:   fn.Name,
+			Issue:      fmt.Sprintf("Function consumes %.1f%% of CPU time", fn.FlatPercent),
+			Suggestion: "Consider optimizing this hot function - profile it in isolation, look for unnecessary allocations, consider algorithmic improvements",
+			Impact:     fmt.Sprintf("Could improve overall performance by up to %.0f%%", fn.FlatPercent*0.7),
+			Profile:    "cpu",
+		})
+	}
+	return result
+}
+
+// cpuHotPathRule reports the hottest call chain when it dominates the
+// profile. A path spreads its cost over several functions, so it is less
+// certain than a hot function.
+func cpuHotPathRule(summary *models.ProfileSummary) []models.Suggestion {
+	for _, path := range summary.HotPaths {
+		if path.Percentage <= cpuHotPathPercent {
+			continue
+		}
+		return []models.Suggestion{{
+			RuleID:     RuleCPUHotPath,
+			Type:       "cpu",
+			Severity:   "medium",
+			Confidence: 0.8 * confidenceAbove(path.Percentage, cpuHotPathPercent, 60),
+			Function:   strings.Join(path.Path, " -> "),
+			Issue:      fmt.Sprintf("Hot path consuming %.1f%% of execution", path.Percentage),
+			Suggestion: "Analyze this call chain for optimization opportunities - consider caching, lazy evaluation, or algorithmic improvements",
+			Impact:     fmt.Sprintf("Optimizing this path could improve performance by %.0f-%.0f%%", path.Percentage*0.5, path.Percentage*0.8),
+			Profile:    "cpu",
+		}}
+	}
+	return nil
+}
+
+// allocationHotspotRule reports the functions allocating most of the memory
+func allocationHotspotRule(summary *models.ProfileSummary) []models.Suggestion {
+	var result []models.Suggestion
+	for _, fn := range summary.MemoryTopFunctions {
+		if fn.FlatPercent <= allocationPercent {
+			continue
+		}
+		result = append(result, models.Suggestion{
+			RuleID:     RuleAllocationHotspot,
+			Type:       "memory",
+			Severity:   severityAbove(fn.FlatPercent, 60),
+			Confidence: confidenceAbove(fn.FlatPercent, allocationPercent, 80),
+			Function:   fn.Name,
+			Issue:      fmt.Sprintf("Function allocates %.1f%% of total memory", fn.FlatPercent),
+			Suggestion: "Consider using sync.Pool for reusable objects, or pre-allocate slices/maps with appropriate capacity",
+			Impact:     "Could significantly reduce allocation pressure and GC overhead",
+			Profile:    "memory",
+		})
+	}
+	return result
+}
+
+// memoryLeakRule reports potential leaks. Growth of in-use memory since
+// warmup is measured, so it is trusted down to medium severity; leaks
+// guessed from allocation patterns are reported only when severe, with
+// low confidence.
+func memoryLeakRule(summary *models.ProfileSummary) []models.Suggestion {
+	measured := summary.MemoryGrowth != nil
+	var result []models.Suggestion
+	for _, leak := range summary.MemoryLeaks {
+		s := models.Suggestion{
+			Type:       "memory",
+			Severity:   leak.Severity,
+			Function:   leak.Function,
+			Issue:      "Potential memory leak detected",
+			Suggestion: "Review this function for retained references, unclosed resources, or unbounded caches",
+			Impact:     "Could prevent memory growth and improve stability",
+		}
+		switch {
+		case measured && leak.Severity != "low":
+			s.RuleID = RuleMemoryGrowth
+			s.Confidence = 0.7
+			if leak.Severity == "high" {
+				s.Confidence = 0.9
+			}
+			s.Issue = leak.Description
+			s.Profile = "warmup"
+		case !measured && leak.Severity == "high":
+			s.RuleID = RuleMemoryLeak
+			s.Confidence = 0.4
+			s.Profile = "memory"
+		default:
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// contentionRule reports the functions causing most of the time goroutines
+// waited on each other, in the block or the mutex profile
+func contentionRule(summary *models.ProfileSummary) []models.Suggestion {
+	var result []models.Suggestion
+	for _, h := range summary.ContentionHotspots {
+		if h.Percentage <= contentionPercent {
+			continue
+		}
+		s := models.Suggestion{
+			RuleID:     RuleContention,
+			Type:       "concurrency",
+			Severity:   severityAbove(h.Percentage, 60),
+			Confidence: confidenceAbove(h.Percentage, contentionPercent, 80),
+			Function:   h.Function,
+			Issue:      fmt.Sprintf("Goroutines spend %.1f%% of their blocked time waiting in %s", h.Percentage, h.Primitive),
+			Suggestion: "Reduce blocking - use buffered channels, batch the work handed between goroutines, or avoid waiting while holding resources",
+			Impact:     "Could improve throughput and scalability under parallel load",
+			Profile:    h.Profile,
+		}
+		if h.Profile == "mutex" {
+			s.Issue = fmt.Sprintf("Holds a contended lock causing %.1f%% of mutex wait time", h.Percentage)
+			s.Suggestion = "Shorten the critical section, shard the lock, or use sync.RWMutex or atomics for read-mostly data"
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// dedupSuggestions keeps one suggestion per issue, the one with the highest
+// score, where an issue is the suggestion type and the function at fault
+func dedupSuggestions(suggestions []models.Suggestion) []models.Suggestion {
+	index := make(map[string]int)
+	var result []models.Suggestion
+	for _, s := range suggestions {
+		key := s.Type + ":" + issueFunction(s)
+		i, seen := index[key]
+		if !seen {
+			index[key] = len(result)
+			result = append(result, s)
+			continue
+		}
+		if s.Score() > result[i].Score() {
+			result[i] = s
+		}
+	}
+	return result
+}
+
+// issueFunction returns the function a suggestion is about: the leaf of a
+// hot path, otherwise the suggestion's function
+func issueFunction(s models.Suggestion) string {
+	if s.RuleID == RuleCPUHotPath {
+		path := strings.Split(s.Function, " -> ")
+		return path[len(path)-1]
+	}
+	return s.Function
+}
+
+// severityAbove returns "high" when value exceeds high, otherwise "medium"
+func severityAbove(value, high float64) string {
+	if value > high {
+		return "high"
+	}
+	return "medium"
+}
+
+// confidenceAbove scales how far value exceeds threshold into a confidence
+// between 0.5, just above it, and 1, at saturation or more
+func confidenceAbove(value, threshold, saturation float64) float64 {
+	c := 0.5 + 0.5*(value-threshold)/(saturation-threshold)
+	return max(0.5, min(1, c))
+}
+
+// benchmarksByFunction maps each function in the samples of prof to the
+// benchmarks whose stacks include it, by name without the "Benchmark"
+// prefix as results are named
+func benchmarksByFunction(prof *profile.Profile) map[string][]string {
+	if prof == nil {
+		return nil
+	}
+	result := make(map[string][]string)
+	for _, sample := range prof.Sample {
+		var functions []string
+		var benchmark string
+		for _, loc := range sample.Location {
+			for _, line := range loc.Line {
+				if line.Function == nil {
+					continue
+				}
+				name := cleanFunctionName(line.Function.Name)
+				functions = append(functions, name)
+				if b := benchmarkName(name); b != "" {
+					benchmark = b
+				}
+			}
+		}
+		if benchmark == "" {
+			continue
+		}
+		for _, fn := range functions {
+			if !slices.Contains(result[fn], benchmark) {
+				result[fn] = append(result[fn], benchmark)
+			}
+		}
+	}
+	for _, names := range result {
+		sort.Strings(names)
+	}
+	return result
+}
+
+// benchmarkName returns the benchmark a function such as
+// "pkg.BenchmarkParse" or its closure "pkg.BenchmarkParse.func1" belongs to,
+// or "" for other functions
+func benchmarkName(function string) string {
+	for _, part := range strings.Split(function, ".") {
+		if name, ok := strings.CutPrefix(part, "Benchmark"); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package profiler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/google/pprof/profile"
+)
+
+// createStackProfile creates a CPU profile with one sample per stack, each
+// listed from leaf to root
+func createStackProfile(stacks ...[]string) *profile.Profile {
+	prof := &profile.Profile{SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}}}
+	functions := make(map[string]*profile.Location)
+	for _, stack := range stacks {
+		sample := &profile.Sample{Value: []int64{1}}
+		for _, name := range stack {
+			loc, ok := functions[name]
+			if !ok {
+				id := uint64(len(functions) + 1)
+				fn := &profile.Function{ID: id, Name: name}
+				loc = &profile.Location{ID: id, Line: []profile.Line{{Function: fn}}}
+				functions[name] = loc
+				prof.Function = append(prof.Function, fn)
+				prof.Location = append(prof.Location, loc)
+			}
+			sample.Location = append(sample.Location, loc)
+		}
+		prof.Sample = append(prof.Sample, sample)
+	}
+	return prof
+}
+
+func TestGenerateSuggestions(t *testing.T) {
+	analyzer := NewAnalyzer()
+	analyzer.cpuProfile = createStackProfile(
+		[]string{"example.com/pkg.parse", "example.com/pkg.BenchmarkParse.func1", "testing.(*B).runN"},
+		[]string{"example.com/pkg.parse", "example.com/pkg.BenchmarkDecode"},
+		[]string{"example.com/pkg.helper", "testing.(*B).runN"},
+	)
+
+	summary := &models.ProfileSummary{
+		CPUTopFunctions: []models.FunctionProfile{
+			{Name: "pkg.parse", FlatPercent: 45},
+			{Name: "pkg.helper", FlatPercent: 10}, // Below the threshold
+		},
+		// Same issue as the hot function, with less confidence
+		HotPaths: []models.HotPath{
+			{Path: []string{"pkg.BenchmarkParse", "pkg.parse"}, Percentage: 40},
+		},
+		MemoryTopFunctions: []models.FunctionProfile{{Name: "pkg.buffer", FlatPercent: 90}},
+		MemoryGrowth:       []models.MemoryGrowth{{Function: "pkg.cache", GrowthBytes: 2 << 20}},
+		MemoryLeaks: []models.MemoryLeak{
+			{Function: "pkg.cache", Severity: "medium", Description: "In-use memory grew by 2.0 MB after warmup"},
+			{Function: "pkg.tiny", Severity: "low"},
+		},
+	}
+
+	suggestions := analyzer.generateSuggestions(summary)
+	var rules []string
+	for _, s := range suggestions {
+		rules = append(rules, s.RuleID+" "+s.Function)
+	}
+	want := []string{
+		RuleAllocationHotspot + " pkg.buffer",
+		RuleCPUHotFunction + " pkg.parse",
+		RuleMemoryGrowth + " pkg.cache",
+	}
+	if !slices.Equal(rules, want) {
+		t.Fatalf("Suggestions = %v, want %v", rules, want)
+	}
+
+	buffer, parse, cache := suggestions[0], suggestions[1], suggestions[2]
+	if buffer.Severity != "high" || buffer.Confidence != 1 {
+		t.Errorf("Expected a certain high allocation hotspot, got %+v", buffer)
+	}
+	if parse.Severity != "medium" || parse.Confidence != 0.75 || parse.Profile != "cpu" {
+		t.Errorf("Unexpected hot function suggestion: %+v", parse)
+	}
+	if !slices.Equal(parse.Benchmarks, []string{"Decode", "Parse"}) {
+		t.Errorf("Benchmarks = %v, want [Decode Parse]", parse.Benchmarks)
+	}
+	if cache.Profile != "warmup" || cache.Issue != summary.MemoryLeaks[0].Description {
+		t.Errorf("Unexpected growth suggestion: %+v", cache)
+	}
+}
+
+func TestGenerateSuggestionsHeuristicLeak(t *testing.T) {
+	summary := &models.ProfileSummary{
+		MemoryLeaks: []models.MemoryLeak{
+			{Function: "pkg.cache", Severity: "high"},
+			{Function: "pkg.buffer", Severity: "medium"}, // Too uncertain without a warmup profile
+		},
+	}
+	suggestions := NewAnalyzer().generateSuggestions(summary)
+	if len(suggestions) != 1 {
+		t.Fatalf("Expected 1 suggestion, got %+v", suggestions)
+	}
+	if s := suggestions[0]; s.RuleID != RuleMemoryLeak || s.Confidence != 0.4 || s.Profile != "memory" {
+		t.Errorf("Unexpected leak suggestion: %+v", s)
+	}
+}
+
+func TestDedupSuggestions(t *testing.T) {
+	path := models.Suggestion{RuleID: RuleCPUHotPath, Type: "cpu", Severity: "medium", Confidence: 0.8, Function: "a -> b"}
+	function := models.Suggestion{RuleID: RuleCPUHotFunction, Type: "cpu", Severity: "medium", Confidence: 0.6, Function: "b"}
+	other := models.Suggestion{RuleID: RuleAllocationHotspot, Type: "memory", Severity: "high", Confidence: 0.5, Function: "b"}
+
+	result := dedupSuggestions([]models.Suggestion{function, path, other})
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 suggestions, got %+v", result)
+	}
+	if result[0].RuleID != RuleCPUHotPath {
+		t.Errorf("Expected the more confident hot path to be kept, got %+v", result[0])
+	}
+	if result[1].RuleID != RuleAllocationHotspot {
+		t.Errorf("Expected the memory suggestion to be kept apart, got %+v", result[1])
+	}
+}
+
+func TestConfidenceAbove(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  float64
+	}{
+		{30, 0.5},
+		{45, 0.75},
+		{60, 1},
+		{95, 1},
+	}
+	for _, tt := range tests {
+		if got := confidenceAbove(tt.value, 30, 60); got != tt.want {
+			t.Errorf("confidenceAbove(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBenchmarkName(t *testing.T) {
+	tests := map[string]string{
+		"pkg.BenchmarkParse":       "Parse",
+		"pkg.BenchmarkParse.func1": "Parse",
+		"pkg.Benchmark":            "",
+		"pkg.parse":                "",
+		"testing.(*B).runN":        "",
+	}
+	for function, want := range tests {
+		if got := benchmarkName(function); got != want {
+			t.Errorf("benchmarkName(%q) = %q, want %q", function, got, want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// Package remote shares the benchmark history through an S3-compatible
+// bucket, such as Amazon S3, Google Cloud Storage with HMAC keys or MinIO,
+// so that CI workers and developer machines see the same runs and
+// baselines.
+package remote
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Location is where the history is kept in a bucket
+type Location struct {
+	Scheme   string // "s3" or "gs"
+	Bucket   string
+	Prefix   string // Key prefix without a trailing slash; empty for the bucket root
+	Endpoint string // Base URL of an S3-compatible service; empty for AWS
+	Region   string
+}
+
+// ParseLocation parses a remote URL: s3://bucket/prefix for Amazon S3 or an
+// S3-compatible service at endpoint, gs://bucket/prefix for Google Cloud
+// Storage. An empty endpoint or region is taken from the environment.
+func ParseLocation(rawURL, endpoint, region string) (*Location, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote %q: use s3://bucket/prefix or gs://bucket/prefix", rawURL)
+	}
+	loc := &Location{
+		Scheme:   u.Scheme,
+		Bucket:   u.Host,
+		Prefix:   strings.Trim(u.Path, "/"),
+		Endpoint: strings.TrimSuffix(firstNonEmpty(endpoint, os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL")), "/"),
+		Region:   firstNonEmpty(region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")),
+	}
+	switch u.Scheme {
+	case "s3":
+	case "gs":
+		// Cloud Storage's XML API is S3-compatible
+		if loc.Endpoint == "" {
+			loc.Endpoint = "https://storage.googleapis.com"
+		}
+		if loc.Region == "" {
+			loc.Region = "auto"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported remote scheme %q: use s3:// or gs://", u.Scheme)
+	}
+	if loc.Region == "" {
+		loc.Region = "us-east-1"
+	}
+	return loc, nil
+}
+
+// String returns the remote URL of the location
+func (l *Location) String() string {
+	if l.Prefix == "" {
+		return l.Scheme + "://" + l.Bucket
+	}
+	return l.Scheme + "://" + l.Bucket + "/" + l.Prefix
+}
+
+// Credentials sign requests to the bucket
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// CredentialsFromEnv reads the credentials from the standard AWS
+// environment variables, which also hold HMAC keys for Cloud Storage
+func CredentialsFromEnv() (Credentials, error) {
+	creds := Credentials{
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKey == "" || creds.SecretKey == "" {
+		return creds, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+	return creds, nil
+}
+
+// ErrPreconditionFailed is returned by Put when the object changed since
+// the caller last saw it, e.g. because another machine stored it first
+var ErrPreconditionFailed = errors.New("object was changed concurrently")
+
+// ErrNotFound is returned by Get for missing objects
+var ErrNotFound = errors.New("object not found")
+
+// Object is an object listed in the bucket
+type Object struct {
+	Key  string // Key relative to the location's prefix
+	ETag string // Entity tag without quotes; the MD5 of the content for simple uploads
+	Size int64
+}
+
+// Client accesses a location with the S3 REST API
+type Client struct {
+	loc    *Location
+	creds  Credentials
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewClient creates a client for the location
+func NewClient(loc *Location, creds Credentials) *Client {
+	return &Client{loc: loc, creds: creds, client: &http.Client{Timeout: 60 * time.Second}, now: time.Now}
+}
+
+// List returns the objects whose keys start with prefix, relative to the
+// location
+func (c *Client) List(prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {c.key(prefix)}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := c.do("GET", "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key  string
+				ETag string
+				Size int64
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bucket listing: %w", err)
+		}
+		for _, o := range result.Contents {
+			objects = append(objects, Object{
+				Key:  strings.TrimPrefix(o.Key, c.key("")),
+				ETag: strings.Trim(o.ETag, `"`),
+				Size: o.Size,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Get returns the content of an object and its entity tag
+func (c *Client) Get(key string) ([]byte, string, error) {
+	resp, err := c.do("GET", c.key(key), nil, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return data, strings.Trim(resp.Header.Get("ETag"), `"`), nil
+}
+
+// Put stores an object. With an empty etag the object must not exist yet;
+// otherwise it must still have that entity tag, so that concurrent writers
+// never silently overwrite each other. Such conflicts return
+// ErrPreconditionFailed. The etag "*" overwrites unconditionally.
+func (c *Client) Put(key string, data []byte, etag string) error {
+	header := http.Header{"Content-Type": {"application/json"}}
+	switch etag {
+	case "":
+		if c.loc.Scheme == "gs" {
+			// Cloud Storage expresses "does not exist" as generation 0
+			header.Set("X-Goog-If-Generation-Match", "0")
+		} else {
+			header.Set("If-None-Match", "*")
+		}
+	case "*":
+	default:
+		header.Set("If-Match", `"`+etag+`"`)
+	}
+	resp, err := c.do("PUT", c.key(key), nil, header, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// key returns the full key of a key relative to the location
+func (c *Client) key(key string) string {
+	if c.loc.Prefix == "" {
+		return key
+	}
+	return c.loc.Prefix + "/" + key
+}
+
+// do sends a signed request for an object key, or for the bucket when key
+// is empty, and fails unless the response is successful
+func (c *Client) do(method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.objectURL(key, query), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	sign(req, body, c.creds, c.loc.Region, c.now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", c.loc, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return nil, fmt.Errorf("%s: %w", key, ErrPreconditionFailed)
+	case http.StatusNotFound:
+		if key != "" {
+			return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+		}
+	}
+	var s3Err struct {
+		Code    string
+		Message string
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, c.loc, s3Err.Code, s3Err.Message)
+	}
+	return nil, fmt.Errorf("%s %s: status %d", method, c.loc, resp.StatusCode)
+}
+
+// objectURL returns the URL of an object: virtual-hosted on AWS and
+// path-style on other services
+func (c *Client) objectURL(key string, query url.Values) string {
+	var base string
+	if c.loc.Endpoint == "" {
+		base = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", c.loc.Bucket, c.loc.Region)
+	} else {
+		base = c.loc.Endpoint + "/" + c.loc.Bucket + "/"
+	}
+	u := base + uriEncode(key, false)
+	if len(query) > 0 {
+		u += "?" + canonicalQuery(query)
+	}
+	return u
+}
+
+// sign adds AWS Signature Version 4 headers to a request. The host and
+// every header set on the request are signed.
+func sign(req *http.Request, body []byte, creds Credentials, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query parameters sorted by name, as signed
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and
+// slashes unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
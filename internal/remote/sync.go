@@ -0,0 +1,286 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Kinds of synced items, which are also their key prefixes in the bucket
+const (
+	KindRun      = "runs"
+	KindBaseline = "baselines"
+)
+
+// Item is a run or a baseline
+type Item struct {
+	Kind string // KindRun or KindBaseline
+	Name string // Run ID or baseline name
+}
+
+// String describes the item, e.g. "run 01J..." or "baseline main"
+func (i Item) String() string {
+	if i.Kind == KindBaseline {
+		return "baseline " + i.Name
+	}
+	return "run " + i.Name
+}
+
+// key returns the key of the item relative to the location
+func (i Item) key() string {
+	return i.Kind + "/" + i.Name + ".json"
+}
+
+// Conflict is an item whose local and remote copies differ and that was
+// left alone
+type Conflict struct {
+	Item
+	Reason string
+}
+
+// Options control a sync
+type Options struct {
+	DryRun bool // Report what would be transferred without changing anything
+	Force  bool // Replace conflicting copies with the ones being synced
+}
+
+// Report is the outcome of a sync
+type Report struct {
+	Transferred []Item     // Items uploaded by Push or downloaded by Pull
+	Unchanged   int        // Items already identical on both sides
+	Conflicts   []Conflict // Items left alone because both sides changed
+}
+
+// Syncer synchronizes a local storage with a bucket. Runs have unique IDs,
+// so runs stored concurrently on different machines never collide and are
+// simply merged. Uploads are conditional: a run is only created when no
+// other machine stored it in the meantime, and a baseline only replaced
+// when it is still the copy that was compared, so concurrent pushes never
+// silently overwrite each other.
+type Syncer struct {
+	store  *storage.Storage
+	client *Client
+}
+
+// NewSyncer creates a syncer between store and the bucket of client
+func NewSyncer(store *storage.Storage, client *Client) *Syncer {
+	return &Syncer{store: store, client: client}
+}
+
+// Push uploads the local runs and baselines that are missing or older in
+// the bucket
+func (s *Syncer) Push(opts Options) (*Report, error) {
+	remote, err := s.remoteItems()
+	if err != nil {
+		return nil, err
+	}
+	local, err := s.localItems()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, item := range local {
+		data, err := s.localData(item)
+		if err != nil {
+			return report, err
+		}
+
+		etag := ""
+		if obj, ok := remote[item]; ok {
+			if obj.ETag == contentMD5(data) {
+				report.Unchanged++
+				continue
+			}
+			var remoteData []byte
+			remoteData, etag, err = s.client.Get(item.key())
+			if err != nil {
+				return report, err
+			}
+			transfer, reason := decide(item, data, remoteData, "remote", opts.Force)
+			if reason != "" {
+				report.Conflicts = append(report.Conflicts, Conflict{Item: item, Reason: reason})
+				continue
+			}
+			if !transfer {
+				report.Unchanged++
+				continue
+			}
+		}
+
+		if !opts.DryRun {
+			err := s.client.Put(item.key(), data, etag)
+			if errors.Is(err, ErrPreconditionFailed) {
+				report.Conflicts = append(report.Conflicts, Conflict{Item: item, Reason: "stored concurrently by another machine, pull and push again"})
+				continue
+			}
+			if err != nil {
+				return report, err
+			}
+		}
+		report.Transferred = append(report.Transferred, item)
+	}
+	return report, nil
+}
+
+// Pull downloads the runs and baselines that are missing or older in the
+// local storage
+func (s *Syncer) Pull(opts Options) (*Report, error) {
+	remote, err := s.remoteItems()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, len(remote))
+	for item := range remote {
+		items = append(items, item)
+	}
+	sortItems(items)
+
+	report := &Report{}
+	for _, item := range items {
+		local, err := s.localData(item)
+		exists := err == nil
+		if exists && contentMD5(local) == remote[item].ETag {
+			report.Unchanged++
+			continue
+		}
+
+		data, _, err := s.client.Get(item.key())
+		if err != nil {
+			return report, err
+		}
+		if exists {
+			transfer, reason := decide(item, data, local, "local", opts.Force)
+			if reason != "" {
+				report.Conflicts = append(report.Conflicts, Conflict{Item: item, Reason: reason})
+				continue
+			}
+			if !transfer {
+				report.Unchanged++
+				continue
+			}
+		}
+
+		if !opts.DryRun {
+			if item.Kind == KindBaseline {
+				_, err = s.store.ImportBaseline(data)
+			} else {
+				_, err = s.store.ImportRun(data)
+			}
+			if err != nil {
+				return report, fmt.Errorf("failed to store %s: %w", item, err)
+			}
+		}
+		report.Transferred = append(report.Transferred, item)
+	}
+	return report, nil
+}
+
+// decide compares the copy being synced with the other side's copy of an
+// item. It reports whether the copy should be transferred, or why not when
+// the two copies conflict.
+func decide(item Item, data, other []byte, otherSide string, force bool) (bool, string) {
+	if bytes.Equal(data, other) {
+		return false, ""
+	}
+	if force {
+		return true, ""
+	}
+	if item.Kind == KindRun {
+		// Runs never change once saved, so differing copies of the same ID
+		// were edited or stored by different machines
+		return false, fmt.Sprintf("the %s copy differs, use -force to replace it", otherSide)
+	}
+
+	// The most recently saved baseline wins
+	created, otherCreated := createdAt(data), createdAt(other)
+	if created.After(otherCreated) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("the %s copy is newer, use -force to replace it", otherSide)
+}
+
+// createdAt returns the creation time of a baseline, or the zero time when
+// the data cannot be parsed
+func createdAt(data []byte) time.Time {
+	var baseline struct {
+		CreatedAt time.Time `json:"created_at"`
+	}
+	json.Unmarshal(data, &baseline)
+	return baseline.CreatedAt
+}
+
+// remoteItems lists the runs and baselines in the bucket
+func (s *Syncer) remoteItems() (map[Item]Object, error) {
+	items := make(map[Item]Object)
+	for _, kind := range []string{KindRun, KindBaseline} {
+		objects, err := s.client.List(kind + "/")
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			name, ok := strings.CutSuffix(strings.TrimPrefix(obj.Key, kind+"/"), ".json")
+			if !ok || name == "" || strings.Contains(name, "/") {
+				continue
+			}
+			items[Item{Kind: kind, Name: name}] = obj
+		}
+	}
+	return items, nil
+}
+
+// localItems lists the runs and baselines in the local storage
+func (s *Syncer) localItems() ([]Item, error) {
+	ids, err := s.store.RunIDs()
+	if err != nil {
+		return nil, err
+	}
+	baselines, err := s.store.ListBaselines()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, id := range ids {
+		items = append(items, Item{Kind: KindRun, Name: id})
+	}
+	for _, baseline := range baselines {
+		items = append(items, Item{Kind: KindBaseline, Name: baseline.Name})
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// localData returns the stored JSON of a local item
+func (s *Syncer) localData(item Item) ([]byte, error) {
+	if item.Kind == KindBaseline {
+		return s.store.BaselineData(item.Name)
+	}
+	return s.store.RunData(item.Name)
+}
+
+// sortItems orders runs before baselines, so that a baseline is never
+// synced without the run it refers to, and each kind by name
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind == KindRun
+		}
+		return items[i].Name < items[j].Name
+	})
+}
+
+// contentMD5 returns the hex MD5 of data, which is the entity tag S3 gives
+// objects uploaded in a single part
+func contentMD5(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
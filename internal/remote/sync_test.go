@@ -0,0 +1,167 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+func saveRun(t *testing.T, store *storage.Storage, id string, nsPerOp float64) {
+	t.Helper()
+	run := &models.BenchmarkRun{
+		ID:        id,
+		Timestamp: time.Now(),
+		Results:   []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: nsPerOp}},
+	}
+	if err := store.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+}
+
+func TestPushAndPull(t *testing.T) {
+	fake, client := newFakeS3(t)
+
+	// Two CI workers store runs concurrently and both push
+	worker1 := storage.NewStorage(t.TempDir())
+	saveRun(t, worker1, "run-1", 100)
+	if _, err := worker1.SaveBaseline("main", "run-1", "", nil); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+	worker2 := storage.NewStorage(t.TempDir())
+	saveRun(t, worker2, "run-2", 110)
+
+	for _, store := range []*storage.Storage{worker1, worker2} {
+		report, err := NewSyncer(store, client).Push(Options{})
+		if err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+		if len(report.Conflicts) != 0 {
+			t.Errorf("Unexpected conflicts: %+v", report.Conflicts)
+		}
+	}
+	if len(fake.objects) != 3 {
+		t.Errorf("Expected both runs and the baseline in the bucket, got %d objects", len(fake.objects))
+	}
+
+	// A developer machine pulls the merged history
+	dev := storage.NewStorage(t.TempDir())
+	syncer := NewSyncer(dev, client)
+	report, err := syncer.Pull(Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	if len(report.Transferred) != 3 || dev.Exists("run-1") {
+		t.Errorf("Expected a dry run to report 3 items without storing them, got %+v", report)
+	}
+
+	report, err = syncer.Pull(Options{})
+	if err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	want := []Item{{KindRun, "run-1"}, {KindRun, "run-2"}, {KindBaseline, "main"}}
+	if len(report.Transferred) != len(want) {
+		t.Fatalf("Transferred = %v, want %v", report.Transferred, want)
+	}
+	for i, item := range want {
+		if report.Transferred[i] != item {
+			t.Errorf("Transferred[%d] = %v, want %v", i, report.Transferred[i], item)
+		}
+	}
+	if !dev.Exists("run-2") || !dev.HasBaseline("main") {
+		t.Error("Expected the pulled runs and baseline to be stored")
+	}
+
+	// Syncing again transfers nothing
+	puts := fake.puts
+	report, err = syncer.Push(Options{})
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if len(report.Transferred) != 0 || report.Unchanged != 3 || fake.puts != puts {
+		t.Errorf("Expected everything unchanged, got %+v", report)
+	}
+}
+
+func TestSyncConflicts(t *testing.T) {
+	_, client := newFakeS3(t)
+
+	local := storage.NewStorage(t.TempDir())
+	saveRun(t, local, "run-1", 100)
+	if _, err := NewSyncer(local, client).Push(Options{}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	// Another machine stored a different run under the same ID
+	other := storage.NewStorage(t.TempDir())
+	saveRun(t, other, "run-1", 200)
+	syncer := NewSyncer(other, client)
+	report, err := syncer.Push(Options{})
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if len(report.Conflicts) != 1 || len(report.Transferred) != 0 {
+		t.Fatalf("Expected a conflict, got %+v", report)
+	}
+	report, err = syncer.Pull(Options{})
+	if err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	if len(report.Conflicts) != 1 {
+		t.Errorf("Expected a conflict on pull, got %+v", report)
+	}
+	if run, _ := other.Load("run-1"); run.Results[0].NsPerOp != 200 {
+		t.Error("Expected the conflicting local run to be left alone")
+	}
+
+	// -force replaces the remote copy
+	report, err = syncer.Push(Options{Force: true})
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if len(report.Transferred) != 1 || len(report.Conflicts) != 0 {
+		t.Errorf("Expected a forced push to replace the run, got %+v", report)
+	}
+}
+
+func TestSyncBaselineNewestWins(t *testing.T) {
+	_, client := newFakeS3(t)
+
+	older := storage.NewStorage(t.TempDir())
+	saveRun(t, older, "run-1", 100)
+	if _, err := older.SaveBaseline("main", "run-1", "old", nil); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+	newer := storage.NewStorage(t.TempDir())
+	saveRun(t, newer, "run-2", 90)
+	time.Sleep(10 * time.Millisecond)
+	if _, err := newer.SaveBaseline("main", "run-2", "new", nil); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+
+	if _, err := NewSyncer(newer, client).Push(Options{}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	// The older baseline does not replace the newer one in the bucket...
+	report, err := NewSyncer(older, client).Push(Options{})
+	if err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Item != (Item{KindBaseline, "main"}) {
+		t.Errorf("Expected the baseline to conflict, got %+v", report)
+	}
+
+	// ...but replaces the older local one on pull
+	if _, err := NewSyncer(older, client).Pull(Options{}); err != nil {
+		t.Fatalf("Pull failed: %v", err)
+	}
+	baseline, err := older.LoadBaseline("main")
+	if err != nil {
+		t.Fatalf("LoadBaseline failed: %v", err)
+	}
+	if baseline.RunID != "run-2" || !older.Exists("run-2") {
+		t.Errorf("Expected the newer baseline and its run to be pulled, got %+v", baseline)
+	}
+}
@@ -0,0 +1,214 @@
+package runner
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// siPrefixes scale the values benchstat prints with a unit prefix,
+// binary prefixes first as they end like decimal ones
+var siPrefixes = []struct {
+	prefix string
+	scale  float64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"n", 1e-9}, {"µ", 1e-6}, {"μ", 1e-6}, {"u", 1e-6}, {"m", 1e-3},
+	{"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// benchstatColumn is a column of a benchstat table holding the center
+// values of one input
+type benchstatColumn struct {
+	index int
+	label string // Input the column summarizes, e.g. "old.txt"; empty with a single input
+	unit  string
+}
+
+// ImportBenchstatCSV parses the tables printed by benchstat -format=csv,
+// or by the older benchstat -csv, returning a run for each input they
+// compare, in the order of their columns. Benchstat only prints the center
+// of the samples of a benchmark, so results have no samples; the deltas,
+// confidence intervals and geomeans it computed are dropped. Configuration
+// lines such as "pkg: example.com/codec" apply to the tables that follow.
+func ImportBenchstatCSV(reader io.Reader) ([]*models.BenchmarkRun, error) {
+	type input struct {
+		label   string
+		results []models.BenchmarkResult
+		index   map[string]int // Result index by package and name
+	}
+	var inputs []*input
+	byLabel := make(map[string]*input)
+	config := make(map[string]string)
+
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var header [][]string // Header rows of the current table
+	var columns []benchstatColumn
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read benchstat CSV: %w", err)
+		}
+
+		line := strings.Join(record, ",")
+		if key, value, ok := parseConfigLine(line); ok && !strings.Contains(key, ",") {
+			config[key] = value
+			continue
+		}
+		// Header rows start with an empty cell, or with "name" before
+		// benchstat v2
+		if record[0] == "" || record[0] == "name" {
+			if columns != nil {
+				header, columns = nil, nil
+			}
+			header = append(header, record)
+			continue
+		}
+		if columns == nil {
+			if columns, err = benchstatColumns(header); err != nil {
+				return nil, err
+			}
+		}
+
+		name := strings.TrimPrefix(record[0], "Benchmark")
+		if name == "geomean" || name == "[Geo mean]" {
+			continue
+		}
+		for _, column := range columns {
+			if column.index >= len(record) {
+				continue
+			}
+			v, ok := parseBenchstatValue(record[column.index])
+			if !ok {
+				continue
+			}
+			in := byLabel[column.label]
+			if in == nil {
+				in = &input{label: column.label, index: make(map[string]int)}
+				byLabel[column.label] = in
+				inputs = append(inputs, in)
+			}
+			key := config["pkg"] + " " + name
+			i, ok := in.index[key]
+			if !ok {
+				result := models.BenchmarkResult{Name: name, Package: config["pkg"], Status: models.StatusOK}
+				setFamily(&result)
+				i = len(in.results)
+				in.index[key] = i
+				in.results = append(in.results, result)
+			}
+			setBenchstatValue(&in.results[i], column.unit, v)
+		}
+	}
+	if len(inputs) == 0 {
+		return nil, errors.New("no benchstat table found in input")
+	}
+
+	runs := make([]*models.BenchmarkRun, 0, len(inputs))
+	for _, in := range inputs {
+		run := importedRun(in.results, config)
+		run.Note = in.label
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// benchstatColumns finds the columns holding center values in the header
+// of a table. Benchstat v2 prints a row of input labels above a row of
+// units, in which "CI", "vs base" and "P" columns describe the values
+// before them. The older benchstat prints a single row naming both, like
+// "old time/op (ns/op)", followed by "±" and "delta" columns.
+func benchstatColumns(header [][]string) ([]benchstatColumn, error) {
+	if len(header) == 0 {
+		return nil, errors.New("benchstat CSV table without a header")
+	}
+	var columns []benchstatColumn
+
+	if header[0][0] == "name" {
+		for i, cell := range header[0][1:] {
+			open, close := strings.LastIndex(cell, "("), strings.LastIndex(cell, ")")
+			if open < 0 || close < open {
+				continue
+			}
+			// The label precedes the quantity, as in "old time/op"
+			label := ""
+			if words := strings.Fields(cell[:open]); len(words) > 1 {
+				label = strings.Join(words[:len(words)-1], " ")
+			}
+			columns = append(columns, benchstatColumn{i + 1, label, cell[open+1 : close]})
+		}
+	} else {
+		units := header[len(header)-1]
+		var labels []string
+		if len(header) > 1 {
+			labels = header[len(header)-2]
+		}
+		label := ""
+		for i := 1; i < len(units); i++ {
+			if i < len(labels) && labels[i] != "" {
+				label = labels[i]
+			}
+			switch units[i] {
+			case "", "CI", "vs base", "P":
+				continue
+			}
+			columns = append(columns, benchstatColumn{i, label, units[i]})
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no value column in benchstat CSV header %q", strings.Join(header[len(header)-1], ","))
+	}
+	return columns, nil
+}
+
+// parseBenchstatValue parses a center value, which benchstat may print
+// with a unit prefix such as "1.5µ" or "2.0Ki". Values benchstat could not
+// compute, like "?", are not ok.
+func parseBenchstatValue(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	}
+	for _, si := range siPrefixes {
+		if number, ok := strings.CutSuffix(s, si.prefix); ok {
+			if v, err := strconv.ParseFloat(number, 64); err == nil {
+				return v * si.scale, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// setBenchstatValue records a value in the unit of its column. Benchstat
+// v2 normalizes time to sec/op and throughput to B/s.
+func setBenchstatValue(result *models.BenchmarkResult, unit string, v float64) {
+	switch unit {
+	case "sec/op":
+		result.NsPerOp = v * 1e9
+	case "ns/op":
+		result.NsPerOp = v
+	case "B/s":
+		result.MBPerSec = v / 1e6
+	case "MB/s":
+		result.MBPerSec = v
+	case "B/op":
+		result.BytesPerOp = int64(math.Round(v))
+	case "allocs/op":
+		result.AllocsPerOp = int64(math.Round(v))
+	default:
+		setMetric(result, unit, v)
+	}
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportBenchstatCSV(t *testing.T) {
+	// benchstat -format=csv old.txt new.txt
+	input := `goos: linux
+goarch: amd64
+pkg: example.com/codec
+cpu: Intel(R) Xeon(R) CPU @ 2.20GHz
+,old.txt,,new.txt
+,sec/op,CI,sec/op,CI,vs base,P
+Encode/json-8,1.5e-06,2%,1.2e-06,1%,-20.00%,p=0.000 n=10
+Decode-8,2.5µ,3%,2.5µ,2%,~,p=0.481 n=10
+geomean,1.936e-06,,1.732e-06,,-10.56%
+
+,old.txt,,new.txt
+,B/op,CI,B/op,CI,vs base,P
+Encode/json-8,512,0%,256,0%,-50.00%,p=0.000 n=10
+Decode-8,1.5Ki,0%,1.5Ki,0%,~,p=1.000 n=10
+`
+	runs, err := ImportBenchstatCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportBenchstatCSV failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Note != "old.txt" || runs[1].Note != "new.txt" {
+		t.Fatalf("Expected runs of old.txt and new.txt, got %d", len(runs))
+	}
+
+	old := runs[0]
+	if old.Package != "example.com/codec" || old.Toolchain == nil || old.Toolchain.GOOS != "linux" {
+		t.Errorf("Expected package and platform from the configuration, got %q and %+v", old.Package, old.Toolchain)
+	}
+	if len(old.Results) != 2 {
+		t.Fatalf("Expected 2 results without the geomean, got %+v", old.Results)
+	}
+	encode := old.Results[0]
+	if encode.Name != "Encode/json-8" || encode.Parent != "Encode" || encode.NsPerOp != 1500 || encode.BytesPerOp != 512 {
+		t.Errorf("Unexpected Encode result %+v", encode)
+	}
+	if decode := old.Results[1]; decode.NsPerOp != 2500 || decode.BytesPerOp != 1536 {
+		t.Errorf("Expected prefixed values scaled, got %+v", decode)
+	}
+	if runs[1].Results[0].NsPerOp != 1200 || runs[1].Results[0].BytesPerOp != 256 {
+		t.Errorf("Unexpected new Encode result %+v", runs[1].Results[0])
+	}
+}
+
+func TestImportBenchstatCSVv1(t *testing.T) {
+	// benchstat -csv old.txt new.txt, before benchstat v2
+	input := `name,old time/op (ns/op),±,new time/op (ns/op),±,delta
+Encode-8,1.50E+03,2%,1.20E+03,1%,-20.00%
+name,old speed (MB/s),±,new speed (MB/s),±,delta
+Encode-8,100,2%,125,1%,+25.00%
+`
+	runs, err := ImportBenchstatCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportBenchstatCSV failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Note != "old" || runs[1].Note != "new" {
+		t.Fatalf("Expected runs of old and new, got %d", len(runs))
+	}
+	if r := runs[1].Results[0]; r.Name != "Encode-8" || r.NsPerOp != 1200 || r.MBPerSec != 125 {
+		t.Errorf("Unexpected new Encode result %+v", r)
+	}
+}
+
+func TestImportBenchstatCSVInvalid(t *testing.T) {
+	for _, input := range []string{"", "goos: linux\n", "Encode-8,1,2\n", ",old.txt\n,CI\nEncode-8,1%\n"} {
+		if _, err := ImportBenchstatCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
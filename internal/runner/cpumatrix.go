@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// cpuMatrix returns the distinct GOMAXPROCS values of a -cpu list, in the
+// order given, when it has several. It returns nil for a single value or
+// a list go test will reject.
+func cpuMatrix(list string) []int {
+	var levels []int
+	for _, field := range strings.Split(list, ",") {
+		level, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || level <= 0 {
+			return nil
+		}
+		if !slices.Contains(levels, level) {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) < 2 {
+		return nil
+	}
+	return levels
+}
+
+// assignProcs records the GOMAXPROCS value each result of a CPU matrix
+// was measured at. go test appends it to the benchmark name, except when
+// it is 1.
+func assignProcs(results []models.BenchmarkResult, levels []int) {
+	for i := range results {
+		result := &results[i]
+		if j := strings.LastIndexByte(result.Name, '-'); j >= 0 {
+			if procs, err := strconv.Atoi(result.Name[j+1:]); err == nil && procs > 1 && slices.Contains(levels, procs) {
+				result.Procs = procs
+				continue
+			}
+		}
+		if slices.Contains(levels, 1) {
+			result.Procs = 1
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestCPUMatrix(t *testing.T) {
+	tests := []struct {
+		list string
+		want []int
+	}{
+		{"", nil},
+		{"4", nil},
+		{"1,2,4,8", []int{1, 2, 4, 8}},
+		{"8, 1, 8", []int{8, 1}},
+		{"4,4", nil},
+		{"1,two", nil},
+		{"0,2", nil},
+	}
+	for _, tt := range tests {
+		if got := cpuMatrix(tt.list); !slices.Equal(got, tt.want) {
+			t.Errorf("cpuMatrix(%q) = %v, want %v", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestAssignProcs(t *testing.T) {
+	results := []models.BenchmarkResult{
+		{Name: "BenchmarkGet"},
+		{Name: "BenchmarkGet-4"},
+		{Name: "BenchmarkSizes/size-64"},
+		{Name: "BenchmarkSizes/size-64-4"},
+	}
+	assignProcs(results, []int{1, 4})
+	for i, want := range []int{1, 4, 1, 4} {
+		if results[i].Procs != want {
+			t.Errorf("%s: Procs = %d, want %d", results[i].Name, results[i].Procs, want)
+		}
+	}
+
+	// Without 1 in the matrix, every result carries its GOMAXPROCS suffix
+	results = []models.BenchmarkResult{{Name: "BenchmarkGet-2"}, {Name: "BenchmarkSizes/size-64-8"}, {Name: "BenchmarkOdd-3"}}
+	assignProcs(results, []int{2, 8})
+	for i, want := range []int{2, 8, 0} {
+		if results[i].Procs != want {
+			t.Errorf("%s: Procs = %d, want %d", results[i].Name, results[i].Procs, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// CollectDependencies records the module versions of the module containing
+// the package directory pkg (a path such as "./..." or "./internal/foo").
+// It returns nil without an error when the package is not in a module.
+func CollectDependencies(pkg string) (*models.Dependencies, error) {
+	modFile, err := findGoMod(packageDir(pkg))
+	if err != nil || modFile == "" {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(modFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	deps, err := parseGoMod(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", modFile, err)
+	}
+
+	sum, err := os.ReadFile(filepath.Join(filepath.Dir(modFile), "go.sum"))
+	if err == nil {
+		hash := sha256.Sum256(sum)
+		deps.GoSumHash = hex.EncodeToString(hash[:])
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read go.sum: %w", err)
+	}
+
+	return deps, nil
+}
+
+// findGoMod returns the go.mod file of the module containing dir, or an
+// empty path if there is none
+func findGoMod(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// parseGoMod extracts the module path and required module versions from a
+// go.mod file. Replaced modules are recorded as "version => replacement".
+func parseGoMod(data []byte) (*models.Dependencies, error) {
+	deps := &models.Dependencies{Modules: make(map[string]string)}
+	replacements := make(map[string]string)
+
+	block := "" // Directive of the enclosing ( ... ) block
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		directive := block
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block == "" && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case block == "":
+			directive, fields = fields[0], fields[1:]
+		}
+
+		var err error
+		switch directive {
+		case "module":
+			if len(fields) == 1 {
+				deps.Module, err = unquote(fields[0])
+			}
+		case "require":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("line %d: malformed require", lineNum)
+			}
+			var path string
+			if path, err = unquote(fields[0]); err == nil {
+				deps.Modules[path] = fields[1]
+			}
+		case "replace":
+			arrow := slices.Index(fields, "=>")
+			if arrow < 1 || arrow == len(fields)-1 {
+				return nil, fmt.Errorf("line %d: malformed replace", lineNum)
+			}
+			var path string
+			if path, err = unquote(fields[0]); err == nil {
+				replacements[path] = strings.Join(fields[arrow+1:], " ")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for path, replacement := range replacements {
+		if version, ok := deps.Modules[path]; ok {
+			deps.Modules[path] = version + " => " + replacement
+		}
+	}
+	return deps, nil
+}
+
+// unquote removes the quotes of a quoted module path
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`") {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
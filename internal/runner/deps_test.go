@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testGoMod = `module example.com/app // the app
+
+go 1.22
+
+require example.com/single v1.0.0
+
+require (
+	example.com/direct v1.2.3
+	"example.com/quoted" v0.1.0 // indirect
+	example.com/forked v2.0.0+incompatible
+	example.com/local v1.0.0
+)
+
+replace example.com/forked => example.com/fork v2.0.1
+
+replace (
+	example.com/local => ../local
+	example.com/unused => example.com/other v1.0.0
+)
+
+exclude (
+	example.com/direct v1.2.2
+)
+`
+
+func TestParseGoMod(t *testing.T) {
+	deps, err := parseGoMod([]byte(testGoMod))
+	if err != nil {
+		t.Fatalf("parseGoMod failed: %v", err)
+	}
+
+	if deps.Module != "example.com/app" {
+		t.Errorf("Module = %q, want example.com/app", deps.Module)
+	}
+	want := map[string]string{
+		"example.com/single": "v1.0.0",
+		"example.com/direct": "v1.2.3",
+		"example.com/quoted": "v0.1.0",
+		"example.com/forked": "v2.0.0+incompatible => example.com/fork v2.0.1",
+		"example.com/local":  "v1.0.0 => ../local",
+	}
+	if !reflect.DeepEqual(deps.Modules, want) {
+		t.Errorf("Modules = %v, want %v", deps.Modules, want)
+	}
+}
+
+func TestParseGoModInvalid(t *testing.T) {
+	for _, content := range []string{
+		"require example.com/direct",
+		"replace example.com/direct v1.0.0",
+		"require (\n\t\"example.com/bad v1.0.0\n)",
+	} {
+		if _, err := parseGoMod([]byte(content)); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestCollectDependencies(t *testing.T) {
+	root := t.TempDir()
+	pkg := filepath.Join(root, "internal", "pkg")
+	os.MkdirAll(pkg, 0755)
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte(testGoMod), 0644)
+
+	deps, err := CollectDependencies(pkg + "/...")
+	if err != nil {
+		t.Fatalf("CollectDependencies failed: %v", err)
+	}
+	if deps == nil || deps.Module != "example.com/app" || deps.GoSumHash != "" {
+		t.Fatalf("unexpected dependencies without go.sum: %+v", deps)
+	}
+
+	os.WriteFile(filepath.Join(root, "go.sum"), []byte("example.com/direct v1.2.3 h1:abc=\n"), 0644)
+	first, _ := CollectDependencies(pkg)
+	os.WriteFile(filepath.Join(root, "go.sum"), []byte("example.com/direct v1.2.4 h1:def=\n"), 0644)
+	second, _ := CollectDependencies(pkg)
+	if first.GoSumHash == "" || first.GoSumHash == second.GoSumHash {
+		t.Errorf("go.sum hash does not track changes: %q vs %q", first.GoSumHash, second.GoSumHash)
+	}
+}
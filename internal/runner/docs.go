@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// BenchmarkDocs returns the doc comments of the benchmark functions in the
+// packages matching pkg, keyed by import path and then by benchmark name
+// without the "Benchmark" prefix, as results are named
+func BenchmarkDocs(pkg string) (map[string]map[string]string, error) {
+	return benchmarkDocs("", pkg)
+}
+
+// benchmarkDocs returns the doc comments of the benchmark functions in the
+// packages matching pkg, resolved from dir
+func benchmarkDocs(dir, pkg string) (map[string]map[string]string, error) {
+	packages, err := listTestPackages(dir, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]map[string]string)
+	for _, p := range packages {
+		found, err := parseBenchmarkDocs(append(p.TestGoFiles, p.XTestGoFiles...))
+		if err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			docs[p.ImportPath] = found
+		}
+	}
+	return docs, nil
+}
+
+// testPackage is a package with the paths of its test files
+type testPackage struct {
+	ImportPath   string
+	TestGoFiles  []string // Test files in the package itself
+	XTestGoFiles []string // Test files in the external _test package
+}
+
+// listTestPackages lists the packages matching pkg, resolved from dir, with
+// their test files. Nothing is compiled, and build constraints are
+// respected the way go test does.
+func listTestPackages(dir, pkg string) ([]testPackage, error) {
+	if pkg == "" {
+		pkg = "./..."
+	}
+	format := "{{.ImportPath}}\t{{.Dir}}\t{{join .TestGoFiles \",\"}}\t{{join .XTestGoFiles \",\"}}"
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-e", "-f", format, pkg)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packages: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var packages []testPackage
+	for _, line := range strings.Split(strings.TrimSuffix(string(output), "\n"), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+		files := func(list string) []string {
+			var paths []string
+			for _, name := range strings.Split(list, ",") {
+				if name != "" {
+					paths = append(paths, filepath.Join(fields[1], name))
+				}
+			}
+			return paths
+		}
+		packages = append(packages, testPackage{
+			ImportPath:   fields[0],
+			TestGoFiles:  files(fields[2]),
+			XTestGoFiles: files(fields[3]),
+		})
+	}
+	return packages, nil
+}
+
+// parseBenchmarkDocs returns the doc comments of the benchmark functions
+// declared in the given test files
+func parseBenchmarkDocs(files []string) (map[string]string, error) {
+	docs := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, path := range files {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Doc == nil || !strings.HasPrefix(fn.Name.Name, "Benchmark") {
+				continue
+			}
+			if doc := strings.TrimSpace(fn.Doc.Text()); doc != "" {
+				docs[strings.TrimPrefix(fn.Name.Name, "Benchmark")] = doc
+			}
+		}
+	}
+	return docs, nil
+}
+
+// applyDocs sets the Doc of each result from the doc comment of its
+// benchmark function. Sub-benchmarks share the doc of their function.
+func applyDocs(results []models.BenchmarkResult, docs map[string]map[string]string) {
+	for i := range results {
+		name := docName(results[i].Name)
+		if results[i].Package != "" {
+			results[i].Doc = docs[results[i].Package][name]
+			continue
+		}
+		// Without a package, use the doc when only one package declares
+		// a benchmark of that name
+		var doc string
+		for _, pkgDocs := range docs {
+			if d, ok := pkgDocs[name]; ok {
+				if doc != "" {
+					doc = ""
+					break
+				}
+				doc = d
+			}
+		}
+		results[i].Doc = doc
+	}
+}
+
+// docName returns the name of the benchmark function a result belongs to:
+// the name without sub-benchmarks and the GOMAXPROCS suffix
+func docName(name string) string {
+	family, _ := models.SplitBenchmarkName(name)
+	return family
+}
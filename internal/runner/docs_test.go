@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestParseBenchmarkDocs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codec_test.go")
+	source := `package codec
+
+import "testing"
+
+// BenchmarkEncode measures encoding a 1 KiB message.
+//
+// It reports allocations.
+func BenchmarkEncode(b *testing.B) {}
+
+func BenchmarkDecode(b *testing.B) {}
+
+// TestEncode is not a benchmark
+func TestEncode(t *testing.T) {}
+
+type suite struct{}
+
+// BenchmarkMethod is a method, not a benchmark function
+func (suite) BenchmarkMethod(b *testing.B) {}
+`
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := parseBenchmarkDocs([]string{path})
+	if err != nil {
+		t.Fatalf("parseBenchmarkDocs failed: %v", err)
+	}
+	want := "BenchmarkEncode measures encoding a 1 KiB message.\n\nIt reports allocations."
+	if docs["Encode"] != want {
+		t.Errorf("docs[Encode] = %q, want %q", docs["Encode"], want)
+	}
+	if len(docs) != 1 {
+		t.Errorf("Expected only Encode to have a doc, got %v", docs)
+	}
+}
+
+func TestBenchmarkDocs(t *testing.T) {
+	docs, err := BenchmarkDocs("../../examples")
+	if err != nil {
+		t.Fatalf("BenchmarkDocs failed: %v", err)
+	}
+	pkgDocs := docs["github.com/alenon/gokanon/examples"]
+	if !strings.HasPrefix(pkgDocs["StringBuilder"], "BenchmarkStringBuilder benchmarks") {
+		t.Errorf("Expected the doc of BenchmarkStringBuilder, got %v", docs)
+	}
+}
+
+func TestApplyDocs(t *testing.T) {
+	docs := map[string]map[string]string{
+		"example.com/a": {"Encode": "Encode in a", "Shared": "Shared in a"},
+		"example.com/b": {"Shared": "Shared in b", "Only": "Only in b"},
+	}
+	results := []models.BenchmarkResult{
+		{Name: "Encode-8", Package: "example.com/a"},
+		{Name: "Encode/small-8", Package: "example.com/a"},
+		{Name: "Shared", Package: "example.com/b"},
+		{Name: "Missing", Package: "example.com/a"},
+		{Name: "Only"},
+		{Name: "Shared"},
+	}
+	applyDocs(results, docs)
+
+	want := []string{"Encode in a", "Encode in a", "Shared in b", "", "Only in b", ""}
+	for i, result := range results {
+		if result.Doc != want[i] {
+			t.Errorf("%s: Doc = %q, want %q", result.Name, result.Doc, want[i])
+		}
+	}
+}
+
+func TestDocName(t *testing.T) {
+	tests := map[string]string{
+		"Encode":             "Encode",
+		"Encode-8":           "Encode",
+		"Encode/size=1K-8":   "Encode",
+		"Parse-Fast":         "Parse-Fast",
+		"Parse-Fast-16":      "Parse-Fast",
+		"Table/sub/nested-4": "Table",
+	}
+	for name, want := range tests {
+		if got := docName(name); got != want {
+			t.Errorf("docName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// hostRoot is the directory /proc and /sys are read from on Linux, a
+// variable for tests
+var hostRoot = "/"
+
+// unlimitedMemory is the cgroup v1 memory limit from which a container is
+// considered unlimited: the kernel reports a page-aligned maximum instead
+const unlimitedMemory = 1 << 60
+
+// collectEnvironment fingerprints the machine benchmarks run on. Properties
+// that cannot be read on the platform are left empty.
+func collectEnvironment() *models.Environment {
+	env := &models.Environment{
+		CPUs:       runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		OS:         runtime.GOOS,
+	}
+	switch runtime.GOOS {
+	case "linux":
+		env.CPUModel = cpuinfoModel(readHostFile("proc/cpuinfo"))
+		env.Kernel = strings.TrimSpace(readHostFile("proc/sys/kernel/osrelease"))
+		env.LoadAverage = parseLoadAverage(readHostFile("proc/loadavg"))
+		env.Governor = strings.TrimSpace(readHostFile("sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"))
+		env.CPULimit, env.MemoryLimit = cgroupLimits()
+	case "darwin":
+		env.CPUModel = sysctl("machdep.cpu.brand_string")
+		env.Kernel = sysctl("kern.osrelease")
+		env.LoadAverage = parseLoadAverage(strings.Trim(sysctl("vm.loadavg"), "{ }"))
+	case "windows":
+		env.CPUModel = os.Getenv("PROCESSOR_IDENTIFIER")
+	}
+	return env
+}
+
+// readHostFile reads a file below hostRoot, returning "" when it cannot
+func readHostFile(name string) string {
+	data, err := os.ReadFile(filepath.Join(hostRoot, name))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// sysctl returns a kernel value on BSD-like systems, "" when unavailable
+func sysctl(name string) string {
+	output, err := exec.Command("sysctl", "-n", name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// cpuinfoModel returns the model name of the first CPU in /proc/cpuinfo
+func cpuinfoModel(cpuinfo string) string {
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(key) == "model name" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// parseLoadAverage returns the one-minute load average, the first field of
+// /proc/loadavg and of vm.loadavg
+func parseLoadAverage(loadavg string) float64 {
+	fields := strings.Fields(loadavg)
+	if len(fields) == 0 {
+		return 0
+	}
+	load, _ := strconv.ParseFloat(fields[0], 64)
+	return load
+}
+
+// cgroupLimits returns the CPUs and bytes of memory the container is
+// limited to, from cgroup v2 or else v1. Zero means unlimited.
+func cgroupLimits() (cpus float64, memory int64) {
+	if cpuMax := readHostFile("sys/fs/cgroup/cpu.max"); cpuMax != "" {
+		// "max 100000" or "<quota> <period>"
+		if fields := strings.Fields(cpuMax); len(fields) == 2 {
+			cpus = cpuQuota(fields[0], fields[1])
+		}
+		memory, _ = strconv.ParseInt(strings.TrimSpace(readHostFile("sys/fs/cgroup/memory.max")), 10, 64)
+		return cpus, memory
+	}
+
+	// A quota of -1 is unlimited
+	cpus = cpuQuota(strings.TrimSpace(readHostFile("sys/fs/cgroup/cpu/cpu.cfs_quota_us")),
+		strings.TrimSpace(readHostFile("sys/fs/cgroup/cpu/cpu.cfs_period_us")))
+	memory, _ = strconv.ParseInt(strings.TrimSpace(readHostFile("sys/fs/cgroup/memory/memory.limit_in_bytes")), 10, 64)
+	if memory >= unlimitedMemory {
+		memory = 0
+	}
+	return cpus, memory
+}
+
+// cpuQuota returns the CPUs a CFS quota and period amount to, 0 when the
+// quota is unlimited or unreadable
+func cpuQuota(quota, period string) float64 {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+	return q / p
+}
@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeHostFiles writes files below a temporary host root used by the
+// environment collection
+func writeHostFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := hostRoot
+	hostRoot = root
+	t.Cleanup(func() { hostRoot = old })
+}
+
+func TestCpuinfoModel(t *testing.T) {
+	cpuinfo := "processor\t: 0\nvendor_id\t: AuthenticAMD\nmodel name\t: AMD EPYC 7763 64-Core Processor\n\nprocessor\t: 1\nmodel name\t: AMD EPYC 7763 64-Core Processor\n"
+	if got := cpuinfoModel(cpuinfo); got != "AMD EPYC 7763 64-Core Processor" {
+		t.Errorf("cpuinfoModel() = %q", got)
+	}
+	if got := cpuinfoModel("processor\t: 0\nCPU part\t: 0xd0c\n"); got != "" {
+		t.Errorf("expected no model without a model name, got %q", got)
+	}
+}
+
+func TestParseLoadAverage(t *testing.T) {
+	if got := parseLoadAverage("1.25 0.80 0.50 2/345 6789\n"); got != 1.25 {
+		t.Errorf("parseLoadAverage() = %v, want 1.25", got)
+	}
+	if got := parseLoadAverage(""); got != 0 {
+		t.Errorf("parseLoadAverage(\"\") = %v, want 0", got)
+	}
+}
+
+func TestCgroupLimitsV2(t *testing.T) {
+	writeHostFiles(t, map[string]string{
+		"sys/fs/cgroup/cpu.max":    "150000 100000\n",
+		"sys/fs/cgroup/memory.max": "536870912\n",
+	})
+	cpus, memory := cgroupLimits()
+	if cpus != 1.5 || memory != 512<<20 {
+		t.Errorf("cgroupLimits() = %v, %v; want 1.5, 512 MiB", cpus, memory)
+	}
+
+	writeHostFiles(t, map[string]string{
+		"sys/fs/cgroup/cpu.max":    "max 100000\n",
+		"sys/fs/cgroup/memory.max": "max\n",
+	})
+	if cpus, memory := cgroupLimits(); cpus != 0 || memory != 0 {
+		t.Errorf("expected no limits, got %v, %v", cpus, memory)
+	}
+}
+
+func TestCgroupLimitsV1(t *testing.T) {
+	writeHostFiles(t, map[string]string{
+		"sys/fs/cgroup/cpu/cpu.cfs_quota_us":         "200000\n",
+		"sys/fs/cgroup/cpu/cpu.cfs_period_us":        "100000\n",
+		"sys/fs/cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+	})
+	cpus, memory := cgroupLimits()
+	if cpus != 2 || memory != 0 {
+		t.Errorf("cgroupLimits() = %v, %v; want 2 CPUs and no memory limit", cpus, memory)
+	}
+}
+
+func TestCollectEnvironment(t *testing.T) {
+	env := collectEnvironment()
+	if env.CPUs == 0 || env.GOMAXPROCS == 0 || env.OS == "" {
+		t.Errorf("expected CPUs, GOMAXPROCS and OS to be recorded, got %+v", env)
+	}
+}
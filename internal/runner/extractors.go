@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MetricExtractor pulls a named domain metric out of a line of benchmark output
+type MetricExtractor struct {
+	name    string
+	pattern *regexp.Regexp
+	path    []string
+}
+
+// NewRegexExtractor creates an extractor whose value is the first capture
+// group of pattern, e.g. `cache_hit_ratio=([0-9.]+)`
+func NewRegexExtractor(name, pattern string) (*MetricExtractor, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex for metric %s: %w", name, err)
+	}
+	if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("regex for metric %s must have a capture group", name)
+	}
+	return &MetricExtractor{name: name, pattern: re}, nil
+}
+
+// NewJSONPathExtractor creates an extractor that reads a numeric field from
+// JSON objects printed on a single line. The path is dot separated,
+// e.g. "latency.p99".
+func NewJSONPathExtractor(name, path string) (*MetricExtractor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty JSON path for metric %s", name)
+	}
+	return &MetricExtractor{name: name, path: strings.Split(path, ".")}, nil
+}
+
+// Name returns the metric name
+func (e *MetricExtractor) Name() string {
+	return e.name
+}
+
+// Extract returns the metric value if the line contains it
+func (e *MetricExtractor) Extract(line string) (float64, bool) {
+	if e.pattern != nil {
+		matches := e.pattern.FindStringSubmatch(line)
+		if matches == nil {
+			return 0, false
+		}
+		v, err := strconv.ParseFloat(matches[1], 64)
+		return v, err == nil
+	}
+
+	// Strip test log prefixes such as "    foo_test.go:12: "
+	start := strings.IndexByte(line, '{')
+	if start < 0 {
+		return 0, false
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(line[start:]), &obj); err != nil {
+		return 0, false
+	}
+
+	var cur interface{} = obj
+	for _, key := range e.path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		if cur, ok = m[key]; !ok {
+			return 0, false
+		}
+	}
+
+	v, ok := cur.(float64)
+	return v, ok
+}
@@ -0,0 +1,69 @@
+package runner
+
+import "testing"
+
+func TestRegexExtractor(t *testing.T) {
+	e, err := NewRegexExtractor("hit_ratio", `hit_ratio=([0-9.]+)`)
+	if err != nil {
+		t.Fatalf("NewRegexExtractor failed: %v", err)
+	}
+
+	tests := []struct {
+		line string
+		want float64
+		ok   bool
+	}{
+		{"hit_ratio=0.93", 0.93, true},
+		{"    cache_test.go:12: hit_ratio=0.5 misses=3", 0.5, true},
+		{"hit_ratio=", 0, false},
+		{"miss_ratio=0.1", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := e.Extract(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Extract(%q) = (%v, %v), want (%v, %v)", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRegexExtractorInvalid(t *testing.T) {
+	if _, err := NewRegexExtractor("m", `([`); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+	if _, err := NewRegexExtractor("m", `m=[0-9]+`); err == nil {
+		t.Error("Expected error for regex without capture group")
+	}
+}
+
+func TestJSONPathExtractor(t *testing.T) {
+	e, err := NewJSONPathExtractor("p99", "latency.p99")
+	if err != nil {
+		t.Fatalf("NewJSONPathExtractor failed: %v", err)
+	}
+
+	tests := []struct {
+		line string
+		want float64
+		ok   bool
+	}{
+		{`{"latency": {"p99": 4.5}}`, 4.5, true},
+		{`    bench_test.go:20: {"latency": {"p99": 12}}`, 12, true},
+		{`{"latency": {"p50": 1}}`, 0, false},
+		{`{"latency": {"p99": "fast"}}`, 0, false},
+		{`{"latency": 3}`, 0, false},
+		{`{not json`, 0, false},
+		{`no json here`, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := e.Extract(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Extract(%q) = (%v, %v), want (%v, %v)", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if _, err := NewJSONPathExtractor("p99", ""); err == nil {
+		t.Error("Expected error for empty path")
+	}
+}
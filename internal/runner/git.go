@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// getCommit returns the git commit checked out in the package directory
+// pkg, or an empty string outside a git repository
+func getCommit(pkg string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = packageDir(pkg)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// getCommitSubject returns the subject line of the git commit checked out
+// in the package directory pkg, or an empty string outside a git repository
+func getCommitSubject(pkg string) string {
+	cmd := exec.Command("git", "log", "-1", "--format=%s")
+	cmd.Dir = packageDir(pkg)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
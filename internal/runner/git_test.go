@@ -0,0 +1,12 @@
+package runner
+
+import "testing"
+
+func TestGetCommitOutsideRepository(t *testing.T) {
+	if commit := getCommit(t.TempDir()); commit != "" {
+		t.Errorf("Expected no commit outside a git repository, got %q", commit)
+	}
+	if subject := getCommitSubject(t.TempDir()); subject != "" {
+		t.Errorf("Expected no commit subject outside a git repository, got %q", subject)
+	}
+}
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Names of the hook functions a benchmark package may declare in its test
+// files, as func(b *testing.B). They run with the timer stopped before and
+// after each call of every benchmark function in the same test package, so
+// they can reset caches or report domain counters with b.ReportMetric.
+const (
+	SetupHook    = "GokanonSetup"
+	TeardownHook = "GokanonTeardown"
+)
+
+// hookedPrefix renames the original benchmark functions wrapped by hooks
+const hookedPrefix = "gokanonHooked"
+
+// prepareHooks generates the harness running the hooks of the packages
+// matching pkg: each test file with benchmarks in a package declaring hooks
+// is rewritten into tempDir, and an overlay for go test -overlay maps the
+// original files to the rewritten ones, leaving the sources untouched. It
+// returns the overlay path, or an empty path when no package has hooks.
+func prepareHooks(dir, pkg, tempDir string) (string, error) {
+	packages, err := listTestPackages(dir, pkg)
+	if err != nil {
+		return "", err
+	}
+
+	replace := make(map[string]string)
+	for _, p := range packages {
+		// The package and its external test package are hooked separately
+		for _, files := range [][]string{p.TestGoFiles, p.XTestGoFiles} {
+			rewritten, err := hookFiles(files)
+			if err != nil {
+				return "", err
+			}
+			for path, source := range rewritten {
+				generated := filepath.Join(tempDir, "hooks", strconv.Itoa(len(replace))+"_"+filepath.Base(path))
+				if err := os.MkdirAll(filepath.Dir(generated), 0755); err != nil {
+					return "", fmt.Errorf("failed to create hooks directory: %w", err)
+				}
+				if err := os.WriteFile(generated, source, 0644); err != nil {
+					return "", fmt.Errorf("failed to write hooked benchmarks: %w", err)
+				}
+				replace[path] = generated
+			}
+		}
+	}
+	if len(replace) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(map[string]map[string]string{"Replace": replace})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal overlay: %w", err)
+	}
+	overlay := filepath.Join(tempDir, "hooks", "overlay.json")
+	if err := os.WriteFile(overlay, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write overlay: %w", err)
+	}
+	return overlay, nil
+}
+
+// hookFiles rewrites the files of one test package so that every benchmark
+// function runs the package's hooks. It returns the rewritten source of
+// each file with benchmarks, or nothing when the package has no hooks.
+func hookFiles(paths []string) (map[string][]byte, error) {
+	fset := token.NewFileSet()
+	files := make(map[string]*ast.File)
+	var setup, teardown bool
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files[path] = file
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !isBenchmarkSignature(fn) {
+				continue
+			}
+			setup = setup || fn.Name.Name == SetupHook
+			teardown = teardown || fn.Name.Name == TeardownHook
+		}
+	}
+	if !setup && !teardown {
+		return nil, nil
+	}
+
+	rewritten := make(map[string][]byte)
+	for path, file := range files {
+		testing := testingImportName(file)
+		if testing == "" {
+			continue
+		}
+		var benchmarks []*ast.FuncDecl
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && isBenchmark(fn) {
+				benchmarks = append(benchmarks, fn)
+			}
+		}
+		if len(benchmarks) == 0 {
+			continue
+		}
+
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		rewritten[path] = wrapBenchmarks(fset, source, benchmarks, testing, setup, teardown)
+	}
+	return rewritten, nil
+}
+
+// wrapBenchmarks renames the benchmark functions in source and appends a
+// wrapper under each original name that calls the hooks around it. Only
+// names change in the original code, so line numbers in failures and
+// profiles still match the sources.
+func wrapBenchmarks(fset *token.FileSet, source []byte, benchmarks []*ast.FuncDecl, testing string, setup, teardown bool) []byte {
+	// Rename from the end so that earlier offsets stay valid
+	sort.Slice(benchmarks, func(i, j int) bool { return benchmarks[i].Name.Pos() > benchmarks[j].Name.Pos() })
+	var out []byte
+	out = append(out, source...)
+	for _, fn := range benchmarks {
+		offset := fset.Position(fn.Name.Pos()).Offset
+		out = append(out[:offset:offset], append([]byte(hookedPrefix), out[offset:]...)...)
+	}
+
+	var wrappers bytes.Buffer
+	for i := len(benchmarks) - 1; i >= 0; i-- {
+		name := benchmarks[i].Name.Name
+		fmt.Fprintf(&wrappers, "\n// %s runs the gokanon benchmark hooks around each call\n", name)
+		fmt.Fprintf(&wrappers, "func %s(b *%s.B) {\n\tb.StopTimer()\n", name, testing)
+		if setup {
+			fmt.Fprintf(&wrappers, "\t%s(b)\n", SetupHook)
+		}
+		fmt.Fprintf(&wrappers, "\tb.StartTimer()\n\t%s%s(b)\n\tb.StopTimer()\n", hookedPrefix, name)
+		if teardown {
+			fmt.Fprintf(&wrappers, "\t%s(b)\n", TeardownHook)
+		}
+		fmt.Fprintf(&wrappers, "\tb.StartTimer()\n}\n")
+	}
+	return append(out, wrappers.Bytes()...)
+}
+
+// isBenchmark reports whether fn is a benchmark function go test runs
+func isBenchmark(fn *ast.FuncDecl) bool {
+	name, ok := strings.CutPrefix(fn.Name.Name, "Benchmark")
+	if !ok || fn.Recv != nil || fn.Body == nil {
+		return false
+	}
+	// Like go test, ignore names continuing in lower case, e.g. Benchmarks
+	if name != "" && name[0] >= 'a' && name[0] <= 'z' {
+		return false
+	}
+	return isBenchmarkSignature(fn)
+}
+
+// isBenchmarkSignature reports whether fn takes a single *testing.B
+func isBenchmarkSignature(fn *ast.FuncDecl) bool {
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 || fn.Type.Results != nil {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "B"
+}
+
+// testingImportName returns the name the testing package is imported as in
+// file, or an empty string when it is not imported by name
+func testingImportName(file *ast.File) string {
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path != "testing" {
+			continue
+		}
+		if spec.Name == nil {
+			return "testing"
+		}
+		if spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name
+		}
+	}
+	return ""
+}
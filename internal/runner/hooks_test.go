@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookFiles(t *testing.T) {
+	dir := t.TempDir()
+	hooks := filepath.Join(dir, "hooks_test.go")
+	benchmarks := filepath.Join(dir, "codec_test.go")
+	os.WriteFile(hooks, []byte(`package codec
+
+import "testing"
+
+func GokanonSetup(b *testing.B) {}
+`), 0644)
+	os.WriteFile(benchmarks, []byte(`package codec
+
+import tst "testing"
+
+func BenchmarkEncode(b *tst.B) {
+	b.ReportAllocs()
+}
+
+func Benchmarks(b *tst.B) {}
+
+func helper(b *tst.B) {}
+`), 0644)
+
+	rewritten, err := hookFiles([]string{hooks, benchmarks})
+	if err != nil {
+		t.Fatalf("hookFiles failed: %v", err)
+	}
+	if len(rewritten) != 1 {
+		t.Fatalf("Expected only the file with benchmarks to be rewritten, got %d files", len(rewritten))
+	}
+
+	source := string(rewritten[benchmarks])
+	if _, err := parser.ParseFile(token.NewFileSet(), "", source, 0); err != nil {
+		t.Fatalf("Rewritten source does not parse: %v\n%s", err, source)
+	}
+	for _, want := range []string{
+		"func gokanonHookedBenchmarkEncode(b *tst.B) {\n\tb.ReportAllocs()",
+		"func BenchmarkEncode(b *tst.B) {\n\tb.StopTimer()\n\tGokanonSetup(b)\n\tb.StartTimer()\n\tgokanonHookedBenchmarkEncode(b)",
+		"func Benchmarks(b *tst.B) {}",
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("Expected rewritten source to contain %q, got:\n%s", want, source)
+		}
+	}
+	if strings.Contains(source, TeardownHook) {
+		t.Errorf("Expected no call to the undeclared teardown hook:\n%s", source)
+	}
+	// The original code keeps its line numbers
+	if !strings.HasPrefix(source, "package codec\n\nimport tst \"testing\"\n\nfunc gokanonHookedBenchmarkEncode") {
+		t.Errorf("Expected the benchmark renamed in place, got:\n%s", source)
+	}
+}
+
+func TestHookFilesWithoutHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codec_test.go")
+	os.WriteFile(path, []byte("package codec\n\nimport \"testing\"\n\nfunc BenchmarkEncode(b *testing.B) {}\n"), 0644)
+
+	rewritten, err := hookFiles([]string{path})
+	if err != nil || len(rewritten) != 0 {
+		t.Errorf("Expected nothing rewritten without hooks, got %v (%v)", rewritten, err)
+	}
+}
+
+func TestRunWithHooks(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module hooked\n\ngo 1.21\n"), 0644)
+	source := `package hooked
+
+import "testing"
+
+var calls int
+
+func GokanonSetup(b *testing.B) { calls = 0 }
+
+func GokanonTeardown(b *testing.B) { b.ReportMetric(float64(calls), "calls") }
+
+func BenchmarkCount(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		calls++
+	}
+}
+`
+	path := filepath.Join(dir, "hooked_test.go")
+	os.WriteFile(path, []byte(source), 0644)
+
+	run, err := NewRunner(".", ".").WithDir(dir).WithBenchtime("50x").Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(run.Results) != 1 || run.Results[0].Metrics["calls"] != 50 {
+		t.Errorf("Expected the teardown hook to report 50 calls, got %+v", run.Results)
+	}
+	if strings.Contains(run.Command, "-overlay") {
+		t.Errorf("Expected the generated harness to stay out of the command, got %s", run.Command)
+	}
+	// The sources are left untouched
+	if data, _ := os.ReadFile(path); string(data) != source {
+		t.Errorf("Expected the benchmark source to be unchanged")
+	}
+
+	run, err = NewRunner(".", ".").WithDir(dir).WithBenchtime("50x").WithoutHooks().Run()
+	if err != nil {
+		t.Fatalf("Run without hooks failed: %v", err)
+	}
+	if _, ok := run.Results[0].Metrics["calls"]; ok {
+		t.Errorf("Expected no hooks to run, got %+v", run.Results[0])
+	}
+}
@@ -0,0 +1,220 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Import parses benchmark results that were produced outside gokanon, such
+// as saved go test -bench output or the text files benchstat reads, and
+// returns them as a run. Configuration lines like "goos: linux" record the
+// platform; repetitions of a benchmark, e.g. from -count, are merged into
+// one result holding all samples. Metric extractors apply as in Run.
+func Import(reader io.Reader, extractors []*MetricExtractor) (*models.BenchmarkRun, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark output: %w", err)
+	}
+
+	r := NewRunner("", "").WithMetricExtractors(extractors)
+	results, err := r.parseOutput(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Configuration lines are "key: value" at the top level. Only the
+	// platform maps onto a run; pkg lines are already applied per result.
+	config := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if key, value, ok := parseConfigLine(scanner.Text()); ok {
+			config[key] = value
+		}
+	}
+
+	return importedRun(mergeSamples(results), config), nil
+}
+
+// importedRun returns a run of imported results, with the platform of the
+// configuration lines they were read with
+func importedRun(results []models.BenchmarkResult, config map[string]string) *models.BenchmarkRun {
+	run := &models.BenchmarkRun{
+		ID:        storage.NewRunID(time.Now()),
+		Timestamp: time.Now(),
+		Results:   results,
+	}
+	if config["goos"] != "" || config["goarch"] != "" {
+		run.Toolchain = &models.Toolchain{GOOS: config["goos"], GOARCH: config["goarch"]}
+	}
+
+	// Like a run of a single package, name the package when all results share it
+	for i, result := range run.Results {
+		if i == 0 {
+			run.Package = result.Package
+		} else if result.Package != run.Package {
+			run.Package = ""
+			break
+		}
+	}
+	return run
+}
+
+// Import formats
+const (
+	FormatGo           = "go"            // go test -bench output, or any file in the Go benchmark data format
+	FormatPerf         = "perf"          // Query results of a golang.org/x/perf/storage server, such as perfdata.golang.org
+	FormatBenchstatCSV = "benchstat-csv" // Tables printed by benchstat -format=csv, or by the older benchstat -csv
+)
+
+// ImportFormats lists the import formats
+var ImportFormats = []string{FormatGo, FormatPerf, FormatBenchstatCSV}
+
+// DetectFormat guesses the format of data to import. Data of a perf
+// storage server carries "upload" labels; benchstat tables are lines of
+// comma-separated values without any benchmark result line.
+func DetectFormat(data []byte) string {
+	csv := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if key, _, ok := parseConfigLine(line); ok {
+			if key == "upload" || key == "upload-time" {
+				return FormatPerf
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "Benchmark") {
+			return FormatGo
+		}
+		if strings.HasPrefix(line, ",") || strings.HasPrefix(line, "name,") {
+			csv = true
+		}
+	}
+	if csv {
+		return FormatBenchstatCSV
+	}
+	return FormatGo
+}
+
+// Labels of a perf storage server with a meaning of their own. Labels
+// naming the file a result was uploaded in differ within one upload.
+var perfLabels = map[string]bool{
+	"goos": true, "goarch": true, "pkg": true,
+	"upload-time": true, "upload-part": true, "upload-file": true,
+	"commit": true, "commit-time": true, "run-time": true,
+}
+
+// ImportPerf parses the results of a golang.org/x/perf/storage server,
+// returning a run for each upload in order of time. An upload's labels are
+// the configuration lines in effect at its first result: it ran at its
+// "run-time", as set by 'gokanon push', else at its "upload-time" or its
+// "commit-time", and at its "commit"; the other labels, such as "upload"
+// and "by", become tags of the run.
+func ImportPerf(reader io.Reader, extractors []*MetricExtractor) ([]*models.BenchmarkRun, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read perf data: %w", err)
+	}
+
+	// The results of an upload may be spread over the data, and their
+	// labels change with every configuration line
+	type upload struct {
+		labels map[string]string
+		pkg    string
+		text   strings.Builder
+	}
+	var order []string
+	uploads := make(map[string]*upload)
+	labels := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if key, value, ok := parseConfigLine(line); ok {
+			if value == "" {
+				delete(labels, key)
+			} else {
+				labels[key] = value
+			}
+			continue
+		}
+		if !strings.HasPrefix(line, "Benchmark") {
+			continue
+		}
+		id := labels["upload"]
+		u, ok := uploads[id]
+		if !ok {
+			u = &upload{labels: maps.Clone(labels)}
+			uploads[id] = u
+			order = append(order, id)
+		}
+		if labels["pkg"] != u.pkg {
+			u.pkg = labels["pkg"]
+			fmt.Fprintf(&u.text, "pkg: %s\n", u.pkg)
+		}
+		u.text.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read perf data: %w", err)
+	}
+	if len(order) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	r := NewRunner("", "").WithMetricExtractors(extractors)
+	runs := make([]*models.BenchmarkRun, 0, len(order))
+	for _, id := range order {
+		u := uploads[id]
+		results, err := r.parseOutput(u.text.String())
+		if err != nil {
+			return nil, fmt.Errorf("upload %s: %w", id, err)
+		}
+		run := importedRun(mergeSamples(results), u.labels)
+		for _, key := range []string{"run-time", "upload-time", "commit-time"} {
+			if value := u.labels[key]; value != "" {
+				ts, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					return nil, fmt.Errorf("upload %s: invalid %s %q: %w", id, key, value, err)
+				}
+				run.Timestamp = ts
+				break
+			}
+		}
+		run.Commit = u.labels["commit"]
+		for key, value := range u.labels {
+			if !perfLabels[key] {
+				if run.Tags == nil {
+					run.Tags = make(map[string]string)
+				}
+				run.Tags[key] = value
+			}
+		}
+		runs = append(runs, run)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Timestamp.Before(runs[j].Timestamp) })
+	return runs, nil
+}
+
+// parseConfigLine parses a configuration line of the Go benchmark data
+// format: a lowercase key without spaces, a colon and a value
+func parseConfigLine(line string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(line, ":")
+	if !ok || key == "" || key[0] < 'a' || key[0] > 'z' || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	if value != "" && !isSpace(value[0]) {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
@@ -0,0 +1,154 @@
+package runner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImport(t *testing.T) {
+	output := `goos: linux
+goarch: arm64
+pkg: example.com/codec
+cpu: Neoverse-N1
+note: Key with a value
+BenchmarkEncode-8   	  100000	      1200 ns/op	     512 B/op	       4 allocs/op
+BenchmarkEncode-8   	  100000	      1300 ns/op	     512 B/op	       4 allocs/op
+BenchmarkDecode-8   	   50000	      2500 ns/op	    42.5 items/op
+PASS
+ok  	example.com/codec	3.012s
+`
+
+	run, err := Import(strings.NewReader(output), nil)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if run.ID == "" || run.Timestamp.IsZero() {
+		t.Errorf("Expected an ID and timestamp, got %q and %v", run.ID, run.Timestamp)
+	}
+	if run.Package != "example.com/codec" {
+		t.Errorf("Expected package from the pkg line, got %q", run.Package)
+	}
+	if run.Toolchain == nil || run.Toolchain.GOOS != "linux" || run.Toolchain.GOARCH != "arm64" {
+		t.Errorf("Expected platform from the configuration lines, got %+v", run.Toolchain)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("Expected repetitions merged into 2 results, got %d", len(run.Results))
+	}
+
+	encode := run.Results[0]
+	if encode.Name != "Encode-8" || encode.NsPerOp != 1250 || !reflect.DeepEqual(encode.Samples, []float64{1200, 1300}) {
+		t.Errorf("Expected Encode-8 with samples [1200 1300], got %+v", encode)
+	}
+	if decode := run.Results[1]; decode.Metrics["items/op"] != 42.5 || decode.Samples != nil {
+		t.Errorf("Expected Decode-8 with its custom metric and no samples, got %+v", decode)
+	}
+}
+
+func TestImportNoResults(t *testing.T) {
+	if _, err := Import(strings.NewReader("PASS\nok  \texample.com/codec\t0.01s\n"), nil); err == nil {
+		t.Error("Expected an error for output without benchmark results")
+	}
+}
+
+func TestParseConfigLine(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantKey   string
+		wantValue string
+		wantOK    bool
+	}{
+		{"goos: linux", "goos", "linux", true},
+		{"cpu: Intel(R) Xeon(R) CPU @ 2.20GHz", "cpu", "Intel(R) Xeon(R) CPU @ 2.20GHz", true},
+		{"commit:", "commit", "", true},
+		{"BenchmarkA-8 100 5 ns/op", "", "", false},
+		{"Goos: linux", "", "", false},
+		{"two words: value", "", "", false},
+		{"    a_test.go:12: log", "", "", false},
+		{"url:http://example.com", "", "", false},
+	}
+
+	for _, tt := range tests {
+		key, value, ok := parseConfigLine(tt.line)
+		if key != tt.wantKey || value != tt.wantValue || ok != tt.wantOK {
+			t.Errorf("parseConfigLine(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.line, key, value, ok, tt.wantKey, tt.wantValue, tt.wantOK)
+		}
+	}
+}
+
+// perfData is the result of a perf storage query spanning two uploads
+const perfData = `upload: 20240501.3
+upload-part: 20240501.3/0
+upload-file: new.txt
+upload-time: 2024-05-01T15:04:05Z
+by: gopher@example.com
+commit: 6b0a4f1
+goos: linux
+goarch: amd64
+pkg: example.com/codec
+BenchmarkEncode-8   	  100000	      1100 ns/op
+BenchmarkEncode-8   	  100000	      1200 ns/op
+upload: 20240401.1
+upload-part: 20240401.1/0
+upload-file: old.txt
+upload-time: 2024-04-01T09:00:00Z
+commit: 2e91c03
+BenchmarkEncode-8   	  100000	      1500 ns/op
+pkg: example.com/codec/json
+BenchmarkDecode-8   	   50000	      2500 ns/op
+`
+
+func TestImportPerf(t *testing.T) {
+	runs, err := ImportPerf(strings.NewReader(perfData), nil)
+	if err != nil {
+		t.Fatalf("ImportPerf failed: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected a run per upload, got %d", len(runs))
+	}
+
+	// Runs come in order of time
+	old, latest := runs[0], runs[1]
+	if !old.Timestamp.Equal(time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)) || old.Commit != "2e91c03" {
+		t.Errorf("Expected the first upload's time and commit, got %v and %q", old.Timestamp, old.Commit)
+	}
+	if len(old.Results) != 2 || old.Results[1].Package != "example.com/codec/json" || old.Package != "" {
+		t.Errorf("Expected results of two packages, got %+v", old.Results)
+	}
+	// Labels carry over from one upload to the next until changed
+	if old.Tags["upload"] != "20240401.1" || old.Tags["by"] != "gopher@example.com" || old.Tags["upload-file"] != "" {
+		t.Errorf("Expected the upload labels as tags, got %v", old.Tags)
+	}
+
+	if latest.Package != "example.com/codec" || latest.Toolchain == nil || latest.Toolchain.GOARCH != "amd64" {
+		t.Errorf("Expected package and platform of the upload, got %q and %+v", latest.Package, latest.Toolchain)
+	}
+	if len(latest.Results) != 1 || !reflect.DeepEqual(latest.Results[0].Samples, []float64{1100, 1200}) {
+		t.Errorf("Expected Encode-8 with both samples, got %+v", latest.Results)
+	}
+
+	if _, err := ImportPerf(strings.NewReader("upload-time: yesterday\nBenchmarkA-8 1 5 ns/op\n"), nil); err == nil {
+		t.Error("Expected an error for an invalid upload time")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"goos: linux\nBenchmarkA-8 100 5 ns/op\n", FormatGo},
+		{perfData, FormatPerf},
+		{"goos: linux\n,old.txt,,new.txt\n,sec/op,CI,sec/op,CI,vs base,P\nA-8,1e-06,1%,2e-06,2%,+100%,p=0.000 n=10\n", FormatBenchstatCSV},
+		{"name,time/op (ns/op),±\nA-8,1.00E+03,1%\n", FormatBenchstatCSV},
+		{"", FormatGo},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat([]byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"os"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// liveTracker publishes the progress of a run to storage for the
+// dashboard's live view. The live view is informational, so write failures
+// are ignored rather than interrupting the run.
+type liveTracker struct {
+	store *storage.Storage
+	live  *models.LiveRun
+}
+
+// newLiveTracker starts tracking a run of pkg. The number of results of the
+// previous run of the same package is used to estimate progress.
+func newLiveTracker(store *storage.Storage, pkg string) *liveTracker {
+	t := &liveTracker{
+		store: store,
+		live: &models.LiveRun{
+			PID:       os.Getpid(),
+			Package:   pkg,
+			StartedAt: time.Now(),
+			Completed: []models.BenchmarkResult{},
+		},
+	}
+	if entries, err := store.Index(); err == nil {
+		for _, entry := range entries {
+			if entry.Package == pkg {
+				t.live.Expected = len(entry.Benchmarks)
+				break
+			}
+		}
+	}
+	t.save()
+	return t
+}
+
+// started records the benchmark that is currently executing
+func (t *liveTracker) started(name string) {
+	t.live.Current = name
+	t.live.CurrentStartedAt = time.Now()
+	t.save()
+}
+
+// completed records a finished benchmark
+func (t *liveTracker) completed(result models.BenchmarkResult) {
+	t.live.Completed = append(t.live.Completed, result)
+	t.live.Current = ""
+	t.save()
+}
+
+// save writes the current status
+func (t *liveTracker) save() {
+	t.live.UpdatedAt = time.Now()
+	_ = t.store.SaveLiveRun(t.live)
+}
+
+// finish removes the status once the run has ended
+func (t *liveTracker) finish() {
+	_ = t.store.ClearLiveRun()
+}
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alenon/gokanon/internal/loadtest"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// WithLoadTest sends HTTP load to a service instead of running benchmarks.
+// The result is named "Load/<name>", by default after the host and path of
+// the URL.
+func (r *Runner) WithLoadTest(name string, opts loadtest.Options) *Runner {
+	if name == "" {
+		name = loadtest.Name(opts.URL)
+	}
+	r.load = &loadTest{name: name, opts: opts}
+	return r
+}
+
+// loadTest is the load test of a runner
+type loadTest struct {
+	name string
+	opts loadtest.Options
+}
+
+// runLoadTest sends the load and records what it measured as a run with
+// a single result
+func (r *Runner) runLoadTest() (*models.BenchmarkRun, error) {
+	startTime := time.Now()
+	opts := r.load.opts
+
+	environment := collectEnvironment()
+	goVersion, err := r.getGoVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Go version: %w", err)
+	}
+	runID := r.newID()
+
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	name := "Load/" + r.load.name
+	if r.liveStore != nil {
+		r.live = newLiveTracker(r.liveStore, opts.URL)
+		defer func() {
+			r.live.finish()
+			r.live = nil
+		}()
+		r.live.started(name)
+	}
+	if r.startCallback != nil {
+		r.startCallback(name)
+	}
+
+	report, err := loadtest.Run(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("benchmark run interrupted: %w", ctx.Err())
+	}
+
+	result := report.Result(r.load.name)
+	if r.live != nil {
+		r.live.completed(result)
+	}
+	if r.progressCallback != nil {
+		r.progressCallback(result)
+	}
+	if r.verboseWriter != nil {
+		fmt.Fprintf(r.verboseWriter, "%s: %d requests in %s, %d failed, status codes %v\n",
+			name, report.Requests, report.Duration.Round(time.Millisecond), report.Errors, report.StatusCodes)
+	}
+
+	method := opts.Method
+	if method == "" {
+		method = "GET"
+	}
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = loadtest.DefaultConcurrency
+	}
+	run := &models.BenchmarkRun{
+		ID:            runID,
+		Timestamp:     startTime,
+		GoVersion:     goVersion,
+		Results:       []models.BenchmarkResult{result},
+		Command:       fmt.Sprintf("%s %s", method, opts.URL),
+		Duration:      time.Since(startTime),
+		Commit:        getCommit(r.localPackagePath()),
+		CommitMessage: getCommitSubject(r.localPackagePath()),
+		Environment:   environment,
+		Load: &models.LoadTest{
+			URL:         opts.URL,
+			Method:      method,
+			Duration:    report.Duration,
+			Rate:        opts.Rate,
+			Concurrency: concurrency,
+			Requests:    report.Requests,
+			Errors:      report.Errors,
+			StatusCodes: report.StatusCodes,
+		},
+	}
+	// Without a single response the service was down rather than slow
+	if result.Status == models.StatusFailed {
+		run.Status = models.StatusFailed
+		run.Error = fmt.Sprintf("load test of %s failed: %s", opts.URL, result.Message)
+	}
+	return run, nil
+}
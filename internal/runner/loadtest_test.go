@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/loadtest"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestRunLoadTest(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	opts := loadtest.Options{URL: server.URL + "/healthz", Duration: 200 * time.Millisecond, Concurrency: 2}
+	run, err := NewRunner("", "").WithLoadTest("", opts).Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(run.Results) != 1 || run.Results[0].Name != "Load/"+loadtest.Name(opts.URL) {
+		t.Fatalf("Results = %+v", run.Results)
+	}
+	if run.Failed() || run.Load == nil || run.Load.Method != "GET" || run.Load.Concurrency != 2 || run.Load.Requests == 0 {
+		t.Errorf("Run status %q, load %+v", run.Status, run.Load)
+	}
+
+	// A service answering no request successfully fails the run
+	healthy.Store(false)
+	run, err = NewRunner("", "").WithLoadTest("health", opts).Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !run.Failed() || run.Results[0].Name != "Load/health" || run.Results[0].Status != models.StatusFailed {
+		t.Errorf("Run status %q, result %+v", run.Status, run.Results[0])
+	}
+}
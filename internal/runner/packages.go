@@ -0,0 +1,233 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/google/pprof/profile"
+)
+
+// expandPackages returns the packages with tests matching a package
+// pattern such as ./..., each benchmarked by its own go test. It returns
+// nil for a single package, or when the packages cannot be listed, and go
+// test is then given the pattern itself.
+func (r *Runner) expandPackages() []string {
+	if r.packagePath != "" && !strings.Contains(r.packagePath, "...") {
+		return nil
+	}
+	listed, err := listTestPackages(r.dir, r.packagePath)
+	if err != nil {
+		return nil
+	}
+	var packages []string
+	for _, p := range listed {
+		if len(p.TestGoFiles) > 0 || len(p.XTestGoFiles) > 0 {
+			packages = append(packages, p.ImportPath)
+		}
+	}
+	return packages
+}
+
+// runPackages benchmarks each package with its own go test, up to
+// packageWorkers at once, and merges their results in package order.
+// Packages without benchmarks are left out. The profiles of the packages
+// are merged into the files of paths.
+func (r *Runner) runPackages(ctx context.Context, packages []string, tempDir string, paths *profilePaths) (*packageRun, []models.PackageRun, error) {
+	workers := r.packageWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(packages) {
+		workers = len(packages)
+	}
+
+	type job struct {
+		outcome *packageRun
+		paths   profilePaths
+		verbose bytes.Buffer
+		err     error
+	}
+	jobs := make([]job, len(packages))
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				j := &jobs[i]
+				dir := filepath.Join(tempDir, fmt.Sprintf("pkg-%d", i))
+				if j.err = os.MkdirAll(dir, 0o755); j.err != nil {
+					continue
+				}
+				j.paths = r.profilePaths(dir)
+				verbose := r.verboseWriter
+				if verbose != nil && workers > 1 {
+					// Like go test, show the output of packages
+					// benchmarked at once package by package
+					verbose = &j.verbose
+				}
+				j.outcome, j.err = r.runPackage(ctx, packages[i], dir, &j.paths, verbose)
+				if verbose == &j.verbose {
+					r.mu.Lock()
+					r.verboseWriter.Write(j.verbose.Bytes())
+					r.mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range packages {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, nil, fmt.Errorf("benchmark run interrupted: %w", ctx.Err())
+	}
+
+	merged := &packageRun{}
+	var packageRuns []models.PackageRun
+	var stderr strings.Builder
+	var packagePaths []profilePaths
+	for i, j := range jobs {
+		if j.err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", packages[i], j.err)
+		}
+		outcome := j.outcome
+		if len(outcome.results) == 0 && outcome.abnormal == nil {
+			continue
+		}
+
+		packageRun := models.PackageRun{
+			Package:    packages[i],
+			Benchmarks: len(outcome.results),
+			Duration:   outcome.duration,
+			Status:     models.StatusOK,
+		}
+		if outcome.abnormal != nil {
+			packageRun.Status = models.StatusFailed
+			packageRun.Error = firstError(outcome.stderr)
+			if packageRun.Error == "" {
+				packageRun.Error = fmt.Sprintf("go test terminated abnormally: %v", outcome.abnormal)
+			}
+			if merged.abnormal == nil {
+				merged.abnormal = fmt.Errorf("%s: %w", packages[i], outcome.abnormal)
+			}
+		}
+		packageRuns = append(packageRuns, packageRun)
+
+		if outcome.stderr != "" {
+			fmt.Fprintf(&stderr, "# %s\n%s", packages[i], outcome.stderr)
+		}
+		merged.results = append(merged.results, outcome.results...)
+		merged.duration += outcome.duration
+		packagePaths = append(packagePaths, j.paths)
+	}
+	merged.stderr = stderr.String()
+
+	if len(merged.results) == 0 {
+		if merged.abnormal != nil {
+			return nil, nil, fmt.Errorf("benchmark execution failed: %w\nStderr: %s", merged.abnormal, merged.stderr)
+		}
+		return nil, nil, fmt.Errorf("failed to parse benchmark output: %w", ErrNoBenchmarks)
+	}
+
+	if paths.any() {
+		if paths.mem != "" {
+			paths.warmup = filepath.Join(tempDir, "mem-warmup.prof")
+		}
+		mergePackageProfiles(paths, packagePaths)
+	}
+	return merged, packageRuns, nil
+}
+
+// mergePackageProfiles merges each kind of profile of the packages into
+// the file of paths, so that a run has one profile per kind. Kinds no
+// package wrote a profile of are cleared from paths.
+func mergePackageProfiles(paths *profilePaths, packagePaths []profilePaths) {
+	kinds := []struct {
+		target *string
+		source func(profilePaths) string
+	}{
+		{&paths.cpu, func(p profilePaths) string { return p.cpu }},
+		{&paths.mem, func(p profilePaths) string { return p.mem }},
+		{&paths.warmup, func(p profilePaths) string { return p.warmup }},
+		{&paths.block, func(p profilePaths) string { return p.block }},
+		{&paths.mutex, func(p profilePaths) string { return p.mutex }},
+	}
+	for _, kind := range kinds {
+		var sources []string
+		for _, p := range packagePaths {
+			if source := kind.source(p); source != "" && fileExists(source) {
+				sources = append(sources, source)
+			}
+		}
+		if *kind.target == "" {
+			continue
+		}
+		if len(sources) == 0 {
+			*kind.target = ""
+			continue
+		}
+		if err := mergeProfiles(*kind.target, sources); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to merge %s: %v\n", filepath.Base(*kind.target), err)
+			*kind.target = ""
+		}
+	}
+}
+
+// mergeProfiles merges the pprof profiles at sources and writes the
+// result to target
+func mergeProfiles(target string, sources []string) error {
+	var profiles []*profile.Profile
+	for _, source := range sources {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return err
+		}
+		p, err := profile.ParseData(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	merged, err := profile.Merge(profiles)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return merged.Write(file)
+}
+
+// fileExists reports whether a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// firstError returns the first line of the standard error of go test
+// that is not a "# package" header, such as the first build error
+func firstError(stderr string) string {
+	for _, line := range strings.Split(stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
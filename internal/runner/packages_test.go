@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// writeModule writes the files of a module to a temporary directory
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func benchmarkFile(pkg, name string) string {
+	return "package " + pkg + "\n\nimport \"testing\"\n\nfunc Benchmark" + name + "(b *testing.B) {\n\tfor i := 0; i < b.N; i++ {\n\t}\n}\n"
+}
+
+func TestRunPackages(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":      "module example.com/multi\n\ngo 1.21\n",
+		"a/a_test.go": benchmarkFile("a", "Alpha"),
+		"b/b_test.go": benchmarkFile("b", "Beta"),
+		"c/c_test.go": "package c\n\nimport \"testing\"\n\nfunc TestNothing(t *testing.T) {}\n",
+		"d/d.go":      "package d\n",
+	})
+
+	var mu sync.Mutex
+	var progress []string
+	r := NewRunner("./...", ".").WithDir(dir).WithBenchtime("1x").WithoutTests().WithPackageWorkers(2).
+		WithProgress(func(result models.BenchmarkResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, result.Name)
+		})
+	run, err := r.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Results come in package order whichever package finished first
+	if len(run.Results) != 2 || run.Results[0].Name != "Alpha" || run.Results[1].Name != "Beta" {
+		t.Fatalf("Results = %+v, want Alpha then Beta", run.Results)
+	}
+	if run.Results[0].Package != "example.com/multi/a" || run.Results[1].Package != "example.com/multi/b" {
+		t.Errorf("Result packages = %q, %q", run.Results[0].Package, run.Results[1].Package)
+	}
+	if len(progress) != 2 {
+		t.Errorf("Progress reported %v, want both benchmarks", progress)
+	}
+
+	// Packages without benchmarks are left out
+	if len(run.Packages) != 2 || run.Packages[0].Package != "example.com/multi/a" || run.Packages[1].Package != "example.com/multi/b" {
+		t.Fatalf("Packages = %+v", run.Packages)
+	}
+	for _, p := range run.Packages {
+		if p.Failed() || p.Benchmarks != 1 || p.Duration <= 0 {
+			t.Errorf("Package %+v", p)
+		}
+	}
+	if run.Failed() {
+		t.Errorf("Run failed: %s", run.Error)
+	}
+}
+
+func TestRunPackagesBuildFailure(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":      "module example.com/broken\n\ngo 1.21\n",
+		"a/a_test.go": benchmarkFile("a", "Alpha"),
+		"b/b_test.go": "package b\n\nvar broken int = \"text\"\n",
+	})
+
+	run, err := NewRunner("./...", ".").WithDir(dir).WithBenchtime("1x").WithoutTests().Run()
+	if err != nil {
+		t.Fatalf("Expected the results of the other packages to be kept, got %v", err)
+	}
+	if len(run.Results) != 1 || run.Results[0].Name != "Alpha" {
+		t.Errorf("Results = %+v", run.Results)
+	}
+	if len(run.Packages) != 2 || !run.Packages[1].Failed() || run.Packages[1].Error == "" {
+		t.Fatalf("Packages = %+v, want b failed", run.Packages)
+	}
+	if !run.Failed() || !strings.Contains(run.Stderr, "# example.com/broken/b") {
+		t.Errorf("Expected the run to be failed with the errors of b, got %q: %q", run.Error, run.Stderr)
+	}
+}
+
+func TestExpandPackages(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":      "module example.com/expand\n\ngo 1.21\n",
+		"a/a_test.go": benchmarkFile("a", "Alpha"),
+		"b/b.go":      "package b\n",
+	})
+
+	if got := NewRunner("./...", ".").WithDir(dir).expandPackages(); len(got) != 1 || got[0] != "example.com/expand/a" {
+		t.Errorf("expandPackages(./...) = %v", got)
+	}
+	// A single package is given to go test as is
+	if got := NewRunner("./a", ".").WithDir(dir).expandPackages(); got != nil {
+		t.Errorf("expandPackages(./a) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,459 @@
+package runner
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// lineBufferSize is the number of lines that may be queued between the
+// pipeline stages before the reader blocks
+const lineBufferSize = 4096
+
+// parseOutputRealtime parses the benchmark output in real-time from a reader.
+//
+// Parsing runs as a three stage pipeline: a reader drains the output into a
+// buffered channel so the benchmark process never stalls on a full pipe, a
+// worker matches benchmark lines, and the caller's goroutine collects results.
+// Each stage consumes its input in order, so progress callbacks fire in the
+// same order the benchmarks were printed.
+func (r *Runner) parseOutputRealtime(reader io.Reader) ([]models.BenchmarkResult, error) {
+	return r.parseOutputTo(reader, r.verboseWriter)
+}
+
+// ErrNoBenchmarks is returned when go test, or the output imported,
+// reported no benchmark result, e.g. because -bench matched none
+var ErrNoBenchmarks = errors.New("no benchmark results found in output")
+
+// parseOutputTo parses the benchmark output in real-time, copying it to the
+// verbose writer, if any. Progress callbacks and the live status are
+// updated under the runner's lock, as packages may be parsed in parallel.
+func (r *Runner) parseOutputTo(reader io.Reader, verbose io.Writer) ([]models.BenchmarkResult, error) {
+	// If verbose mode is enabled, tee the output to the verbose writer
+	if verbose != nil {
+		reader = io.TeeReader(reader, verbose)
+	}
+
+	lines := make(chan string, lineBufferSize)
+	parsed := make(chan parseEvent, lineBufferSize)
+	scanErr := make(chan error, 1)
+
+	// Stage 1: read lines
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(reader)
+		// Increase buffer size to handle long output lines (default is 64KB, set to 1MB)
+		buf := make([]byte, 0, 64*1024)
+		scanner.Buffer(buf, 1024*1024) // 1MB max token size
+
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	// Stage 2: match benchmark lines
+	go func() {
+		defer close(parsed)
+
+		p := &lineParser{extractors: r.metricExtractors}
+		emit := func(result models.BenchmarkResult) { parsed <- parseEvent{result: result} }
+		if r.startCallback != nil || r.live != nil {
+			p.started = func(name string) { parsed <- parseEvent{started: name} }
+		}
+		for line := range lines {
+			p.feed(line, emit)
+		}
+		p.flush(emit)
+	}()
+
+	// Stage 3: collect results in output order
+	var results []models.BenchmarkResult
+	for event := range parsed {
+		r.mu.Lock()
+		if event.started != "" {
+			if r.live != nil {
+				r.live.started(event.started)
+			}
+			if r.startCallback != nil {
+				r.startCallback(event.started)
+			}
+			r.mu.Unlock()
+			continue
+		}
+		results = append(results, event.result)
+
+		if r.live != nil {
+			r.live.completed(event.result)
+		}
+		// Call progress callback with full result after parsing
+		if r.progressCallback != nil {
+			r.progressCallback(event.result)
+		}
+		r.mu.Unlock()
+	}
+
+	if err := <-scanErr; err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, ErrNoBenchmarks
+	}
+
+	return results, nil
+}
+
+// parseEvent is either a completed result or the name of a benchmark that started
+type parseEvent struct {
+	result  models.BenchmarkResult
+	started string
+}
+
+// parseOutput parses the benchmark output from go test -bench (kept for compatibility)
+func (r *Runner) parseOutput(output string) ([]models.BenchmarkResult, error) {
+	return r.parseOutputRealtime(strings.NewReader(output))
+}
+
+// lineParser turns go test -bench output lines into results. It tracks
+// enough state to recover result lines split by benchmark output, to
+// attribute extracted domain metrics to the benchmark that printed them, and
+// to record benchmarks that failed, panicked or were skipped.
+//
+// Without -v, output printed by a benchmark lands between its name and its
+// measurements, and b.Log output follows in a "--- BENCH:" block:
+//
+//	BenchmarkA   cache_hit_ratio=0.93
+//	     100     154.9 ns/op
+//	--- BENCH: BenchmarkA
+//	    a_test.go:3: misses=7
+//
+// With -v the name is printed on its own line before any output, and log
+// lines of failing or skipped benchmarks precede their "--- FAIL:" line.
+type lineParser struct {
+	extractors []*MetricExtractor
+
+	pendingName    string                  // Benchmark whose measurements have not been printed yet
+	pendingMetrics map[string]float64      // Metrics extracted since the last result
+	pendingLogs    []string                // Indented log lines since the last result
+	held           *models.BenchmarkResult // Last result, kept while its log block may follow
+	inBenchLog     bool                    // Inside a "--- BENCH:" or "--- FAIL:" block for held
+	panicking      string                  // Panic message awaiting the benchmark named in the stack trace
+	failedNames    []string                // Names of failed benchmarks, to skip their parents
+	pkg            string                  // Import path from the last "pkg:" line
+	started        func(name string)       // Called when a benchmark's name line is printed, may be nil
+}
+
+// feed processes one line of output, calling emit for each completed result
+func (p *lineParser) feed(line string, emit func(models.BenchmarkResult)) {
+	if p.panicking != "" {
+		if name, ok := panickingBenchmark(line); ok {
+			p.fail(name, p.panicking, models.StatusFailed, emit)
+			p.panicking = ""
+			return
+		}
+		if !strings.HasPrefix(line, "FAIL") && !strings.HasPrefix(line, "exit status") {
+			return
+		}
+		// The test binary exited without a recognizable benchmark frame
+		p.panicking = ""
+	}
+
+	if result, ok := parseBenchmarkLine(line); ok {
+		p.complete(result, emit)
+		return
+	}
+
+	// Measurements continuing a name line that was interrupted by output.
+	// They are right-aligned, so large iteration counts are not indented.
+	if p.pendingName != "" {
+		if result, ok := parseResultFields(p.pendingName, line); ok {
+			p.complete(result, emit)
+			return
+		}
+	}
+
+	indented := startsWithSpace(line)
+	rest := line
+	if !indented {
+		if strings.HasPrefix(line, "--- BENCH:") {
+			p.inBenchLog = true
+			p.pendingLogs = nil
+			return
+		}
+		// Anything else at the top level ends the previous result's log block
+		p.flush(emit)
+
+		if pkg, ok := strings.CutPrefix(line, "pkg: "); ok {
+			p.pkg = strings.TrimSpace(pkg)
+			return
+		}
+
+		if name, after := nextField(line); strings.HasPrefix(name, "Benchmark") {
+			p.pendingName = strings.TrimPrefix(name, "Benchmark")
+			p.pendingLogs = nil
+			if p.started != nil {
+				p.started(p.pendingName)
+			}
+			rest = strings.TrimLeft(after, " \t")
+		}
+
+		if status, name, ok := parseStatusLine(rest); ok {
+			p.fail(name, firstLog(p.pendingLogs), status, emit)
+			return
+		}
+
+		if msg, ok := strings.CutPrefix(rest, "panic: "); ok {
+			p.panicking = "panic: " + msg
+			if p.pendingName != "" {
+				// With -v the running benchmark is known from its name line
+				p.fail(p.pendingName, p.panicking, models.StatusFailed, emit)
+				p.panicking = ""
+			}
+			return
+		}
+	} else {
+		if p.held != nil && p.inBenchLog && !p.held.Measured() && p.held.Message == "" {
+			p.held.Message = strings.TrimSpace(line)
+		}
+		if !p.inBenchLog {
+			p.pendingLogs = append(p.pendingLogs, strings.TrimSpace(line))
+		}
+	}
+
+	for _, e := range p.extractors {
+		v, ok := e.Extract(rest)
+		if !ok {
+			continue
+		}
+		if p.held != nil && p.inBenchLog {
+			setMetric(p.held, e.Name(), v)
+			continue
+		}
+		if p.pendingMetrics == nil {
+			p.pendingMetrics = make(map[string]float64)
+		}
+		// Benchmarks run several times while calibrating b.N; keep the last value
+		p.pendingMetrics[e.Name()] = v
+	}
+}
+
+// complete records a parsed result, attaching any metrics printed before it
+func (p *lineParser) complete(result models.BenchmarkResult, emit func(models.BenchmarkResult)) {
+	p.flush(emit)
+
+	result.Status = models.StatusOK
+	result.Package = p.pkg
+	setFamily(&result)
+	for name, v := range p.pendingMetrics {
+		setMetric(&result, name, v)
+	}
+	p.reset()
+
+	if len(p.extractors) == 0 {
+		emit(result)
+		return
+	}
+	// Hold the result until we know whether a log block follows
+	p.held = &result
+}
+
+// fail records a benchmark that failed or was skipped. The result is held
+// so that the log lines following it can supply the message.
+func (p *lineParser) fail(name, message, status string, emit func(models.BenchmarkResult)) {
+	p.flush(emit)
+	p.reset()
+
+	// A parent benchmark fails when one of its sub-benchmarks fails;
+	// the sub-benchmark has already been recorded
+	if status == models.StatusFailed {
+		for _, failed := range p.failedNames {
+			if strings.HasPrefix(failed, name+"/") {
+				return
+			}
+		}
+		p.failedNames = append(p.failedNames, name)
+	}
+
+	p.held = &models.BenchmarkResult{Name: name, Package: p.pkg, Status: status, Message: message}
+	setFamily(p.held)
+	p.inBenchLog = true
+}
+
+// setFamily records the benchmark function and variant of a sub-benchmark
+func setFamily(result *models.BenchmarkResult) {
+	if family, variant := models.SplitBenchmarkName(result.Name); variant != "" {
+		result.Parent, result.Variant = family, variant
+	}
+}
+
+// reset clears the state accumulated for the next benchmark
+func (p *lineParser) reset() {
+	p.pendingMetrics = nil
+	p.pendingLogs = nil
+	p.pendingName = ""
+}
+
+// flush emits the held result, if any
+func (p *lineParser) flush(emit func(models.BenchmarkResult)) {
+	if p.held != nil {
+		emit(*p.held)
+		p.held = nil
+	}
+	p.inBenchLog = false
+}
+
+// parseStatusLine parses "--- FAIL: BenchmarkX" and "--- SKIP: BenchmarkX"
+// lines, returning the status and the benchmark name
+func parseStatusLine(line string) (status, name string, ok bool) {
+	var rest string
+	switch {
+	case strings.HasPrefix(line, "--- FAIL: "):
+		status, rest = models.StatusFailed, line[len("--- FAIL: "):]
+	case strings.HasPrefix(line, "--- SKIP: "):
+		status, rest = models.StatusSkipped, line[len("--- SKIP: "):]
+	default:
+		return "", "", false
+	}
+
+	// Strip the trailing duration printed by -v, e.g. "(0.00s)"
+	field, _ := nextField(rest)
+	if !strings.HasPrefix(field, "Benchmark") || len(field) <= len("Benchmark") {
+		return "", "", false
+	}
+	return status, field[len("Benchmark"):], true
+}
+
+// panickingBenchmark extracts the benchmark name from a goroutine stack
+// frame such as "example.com/pkg.BenchmarkFoo.func1(0xc000010000)"
+func panickingBenchmark(line string) (string, bool) {
+	i := strings.Index(line, ".Benchmark")
+	if i < 0 || startsWithSpace(line) {
+		return "", false
+	}
+	name := line[i+len(".Benchmark"):]
+	end := 0
+	for end < len(name) && (name[end] == '_' || unicode.IsLetter(rune(name[end])) || unicode.IsDigit(rune(name[end]))) {
+		end++
+	}
+	if end == 0 {
+		return "", false
+	}
+	return name[:end], true
+}
+
+// firstLog returns the first log line, if any
+func firstLog(logs []string) string {
+	if len(logs) == 0 {
+		return ""
+	}
+	return logs[0]
+}
+
+// setMetric stores a custom metric on a result
+func setMetric(result *models.BenchmarkResult, name string, v float64) {
+	if result.Metrics == nil {
+		result.Metrics = make(map[string]float64)
+	}
+	result.Metrics[name] = v
+}
+
+// parseBenchmarkLine parses a single line of go test -bench output,
+// reporting false for lines that are not benchmark results.
+//
+// The line is tokenized by hand rather than with a regexp: a result line is
+// a name beginning with "Benchmark", an iteration count, and any number of
+// value/unit pairs separated by arbitrary spaces or tabs.
+// Example: BenchmarkFoo-8   1000000   1234 ns/op   512 B/op   10 allocs/op
+func parseBenchmarkLine(line string) (models.BenchmarkResult, bool) {
+	name, rest := nextField(line)
+	if len(name) <= len("Benchmark") || !strings.HasPrefix(name, "Benchmark") {
+		return models.BenchmarkResult{}, false
+	}
+
+	return parseResultFields(name[len("Benchmark"):], rest)
+}
+
+// parseResultFields parses the iteration count and value/unit pairs that
+// follow a benchmark name
+func parseResultFields(name, fields string) (models.BenchmarkResult, bool) {
+	field, rest := nextField(fields)
+	iterations, err := strconv.ParseInt(field, 10, 64)
+	if err != nil {
+		return models.BenchmarkResult{}, false
+	}
+
+	result := models.BenchmarkResult{
+		Name:       name,
+		Iterations: iterations,
+	}
+
+	pairs := 0
+	for {
+		var value, unit string
+		value, rest = nextField(rest)
+		if value == "" {
+			break
+		}
+		unit, rest = nextField(rest)
+		if unit == "" {
+			break
+		}
+
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			// Not a metric pair (e.g. log output on the same line); keep what we have
+			break
+		}
+		pairs++
+
+		switch unit {
+		case "ns/op":
+			result.NsPerOp = v
+		case "MB/s":
+			result.MBPerSec = v
+		case "B/op":
+			result.BytesPerOp = int64(v)
+		case "allocs/op":
+			result.AllocsPerOp = int64(v)
+		default:
+			// Custom units reported via b.ReportMetric
+			setMetric(&result, unit, v)
+		}
+	}
+
+	if pairs == 0 {
+		return models.BenchmarkResult{}, false
+	}
+
+	return result, true
+}
+
+// startsWithSpace reports whether line is indented
+func startsWithSpace(line string) bool {
+	return line != "" && isSpace(line[0])
+}
+
+// nextField returns the next whitespace-delimited field of s and the remainder
+func nextField(s string) (field, rest string) {
+	i := 0
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	j := i
+	for j < len(s) && !isSpace(s[j]) {
+		j++
+	}
+	return s[i:j], s[j:]
+}
+
+// isSpace reports whether b separates fields in benchmark output
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\v' || b == '\f'
+}
@@ -0,0 +1,523 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// generateBenchOutput builds go test -bench output with n result lines
+// interleaved with log noise
+func generateBenchOutput(n int) string {
+	var sb strings.Builder
+	sb.WriteString("goos: linux\ngoarch: amd64\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "BenchmarkSweep/case-%d-8    %d   %d ns/op   %d B/op   %d allocs/op\n", i, 1000+i, i+1, i*2, i%7)
+		if i%10 == 0 {
+			fmt.Fprintf(&sb, "    sweep_test.go:42: iteration %d\n", i)
+		}
+	}
+	sb.WriteString("PASS\nok      github.com/test/bench   3.456s\n")
+	return sb.String()
+}
+
+func TestParseOutputLargeKeepsOrder(t *testing.T) {
+	const n = 100000
+	output := generateBenchOutput(n)
+
+	var callbackNames []string
+	r := &Runner{}
+	r.WithProgress(func(result models.BenchmarkResult) {
+		callbackNames = append(callbackNames, result.Name)
+	})
+
+	results, err := r.parseOutput(output)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != n {
+		t.Fatalf("Expected %d results, got %d", n, len(results))
+	}
+	if len(callbackNames) != n {
+		t.Fatalf("Expected %d progress callbacks, got %d", n, len(callbackNames))
+	}
+
+	for i, result := range results {
+		expected := fmt.Sprintf("Sweep/case-%d-8", i)
+		if result.Name != expected {
+			t.Fatalf("Result %d: expected name %s, got %s", i, expected, result.Name)
+		}
+		if callbackNames[i] != expected {
+			t.Fatalf("Callback %d: expected name %s, got %s", i, expected, callbackNames[i])
+		}
+		if result.Iterations != int64(1000+i) || result.NsPerOp != float64(i+1) {
+			t.Fatalf("Result %d: unexpected values %+v", i, result)
+		}
+	}
+}
+
+type failingReader struct {
+	data io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.data.Read(p)
+	if err == io.EOF {
+		return n, errors.New("pipe broken")
+	}
+	return n, err
+}
+
+func TestParseOutputReaderError(t *testing.T) {
+	r := &Runner{}
+	reader := &failingReader{data: strings.NewReader(generateBenchOutput(10))}
+
+	_, err := r.parseOutputRealtime(reader)
+	if err == nil || !strings.Contains(err.Error(), "pipe broken") {
+		t.Errorf("Expected reader error to be returned, got %v", err)
+	}
+}
+
+func TestParseBenchmarkLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		ok       bool
+		expected models.BenchmarkResult
+	}{
+		{
+			name:     "basic",
+			line:     "BenchmarkFoo-8   1000   12.5 ns/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo-8", Iterations: 1000, NsPerOp: 12.5},
+		},
+		{
+			name:     "all standard units",
+			line:     "BenchmarkFoo-8   1000   12.5 ns/op   85.40 MB/s   64 B/op   1 allocs/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo-8", Iterations: 1000, NsPerOp: 12.5, MBPerSec: 85.4, BytesPerOp: 64, AllocsPerOp: 1},
+		},
+		{
+			name:     "tabs and uneven spacing",
+			line:     "BenchmarkFoo-8\t \t1000\t12.5 ns/op\t  64 B/op \t1 allocs/op  ",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo-8", Iterations: 1000, NsPerOp: 12.5, BytesPerOp: 64, AllocsPerOp: 1},
+		},
+		{
+			name:     "carriage return line ending",
+			line:     "BenchmarkFoo-8   1000   12.5 ns/op\r",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo-8", Iterations: 1000, NsPerOp: 12.5},
+		},
+		{
+			name:     "sub-benchmark",
+			line:     "BenchmarkMap/size=1024/parallel-16   500   2400 ns/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Map/size=1024/parallel-16", Iterations: 500, NsPerOp: 2400},
+		},
+		{
+			name:     "no GOMAXPROCS suffix",
+			line:     "BenchmarkFoo   1000   12 ns/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo", Iterations: 1000, NsPerOp: 12},
+		},
+		{
+			name:     "units in non-standard order",
+			line:     "BenchmarkFoo-8   1000   1 allocs/op   64 B/op   12 ns/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo-8", Iterations: 1000, NsPerOp: 12, BytesPerOp: 64, AllocsPerOp: 1},
+		},
+		{
+			name: "custom units",
+			line: "BenchmarkCache-8   1000   12 ns/op   0.93 hit-ratio   4.000 p99-ms",
+			ok:   true,
+			expected: models.BenchmarkResult{
+				Name: "Cache-8", Iterations: 1000, NsPerOp: 12,
+				Metrics: map[string]float64{"hit-ratio": 0.93, "p99-ms": 4},
+			},
+		},
+		{
+			name:     "custom units only",
+			line:     "BenchmarkCache-8   1000   42 items/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Cache-8", Iterations: 1000, Metrics: map[string]float64{"items/op": 42}},
+		},
+		{
+			name:     "negative allocs reported under race detector",
+			line:     "BenchmarkRace-8   1000   12 ns/op   -16 B/op   -1 allocs/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Race-8", Iterations: 1000, NsPerOp: 12, BytesPerOp: -16, AllocsPerOp: -1},
+		},
+		{
+			name:     "scientific notation",
+			line:     "BenchmarkSlow-8   1   1.5e+09 ns/op",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Slow-8", Iterations: 1, NsPerOp: 1.5e9},
+		},
+		{
+			name:     "trailing log text",
+			line:     "BenchmarkFoo-8   1000   12 ns/op   note: warmed up",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo-8", Iterations: 1000, NsPerOp: 12},
+		},
+		{
+			name:     "dangling value without unit",
+			line:     "BenchmarkFoo-8   1000   12 ns/op   64",
+			ok:       true,
+			expected: models.BenchmarkResult{Name: "Foo-8", Iterations: 1000, NsPerOp: 12},
+		},
+		{name: "name only", line: "BenchmarkFoo", ok: false},
+		{name: "bare prefix", line: "Benchmark   1000   12 ns/op", ok: false},
+		{name: "name and iterations only", line: "BenchmarkFoo-8   1000", ok: false},
+		{name: "benchmark header from -v", line: "BenchmarkFoo-8", ok: false},
+		{name: "non-numeric iterations", line: "BenchmarkFoo-8   many   12 ns/op", ok: false},
+		{name: "log line mentioning benchmark", line: "BenchmarkFoo-8   \tfoo_test.go:12: setting up", ok: false},
+		{name: "indented log line", line: "    foo_test.go:12: BenchmarkFoo-8 1000 12 ns/op", ok: false},
+		{name: "skip marker", line: "--- SKIP: BenchmarkFoo-8", ok: false},
+		{name: "pass", line: "PASS", ok: false},
+		{name: "empty", line: "", ok: false},
+		{name: "whitespace only", line: " \t ", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parseBenchmarkLine(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("parseBenchmarkLine(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseBenchmarkLine(%q) = %+v, want %+v", tt.line, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNextField(t *testing.T) {
+	tests := []struct {
+		input string
+		field string
+		rest  string
+	}{
+		{"foo bar", "foo", " bar"},
+		{"  \tfoo", "foo", ""},
+		{"", "", ""},
+		{"   ", "", ""},
+	}
+
+	for _, tt := range tests {
+		field, rest := nextField(tt.input)
+		if field != tt.field || rest != tt.rest {
+			t.Errorf("nextField(%q) = (%q, %q), want (%q, %q)", tt.input, field, rest, tt.field, tt.rest)
+		}
+	}
+}
+
+func BenchmarkParseOutput(b *testing.B) {
+	output := generateBenchOutput(10000)
+	r := &Runner{}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.parseOutput(output); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Output of benchmarks that print while running, without -v
+const printingBenchOutput = `cache_hit_ratio=0.93
+goos: linux
+goarch: amd64
+pkg: bx
+BenchmarkA-8 	cache_hit_ratio=0.91
+     100	       154.9 ns/op
+--- BENCH: BenchmarkA-8
+    b_test.go:3: misses=7
+    b_test.go:3: misses=9
+{"latency": {"p99_ms": 4.5}}
+BenchmarkB-8 	{"latency": {"p99_ms": 4.5}}
+1000000000	         0.5335 ns/op
+BenchmarkC/sub-8         	     100	        75.54 ns/op
+PASS
+ok  	bx	0.003s`
+
+// The same benchmarks run with -v
+const printingBenchOutputVerbose = `goos: linux
+goarch: amd64
+pkg: bx
+BenchmarkA
+cache_hit_ratio=0.93
+    b_test.go:3: misses=7
+cache_hit_ratio=0.91
+    b_test.go:3: misses=9
+BenchmarkA-8 	     100	       193.2 ns/op
+BenchmarkB
+{"latency": {"p99_ms": 4.5}}
+BenchmarkB-8 	     100	        54.02 ns/op
+BenchmarkC
+BenchmarkC/sub
+BenchmarkC/sub-8         	     100	       113.1 ns/op
+PASS
+ok  	bx	0.003s`
+
+func TestParseOutputInterruptedResultLines(t *testing.T) {
+	r := &Runner{}
+	results, err := r.parseOutput(printingBenchOutput)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	names := []string{"A-8", "B-8", "C/sub-8"}
+	if len(results) != len(names) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(names), len(results), results)
+	}
+	for i, name := range names {
+		if results[i].Name != name {
+			t.Errorf("Result %d: expected name %s, got %s", i, name, results[i].Name)
+		}
+		if results[i].Metrics != nil {
+			t.Errorf("Result %d: expected no metrics without extractors, got %v", i, results[i].Metrics)
+		}
+		if results[i].Package != "bx" {
+			t.Errorf("Result %d: expected package bx, got %q", i, results[i].Package)
+		}
+	}
+	if results[0].NsPerOp != 154.9 || results[0].Iterations != 100 {
+		t.Errorf("Unexpected values for A-8: %+v", results[0])
+	}
+	if results[0].Parent != "" || results[0].Variant != "" {
+		t.Errorf("Expected no parent for a top-level benchmark, got %+v", results[0])
+	}
+	if results[2].Parent != "C" || results[2].Variant != "sub" {
+		t.Errorf("Expected C/sub-8 to be variant sub of C, got parent %q variant %q", results[2].Parent, results[2].Variant)
+	}
+}
+
+func TestParseOutputStartCallback(t *testing.T) {
+	var events []string
+	r := (&Runner{}).
+		WithStartCallback(func(name string) { events = append(events, "start "+name) }).
+		WithProgress(func(result models.BenchmarkResult) { events = append(events, "done "+result.Name) })
+
+	if _, err := r.parseOutput(printingBenchOutputVerbose); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"start A", "done A-8", "start B", "done B-8", "start C", "start C/sub", "done C/sub-8"}
+	if strings.Join(events, ", ") != strings.Join(want, ", ") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestParseOutputMetricExtractors(t *testing.T) {
+	hitRatio, err := NewRegexExtractor("cache_hit_ratio", `cache_hit_ratio=([0-9.]+)`)
+	if err != nil {
+		t.Fatalf("NewRegexExtractor failed: %v", err)
+	}
+	misses, err := NewRegexExtractor("misses", `misses=(\d+)`)
+	if err != nil {
+		t.Fatalf("NewRegexExtractor failed: %v", err)
+	}
+	p99, err := NewJSONPathExtractor("p99_ms", "latency.p99_ms")
+	if err != nil {
+		t.Fatalf("NewJSONPathExtractor failed: %v", err)
+	}
+
+	for name, output := range map[string]string{
+		"plain":   printingBenchOutput,
+		"verbose": printingBenchOutputVerbose,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var progress []models.BenchmarkResult
+			r := &Runner{}
+			r.WithMetricExtractors([]*MetricExtractor{hitRatio, misses, p99})
+			r.WithProgress(func(result models.BenchmarkResult) {
+				progress = append(progress, result)
+			})
+
+			results, err := r.parseOutput(output)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(results) != 3 {
+				t.Fatalf("Expected 3 results, got %d", len(results))
+			}
+
+			expected := []map[string]float64{
+				{"cache_hit_ratio": 0.91, "misses": 9},
+				{"p99_ms": 4.5},
+				nil,
+			}
+			for i, want := range expected {
+				if !reflect.DeepEqual(results[i].Metrics, want) {
+					t.Errorf("Result %s: metrics = %v, want %v", results[i].Name, results[i].Metrics, want)
+				}
+			}
+
+			if !reflect.DeepEqual(progress, results) {
+				t.Errorf("Progress callbacks %+v do not match results %+v", progress, results)
+			}
+		})
+	}
+}
+
+// Output of a suite with failing, skipped and panicking benchmarks
+const failingBenchOutput = `goos: linux
+goarch: amd64
+pkg: bf
+BenchmarkOK-2   	    1000	         0.6310 ns/op	       0 B/op	       0 allocs/op
+--- FAIL: BenchmarkFail
+    b_test.go:4: assertion failed: want 3
+BenchmarkErr-2  	--- FAIL: BenchmarkErr
+    b_test.go:5: bad result
+BenchmarkSub/good-2         	    1000	         0.6420 ns/op	       0 B/op	       0 allocs/op
+--- FAIL: BenchmarkSub/bad
+    b_test.go:7: x
+--- FAIL: BenchmarkSub
+BenchmarkZ-2                	    1000	         0.5500 ns/op	       0 B/op	       0 allocs/op
+panic: boom
+
+goroutine 19 [running]:
+bf.BenchmarkPanic(0x1e147570e308?)
+	/tmp/bf/p_test.go:3 +0x25
+testing.(*B).runN(0x1e147570e308, 0x1)
+	/usr/local/go/src/testing/benchmark.go:219 +0x190
+exit status 2
+FAIL	bf	0.006s`
+
+// The same suite run with -v
+const failingBenchOutputVerbose = `goos: linux
+goarch: amd64
+pkg: bf
+BenchmarkOK
+BenchmarkOK-2    	    1000	         0.4820 ns/op	       0 B/op	       0 allocs/op
+BenchmarkFail
+    b_test.go:4: assertion failed: want 3
+--- FAIL: BenchmarkFail
+BenchmarkErr
+    b_test.go:5: bad result
+--- FAIL: BenchmarkErr
+BenchmarkSkip
+    b_test.go:6: needs network
+--- SKIP: BenchmarkSkip
+BenchmarkSub
+BenchmarkSub/good
+BenchmarkSub/good-2         	    1000	         0.3840 ns/op	       0 B/op	       0 allocs/op
+BenchmarkSub/bad
+    b_test.go:7: x
+--- FAIL: BenchmarkSub/bad
+--- FAIL: BenchmarkSub
+BenchmarkZ
+BenchmarkZ-2                	    1000	         0.3810 ns/op	       0 B/op	       0 allocs/op
+BenchmarkPanic
+panic: boom
+
+goroutine 19 [running]:
+bf.BenchmarkPanic(0x3d2cc4eca308?)
+	/tmp/bf/p_test.go:3 +0x25
+exit status 2
+FAIL	bf	0.006s`
+
+func TestParseOutputFailures(t *testing.T) {
+	type entry struct {
+		name    string
+		status  string
+		message string
+	}
+
+	tests := []struct {
+		name     string
+		output   string
+		expected []entry
+	}{
+		{
+			name:   "plain",
+			output: failingBenchOutput,
+			expected: []entry{
+				{"OK-2", models.StatusOK, ""},
+				{"Fail", models.StatusFailed, "b_test.go:4: assertion failed: want 3"},
+				{"Err", models.StatusFailed, "b_test.go:5: bad result"},
+				{"Sub/good-2", models.StatusOK, ""},
+				{"Sub/bad", models.StatusFailed, "b_test.go:7: x"},
+				{"Z-2", models.StatusOK, ""},
+				{"Panic", models.StatusFailed, "panic: boom"},
+			},
+		},
+		{
+			name:   "verbose",
+			output: failingBenchOutputVerbose,
+			expected: []entry{
+				{"OK-2", models.StatusOK, ""},
+				{"Fail", models.StatusFailed, "b_test.go:4: assertion failed: want 3"},
+				{"Err", models.StatusFailed, "b_test.go:5: bad result"},
+				{"Skip", models.StatusSkipped, "b_test.go:6: needs network"},
+				{"Sub/good-2", models.StatusOK, ""},
+				{"Sub/bad", models.StatusFailed, "b_test.go:7: x"},
+				{"Z-2", models.StatusOK, ""},
+				{"Panic", models.StatusFailed, "panic: boom"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var progress []string
+			r := &Runner{}
+			r.WithProgress(func(result models.BenchmarkResult) {
+				progress = append(progress, result.Name)
+			})
+
+			results, err := r.parseOutput(tt.output)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(results) != len(tt.expected) {
+				t.Fatalf("Expected %d results, got %d: %+v", len(tt.expected), len(results), results)
+			}
+			for i, want := range tt.expected {
+				got := entry{results[i].Name, results[i].Status, results[i].Message}
+				if got != want {
+					t.Errorf("Result %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if len(progress) != len(results) {
+				t.Errorf("Expected %d progress callbacks, got %d", len(results), len(progress))
+			}
+			if !hasFailures(results) {
+				t.Error("Expected hasFailures to report the failures")
+			}
+		})
+	}
+}
+
+func TestPanickingBenchmark(t *testing.T) {
+	tests := []struct {
+		line string
+		name string
+		ok   bool
+	}{
+		{"bf.BenchmarkPanic(0x1e147570e308?)", "Panic", true},
+		{"github.com/acme/cache.BenchmarkGet.func1(0xc000010000)", "Get", true},
+		{"github.com/acme/cache.BenchmarkGet_Parallel(0xc000010000)", "Get_Parallel", true},
+		{"testing.(*B).runN(0x1e147570e308, 0x1)", "", false},
+		{"\t/tmp/bf/p_test.go:3 +0x25", "", false},
+		{"goroutine 19 [running]:", "", false},
+	}
+
+	for _, tt := range tests {
+		name, ok := panickingBenchmark(tt.line)
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("panickingBenchmark(%q) = (%q, %v), want (%q, %v)", tt.line, name, ok, tt.name, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// removeRetries is how often removing a temporary directory is attempted.
+// On Windows, files of a process that just exited can stay locked for a
+// moment, and virus scanners open freshly written binaries.
+const removeRetries = 10
+
+// exeSuffix returns the file name suffix of executables on this platform
+func exeSuffix() string {
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+// removeTempDir removes a temporary directory, retrying on Windows while
+// its files are still in use
+func removeTempDir(dir string) {
+	var err error
+	for attempt := 1; attempt <= removeRetries; attempt++ {
+		if err = os.RemoveAll(dir); err == nil || runtime.GOOS != "windows" {
+			break
+		}
+		time.Sleep(time.Duration(attempt) * 50 * time.Millisecond)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove temporary directory %s: %v\n", dir, err)
+	}
+}
+
+// packageDir returns the directory of a package pattern such as "./...",
+// "./internal/foo" or ".\internal\foo" on Windows
+func packageDir(pkg string) string {
+	dir := strings.TrimSuffix(pkg, "...")
+	for len(dir) > 1 && os.IsPathSeparator(dir[len(dir)-1]) {
+		dir = dir[:len(dir)-1]
+	}
+	if dir == "" {
+		return "."
+	}
+	return filepath.Clean(dir)
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPackageDir(t *testing.T) {
+	tests := []struct {
+		pkg  string
+		want string
+	}{
+		{"", "."},
+		{".", "."},
+		{"./...", "."},
+		{"...", "."},
+		{"./internal/...", "internal"},
+		{"./internal/foo", filepath.Join("internal", "foo")},
+		{"../../examples", filepath.Join("..", "..", "examples")},
+		{"/abs/path/", filepath.Clean("/abs/path")},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, struct {
+			pkg  string
+			want string
+		}{`.\internal\...`, "internal"})
+	}
+
+	for _, tt := range tests {
+		if got := packageDir(tt.pkg); got != tt.want {
+			t.Errorf("packageDir(%q) = %q, want %q", tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func TestExeSuffix(t *testing.T) {
+	want := ""
+	if runtime.GOOS == "windows" {
+		want = ".exe"
+	}
+	if got := exeSuffix(); got != want {
+		t.Errorf("exeSuffix() = %q, want %q", got, want)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewRunner("../../examples", ".").WithContext(ctx).Run()
+	if err == nil {
+		t.Fatal("Expected an error for a canceled run")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a context.Canceled error, got: %v", err)
+	}
+}
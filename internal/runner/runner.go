@@ -1,16 +1,19 @@
 package runner
 
 import (
-	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alenon/gokanon/internal/aianalyzer"
@@ -22,11 +25,18 @@ import (
 // ProgressCallback is called when a benchmark test completes with its results
 type ProgressCallback func(result models.BenchmarkResult)
 
+// StartCallback is called with the name of a benchmark when it starts executing
+type StartCallback func(name string)
+
 // ProfileOptions configures profiling behavior
 type ProfileOptions struct {
-	EnableCPU    bool
-	EnableMemory bool
-	Storage      *storage.Storage
+	EnableCPU        bool
+	EnableMemory     bool
+	EnableBlock      bool   // Profile where goroutines block on synchronization
+	EnableMutex      bool   // Profile the holders of contended mutexes
+	CPUSampleType    string // Sample type to analyze in the CPU profile (empty = default)
+	MemorySampleType string // Sample type to analyze in the memory profile (empty = alloc_space)
+	Storage          *storage.Storage
 }
 
 // Runner handles benchmark execution
@@ -35,11 +45,35 @@ type Runner struct {
 	benchFilter      string
 	profileOptions   *ProfileOptions
 	progressCallback ProgressCallback
+	startCallback    StartCallback
 	verboseWriter    io.Writer
 	cpu              string
 	benchtime        string
+	count            int
+	gcflags          string
+	metricExtractors []*MetricExtractor
+	liveStore        *storage.Storage
+	live             *liveTracker // Set while a run publishes its live status
+	ctx              context.Context
+	aiDefaults       aianalyzer.Config
+	dir              string // Directory the go command runs in; empty for the current one
+	noHooks          bool
+	noTests          bool
+	stress           *models.StressMatrix // Parallelism levels of a stress run
+	packageWorkers   int                  // Packages benchmarked at once when the pattern matches several
+	startup          *StartupOptions      // Set to time the startup of the main package instead
+	load             *loadTest            // Set to send HTTP load to a service instead
+
+	// Serializes the progress callbacks and live status of packages
+	// benchmarked in parallel
+	mu sync.Mutex
 }
 
+// interruptGrace is how long an interrupted run waits for the benchmark
+// processes to exit before it stops reading their output. On Windows a
+// test binary can outlive the go command that started it.
+const interruptGrace = 5 * time.Second
+
 // NewRunner creates a new benchmark runner
 func NewRunner(packagePath, benchFilter string) *Runner {
 	return &Runner{
@@ -60,28 +94,131 @@ func (r *Runner) WithProgress(callback ProgressCallback) *Runner {
 	return r
 }
 
+// WithStartCallback configures the runner to report benchmarks as they start
+func (r *Runner) WithStartCallback(callback StartCallback) *Runner {
+	r.startCallback = callback
+	return r
+}
+
+// WithLiveStatus configures the runner to publish its progress to the
+// storage directory, where the dashboard's live view picks it up
+func (r *Runner) WithLiveStatus(store *storage.Storage) *Runner {
+	r.liveStore = store
+	return r
+}
+
 // WithVerbose configures the runner to output verbose benchmark details
 func (r *Runner) WithVerbose(writer io.Writer) *Runner {
 	r.verboseWriter = writer
 	return r
 }
 
-// WithCPU configures the runner to use specific CPU values
+// WithCPU configures the runner to use specific CPU values. With several,
+// every benchmark is measured at each GOMAXPROCS value and the run records
+// them as its CPU matrix.
 func (r *Runner) WithCPU(cpu string) *Runner {
 	r.cpu = cpu
 	return r
 }
 
+// WithStress measures each benchmark at every parallelism level: go test
+// runs it with GOMAXPROCS set to the level, so RunParallel starts that many
+// goroutines. With the default filter, only the benchmarks calling
+// RunParallel are run.
+func (r *Runner) WithStress(levels []int) *Runner {
+	procs := make([]string, len(levels))
+	for i, level := range levels {
+		procs[i] = strconv.Itoa(level)
+	}
+	r.cpu = strings.Join(procs, ",")
+	r.stress = &models.StressMatrix{Levels: levels, CPUs: runtime.NumCPU()}
+	return r
+}
+
 // WithBenchtime configures the runner to use a specific benchtime
 func (r *Runner) WithBenchtime(benchtime string) *Runner {
 	r.benchtime = benchtime
 	return r
 }
 
+// WithCount configures the runner to run each benchmark n times. The
+// repetitions are merged into one result per benchmark holding all samples.
+func (r *Runner) WithCount(n int) *Runner {
+	r.count = n
+	return r
+}
+
+// WithGCFlags sets the -gcflags passed to the compiler
+func (r *Runner) WithGCFlags(gcflags string) *Runner {
+	r.gcflags = gcflags
+	return r
+}
+
+// WithContext sets a context whose cancellation, e.g. on Ctrl+C, stops the
+// benchmarks. Run then cleans up and returns an error.
+func (r *Runner) WithContext(ctx context.Context) *Runner {
+	r.ctx = ctx
+	return r
+}
+
+// WithAIDefaults sets the AI analyzer configuration used for profile
+// summaries where no GOKANON_AI_* environment variable is set
+func (r *Runner) WithAIDefaults(defaults aianalyzer.Config) *Runner {
+	r.aiDefaults = defaults
+	return r
+}
+
+// WithDir runs the benchmarks from dir instead of the current directory,
+// e.g. a worktree with another commit checked out. The package path is
+// relative to dir.
+func (r *Runner) WithDir(dir string) *Runner {
+	r.dir = dir
+	return r
+}
+
+// WithoutHooks disables the GokanonSetup and GokanonTeardown hooks that
+// benchmark packages may declare
+func (r *Runner) WithoutHooks() *Runner {
+	r.noHooks = true
+	return r
+}
+
+// WithoutTests skips the tests of the benchmarked packages, so that only
+// benchmarks run and show up in profiles
+func (r *Runner) WithoutTests() *Runner {
+	r.noTests = true
+	return r
+}
+
+// WithPackageWorkers benchmarks up to n packages at once when the package
+// pattern matches several, each with its own go test. Concurrent
+// benchmarks compete for the CPU and disturb each other's timings, so
+// packages are benchmarked one at a time by default.
+func (r *Runner) WithPackageWorkers(n int) *Runner {
+	r.packageWorkers = n
+	return r
+}
+
+// WithMetricExtractors sets extractors for domain metrics printed by benchmarks
+func (r *Runner) WithMetricExtractors(extractors []*MetricExtractor) *Runner {
+	r.metricExtractors = extractors
+	return r
+}
+
 // Run executes the benchmarks and returns parsed results
 func (r *Runner) Run() (*models.BenchmarkRun, error) {
+	if r.startup != nil {
+		return r.runStartup()
+	}
+	if r.load != nil {
+		return r.runLoadTest()
+	}
+
 	startTime := time.Now()
 
+	// Fingerprint the machine before the benchmarks load it
+	environment := collectEnvironment()
+
 	// Get Go version
 	goVersion, err := r.getGoVersion()
 	if err != nil {
@@ -89,49 +226,192 @@ func (r *Runner) Run() (*models.BenchmarkRun, error) {
 	}
 
 	// Generate unique ID for this run
-	runID := generateID()
+	runID := r.newID()
 
 	// Create temporary directory for profile files
 	tempDir, err := os.MkdirTemp("", "gokanon-profile-*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
-	defer os.RemoveAll(tempDir)
+	defer removeTempDir(tempDir)
 
-	// Build the benchmark command
-	args := []string{"test", "-bench", r.benchFilter, "-benchmem"}
+	if r.stress != nil {
+		r.prepareStress()
+	}
 
-	// Add CPU flag if specified
-	if r.cpu != "" {
-		args = append(args, "-cpu", r.cpu)
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
-	// Add benchtime flag if specified
-	if r.benchtime != "" {
-		args = append(args, "-benchtime", r.benchtime)
+	// A pattern matching several packages runs a go test per package
+	packages := r.expandPackages()
+
+	if r.liveStore != nil {
+		r.live = newLiveTracker(r.liveStore, r.packagePath)
+		defer func() {
+			r.live.finish()
+			r.live = nil
+		}()
 	}
 
-	// Add profiling flags if enabled
-	var cpuProfilePath, memProfilePath string
+	paths := r.profilePaths(tempDir)
+	args := r.testArgs(r.packagePath, tempDir, r.benchtime, r.count, paths)
+	var outcome *packageRun
+	var packageRuns []models.PackageRun
+	if len(packages) > 1 {
+		outcome, packageRuns, err = r.runPackages(ctx, packages, tempDir, &paths)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		outcome, err = r.runPackage(ctx, r.packagePath, tempDir, &paths, r.verboseWriter)
+		if err != nil {
+			return nil, err
+		}
+		// Without any result the run failed, e.g. on a build failure
+		if len(outcome.results) == 0 {
+			if outcome.abnormal != nil {
+				return nil, fmt.Errorf("benchmark execution failed: %w\nStderr: %s", outcome.abnormal, outcome.stderr)
+			}
+			return nil, fmt.Errorf("failed to parse benchmark output: %w", ErrNoBenchmarks)
+		}
+	}
+	results := outcome.results
+
+	if r.count > 1 {
+		results = mergeSamples(results)
+	}
+
+	// A -cpu list measures every benchmark at each GOMAXPROCS value
+	matrix := cpuMatrix(r.cpu)
+	if matrix != nil {
+		assignProcs(results, matrix)
+	}
+
+	duration := time.Since(startTime)
+
+	run := &models.BenchmarkRun{
+		ID:            runID,
+		Timestamp:     startTime,
+		Package:       r.packagePath,
+		GoVersion:     goVersion,
+		Results:       results,
+		Command:       fmt.Sprintf("go %s", strings.Join(args, " ")),
+		Duration:      duration,
+		Commit:        getCommit(r.localPackagePath()),
+		CommitMessage: getCommitSubject(r.localPackagePath()),
+		Stress:        r.stress,
+		CPUMatrix:     matrix,
+		Environment:   environment,
+		Packages:      packageRuns,
+	}
+
+	if outcome.abnormal != nil {
+		markFailed(run, outcome.abnormal, outcome.stderr)
+	}
+
+	// Record build settings so comparisons can flag mismatches
+	if toolchain, err := r.getToolchain(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record toolchain settings: %v\n", err)
+	} else {
+		run.Toolchain = toolchain
+	}
+
+	// Record what each benchmark measures for reports and AI analysis
+	if docs, err := benchmarkDocs(r.dir, r.packagePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read benchmark docs: %v\n", err)
+	} else {
+		applyDocs(run.Results, docs)
+	}
+
+	// Record module versions so regressions can be attributed to upgrades
+	if deps, err := CollectDependencies(r.localPackagePath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record dependencies: %v\n", err)
+	} else {
+		run.Dependencies = deps
+	}
+
+	// Handle profile files if profiling was enabled
+	if r.profileOptions != nil && r.profileOptions.Storage != nil {
+		if err := r.handleProfiles(run, paths); err != nil {
+			// Log warning but don't fail the run
+			fmt.Fprintf(os.Stderr, "Warning: failed to process profiles: %v\n", err)
+		}
+	}
+
+	return run, nil
+}
+
+// packageRun is the outcome of a go test benchmarking one package, or
+// every package matching the pattern of a run
+type packageRun struct {
+	results  []models.BenchmarkResult
+	stderr   string
+	abnormal error // Set when go test terminated abnormally
+	duration time.Duration
+}
+
+// profilePaths returns the files in dir that go test writes the enabled
+// profiles to
+func (r *Runner) profilePaths(dir string) profilePaths {
+	var paths profilePaths
 	if r.profileOptions != nil {
 		if r.profileOptions.EnableCPU {
-			cpuProfilePath = filepath.Join(tempDir, "cpu.prof")
-			args = append(args, "-cpuprofile", cpuProfilePath)
+			paths.cpu = filepath.Join(dir, "cpu.prof")
 		}
 		if r.profileOptions.EnableMemory {
-			memProfilePath = filepath.Join(tempDir, "mem.prof")
-			args = append(args, "-memprofile", memProfilePath)
+			paths.mem = filepath.Join(dir, "mem.prof")
+		}
+		if r.profileOptions.EnableBlock {
+			paths.block = filepath.Join(dir, "block.prof")
+		}
+		if r.profileOptions.EnableMutex {
+			paths.mutex = filepath.Join(dir, "mutex.prof")
 		}
 	}
+	return paths
+}
 
-	if r.packagePath != "" {
-		args = append(args, r.packagePath)
-	} else {
-		args = append(args, "./...")
+// runPackage benchmarks pkg with go test, writing the enabled profiles to
+// paths. It fails when go test cannot run; a go test that terminated
+// abnormally, with or without results, is reported in the outcome. The
+// warmup heap profile path is cleared when it could not be taken.
+func (r *Runner) runPackage(ctx context.Context, pkg, tempDir string, paths *profilePaths, verbose io.Writer) (*packageRun, error) {
+	startTime := time.Now()
+	args := r.testArgs(pkg, tempDir, r.benchtime, r.count, *paths)
+
+	// Run the hooks declared by benchmark packages through a generated
+	// harness, which is not part of the recorded command
+	overlay := ""
+	if !r.noHooks {
+		var err error
+		overlay, err = prepareHooks(r.dir, pkg, tempDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to prepare benchmark hooks: %v\n", err)
+		}
 	}
 
+	// A heap profile taken after one iteration of every benchmark shows,
+	// compared with the one at the end, the memory retained by the measured
+	// iterations. It comes from a separate go test, which skips the tests.
+	if paths.mem != "" {
+		paths.warmup = filepath.Join(tempDir, "mem-warmup.prof")
+		warmupArgs := r.warmupArgs(pkg, tempDir, paths.warmup)
+		if err := r.runWarmup(ctx, withOverlay(warmupArgs, overlay), paths.warmup); err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("benchmark run interrupted: %w", ctx.Err())
+			}
+			fmt.Fprintf(os.Stderr, "Warning: failed to take the warmup heap profile: %v\n", err)
+			paths.warmup = ""
+		}
+	}
+	execArgs := withOverlay(args, overlay)
+
 	// Execute benchmark
-	cmd := exec.Command("go", args...)
+	cmd := exec.CommandContext(ctx, "go", execArgs...)
+	cmd.Dir = r.dir
+	cmd.WaitDelay = interruptGrace
 
 	// Capture stderr to a buffer
 	var stderr bytes.Buffer
@@ -148,138 +428,253 @@ func (r *Runner) Run() (*models.BenchmarkRun, error) {
 		return nil, fmt.Errorf("failed to start benchmark: %w", err)
 	}
 
+	// When interrupted, stop reading output that a lingering test binary
+	// may keep open
+	stopWatching := context.AfterFunc(ctx, func() {
+		time.AfterFunc(interruptGrace, func() { stdoutPipe.Close() })
+	})
+	defer stopWatching()
+
 	// Parse results in real-time while collecting output
-	results, err := r.parseOutputRealtime(stdoutPipe)
-	if err != nil {
+	results, err := r.parseOutputTo(stdoutPipe, verbose)
+	if ctx.Err() != nil {
+		cmd.Wait()
+		return nil, fmt.Errorf("benchmark run interrupted: %w", ctx.Err())
+	}
+	if err != nil && !errors.Is(err, ErrNoBenchmarks) {
 		return nil, fmt.Errorf("failed to parse benchmark output: %w", err)
 	}
 
-	// Wait for command to complete
-	if err := cmd.Wait(); err != nil {
-		return nil, fmt.Errorf("benchmark execution failed: %w\nStderr: %s", err, stderr.String())
+	// Wait for command to complete. Failing benchmarks make go test exit
+	// non-zero; they are recorded in the results. Otherwise, or when a
+	// benchmark panicked and took the benchmarks after it down with the
+	// test binary, go test terminated abnormally. Its results so far are
+	// kept; without any, it failed to run them (e.g. a build failure).
+	outcome := &packageRun{results: results}
+	if err := cmd.Wait(); err != nil && (!hasFailures(results) || hasPanics(results)) {
+		outcome.abnormal = err
 	}
+	outcome.stderr = stderr.String()
+	outcome.duration = time.Since(startTime)
+	return outcome, nil
+}
 
-	duration := time.Since(startTime)
-
-	run := &models.BenchmarkRun{
-		ID:        runID,
-		Timestamp: startTime,
-		Package:   r.packagePath,
-		GoVersion: goVersion,
-		Results:   results,
-		Command:   fmt.Sprintf("go %s", strings.Join(args, " ")),
-		Duration:  duration,
+// localPackagePath returns the package path relative to the current
+// directory rather than to the directory the benchmarks run in
+func (r *Runner) localPackagePath() string {
+	if r.dir == "" {
+		return r.packagePath
+	}
+	pkg := r.packagePath
+	if pkg == "" {
+		pkg = "./..."
 	}
+	return filepath.Join(r.dir, pkg)
+}
 
-	// Handle profile files if profiling was enabled
-	if r.profileOptions != nil && r.profileOptions.Storage != nil {
-		if err := r.handleProfiles(run, cpuProfilePath, memProfilePath); err != nil {
-			// Log warning but don't fail the run
-			fmt.Fprintf(os.Stderr, "Warning: failed to process profiles: %v\n", err)
+// hasFailures reports whether any benchmark failed
+func hasFailures(results []models.BenchmarkResult) bool {
+	for _, result := range results {
+		if result.Status == models.StatusFailed {
+			return true
 		}
 	}
+	return false
+}
 
-	return run, nil
+// hasPanics reports whether any benchmark panicked
+func hasPanics(results []models.BenchmarkResult) bool {
+	for _, result := range results {
+		if result.Status == models.StatusFailed && strings.HasPrefix(result.Message, "panic: ") {
+			return true
+		}
+	}
+	return false
 }
 
-// parseOutputRealtime parses the benchmark output in real-time from a reader
-func (r *Runner) parseOutputRealtime(reader io.Reader) ([]models.BenchmarkResult, error) {
-	var results []models.BenchmarkResult
+// maxStderr is the number of bytes of standard error kept with a failed run
+const maxStderr = 64 << 10
 
-	// If verbose mode is enabled, tee the output to the verbose writer
-	if r.verboseWriter != nil {
-		reader = io.TeeReader(reader, r.verboseWriter)
+// Skip records a run whose benchmarks were not run because the
+// environment was unstable, with the reason, so that the pipeline keeps a
+// trace of it without numbers that would mislead
+func (r *Runner) Skip(reason string) (*models.BenchmarkRun, error) {
+	goVersion, err := r.getGoVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Go version: %w", err)
 	}
+	return &models.BenchmarkRun{
+		ID:            r.newID(),
+		Timestamp:     time.Now(),
+		Package:       r.packagePath,
+		GoVersion:     goVersion,
+		Results:       []models.BenchmarkResult{},
+		Commit:        getCommit(r.localPackagePath()),
+		CommitMessage: getCommitSubject(r.localPackagePath()),
+		Environment:   collectEnvironment(),
+		Status:        models.StatusDegraded,
+		Error:         reason,
+	}, nil
+}
 
-	// Regex to match benchmark lines
-	// Example: BenchmarkFoo-8   1000000   1234 ns/op   512 B/op   10 allocs/op
-	benchRegex := regexp.MustCompile(`^Benchmark(\S+)\s+(\d+)\s+([\d.]+)\s+ns/op(?:\s+([\d.]+)\s+MB/s)?(?:\s+(\d+)\s+B/op)?(?:\s+(\d+)\s+allocs/op)?`)
+// markFailed marks a run whose test binary terminated abnormally as failed,
+// describing the first panic if a benchmark panicked, and keeps the end of
+// the standard error of go test
+func markFailed(run *models.BenchmarkRun, err error, stderr string) {
+	run.Status = models.StatusFailed
+	run.Error = fmt.Sprintf("go test terminated abnormally: %v", err)
+	for _, result := range run.Results {
+		if result.Status == models.StatusFailed && strings.HasPrefix(result.Message, "panic: ") {
+			run.Error = fmt.Sprintf("Benchmark%s: %s", result.Name, result.Message)
+			break
+		}
+	}
+	if len(stderr) > maxStderr {
+		stderr = "...\n" + stderr[len(stderr)-maxStderr:]
+	}
+	run.Stderr = stderr
+}
 
-	scanner := bufio.NewScanner(reader)
-	// Increase buffer size to handle long output lines (default is 64KB, set to 1MB)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024) // 1MB max token size
+// getGoVersion returns the current Go version
+func (r *Runner) getGoVersion() (string, error) {
+	cmd := exec.Command("go", "version")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		matches := benchRegex.FindStringSubmatch(line)
+// newID generates a unique ID for a benchmark run, checked against the
+// storage when the runner writes to one
+func (r *Runner) newID() string {
+	switch {
+	case r.liveStore != nil:
+		return r.liveStore.NewRunID()
+	case r.profileOptions != nil && r.profileOptions.Storage != nil:
+		return r.profileOptions.Storage.NewRunID()
+	}
+	return storage.NewRunID(time.Now())
+}
 
-		if matches != nil {
-			name := matches[1]
-			iterations, _ := strconv.ParseInt(matches[2], 10, 64)
-			nsPerOp, _ := strconv.ParseFloat(matches[3], 64)
+// profilePaths are the temporary files go test writes the enabled profiles
+// to; empty for the profiles that are not taken
+type profilePaths struct {
+	cpu, mem, warmup, block, mutex string
+}
 
-			result := models.BenchmarkResult{
-				Name:       name,
-				Iterations: iterations,
-				NsPerOp:    nsPerOp,
-			}
+// any reports whether go test writes any profile
+func (p profilePaths) any() bool {
+	return p.cpu != "" || p.mem != "" || p.block != "" || p.mutex != ""
+}
 
-			// Parse optional MB/s
-			if matches[4] != "" {
-				result.MBPerSec, _ = strconv.ParseFloat(matches[4], 64)
-			}
+// testArgs builds the go test arguments benchmarking pkg. -v is needed
+// for go test to report skipped benchmarks.
+func (r *Runner) testArgs(pkg, tempDir, benchtime string, count int, paths profilePaths) []string {
+	args := []string{"test", "-bench", r.benchFilter, "-benchmem", "-v"}
 
-			// Parse optional B/op
-			if matches[5] != "" {
-				result.BytesPerOp, _ = strconv.ParseInt(matches[5], 10, 64)
-			}
+	if r.noTests {
+		args = append(args, "-run", "^$")
+	}
 
-			// Parse optional allocs/op
-			if matches[6] != "" {
-				result.AllocsPerOp, _ = strconv.ParseInt(matches[6], 10, 64)
-			}
+	// Add CPU flag if specified
+	if r.cpu != "" {
+		args = append(args, "-cpu", r.cpu)
+	}
 
-			results = append(results, result)
+	// Add benchtime flag if specified
+	if benchtime != "" {
+		args = append(args, "-benchtime", benchtime)
+	}
 
-			// Call progress callback with full result after parsing
-			if r.progressCallback != nil {
-				r.progressCallback(result)
-			}
-		}
+	if count > 1 {
+		args = append(args, "-count", strconv.Itoa(count))
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if r.gcflags != "" {
+		args = append(args, "-gcflags", r.gcflags)
 	}
 
-	if len(results) == 0 {
-		return nil, fmt.Errorf("no benchmark results found in output")
+	// go test keeps the test binary next to profiles, in the working
+	// directory unless told otherwise
+	if paths.any() {
+		args = append(args, "-o", filepath.Join(tempDir, "bench.test"+exeSuffix()))
+	}
+	if paths.cpu != "" {
+		args = append(args, "-cpuprofile", paths.cpu)
+	}
+	if paths.mem != "" {
+		args = append(args, "-memprofile", paths.mem)
+	}
+	// go test records every blocking event and contended mutex when
+	// these profiles are requested
+	if paths.block != "" {
+		args = append(args, "-blockprofile", paths.block)
+	}
+	if paths.mutex != "" {
+		args = append(args, "-mutexprofile", paths.mutex)
 	}
 
-	return results, nil
+	if pkg != "" {
+		args = append(args, pkg)
+	} else {
+		args = append(args, "./...")
+	}
+	return args
 }
 
-// parseOutput parses the benchmark output from go test -bench (kept for compatibility)
-func (r *Runner) parseOutput(output string) ([]models.BenchmarkResult, error) {
-	return r.parseOutputRealtime(strings.NewReader(output))
+// withOverlay adds the -overlay flag for the hooks harness, if any, to the
+// go test arguments
+func withOverlay(args []string, overlay string) []string {
+	if overlay == "" {
+		return args
+	}
+	return slices.Insert(slices.Clone(args), 1, "-overlay", overlay)
 }
 
-// getGoVersion returns the current Go version
-func (r *Runner) getGoVersion() (string, error) {
-	cmd := exec.Command("go", "version")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+// warmupArgs builds the go test arguments taking the baseline heap profile
+// of pkg: a single iteration of every benchmark, without the tests
+func (r *Runner) warmupArgs(pkg, tempDir, profilePath string) []string {
+	args := r.testArgs(pkg, tempDir, "1x", 1, profilePaths{mem: profilePath})
+	if !r.noTests {
+		args = slices.Insert(args, 1, "-run", "^$")
 	}
-	return strings.TrimSpace(string(output)), nil
+	return args
 }
 
-// generateID generates a unique ID for a benchmark run
-func generateID() string {
-	return fmt.Sprintf("run-%d", time.Now().Unix())
+// runWarmup runs every benchmark for a single iteration in a go test of its
+// own and writes the heap profile at its end, a one-iteration baseline, to
+// profilePath. Failing benchmarks still leave a usable profile.
+func (r *Runner) runWarmup(ctx context.Context, args []string, profilePath string) error {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = r.dir
+	cmd.WaitDelay = interruptGrace
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if _, statErr := os.Stat(profilePath); statErr == nil {
+		return nil
+	}
+	if err == nil {
+		return fmt.Errorf("go test wrote no heap profile")
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return fmt.Errorf("%w: %s", err, lines[len(lines)-1])
 }
 
 // handleProfiles processes and stores profile files, and analyzes them
-func (r *Runner) handleProfiles(run *models.BenchmarkRun, cpuProfilePath, memProfilePath string) error {
+func (r *Runner) handleProfiles(run *models.BenchmarkRun, paths profilePaths) error {
 	store := r.profileOptions.Storage
 	analyzer := profiler.NewAnalyzer()
+	analyzer.SetCPUSampleType(r.profileOptions.CPUSampleType)
+	analyzer.SetMemorySampleType(r.profileOptions.MemorySampleType)
 
 	// Process CPU profile
-	if cpuProfilePath != "" {
-		if _, err := os.Stat(cpuProfilePath); err == nil {
+	if paths.cpu != "" {
+		if _, err := os.Stat(paths.cpu); err == nil {
 			// Read profile data
-			cpuData, err := os.ReadFile(cpuProfilePath)
+			cpuData, err := os.ReadFile(paths.cpu)
 			if err != nil {
 				return fmt.Errorf("failed to read CPU profile: %w", err)
 			}
@@ -300,10 +695,10 @@ func (r *Runner) handleProfiles(run *models.BenchmarkRun, cpuProfilePath, memPro
 	}
 
 	// Process memory profile
-	if memProfilePath != "" {
-		if _, err := os.Stat(memProfilePath); err == nil {
+	if paths.mem != "" {
+		if _, err := os.Stat(paths.mem); err == nil {
 			// Read profile data
-			memData, err := os.ReadFile(memProfilePath)
+			memData, err := os.ReadFile(paths.mem)
 			if err != nil {
 				return fmt.Errorf("failed to read memory profile: %w", err)
 			}
@@ -323,19 +718,58 @@ func (r *Runner) handleProfiles(run *models.BenchmarkRun, cpuProfilePath, memPro
 		}
 	}
 
+	// Process the one-iteration baseline heap profile
+	if paths.warmup != "" && run.MemoryProfile != "" {
+		warmupData, err := os.ReadFile(paths.warmup)
+		if err != nil {
+			return fmt.Errorf("failed to read warmup heap profile: %w", err)
+		}
+		if err := store.SaveProfile(run.ID, "warmup", bytes.NewReader(warmupData)); err != nil {
+			return fmt.Errorf("failed to save warmup heap profile: %w", err)
+		}
+		run.WarmupProfile = store.GetWarmupProfilePath(run.ID)
+		if err := analyzer.LoadWarmupProfile(warmupData); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to analyze warmup heap profile: %v\n", err)
+		}
+	}
+
+	// Process the block and mutex profiles
+	if paths.block != "" {
+		if blockData, err := os.ReadFile(paths.block); err == nil {
+			if err := store.SaveProfile(run.ID, "block", bytes.NewReader(blockData)); err != nil {
+				return fmt.Errorf("failed to save block profile: %w", err)
+			}
+			run.BlockProfile = store.GetBlockProfilePath(run.ID)
+			if err := analyzer.LoadBlockProfile(blockData); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze block profile: %v\n", err)
+			}
+		}
+	}
+	if paths.mutex != "" {
+		if mutexData, err := os.ReadFile(paths.mutex); err == nil {
+			if err := store.SaveProfile(run.ID, "mutex", bytes.NewReader(mutexData)); err != nil {
+				return fmt.Errorf("failed to save mutex profile: %w", err)
+			}
+			run.MutexProfile = store.GetMutexProfilePath(run.ID)
+			if err := analyzer.LoadMutexProfile(mutexData); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze mutex profile: %v\n", err)
+			}
+		}
+	}
+
 	// Analyze profiles and generate summary
-	if run.CPUProfile != "" || run.MemoryProfile != "" {
+	if run.CPUProfile != "" || run.MemoryProfile != "" || run.BlockProfile != "" || run.MutexProfile != "" {
 		summary, err := analyzer.Analyze()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to analyze profiles: %v\n", err)
 		} else {
 			// Enhance with AI analysis if enabled
-			aiAnalyzer, err := aianalyzer.NewFromEnv()
+			aiAnalyzer, err := aianalyzer.NewAnalyzer(aianalyzer.ConfigFromEnv(r.aiDefaults))
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to initialize AI analyzer: %v\n", err)
 				run.ProfileSummary = summary
 			} else {
-				enhanced, err := aiAnalyzer.EnhanceProfileSummary(summary)
+				enhanced, err := aiAnalyzer.WithCache(store, false).EnhanceRunProfile(run.ID, summary)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Warning: AI analysis failed: %v\n", err)
 					run.ProfileSummary = summary
@@ -343,8 +777,33 @@ func (r *Runner) handleProfiles(run *models.BenchmarkRun, cpuProfilePath, memPro
 					run.ProfileSummary = enhanced
 				}
 			}
+			SetContention(run)
 		}
 	}
 
 	return nil
 }
+
+// SetContention sets the contention per operation of the results from the
+// block and mutex delay of their benchmark function in the profile summary
+func SetContention(run *models.BenchmarkRun) {
+	if run.BlockProfile == "" && run.MutexProfile == "" {
+		return
+	}
+	delays := run.ProfileSummary.BenchmarkContention
+
+	iterations := make(map[string]int64)
+	for _, result := range run.Results {
+		if result.Measured() {
+			iterations[result.Family()] += result.Iterations
+		}
+	}
+	for i := range run.Results {
+		result := &run.Results[i]
+		family := result.Family()
+		if !result.Measured() || iterations[family] == 0 {
+			continue
+		}
+		result.ContentionNsPerOp = float64(delays[family]) / float64(iterations[family])
+	}
+}
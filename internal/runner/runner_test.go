@@ -1,6 +1,10 @@
 package runner
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -191,8 +195,8 @@ func TestNewRunner(t *testing.T) {
 	}
 }
 
-func TestGenerateID(t *testing.T) {
-	id1 := generateID()
+func TestNewID(t *testing.T) {
+	id1 := NewRunner("", "").newID()
 
 	if !strings.HasPrefix(id1, "run-") {
 		t.Errorf("Expected ID to start with 'run-', got %s", id1)
@@ -203,16 +207,59 @@ func TestGenerateID(t *testing.T) {
 		t.Errorf("Expected ID length > 5, got %d", len(id1))
 	}
 
-	// Test that format is consistent (run-<timestamp>)
+	// Test that format is consistent (run-<ulid>)
 	parts := strings.Split(id1, "-")
 	if len(parts) != 2 {
-		t.Errorf("Expected ID format 'run-<timestamp>', got %s", id1)
+		t.Errorf("Expected ID format 'run-<ulid>', got %s", id1)
 	}
 	if parts[0] != "run" {
 		t.Errorf("Expected ID prefix 'run', got %s", parts[0])
 	}
 }
 
+func TestWithoutTests(t *testing.T) {
+	args := NewRunner("./pkg", "^BenchmarkHot$").testArgs("./pkg", t.TempDir(), "", 1, profilePaths{})
+	if slices.Contains(args, "-run") {
+		t.Errorf("Expected tests to run by default, got %v", args)
+	}
+
+	args = NewRunner("./pkg", "^BenchmarkHot$").WithoutTests().testArgs("./pkg", t.TempDir(), "", 1, profilePaths{})
+	if i := slices.Index(args, "-run"); i < 0 || args[i+1] != "^$" {
+		t.Errorf("Expected -run ^$ to skip tests, got %v", args)
+	}
+}
+
+func TestWarmupArgs(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, r := range []*Runner{NewRunner("./pkg", "."), NewRunner("./pkg", ".").WithoutTests()} {
+		args := r.warmupArgs("./pkg", tempDir, filepath.Join(tempDir, "mem-warmup.prof"))
+		if i := slices.Index(args, "-run"); i < 0 || args[i+1] != "^$" || slices.Index(args[i+1:], "-run") >= 0 {
+			t.Errorf("Expected the tests skipped once, got %v", args)
+		}
+		if i := slices.Index(args, "-benchtime"); i < 0 || args[i+1] != "1x" {
+			t.Errorf("Expected a single iteration, got %v", args)
+		}
+	}
+}
+
+func TestTestArgsContentionProfiles(t *testing.T) {
+	tempDir := t.TempDir()
+	args := NewRunner("./pkg", ".").testArgs("./pkg", tempDir, "", 1, profilePaths{
+		block: filepath.Join(tempDir, "block.prof"),
+		mutex: filepath.Join(tempDir, "mutex.prof"),
+	})
+	if i := slices.Index(args, "-blockprofile"); i < 0 || args[i+1] != filepath.Join(tempDir, "block.prof") {
+		t.Errorf("Expected -blockprofile, got %v", args)
+	}
+	if i := slices.Index(args, "-mutexprofile"); i < 0 || args[i+1] != filepath.Join(tempDir, "mutex.prof") {
+		t.Errorf("Expected -mutexprofile, got %v", args)
+	}
+	// The test binary is kept with the profiles
+	if !slices.Contains(args, "-o") || slices.Contains(args, "-cpuprofile") {
+		t.Errorf("Expected -o and no CPU profile, got %v", args)
+	}
+}
+
 func TestWithProfiling(t *testing.T) {
 	r := NewRunner("./test", ".")
 
@@ -308,6 +355,25 @@ func TestRunWithActualBenchmarks(t *testing.T) {
 	}
 }
 
+func TestRunWithDir(t *testing.T) {
+	// The package path is relative to the directory the benchmarks run in
+	r := NewRunner("./examples", "BenchmarkStringConcatenation$").WithDir("../..").WithBenchtime("1x")
+
+	run, err := r.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(run.Results) == 0 {
+		t.Fatal("Expected at least one benchmark result")
+	}
+	if run.Dependencies == nil {
+		t.Error("Expected dependencies of the module in the directory")
+	}
+	if got := r.localPackagePath(); got != filepath.Join("..", "..", "examples") {
+		t.Errorf("Expected the package path relative to the current directory, got %q", got)
+	}
+}
+
 func TestRunWithProfiling(t *testing.T) {
 	tempDir := t.TempDir()
 	store := storage.NewStorage(tempDir)
@@ -334,6 +400,22 @@ func TestRunWithProfiling(t *testing.T) {
 		// It's okay if profiles weren't generated for quick tests
 		t.Log("No profiles generated (benchmarks may have been too quick)")
 	}
+
+	// A heap profile is also taken after warmup, by a separate pass that
+	// is not part of the recorded command
+	if run.MemoryProfile != "" {
+		if !store.HasProfile(run.ID, "warmup") || run.WarmupProfile == "" {
+			t.Error("Expected a warmup heap profile next to the memory profile")
+		}
+		if strings.Contains(run.Command, "1x") {
+			t.Errorf("Expected the warmup pass not to be recorded, got %q", run.Command)
+		}
+	}
+
+	// The test binary kept for profiling is built in a temporary directory
+	if matches, _ := filepath.Glob("*.test" + exeSuffix()); len(matches) > 0 {
+		t.Errorf("Expected no test binary in the working directory, found %v", matches)
+	}
 }
 
 func TestRunWithInvalidPackage(t *testing.T) {
@@ -675,3 +757,90 @@ func TestProgressAndVerboseNotBothSet(t *testing.T) {
 		t.Error("Expected verbose output to be written")
 	}
 }
+
+func TestSetContention(t *testing.T) {
+	run := &models.BenchmarkRun{
+		MutexProfile: "mutex.prof",
+		Results: []models.BenchmarkResult{
+			{Name: "Get/small-8", Parent: "Get", Iterations: 300},
+			{Name: "Get/large-8", Parent: "Get", Iterations: 100},
+			{Name: "Put-8", Iterations: 1000},
+			{Name: "Del-8", Status: models.StatusFailed},
+		},
+		ProfileSummary: &models.ProfileSummary{
+			BenchmarkContention: map[string]int64{"Get": 2000, "Del": 500},
+		},
+	}
+
+	SetContention(run)
+
+	// The delay of a benchmark function is spread over all its results
+	for _, i := range []int{0, 1} {
+		if got := run.Results[i].ContentionNsPerOp; got != 5 {
+			t.Errorf("%s: expected 5ns of contention per op, got %v", run.Results[i].Name, got)
+		}
+	}
+	if got := run.Results[2].ContentionNsPerOp; got != 0 {
+		t.Errorf("Expected no contention in Put, got %v", got)
+	}
+	if got := run.Results[3].ContentionNsPerOp; got != 0 {
+		t.Errorf("Expected no contention for a failed benchmark, got %v", got)
+	}
+}
+
+func TestRunPanickingBenchmark(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/panics\n\ngo 1.21\n",
+		"panics_test.go": `package panics
+
+import "testing"
+
+func BenchmarkFine(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+	}
+}
+
+func BenchmarkBroken(b *testing.B) {
+	var s []int
+	_ = s[len(s)+1]
+}
+
+func BenchmarkNeverRuns(b *testing.B) {}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run, err := NewRunner(".", ".").WithDir(dir).WithBenchtime("1x").WithoutTests().Run()
+	if err != nil {
+		t.Fatalf("Expected the results before the panic to be kept, got %v", err)
+	}
+	if !run.Failed() {
+		t.Fatal("Expected the run to be marked failed")
+	}
+	if !strings.HasPrefix(run.Error, "BenchmarkBroken: panic: ") {
+		t.Errorf("Error = %q, want the panic of BenchmarkBroken", run.Error)
+	}
+	if !strings.Contains(run.Stderr+run.Error, "index out of range") {
+		t.Errorf("Expected the panic message to be captured, got %q", run.Stderr)
+	}
+	if len(run.Results) == 0 || run.Results[0].Name != "Fine" || !run.Results[0].Measured() {
+		t.Errorf("Expected BenchmarkFine to be recorded, got %+v", run.Results)
+	}
+}
+
+func TestMarkFailed(t *testing.T) {
+	run := &models.BenchmarkRun{Results: []models.BenchmarkResult{{Name: "Fine", NsPerOp: 10}}}
+	markFailed(run, errors.New("signal: killed"), strings.Repeat("x", maxStderr+10))
+
+	if !run.Failed() || run.Error != "go test terminated abnormally: signal: killed" {
+		t.Errorf("Unexpected failure: %q %q", run.Status, run.Error)
+	}
+	if !strings.HasPrefix(run.Stderr, "...\n") || len(run.Stderr) != maxStderr+4 {
+		t.Errorf("Expected standard error truncated to its end, got %d bytes", len(run.Stderr))
+	}
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"math"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// mergeSamples merges the repetitions of each benchmark reported with
+// -count into a single result, in order of first appearance. The ns/op
+// values are kept as samples and the measurements are averaged. A
+// benchmark with a failed or skipped repetition is reported as such.
+func mergeSamples(results []models.BenchmarkResult) []models.BenchmarkResult {
+	type key struct{ pkg, name string }
+	var order []key
+	groups := make(map[key][]models.BenchmarkResult)
+	for _, result := range results {
+		k := key{result.Package, result.Name}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], result)
+	}
+
+	merged := make([]models.BenchmarkResult, 0, len(order))
+	for _, k := range order {
+		merged = append(merged, mergeRepetitions(groups[k]))
+	}
+	return merged
+}
+
+// mergeRepetitions merges the results of one benchmark
+func mergeRepetitions(reps []models.BenchmarkResult) models.BenchmarkResult {
+	for _, rep := range reps {
+		if !rep.Measured() {
+			return rep
+		}
+	}
+	if len(reps) == 1 {
+		return reps[0]
+	}
+
+	n := float64(len(reps))
+	result := models.BenchmarkResult{
+		Name:    reps[0].Name,
+		Package: reps[0].Package,
+		Parent:  reps[0].Parent,
+		Variant: reps[0].Variant,
+		Status:  reps[0].Status,
+		Samples: make([]float64, len(reps)),
+	}
+	var iterations, bytesPerOp, allocsPerOp float64
+	for i, rep := range reps {
+		result.Samples[i] = rep.NsPerOp
+		result.NsPerOp += rep.NsPerOp / n
+		result.MBPerSec += rep.MBPerSec / n
+		iterations += float64(rep.Iterations)
+		bytesPerOp += float64(rep.BytesPerOp)
+		allocsPerOp += float64(rep.AllocsPerOp)
+		for metric, value := range rep.Metrics {
+			if result.Metrics == nil {
+				result.Metrics = make(map[string]float64)
+			}
+			result.Metrics[metric] += value / n
+		}
+	}
+	result.Iterations = int64(math.Round(iterations / n))
+	result.BytesPerOp = int64(math.Round(bytesPerOp / n))
+	result.AllocsPerOp = int64(math.Round(allocsPerOp / n))
+	return result
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestMergeSamples(t *testing.T) {
+	results := []models.BenchmarkResult{
+		{Name: "Fast-8", Iterations: 1000, NsPerOp: 100, BytesPerOp: 16, AllocsPerOp: 1, Metrics: map[string]float64{"rps": 10}},
+		{Name: "Slow-8", Iterations: 10, NsPerOp: 5000},
+		{Name: "Fast-8", Iterations: 1001, NsPerOp: 110, BytesPerOp: 17, AllocsPerOp: 1, Metrics: map[string]float64{"rps": 20}},
+		{Name: "Slow-8", Iterations: 10, NsPerOp: 6000},
+		{Name: "Flaky-8", Iterations: 100, NsPerOp: 50},
+		{Name: "Flaky-8", Status: models.StatusFailed, Message: "boom"},
+		{Name: "Once-8", Iterations: 5, NsPerOp: 42},
+	}
+
+	merged := mergeSamples(results)
+
+	var names []string
+	for _, result := range merged {
+		names = append(names, result.Name)
+	}
+	if want := []string{"Fast-8", "Slow-8", "Flaky-8", "Once-8"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Expected benchmarks %v in order of first appearance, got %v", want, names)
+	}
+
+	fast := merged[0]
+	if fast.NsPerOp != 105 || !reflect.DeepEqual(fast.Samples, []float64{100, 110}) {
+		t.Errorf("Expected mean 105 of samples [100 110], got %v of %v", fast.NsPerOp, fast.Samples)
+	}
+	if fast.Iterations != 1001 || fast.BytesPerOp != 17 || fast.AllocsPerOp != 1 {
+		t.Errorf("Expected rounded averages 1001 iterations, 17 B/op, 1 allocs/op, got %+v", fast)
+	}
+	if fast.Metrics["rps"] != 15 {
+		t.Errorf("Expected averaged metric 15, got %v", fast.Metrics["rps"])
+	}
+
+	if flaky := merged[2]; flaky.Status != models.StatusFailed || flaky.Message != "boom" || flaky.Samples != nil {
+		t.Errorf("Expected the failed repetition to be reported, got %+v", flaky)
+	}
+	if once := merged[3]; once.NsPerOp != 42 || once.Samples != nil {
+		t.Errorf("Expected a single repetition to be kept without samples, got %+v", once)
+	}
+}
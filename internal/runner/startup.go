@@ -0,0 +1,401 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Defaults of a startup-time run
+const (
+	DefaultStartupIterations = 20
+	DefaultStartupTimeout    = 30 * time.Second
+)
+
+// probeInterval is how long a process that is not ready yet is left alone
+// before its readiness probe is tried again. It bounds the error of the
+// measured startup time.
+const probeInterval = time.Millisecond
+
+// StartupOptions configure a startup-time run, which builds the main
+// package of the runner and times how long processes started from the
+// binary take to become ready
+type StartupOptions struct {
+	Args       []string      // Arguments of the processes
+	Iterations int           // Processes started one after the other (default 20)
+	Ready      ReadyProbe    // When a process is ready (default: when it exits)
+	Timeout    time.Duration // Longest a process may take to become ready (default 30s)
+}
+
+// ReadyProbe tells when a started process is ready. The zero probe waits
+// for the process to exit successfully, which suits command-line tools.
+type ReadyProbe struct {
+	spec    string
+	kind    string // "exit", "http", "tcp" or "output"
+	target  string // URL of "http", address of "tcp"
+	pattern *regexp.Regexp
+}
+
+// ParseReadyProbe parses a readiness probe:
+//
+//	exit                    the process exited with status 0
+//	http://host:port/path   the URL answers with a 2xx or 3xx status
+//	tcp://host:port         the address accepts connections
+//	output:<regexp>         a line of standard output or error matches
+func ParseReadyProbe(spec string) (ReadyProbe, error) {
+	probe := ReadyProbe{spec: spec}
+	switch {
+	case spec == "" || spec == "exit":
+		probe.spec, probe.kind = "exit", "exit"
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		u, err := url.Parse(spec)
+		if err != nil || u.Host == "" {
+			return ReadyProbe{}, fmt.Errorf("invalid URL %q", spec)
+		}
+		probe.kind, probe.target = "http", spec
+	case strings.HasPrefix(spec, "tcp://"):
+		addr := strings.TrimPrefix(spec, "tcp://")
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return ReadyProbe{}, fmt.Errorf("invalid address %q: %w", addr, err)
+		}
+		probe.kind, probe.target = "tcp", addr
+	case strings.HasPrefix(spec, "output:"):
+		pattern, err := regexp.Compile(strings.TrimPrefix(spec, "output:"))
+		if err != nil {
+			return ReadyProbe{}, fmt.Errorf("invalid output pattern: %w", err)
+		}
+		probe.kind, probe.pattern = "output", pattern
+	default:
+		return ReadyProbe{}, fmt.Errorf("unknown probe %q", spec)
+	}
+	return probe, nil
+}
+
+// String returns the probe as given to ParseReadyProbe
+func (p ReadyProbe) String() string {
+	if p.spec == "" {
+		return "exit"
+	}
+	return p.spec
+}
+
+// WithStartup measures the startup time of the main package of the runner
+// instead of running its benchmarks
+func (r *Runner) WithStartup(opts StartupOptions) *Runner {
+	if opts.Iterations < 1 {
+		opts.Iterations = DefaultStartupIterations
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultStartupTimeout
+	}
+	r.startup = &opts
+	return r
+}
+
+// runStartup builds the main package and starts a process from it for
+// each iteration. The time from starting a process to its readiness is a
+// sample of a result named "Startup/<program>", so that startup times are
+// compared, checked and trended like benchmarks.
+func (r *Runner) runStartup() (*models.BenchmarkRun, error) {
+	startTime := time.Now()
+	opts := r.startup
+
+	environment := collectEnvironment()
+	goVersion, err := r.getGoVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Go version: %w", err)
+	}
+	runID := r.newID()
+
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tempDir, err := os.MkdirTemp("", "gokanon-startup-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer removeTempDir(tempDir)
+
+	importPath, err := r.mainPackage()
+	if err != nil {
+		return nil, err
+	}
+	binary := filepath.Join(tempDir, path.Base(importPath)+exeSuffix())
+	buildArgs := []string{"build", "-o", binary}
+	if r.gcflags != "" {
+		buildArgs = append(buildArgs, "-gcflags="+r.gcflags)
+	}
+	buildArgs = append(buildArgs, r.packagePath)
+	build := exec.CommandContext(ctx, "go", buildArgs...)
+	build.Dir = r.dir
+	var buildErr bytes.Buffer
+	build.Stderr = &buildErr
+	if err := build.Run(); err != nil {
+		return nil, fmt.Errorf("failed to build %s: %w\n%s", importPath, err, strings.TrimSpace(buildErr.String()))
+	}
+
+	name := "Startup/" + path.Base(importPath)
+	if r.liveStore != nil {
+		r.live = newLiveTracker(r.liveStore, r.packagePath)
+		defer func() {
+			r.live.finish()
+			r.live = nil
+		}()
+		r.live.started(name)
+	}
+	if r.startCallback != nil {
+		r.startCallback(name)
+	}
+
+	samples := make([]float64, 0, opts.Iterations)
+	for i := range opts.Iterations {
+		elapsed, err := r.startProcess(ctx, binary, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("benchmark run interrupted: %w", ctx.Err())
+			}
+			return nil, fmt.Errorf("process %d of %d: %w", i+1, opts.Iterations, err)
+		}
+		samples = append(samples, float64(elapsed.Nanoseconds()))
+		if r.verboseWriter != nil {
+			fmt.Fprintf(r.verboseWriter, "%s: process %d ready in %s\n", name, i+1, elapsed)
+		}
+	}
+
+	result := models.BenchmarkResult{
+		Name:       name,
+		Package:    importPath,
+		Iterations: int64(len(samples)),
+	}
+	for _, sample := range samples {
+		result.NsPerOp += sample / float64(len(samples))
+	}
+	if len(samples) > 1 {
+		result.Samples = samples
+	}
+	setFamily(&result)
+
+	if r.live != nil {
+		r.live.completed(result)
+	}
+	if r.progressCallback != nil {
+		r.progressCallback(result)
+	}
+
+	run := &models.BenchmarkRun{
+		ID:            runID,
+		Timestamp:     startTime,
+		Package:       r.packagePath,
+		GoVersion:     goVersion,
+		Results:       []models.BenchmarkResult{result},
+		Command:       fmt.Sprintf("go %s", strings.Join(buildArgs, " ")),
+		Duration:      time.Since(startTime),
+		Commit:        getCommit(r.localPackagePath()),
+		CommitMessage: getCommitSubject(r.localPackagePath()),
+		Environment:   environment,
+		Startup: &models.Startup{
+			Args:       opts.Args,
+			Ready:      opts.Ready.String(),
+			Iterations: opts.Iterations,
+		},
+	}
+
+	if toolchain, err := r.getToolchain(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record toolchain settings: %v\n", err)
+	} else {
+		run.Toolchain = toolchain
+	}
+	if deps, err := CollectDependencies(r.localPackagePath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record dependencies: %v\n", err)
+	} else {
+		run.Dependencies = deps
+	}
+	return run, nil
+}
+
+// mainPackage returns the import path of the package of the runner, which
+// must be a single main package
+func (r *Runner) mainPackage() (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}}\t{{.Name}}", r.packagePath)
+	cmd.Dir = r.dir
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list %s: %w: %s", r.packagePath, err, strings.TrimSpace(stderr.String()))
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 1 {
+		return "", fmt.Errorf("%s matches %d packages, startup time is measured for one program", r.packagePath, len(lines))
+	}
+	importPath, name, _ := strings.Cut(lines[0], "\t")
+	if name != "main" {
+		return "", fmt.Errorf("%s is package %s, not a program: point -startup at a main package", importPath, name)
+	}
+	return importPath, nil
+}
+
+// startProcess starts a process from binary and returns how long it took
+// to become ready. Processes that are still running once ready are killed.
+func (r *Runner) startProcess(ctx context.Context, binary string, opts *StartupOptions) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	cmd := exec.Command(binary, opts.Args...)
+	cmd.Dir = r.dir
+	output := &outputWatcher{pattern: opts.Ready.pattern, matched: make(chan struct{})}
+	cmd.Stdout, cmd.Stderr = output, output
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	stop := func() {
+		cmd.Process.Kill()
+		<-exited
+	}
+
+	if opts.Ready.kind == "exit" || opts.Ready.kind == "" {
+		select {
+		case err := <-exited:
+			elapsed := time.Since(start)
+			if err != nil {
+				return 0, processError(err, output)
+			}
+			return elapsed, nil
+		case <-ctx.Done():
+			stop()
+			return 0, fmt.Errorf("not done after %s", opts.Timeout)
+		}
+	}
+
+	for {
+		if opts.Ready.ready(ctx, output) {
+			elapsed := time.Since(start)
+			stop()
+			return elapsed, nil
+		}
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("exited")
+			}
+			return 0, fmt.Errorf("not ready before the process ended: %w", processError(err, output))
+		case <-ctx.Done():
+			stop()
+			return 0, fmt.Errorf("not ready after %s (probe %s)", opts.Timeout, opts.Ready)
+		case <-output.matched:
+		case <-time.After(probeInterval):
+		}
+	}
+}
+
+// ready tries the probe once
+func (p ReadyProbe) ready(ctx context.Context, output *outputWatcher) bool {
+	switch p.kind {
+	case "http":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.target, nil)
+		if err != nil {
+			return false
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode >= 200 && resp.StatusCode < 400
+	case "tcp":
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", p.target)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	case "output":
+		select {
+		case <-output.matched:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// processError describes how a process failed, with the end of its output
+func processError(err error, output *outputWatcher) error {
+	if tail := output.tail(); tail != "" {
+		return fmt.Errorf("%w: %s", err, tail)
+	}
+	return err
+}
+
+// maxStartupOutput is how much of the output of a process is kept to
+// explain its failure
+const maxStartupOutput = 4096
+
+// outputWatcher keeps the end of the output of a process and closes
+// matched once a line matches the pattern of an output probe
+type outputWatcher struct {
+	pattern *regexp.Regexp
+	matched chan struct{}
+
+	mu      sync.Mutex
+	line    []byte // Incomplete last line
+	last    []byte // End of the output
+	didFind bool
+}
+
+func (w *outputWatcher) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.last = append(w.last, data...)
+	if len(w.last) > maxStartupOutput {
+		w.last = w.last[len(w.last)-maxStartupOutput:]
+	}
+	if w.pattern == nil || w.didFind {
+		return len(data), nil
+	}
+	w.line = append(w.line, data...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		if w.pattern.Match(w.line[:i]) {
+			w.didFind = true
+			close(w.matched)
+			break
+		}
+		w.line = w.line[i+1:]
+	}
+	return len(data), nil
+}
+
+// tail returns the last line of output
+func (w *outputWatcher) tail() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lines := strings.Split(strings.TrimSpace(string(w.last)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// serverProgram listens on a free port after a short delay, prints its
+// address and serves until killed
+const serverProgram = `package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+func main() {
+	time.Sleep(5 * time.Millisecond)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("listening on", listener.Addr())
+	http.Serve(listener, nil)
+}
+`
+
+func TestParseReadyProbe(t *testing.T) {
+	tests := []struct {
+		spec, kind string
+		wantErr    bool
+	}{
+		{"", "exit", false},
+		{"exit", "exit", false},
+		{"http://localhost:8080/healthz", "http", false},
+		{"tcp://localhost:8080", "tcp", false},
+		{"output:listening on", "output", false},
+		{"tcp://localhost", "", true},
+		{"output:([", "", true},
+		{"ftp://host", "", true},
+	}
+	for _, tt := range tests {
+		probe, err := ParseReadyProbe(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseReadyProbe(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if probe.kind != tt.kind {
+			t.Errorf("ParseReadyProbe(%q) kind = %q, want %q", tt.spec, probe.kind, tt.kind)
+		}
+	}
+}
+
+func TestRunStartup(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":             "module example.com/startup\n\ngo 1.21\n",
+		"cmd/server/main.go": serverProgram,
+		"cmd/tool/main.go":   "package main\n\nfunc main() {}\n",
+		"lib/lib.go":         "package lib\n",
+	})
+
+	probe, err := ParseReadyProbe("output:^listening on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := NewRunner("./cmd/server", "").WithDir(dir).
+		WithStartup(StartupOptions{Iterations: 3, Ready: probe}).Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(run.Results) != 1 {
+		t.Fatalf("Results = %+v, want one", run.Results)
+	}
+	result := run.Results[0]
+	if result.Name != "Startup/server" || result.Package != "example.com/startup/cmd/server" || result.Parent != "Startup" {
+		t.Errorf("Result = %+v", result)
+	}
+	if len(result.Samples) != 3 || result.Iterations != 3 {
+		t.Errorf("Samples = %v, iterations %d, want 3", result.Samples, result.Iterations)
+	}
+	if result.NsPerOp < float64(5*time.Millisecond) {
+		t.Errorf("NsPerOp = %v, want at least the delay of the program", result.NsPerOp)
+	}
+	if run.Startup == nil || run.Startup.Ready != "output:^listening on" || run.Startup.Iterations != 3 {
+		t.Errorf("Startup = %+v", run.Startup)
+	}
+
+	// A command-line tool is ready when it exits
+	run, err = NewRunner("./cmd/tool", "").WithDir(dir).WithStartup(StartupOptions{Iterations: 2}).Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if run.Results[0].Name != "Startup/tool" || run.Startup.Ready != "exit" {
+		t.Errorf("Result %+v, startup %+v", run.Results[0], run.Startup)
+	}
+
+	// Only programs can be started
+	_, err = NewRunner("./lib", "").WithDir(dir).WithStartup(StartupOptions{}).Run()
+	if err == nil || !strings.Contains(err.Error(), "not a program") {
+		t.Errorf("Run of a library error = %v", err)
+	}
+}
+
+func TestRunStartupNeverReady(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod":  "module example.com/startup\n\ngo 1.21\n",
+		"main.go": "package main\n\nimport \"os\"\n\nfunc main() {\n\tprintln(\"no config file\")\n\tos.Exit(2)\n}\n",
+	})
+
+	probe, err := ParseReadyProbe("tcp://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewRunner(".", "").WithDir(dir).WithStartup(StartupOptions{Iterations: 1, Ready: probe, Timeout: 5 * time.Second}).Run()
+	if err == nil || !strings.Contains(err.Error(), "not ready before the process ended") || !strings.Contains(err.Error(), "no config file") {
+		t.Errorf("error = %v, want the exit of the process with its output", err)
+	}
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// parallelBenchmarks returns the names, without the "Benchmark" prefix, of
+// the benchmark functions in the packages matching pkg, resolved from dir,
+// that call RunParallel themselves or in the closures they declare, such
+// as sub-benchmarks
+func parallelBenchmarks(dir, pkg string) ([]string, error) {
+	packages, err := listTestPackages(dir, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, p := range packages {
+		for _, path := range append(p.TestGoFiles, p.XTestGoFiles...) {
+			file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || fn.Body == nil || !strings.HasPrefix(fn.Name.Name, "Benchmark") {
+					continue
+				}
+				if callsRunParallel(fn.Body) {
+					found[strings.TrimPrefix(fn.Name.Name, "Benchmark")] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// callsRunParallel reports whether a function body calls a RunParallel
+// method, or a helper named like one such as bench.RunParallelWith
+func callsRunParallel(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && strings.HasPrefix(sel.Sel.Name, "RunParallel") {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// stressFilter returns the -bench filter selecting the named benchmark
+// functions and their sub-benchmarks
+func stressFilter(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return "^Benchmark(" + strings.Join(quoted, "|") + ")$"
+}
+
+// prepareStress finds the benchmarks of a stress run calling RunParallel,
+// and limits the run to them unless the filter picks other benchmarks
+func (r *Runner) prepareStress() {
+	benchmarks, err := parallelBenchmarks(r.dir, r.packagePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to find the benchmarks calling RunParallel: %v\n", err)
+		return
+	}
+	r.stress.Benchmarks = benchmarks
+	if r.benchFilter != "." {
+		return
+	}
+	if len(benchmarks) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no benchmark calls RunParallel, stressing all of them\n")
+		return
+	}
+	r.benchFilter = stressFilter(benchmarks)
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParallelBenchmarks(t *testing.T) {
+	dir := t.TempDir()
+	source := `package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func BenchmarkGet(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+		}
+	})
+}
+
+func BenchmarkSizes(b *testing.B) {
+	for _, n := range []int{1, 64} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkPooled(b *testing.B) {
+	var h helpers
+	h.RunParallelWith(b)
+}
+
+func BenchmarkSerial(b *testing.B) {}
+
+type helpers struct{}
+
+func (helpers) RunParallelWith(b *testing.B) {}
+`
+	files := map[string]string{
+		"go.mod":        "module example.com/cache\n\ngo 1.21\n",
+		"cache_test.go": source,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err := parallelBenchmarks(dir, "./...")
+	if err != nil {
+		t.Fatalf("parallelBenchmarks failed: %v", err)
+	}
+	if strings.Join(names, ",") != "Get,Pooled,Sizes" {
+		t.Errorf("Expected Get, Pooled and Sizes, got %v", names)
+	}
+}
+
+func TestStressFilter(t *testing.T) {
+	filter := regexp.MustCompile(stressFilter([]string{"Get", "Cache.Put"}))
+	for name, want := range map[string]bool{
+		"BenchmarkGet":       true,
+		"BenchmarkCache.Put": true,
+		"BenchmarkGetAll":    false,
+		"BenchmarkCacheXPut": false,
+	} {
+		if filter.MatchString(name) != want {
+			t.Errorf("%s: match = %v, want %v", name, !want, want)
+		}
+	}
+}
+
+func TestWithStress(t *testing.T) {
+	r := NewRunner("./pkg", ".").WithStress([]int{1, 4, 16})
+	args := r.testArgs("./pkg", t.TempDir(), "", 1, profilePaths{})
+	i := slices.Index(args, "-cpu")
+	if i < 0 || args[i+1] != "1,4,16" {
+		t.Errorf("Expected -cpu 1,4,16, got %v", args)
+	}
+	if r.stress.CPUs == 0 || !slices.Equal(r.stress.Levels, []int{1, 4, 16}) {
+		t.Errorf("Unexpected stress matrix: %+v", r.stress)
+	}
+}
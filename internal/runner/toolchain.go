@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// toolchainVars are the go env variables recorded with each run
+var toolchainVars = []string{"GOOS", "GOARCH", "GOAMD64", "GOARM", "GOARM64", "CGO_ENABLED", "GOFLAGS"}
+
+// getToolchain returns the build settings benchmarks are compiled with
+func (r *Runner) getToolchain() (*models.Toolchain, error) {
+	cmd := exec.Command("go", append([]string{"env", "-json"}, toolchainVars...)...)
+	cmd.Dir = r.dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseToolchain(output, r.gcflags)
+}
+
+// parseToolchain builds the toolchain settings from the output of
+// 'go env -json'. Explicit gcflags take precedence over those in GOFLAGS,
+// as on the go command line.
+func parseToolchain(goEnv []byte, gcflags string) (*models.Toolchain, error) {
+	var env map[string]string
+	if err := json.Unmarshal(goEnv, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse go env: %w", err)
+	}
+
+	tc := &models.Toolchain{
+		GOOS:       env["GOOS"],
+		GOARCH:     env["GOARCH"],
+		CGOEnabled: env["CGO_ENABLED"],
+		GOFLAGS:    env["GOFLAGS"],
+		GCFlags:    gcflags,
+	}
+	switch tc.GOARCH {
+	case "amd64":
+		tc.GOAMD64 = env["GOAMD64"]
+	case "arm":
+		tc.GOARM = env["GOARM"]
+	case "arm64":
+		tc.GOARM64 = env["GOARM64"]
+	}
+	if tc.GCFlags == "" {
+		tc.GCFlags = goflagsValue(tc.GOFLAGS, "gcflags")
+	}
+	return tc, nil
+}
+
+// goflagsValue returns the value of a flag set in GOFLAGS, e.g. -gcflags=-N
+func goflagsValue(goflags, name string) string {
+	value := ""
+	for _, flag := range strings.Fields(goflags) {
+		flag = strings.TrimLeft(flag, "-")
+		if v, ok := strings.CutPrefix(flag, name+"="); ok {
+			value = v // The last occurrence wins
+		}
+	}
+	return value
+}
@@ -0,0 +1,35 @@
+package runner
+
+import "testing"
+
+func TestParseToolchain(t *testing.T) {
+	goEnv := []byte(`{
+		"CGO_ENABLED": "0",
+		"GOAMD64": "v3",
+		"GOARCH": "amd64",
+		"GOARM": "",
+		"GOARM64": "v8.0",
+		"GOFLAGS": "-mod=mod -gcflags=all=-N",
+		"GOOS": "linux"
+	}`)
+
+	tc, err := parseToolchain(goEnv, "")
+	if err != nil {
+		t.Fatalf("parseToolchain failed: %v", err)
+	}
+	if tc.GOAMD64 != "v3" || tc.GOARM64 != "" || tc.CGOEnabled != "0" {
+		t.Errorf("unexpected settings: %+v", tc)
+	}
+	if tc.GCFlags != "all=-N" {
+		t.Errorf("GCFlags = %q, want the value from GOFLAGS", tc.GCFlags)
+	}
+
+	tc, _ = parseToolchain(goEnv, "-B")
+	if tc.GCFlags != "-B" {
+		t.Errorf("GCFlags = %q, want the explicit flag", tc.GCFlags)
+	}
+
+	if _, err := parseToolchain([]byte("not json"), ""); err == nil {
+		t.Error("expected an error for invalid go env output")
+	}
+}
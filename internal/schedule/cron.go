@@ -0,0 +1,190 @@
+// Package schedule parses the cron expressions of the daemon's scheduled
+// runs and computes when they are next due. It supports the five standard
+// fields with lists, ranges and steps, the @hourly, @daily, @weekly and
+// @monthly shorthands, and "@every <duration>" for fixed intervals.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLookahead bounds the search for the next time of an expression that
+// never matches, such as the 31st of February
+const maxLookahead = 5 * 366 * 24 * time.Hour
+
+// shorthands are the named expressions
+var shorthands = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// field is the set of values a cron field matches
+type field uint64
+
+// Cron is a parsed cron expression
+type Cron struct {
+	spec                          string
+	every                         time.Duration // Fixed interval of @every; the fields are unused
+	minute, hour, dom, month, dow field
+	domRestricted, dowRestricted  bool // Fields other than *, combined with OR as in cron
+}
+
+// Parse parses a cron expression: minute, hour, day of month, month and
+// day of week (0 or 7 is Sunday), or a shorthand
+func Parse(spec string) (*Cron, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || every < time.Minute {
+			return nil, fmt.Errorf("invalid interval %q: use a duration of at least 1m, e.g. @every 6h", rest)
+		}
+		return &Cron{spec: spec, every: every}, nil
+	}
+	expr := spec
+	if named, ok := shorthands[spec]; ok {
+		expr = named
+	} else if strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("unknown shorthand %q", spec)
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 fields (minute hour day-of-month month day-of-week)", spec)
+	}
+	c := &Cron{spec: spec}
+	var err error
+	for i, f := range []struct {
+		name     string
+		min, max int
+		dst      *field
+	}{
+		{"minute", 0, 59, &c.minute},
+		{"hour", 0, 23, &c.hour},
+		{"day of month", 1, 31, &c.dom},
+		{"month", 1, 12, &c.month},
+		{"day of week", 0, 7, &c.dow},
+	} {
+		if *f.dst, err = parseField(fields[i], f.min, f.max); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", f.name, fields[i], err)
+		}
+	}
+	// Sunday is both 0 and 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domRestricted = fields[2] != "*"
+	c.dowRestricted = fields[4] != "*"
+	return c, nil
+}
+
+// String returns the expression as given
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// Next returns the first time after t the expression matches, in t's
+// location. It returns the zero time when it never matches.
+func (c *Cron) Next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Add(c.every).Truncate(time.Second)
+	}
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	end := t.Add(maxLookahead)
+	for next.Before(end) {
+		switch {
+		case !c.month.has(int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !c.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !c.hour.has(next.Hour()):
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !c.minute.has(next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t matches. When both the day of
+// month and the day of week are restricted, either matching is enough.
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom, dow := c.dom.has(t.Day()), c.dow.has(int(t.Weekday()))
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// has reports whether the field matches v
+func (f field) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+// parseField parses a comma-separated list of *, values and ranges, each
+// with an optional /step
+func parseField(s string, min, max int) (field, error) {
+	var f field
+	for _, part := range strings.Split(s, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(a, min, max); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(b, min, max); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("range %s starts after it ends", rangePart)
+			}
+		default:
+			v, err := parseValue(rangePart, min, max)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			f |= 1 << uint(v)
+		}
+	}
+	return f, nil
+}
+
+// parseValue parses a value of a field within its bounds
+func parseValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", v, min, max)
+	}
+	return v, nil
+}
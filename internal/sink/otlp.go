@@ -0,0 +1,146 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// OTLP exports the results of runs as metrics to an OpenTelemetry
+// collector, using OTLP over HTTP with JSON encoding
+type OTLP struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewOTLP creates a sink exporting to the collector at endpoint. Without a
+// path, metrics are sent to the standard /v1/metrics.
+func NewOTLP(endpoint, token string) *OTLP {
+	if u, err := url.Parse(endpoint); err == nil && (u.Path == "" || u.Path == "/") {
+		u.Path = "/v1/metrics"
+		endpoint = u.String()
+	}
+	return &OTLP{url: endpoint, token: token, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name implements Sink
+func (o *OTLP) Name() string {
+	return "otlp " + o.url
+}
+
+// Write implements Sink
+func (o *OTLP) Write(run *models.BenchmarkRun) error {
+	data, err := json.Marshal(otlpMetrics(run))
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+	return post(o.client, o.url, o.token, data)
+}
+
+// The OTLP/HTTP JSON encoding of a metrics export request, limited to the
+// gauges benchmark results are exported as
+type (
+	otlpRequest struct {
+		ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+	}
+	otlpResourceMetrics struct {
+		Resource     otlpResource       `json:"resource"`
+		ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeMetrics struct {
+		Scope   otlpScope    `json:"scope"`
+		Metrics []otlpMetric `json:"metrics"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpMetric struct {
+		Name  string    `json:"name"`
+		Unit  string    `json:"unit,omitempty"`
+		Gauge otlpGauge `json:"gauge"`
+	}
+	otlpGauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	}
+	otlpDataPoint struct {
+		Attributes   []otlpAttribute `json:"attributes"`
+		TimeUnixNano string          `json:"timeUnixNano"`
+		AsDouble     float64         `json:"asDouble"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// otlpMetrics converts the measured results of a run to one gauge per
+// metric, with a data point per benchmark
+func otlpMetrics(run *models.BenchmarkRun) otlpRequest {
+	timestamp := strconv.FormatInt(run.Timestamp.UnixNano(), 10)
+	metrics := make(map[string]*otlpMetric)
+	add := func(name, unit, benchmark string, value float64) {
+		metric := metrics[name]
+		if metric == nil {
+			metric = &otlpMetric{Name: name, Unit: unit}
+			metrics[name] = metric
+		}
+		metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, otlpDataPoint{
+			Attributes:   []otlpAttribute{attribute("benchmark", benchmark)},
+			TimeUnixNano: timestamp,
+			AsDouble:     value,
+		})
+	}
+
+	for _, result := range run.Results {
+		if !result.Measured() {
+			continue
+		}
+		add("gokanon.benchmark.duration", "ns", result.Name, result.NsPerOp)
+		add("gokanon.benchmark.memory", "By", result.Name, float64(result.BytesPerOp))
+		add("gokanon.benchmark.allocations", "{allocation}", result.Name, float64(result.AllocsPerOp))
+		if result.MBPerSec > 0 {
+			add("gokanon.benchmark.throughput", "MBy/s", result.Name, result.MBPerSec)
+		}
+		for unit, value := range result.Metrics {
+			add("gokanon.benchmark.custom."+unit, unit, result.Name, value)
+		}
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	scope := otlpScopeMetrics{Scope: otlpScope{Name: "gokanon"}}
+	for _, name := range names {
+		scope.Metrics = append(scope.Metrics, *metrics[name])
+	}
+
+	resource := otlpResource{Attributes: []otlpAttribute{
+		attribute("service.name", "gokanon"),
+		attribute("gokanon.run.id", run.ID),
+		attribute("gokanon.package", run.Package),
+		attribute("gokanon.go.version", run.GoVersion),
+	}}
+	if run.Commit != "" {
+		resource.Attributes = append(resource.Attributes, attribute("vcs.ref.head.revision", run.Commit))
+	}
+	return otlpRequest{ResourceMetrics: []otlpResourceMetrics{{Resource: resource, ScopeMetrics: []otlpScopeMetrics{scope}}}}
+}
+
+// attribute creates a string attribute
+func attribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
@@ -0,0 +1,139 @@
+// Package sink delivers finished benchmark runs to their destinations: the
+// local storage, stdout, files, webhooks and OpenTelemetry collectors.
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Sink receives a finished benchmark run
+type Sink interface {
+	// Name describes the destination in messages, e.g. "webhook https://ci/hook"
+	Name() string
+	// Write delivers the run
+	Write(run *models.BenchmarkRun) error
+}
+
+// Kinds lists the sink kinds accepted by Parse
+var Kinds = []string{"storage", "stdout", "file:<path>", "webhook:<url>", "otlp:<url>"}
+
+// Parse creates the sink described by spec: "storage" saves to store,
+// "stdout" writes JSON to out, "file:<path>" writes JSON to a file (or to
+// <id>.json in a directory), "webhook:<url>" POSTs JSON to a URL and
+// "otlp:<url>" exports metrics to an OpenTelemetry collector over OTLP/HTTP.
+func Parse(spec string, store *storage.Storage, out io.Writer) (Sink, error) {
+	kind, target, _ := strings.Cut(spec, ":")
+	switch strings.ToLower(kind) {
+	case "storage":
+		return NewStorage(store), nil
+	case "stdout":
+		return NewStdout(out), nil
+	case "file":
+		if target == "" {
+			return nil, fmt.Errorf("missing path in sink %q, e.g. file:results.json", spec)
+		}
+		return NewFile(target), nil
+	case "webhook":
+		if !isHTTPURL(target) {
+			return nil, fmt.Errorf("invalid URL in sink %q, e.g. webhook:https://collector.example.com/runs", spec)
+		}
+		return NewWebhook(target, os.Getenv("GOKANON_SINK_TOKEN")), nil
+	case "otlp":
+		if !isHTTPURL(target) {
+			return nil, fmt.Errorf("invalid URL in sink %q, e.g. otlp:http://localhost:4318", spec)
+		}
+		return NewOTLP(target, os.Getenv("GOKANON_SINK_TOKEN")), nil
+	}
+	return nil, fmt.Errorf("unknown sink %q: use %s", spec, strings.Join(Kinds, ", "))
+}
+
+// isHTTPURL reports whether s is an http or https URL
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// Storage saves runs to the local storage
+type Storage struct {
+	store *storage.Storage
+}
+
+// NewStorage creates a sink saving runs to store
+func NewStorage(store *storage.Storage) *Storage {
+	return &Storage{store: store}
+}
+
+// Name implements Sink
+func (s *Storage) Name() string {
+	return "storage"
+}
+
+// Write implements Sink
+func (s *Storage) Write(run *models.BenchmarkRun) error {
+	return s.store.Save(run)
+}
+
+// Stdout writes runs as JSON to a writer, normally standard output
+type Stdout struct {
+	out io.Writer
+}
+
+// NewStdout creates a sink writing runs as JSON to out
+func NewStdout(out io.Writer) *Stdout {
+	return &Stdout{out: out}
+}
+
+// Name implements Sink
+func (s *Stdout) Name() string {
+	return "stdout"
+}
+
+// Write implements Sink
+func (s *Stdout) Write(run *models.BenchmarkRun) error {
+	encoder := json.NewEncoder(s.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(run)
+}
+
+// File writes runs as JSON to a file. When the path is a directory, each
+// run is written to <id>.json in it.
+type File struct {
+	path string
+}
+
+// NewFile creates a sink writing runs to path
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+// Name implements Sink
+func (f *File) Name() string {
+	return "file " + f.path
+}
+
+// Write implements Sink
+func (f *File) Write(run *models.BenchmarkRun) error {
+	path := f.path
+	if info, err := os.Stat(path); (err == nil && info.IsDir()) || os.IsPathSeparator(path[len(path)-1]) {
+		path = filepath.Join(path, run.ID+".json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+func testRun() *models.BenchmarkRun {
+	return &models.BenchmarkRun{
+		ID:        "run-1",
+		Timestamp: time.Unix(1700000000, 0).UTC(),
+		Package:   "./pkg",
+		GoVersion: "go1.24",
+		Commit:    "abc123",
+		Results: []models.BenchmarkResult{
+			{Name: "Parse-8", NsPerOp: 120, BytesPerOp: 64, AllocsPerOp: 2, Metrics: map[string]float64{"items/s": 5000}},
+			{Name: "Broken-8", Status: models.StatusFailed},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{"storage", "storage", false},
+		{"stdout", "stdout", false},
+		{"file:out.json", "file out.json", false},
+		{"webhook:https://ci.example.com/runs", "webhook https://ci.example.com/runs", false},
+		{"otlp:http://localhost:4318", "otlp http://localhost:4318/v1/metrics", false},
+		{"otlp:http://localhost:4318/custom/path", "otlp http://localhost:4318/custom/path", false},
+		{"file:", "", true},
+		{"webhook:ftp://example.com", "", true},
+		{"otlp", "", true},
+		{"kafka:topic", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec, store, io.Discard)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err == nil && s.Name() != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.spec, s.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStorage(t *testing.T) {
+	store := storage.NewStorage(t.TempDir())
+	if err := NewStorage(store).Write(testRun()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := store.Load("run-1"); err != nil {
+		t.Errorf("Expected the run in the storage: %v", err)
+	}
+}
+
+func TestStdout(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewStdout(&out).Write(testRun()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var run models.BenchmarkRun
+	if err := json.Unmarshal(out.Bytes(), &run); err != nil || run.ID != "run-1" {
+		t.Errorf("Expected the run as JSON, got %q (%v)", out.String(), err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "nested", "run.json")
+	if err := NewFile(path).Write(testRun()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected %s to be written: %v", path, err)
+	}
+
+	// A directory gets a file per run
+	if err := NewFile(dir).Write(testRun()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "run-1.json")); err != nil {
+		t.Errorf("Expected run-1.json in the directory: %v", err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var body []byte
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	if err := NewWebhook(server.URL, "secret").Write(testRun()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expected the bearer token, got %q", auth)
+	}
+	var run models.BenchmarkRun
+	if err := json.Unmarshal(body, &run); err != nil || run.ID != "run-1" {
+		t.Errorf("Expected the run as JSON, got %q (%v)", body, err)
+	}
+}
+
+func TestWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "collector down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL, "").Write(testRun())
+	if err == nil || !strings.Contains(err.Error(), "503") || !strings.Contains(err.Error(), "collector down") {
+		t.Errorf("Expected the status and body in the error, got %v", err)
+	}
+}
+
+func TestOTLP(t *testing.T) {
+	var path string
+	var request otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&request)
+	}))
+	defer server.Close()
+
+	if err := NewOTLP(server.URL, "").Write(testRun()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if path != "/v1/metrics" {
+		t.Errorf("Expected metrics posted to /v1/metrics, got %s", path)
+	}
+
+	if len(request.ResourceMetrics) != 1 || len(request.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("Unexpected request: %+v", request)
+	}
+	metrics := make(map[string]otlpMetric)
+	for _, m := range request.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+	duration := metrics["gokanon.benchmark.duration"]
+	// The failed benchmark has no measurements to export
+	if len(duration.Gauge.DataPoints) != 1 || duration.Gauge.DataPoints[0].AsDouble != 120 || duration.Unit != "ns" {
+		t.Errorf("Unexpected duration metric: %+v", duration)
+	}
+	if point := duration.Gauge.DataPoints[0]; point.TimeUnixNano != "1700000000000000000" || point.Attributes[0].Value.StringValue != "Parse-8" {
+		t.Errorf("Unexpected data point: %+v", point)
+	}
+	if _, ok := metrics["gokanon.benchmark.custom.items/s"]; !ok {
+		t.Errorf("Expected the custom metric, got %v", metrics)
+	}
+}
@@ -0,0 +1,67 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// requestTimeout bounds how long a run waits for a remote sink
+const requestTimeout = 30 * time.Second
+
+// Webhook POSTs runs as JSON to a URL, such as a central collector
+type Webhook struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhook creates a sink POSTing runs to url. A non-empty token is sent
+// as a bearer token.
+func NewWebhook(url, token string) *Webhook {
+	return &Webhook{url: url, token: token, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name implements Sink
+func (w *Webhook) Name() string {
+	return "webhook " + w.url
+}
+
+// Write implements Sink
+func (w *Webhook) Write(run *models.BenchmarkRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run: %w", err)
+	}
+	return post(w.client, w.url, w.token, data)
+}
+
+// post sends a JSON body and fails unless the response status is 2xx
+func post(client *http.Client, url, token string, data []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s responded with status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
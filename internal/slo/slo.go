@@ -0,0 +1,230 @@
+// Package slo evaluates service level objectives declared in the project
+// configuration against the history of benchmark runs.
+package slo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
+)
+
+// States of an objective
+const (
+	StateOK       = "ok"
+	StateBreached = "breached"
+	StateNoData   = "no data"
+)
+
+// HistoryLength is the number of most recent evaluations kept in a Status
+const HistoryLength = 50
+
+// Status is the evaluation of an objective over the most recent runs
+type Status struct {
+	Name       string  `json:"name"`
+	Benchmark  string  `json:"benchmark"`
+	Metric     string  `json:"metric"`
+	Objective  string  `json:"objective"` // Human-readable objective, e.g. "p95 ≤ 2ms"
+	Limit      float64 `json:"limit"`
+	Upper      bool    `json:"upper"` // Limit is a maximum rather than a minimum
+	Percentile float64 `json:"percentile"`
+	Window     int     `json:"window"`
+
+	State      string  `json:"state"`
+	Samples    int     `json:"samples"`    // Runs in the window that measured the benchmark
+	Value      float64 `json:"value"`      // Observed percentile over the window
+	Violations int     `json:"violations"` // Samples outside the limit
+	BurnRate   float64 `json:"burnRate"`   // Violation rate relative to the error budget; above 1 breaches
+
+	History  []Point  `json:"history"`  // Rolling evaluation after each run, oldest first
+	Breaches []Breach `json:"breaches"` // Periods in History during which the objective was breached
+}
+
+// Point is the evaluation of an objective after a run
+type Point struct {
+	RunID     string    `json:"runId"`
+	Timestamp time.Time `json:"timestamp"`
+	Sample    float64   `json:"sample"` // The run's own value
+	Value     float64   `json:"value"`  // Percentile of the window ending at the run
+	Compliant bool      `json:"compliant"`
+}
+
+// Breach is a period of consecutive runs breaching an objective
+type Breach struct {
+	FromRun string    `json:"fromRun"`
+	ToRun   string    `json:"toRun"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Runs    int       `json:"runs"`
+	Ongoing bool      `json:"ongoing"` // The breach lasts until the newest run
+}
+
+// EvaluateAll evaluates every objective. Runs are newest first, as returned
+// by storage.List.
+func EvaluateAll(slos []config.SLO, runs []models.BenchmarkRun) ([]Status, error) {
+	statuses := make([]Status, 0, len(slos))
+	for _, objective := range slos {
+		status, err := Evaluate(objective, runs)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+// Evaluate evaluates an objective. Runs are newest first, as returned by
+// storage.List. Runs that did not measure the benchmark are ignored, so the
+// window covers the most recent runs that did.
+//
+// The error budget of a p95 objective is 5% of the window: the burn rate is
+// the fraction of samples outside the limit divided by that budget.
+func Evaluate(objective config.SLO, runs []models.BenchmarkRun) (*Status, error) {
+	limit, upper, err := objective.Bound()
+	if err != nil {
+		return nil, fmt.Errorf("slo %q: %w", objective.Name, err)
+	}
+
+	status := &Status{
+		Name:       objective.Name,
+		Benchmark:  objective.Benchmark,
+		Metric:     objective.MetricName(),
+		Limit:      limit,
+		Upper:      upper,
+		Percentile: objective.TargetPercentile(),
+		Window:     objective.WindowRuns(),
+		State:      StateNoData,
+		History:    []Point{},
+		Breaches:   []Breach{},
+	}
+	status.Objective = objectiveText(status)
+
+	// Collect samples in chronological order
+	var points []Point
+	var samples []float64
+	for i := len(runs) - 1; i >= 0; i-- {
+		value, ok := sample(&runs[i], objective.Benchmark, status.Metric)
+		if !ok {
+			continue
+		}
+		samples = append(samples, value)
+		window := samples[max(0, len(samples)-status.Window):]
+		observed := windowValue(status, window)
+		points = append(points, Point{
+			RunID:     runs[i].ID,
+			Timestamp: runs[i].Timestamp,
+			Sample:    value,
+			Value:     observed,
+			Compliant: meets(status, observed),
+		})
+	}
+	if len(points) == 0 {
+		return status, nil
+	}
+
+	window := samples[max(0, len(samples)-status.Window):]
+	status.Samples = len(window)
+	status.Value = points[len(points)-1].Value
+	for _, v := range window {
+		if !meets(status, v) {
+			status.Violations++
+		}
+	}
+	budget := (100 - status.Percentile) / 100
+	status.BurnRate = float64(status.Violations) / float64(len(window)) / budget
+
+	status.State = StateOK
+	if !points[len(points)-1].Compliant {
+		status.State = StateBreached
+	}
+
+	status.History = points[max(0, len(points)-HistoryLength):]
+	status.Breaches = breaches(status.History)
+	return status, nil
+}
+
+// Covers reports whether an objective applies to the named benchmark
+func Covers(objective config.SLO, name string) bool {
+	return matches(strings.TrimPrefix(objective.Benchmark, "Benchmark"), name)
+}
+
+// matches reports whether a benchmark name, with or without the "Benchmark"
+// prefix and GOMAXPROCS suffix, is the given one
+func matches(benchmark, name string) bool {
+	name = strings.TrimPrefix(name, "Benchmark")
+	return name == benchmark || stats.BenchmarkKey(name) == benchmark
+}
+
+// sample returns the value of the objective's metric for a run
+func sample(run *models.BenchmarkRun, benchmark, metric string) (float64, bool) {
+	benchmark = strings.TrimPrefix(benchmark, "Benchmark")
+	for _, result := range run.Results {
+		if !result.Measured() || !matches(benchmark, result.Name) {
+			continue
+		}
+		return stats.MetricValue(result, metric)
+	}
+	return 0, false
+}
+
+// windowValue returns the percentile of a window checked against the limit.
+// For a lower limit the complementary percentile is used, so that a p95
+// objective requires 95% of samples to be above the limit.
+func windowValue(status *Status, window []float64) float64 {
+	if status.Upper {
+		return stats.Percentile(window, status.Percentile)
+	}
+	return stats.Percentile(window, 100-status.Percentile)
+}
+
+// meets reports whether a value is within the objective's limit
+func meets(status *Status, value float64) bool {
+	if status.Upper {
+		return value <= status.Limit
+	}
+	return value >= status.Limit
+}
+
+// breaches groups consecutive non-compliant points into periods
+func breaches(points []Point) []Breach {
+	result := []Breach{}
+	for i, p := range points {
+		if p.Compliant {
+			continue
+		}
+		if i > 0 && !points[i-1].Compliant {
+			last := &result[len(result)-1]
+			last.ToRun, last.To = p.RunID, p.Timestamp
+			last.Runs++
+		} else {
+			result = append(result, Breach{FromRun: p.RunID, ToRun: p.RunID, From: p.Timestamp, To: p.Timestamp, Runs: 1})
+		}
+	}
+	if n := len(result); n > 0 && !points[len(points)-1].Compliant {
+		result[n-1].Ongoing = true
+	}
+	return result
+}
+
+// objectiveText describes an objective, e.g. "p95 ≤ 2ms"
+func objectiveText(status *Status) string {
+	op := "≤"
+	if !status.Upper {
+		op = "≥"
+	}
+	return fmt.Sprintf("p%g %s %s", status.Percentile, op, FormatValue(status.Limit, status.Metric))
+}
+
+// FormatValue formats a value of a metric, as a duration for ns/op
+func FormatValue(value float64, metric string) string {
+	if metric == "ns/op" && value >= 1000 {
+		return time.Duration(value).String()
+	}
+	if metric == "ns/op" {
+		return fmt.Sprintf("%gns", value)
+	}
+	return fmt.Sprintf("%g %s", value, metric)
+}
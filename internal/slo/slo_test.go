@@ -0,0 +1,133 @@
+package slo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// history builds runs, newest first, from ns/op values given oldest first
+func history(values ...float64) []models.BenchmarkRun {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := make([]models.BenchmarkRun, len(values))
+	for i, v := range values {
+		runs[len(values)-1-i] = models.BenchmarkRun{
+			ID:        fmt.Sprintf("run-%d", i),
+			Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkCheckout-8", NsPerOp: v},
+				{Name: "BenchmarkOther-8", NsPerOp: 1},
+			},
+		}
+	}
+	return runs
+}
+
+func TestEvaluate(t *testing.T) {
+	objective := config.SLO{Name: "checkout", Benchmark: "Checkout", Max: "2µs", Percentile: 90, Window: 10}
+
+	// Nine fast runs and one slow run: 10% violations is exactly the budget
+	status, err := Evaluate(objective, history(1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 3000))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if status.State != StateOK || status.Samples != 10 || status.Violations != 1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if status.BurnRate < 0.999 || status.BurnRate > 1.001 {
+		t.Errorf("BurnRate = %v, want 1", status.BurnRate)
+	}
+	if status.Objective != "p90 ≤ 2µs" {
+		t.Errorf("Objective = %q", status.Objective)
+	}
+
+	// A second slow run exhausts the budget
+	status, _ = Evaluate(objective, history(1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 3000, 3000))
+	if status.State != StateBreached || status.Value != 3000 || status.BurnRate != 2 {
+		t.Errorf("expected a breach, got %+v", status)
+	}
+	if len(status.Breaches) != 1 || !status.Breaches[0].Ongoing || status.Breaches[0].FromRun != "run-10" {
+		t.Errorf("unexpected breaches: %+v", status.Breaches)
+	}
+}
+
+func TestEvaluateMinimum(t *testing.T) {
+	objective := config.SLO{Name: "ingest", Benchmark: "BenchmarkIngest", Metric: "events/s", Min: "100", Percentile: 50, Window: 4}
+	run := func(id string, v float64) models.BenchmarkRun {
+		return models.BenchmarkRun{ID: id, Results: []models.BenchmarkResult{
+			{Name: "Ingest-8", NsPerOp: 1, Metrics: map[string]float64{"events/s": v}},
+		}}
+	}
+	runs := []models.BenchmarkRun{run("r4", 90), run("r3", 80), run("r2", 150), run("r1", 200)}
+
+	status, err := Evaluate(objective, runs)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if status.State != StateBreached || status.Value != 90 || status.Violations != 2 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if len(status.History) != 4 || !status.History[0].Compliant || status.History[3].Compliant {
+		t.Errorf("unexpected history: %+v", status.History)
+	}
+}
+
+func TestEvaluateRecoveredBreach(t *testing.T) {
+	objective := config.SLO{Name: "checkout", Benchmark: "Checkout", Max: "2000", Percentile: 50, Window: 1}
+	status, _ := Evaluate(objective, history(1000, 3000, 3000, 1000))
+
+	if status.State != StateOK {
+		t.Errorf("State = %q, want ok", status.State)
+	}
+	if len(status.Breaches) != 1 || status.Breaches[0].Runs != 2 || status.Breaches[0].Ongoing {
+		t.Errorf("unexpected breaches: %+v", status.Breaches)
+	}
+	if status.Breaches[0].FromRun != "run-1" || status.Breaches[0].ToRun != "run-2" {
+		t.Errorf("breach spans %s..%s, want run-1..run-2", status.Breaches[0].FromRun, status.Breaches[0].ToRun)
+	}
+}
+
+func TestEvaluateNoData(t *testing.T) {
+	objective := config.SLO{Name: "missing", Benchmark: "Missing", Max: "1ms"}
+	status, err := Evaluate(objective, history(1000))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if status.State != StateNoData || status.Samples != 0 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	objective := config.SLO{Name: "checkout", Benchmark: "BenchmarkCheckout", Max: "1ms"}
+	for name, want := range map[string]bool{
+		"BenchmarkCheckout":   true,
+		"BenchmarkCheckout-8": true,
+		"Checkout":            true,
+		"BenchmarkCart":       false,
+	} {
+		if got := Covers(objective, name); got != want {
+			t.Errorf("Covers(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value  float64
+		metric string
+		want   string
+	}{
+		{2e6, "ns/op", "2ms"},
+		{12.5, "ns/op", "12.5ns"},
+		{5000, "events/s", "5000 events/s"},
+	}
+	for _, tt := range tests {
+		if got := FormatValue(tt.value, tt.metric); got != tt.want {
+			t.Errorf("FormatValue(%v, %q) = %q, want %q", tt.value, tt.metric, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+)
+
+// Anomaly detection methods, each comparing a value with the window of
+// values before it
+const (
+	AnomalyStdDev = "stddev" // Standard deviations from the window mean
+	AnomalyIQR    = "iqr"    // Interquartile ranges beyond the window quartiles
+	AnomalyMedian = "median" // Scaled median absolute deviations from the rolling median
+)
+
+// DefaultAnomalyWindow is the number of preceding values a value is
+// compared with
+const DefaultAnomalyWindow = 10
+
+// minAnomalyWindow is the fewest preceding values that tell a spike from
+// noise; earlier values are never flagged
+const minAnomalyWindow = 4
+
+// madScale makes the median absolute deviation of normally distributed
+// values an estimate of their standard deviation
+const madScale = 1.4826
+
+// Anomaly is a value that deviates from the values before it by more than
+// the threshold of the method
+type Anomaly struct {
+	Index    int     `json:"index"`    // Index of the value in the series
+	Value    float64 `json:"value"`    // Value flagged
+	Expected float64 `json:"expected"` // Mean or median of the window
+	Score    float64 `json:"score"`    // Deviation in the unit of the method; negative below the window
+}
+
+// DefaultAnomalyThreshold returns the deviation from which a method flags
+// a value: 3 standard deviations or scaled MADs, or Tukey's fences of 1.5
+// interquartile ranges
+func DefaultAnomalyThreshold(method string) float64 {
+	if method == AnomalyIQR {
+		return 1.5
+	}
+	return 3
+}
+
+// DetectAnomalies flags the values that deviate by more than threshold
+// from up to window values before them, by the given method. A zero
+// window or threshold selects the default. The spread of the window is at
+// least 1% of its center, so that the slightest change after identical
+// values is no anomaly.
+func DetectAnomalies(values []float64, method string, window int, threshold float64) ([]Anomaly, error) {
+	if method == "" {
+		method = AnomalyStdDev
+	}
+	if method != AnomalyStdDev && method != AnomalyIQR && method != AnomalyMedian {
+		return nil, fmt.Errorf("unknown anomaly method %q (use %s, %s or %s)", method, AnomalyStdDev, AnomalyIQR, AnomalyMedian)
+	}
+	if window == 0 {
+		window = DefaultAnomalyWindow
+	}
+	if window < minAnomalyWindow {
+		return nil, fmt.Errorf("anomaly window must be at least %d runs", minAnomalyWindow)
+	}
+	if threshold == 0 {
+		threshold = DefaultAnomalyThreshold(method)
+	}
+
+	var anomalies []Anomaly
+	for i := minAnomalyWindow; i < len(values); i++ {
+		previous := values[max(0, i-window):i]
+		expected, score := deviation(values[i], previous, method)
+		if math.Abs(score) > threshold {
+			anomalies = append(anomalies, Anomaly{Index: i, Value: values[i], Expected: expected, Score: score})
+		}
+	}
+	return anomalies, nil
+}
+
+// deviation returns the center of the window and how far v deviates from
+// it in the unit of the method
+func deviation(v float64, window []float64, method string) (float64, float64) {
+	switch method {
+	case AnomalyIQR:
+		median := Percentile(window, 50)
+		q1, q3 := Percentile(window, 25), Percentile(window, 75)
+		iqr := max(q3-q1, math.Abs(median)/100)
+		switch {
+		case v > q3:
+			return median, (v - q3) / iqr
+		case v < q1:
+			return median, (v - q1) / iqr
+		}
+		return median, 0
+	case AnomalyMedian:
+		median := Percentile(window, 50)
+		deviations := make([]float64, len(window))
+		for i, w := range window {
+			deviations[i] = math.Abs(w - median)
+		}
+		mad := max(madScale*Percentile(deviations, 50), math.Abs(median)/100)
+		return median, (v - median) / mad
+	}
+	stats := NewAnalyzer().calculateStats("", window)
+	stdDev := max(stats.StdDev, math.Abs(stats.Mean)/100)
+	return stats.Mean, (v - stats.Mean) / stdDev
+}
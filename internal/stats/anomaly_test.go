@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"testing"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	// A spike at index 6, back to normal afterwards
+	values := []float64{100, 101, 99, 100, 101, 99, 150, 100, 101}
+
+	for _, method := range []string{AnomalyStdDev, AnomalyIQR, AnomalyMedian} {
+		anomalies, err := DetectAnomalies(values, method, 0, 0)
+		if err != nil {
+			t.Fatalf("%s: DetectAnomalies failed: %v", method, err)
+		}
+		if len(anomalies) != 1 || anomalies[0].Index != 6 || anomalies[0].Value != 150 || anomalies[0].Score <= 0 {
+			t.Errorf("%s: expected the spike at index 6, got %+v", method, anomalies)
+		}
+	}
+
+	// Drops are flagged with a negative score
+	anomalies, _ := DetectAnomalies([]float64{100, 101, 99, 100, 100, 60}, AnomalyMedian, 0, 0)
+	if len(anomalies) != 1 || anomalies[0].Score >= 0 || anomalies[0].Expected != 100 {
+		t.Errorf("Expected a drop from 100, got %+v", anomalies)
+	}
+
+	// Changes within 1% of identical values are noise
+	if anomalies, _ := DetectAnomalies([]float64{100, 100, 100, 100, 100, 101}, AnomalyStdDev, 0, 0); len(anomalies) != 0 {
+		t.Errorf("Expected no anomaly for a 1%% change, got %+v", anomalies)
+	}
+
+	// A higher threshold tolerates the spike
+	if anomalies, _ := DetectAnomalies(values, AnomalyStdDev, 0, 100); len(anomalies) != 0 {
+		t.Errorf("Expected no anomaly with a threshold of 100, got %+v", anomalies)
+	}
+
+	// Too few values before the spike to judge it
+	if anomalies, _ := DetectAnomalies([]float64{100, 100, 200}, AnomalyStdDev, 0, 0); len(anomalies) != 0 {
+		t.Errorf("Expected no anomaly without a full window, got %+v", anomalies)
+	}
+
+	if _, err := DetectAnomalies(values, "zscore", 0, 0); err == nil {
+		t.Error("Expected an error for an unknown method")
+	}
+	if _, err := DetectAnomalies(values, AnomalyIQR, 2, 0); err == nil {
+		t.Error("Expected an error for a window of 2 runs")
+	}
+}
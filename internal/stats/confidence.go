@@ -0,0 +1,154 @@
+package stats
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Confidence grades of a result, from numbers to trust to numbers too
+// short or too noisy to act on
+const (
+	GradeA = "A"
+	GradeB = "B"
+	GradeC = "C"
+	GradeD = "D"
+)
+
+// grades are the confidence grades from best to worst
+var grades = []string{GradeA, GradeB, GradeC, GradeD}
+
+// Limits below or above which a result loses confidence. Each missed limit
+// costs one point, two for the second limit of a factor; 0 points grade A,
+// 1 grades B, 2 or 3 grade C and more grade D.
+const (
+	lowIterations        = 100
+	veryLowIterations    = 10
+	shortMeasurement     = 500 * time.Millisecond // Time measured per sample, iterations × ns/op
+	veryShortMeasurement = 100 * time.Millisecond
+	fewSamples           = 20 // Processes or requests timed by a startup or load run
+	veryFewSamples       = 10
+	noisyHistoryCV       = 5.0  // % across the previous runs
+	veryNoisyHistoryCV   = 10.0 // % across the previous runs
+	minGradeHistory      = 3    // Previous runs needed to judge the stability of a benchmark
+	maxQuietLoadPerCPU   = 0.5  // One-minute load average per CPU of a quiet machine
+)
+
+// GradeConfidence grades how far a result can be trusted from its
+// iterations, the time it was measured for, the coefficient of variation
+// of its benchmark across previous runs and the environment of its run.
+// The results of startup and load runs are graded from their samples
+// instead of iterations and time.
+// history holds the ns/op of the previous runs and may be nil. It returns
+// the grade and the reasons for any point lost.
+func GradeConfidence(result models.BenchmarkResult, history *models.Aggregate, run *models.BenchmarkRun) (string, []string) {
+	points := 0
+	var reasons []string
+	lose := func(n int, reason string, args ...any) {
+		points += n
+		reasons = append(reasons, fmt.Sprintf(reason, args...))
+	}
+
+	// Each iteration of a startup or load result is a process or request
+	// timed on its own, not a go test loop to amortize
+	timedSamples := run.Startup != nil || run.Load != nil
+	if timedSamples {
+		switch {
+		case result.Iterations < veryFewSamples:
+			lose(2, "only %d samples", result.Iterations)
+		case result.Iterations < fewSamples:
+			lose(1, "only %d samples", result.Iterations)
+		}
+	} else {
+		switch {
+		case result.Iterations < veryLowIterations:
+			lose(2, "only %d iterations", result.Iterations)
+		case result.Iterations < lowIterations:
+			lose(1, "only %d iterations", result.Iterations)
+		}
+	}
+
+	// Normalized values are not in nanoseconds
+	if run.NormalizedTo == "" && !timedSamples {
+		measured := time.Duration(float64(result.Iterations) * result.NsPerOp)
+		switch {
+		case measured < veryShortMeasurement:
+			lose(2, "measured for %s", measured.Round(time.Millisecond))
+		case measured < shortMeasurement:
+			lose(1, "measured for %s", measured.Round(time.Millisecond))
+		}
+	}
+
+	if history == nil || history.Count < minGradeHistory {
+		lose(1, "fewer than %d previous runs to judge stability", minGradeHistory)
+	} else if history.Mean > 0 {
+		cv := history.StdDev() / history.Mean * 100
+		switch {
+		case cv > veryNoisyHistoryCV:
+			lose(2, "%.1f%% variation across %d runs", cv, history.Count)
+		case cv > noisyHistoryCV:
+			lose(1, "%.1f%% variation across %d runs", cv, history.Count)
+		}
+	}
+
+	if run.Degraded() {
+		lose(2, "environment degraded: %s", run.Error)
+	}
+	if env := run.Environment; env != nil {
+		if env.CPUs > 0 && env.LoadAverage > maxQuietLoadPerCPU*float64(env.CPUs) {
+			lose(1, "load average %.2f on %d CPUs", env.LoadAverage, env.CPUs)
+		}
+		if env.Governor != "" && env.Governor != "performance" {
+			lose(1, "CPU frequency governor %q", env.Governor)
+		}
+	}
+
+	switch {
+	case points == 0:
+		return GradeA, reasons
+	case points == 1:
+		return GradeB, reasons
+	case points <= 3:
+		return GradeC, reasons
+	}
+	return GradeD, reasons
+}
+
+// GradeRun sets the confidence grade of each measured result of a run,
+// judging its stability from history, the statistics of each benchmark
+// across previous runs by name
+func GradeRun(run *models.BenchmarkRun, history map[string]*models.Aggregate) {
+	for i := range run.Results {
+		result := &run.Results[i]
+		if !result.Measured() {
+			continue
+		}
+		result.Confidence, _ = GradeConfidence(*result, history[result.Name], run)
+	}
+}
+
+// WorseGrade returns the worse of two grades, ignoring missing ones
+func WorseGrade(a, b string) string {
+	if slices.Index(grades, a) < slices.Index(grades, b) {
+		return b
+	}
+	return a
+}
+
+// RunConfidence returns the median grade of the graded results of a run,
+// the worse middle one for an even count, or "" when none is graded
+func RunConfidence(run *models.BenchmarkRun) string {
+	var indexes []int
+	for _, result := range run.Results {
+		if i := slices.Index(grades, result.Confidence); i >= 0 {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return ""
+	}
+	slices.Sort(indexes)
+	return grades[indexes[len(indexes)/2]]
+}
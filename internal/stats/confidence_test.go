@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestGradeConfidence(t *testing.T) {
+	stable := &models.Aggregate{}
+	noisy := &models.Aggregate{}
+	for _, v := range []float64{1000, 1010, 990, 1005, 995} {
+		stable.Add(v)
+	}
+	for _, v := range []float64{1000, 1300, 800, 1200, 700} {
+		noisy.Add(v)
+	}
+	// 1ms per op over 1000 iterations measures for 1s
+	long := models.BenchmarkResult{Name: "Long", Iterations: 1000, NsPerOp: 1e6}
+	quiet := &models.BenchmarkRun{Environment: &models.Environment{CPUs: 8, LoadAverage: 0.5, Governor: "performance"}}
+
+	tests := []struct {
+		name    string
+		result  models.BenchmarkResult
+		history *models.Aggregate
+		run     *models.BenchmarkRun
+		want    string
+	}{
+		{"long, stable and quiet", long, stable, quiet, GradeA},
+		{"no history", long, nil, quiet, GradeB},
+		{"noisy history", long, noisy, quiet, GradeC},
+		{"few iterations", models.BenchmarkResult{Iterations: 50, NsPerOp: 2e7}, stable, quiet, GradeB},
+		{"too short", models.BenchmarkResult{Iterations: 5, NsPerOp: 1e6}, nil, quiet, GradeD},
+		{"busy machine", long, stable, &models.BenchmarkRun{Environment: &models.Environment{CPUs: 2, LoadAverage: 4, Governor: "powersave"}}, GradeC},
+		{"degraded", long, stable, &models.BenchmarkRun{Status: models.StatusDegraded, Error: "throttled"}, GradeC},
+		// 20 processes starting in 5ms each are enough samples
+		{"startup", models.BenchmarkResult{Iterations: 20, NsPerOp: 5e6}, stable, &models.BenchmarkRun{Startup: &models.Startup{Iterations: 20}}, GradeA},
+		{"few startups", models.BenchmarkResult{Iterations: 5, NsPerOp: 5e6}, stable, &models.BenchmarkRun{Startup: &models.Startup{Iterations: 5}}, GradeC},
+		{"load", models.BenchmarkResult{Iterations: 3000, NsPerOp: 2e5}, stable, &models.BenchmarkRun{Load: &models.LoadTest{Requests: 3000}}, GradeA},
+	}
+	for _, tt := range tests {
+		grade, reasons := GradeConfidence(tt.result, tt.history, tt.run)
+		if grade != tt.want {
+			t.Errorf("%s: grade = %s (%v), want %s", tt.name, grade, reasons, tt.want)
+		}
+		if (grade == GradeA) != (len(reasons) == 0) {
+			t.Errorf("%s: reasons = %v for grade %s", tt.name, reasons, grade)
+		}
+	}
+}
+
+func TestGradeRun(t *testing.T) {
+	run := &models.BenchmarkRun{Results: []models.BenchmarkResult{
+		{Name: "A", Iterations: 1000, NsPerOp: 1e6},
+		{Name: "B", Iterations: 1000, NsPerOp: 1e6},
+		{Name: "Short", Iterations: 5, NsPerOp: 1e6},
+		{Name: "Skipped", Status: models.StatusSkipped},
+	}}
+	stable := &models.Aggregate{}
+	for range 3 {
+		stable.Add(1e6)
+	}
+	GradeRun(run, map[string]*models.Aggregate{"A": stable})
+
+	var got []string
+	for _, result := range run.Results {
+		got = append(got, result.Confidence)
+	}
+	if want := []string{GradeA, GradeB, GradeD, ""}; !slices.Equal(got, want) {
+		t.Errorf("grades = %q, want %q", got, want)
+	}
+	// The median of A, B and D
+	if grade := RunConfidence(run); grade != GradeB {
+		t.Errorf("RunConfidence = %q, want B", grade)
+	}
+	if grade := RunConfidence(&models.BenchmarkRun{}); grade != "" {
+		t.Errorf("RunConfidence of an ungraded run = %q, want none", grade)
+	}
+}
+
+func TestWorseGrade(t *testing.T) {
+	for _, tt := range []struct{ a, b, want string }{
+		{GradeA, GradeC, GradeC},
+		{GradeD, GradeB, GradeD},
+		{"", GradeB, GradeB},
+		{GradeA, "", GradeA},
+		{"", "", ""},
+	} {
+		if got := WorseGrade(tt.a, tt.b); got != tt.want {
+			t.Errorf("WorseGrade(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// ScoreUnit is the unit of performance scores
+const ScoreUnit = "ops/s"
+
+// Score returns the weighted geometric mean of the throughput (operations
+// per second) of the measured benchmarks of a run. Higher is better, and a
+// change of the score is the weighted average change across benchmarks, so
+// a single number tracks whether a run got faster or slower overall.
+//
+// Weights are looked up by benchmark name without the "Benchmark" prefix and
+// the GOMAXPROCS suffix. Keys may be exact names or path.Match patterns such
+// as "Parse/*"; exact names take precedence, then the first matching pattern
+// in lexical order. Unlisted benchmarks weigh 1, and a weight of 0 leaves a
+// benchmark out. It reports false if no benchmark contributes to the score.
+func Score(run *models.BenchmarkRun, weights map[string]float64) (float64, bool) {
+	patterns := make([]string, 0, len(weights))
+	for key := range weights {
+		patterns = append(patterns, key)
+	}
+	sort.Strings(patterns)
+
+	var sum, total float64
+	for _, result := range run.Results {
+		if !result.Measured() || result.NsPerOp <= 0 {
+			continue
+		}
+		w := scoreWeight(BenchmarkKey(result.Name), weights, patterns)
+		if w <= 0 {
+			continue
+		}
+		sum += w * math.Log(1e9/result.NsPerOp)
+		total += w
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return math.Exp(sum / total), true
+}
+
+// AnalyzeScoreTrend analyzes the trend of the performance score over runs in
+// chronological order. Higher scores are better, and the score is considered
+// stable while the trend line moves it by less than 1% of its mean per run.
+// Runs without a score are skipped.
+func (a *Analyzer) AnalyzeScoreTrend(runs []models.BenchmarkRun, weights map[string]float64) *TrendAnalysis {
+	var values, times []float64
+	var sum float64
+	for i := range runs {
+		if score, ok := Score(&runs[i], weights); ok {
+			values = append(values, score)
+			times = append(times, float64(i))
+			sum += score
+		}
+	}
+	if len(values) < 2 {
+		return nil
+	}
+
+	slope, _, rSquared := LinearRegression(times, values)
+
+	direction := "stable"
+	if math.Abs(slope) > 0.01*sum/float64(len(values)) {
+		if slope > 0 {
+			direction = "improving"
+		} else {
+			direction = "degrading"
+		}
+	}
+
+	return &TrendAnalysis{
+		BenchmarkName: "score",
+		Direction:     direction,
+		TrendLine:     slope,
+		Confidence:    rSquared,
+	}
+}
+
+// scoreWeight returns the weight of a benchmark
+func scoreWeight(name string, weights map[string]float64, patterns []string) float64 {
+	if w, ok := weights[name]; ok {
+		return w
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return weights[pattern]
+		}
+	}
+	return 1
+}
+
+// BenchmarkKey strips the "Benchmark" prefix and GOMAXPROCS suffix from a
+// name, e.g. "BenchmarkParse-8" -> "Parse"
+func BenchmarkKey(name string) string {
+	name = strings.TrimPrefix(name, "Benchmark")
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 || i == len(name)-1 {
+		return name
+	}
+	for _, r := range name[i+1:] {
+		if r < '0' || r > '9' {
+			return name
+		}
+	}
+	return name[:i]
+}
+
+// FormatScore formats a score with an SI suffix, e.g. "1.25M"
+func FormatScore(score float64) string {
+	switch {
+	case score >= 1e9:
+		return fmt.Sprintf("%.2fG", score/1e9)
+	case score >= 1e6:
+		return fmt.Sprintf("%.2fM", score/1e6)
+	case score >= 1e3:
+		return fmt.Sprintf("%.2fk", score/1e3)
+	default:
+		return fmt.Sprintf("%.2f", score)
+	}
+}
@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestScore(t *testing.T) {
+	run := &models.BenchmarkRun{Results: []models.BenchmarkResult{
+		{Name: "Parse-8", NsPerOp: 100},           // 1e7 ops/s
+		{Name: "BenchmarkEncode-8", NsPerOp: 1e4}, // 1e5 ops/s
+		{Name: "Broken-8", Status: models.StatusFailed},
+	}}
+
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		want    float64
+	}{
+		{"equal weights", nil, 1e6},
+		{"exact name", map[string]float64{"Parse": 3}, math.Pow(1e7, 0.75) * math.Pow(1e5, 0.25)},
+		{"pattern", map[string]float64{"Enc*": 0}, 1e7},
+		{"exact before pattern", map[string]float64{"*": 0, "Encode": 1}, 1e5},
+	}
+
+	for _, tt := range tests {
+		got, ok := Score(run, tt.weights)
+		if !ok || math.Abs(got-tt.want)/tt.want > 1e-9 {
+			t.Errorf("%s: Score() = %v, %v; want %v", tt.name, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := Score(run, map[string]float64{"*": 0}); ok {
+		t.Error("expected no score when every benchmark is excluded")
+	}
+}
+
+func TestScoreTracksOverallChange(t *testing.T) {
+	before := &models.BenchmarkRun{Results: []models.BenchmarkResult{
+		{Name: "A-8", NsPerOp: 100},
+		{Name: "B-8", NsPerOp: 400},
+	}}
+	// A got twice as fast, B twice as slow: no change overall
+	after := &models.BenchmarkRun{Results: []models.BenchmarkResult{
+		{Name: "A-8", NsPerOp: 50},
+		{Name: "B-8", NsPerOp: 800},
+	}}
+
+	s1, _ := Score(before, nil)
+	s2, _ := Score(after, nil)
+	if math.Abs(s1-s2)/s1 > 1e-9 {
+		t.Errorf("scores differ: %v vs %v", s1, s2)
+	}
+}
+
+func TestAnalyzeScoreTrend(t *testing.T) {
+	run := func(ns float64) models.BenchmarkRun {
+		return models.BenchmarkRun{Results: []models.BenchmarkResult{{Name: "A-8", NsPerOp: ns}}}
+	}
+	a := NewAnalyzer()
+
+	tests := []struct {
+		runs []models.BenchmarkRun
+		want string
+	}{
+		{[]models.BenchmarkRun{run(100), run(80), run(50)}, "improving"},
+		{[]models.BenchmarkRun{run(50), run(80), run(100)}, "degrading"},
+		{[]models.BenchmarkRun{run(100), run(100.1), run(99.9)}, "stable"},
+	}
+	for _, tt := range tests {
+		if trend := a.AnalyzeScoreTrend(tt.runs, nil); trend == nil || trend.Direction != tt.want {
+			t.Errorf("AnalyzeScoreTrend() = %+v, want %s", trend, tt.want)
+		}
+	}
+
+	if trend := a.AnalyzeScoreTrend([]models.BenchmarkRun{run(100), {}}, nil); trend != nil {
+		t.Errorf("expected nil with one scored run, got %+v", trend)
+	}
+}
+
+func TestFormatScore(t *testing.T) {
+	tests := map[float64]string{
+		12.345: "12.35",
+		1500:   "1.50k",
+		2.5e6:  "2.50M",
+		3.25e9: "3.25G",
+	}
+	for score, want := range tests {
+		if got := FormatScore(score); got != want {
+			t.Errorf("FormatScore(%v) = %q, want %q", score, got, want)
+		}
+	}
+}
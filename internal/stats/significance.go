@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// exactLimit is the largest sample size for which the exact distribution
+// of the Mann-Whitney U statistic is computed
+const exactLimit = 50
+
+// MannWhitneyU performs a two-sided Mann-Whitney U test of whether x and y
+// come from the same distribution, as benchstat does. It returns the U
+// statistic of x and the p-value. Small samples without ties use the exact
+// distribution of U; otherwise the normal approximation with tie and
+// continuity corrections is used. Empty samples give a p-value of 1.
+func MannWhitneyU(x, y []float64) (u, p float64) {
+	m, n := len(x), len(y)
+	if m == 0 || n == 0 {
+		return 0, 1
+	}
+
+	// Rank the pooled samples, giving tied values their average rank
+	type sample struct {
+		value float64
+		fromX bool
+	}
+	pooled := make([]sample, 0, m+n)
+	for _, v := range x {
+		pooled = append(pooled, sample{v, true})
+	}
+	for _, v := range y {
+		pooled = append(pooled, sample{v, false})
+	}
+	sort.Slice(pooled, func(i, j int) bool { return pooled[i].value < pooled[j].value })
+
+	var rankSumX, tieTerm float64
+	for i := 0; i < len(pooled); {
+		j := i
+		for j < len(pooled) && pooled[j].value == pooled[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // Average of the 1-based ranks i+1..j
+		for k := i; k < j; k++ {
+			if pooled[k].fromX {
+				rankSumX += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+	u = rankSumX - float64(m*(m+1))/2
+
+	if tieTerm == 0 && m <= exactLimit && n <= exactLimit {
+		return u, mannWhitneyExact(int(u), m, n)
+	}
+
+	total := float64(m + n)
+	mean := float64(m*n) / 2
+	variance := float64(m*n) / 12 * ((total + 1) - tieTerm/(total*(total-1)))
+	if variance <= 0 {
+		return u, 1 // All values are equal
+	}
+	z := math.Max(0, math.Abs(u-mean)-0.5) / math.Sqrt(variance)
+	return u, math.Erfc(z / math.Sqrt2)
+}
+
+// mannWhitneyExact returns the two-sided p-value of U = u for samples of
+// sizes m and n without ties.
+//
+// The distribution is built up from smaller samples: the largest of a+b
+// values belongs to x with probability a/(a+b), and is then greater than
+// all b values of y.
+func mannWhitneyExact(u, m, n int) float64 {
+	var prev [][]float64 // Distributions for a-1 values of x, by size of y
+	for a := 0; a <= m; a++ {
+		cur := make([][]float64, n+1)
+		for b := 0; b <= n; b++ {
+			dist := make([]float64, a*b+1)
+			if a == 0 || b == 0 {
+				dist[0] = 1
+			} else {
+				pa := float64(a) / float64(a+b)
+				for k, v := range prev[b] {
+					dist[k+b] += pa * v
+				}
+				for k, v := range cur[b-1] {
+					dist[k] += (1 - pa) * v
+				}
+			}
+			cur[b] = dist
+		}
+		prev = cur
+	}
+
+	var lower, upper float64
+	for k, v := range prev[n] {
+		if k <= u {
+			lower += v
+		}
+		if k >= u {
+			upper += v
+		}
+	}
+	return math.Min(1, 2*math.Min(lower, upper))
+}
+
+// MeanCI returns the mean of values and the half-width of its 95%
+// confidence interval, based on Student's t-distribution. The half-width
+// is 0 for fewer than two values.
+func MeanCI(values []float64) (mean, halfWidth float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	n := float64(len(values))
+	mean /= n
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var sumSquaredDiff float64
+	for _, v := range values {
+		sumSquaredDiff += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(sumSquaredDiff / (n - 1))
+	return mean, tQuantile975(len(values)-1) * stdDev / math.Sqrt(n)
+}
+
+// tTable975 holds the 97.5th percentiles of Student's t-distribution for
+// 1 to 30 degrees of freedom
+var tTable975 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tQuantile975 returns the 97.5th percentile of Student's t-distribution,
+// using a Cornish-Fisher expansion beyond the table
+func tQuantile975(df int) float64 {
+	if df <= len(tTable975) {
+		return tTable975[df-1]
+	}
+	const z = 1.959964
+	d := float64(df)
+	return z + (z*z*z+z)/(4*d) + (5*math.Pow(z, 5)+16*z*z*z+3*z)/(96*d*d)
+}
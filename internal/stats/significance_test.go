@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name      string
+		x, y      []float64
+		expectedU float64
+		expectedP float64
+	}{
+		{
+			name:      "separated samples",
+			x:         []float64{1, 2, 3},
+			y:         []float64{4, 5, 6},
+			expectedU: 0,
+			expectedP: 0.1, // 2 * 1/20
+		},
+		{
+			name:      "separated larger samples",
+			x:         []float64{10, 11, 12, 13, 14},
+			y:         []float64{1, 2, 3, 4, 5},
+			expectedU: 25,
+			expectedP: 2.0 / 252,
+		},
+		{
+			name:      "interleaved samples",
+			x:         []float64{1, 3, 5},
+			y:         []float64{2, 4, 6},
+			expectedU: 3,
+			expectedP: 0.7, // 2 * P(U <= 3) = 2 * 7/20
+		},
+		{
+			name:      "all values tied",
+			x:         []float64{7, 7, 7},
+			y:         []float64{7, 7, 7},
+			expectedU: 4.5,
+			expectedP: 1,
+		},
+		{
+			name:      "empty sample",
+			x:         nil,
+			y:         []float64{1, 2},
+			expectedU: 0,
+			expectedP: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, p := MannWhitneyU(tt.x, tt.y)
+			if u != tt.expectedU {
+				t.Errorf("Expected U %v, got %v", tt.expectedU, u)
+			}
+			if math.Abs(p-tt.expectedP) > 1e-9 {
+				t.Errorf("Expected p-value %v, got %v", tt.expectedP, p)
+			}
+		})
+	}
+}
+
+func TestMannWhitneyUNormalApproximation(t *testing.T) {
+	// Ties force the normal approximation; clearly shifted samples must
+	// still be significant and identical ones must not
+	x := []float64{100, 100, 101, 102, 102, 103, 104, 104, 105, 106}
+	y := []float64{110, 110, 111, 112, 112, 113, 114, 114, 115, 116}
+
+	if _, p := MannWhitneyU(x, y); p >= 0.001 {
+		t.Errorf("Expected a significant p-value for shifted samples, got %v", p)
+	}
+	if _, p := MannWhitneyU(x, x); p < 0.9 {
+		t.Errorf("Expected a p-value near 1 for identical samples, got %v", p)
+	}
+}
+
+func TestMeanCI(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       []float64
+		expectedMean float64
+		expectedHalf float64
+	}{
+		{"three values", []float64{1, 2, 3}, 2, 4.303 / math.Sqrt(3)},
+		{"constant values", []float64{5, 5, 5, 5}, 5, 0},
+		{"single value", []float64{42}, 42, 0},
+		{"empty", nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, half := MeanCI(tt.values)
+			if math.Abs(mean-tt.expectedMean) > 1e-9 {
+				t.Errorf("Expected mean %v, got %v", tt.expectedMean, mean)
+			}
+			if math.Abs(half-tt.expectedHalf) > 1e-9 {
+				t.Errorf("Expected half-width %v, got %v", tt.expectedHalf, half)
+			}
+		})
+	}
+}
+
+func TestTQuantile975(t *testing.T) {
+	// The expansion beyond the table continues it smoothly towards 1.96
+	if q := tQuantile975(31); q >= tQuantile975(30) || q < 2.03 {
+		t.Errorf("Expected t(31) just below t(30), got %v", q)
+	}
+	if q := tQuantile975(1000); math.Abs(q-1.962) > 0.001 {
+		t.Errorf("Expected t(1000) ≈ 1.962, got %v", q)
+	}
+}
@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"math"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Limits of the suggestions made for noisy benchmarks
+const (
+	maxSuggestedCount     = 50
+	maxBenchtimeFactor    = 10
+	minSuggestedBenchtime = time.Second
+)
+
+// Stability describes how much the repetitions of a benchmark vary, and
+// how to measure it more reliably when it is too noisy to gate CI on
+type Stability struct {
+	Benchmark string  `json:"benchmark"`
+	Samples   int     `json:"samples"`
+	Mean      float64 `json:"mean"`     // Mean ns/op of the samples
+	CV        float64 `json:"cv"`       // Coefficient of variation of the samples (%)
+	Outliers  int     `json:"outliers"` // Samples beyond 1.5 interquartile ranges of the quartiles
+	Stable    bool    `json:"stable"`
+
+	// Set for unstable benchmarks
+	SuggestedCount     int           `json:"suggested_count,omitempty"`     // Repetitions bringing the 95% confidence interval of the mean within half the regression threshold
+	SuggestedBenchtime time.Duration `json:"suggested_benchtime,omitempty"` // Longer repetitions averaging out the disturbances behind outliers and variance
+}
+
+// AnalyzeStability analyzes the samples of a result measured with -count.
+// A benchmark is stable when its CV is at most cvThreshold and at most one
+// in ten samples is an outlier. The suggested -count makes regressions of
+// threshold percent stand out of the noise.
+func AnalyzeStability(result models.BenchmarkResult, cvThreshold, threshold float64) Stability {
+	stability := Stability{
+		Benchmark: result.Name,
+		Samples:   len(result.Samples),
+		Mean:      result.NsPerOp,
+	}
+	if stats := NewAnalyzer().calculateStats(result.Name, result.Samples); stats != nil {
+		stability.Mean = stats.Mean
+		stability.CV = stats.CV
+	}
+	stability.Outliers = countOutliers(result.Samples)
+	stability.Stable = stability.CV <= cvThreshold && stability.Outliers*10 <= stability.Samples
+	if stability.Stable {
+		return stability
+	}
+
+	// The half-width of the confidence interval shrinks with the square
+	// root of the number of samples
+	count := int(math.Ceil(math.Pow(1.96*stability.CV/(threshold/2), 2)))
+	stability.SuggestedCount = min(max(count, stability.Samples), maxSuggestedCount)
+
+	// Longer repetitions average out the variance within each of them
+	factor := math.Ceil(math.Pow(stability.CV/cvThreshold, 2))
+	factor = min(max(factor, 2), maxBenchtimeFactor)
+	sample := time.Duration(stability.Mean * float64(result.Iterations))
+	benchtime := max(time.Duration(float64(sample)*factor), minSuggestedBenchtime)
+	stability.SuggestedBenchtime = benchtime.Round(time.Second)
+	return stability
+}
+
+// countOutliers counts the values beyond Tukey's fences: 1.5 interquartile
+// ranges below the first or above the third quartile. The interquartile
+// range is at least 1% of the median, so that the slightest deviation from
+// tightly clustered values is no outlier. Fewer than four values have no
+// meaningful quartiles.
+func countOutliers(values []float64) int {
+	if len(values) < 4 {
+		return 0
+	}
+	q1, q3 := Percentile(values, 25), Percentile(values, 75)
+	iqr := max(q3-q1, Percentile(values, 50)/100)
+	low, high := q1-1.5*iqr, q3+1.5*iqr
+	outliers := 0
+	for _, v := range values {
+		if v < low || v > high {
+			outliers++
+		}
+	}
+	return outliers
+}
@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestAnalyzeStability(t *testing.T) {
+	stable := AnalyzeStability(models.BenchmarkResult{
+		Name:       "Hash",
+		NsPerOp:    100,
+		Iterations: 10_000_000,
+		Samples:    []float64{99, 100, 101, 100, 99, 101, 100, 100, 100, 100},
+	}, 5, 5)
+	if !stable.Stable || stable.Outliers != 0 || stable.Samples != 10 {
+		t.Errorf("Expected Hash to be stable, got %+v", stable)
+	}
+	if stable.SuggestedCount != 0 || stable.SuggestedBenchtime != 0 {
+		t.Errorf("Expected no suggestions for a stable benchmark, got %+v", stable)
+	}
+
+	// A single spike of ten samples is an outlier the CV tolerates
+	spiky := AnalyzeStability(models.BenchmarkResult{
+		Name:       "Parse",
+		Iterations: 1_000_000,
+		Samples:    []float64{100, 100, 101, 99, 100, 100, 101, 99, 100, 108},
+	}, 5, 5)
+	if spiky.Outliers != 1 || !spiky.Stable {
+		t.Errorf("Expected one tolerated outlier, got %+v", spiky)
+	}
+
+	noisy := AnalyzeStability(models.BenchmarkResult{
+		Name:       "Alloc",
+		Iterations: 1_000_000,
+		Samples:    []float64{800, 1000, 1200, 900, 1100, 1000, 800, 1200, 1000, 1000},
+	}, 5, 5)
+	if noisy.Stable {
+		t.Fatalf("Expected Alloc to be noisy, got %+v", noisy)
+	}
+	// A CV of about 13% would need (1.96*13/2.5)^2 samples, over the limit
+	if noisy.SuggestedCount != maxSuggestedCount {
+		t.Errorf("Unexpected suggested count: %d", noisy.SuggestedCount)
+	}
+	// Samples of 1s get longer, within the factor limit
+	if noisy.SuggestedBenchtime < 2*time.Second || noisy.SuggestedBenchtime > maxBenchtimeFactor*time.Second {
+		t.Errorf("Unexpected suggested benchtime: %s", noisy.SuggestedBenchtime)
+	}
+}
+
+func TestCountOutliers(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   int
+	}{
+		{nil, 0},
+		{[]float64{1, 100, 1}, 0},
+		{[]float64{100, 100, 100, 100, 101}, 0},
+		{[]float64{10, 10, 11, 10, 50}, 1},
+		{[]float64{1, 10, 10, 11, 10, 50}, 2},
+	}
+	for _, tt := range tests {
+		if got := countOutliers(tt.values); got != tt.want {
+			t.Errorf("countOutliers(%v) = %d, want %d", tt.values, got, tt.want)
+		}
+	}
+}
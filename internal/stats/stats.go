@@ -36,6 +36,9 @@ func (a *Analyzer) AnalyzeMultiple(runs []models.BenchmarkRun) map[string]*Stats
 
 	for _, run := range runs {
 		for _, result := range run.Results {
+			if !result.Measured() {
+				continue
+			}
 			grouped[result.Name] = append(grouped[result.Name], result.NsPerOp)
 		}
 	}
@@ -49,6 +52,25 @@ func (a *Analyzer) AnalyzeMultiple(runs []models.BenchmarkRun) map[string]*Stats
 	return stats
 }
 
+// FromAggregate returns the statistics of a benchmark from its running
+// statistics. The median needs every value, so it is NaN.
+func FromAggregate(name string, agg *models.Aggregate) *Stats {
+	stats := &Stats{
+		Name:     name,
+		Count:    agg.Count,
+		Mean:     agg.Mean,
+		Median:   math.NaN(),
+		Min:      agg.Min,
+		Max:      agg.Max,
+		Variance: agg.Variance(),
+		StdDev:   agg.StdDev(),
+	}
+	if stats.Mean != 0 {
+		stats.CV = (stats.StdDev / stats.Mean) * 100
+	}
+	return stats
+}
+
 // calculateStats calculates statistical measures for a set of values
 func (a *Analyzer) calculateStats(name string, values []float64) *Stats {
 	if len(values) == 0 {
@@ -100,12 +122,16 @@ func (a *Analyzer) calculateStats(name string, values []float64) *Stats {
 
 // FormatStats formats statistics for display
 func FormatStats(stats *Stats) string {
+	median := fmt.Sprintf("%10.2f", stats.Median)
+	if math.IsNaN(stats.Median) {
+		median = fmt.Sprintf("%10s", "-")
+	}
 	return fmt.Sprintf(
-		"%-40s Count: %3d | Mean: %10.2f ns/op | Median: %10.2f ns/op | StdDev: %8.2f (±%.1f%%) | Range: [%.2f - %.2f]",
+		"%-40s Count: %3d | Mean: %10.2f ns/op | Median: %s ns/op | StdDev: %8.2f (±%.1f%%) | Range: [%.2f - %.2f]",
 		stats.Name,
 		stats.Count,
 		stats.Mean,
-		stats.Median,
+		median,
 		stats.StdDev,
 		stats.CV,
 		stats.Min,
@@ -128,14 +154,22 @@ type TrendAnalysis struct {
 
 // AnalyzeTrend analyzes the performance trend over time
 func (a *Analyzer) AnalyzeTrend(runs []models.BenchmarkRun, benchmarkName string) *TrendAnalysis {
+	return a.AnalyzeMetricTrend(runs, benchmarkName, "")
+}
+
+// AnalyzeMetricTrend analyzes the trend of a custom metric over time.
+// An empty metric name analyzes ns/op. Direction assumes lower is better.
+func (a *Analyzer) AnalyzeMetricTrend(runs []models.BenchmarkRun, benchmarkName, metric string) *TrendAnalysis {
 	var values []float64
 	var times []float64
 
 	for i, run := range runs {
 		for _, result := range run.Results {
-			if result.Name == benchmarkName {
-				values = append(values, result.NsPerOp)
-				times = append(times, float64(i))
+			if result.Name == benchmarkName && result.Measured() {
+				if v, ok := MetricValue(result, metric); ok {
+					values = append(values, v)
+					times = append(times, float64(i))
+				}
 				break
 			}
 		}
@@ -146,7 +180,7 @@ func (a *Analyzer) AnalyzeTrend(runs []models.BenchmarkRun, benchmarkName string
 	}
 
 	// Calculate linear regression
-	slope, _, rSquared := linearRegression(times, values)
+	slope, _, rSquared := LinearRegression(times, values)
 
 	direction := "stable"
 	if math.Abs(slope) > 1.0 { // Threshold for meaningful change
@@ -165,9 +199,45 @@ func (a *Analyzer) AnalyzeTrend(runs []models.BenchmarkRun, benchmarkName string
 	}
 }
 
-// linearRegression calculates the linear regression for the given data
+// MetricValue returns the value of a metric for a result. An empty name or
+// "ns/op" selects the time per operation and "contention-ns/op" the block
+// and mutex delay per operation of profiled runs; other names look up
+// custom metrics.
+func MetricValue(result models.BenchmarkResult, metric string) (float64, bool) {
+	if metric == "" || metric == "ns/op" {
+		return result.NsPerOp, true
+	}
+	if metric == models.ContentionMetric && result.ContentionNsPerOp != 0 {
+		return result.ContentionNsPerOp, true
+	}
+	v, ok := result.Metrics[metric]
+	return v, ok
+}
+
+// Percentile returns the p-th percentile (0-100) of values using the
+// nearest-rank method, so the result is always one of the values. It
+// returns 0 for no values.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// LinearRegression fits y = slope*x + intercept by least squares
 // Returns: slope, intercept, r-squared
-func linearRegression(x, y []float64) (float64, float64, float64) {
+func LinearRegression(x, y []float64) (float64, float64, float64) {
 	n := float64(len(x))
 
 	var sumX, sumY, sumXY, sumX2, sumY2 float64
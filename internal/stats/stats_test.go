@@ -251,12 +251,35 @@ func TestFormatStats(t *testing.T) {
 	}
 }
 
+func TestFromAggregate(t *testing.T) {
+	var agg models.Aggregate
+	values := []float64{100, 110, 120, 130}
+	for _, v := range values {
+		agg.Add(v)
+	}
+
+	got := FromAggregate("BenchmarkTest", &agg)
+	want := NewAnalyzer().calculateStats("BenchmarkTest", values)
+	if got.Count != want.Count || got.Mean != want.Mean || got.Min != want.Min || got.Max != want.Max {
+		t.Errorf("FromAggregate = %+v, want %+v", got, want)
+	}
+	if math.Abs(got.StdDev-want.StdDev) > 1e-9 || math.Abs(got.CV-want.CV) > 1e-9 {
+		t.Errorf("StdDev and CV = %v, %v, want %v, %v", got.StdDev, got.CV, want.StdDev, want.CV)
+	}
+	if !math.IsNaN(got.Median) {
+		t.Errorf("Expected no median, got %v", got.Median)
+	}
+	if formatted := FormatStats(got); !strings.Contains(formatted, "Median:          - ns/op") {
+		t.Errorf("Expected the missing median as -, got: %s", formatted)
+	}
+}
+
 func TestLinearRegression(t *testing.T) {
 	// Test with perfect linear relationship y = 2x + 1
 	x := []float64{1, 2, 3, 4, 5}
 	y := []float64{3, 5, 7, 9, 11}
 
-	slope, intercept, rSquared := linearRegression(x, y)
+	slope, intercept, rSquared := LinearRegression(x, y)
 
 	// Slope should be 2
 	if math.Abs(slope-2.0) > 0.01 {
@@ -279,7 +302,7 @@ func TestLinearRegressionImperfect(t *testing.T) {
 	x := []float64{1, 2, 3, 4, 5}
 	y := []float64{2.1, 3.9, 6.2, 7.8, 10.1}
 
-	slope, _, rSquared := linearRegression(x, y)
+	slope, _, rSquared := LinearRegression(x, y)
 
 	// Slope should be approximately 2
 	if math.Abs(slope-2.0) > 0.5 {
@@ -426,3 +449,91 @@ func TestAnalyzeTrendNonExistentBenchmark(t *testing.T) {
 		t.Error("Expected nil trend for non-existent benchmark")
 	}
 }
+
+func TestAnalyzeMetricTrend(t *testing.T) {
+	a := NewAnalyzer()
+	runs := []models.BenchmarkRun{
+		{Results: []models.BenchmarkResult{{Name: "BenchmarkCache", NsPerOp: 100, Metrics: map[string]float64{"misses": 10}}}},
+		{Results: []models.BenchmarkResult{{Name: "BenchmarkCache", NsPerOp: 100}}},
+		{Results: []models.BenchmarkResult{{Name: "BenchmarkCache", NsPerOp: 100, Metrics: map[string]float64{"misses": 30}}}},
+		{Results: []models.BenchmarkResult{{Name: "BenchmarkCache", NsPerOp: 100, Metrics: map[string]float64{"misses": 40}}}},
+	}
+
+	trend := a.AnalyzeMetricTrend(runs, "BenchmarkCache", "misses")
+	if trend == nil {
+		t.Fatal("Expected trend analysis")
+	}
+	if trend.Direction != "degrading" {
+		t.Errorf("Expected degrading trend for increasing misses, got %s", trend.Direction)
+	}
+	if math.Abs(trend.TrendLine-10) > 0.01 {
+		t.Errorf("Expected slope of 10 per run (run without metric skipped), got %f", trend.TrendLine)
+	}
+
+	if trend := a.AnalyzeMetricTrend(runs, "BenchmarkCache", "unknown"); trend != nil {
+		t.Errorf("Expected nil trend for unknown metric, got %+v", trend)
+	}
+
+	if trend := a.AnalyzeMetricTrend(runs, "BenchmarkCache", "ns/op"); trend == nil || trend.Direction != "stable" {
+		t.Errorf("Expected stable ns/op trend, got %+v", trend)
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	result := models.BenchmarkResult{NsPerOp: 12, Metrics: map[string]float64{"hit_ratio": 0.9}}
+
+	if v, ok := MetricValue(result, ""); !ok || v != 12 {
+		t.Errorf("MetricValue(\"\") = %v, %v", v, ok)
+	}
+	if v, ok := MetricValue(result, "ns/op"); !ok || v != 12 {
+		t.Errorf("MetricValue(ns/op) = %v, %v", v, ok)
+	}
+	if v, ok := MetricValue(result, "hit_ratio"); !ok || v != 0.9 {
+		t.Errorf("MetricValue(hit_ratio) = %v, %v", v, ok)
+	}
+	if _, ok := MetricValue(result, "missing"); ok {
+		t.Error("Expected missing metric to report false")
+	}
+}
+
+func TestAnalyzeMultipleIgnoresFailed(t *testing.T) {
+	a := NewAnalyzer()
+	runs := []models.BenchmarkRun{
+		{Results: []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: 100}}},
+		{Results: []models.BenchmarkResult{{Name: "BenchmarkA", Status: models.StatusFailed}}},
+		{Results: []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: 200, Status: models.StatusOK}}},
+	}
+
+	stats := a.AnalyzeMultiple(runs)
+	if stats["BenchmarkA"].Count != 2 {
+		t.Errorf("Expected failed result to be excluded, got count %d", stats["BenchmarkA"].Count)
+	}
+	if stats["BenchmarkA"].Mean != 150 {
+		t.Errorf("Expected mean 150, got %f", stats["BenchmarkA"].Mean)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{50, 5},
+		{90, 9},
+		{95, 10},
+		{100, 10},
+	}
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil) = %v, want 0", got)
+	}
+	if values[0] != 5 {
+		t.Error("Percentile sorted its input")
+	}
+}
@@ -0,0 +1,146 @@
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// StressPoint is the throughput of a benchmark at one parallelism level of
+// a stress run
+type StressPoint struct {
+	Parallelism int     `json:"parallelism"`
+	NsPerOp     float64 `json:"nsPerOp"`
+	Speedup     float64 `json:"speedup"`    // Throughput relative to the lowest level
+	Efficiency  float64 `json:"efficiency"` // Speedup per unit of added parallelism, 1 for linear scaling
+}
+
+// StressCurve is the speedup curve of a benchmark across the parallelism
+// levels of a stress run
+type StressCurve struct {
+	Benchmark string        `json:"benchmark"`
+	Points    []StressPoint `json:"points"`
+	Peak      int           `json:"peak"`      // Level with the highest throughput
+	Contended bool          `json:"contended"` // Throughput falls beyond the peak at a level the CPUs run in parallel
+}
+
+// StressReport returns the speedup curves of the benchmarks of a stress
+// run measured at several parallelism levels, sorted by name. Only the
+// benchmarks calling RunParallel are reported when they are known. A run
+// without a stress matrix has no curves.
+func StressReport(run *models.BenchmarkRun) []StressCurve {
+	if run.Stress == nil {
+		return nil
+	}
+
+	levels := make(map[int]bool, len(run.Stress.Levels))
+	for _, level := range run.Stress.Levels {
+		levels[level] = true
+	}
+	parallel := make(map[string]bool, len(run.Stress.Benchmarks))
+	for _, name := range run.Stress.Benchmarks {
+		parallel[name] = true
+	}
+
+	points := make(map[string][]StressPoint)
+	for _, result := range run.Results {
+		if !result.Measured() || result.NsPerOp <= 0 {
+			continue
+		}
+		if len(parallel) > 0 && !parallel[result.Family()] {
+			continue
+		}
+		name, level := splitProcs(result.Name)
+		if !levels[level] {
+			continue
+		}
+		points[name] = append(points[name], StressPoint{Parallelism: level, NsPerOp: result.NsPerOp})
+	}
+	return speedupCurves(points, run.Stress.CPUs)
+}
+
+// ScalingReport returns the scalability curves of the benchmarks of a run
+// with a CPU matrix, ns/op and speedup at each GOMAXPROCS value, sorted by
+// name. Unlike a stress report, it covers every benchmark. A run without a
+// CPU matrix has no curves.
+func ScalingReport(run *models.BenchmarkRun) []StressCurve {
+	if len(run.CPUMatrix) < 2 {
+		return nil
+	}
+
+	points := make(map[string][]StressPoint)
+	for _, result := range run.Results {
+		if !result.Measured() || result.NsPerOp <= 0 || result.Procs <= 0 {
+			continue
+		}
+		name := result.Name
+		if result.Procs > 1 {
+			name, _ = splitProcs(name)
+		}
+		points[name] = append(points[name], StressPoint{Parallelism: result.Procs, NsPerOp: result.NsPerOp})
+	}
+	cpus := 0
+	if run.Environment != nil {
+		cpus = run.Environment.CPUs
+	}
+	return speedupCurves(points, cpus)
+}
+
+// speedupCurves computes the speedup and efficiency of each benchmark's
+// points relative to its lowest level, leaving out benchmarks measured at
+// a single level. cpus is the number of logical CPUs, 0 when unknown.
+func speedupCurves(points map[string][]StressPoint, cpus int) []StressCurve {
+	var curves []StressCurve
+	for name, curve := range points {
+		if len(curve) < 2 {
+			continue
+		}
+		sort.Slice(curve, func(i, j int) bool { return curve[i].Parallelism < curve[j].Parallelism })
+		base := curve[0]
+		peak := base
+		for i := range curve {
+			p := &curve[i]
+			p.Speedup = base.NsPerOp / p.NsPerOp
+			p.Efficiency = p.Speedup * float64(base.Parallelism) / float64(p.Parallelism)
+			if p.NsPerOp < peak.NsPerOp {
+				peak = *p
+			}
+		}
+		curves = append(curves, StressCurve{
+			Benchmark: name,
+			Points:    curve,
+			Peak:      peak.Parallelism,
+			Contended: contended(curve, peak.Parallelism, cpus),
+		})
+	}
+	sort.Slice(curves, func(i, j int) bool { return curves[i].Benchmark < curves[j].Benchmark })
+	return curves
+}
+
+// contended reports whether a curve has a level above its peak that the
+// CPUs run in parallel. Beyond the CPU count, goroutines share CPUs and
+// throughput may drop without contention.
+func contended(curve []StressPoint, peak, cpus int) bool {
+	for _, p := range curve {
+		if p.Parallelism > peak && (cpus <= 0 || p.Parallelism <= cpus) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitProcs splits a benchmark name into the name without the GOMAXPROCS
+// suffix and the GOMAXPROCS it ran with, which go test leaves out for 1
+func splitProcs(name string) (string, int) {
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return name, 1
+	}
+	procs, err := strconv.Atoi(name[i+1:])
+	if err != nil || procs <= 0 {
+		return name, 1
+	}
+	return name[:i], procs
+}
@@ -0,0 +1,93 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestStressReport(t *testing.T) {
+	run := &models.BenchmarkRun{
+		Stress: &models.StressMatrix{Levels: []int{1, 4, 16}, CPUs: 16, Benchmarks: []string{"Get", "Sizes"}},
+		Results: []models.BenchmarkResult{
+			{Name: "Get", NsPerOp: 400},
+			{Name: "Get-4", NsPerOp: 100},
+			{Name: "Get-16", NsPerOp: 200},
+			{Name: "Sizes/64", NsPerOp: 800},
+			{Name: "Sizes/64-16", NsPerOp: 100},
+			{Name: "Sizes/64-4", Status: models.StatusFailed},
+			// Only measured at one level
+			{Name: "Sizes/1-4", NsPerOp: 10},
+			// Not calling RunParallel
+			{Name: "Serial", NsPerOp: 10},
+			{Name: "Serial-4", NsPerOp: 10},
+		},
+	}
+
+	curves := StressReport(run)
+	if len(curves) != 2 || curves[0].Benchmark != "Get" || curves[1].Benchmark != "Sizes/64" {
+		t.Fatalf("Expected the curves of Get and Sizes/64, got %+v", curves)
+	}
+
+	get := curves[0]
+	if get.Peak != 4 || !get.Contended || len(get.Points) != 3 {
+		t.Fatalf("Expected Get to peak at 4 over 3 levels, got %+v", get)
+	}
+	want := []StressPoint{
+		{Parallelism: 1, NsPerOp: 400, Speedup: 1, Efficiency: 1},
+		{Parallelism: 4, NsPerOp: 100, Speedup: 4, Efficiency: 1},
+		{Parallelism: 16, NsPerOp: 200, Speedup: 2, Efficiency: 0.125},
+	}
+	for i, p := range get.Points {
+		if p.Parallelism != want[i].Parallelism || math.Abs(p.Speedup-want[i].Speedup) > 1e-9 ||
+			math.Abs(p.Efficiency-want[i].Efficiency) > 1e-9 {
+			t.Errorf("Point %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+
+	if sizes := curves[1]; sizes.Peak != 16 || sizes.Contended || sizes.Points[1].Efficiency != 0.5 {
+		t.Errorf("Unexpected Sizes/64 curve: %+v", sizes)
+	}
+
+	// Throughput falling only with more goroutines than CPUs is not contention
+	run.Stress.CPUs = 4
+	if curves := StressReport(run); curves[0].Contended {
+		t.Errorf("Expected Get not to be contended on 4 CPUs: %+v", curves[0])
+	}
+
+	if StressReport(&models.BenchmarkRun{Results: run.Results}) != nil {
+		t.Error("Expected no curves for a run without a stress matrix")
+	}
+}
+
+func TestScalingReport(t *testing.T) {
+	run := &models.BenchmarkRun{
+		CPUMatrix:   []int{1, 2, 4},
+		Environment: &models.Environment{CPUs: 4},
+		Results: []models.BenchmarkResult{
+			{Name: "BenchmarkEncode", NsPerOp: 400, Procs: 1},
+			{Name: "BenchmarkEncode-2", NsPerOp: 200, Procs: 2},
+			{Name: "BenchmarkEncode-4", NsPerOp: 250, Procs: 4},
+			{Name: "BenchmarkSizes/size-64", NsPerOp: 90, Procs: 1},
+			{Name: "BenchmarkSizes/size-64-4", NsPerOp: 30, Procs: 4},
+			// Recorded before the run had a CPU matrix
+			{Name: "BenchmarkOld-2", NsPerOp: 10},
+		},
+	}
+
+	curves := ScalingReport(run)
+	if len(curves) != 2 || curves[0].Benchmark != "BenchmarkEncode" || curves[1].Benchmark != "BenchmarkSizes/size-64" {
+		t.Fatalf("Expected the curves of Encode and Sizes/size-64, got %+v", curves)
+	}
+	if encode := curves[0]; encode.Peak != 2 || !encode.Contended || encode.Points[1].Speedup != 2 {
+		t.Errorf("Unexpected Encode curve: %+v", encode)
+	}
+	if sizes := curves[1]; sizes.Points[1].Parallelism != 4 || sizes.Points[1].Speedup != 3 || sizes.Points[1].Efficiency != 0.75 {
+		t.Errorf("Unexpected Sizes/size-64 curve: %+v", sizes)
+	}
+
+	if ScalingReport(&models.BenchmarkRun{Results: run.Results}) != nil {
+		t.Error("Expected no curves for a run without a CPU matrix")
+	}
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// aggregatesFile caches the statistics of every benchmark across all saved
+// runs. Like the live status it does not use the .json extension, so that
+// it is never listed as a run.
+const aggregatesFile = "stats.cache"
+
+// Aggregates are the running statistics of each benchmark across all runs
+// in the storage, keyed by benchmark name
+type Aggregates struct {
+	Runs       []string                     `json:"runs"` // Sorted IDs of the runs included
+	First      time.Time                    `json:"first"`
+	Last       time.Time                    `json:"last"`
+	Benchmarks map[string]*models.Aggregate `json:"benchmarks"`
+}
+
+// add includes the measured results of a run. Runs that terminated
+// abnormally or ran in an unstable environment are listed, to keep the
+// cache valid, but not included.
+func (a *Aggregates) add(run *models.BenchmarkRun) {
+	if i, found := slices.BinarySearch(a.Runs, run.ID); !found {
+		a.Runs = slices.Insert(a.Runs, i, run.ID)
+	}
+	if run.Failed() || run.Degraded() {
+		return
+	}
+	if a.First.IsZero() || run.Timestamp.Before(a.First) {
+		a.First = run.Timestamp
+	}
+	if run.Timestamp.After(a.Last) {
+		a.Last = run.Timestamp
+	}
+	if a.Benchmarks == nil {
+		a.Benchmarks = make(map[string]*models.Aggregate)
+	}
+	for _, result := range run.Results {
+		if !result.Measured() {
+			continue
+		}
+		agg := a.Benchmarks[result.Name]
+		if agg == nil {
+			agg = &models.Aggregate{}
+			a.Benchmarks[result.Name] = agg
+		}
+		agg.Add(result.NsPerOp)
+	}
+}
+
+// GetAggregatesPath returns the path to the statistics cache
+func (s *Storage) GetAggregatesPath() string {
+	return filepath.Join(s.dir, aggregatesFile)
+}
+
+// Aggregates returns the statistics of each benchmark across all runs.
+// They are read from the cache that Save keeps up to date, and rebuilt
+// from the full history only when runs were added or removed some other
+// way.
+func (s *Storage) Aggregates() (*Aggregates, error) {
+	ids, err := s.RunIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, err := s.loadAggregates(); err == nil && slices.Equal(cached.Runs, ids) {
+		return cached, nil
+	}
+
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	aggregates := &Aggregates{Benchmarks: make(map[string]*models.Aggregate)}
+	// Oldest first, so that rebuilt statistics equal incremental ones
+	for i := len(runs) - 1; i >= 0; i-- {
+		aggregates.add(&runs[i])
+	}
+	// Unreadable run files are skipped by List; record them as included so
+	// they do not trigger a rebuild every time
+	aggregates.Runs = ids
+	if len(ids) > 0 {
+		// The cache is an optimization: in read-only storage it is rebuilt
+		// every time instead
+		s.saveAggregates(aggregates)
+	}
+	return aggregates, nil
+}
+
+// updateAggregates adds a newly saved run to the statistics cache. A run
+// that was saved before is already included, so the cache is dropped and
+// rebuilt on its next use instead.
+func (s *Storage) updateAggregates(run *models.BenchmarkRun) error {
+	aggregates, err := s.loadAggregates()
+	if os.IsNotExist(err) {
+		// Without a cache the first read builds it from the full history
+		return nil
+	}
+	if err != nil {
+		return s.invalidateAggregates()
+	}
+	if _, found := slices.BinarySearch(aggregates.Runs, run.ID); found {
+		return s.invalidateAggregates()
+	}
+	aggregates.add(run)
+	return s.saveAggregates(aggregates)
+}
+
+// invalidateAggregates removes the statistics cache
+func (s *Storage) invalidateAggregates() error {
+	if err := os.Remove(s.GetAggregatesPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove statistics cache: %w", err)
+	}
+	return nil
+}
+
+// loadAggregates reads the statistics cache
+func (s *Storage) loadAggregates() (*Aggregates, error) {
+	data, err := os.ReadFile(s.GetAggregatesPath())
+	if err != nil {
+		return nil, err
+	}
+	var aggregates Aggregates
+	if err := json.Unmarshal(data, &aggregates); err != nil {
+		return nil, fmt.Errorf("failed to parse statistics cache: %w", err)
+	}
+	return &aggregates, nil
+}
+
+// saveAggregates replaces the statistics cache atomically
+func (s *Storage) saveAggregates(aggregates *Aggregates) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	data, err := json.Marshal(aggregates)
+	if err != nil {
+		return fmt.Errorf("failed to marshal statistics cache: %w", err)
+	}
+
+	tmp := s.GetAggregatesPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write statistics cache: %w", err)
+	}
+	if err := replaceFile(tmp, s.GetAggregatesPath()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write statistics cache: %w", err)
+	}
+	return nil
+}
+
+// RunIDs returns the sorted IDs of the saved runs without loading them
+func (s *Storage) RunIDs() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage directory: %w", err)
+	}
+
+	ids := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if id, ok := runFileID(entry.Name()); ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	// A run saved by an older version may not be removed yet
+	return slices.Compact(ids), nil
+}
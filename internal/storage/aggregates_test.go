@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestAggregates(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	aggregates, err := s.Aggregates()
+	if err != nil || len(aggregates.Benchmarks) != 0 {
+		t.Fatalf("Expected no statistics in empty storage, got %+v, %v", aggregates, err)
+	}
+
+	now := time.Now()
+	save := func(id string, offset time.Duration, nsPerOp float64) {
+		t.Helper()
+		run := &models.BenchmarkRun{
+			ID:        id,
+			Timestamp: now.Add(offset),
+			Results: []models.BenchmarkResult{
+				{Name: "Parse", NsPerOp: nsPerOp},
+				{Name: "Broken", Status: models.StatusFailed},
+			},
+		}
+		if err := s.Save(run); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	save("run-1", 0, 100)
+	save("run-2", time.Hour, 200)
+
+	// The first read builds the cache from the history
+	aggregates, err = s.Aggregates()
+	if err != nil {
+		t.Fatalf("Aggregates failed: %v", err)
+	}
+	if _, err := os.Stat(s.GetAggregatesPath()); err != nil {
+		t.Fatalf("Expected a statistics cache: %v", err)
+	}
+	if _, ok := aggregates.Benchmarks["Broken"]; ok {
+		t.Error("Expected failed results to be left out")
+	}
+
+	// Later saves update it incrementally
+	save("run-3", 2*time.Hour, 600)
+	cached, err := s.loadAggregates()
+	if err != nil {
+		t.Fatalf("Failed to read the cache: %v", err)
+	}
+	parse := cached.Benchmarks["Parse"]
+	if parse.Count != 3 || parse.Mean != 300 || parse.Min != 100 || parse.Max != 600 {
+		t.Errorf("Unexpected statistics: %+v", parse)
+	}
+	if want := 14e4 / 3; math.Abs(parse.Variance()-want) > 1e-6 {
+		t.Errorf("Variance = %v, want %v", parse.Variance(), want)
+	}
+	if len(cached.Runs) != 3 || !cached.Last.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("Unexpected runs in the cache: %v, last %v", cached.Runs, cached.Last)
+	}
+
+	// Runs that terminated abnormally are left out
+	failed := &models.BenchmarkRun{
+		ID:        "run-failed",
+		Timestamp: now.Add(3 * time.Hour),
+		Status:    models.StatusFailed,
+		Results:   []models.BenchmarkResult{{Name: "Parse", NsPerOp: 5000}},
+	}
+	if err := s.Save(failed); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	aggregates, err = s.Aggregates()
+	if err != nil {
+		t.Fatalf("Aggregates failed: %v", err)
+	}
+	if parse := aggregates.Benchmarks["Parse"]; parse.Count != 3 || parse.Max != 600 {
+		t.Errorf("Expected the failed run to be left out, got %+v", parse)
+	}
+	if err := s.Delete("run-failed"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	// Deleting a run drops the cache, and the next read rebuilds it
+	if err := s.Delete("run-3"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(s.GetAggregatesPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected the cache to be removed, got %v", err)
+	}
+	aggregates, err = s.Aggregates()
+	if err != nil {
+		t.Fatalf("Aggregates failed: %v", err)
+	}
+	if parse := aggregates.Benchmarks["Parse"]; parse.Count != 2 || parse.Mean != 150 {
+		t.Errorf("Unexpected statistics after delete: %+v", parse)
+	}
+
+	// Runs written by other means are picked up as well
+	data, _ := s.RunData("run-1")
+	if err := os.WriteFile(filepath.Join(s.dir, "run-copy.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	aggregates, err = s.Aggregates()
+	if err != nil {
+		t.Fatalf("Aggregates failed: %v", err)
+	}
+	if parse := aggregates.Benchmarks["Parse"]; parse.Count != 3 {
+		t.Errorf("Expected the copied run to be included, got %+v", parse)
+	}
+}
+
+func TestAggregatesResave(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	run := &models.BenchmarkRun{ID: "run-1", Timestamp: time.Now(), Results: []models.BenchmarkResult{{Name: "A", NsPerOp: 10}}}
+	if err := s.Save(run); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Aggregates(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving a run again, e.g. after annotating it, must not count it twice
+	run.Results[0].NsPerOp = 20
+	if err := s.Save(run); err != nil {
+		t.Fatal(err)
+	}
+	aggregates, err := s.Aggregates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := aggregates.Benchmarks["A"]; a.Count != 1 || a.Mean != 20 {
+		t.Errorf("Unexpected statistics: %+v", a)
+	}
+}
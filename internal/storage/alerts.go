@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// alertStateFile records which alert rules are firing, without the .json
+// extension so that it is never listed as a saved run
+const alertStateFile = "alerts.state"
+
+// SaveFiringAlerts records the alerts firing after the latest evaluation:
+// since when each benchmark fired, by rule name. The file is replaced
+// atomically.
+func (s *Storage) SaveFiringAlerts(firing map[string]map[string]time.Time) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	data, err := json.Marshal(firing)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert state: %w", err)
+	}
+
+	path := filepath.Join(s.dir, alertStateFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write alert state: %w", err)
+	}
+	if err := replaceFile(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write alert state: %w", err)
+	}
+	return nil
+}
+
+// LoadFiringAlerts returns the alerts recorded as firing by SaveFiringAlerts,
+// empty when none was ever recorded
+func (s *Storage) LoadFiringAlerts() (map[string]map[string]time.Time, error) {
+	firing := make(map[string]map[string]time.Time)
+	data, err := os.ReadFile(filepath.Join(s.dir, alertStateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return firing, nil
+		}
+		return nil, fmt.Errorf("failed to read alert state: %w", err)
+	}
+	if err := json.Unmarshal(data, &firing); err != nil {
+		return nil, fmt.Errorf("failed to parse alert state: %w", err)
+	}
+	return firing, nil
+}
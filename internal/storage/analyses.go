@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// GetAnalysisDir returns the directory of the cached AI analyses
+func (s *Storage) GetAnalysisDir() string {
+	return filepath.Join(s.dir, "analyses")
+}
+
+// validateAnalysisKey checks that a key is hex-encoded, and so safe as a
+// file name
+func validateAnalysisKey(key string) error {
+	if key == "" || strings.Trim(key, "0123456789abcdef") != "" {
+		return fmt.Errorf("invalid analysis key %q", key)
+	}
+	return nil
+}
+
+// SaveAnalysis caches an AI analysis, replacing one with the same key
+func (s *Storage) SaveAnalysis(analysis *models.AIAnalysis) error {
+	if err := validateAnalysisKey(analysis.Key); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.GetAnalysisDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create analyses directory: %w", err)
+	}
+	data, err := json.MarshalIndent(analysis, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal analysis: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.GetAnalysisDir(), analysis.Key+".json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write analysis: %w", err)
+	}
+	return nil
+}
+
+// LoadAnalysis returns a cached AI analysis by key. The error satisfies
+// os.IsNotExist when there is none.
+func (s *Storage) LoadAnalysis(key string) (*models.AIAnalysis, error) {
+	if err := validateAnalysisKey(key); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.GetAnalysisDir(), key+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var analysis models.AIAnalysis
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal analysis: %w", err)
+	}
+	return &analysis, nil
+}
+
+// ListAnalyses returns the cached AI analyses, newest first. With run IDs,
+// only the analyses of all of those runs are returned.
+func (s *Storage) ListAnalyses(runIDs ...string) ([]models.AIAnalysis, error) {
+	entries, err := os.ReadDir(s.GetAnalysisDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read analyses directory: %w", err)
+	}
+
+	var analyses []models.AIAnalysis
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		analysis, err := s.LoadAnalysis(entry.Name()[:len(entry.Name())-5])
+		if err != nil {
+			continue // Skip invalid files
+		}
+		if !containsAll(analysis.RunIDs, runIDs) {
+			continue
+		}
+		analyses = append(analyses, *analysis)
+	}
+	sort.Slice(analyses, func(i, j int) bool {
+		return analyses[i].CreatedAt.After(analyses[j].CreatedAt)
+	})
+	return analyses, nil
+}
+
+// deleteAnalyses drops the cached analyses of a deleted run
+func (s *Storage) deleteAnalyses(id string) error {
+	analyses, err := s.ListAnalyses(id)
+	if err != nil {
+		return err
+	}
+	for _, analysis := range analyses {
+		if err := os.Remove(filepath.Join(s.GetAnalysisDir(), analysis.Key+".json")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete analysis: %w", err)
+		}
+	}
+	return nil
+}
+
+// containsAll reports whether every one of want is in have
+func containsAll(have, want []string) bool {
+	for _, id := range want {
+		if !slices.Contains(have, id) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestAnalyses(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	if analyses, err := s.ListAnalyses(); err != nil || len(analyses) != 0 {
+		t.Fatalf("ListAnalyses of empty storage = %v, %v", analyses, err)
+	}
+	if _, err := s.LoadAnalysis("abc123"); !os.IsNotExist(err) {
+		t.Errorf("LoadAnalysis of a missing analysis: %v", err)
+	}
+
+	now := time.Now()
+	for _, analysis := range []*models.AIAnalysis{
+		{Key: "aa", Kind: "comparison", RunIDs: []string{"run-1", "run-2"}, Response: "slower", CreatedAt: now.Add(-time.Hour)},
+		{Key: "bb", Kind: "profile", RunIDs: []string{"run-2"}, Response: "hot loop", CreatedAt: now},
+	} {
+		if err := s.SaveAnalysis(analysis); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SaveAnalysis(&models.AIAnalysis{Key: "../escape"}); err == nil {
+		t.Error("SaveAnalysis accepted a key that is not hex-encoded")
+	}
+
+	analysis, err := s.LoadAnalysis("aa")
+	if err != nil || analysis.Response != "slower" {
+		t.Fatalf("LoadAnalysis = %+v, %v", analysis, err)
+	}
+	analyses, err := s.ListAnalyses()
+	if err != nil || len(analyses) != 2 || analyses[0].Key != "bb" {
+		t.Errorf("ListAnalyses = %+v, %v; want newest first", analyses, err)
+	}
+	if analyses, _ := s.ListAnalyses("run-1"); len(analyses) != 1 || analyses[0].Key != "aa" {
+		t.Errorf("ListAnalyses(run-1) = %+v", analyses)
+	}
+
+	// Deleting a run drops its analyses
+	if err := s.Save(&models.BenchmarkRun{ID: "run-1", Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("run-1"); err != nil {
+		t.Fatal(err)
+	}
+	if analyses, _ := s.ListAnalyses(); len(analyses) != 1 || analyses[0].Key != "bb" {
+		t.Errorf("Analyses after deleting run-1 = %+v", analyses)
+	}
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Run records are saved as <id>.json.gz. Records of older versions, saved
+// as <id>.json, are still read, and replaced when the run is next saved.
+const (
+	runExt           = ".json"
+	compressedRunExt = ".json.gz"
+)
+
+// gzipMagic starts every gzip stream, such as a pprof profile written by
+// the Go runtime
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isGzip reports whether data is gzip-compressed
+func isGzip(data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+// compress gzips data
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress reads gzip-compressed data
+func decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// runFileID returns the run ID of a file name in the storage directory
+func runFileID(name string) (string, bool) {
+	if id, ok := strings.CutSuffix(name, compressedRunExt); ok {
+		return id, true
+	}
+	return strings.CutSuffix(name, runExt)
+}
+
+// runPaths returns the compressed and the legacy path of a run record
+func (s *Storage) runPaths(id string) (compressed, legacy string) {
+	return filepath.Join(s.dir, id+compressedRunExt), filepath.Join(s.dir, id+runExt)
+}
+
+// runPath returns the path of the saved record of a run, or the path to
+// save it at
+func (s *Storage) runPath(id string) string {
+	compressed, legacy := s.runPaths(id)
+	if _, err := os.Stat(compressed); err != nil {
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return compressed
+}
+
+// readRunFile returns the JSON of a saved run, compressed or not
+func (s *Storage) readRunFile(id string) ([]byte, error) {
+	compressed, legacy := s.runPaths(id)
+	data, err := os.ReadFile(compressed)
+	if os.IsNotExist(err) {
+		return os.ReadFile(legacy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data, err = decompress(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", compressed, err)
+	}
+	return data, nil
+}
+
+// writeRunFile saves the JSON of a run compressed, replacing a record of
+// an older version
+func (s *Storage) writeRunFile(id string, data []byte) error {
+	compressed, legacy := s.runPaths(id)
+	gz, err := compress(data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(compressed, gz, 0644); err != nil {
+		return err
+	}
+	if err := os.Remove(legacy); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// writeProfileFile saves a profile gzip-compressed when that makes it
+// smaller. Profiles written by the Go runtime are compressed already;
+// pprof reads both.
+func writeProfileFile(path string, data io.Reader) error {
+	raw, err := io.ReadAll(data)
+	if err != nil {
+		return fmt.Errorf("failed to read profile data: %w", err)
+	}
+	if !isGzip(raw) {
+		gz, err := compress(raw)
+		if err != nil {
+			return fmt.Errorf("failed to compress profile: %w", err)
+		}
+		if len(gz) < len(raw) {
+			raw = gz
+		}
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write profile data: %w", err)
+	}
+	return nil
+}
+
+// CompressStats summarizes what Compress did
+type CompressStats struct {
+	Runs     int   // Run records compressed
+	Profiles int   // Profiles compressed
+	Before   int64 // Bytes of the files before
+	After    int64 // Bytes of the files after
+}
+
+// Compress compresses the run records and profiles that older versions
+// saved uncompressed. Everything saved since is compressed already.
+func (s *Storage) Compress() (*CompressStats, error) {
+	stats := &CompressStats{}
+	ids, err := s.RunIDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		compressed, legacy := s.runPaths(id)
+		if _, err := os.Stat(compressed); err == nil {
+			// The compressed record is the one read
+			os.Remove(legacy)
+			continue
+		}
+		data, err := os.ReadFile(legacy)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to read benchmark run: %w", err)
+		}
+		if err := s.writeRunFile(id, data); err != nil {
+			return stats, fmt.Errorf("failed to compress benchmark run %s: %w", id, err)
+		}
+		info, err := os.Stat(compressed)
+		if err != nil {
+			return stats, err
+		}
+		stats.Runs++
+		stats.Before += int64(len(data))
+		stats.After += info.Size()
+	}
+
+	err = filepath.WalkDir(filepath.Join(s.dir, "profiles"), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".prof" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if isGzip(data) {
+			return nil
+		}
+		if err := writeProfileFile(path, bytes.NewReader(data)); err != nil {
+			return err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.Size() == int64(len(data)) {
+			// Too small to gain from compression
+			return nil
+		}
+		stats.Profiles++
+		stats.Before += int64(len(data))
+		stats.After += info.Size()
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to compress profiles: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestRunRecordsCompressed(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir)
+	run := &models.BenchmarkRun{ID: "run-1", Timestamp: time.Now(), Results: []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}}}
+	if err := s.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "run-1.json.gz"))
+	if err != nil || !isGzip(data) {
+		t.Fatalf("Expected a gzip-compressed record, got %v", err)
+	}
+	json, err := s.RunData("run-1")
+	if err != nil || !strings.Contains(string(json), `"Parse"`) {
+		t.Errorf("Expected RunData to return the JSON, got %q, %v", json, err)
+	}
+
+	// Records of older versions are read and replaced when saved again
+	legacy := []byte(`{"id":"run-2","timestamp":"2024-05-01T12:00:00Z","results":[{"name":"Encode","ns_per_op":50}]}`)
+	if err := os.WriteFile(filepath.Join(dir, "run-2.json"), legacy, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := s.RunIDs(); len(ids) != 2 || !s.Exists("run-2") {
+		t.Fatalf("Expected the legacy record listed, got %v", ids)
+	}
+	old, err := s.Load("run-2")
+	if err != nil || old.Results[0].Name != "Encode" {
+		t.Fatalf("Expected the legacy record loaded, got %+v, %v", old, err)
+	}
+	old.Note = "resaved"
+	if err := s.SaveMetadata(old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "run-2.json")); !os.IsNotExist(err) {
+		t.Error("Expected the legacy record replaced")
+	}
+	if err := s.Delete("run-2"); err != nil || s.Exists("run-2") {
+		t.Errorf("Expected run-2 deleted, got %v", err)
+	}
+}
+
+func TestProfilesCompressed(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	raw := bytes.Repeat([]byte("sample "), 1000)
+	if err := s.SaveProfile("run-1", "cpu", bytes.NewReader(raw)); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := os.ReadFile(s.GetCPUProfilePath("run-1"))
+	if !isGzip(stored) || len(stored) >= len(raw) {
+		t.Errorf("Expected a compressed profile, got %d bytes", len(stored))
+	}
+
+	// Profiles of the Go runtime are compressed already
+	gz, _ := compress(raw)
+	if err := s.SaveAttachedProfile("run-1", "wall", bytes.NewReader(gz)); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := os.ReadFile(s.GetAttachedProfilePath("run-1", "wall")); !bytes.Equal(stored, gz) {
+		t.Error("Expected a compressed profile stored unchanged")
+	}
+}
+
+func TestCompress(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir)
+	legacy := `{"id":"run-1","timestamp":"2024-05-01T12:00:00Z","command":"` + strings.Repeat("x", 1000) + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "run-1.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(s.GetProfileDir("run-1"), 0755)
+	if err := os.WriteFile(s.GetMemoryProfilePath("run-1"), bytes.Repeat([]byte("heap "), 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	health, _ := s.Health()
+
+	stats, err := s.Compress()
+	if err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	if stats.Runs != 1 || stats.Profiles != 1 || stats.After >= stats.Before {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "run-1.json")); !os.IsNotExist(err) {
+		t.Error("Expected the legacy record removed")
+	}
+	// The JSON is unchanged, and so is its checksum
+	if after, _ := s.Health(); !after.Healthy() || after.Runs != health.Runs {
+		t.Errorf("Expected the storage healthy after compression, got %+v", after)
+	}
+
+	if stats, _ := s.Compress(); stats.Runs != 0 || stats.Profiles != 0 {
+		t.Errorf("Expected nothing left to compress, got %+v", stats)
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// daemonStatusFile holds the status of 'gokanon daemon', without the .json
+// extension so that it is never listed as a saved run
+const daemonStatusFile = "daemon.status"
+
+// SaveDaemonStatus writes the status of the daemon, replacing the file
+// atomically so readers never observe a partial write
+func (s *Storage) SaveDaemonStatus(status *models.DaemonStatus) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal daemon status: %w", err)
+	}
+
+	path := filepath.Join(s.dir, daemonStatusFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write daemon status: %w", err)
+	}
+	if err := replaceFile(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write daemon status: %w", err)
+	}
+	return nil
+}
+
+// LoadDaemonStatus returns the status of the daemon, or nil if no daemon
+// ever used the storage. The status of a daemon that stopped is kept, with
+// Running false, so that its last runs can still be shown.
+func (s *Storage) LoadDaemonStatus() (*models.DaemonStatus, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, daemonStatusFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read daemon status: %w", err)
+	}
+
+	var status models.DaemonStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse daemon status: %w", err)
+	}
+	status.Running = status.Running && processAlive(status.PID)
+	return &status, nil
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestDaemonStatus(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	status, err := s.LoadDaemonStatus()
+	if err != nil || status != nil {
+		t.Fatalf("Expected no daemon status, got %+v, %v", status, err)
+	}
+
+	want := &models.DaemonStatus{
+		PID:       os.Getpid(),
+		StartedAt: time.Now(),
+		Running:   true,
+		Schedules: []models.ScheduleStatus{{Name: "nightly", Cron: "0 2 * * *", Next: time.Now().Add(time.Hour), LastStatus: "ok"}},
+	}
+	if err := s.SaveDaemonStatus(want); err != nil {
+		t.Fatalf("SaveDaemonStatus failed: %v", err)
+	}
+	status, err = s.LoadDaemonStatus()
+	if err != nil {
+		t.Fatalf("LoadDaemonStatus failed: %v", err)
+	}
+	if status == nil || !status.Running || len(status.Schedules) != 1 || status.Schedules[0].Name != "nightly" {
+		t.Errorf("Unexpected daemon status: %+v", status)
+	}
+
+	// The status file must not show up as a saved run
+	if runs, err := s.List(); err != nil || len(runs) != 0 {
+		t.Errorf("Expected no saved runs, got %d (%v)", len(runs), err)
+	}
+
+	// The status of a daemon that is gone is kept, but not running
+	want.PID = 1 << 30
+	if err := s.SaveDaemonStatus(want); err != nil {
+		t.Fatalf("SaveDaemonStatus failed: %v", err)
+	}
+	if status, _ := s.LoadDaemonStatus(); status == nil || status.Running {
+		t.Errorf("Expected a stopped daemon, got %+v", status)
+	}
+}
+
+func TestFiringAlerts(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	firing, err := s.LoadFiringAlerts()
+	if err != nil || len(firing) != 0 {
+		t.Fatalf("Expected no firing alerts, got %v, %v", firing, err)
+	}
+
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := s.SaveFiringAlerts(map[string]map[string]time.Time{"creep": {"Checkout": since}}); err != nil {
+		t.Fatalf("SaveFiringAlerts failed: %v", err)
+	}
+	firing, err = s.LoadFiringAlerts()
+	if err != nil {
+		t.Fatalf("LoadFiringAlerts failed: %v", err)
+	}
+	if !firing["creep"]["Checkout"].Equal(since) {
+		t.Errorf("Unexpected firing alerts: %v", firing)
+	}
+	if runs, err := s.List(); err != nil || len(runs) != 0 {
+		t.Errorf("Expected no saved runs, got %d (%v)", len(runs), err)
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import "errors"
+
+// Errors that callers can tell apart with errors.Is rather than by their
+// message. The storage returns them wrapped in errors naming the run,
+// baseline or profile concerned.
+var (
+	// ErrRunNotFound is returned for a run that is not stored, whether
+	// named by its ID or by a reference such as latest~5
+	ErrRunNotFound = errors.New("benchmark run not found")
+
+	// ErrBaselineNotFound is returned for a baseline that does not exist
+	ErrBaselineNotFound = errors.New("baseline not found")
+
+	// ErrBaselineExists is returned by CreateBaseline for a name taken
+	ErrBaselineExists = errors.New("baseline already exists")
+
+	// ErrProfileMissing is returned for a profile the run was not
+	// profiled with, or whose file was pruned
+	ErrProfileMissing = errors.New("profile not found")
+)
+
+// kindError is an error that also matches one of the errors above,
+// keeping its own message and cause
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
+// withKind makes err match kind with errors.Is
+func withKind(kind, err error) error {
+	return &kindError{err: err, kind: kind}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestTypedErrors(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	if _, err := s.GetLatest(); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("GetLatest on empty storage: got %v, want ErrRunNotFound", err)
+	}
+
+	run := &models.BenchmarkRun{ID: "run-1", Timestamp: time.Now()}
+	if err := s.Save(run); err != nil {
+		t.Fatalf("Failed to save run: %v", err)
+	}
+
+	_, err := s.Load("run-missing")
+	if !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Load: got %v, want ErrRunNotFound", err)
+	}
+	// Callers checking for a missing file keep working
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load: %v no longer matches os.ErrNotExist", err)
+	}
+
+	if _, err := s.Resolve("latest~5"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Resolve(latest~5): got %v, want ErrRunNotFound", err)
+	}
+	if err := s.Delete("run-missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("Delete: got %v, want ErrRunNotFound", err)
+	}
+
+	if _, err := s.LoadProfile("run-1", "cpu"); !errors.Is(err, ErrProfileMissing) {
+		t.Errorf("LoadProfile: got %v, want ErrProfileMissing", err)
+	}
+	if _, err := s.Resolve("baseline:none"); !errors.Is(err, ErrBaselineNotFound) {
+		t.Errorf("Resolve(baseline:none): got %v, want ErrBaselineNotFound", err)
+	}
+
+	if _, err := s.CreateBaseline("v1.0", "run-1", "", nil); err != nil {
+		t.Fatalf("CreateBaseline failed: %v", err)
+	}
+	_, err = s.CreateBaseline("v1.0", "run-1", "", nil)
+	if !errors.Is(err, ErrBaselineExists) {
+		t.Errorf("CreateBaseline of a taken name: got %v, want ErrBaselineExists", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "v1.0") {
+		t.Errorf("Error %q does not name the baseline", err)
+	}
+}
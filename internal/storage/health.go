@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Health describes the state of a storage, for monitoring an instance that
+// a team or CI fleet saves its runs to
+type Health struct {
+	Runs           int       `json:"runs"`                      // Runs that can be loaded
+	FailedRuns     int       `json:"failed_runs"`               // Runs that terminated abnormally
+	UnreadableRuns []string  `json:"unreadable_runs,omitempty"` // IDs of run files that cannot be loaded
+	CorruptRuns    []string  `json:"corrupt_runs,omitempty"`    // IDs of run files whose checksum differs from the manifest
+	DiskUsage      int64     `json:"disk_usage_bytes"`          // Bytes of every file, profiles and baselines included
+	IndexFresh     bool      `json:"index_fresh"`               // The statistics cache includes every run
+	IndexUpdated   time.Time `json:"index_updated,omitzero"`    // When the statistics cache was last written
+	LastIngest     time.Time `json:"last_ingest,omitzero"`      // When a run was last saved or imported
+}
+
+// Healthy reports whether every run can be loaded and is unchanged since
+// it was saved. A stale statistics cache is not a problem: it is rebuilt
+// when next read.
+func (h *Health) Healthy() bool {
+	return len(h.UnreadableRuns) == 0 && len(h.CorruptRuns) == 0
+}
+
+// Health loads every run to report the state of the storage, verifying its
+// file against the checksum in the manifest. A storage that does not exist
+// yet is healthy and empty.
+func (s *Storage) Health() (*Health, error) {
+	manifest, err := s.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.RunIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	health := &Health{}
+	for _, id := range ids {
+		data, err := s.RunData(id)
+		if err != nil {
+			health.UnreadableRuns = append(health.UnreadableRuns, id)
+			continue
+		}
+		if entry, ok := manifest.Entry(id); ok && entry.SHA256 != checksum(data) {
+			health.CorruptRuns = append(health.CorruptRuns, id)
+		}
+		var run models.BenchmarkRun
+		if err := json.Unmarshal(data, &run); err != nil {
+			health.UnreadableRuns = append(health.UnreadableRuns, id)
+			continue
+		}
+		health.Runs++
+		if run.Failed() {
+			health.FailedRuns++
+		}
+	}
+
+	err = filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		health.DiskUsage += info.Size()
+		// Runs are written once, so their files date their ingestion
+		if _, ok := runFileID(entry.Name()); ok && filepath.Dir(path) == filepath.Clean(s.dir) && info.ModTime().After(health.LastIngest) {
+			health.LastIngest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure storage: %w", err)
+	}
+
+	health.IndexFresh = len(ids) == 0
+	if cached, err := s.loadAggregates(); err == nil {
+		health.IndexFresh = slices.Equal(cached.Runs, ids)
+		if info, err := os.Stat(s.GetAggregatesPath()); err == nil {
+			health.IndexUpdated = info.ModTime()
+		}
+	}
+	return health, nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestHealth(t *testing.T) {
+	s := NewStorage(filepath.Join(t.TempDir(), "missing"))
+	health, err := s.Health()
+	if err != nil || health.Runs != 0 || !health.IndexFresh || !health.Healthy() {
+		t.Fatalf("Expected a missing storage to be healthy and empty, got %+v, %v", health, err)
+	}
+
+	for _, run := range []*models.BenchmarkRun{
+		{ID: "run-1", Timestamp: time.Now(), Results: []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}}},
+		{ID: "run-2", Timestamp: time.Now(), Status: models.StatusFailed},
+	} {
+		if err := s.Save(run); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	if _, err := s.Aggregates(); err != nil {
+		t.Fatalf("Aggregates failed: %v", err)
+	}
+
+	health, err = s.Health()
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if health.Runs != 2 || health.FailedRuns != 1 || !health.IndexFresh || health.IndexUpdated.IsZero() || health.LastIngest.IsZero() {
+		t.Errorf("Unexpected health: %+v", health)
+	}
+	if health.DiskUsage <= 0 {
+		t.Errorf("Expected disk usage, got %d", health.DiskUsage)
+	}
+
+	// A file copied in without going through Save is unreadable and not in
+	// the statistics cache
+	if err := os.WriteFile(filepath.Join(s.dir, "run-3.json"), []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	health, err = s.Health()
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if health.Healthy() || !slices.Equal(health.UnreadableRuns, []string{"run-3"}) || health.IndexFresh {
+		t.Errorf("Expected run-3 to be unreadable and the cache stale, got %+v", health)
+	}
+
+	// A run changed since it was saved is corrupt, even if it can be loaded
+	os.Remove(filepath.Join(s.dir, "run-3.json"))
+	data, err := s.RunData("run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "100", "90", 1))
+	if err := s.writeRunFile("run-1", data); err != nil {
+		t.Fatal(err)
+	}
+	health, err = s.Health()
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if health.Healthy() || !slices.Equal(health.CorruptRuns, []string{"run-1"}) || health.Runs != 2 {
+		t.Errorf("Expected run-1 to be corrupt, got %+v", health)
+	}
+}
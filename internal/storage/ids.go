@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"crypto/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunIDPrefix starts every generated run ID
+const RunIDPrefix = "run-"
+
+// crockford is the Crockford base32 alphabet used by ULIDs. It sorts in
+// byte order, so IDs sort by creation time.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// idState makes IDs generated within the same millisecond increase
+var idState struct {
+	sync.Mutex
+	lastMillis int64
+	lastRandom [10]byte
+}
+
+// NewRunID returns a new run ID: "run-" followed by a ULID, i.e. the
+// creation time in milliseconds and 80 random bits. IDs sort by creation
+// time, including IDs created within the same millisecond by this process.
+func NewRunID(now time.Time) string {
+	idState.Lock()
+	defer idState.Unlock()
+
+	millis := now.UnixMilli()
+	var random [10]byte
+	if millis <= idState.lastMillis {
+		// Increment the previous random part instead of drawing a new one
+		millis = idState.lastMillis
+		random = idState.lastRandom
+		for i := len(random) - 1; i >= 0; i-- {
+			random[i]++
+			if random[i] != 0 {
+				break
+			}
+		}
+	} else {
+		rand.Read(random[:])
+	}
+	idState.lastMillis, idState.lastRandom = millis, random
+
+	return RunIDPrefix + encodeULID(millis, random)
+}
+
+// encodeULID encodes a 48-bit timestamp and 80 random bits as 26
+// characters of Crockford base32
+func encodeULID(millis int64, random [10]byte) string {
+	var id [26]byte
+	for i := 9; i >= 0; i-- {
+		id[i] = crockford[millis&31]
+		millis >>= 5
+	}
+
+	// 80 bits are exactly 16 characters of 5 bits
+	var acc uint64
+	bits := 0
+	pos := 10
+	for _, b := range random {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			id[pos] = crockford[(acc>>bits)&31]
+			pos++
+		}
+	}
+	return string(id[:])
+}
+
+// NewRunID returns a new run ID that no run in the storage uses yet
+func (s *Storage) NewRunID() string {
+	for {
+		id := NewRunID(time.Now())
+		if !s.Exists(id) {
+			return id
+		}
+	}
+}
+
+// NewRunIDAt returns an ID for a run created at t that no run in the
+// storage uses yet. Unlike NewRunID it may return IDs that sort before
+// existing ones, so that imported runs sort by the time they ran.
+func (s *Storage) NewRunIDAt(t time.Time) string {
+	for {
+		var random [10]byte
+		rand.Read(random[:])
+		id := RunIDPrefix + encodeULID(t.UnixMilli(), random)
+		if !s.Exists(id) {
+			return id
+		}
+	}
+}
+
+// Exists reports whether a run with the given ID is stored
+func (s *Storage) Exists(id string) bool {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return false
+	}
+	_, err := os.Stat(s.runPath(id))
+	return err == nil
+}
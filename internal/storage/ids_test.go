@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestNewRunID(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Many IDs within the same millisecond stay unique and ordered
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = NewRunID(now)
+	}
+	later := NewRunID(now.Add(time.Millisecond))
+	ids = append(ids, later)
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("Duplicate ID %s", id)
+		}
+		seen[id] = true
+		if !strings.HasPrefix(id, RunIDPrefix) || len(id) != len(RunIDPrefix)+26 {
+			t.Errorf("Expected run- followed by a 26 character ULID, got %s", id)
+		}
+		if strings.Trim(id[len(RunIDPrefix):], crockford) != "" {
+			t.Errorf("Expected only Crockford base32 characters, got %s", id)
+		}
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("Expected IDs to sort by creation order")
+	}
+
+	// An earlier clock reading never produces a smaller ID
+	if earlier := NewRunID(now.Add(-time.Hour)); earlier <= later {
+		t.Errorf("Expected %s to sort after %s", earlier, later)
+	}
+}
+
+func TestEncodeULID(t *testing.T) {
+	var random [10]byte
+	if got := encodeULID(0, random); got != strings.Repeat("0", 26) {
+		t.Errorf("Expected all zeros, got %s", got)
+	}
+	for i := range random {
+		random[i] = 0xff
+	}
+	// The largest 48-bit timestamp encodes as 7ZZZZZZZZZ
+	if got := encodeULID(1<<48-1, random); got != "7"+strings.Repeat("Z", 25) {
+		t.Errorf("Expected maximum ULID, got %s", got)
+	}
+}
+
+func TestStorageNewRunID(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir)
+
+	id := s.NewRunID()
+	if s.Exists(id) {
+		t.Fatalf("Expected new ID %s not to exist", id)
+	}
+	if err := s.Save(&models.BenchmarkRun{ID: id}); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Exists(id) {
+		t.Errorf("Expected saved run %s to exist", id)
+	}
+	if next := s.NewRunID(); next == id || next < id {
+		t.Errorf("Expected a later, different ID than %s, got %s", id, next)
+	}
+
+	// Imported runs sort by the time they ran
+	if imported := s.NewRunIDAt(time.Now().Add(-24 * time.Hour)); imported >= id || s.Exists(imported) {
+		t.Errorf("Expected an ID of a day ago before %s, got %s", id, imported)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "x.json"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if s.Exists("../" + filepath.Base(dir) + "/x") {
+		t.Error("Expected IDs with path separators never to exist")
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// liveRunFile holds the status of the run in progress. It does not use the
+// .json extension so that it is never listed as a saved run.
+const liveRunFile = "run.status"
+
+// GetLiveRunPath returns the path to the live run status file
+func (s *Storage) GetLiveRunPath() string {
+	return filepath.Join(s.dir, liveRunFile)
+}
+
+// SaveLiveRun writes the status of the run in progress. The file is
+// replaced atomically so readers never observe a partial write.
+func (s *Storage) SaveLiveRun(live *models.LiveRun) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	data, err := json.Marshal(live)
+	if err != nil {
+		return fmt.Errorf("failed to marshal live run: %w", err)
+	}
+
+	tmp := s.GetLiveRunPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write live run: %w", err)
+	}
+	if err := replaceFile(tmp, s.GetLiveRunPath()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write live run: %w", err)
+	}
+	return nil
+}
+
+// LoadLiveRun returns the status of the run in progress, or nil if no run
+// is in progress. Status files left behind by crashed runs are ignored.
+func (s *Storage) LoadLiveRun() (*models.LiveRun, error) {
+	data, err := os.ReadFile(s.GetLiveRunPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read live run: %w", err)
+	}
+
+	var live models.LiveRun
+	if err := json.Unmarshal(data, &live); err != nil {
+		return nil, fmt.Errorf("failed to parse live run: %w", err)
+	}
+
+	if !processAlive(live.PID) {
+		return nil, nil
+	}
+	return &live, nil
+}
+
+// ClearLiveRun removes the live run status once the run has finished
+func (s *Storage) ClearLiveRun() error {
+	if err := os.Remove(s.GetLiveRunPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove live run: %w", err)
+	}
+	return nil
+}
+
+// replaceFile renames src over dst. On Windows the rename fails while a
+// reader, such as the dashboard polling the live run, has dst open, so it
+// is retried briefly.
+func replaceFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	for attempt := 1; err != nil && runtime.GOOS == "windows" && attempt <= 10; attempt++ {
+		time.Sleep(time.Duration(attempt) * 10 * time.Millisecond)
+		err = os.Rename(src, dst)
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestLiveRun(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	live, err := s.LoadLiveRun()
+	if err != nil || live != nil {
+		t.Fatalf("Expected no live run, got %+v, %v", live, err)
+	}
+
+	want := &models.LiveRun{
+		PID:       os.Getpid(),
+		Package:   "./examples",
+		StartedAt: time.Now(),
+		Current:   "Fibonacci",
+		Completed: []models.BenchmarkResult{{Name: "Sum-8", NsPerOp: 12}},
+		Expected:  3,
+	}
+	if err := s.SaveLiveRun(want); err != nil {
+		t.Fatalf("SaveLiveRun failed: %v", err)
+	}
+
+	live, err = s.LoadLiveRun()
+	if err != nil {
+		t.Fatalf("LoadLiveRun failed: %v", err)
+	}
+	if live == nil || live.Current != "Fibonacci" || len(live.Completed) != 1 || live.Expected != 3 {
+		t.Errorf("Unexpected live run: %+v", live)
+	}
+
+	// The status file must not show up as a saved run
+	runs, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("Expected no saved runs, got %d", len(runs))
+	}
+
+	if err := s.ClearLiveRun(); err != nil {
+		t.Fatalf("ClearLiveRun failed: %v", err)
+	}
+	if live, _ := s.LoadLiveRun(); live != nil {
+		t.Errorf("Expected no live run after clearing, got %+v", live)
+	}
+}
+
+func TestLiveRunStale(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	// Status left behind by a crashed process
+	if err := s.SaveLiveRun(&models.LiveRun{PID: 1 << 30, StartedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveLiveRun failed: %v", err)
+	}
+
+	live, err := s.LoadLiveRun()
+	if err != nil {
+		t.Fatalf("LoadLiveRun failed: %v", err)
+	}
+	if live != nil {
+		t.Errorf("Expected stale live run to be ignored, got %+v", live)
+	}
+}
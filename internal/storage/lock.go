@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	runLockFile = "run.lock"
+
+	// lockPollInterval is how often a waiting process retries the run lock
+	lockPollInterval = 500 * time.Millisecond
+
+	// lockReadInterval is how soon the holder is read again when it has not
+	// written its information yet
+	lockReadInterval = 10 * time.Millisecond
+)
+
+// LockInfo identifies the process holding the run lock
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// LockedError is returned when another process holds the run lock
+type LockedError struct {
+	Dir    string
+	Holder LockInfo
+}
+
+// Error implements the error interface
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("storage %s is locked by another gokanon run (PID %d, started %s)",
+		e.Dir, e.Holder.PID, e.Holder.StartedAt.Format(time.RFC3339))
+}
+
+// RunLock is an exclusive lock on a storage directory held for the duration
+// of a benchmark run. It is an advisory lock on the lock file, which the
+// operating system releases when the holder exits, even on a crash.
+type RunLock struct {
+	file *os.File
+}
+
+// GetRunLockPath returns the path to the run lock file
+func (s *Storage) GetRunLockPath() string {
+	return filepath.Join(s.dir, runLockFile)
+}
+
+// AcquireRunLock takes the run lock for this storage directory. If another
+// live process holds it, a *LockedError is returned immediately unless wait
+// is set, in which case the call blocks until the lock is released.
+func (s *Storage) AcquireRunLock(wait bool) (*RunLock, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	info := LockInfo{PID: os.Getpid(), StartedAt: time.Now()}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lock info: %w", err)
+	}
+
+	for {
+		// The file is kept once created: removing it would let a process
+		// lock the removed file while another creates a new one
+		f, err := os.OpenFile(s.GetRunLockPath(), os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create run lock: %w", err)
+		}
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to take run lock: %w", err)
+		}
+		if locked {
+			lock := &RunLock{file: f}
+			if err := lock.write(data); err != nil {
+				lock.Release()
+				return nil, fmt.Errorf("failed to write run lock: %w", err)
+			}
+			return lock, nil
+		}
+		f.Close()
+
+		holder, err := s.ReadRunLock()
+		if err != nil {
+			// The holder has not written its information yet, or released
+			// the lock since
+			if os.IsNotExist(err) || errors.Is(err, errPartialLock) {
+				time.Sleep(lockReadInterval)
+				continue
+			}
+			return nil, err
+		}
+		if !wait {
+			return nil, &LockedError{Dir: s.dir, Holder: *holder}
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// errPartialLock is returned by ReadRunLock for a lock file that is being
+// written
+var errPartialLock = errors.New("run lock is being written")
+
+// ReadRunLock returns information about the current run lock holder. The
+// lock file of a released lock is empty, and reads as not existing.
+func (s *Storage) ReadRunLock() (*LockInfo, error) {
+	data, err := os.ReadFile(s.GetRunLockPath())
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, &os.PathError{Op: "read", Path: s.GetRunLockPath(), Err: os.ErrNotExist}
+	}
+
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse run lock: %w", errors.Join(errPartialLock, err))
+	}
+
+	return &info, nil
+}
+
+// write records the holder in the lock file
+func (l *RunLock) write(data []byte) error {
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := l.file.WriteAt(data, 0)
+	return err
+}
+
+// Release empties the lock file and releases the run lock
+func (l *RunLock) Release() error {
+	terr := l.file.Truncate(0)
+	uerr := unlockFile(l.file)
+	cerr := l.file.Close()
+	if err := errors.Join(terr, uerr, cerr); err != nil {
+		return fmt.Errorf("failed to release run lock: %w", err)
+	}
+	return nil
+}
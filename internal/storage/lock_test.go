@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireRunLock(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	lock, err := s.AcquireRunLock(false)
+	if err != nil {
+		t.Fatalf("AcquireRunLock failed: %v", err)
+	}
+
+	info, err := s.ReadRunLock()
+	if err != nil {
+		t.Fatalf("ReadRunLock failed: %v", err)
+	}
+	if info.PID != os.Getpid() {
+		t.Errorf("Expected lock PID %d, got %d", os.Getpid(), info.PID)
+	}
+	if info.StartedAt.IsZero() {
+		t.Error("Expected non-zero lock start time")
+	}
+
+	// A second run must fail fast and identify the holder
+	_, err = s.AcquireRunLock(false)
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Expected LockedError, got %v", err)
+	}
+	if locked.Holder.PID != os.Getpid() {
+		t.Errorf("Expected holder PID %d, got %d", os.Getpid(), locked.Holder.PID)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+
+	lock, err = s.AcquireRunLock(false)
+	if err != nil {
+		t.Fatalf("AcquireRunLock after release failed: %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquireRunLockStale(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	// Simulate a lock left behind by a crashed process
+	data, _ := json.Marshal(LockInfo{PID: 1 << 30, StartedAt: time.Now().Add(-time.Hour)})
+	if err := os.WriteFile(s.GetRunLockPath(), data, 0644); err != nil {
+		t.Fatalf("Failed to write stale lock: %v", err)
+	}
+
+	lock, err := s.AcquireRunLock(false)
+	if err != nil {
+		t.Fatalf("Expected stale lock to be reclaimed, got %v", err)
+	}
+	defer lock.Release()
+
+	info, _ := s.ReadRunLock()
+	if info == nil || info.PID != os.Getpid() {
+		t.Errorf("Expected lock to be owned by current process, got %+v", info)
+	}
+}
+
+func TestAcquireRunLockConcurrent(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	// A lock left behind by a crashed process is free for all of them
+	data, _ := json.Marshal(LockInfo{PID: 1 << 30, StartedAt: time.Now().Add(-time.Hour)})
+	if err := os.WriteFile(s.GetRunLockPath(), data, 0644); err != nil {
+		t.Fatalf("Failed to write stale lock: %v", err)
+	}
+
+	var holders, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := s.AcquireRunLock(true)
+			if err != nil {
+				t.Errorf("AcquireRunLock failed: %v", err)
+				return
+			}
+			if holders.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			time.Sleep(5 * time.Millisecond)
+			holders.Add(-1)
+			if err := lock.Release(); err != nil {
+				t.Errorf("Release failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := overlaps.Load(); n > 0 {
+		t.Errorf("Run lock held by several runs at once %d times", n)
+	}
+}
+
+func TestReadRunLockPartial(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	// A released lock reads as no holder
+	lock, err := s.AcquireRunLock(false)
+	if err != nil {
+		t.Fatalf("AcquireRunLock failed: %v", err)
+	}
+	lock.Release()
+	if _, err := s.ReadRunLock(); !os.IsNotExist(err) {
+		t.Errorf("Expected no holder after release, got %v", err)
+	}
+
+	// A holder still writing its information is not a hard error
+	if err := os.WriteFile(s.GetRunLockPath(), []byte(`{"pid":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ReadRunLock(); !errors.Is(err, errPartialLock) {
+		t.Errorf("Expected a partial lock, got %v", err)
+	}
+}
+
+func TestAcquireRunLockWait(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	lock, err := s.AcquireRunLock(false)
+	if err != nil {
+		t.Fatalf("AcquireRunLock failed: %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		lock.Release()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		l, err := s.AcquireRunLock(true)
+		if err == nil {
+			l.Release()
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Waiting AcquireRunLock failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for run lock")
+	}
+}
+
+func TestRunLockNotListedAsRun(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	lock, err := s.AcquireRunLock(false)
+	if err != nil {
+		t.Fatalf("AcquireRunLock failed: %v", err)
+	}
+	defer lock.Release()
+
+	runs, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("Expected lock file to be ignored by List, got %d runs", len(runs))
+	}
+}
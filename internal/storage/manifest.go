@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// manifestFile lists every run with the checksum of its file. Like the
+// caches it does not use the .json extension, so that it is never listed
+// as a run. Unlike them it is not rebuilt from the runs: the checksum
+// recorded when a run was saved is what its file is verified against.
+const manifestFile = "manifest.index"
+
+// Manifest is the index of the saved runs, so that runs are listed without
+// loading them and corrupted run files are detected
+type Manifest struct {
+	Runs []ManifestEntry `json:"runs"` // Sorted by ID
+}
+
+// ManifestEntry describes a saved run
+type ManifestEntry struct {
+	ID         string    `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Package    string    `json:"package,omitempty"`
+	Benchmarks []string  `json:"benchmarks,omitempty"`
+	Failed     bool      `json:"failed,omitempty"`
+	SHA256     string    `json:"sha256"` // Of the run file, hex-encoded
+	Size       int64     `json:"size"`
+}
+
+// newManifestEntry describes a run saved as data. A file that cannot be
+// parsed is recorded with its checksum alone.
+func newManifestEntry(id string, data []byte) ManifestEntry {
+	entry := ManifestEntry{ID: id, SHA256: checksum(data), Size: int64(len(data))}
+	var run models.BenchmarkRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return entry
+	}
+	entry.Timestamp = run.Timestamp
+	entry.Package = run.Package
+	entry.Failed = run.Failed()
+	for _, result := range run.Results {
+		entry.Benchmarks = append(entry.Benchmarks, result.Name)
+	}
+	return entry
+}
+
+// checksum returns the hex-encoded SHA-256 of data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// find returns the index of the entry of a run
+func (m *Manifest) find(id string) (int, bool) {
+	return slices.BinarySearchFunc(m.Runs, id, func(e ManifestEntry, id string) int {
+		return strings.Compare(e.ID, id)
+	})
+}
+
+// Entry returns the entry of a run
+func (m *Manifest) Entry(id string) (ManifestEntry, bool) {
+	i, found := m.find(id)
+	if !found {
+		return ManifestEntry{}, false
+	}
+	return m.Runs[i], true
+}
+
+// put adds or replaces the entry of a run
+func (m *Manifest) put(entry ManifestEntry) {
+	if i, found := m.find(entry.ID); found {
+		m.Runs[i] = entry
+	} else {
+		m.Runs = slices.Insert(m.Runs, i, entry)
+	}
+}
+
+// remove drops the entry of a run
+func (m *Manifest) remove(id string) bool {
+	i, found := m.find(id)
+	if found {
+		m.Runs = slices.Delete(m.Runs, i, i+1)
+	}
+	return found
+}
+
+// GetManifestPath returns the path to the manifest
+func (s *Storage) GetManifestPath() string {
+	return filepath.Join(s.dir, manifestFile)
+}
+
+// Manifest returns the manifest of the saved runs. Runs saved without
+// being recorded, e.g. by an older version or copied into the directory,
+// are recorded with the checksum their file has now; runs removed without
+// Delete are dropped.
+func (s *Storage) Manifest() (*Manifest, error) {
+	ids, err := s.RunIDs()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := s.loadManifest()
+	if err != nil {
+		// Without a manifest, or with an unreadable one, every run is
+		// recorded as it is now
+		manifest = &Manifest{}
+	}
+
+	recorded := len(manifest.Runs)
+	manifest.Runs = slices.DeleteFunc(manifest.Runs, func(e ManifestEntry) bool {
+		_, found := slices.BinarySearch(ids, e.ID)
+		return !found
+	})
+	changed := err != nil || len(manifest.Runs) != recorded
+	for _, id := range ids {
+		if _, found := manifest.find(id); found {
+			continue
+		}
+		data, err := s.RunData(id)
+		if err != nil {
+			continue
+		}
+		manifest.put(newManifestEntry(id, data))
+		changed = true
+	}
+	if changed && len(ids) > 0 {
+		// In read-only storage the manifest is rebuilt every time instead,
+		// and cannot detect corruption
+		s.saveManifest(manifest)
+	}
+	return manifest, nil
+}
+
+// Index returns the manifest entries of the saved runs, newest first
+func (s *Storage) Index() ([]ManifestEntry, error) {
+	manifest, err := s.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	entries := slices.Clone(manifest.Runs)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return entries, nil
+}
+
+// updateManifest records a run saved as data
+func (s *Storage) updateManifest(id string, data []byte) error {
+	manifest, err := s.loadManifest()
+	if os.IsNotExist(err) {
+		// Recording every run records this one too
+		_, err := s.Manifest()
+		return err
+	}
+	if err != nil {
+		manifest = &Manifest{}
+	}
+	manifest.put(newManifestEntry(id, data))
+	return s.saveManifest(manifest)
+}
+
+// removeFromManifest drops a deleted run from the manifest
+func (s *Storage) removeFromManifest(id string) error {
+	manifest, err := s.loadManifest()
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !manifest.remove(id) {
+		return nil
+	}
+	return s.saveManifest(manifest)
+}
+
+// loadManifest reads the manifest
+func (s *Storage) loadManifest() (*Manifest, error) {
+	data, err := os.ReadFile(s.GetManifestPath())
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// saveManifest replaces the manifest atomically
+func (s *Storage) saveManifest(manifest *Manifest) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	tmp := s.GetManifestPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := replaceFile(tmp, s.GetManifestPath()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestManifest(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	now := time.Now()
+	for _, run := range []*models.BenchmarkRun{
+		{ID: "run-1", Timestamp: now.Add(-time.Hour), Package: "example.com/a", Results: []models.BenchmarkResult{{Name: "Parse"}, {Name: "Encode"}}},
+		{ID: "run-2", Timestamp: now, Status: models.StatusFailed},
+		// Imported later, but ran first
+		{ID: "run-3", Timestamp: now.Add(-2 * time.Hour)},
+	} {
+		if err := s.Save(run); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	entries, err := s.Index()
+	if err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	if !slices.Equal(ids, []string{"run-2", "run-1", "run-3"}) {
+		t.Fatalf("Expected entries newest first, got %v", ids)
+	}
+	first := entries[1]
+	data, _ := s.RunData("run-1")
+	if first.Package != "example.com/a" || !slices.Equal(first.Benchmarks, []string{"Parse", "Encode"}) ||
+		first.SHA256 != checksum(data) || first.Size != int64(len(data)) || !entries[0].Failed {
+		t.Errorf("Unexpected entry %+v", first)
+	}
+
+	latest, err := s.GetLatest()
+	if err != nil || latest.ID != "run-2" {
+		t.Errorf("Expected run-2 as the latest run, got %+v, %v", latest, err)
+	}
+
+	// Rewriting a run records its new checksum
+	run, _ := s.Load("run-1")
+	run.Note = "noisy"
+	if err := s.SaveMetadata(run); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := s.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = s.RunData("run-1")
+	if entry, _ := manifest.Entry("run-1"); entry.SHA256 != checksum(data) {
+		t.Error("Expected the checksum of the rewritten run")
+	}
+
+	if err := s.Delete("run-2"); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ = s.loadManifest()
+	if _, ok := manifest.Entry("run-2"); ok || len(manifest.Runs) != 2 {
+		t.Errorf("Expected run-2 dropped from the manifest, got %+v", manifest.Runs)
+	}
+}
+
+func TestManifestRecordsUnknownRuns(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir)
+	// Runs saved before the manifest existed, or copied in
+	if err := os.WriteFile(filepath.Join(dir, "run-1.json"), []byte(`{"id":"run-1","timestamp":"2024-05-01T12:00:00Z"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "run-2.json"), []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.Index()
+	if err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "run-1" || entries[1].SHA256 != checksum([]byte("not json")) {
+		t.Errorf("Expected both runs recorded, got %+v", entries)
+	}
+	if _, err := os.Stat(s.GetManifestPath()); err != nil {
+		t.Errorf("Expected the manifest written: %v", err)
+	}
+
+	// Runs removed behind the storage's back are dropped
+	os.Remove(filepath.Join(dir, "run-2.json"))
+	if entries, _ := s.Index(); len(entries) != 1 {
+		t.Errorf("Expected run-2 dropped, got %+v", entries)
+	}
+
+	// An unreadable manifest is rebuilt
+	os.WriteFile(s.GetManifestPath(), []byte("{"), 0644)
+	if entries, err := s.Index(); err != nil || len(entries) != 1 {
+		t.Errorf("Expected the manifest rebuilt, got %+v, %v", entries, err)
+	}
+}
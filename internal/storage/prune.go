@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// ProfileSize returns the bytes of profile files stored for a run, 0 when
+// it has none
+func (s *Storage) ProfileSize(runID string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(s.GetProfileDir(runID), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure profiles: %w", err)
+	}
+	return size, nil
+}
+
+// PruneProfiles deletes the profile files of a run while keeping its
+// results and profile summary. The run is rewritten to record which
+// profiles were removed and when, and no longer points at their files.
+// Results are unchanged, so the statistics cache stays valid.
+func (s *Storage) PruneProfiles(run *models.BenchmarkRun, now time.Time) error {
+	if err := os.RemoveAll(s.GetProfileDir(run.ID)); err != nil {
+		return fmt.Errorf("failed to delete profile directory: %w", err)
+	}
+
+	for _, profile := range []struct {
+		name string
+		path *string
+	}{
+		{"cpu", &run.CPUProfile},
+		{"mem", &run.MemoryProfile},
+		{"warmup", &run.WarmupProfile},
+		{"block", &run.BlockProfile},
+		{"mutex", &run.MutexProfile},
+	} {
+		if *profile.path != "" {
+			run.PrunedProfiles = append(run.PrunedProfiles, profile.name)
+			*profile.path = ""
+		}
+	}
+	for _, attached := range run.AttachedProfiles {
+		run.PrunedProfiles = append(run.PrunedProfiles, attached.Name)
+	}
+	run.AttachedProfiles = nil
+	run.ProfilesPrunedAt = now
+
+	return s.writeRun(run)
+}
+
+// RetentionPolicy selects what Prune deletes. Runs are deleted once beyond
+// the KeepLast newest, once older than OlderThan, or, with both set, once
+// beyond the newest and old. Runs that are kept lose their profiles once
+// older than ProfilesOlderThan. Zero values disable a criterion.
+type RetentionPolicy struct {
+	KeepLast          int
+	OlderThan         time.Duration
+	ProfilesOlderThan time.Duration
+	KeepBaselines     bool // Never touch the runs baselines point at
+}
+
+// PrunedRun is a run whose files Prune deleted
+type PrunedRun struct {
+	ID           string
+	Timestamp    time.Time
+	Bytes        int64 // Bytes freed
+	ProfilesOnly bool  // Only the profiles were deleted
+}
+
+// Prune applies a retention policy at time now, returning the runs it
+// pruned, newest first. With dryRun nothing is deleted and the runs that
+// would be pruned are returned. Callers should hold the run lock so that
+// no run is saved meanwhile.
+func (s *Storage) Prune(policy RetentionPolicy, now time.Time, dryRun bool) ([]PrunedRun, error) {
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool)
+	if policy.KeepBaselines {
+		baselines, err := s.ListBaselines()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list baselines: %w", err)
+		}
+		for _, baseline := range baselines {
+			keep[baseline.RunID] = true
+		}
+	}
+
+	var pruned []PrunedRun
+	for i := range runs {
+		run := &runs[i]
+		if keep[run.ID] {
+			continue
+		}
+		profiles, err := s.ProfileSize(run.ID)
+		if err != nil {
+			return pruned, err
+		}
+
+		if policy.expired(i, run.Timestamp, now) {
+			info, err := os.Stat(s.runPath(run.ID))
+			if err != nil {
+				return pruned, fmt.Errorf("failed to read benchmark run: %w", err)
+			}
+			if !dryRun {
+				if err := s.Delete(run.ID); err != nil {
+					return pruned, err
+				}
+			}
+			pruned = append(pruned, PrunedRun{ID: run.ID, Timestamp: run.Timestamp, Bytes: info.Size() + profiles})
+			continue
+		}
+
+		if policy.ProfilesOlderThan > 0 && profiles > 0 && run.Timestamp.Before(now.Add(-policy.ProfilesOlderThan)) {
+			if !dryRun {
+				if err := s.PruneProfiles(run, now); err != nil {
+					return pruned, fmt.Errorf("failed to prune profiles of %s: %w", run.ID, err)
+				}
+			}
+			pruned = append(pruned, PrunedRun{ID: run.ID, Timestamp: run.Timestamp, Bytes: profiles, ProfilesOnly: true})
+		}
+	}
+	return pruned, nil
+}
+
+// expired reports whether the run at index i of the runs, newest first,
+// is deleted
+func (p RetentionPolicy) expired(i int, timestamp, now time.Time) bool {
+	old := p.OlderThan > 0 && timestamp.Before(now.Add(-p.OlderThan))
+	switch {
+	case p.KeepLast > 0 && p.OlderThan > 0:
+		return i >= p.KeepLast && old
+	case p.KeepLast > 0:
+		return i >= p.KeepLast
+	default:
+		return old
+	}
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestPruneProfiles(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	run := &models.BenchmarkRun{
+		ID:             "run-1",
+		Timestamp:      time.Now(),
+		Results:        []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}},
+		ProfileSummary: &models.ProfileSummary{TotalCPUSamples: 42},
+	}
+	if err := s.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if size, err := s.ProfileSize(run.ID); err != nil || size != 0 {
+		t.Fatalf("Expected no profiles yet, got %d, %v", size, err)
+	}
+
+	if err := s.SaveProfile(run.ID, "cpu", strings.NewReader("cpu profile")); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	if err := s.SaveAttachedProfile(run.ID, "fgprof", strings.NewReader("wall")); err != nil {
+		t.Fatalf("SaveAttachedProfile failed: %v", err)
+	}
+	run.CPUProfile = s.GetCPUProfilePath(run.ID)
+	run.AttachedProfiles = []models.AttachedProfile{{Name: "fgprof", Path: s.GetAttachedProfilePath(run.ID, "fgprof")}}
+	if err := s.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if size, err := s.ProfileSize(run.ID); err != nil || size != int64(len("cpu profile")+len("wall")) {
+		t.Fatalf("Unexpected profile size %d, %v", size, err)
+	}
+	aggregates, err := s.Aggregates()
+	if err != nil {
+		t.Fatalf("Aggregates failed: %v", err)
+	}
+
+	now := time.Now()
+	if err := s.PruneProfiles(run, now); err != nil {
+		t.Fatalf("PruneProfiles failed: %v", err)
+	}
+	if _, err := os.Stat(s.GetProfileDir(run.ID)); !os.IsNotExist(err) {
+		t.Errorf("Expected the profile directory to be deleted, got %v", err)
+	}
+
+	loaded, err := s.Load(run.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.CPUProfile != "" || loaded.AttachedProfiles != nil || !slices.Equal(loaded.PrunedProfiles, []string{"cpu", "fgprof"}) {
+		t.Errorf("Expected the run to record its pruned profiles, got %+v", loaded)
+	}
+	if !loaded.ProfilesPrunedAt.Equal(now) || loaded.ProfileSummary == nil || len(loaded.Results) != 1 {
+		t.Errorf("Expected results and summary to be kept, got %+v", loaded)
+	}
+
+	// The results did not change, so neither did the statistics cache
+	cached, err := s.loadAggregates()
+	if err != nil || !slices.Equal(cached.Runs, aggregates.Runs) {
+		t.Errorf("Expected the statistics cache to be kept, got %+v, %v", cached, err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	setup := func(t *testing.T) *Storage {
+		t.Helper()
+		s := NewStorage(t.TempDir())
+		// run-0 is the newest, run-4 the oldest, one run every 30 days
+		for i := 0; i < 5; i++ {
+			id := fmt.Sprintf("run-%d", i)
+			run := &models.BenchmarkRun{
+				ID:         id,
+				Timestamp:  now.Add(-time.Duration(i) * 30 * 24 * time.Hour),
+				Results:    []models.BenchmarkResult{{Name: "Parse", NsPerOp: 100}},
+				CPUProfile: s.GetCPUProfilePath(id),
+			}
+			if err := s.Save(run); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			if err := s.SaveProfile(id, "cpu", strings.NewReader("profile")); err != nil {
+				t.Fatalf("SaveProfile failed: %v", err)
+			}
+		}
+		if _, err := s.SaveBaseline("main", "run-4", "", nil); err != nil {
+			t.Fatalf("SaveBaseline failed: %v", err)
+		}
+		return s
+	}
+	days := func(n int) time.Duration { return time.Duration(n) * 24 * time.Hour }
+
+	tests := []struct {
+		name     string
+		policy   RetentionPolicy
+		deleted  []string
+		profiles []string // Runs that only lost their profiles
+	}{
+		{"keep last", RetentionPolicy{KeepLast: 2, KeepBaselines: true}, []string{"run-2", "run-3"}, nil},
+		{"older than", RetentionPolicy{OlderThan: days(45), KeepBaselines: true}, []string{"run-2", "run-3"}, nil},
+		{"keep last and older than", RetentionPolicy{KeepLast: 3, OlderThan: days(15), KeepBaselines: true}, []string{"run-3"}, nil},
+		{"baselines not kept", RetentionPolicy{OlderThan: days(100)}, []string{"run-4"}, nil},
+		{"profiles", RetentionPolicy{KeepLast: 3, ProfilesOlderThan: days(15), KeepBaselines: true}, []string{"run-3"}, []string{"run-1", "run-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := setup(t)
+
+			dry, err := s.Prune(tt.policy, now, true)
+			if err != nil {
+				t.Fatalf("Prune -dry-run failed: %v", err)
+			}
+			if ids, _ := s.RunIDs(); len(ids) != 5 {
+				t.Fatalf("Expected a dry run to delete nothing, %d runs left", len(ids))
+			}
+
+			pruned, err := s.Prune(tt.policy, now, false)
+			if err != nil {
+				t.Fatalf("Prune failed: %v", err)
+			}
+			if !slices.Equal(pruned, dry) {
+				t.Errorf("Dry run %+v differs from %+v", dry, pruned)
+			}
+			var deleted, profiles []string
+			for _, run := range pruned {
+				if run.Bytes <= 0 {
+					t.Errorf("%s: expected bytes freed", run.ID)
+				}
+				if run.ProfilesOnly {
+					profiles = append(profiles, run.ID)
+				} else {
+					deleted = append(deleted, run.ID)
+				}
+			}
+			if !slices.Equal(deleted, tt.deleted) || !slices.Equal(profiles, tt.profiles) {
+				t.Errorf("Pruned runs %v and profiles %v, want %v and %v", deleted, profiles, tt.deleted, tt.profiles)
+			}
+			for _, id := range deleted {
+				if s.Exists(id) || s.HasProfile(id, "cpu") {
+					t.Errorf("Expected %s and its profiles to be deleted", id)
+				}
+			}
+			for _, id := range profiles {
+				if !s.Exists(id) || s.HasProfile(id, "cpu") {
+					t.Errorf("Expected only the profiles of %s to be deleted", id)
+				}
+			}
+		})
+	}
+}
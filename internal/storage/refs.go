@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// Prefixes of symbolic run references
+const (
+	baselineRefPrefix = "baseline:"
+	commitRefPrefix   = "commit:"
+)
+
+// Resolve loads the run that ref refers to. Besides run IDs it accepts
+// symbolic references, so scripts need not parse list output:
+//
+//	latest          the most recent run
+//	latest~N        the Nth run before the most recent one
+//	previous        the run before the most recent one (latest~1)
+//	baseline:NAME   the run saved as baseline NAME
+//	commit:REV      the most recent run of the commit starting with REV
+func (s *Storage) Resolve(ref string) (*models.BenchmarkRun, error) {
+	switch {
+	case ref == "latest", ref == "previous", strings.HasPrefix(ref, "latest~"):
+		n := 1
+		if ref != "previous" {
+			var err error
+			if n, err = latestOffset(ref); err != nil {
+				return nil, err
+			}
+		}
+		runs, err := s.Latest(n+1, func(*models.BenchmarkRun) bool { return true })
+		if err != nil {
+			return nil, err
+		}
+		if n >= len(runs) {
+			return nil, withKind(ErrRunNotFound, fmt.Errorf("%s does not exist: only %d runs are stored", ref, len(runs)))
+		}
+		return &runs[n], nil
+
+	case strings.HasPrefix(ref, baselineRefPrefix):
+		baseline, err := s.LoadBaseline(strings.TrimPrefix(ref, baselineRefPrefix))
+		if err != nil {
+			return nil, err
+		}
+		if baseline.Run == nil {
+			return nil, withKind(ErrRunNotFound, fmt.Errorf("baseline %s has no run", baseline.Name))
+		}
+		return baseline.Run, nil
+
+	case strings.HasPrefix(ref, commitRefPrefix):
+		return s.resolveCommit(strings.TrimPrefix(ref, commitRefPrefix))
+	}
+
+	return s.Load(ref)
+}
+
+// latestOffset parses the offset N of "latest" or "latest~N"
+func latestOffset(ref string) (int, error) {
+	if ref == "latest" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(ref, "latest~"))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid run reference %q: expected latest~N with N >= 0", ref)
+	}
+	return n, nil
+}
+
+// resolveCommit returns the most recent run of the commit starting with rev
+func (s *Storage) resolveCommit(rev string) (*models.BenchmarkRun, error) {
+	if rev == "" {
+		return nil, fmt.Errorf("invalid run reference: commit: needs a revision")
+	}
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *models.BenchmarkRun
+	for i, run := range runs {
+		if run.Commit == "" || !strings.HasPrefix(run.Commit, rev) {
+			continue
+		}
+		if found == nil {
+			found = &runs[i] // Runs are sorted newest first
+		} else if run.Commit != found.Commit {
+			return nil, fmt.Errorf("commit %s is ambiguous: matches %s and %s", rev, found.Commit, run.Commit)
+		}
+	}
+	if found == nil {
+		return nil, withKind(ErrRunNotFound, fmt.Errorf("no run found for commit %s", rev))
+	}
+	return found, nil
+}
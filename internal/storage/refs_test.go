@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestResolve(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	now := time.Now()
+	runs := []struct {
+		id     string
+		commit string
+	}{
+		{"run-3", "abc1234def"}, // Newest
+		{"run-2", "abc1234def"},
+		{"run-1", "abd9999000"},
+	}
+	for i, r := range runs {
+		run := &models.BenchmarkRun{ID: r.id, Timestamp: now.Add(-time.Duration(i) * time.Hour), Commit: r.commit}
+		if err := s.Save(run); err != nil {
+			t.Fatalf("Failed to save run: %v", err)
+		}
+	}
+	if _, err := s.SaveBaseline("v1.0", "run-1", "", nil); err != nil {
+		t.Fatalf("Failed to save baseline: %v", err)
+	}
+
+	tests := []struct {
+		ref     string
+		wantID  string
+		wantErr string
+	}{
+		{"run-2", "run-2", ""},
+		{"latest", "run-3", ""},
+		{"latest~0", "run-3", ""},
+		{"latest~2", "run-1", ""},
+		{"previous", "run-2", ""},
+		{"baseline:v1.0", "run-1", ""},
+		{"commit:abc1", "run-3", ""}, // Most recent run of the commit
+		{"commit:abd", "run-1", ""},
+		{"latest~3", "", "only 3 runs"},
+		{"latest~x", "", "invalid run reference"},
+		{"commit:ab", "", "ambiguous"},
+		{"commit:fff", "", "no run found"},
+		{"commit:", "", "needs a revision"},
+		{"baseline:missing", "", "baseline"},
+		{"run-404", "", "failed to read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			run, err := s.Resolve(tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			if run.ID != tt.wantID {
+				t.Errorf("Resolve(%s) = %s, want %s", tt.ref, run.ID, tt.wantID)
+			}
+		})
+	}
+}
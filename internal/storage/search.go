@@ -0,0 +1,424 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+// searchIndexFile caches the search index. Like the statistics cache it
+// does not use the .json extension, so that it is never listed as a run.
+const searchIndexFile = "search.index"
+
+// SearchFields are the qualifiers a search term can be limited to, as in
+// "package:payments"
+var SearchFields = []string{"id", "bench", "package", "tag", "note", "commit"}
+
+// searchFieldAliases are the other names accepted for search fields
+var searchFieldAliases = map[string]string{
+	"benchmark": "bench",
+	"name":      "bench",
+	"pkg":       "package",
+	"tags":      "tag",
+}
+
+// SearchIndex is an inverted index of the metadata of every run, so that
+// runs are found without loading them
+type SearchIndex struct {
+	Runs    []string                `json:"runs"`    // Sorted IDs of the runs included
+	Entries map[string]*SearchEntry `json:"entries"` // Metadata of each run by ID
+	Terms   map[string][]string     `json:"terms"`   // Sorted run IDs by "field:token"
+}
+
+// SearchEntry is the metadata of a run kept in the search index
+type SearchEntry struct {
+	ID            string            `json:"id"`
+	Timestamp     time.Time         `json:"timestamp"`
+	Package       string            `json:"package"`
+	Commit        string            `json:"commit,omitempty"`
+	CommitMessage string            `json:"commit_message,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Note          string            `json:"note,omitempty"`
+	Failed        bool              `json:"failed,omitempty"`
+	Benchmarks    []SearchBenchmark `json:"benchmarks"`
+}
+
+// SearchBenchmark is a benchmark result kept in the search index
+type SearchBenchmark struct {
+	Name    string  `json:"name"`
+	NsPerOp float64 `json:"ns_per_op"`
+}
+
+// SearchTerm is a term of a search query, limited to a field or matching
+// any when Field is empty
+type SearchTerm struct {
+	Field string
+	Text  string
+}
+
+// SearchHit is a run matching a query, with the benchmarks whose names
+// match its unqualified and bench terms
+type SearchHit struct {
+	*SearchEntry
+	Matches []SearchBenchmark `json:"matches,omitempty"`
+}
+
+// ParseSearchQuery splits a query into terms separated by spaces. A term
+// prefixed with a field, as in "package:payments" or "tag:env=ci", only
+// matches that field; quotes keep spaces within a term, as in
+// note:"new disk".
+func ParseSearchQuery(query string) ([]SearchTerm, error) {
+	var terms []SearchTerm
+	for _, word := range splitQuery(query) {
+		term := SearchTerm{Text: word}
+		if field, text, ok := strings.Cut(word, ":"); ok {
+			field = strings.ToLower(field)
+			if alias, ok := searchFieldAliases[field]; ok {
+				field = alias
+			}
+			if !slices.Contains(SearchFields, field) {
+				return nil, fmt.Errorf("unknown search field %q (expected one of %s)", field, strings.Join(SearchFields, ", "))
+			}
+			term = SearchTerm{Field: field, Text: strings.Trim(text, `"`)}
+		}
+		if len(searchTokens(term.Text)) == 0 {
+			return nil, fmt.Errorf("empty search term %q", word)
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty search query")
+	}
+	return terms, nil
+}
+
+// splitQuery splits a query on spaces outside double quotes
+func splitQuery(query string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			word.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if word.Len() > 0 {
+				words = append(words, strings.Trim(word.String(), `"`))
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, strings.Trim(word.String(), `"`))
+	}
+	return words
+}
+
+// searchTokens splits text into lowercase tokens on punctuation and at
+// camel case boundaries, so that "BenchmarkJSONParse/size=10" yields
+// benchmark, json, parse, size and 10
+func searchTokens(text string) []string {
+	var tokens []string
+	runes := []rune(text)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, strings.ToLower(string(runes[start:end])))
+			start = -1
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			// "stringBuilder" and the "P" of "JSONParse" start a new word
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				flush(i)
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(runes))
+	return tokens
+}
+
+// add indexes the metadata of a run
+func (idx *SearchIndex) add(run *models.BenchmarkRun) {
+	if i, found := slices.BinarySearch(idx.Runs, run.ID); !found {
+		idx.Runs = slices.Insert(idx.Runs, i, run.ID)
+	}
+	if idx.Entries == nil {
+		idx.Entries = make(map[string]*SearchEntry)
+	}
+	if idx.Terms == nil {
+		idx.Terms = make(map[string][]string)
+	}
+
+	entry := &SearchEntry{
+		ID:            run.ID,
+		Timestamp:     run.Timestamp,
+		Package:       run.Package,
+		Commit:        run.Commit,
+		CommitMessage: run.CommitMessage,
+		Tags:          run.Tags,
+		Note:          run.Note,
+		Failed:        run.Failed(),
+		Benchmarks:    make([]SearchBenchmark, 0, len(run.Results)),
+	}
+	idx.Entries[run.ID] = entry
+
+	idx.addTerms("id", run.ID, run.ID)
+	idx.addTerms("package", run.Package, run.ID)
+	idx.addTerms("commit", run.Commit+" "+run.CommitMessage, run.ID)
+	idx.addTerms("note", run.Note, run.ID)
+	for _, annotation := range run.Annotations {
+		idx.addTerms("note", annotation.Kind+" "+annotation.Message, run.ID)
+	}
+	for key, value := range run.Tags {
+		idx.addTerms("tag", key+"="+value, run.ID)
+	}
+	for _, result := range run.Results {
+		entry.Benchmarks = append(entry.Benchmarks, SearchBenchmark{Name: result.Name, NsPerOp: result.NsPerOp})
+		idx.addTerms("bench", result.Name, run.ID)
+	}
+}
+
+// addTerms indexes the tokens of text in a field of a run
+func (idx *SearchIndex) addTerms(field, text, id string) {
+	for _, token := range searchTokens(text) {
+		key := field + ":" + token
+		ids := idx.Terms[key]
+		if i, found := slices.BinarySearch(ids, id); !found {
+			idx.Terms[key] = slices.Insert(ids, i, id)
+		}
+	}
+}
+
+// match returns the sorted IDs of the runs matching a term: every token of
+// the term must prefix a token of the field, or of any field
+func (idx *SearchIndex) match(term SearchTerm) []string {
+	var result []string
+	for i, token := range searchTokens(term.Text) {
+		var ids []string
+		for key, keyIDs := range idx.Terms {
+			field, keyToken, _ := strings.Cut(key, ":")
+			if (term.Field == "" || term.Field == field) && strings.HasPrefix(keyToken, token) {
+				ids = union(ids, keyIDs)
+			}
+		}
+		if i == 0 {
+			result = ids
+		} else {
+			result = intersect(result, ids)
+		}
+		if len(result) == 0 {
+			return nil
+		}
+	}
+	return result
+}
+
+// Search returns the runs matching every term of a query, newest first,
+// with the benchmarks matching its unqualified and bench terms
+func (idx *SearchIndex) Search(terms []SearchTerm) []SearchHit {
+	var ids []string
+	for i, term := range terms {
+		matched := idx.match(term)
+		if i == 0 {
+			ids = matched
+		} else {
+			ids = intersect(ids, matched)
+		}
+	}
+
+	var nameTerms [][]string
+	for _, term := range terms {
+		if term.Field == "" || term.Field == "bench" {
+			nameTerms = append(nameTerms, searchTokens(term.Text))
+		}
+	}
+
+	hits := make([]SearchHit, 0, len(ids))
+	for _, id := range ids {
+		hit := SearchHit{SearchEntry: idx.Entries[id]}
+		if len(nameTerms) > 0 {
+			for _, bench := range hit.Benchmarks {
+				if matchesAll(searchTokens(bench.Name), nameTerms) {
+					hit.Matches = append(hit.Matches, bench)
+				}
+			}
+		}
+		hits = append(hits, hit)
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Timestamp.After(hits[j].Timestamp)
+	})
+	return hits
+}
+
+// HitsSince keeps the hits of runs taken at or after since
+func HitsSince(hits []SearchHit, since time.Time) []SearchHit {
+	return slices.DeleteFunc(hits, func(hit SearchHit) bool {
+		return hit.Timestamp.Before(since)
+	})
+}
+
+// matchesAll reports whether every token of every term prefixes one of
+// tokens
+func matchesAll(tokens []string, terms [][]string) bool {
+	for _, term := range terms {
+		for _, want := range term {
+			if !slices.ContainsFunc(tokens, func(token string) bool { return strings.HasPrefix(token, want) }) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// union merges two sorted lists of IDs
+func union(a, b []string) []string {
+	merged := make([]string, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	merged = append(merged, b...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// intersect returns the IDs in both sorted lists
+func intersect(a, b []string) []string {
+	var common []string
+	for _, id := range a {
+		if _, found := slices.BinarySearch(b, id); found {
+			common = append(common, id)
+		}
+	}
+	return common
+}
+
+// GetSearchIndexPath returns the path to the search index
+func (s *Storage) GetSearchIndexPath() string {
+	return filepath.Join(s.dir, searchIndexFile)
+}
+
+// Search returns the runs matching a query, newest first. See
+// ParseSearchQuery for the query syntax.
+func (s *Storage) Search(query string) ([]SearchHit, error) {
+	terms, err := ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.SearchIndex()
+	if err != nil {
+		return nil, err
+	}
+	return index.Search(terms), nil
+}
+
+// SearchIndex returns the search index of all runs. It is read from the
+// cache that Save keeps up to date, and rebuilt from the full history only
+// when runs were added or removed some other way.
+func (s *Storage) SearchIndex() (*SearchIndex, error) {
+	ids, err := s.RunIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, err := s.loadSearchIndex(); err == nil && slices.Equal(cached.Runs, ids) {
+		return cached, nil
+	}
+
+	runs, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	index := &SearchIndex{Entries: make(map[string]*SearchEntry), Terms: make(map[string][]string)}
+	for i := range runs {
+		index.add(&runs[i])
+	}
+	// Unreadable run files are skipped by List; record them as included so
+	// they do not trigger a rebuild every time
+	index.Runs = ids
+	if len(ids) > 0 {
+		s.saveSearchIndex(index)
+	}
+	return index, nil
+}
+
+// updateSearchIndex adds a newly saved run to the search index. A run that
+// was saved before is already included, so the index is dropped and
+// rebuilt on its next use instead.
+func (s *Storage) updateSearchIndex(run *models.BenchmarkRun) error {
+	index, err := s.loadSearchIndex()
+	if os.IsNotExist(err) {
+		// Without an index the first search builds it from the full history
+		return nil
+	}
+	if err != nil {
+		return s.invalidateSearchIndex()
+	}
+	if _, found := slices.BinarySearch(index.Runs, run.ID); found {
+		return s.invalidateSearchIndex()
+	}
+	index.add(run)
+	return s.saveSearchIndex(index)
+}
+
+// invalidateSearchIndex removes the search index
+func (s *Storage) invalidateSearchIndex() error {
+	if err := os.Remove(s.GetSearchIndexPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove search index: %w", err)
+	}
+	return nil
+}
+
+// loadSearchIndex reads the search index
+func (s *Storage) loadSearchIndex() (*SearchIndex, error) {
+	data, err := os.ReadFile(s.GetSearchIndexPath())
+	if err != nil {
+		return nil, err
+	}
+	var index SearchIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse search index: %w", err)
+	}
+	return &index, nil
+}
+
+// saveSearchIndex replaces the search index atomically
+func (s *Storage) saveSearchIndex(index *SearchIndex) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+
+	tmp := s.GetSearchIndexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	if err := replaceFile(tmp, s.GetSearchIndexPath()); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+)
+
+func TestSearchTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"BenchmarkStringBuilder", []string{"benchmark", "string", "builder"}},
+		{"BenchmarkJSONParse/size=10-8", []string{"benchmark", "json", "parse", "size", "10", "8"}},
+		{"github.com/acme/payments", []string{"github", "com", "acme", "payments"}},
+		{"Fix slow path", []string{"fix", "slow", "path"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := searchTokens(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("searchTokens(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	terms, err := ParseSearchQuery(`pkg:payments String note:"new disk"`)
+	if err != nil {
+		t.Fatalf("ParseSearchQuery failed: %v", err)
+	}
+	want := []SearchTerm{{Field: "package", Text: "payments"}, {Text: "String"}, {Field: "note", Text: "new disk"}}
+	if !slices.Equal(terms, want) {
+		t.Errorf("Expected %v, got %v", want, terms)
+	}
+
+	for _, query := range []string{"", "  ", "color:red", "bench:--"} {
+		if _, err := ParseSearchQuery(query); err == nil {
+			t.Errorf("Expected an error for %q", query)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	now := time.Now()
+	runs := []*models.BenchmarkRun{
+		{
+			ID: "run-1", Timestamp: now, Package: "github.com/acme/payments",
+			Commit: "4fa83cc0123456789", CommitMessage: "Speed up refund validation",
+			Tags: map[string]string{"env": "ci"},
+			Results: []models.BenchmarkResult{
+				{Name: "BenchmarkStringBuilder", NsPerOp: 100},
+				{Name: "BenchmarkRefund", NsPerOp: 300},
+			},
+		},
+		{
+			ID: "run-2", Timestamp: now.Add(time.Hour), Package: "github.com/acme/ledger",
+			Note:    "after upgrading the disk",
+			Results: []models.BenchmarkResult{{Name: "BenchmarkStringConcat", NsPerOp: 200}},
+		},
+	}
+	for _, run := range runs {
+		if err := s.Save(run); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query   string
+		ids     []string
+		matches int
+	}{
+		{"String", []string{"run-2", "run-1"}, 2},
+		{"package:payments String", []string{"run-1"}, 1},
+		{"tag:env=ci", []string{"run-1"}, 0},
+		{"commit:4fa83cc", []string{"run-1"}, 0},
+		{"refund", []string{"run-1"}, 1},
+		{"note:disk", []string{"run-2"}, 0},
+		{"bench:Concat package:payments", nil, 0},
+	}
+	for _, tt := range tests {
+		hits, err := s.Search(tt.query)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		var ids []string
+		matches := 0
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+			matches += len(hit.Matches)
+		}
+		if !slices.Equal(ids, tt.ids) || matches != tt.matches {
+			t.Errorf("Search(%q) = %v with %d matching benchmarks, want %v with %d", tt.query, ids, matches, tt.ids, tt.matches)
+		}
+	}
+
+	hits, err := s.Search("String")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if recent := HitsSince(hits, now.Add(30*time.Minute)); len(recent) != 1 || recent[0].ID != "run-2" {
+		t.Errorf("Expected only run-2 since 30 minutes from now, got %v", recent)
+	}
+
+	// The first search built the index; saving updates it and deleting
+	// drops it
+	if _, err := os.Stat(s.GetSearchIndexPath()); err != nil {
+		t.Fatalf("Expected a search index: %v", err)
+	}
+	if err := s.Save(&models.BenchmarkRun{ID: "run-3", Timestamp: now, Package: "github.com/acme/search"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	index, err := s.loadSearchIndex()
+	if err != nil || !slices.Equal(index.Runs, []string{"run-1", "run-2", "run-3"}) {
+		t.Fatalf("Expected the saved run to be indexed, got %+v, %v", index, err)
+	}
+	if err := s.Delete("run-3"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(s.GetSearchIndexPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected the search index to be dropped, got %v", err)
+	}
+	if hits, err := s.Search("package:search"); err != nil || len(hits) != 0 {
+		t.Errorf("Expected the deleted run not to be found, got %v, %v", hits, err)
+	}
+}
+
+func TestSaveMetadata(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	run := &models.BenchmarkRun{ID: "run-1", Timestamp: time.Now(), Results: []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: 100}}}
+	if err := s.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := s.Search("BenchmarkA"); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err := s.Aggregates(); err != nil {
+		t.Fatalf("Aggregates failed: %v", err)
+	}
+
+	run.Note = "before the rewrite"
+	if err := s.SaveMetadata(run); err != nil {
+		t.Fatalf("SaveMetadata failed: %v", err)
+	}
+	if hits, err := s.Search("note:rewrite"); err != nil || len(hits) != 1 {
+		t.Errorf("Search for the new note = %v, %v", hits, err)
+	}
+	// The statistics do not depend on metadata
+	if _, err := os.Stat(s.GetAggregatesPath()); err != nil {
+		t.Errorf("Statistics cache was dropped: %v", err)
+	}
+
+	if err := s.SaveMetadata(&models.BenchmarkRun{ID: "missing"}); err == nil {
+		t.Error("Expected an error for a run that was never saved")
+	}
+}
@@ -2,11 +2,13 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/alenon/gokanon/internal/models"
@@ -31,14 +33,28 @@ func NewStorage(dir string) *Storage {
 
 // Save saves a benchmark run to storage
 func (s *Storage) Save(run *models.BenchmarkRun) error {
+	if err := s.writeRun(run); err != nil {
+		return err
+	}
+
+	// The run is saved; a stale cache is detected and rebuilt when read
+	if err := s.updateAggregates(run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update statistics cache: %v\n", err)
+	}
+	if err := s.updateSearchIndex(run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update search index: %v\n", err)
+	}
+
+	return nil
+}
+
+// writeRun writes the JSON of a run, leaving the statistics cache alone
+func (s *Storage) writeRun(run *models.BenchmarkRun) error {
 	// Ensure directory exists
 	if err := os.MkdirAll(s.dir, 0755); err != nil {
 		return fmt.Errorf("failed to create storage directory: %w", err)
 	}
 
-	// Create filename based on ID
-	filename := filepath.Join(s.dir, run.ID+".json")
-
 	// Marshal to JSON
 	data, err := json.MarshalIndent(run, "", "  ")
 	if err != nil {
@@ -46,70 +62,164 @@ func (s *Storage) Save(run *models.BenchmarkRun) error {
 	}
 
 	// Write to file
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if err := s.writeRunFile(run.ID, data); err != nil {
 		return fmt.Errorf("failed to write benchmark run: %w", err)
 	}
+	if err := s.updateManifest(run.ID, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update manifest: %v\n", err)
+	}
+	return nil
+}
 
+// SaveMetadata rewrites a saved run whose metadata, such as its note or
+// tags, changed. Its results must be unchanged: the statistics cache is
+// kept and only the search index is rebuilt.
+func (s *Storage) SaveMetadata(run *models.BenchmarkRun) error {
+	if !s.Exists(run.ID) {
+		return withKind(ErrRunNotFound, fmt.Errorf("benchmark run %s not found", run.ID))
+	}
+	if err := s.writeRun(run); err != nil {
+		return err
+	}
+	if err := s.invalidateSearchIndex(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 	return nil
 }
 
 // Load loads a benchmark run from storage by ID
 func (s *Storage) Load(id string) (*models.BenchmarkRun, error) {
-	filename := filepath.Join(s.dir, id+".json")
+	data, err := s.RunData(id)
+	if err != nil {
+		return nil, err
+	}
 
-	data, err := os.ReadFile(filename)
+	var run models.BenchmarkRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal benchmark run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// RunData returns the stored JSON of a benchmark run, uncompressed
+func (s *Storage) RunData(id string) ([]byte, error) {
+	data, err := s.readRunFile(id)
+	if os.IsNotExist(err) {
+		return nil, withKind(ErrRunNotFound, fmt.Errorf("failed to read benchmark run: %w", err))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read benchmark run: %w", err)
 	}
+	return data, nil
+}
 
+// ImportRun stores the JSON of a run saved by another storage, such as on
+// another machine. The JSON is kept unchanged so that both copies stay
+// byte for byte identical; a run with the same ID is replaced.
+func (s *Storage) ImportRun(data []byte) (*models.BenchmarkRun, error) {
 	var run models.BenchmarkRun
 	if err := json.Unmarshal(data, &run); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal benchmark run: %w", err)
 	}
+	if run.ID == "" || strings.ContainsAny(run.ID, `/\`) {
+		return nil, fmt.Errorf("invalid benchmark run ID %q", run.ID)
+	}
 
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	if err := s.writeRunFile(run.ID, data); err != nil {
+		return nil, fmt.Errorf("failed to write benchmark run: %w", err)
+	}
+	if err := s.updateManifest(run.ID, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update manifest: %v\n", err)
+	}
+	if err := s.updateAggregates(&run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update statistics cache: %v\n", err)
+	}
+	if err := s.updateSearchIndex(&run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update search index: %v\n", err)
+	}
 	return &run, nil
 }
 
-// List returns all available benchmark run IDs, sorted by timestamp (newest first)
+// List returns all available benchmark runs, sorted by timestamp (newest
+// first). It loads every run file: callers that need the newest runs use
+// Latest, and those that only need IDs, times, packages or benchmark names
+// use Index.
 func (s *Storage) List() ([]models.BenchmarkRun, error) {
-	// Check if directory exists
-	if _, err := os.Stat(s.dir); os.IsNotExist(err) {
-		return []models.BenchmarkRun{}, nil
-	}
-
-	entries, err := os.ReadDir(s.dir)
+	entries, err := s.Index()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read storage directory: %w", err)
+		return nil, err
 	}
 
-	var runs []models.BenchmarkRun
+	runs := []models.BenchmarkRun{}
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
-			continue
-		}
-
-		id := entry.Name()[:len(entry.Name())-5] // Remove .json
-		run, err := s.Load(id)
+		run, err := s.Load(entry.ID)
 		if err != nil {
 			continue // Skip invalid files
 		}
 		runs = append(runs, *run)
 	}
 
-	// Sort by timestamp, newest first
-	sort.Slice(runs, func(i, j int) bool {
+	// A run file changed since it was recorded may have another timestamp
+	sort.SliceStable(runs, func(i, j int) bool {
 		return runs[i].Timestamp.After(runs[j].Timestamp)
 	})
 
 	return runs, nil
 }
 
+// Latest returns the n newest runs that keep accepts, newest first,
+// loading only as many runs as needed to find them. A non-positive n
+// returns every accepted run.
+func (s *Storage) Latest(n int, keep func(*models.BenchmarkRun) bool) ([]models.BenchmarkRun, error) {
+	entries, err := s.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []models.BenchmarkRun{}
+	for _, entry := range entries {
+		if n > 0 && len(runs) == n {
+			break
+		}
+		run, err := s.Load(entry.ID)
+		if err != nil || !keep(run) {
+			continue
+		}
+		runs = append(runs, *run)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Timestamp.After(runs[j].Timestamp)
+	})
+	return runs, nil
+}
+
 // Delete removes a benchmark run from storage, including profile files
 func (s *Storage) Delete(id string) error {
-	filename := filepath.Join(s.dir, id+".json")
-	if err := os.Remove(filename); err != nil {
+	if err := os.Remove(s.runPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return withKind(ErrRunNotFound, fmt.Errorf("failed to delete benchmark run: %w", err))
+		}
 		return fmt.Errorf("failed to delete benchmark run: %w", err)
 	}
+	// A legacy record left next to a compressed one
+	_, legacy := s.runPaths(id)
+	os.Remove(legacy)
+	if err := s.removeFromManifest(id); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update manifest: %v\n", err)
+	}
+
+	// Statistics cannot be updated to leave a run out; rebuild them on use
+	if err := s.invalidateAggregates(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := s.invalidateSearchIndex(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 
 	// Also delete profile directory if it exists
 	profileDir := s.GetProfileDir(id)
@@ -119,22 +229,26 @@ func (s *Storage) Delete(id string) error {
 			fmt.Fprintf(os.Stderr, "Warning: failed to delete profile directory: %v\n", err)
 		}
 	}
+	if err := s.deleteAnalyses(id); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 
 	return nil
 }
 
-// GetLatest returns the most recent benchmark run
+// GetLatest returns the most recent benchmark run, loading only that run
 func (s *Storage) GetLatest() (*models.BenchmarkRun, error) {
-	runs, err := s.List()
+	entries, err := s.Index()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(runs) == 0 {
-		return nil, fmt.Errorf("no benchmark runs found")
+	for _, entry := range entries {
+		if run, err := s.Load(entry.ID); err == nil {
+			return run, nil
+		}
 	}
-
-	return &runs[0], nil
+	return nil, withKind(ErrRunNotFound, errors.New("no benchmark runs found"))
 }
 
 // GetProfileDir returns the profile directory for a given run ID
@@ -152,6 +266,91 @@ func (s *Storage) GetMemoryProfilePath(runID string) string {
 	return filepath.Join(s.GetProfileDir(runID), "mem.prof")
 }
 
+// GetWarmupProfilePath returns the path to the heap profile taken after
+// warmup for a run
+func (s *Storage) GetWarmupProfilePath(runID string) string {
+	return filepath.Join(s.GetProfileDir(runID), "mem-warmup.prof")
+}
+
+// GetBlockProfilePath returns the path to the block profile for a run
+func (s *Storage) GetBlockProfilePath(runID string) string {
+	return filepath.Join(s.GetProfileDir(runID), "block.prof")
+}
+
+// GetMutexProfilePath returns the path to the mutex profile for a run
+func (s *Storage) GetMutexProfilePath(runID string) string {
+	return filepath.Join(s.GetProfileDir(runID), "mutex.prof")
+}
+
+// GetAttachedProfilePath returns the path to a named attached profile for a run
+func (s *Storage) GetAttachedProfilePath(runID, name string) string {
+	return filepath.Join(s.GetProfileDir(runID), name+".prof")
+}
+
+// SaveAttachedProfile stores an externally collected profile under the given name
+func (s *Storage) SaveAttachedProfile(runID, name string, data io.Reader) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	profileDir := s.GetProfileDir(runID)
+	if err := os.MkdirAll(profileDir, 0755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	return writeProfileFile(s.GetAttachedProfilePath(runID, name), data)
+}
+
+// ValidateProfileName checks that a name is usable for an attached profile.
+// Names must be simple identifiers and may not shadow the built-in profiles.
+func ValidateProfileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	switch name {
+	case "cpu", "mem", "memory", "warmup", "mem-warmup", "block", "mutex":
+		return fmt.Errorf("profile name %q is reserved", name)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("invalid profile name %q: use letters, digits, '-' or '_'", name)
+		}
+	}
+	return nil
+}
+
+// reservedFileNames are device names Windows does not allow as file names,
+// with or without an extension
+var reservedFileNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// ValidateBaselineName checks that a name is usable as a baseline. Baselines
+// are stored as <name>.json, so names must be valid file names on every
+// platform; a baseline saved on Linux must still load on Windows.
+func ValidateBaselineName(name string) error {
+	if name == "" {
+		return fmt.Errorf("baseline name is required")
+	}
+	if name == "." || name == ".." || strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("invalid baseline name %q: may not end with '.' or a space", name)
+	}
+	for _, r := range name {
+		if r < 32 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return fmt.Errorf("invalid baseline name %q: may not contain %q", name, r)
+		}
+	}
+	base, _, _ := strings.Cut(name, ".")
+	if reservedFileNames[strings.ToUpper(base)] {
+		return fmt.Errorf("baseline name %q is reserved on Windows", name)
+	}
+	return nil
+}
+
 // SaveProfile saves a profile file to the storage
 func (s *Storage) SaveProfile(runID, profileType string, data io.Reader) error {
 	profileDir := s.GetProfileDir(runID)
@@ -168,23 +367,18 @@ func (s *Storage) SaveProfile(runID, profileType string, data io.Reader) error {
 		filename = s.GetCPUProfilePath(runID)
 	case "memory", "mem":
 		filename = s.GetMemoryProfilePath(runID)
+	case "warmup":
+		filename = s.GetWarmupProfilePath(runID)
+	case "block":
+		filename = s.GetBlockProfilePath(runID)
+	case "mutex":
+		filename = s.GetMutexProfilePath(runID)
 	default:
 		return fmt.Errorf("unknown profile type: %s", profileType)
 	}
 
-	// Create file
-	out, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create profile file: %w", err)
-	}
-	defer out.Close()
-
-	// Copy data
-	if _, err := io.Copy(out, data); err != nil {
-		return fmt.Errorf("failed to write profile data: %w", err)
-	}
-
-	return nil
+	// Compressed like the profiles the Go runtime writes
+	return writeProfileFile(filename, data)
 }
 
 // LoadProfile loads a profile file from storage
@@ -195,11 +389,20 @@ func (s *Storage) LoadProfile(runID, profileType string) ([]byte, error) {
 		filename = s.GetCPUProfilePath(runID)
 	case "memory", "mem":
 		filename = s.GetMemoryProfilePath(runID)
+	case "warmup":
+		filename = s.GetWarmupProfilePath(runID)
+	case "block":
+		filename = s.GetBlockProfilePath(runID)
+	case "mutex":
+		filename = s.GetMutexProfilePath(runID)
 	default:
 		return nil, fmt.Errorf("unknown profile type: %s", profileType)
 	}
 
 	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, withKind(ErrProfileMissing, fmt.Errorf("failed to read profile file: %w", err))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read profile file: %w", err)
 	}
@@ -215,6 +418,12 @@ func (s *Storage) HasProfile(runID, profileType string) bool {
 		filename = s.GetCPUProfilePath(runID)
 	case "memory", "mem":
 		filename = s.GetMemoryProfilePath(runID)
+	case "warmup":
+		filename = s.GetWarmupProfilePath(runID)
+	case "block":
+		filename = s.GetBlockProfilePath(runID)
+	case "mutex":
+		filename = s.GetMutexProfilePath(runID)
 	default:
 		return false
 	}
@@ -230,6 +439,10 @@ func (s *Storage) GetBaselineDir() string {
 
 // SaveBaseline saves a benchmark run as a baseline with the given name
 func (s *Storage) SaveBaseline(name, runID, description string, tags map[string]string) (*models.Baseline, error) {
+	if err := ValidateBaselineName(name); err != nil {
+		return nil, err
+	}
+
 	// Load the run
 	run, err := s.Load(runID)
 	if err != nil {
@@ -266,11 +479,23 @@ func (s *Storage) SaveBaseline(name, runID, description string, tags map[string]
 	return baseline, nil
 }
 
+// CreateBaseline is SaveBaseline failing with ErrBaselineExists instead
+// of replacing a baseline of the same name
+func (s *Storage) CreateBaseline(name, runID, description string, tags map[string]string) (*models.Baseline, error) {
+	if s.HasBaseline(name) {
+		return nil, withKind(ErrBaselineExists, fmt.Errorf("baseline %s already exists", name))
+	}
+	return s.SaveBaseline(name, runID, description, tags)
+}
+
 // LoadBaseline loads a baseline by name
 func (s *Storage) LoadBaseline(name string) (*models.Baseline, error) {
 	filename := filepath.Join(s.GetBaselineDir(), name+".json")
 
 	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, withKind(ErrBaselineNotFound, fmt.Errorf("failed to read baseline %s: %w", name, err))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read baseline %s: %w", name, err)
 	}
@@ -283,6 +508,38 @@ func (s *Storage) LoadBaseline(name string) (*models.Baseline, error) {
 	return &baseline, nil
 }
 
+// BaselineData returns the stored JSON of a baseline
+func (s *Storage) BaselineData(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.GetBaselineDir(), name+".json"))
+	if os.IsNotExist(err) {
+		return nil, withKind(ErrBaselineNotFound, fmt.Errorf("failed to read baseline %s: %w", name, err))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// ImportBaseline stores the JSON of a baseline saved by another storage,
+// unchanged. A baseline with the same name is replaced.
+func (s *Storage) ImportBaseline(data []byte) (*models.Baseline, error) {
+	var baseline models.Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal baseline: %w", err)
+	}
+	if err := ValidateBaselineName(baseline.Name); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.GetBaselineDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create baselines directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.GetBaselineDir(), baseline.Name+".json"), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return &baseline, nil
+}
+
 // ListBaselines returns all available baselines
 func (s *Storage) ListBaselines() ([]models.Baseline, error) {
 	baselineDir := s.GetBaselineDir()
@@ -323,6 +580,9 @@ func (s *Storage) ListBaselines() ([]models.Baseline, error) {
 func (s *Storage) DeleteBaseline(name string) error {
 	filename := filepath.Join(s.GetBaselineDir(), name+".json")
 	if err := os.Remove(filename); err != nil {
+		if os.IsNotExist(err) {
+			return withKind(ErrBaselineNotFound, fmt.Errorf("failed to delete baseline %s: %w", name, err))
+		}
 		return fmt.Errorf("failed to delete baseline %s: %w", name, err)
 	}
 	return nil
@@ -334,3 +594,81 @@ func (s *Storage) HasBaseline(name string) bool {
 	_, err := os.Stat(filename)
 	return err == nil
 }
+
+// GetSnapshotDir returns the dashboard snapshots directory
+func (s *Storage) GetSnapshotDir() string {
+	return filepath.Join(s.dir, "snapshots")
+}
+
+// SaveSnapshot saves a dashboard snapshot, replacing one with the same name.
+// Snapshot names follow the rules of baseline names.
+func (s *Storage) SaveSnapshot(snapshot *models.Snapshot) error {
+	if err := ValidateBaselineName(snapshot.Name); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.GetSnapshotDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshots directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.GetSnapshotDir(), snapshot.Name+".json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot loads a dashboard snapshot by name
+func (s *Storage) LoadSnapshot(name string) (*models.Snapshot, error) {
+	if err := ValidateBaselineName(name); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.GetSnapshotDir(), name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", name, err)
+	}
+
+	var snapshot models.Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots returns all dashboard snapshots, newest first
+func (s *Storage) ListSnapshots() ([]models.Snapshot, error) {
+	entries, err := os.ReadDir(s.GetSnapshotDir())
+	if os.IsNotExist(err) {
+		return []models.Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+
+	snapshots := []models.Snapshot{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		snapshot, err := s.LoadSnapshot(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue // Skip invalid files
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// HasSnapshot checks if a dashboard snapshot with the given name exists
+func (s *Storage) HasSnapshot(name string) bool {
+	_, err := os.Stat(filepath.Join(s.GetSnapshotDir(), name+".json"))
+	return err == nil
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -56,8 +57,8 @@ func TestSaveAndLoad(t *testing.T) {
 		t.Fatalf("Save failed: %v", err)
 	}
 
-	// Verify file exists
-	filename := filepath.Join(tempDir, run.ID+".json")
+	// Verify file exists, compressed
+	filename := filepath.Join(tempDir, run.ID+".json.gz")
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		t.Fatalf("Expected file %s to exist", filename)
 	}
@@ -131,6 +132,37 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestLatest(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	now := time.Now()
+	for i, status := range []string{"", "", models.StatusFailed, ""} {
+		run := &models.BenchmarkRun{
+			ID:        fmt.Sprintf("run-%d", i+1),
+			Timestamp: now.Add(time.Duration(i) * time.Second),
+			Status:    status,
+			Results:   []models.BenchmarkResult{{Name: "Test", Iterations: 100, NsPerOp: 100.0}},
+		}
+		if err := s.Save(run); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	succeeded := func(run *models.BenchmarkRun) bool { return !run.Failed() }
+
+	runs, err := s.Latest(2, succeeded)
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != "run-4" || runs[1].ID != "run-2" {
+		t.Errorf("Expected run-4 and run-2, got %v", runs)
+	}
+
+	// Every accepted run without a limit
+	runs, err = s.Latest(0, succeeded)
+	if err != nil || len(runs) != 3 {
+		t.Errorf("Expected 3 runs, got %d (%v)", len(runs), err)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	// Create temp directory
 	tempDir := t.TempDir()
@@ -152,7 +184,7 @@ func TestDelete(t *testing.T) {
 	}
 
 	// Verify file exists
-	filename := filepath.Join(tempDir, run.ID+".json")
+	filename := filepath.Join(tempDir, run.ID+".json.gz")
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		t.Fatalf("Expected file to exist before delete")
 	}
@@ -306,6 +338,13 @@ func TestGetProfilePaths(t *testing.T) {
 	if memPath != expectedMemPath {
 		t.Errorf("Expected memory profile path %s, got %s", expectedMemPath, memPath)
 	}
+
+	if got, want := s.GetBlockProfilePath(runID), filepath.Join(tempDir, "profiles", runID, "block.prof"); got != want {
+		t.Errorf("Expected block profile path %s, got %s", want, got)
+	}
+	if got, want := s.GetMutexProfilePath(runID), filepath.Join(tempDir, "profiles", runID, "mutex.prof"); got != want {
+		t.Errorf("Expected mutex profile path %s, got %s", want, got)
+	}
 }
 
 func TestSaveAndLoadProfile(t *testing.T) {
@@ -321,15 +360,14 @@ func TestSaveAndLoadProfile(t *testing.T) {
 		{"cpu profile", "cpu", "cpu profile data"},
 		{"memory profile", "memory", "memory profile data"},
 		{"mem profile alias", "mem", "mem profile data"},
+		{"warmup heap profile", "warmup", "warmup profile data"},
+		{"block profile", "block", "block profile data"},
+		{"mutex profile", "mutex", "mutex profile data"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Save profile
-			reader := os.NewFile(0, tt.data)
-			defer reader.Close()
-
-			// Use a pipe to simulate io.Reader
+			// Save profile, using a pipe to simulate io.Reader
 			r, w, _ := os.Pipe()
 			go func() {
 				w.Write([]byte(tt.data))
@@ -498,6 +536,53 @@ func TestBaselineOperations(t *testing.T) {
 	}
 }
 
+func TestSnapshotOperations(t *testing.T) {
+	s := NewStorage(t.TempDir())
+
+	snapshots, err := s.ListSnapshots()
+	if err != nil || len(snapshots) != 0 {
+		t.Fatalf("Expected no snapshots, got %v (%v)", snapshots, err)
+	}
+
+	older := &models.Snapshot{Name: "v1.0.0", CreatedAt: time.Now().Add(-time.Hour)}
+	newer := &models.Snapshot{
+		Name:      "v1.1.0",
+		CreatedAt: time.Now(),
+		Stats:     map[string]interface{}{"totalRuns": 3.0},
+	}
+	for _, snapshot := range []*models.Snapshot{older, newer} {
+		if err := s.SaveSnapshot(snapshot); err != nil {
+			t.Fatalf("SaveSnapshot failed: %v", err)
+		}
+	}
+
+	loaded, err := s.LoadSnapshot("v1.1.0")
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if loaded.Stats["totalRuns"] != 3.0 {
+		t.Errorf("Expected the stats to be saved, got %v", loaded.Stats)
+	}
+	if !s.HasSnapshot("v1.0.0") || s.HasSnapshot("v2.0.0") {
+		t.Error("HasSnapshot reports the wrong snapshots")
+	}
+
+	snapshots, err = s.ListSnapshots()
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != "v1.1.0" {
+		t.Errorf("Expected 2 snapshots, newest first, got %+v", snapshots)
+	}
+
+	if err := s.SaveSnapshot(&models.Snapshot{Name: "release/1"}); err == nil {
+		t.Error("Expected an error for a name with a path separator")
+	}
+	if _, err := s.LoadSnapshot("../baselines/v1"); err == nil {
+		t.Error("Expected an error when loading a snapshot outside the snapshots directory")
+	}
+}
+
 func TestGetBaselineDir(t *testing.T) {
 	tempDir := t.TempDir()
 	s := NewStorage(tempDir)
@@ -746,3 +831,145 @@ func TestHasProfileWithInvalidPath(t *testing.T) {
 		t.Error("Expected HasProfile to return false for non-existent storage path")
 	}
 }
+
+func TestSaveAttachedProfile(t *testing.T) {
+	tempDir := t.TempDir()
+	s := NewStorage(tempDir)
+	runID := "test-run-123"
+
+	if err := s.SaveAttachedProfile(runID, "wall", strings.NewReader("profile data")); err != nil {
+		t.Fatalf("SaveAttachedProfile failed: %v", err)
+	}
+
+	path := s.GetAttachedProfilePath(runID, "wall")
+	if path != filepath.Join(tempDir, "profiles", runID, "wall.prof") {
+		t.Errorf("Unexpected attached profile path: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read attached profile: %v", err)
+	}
+	if string(data) != "profile data" {
+		t.Errorf("Unexpected profile content: %s", data)
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"wall", false},
+		{"fg-prof_2", false},
+		{"", true},
+		{"cpu", true},
+		{"mem", true},
+		{"mem-warmup", true},
+		{"block", true},
+		{"mutex", true},
+		{"../escape", true},
+		{"has space", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateProfileName(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateProfileName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBaselineName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"v1.0", false},
+		{"release 2024", false},
+		{"main-branch_2", false},
+		{"console", false},
+		{"", true},
+		{"..", true},
+		{"../escape", true},
+		{`dir\name`, true},
+		{"a:b", true},
+		{"what?", true},
+		{"trailing.", true},
+		{"trailing ", true},
+		{"CON", true},
+		{"nul.json", true},
+		{"com1", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateBaselineName(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateBaselineName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSaveBaselineInvalidName(t *testing.T) {
+	s := NewStorage(t.TempDir())
+	run := &models.BenchmarkRun{ID: "run-1", Timestamp: time.Now()}
+	if err := s.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if _, err := s.SaveBaseline("../outside", run.ID, "", nil); err == nil {
+		t.Error("Expected an error for a baseline name with a path separator")
+	}
+}
+
+func TestImportRunAndBaseline(t *testing.T) {
+	src := NewStorage(t.TempDir())
+	run := &models.BenchmarkRun{
+		ID:        "remote-run",
+		Timestamp: time.Now(),
+		Results:   []models.BenchmarkResult{{Name: "BenchmarkA", NsPerOp: 100}},
+	}
+	if err := src.Save(run); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := src.SaveBaseline("main", run.ID, "", nil); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+
+	dst := NewStorage(t.TempDir())
+	data, err := src.RunData(run.ID)
+	if err != nil {
+		t.Fatalf("RunData failed: %v", err)
+	}
+	if _, err := dst.ImportRun(data); err != nil {
+		t.Fatalf("ImportRun failed: %v", err)
+	}
+	imported, err := dst.RunData(run.ID)
+	if err != nil {
+		t.Fatalf("RunData failed after import: %v", err)
+	}
+	if string(imported) != string(data) {
+		t.Error("Expected the imported run to be stored byte for byte")
+	}
+
+	data, err = src.BaselineData("main")
+	if err != nil {
+		t.Fatalf("BaselineData failed: %v", err)
+	}
+	baseline, err := dst.ImportBaseline(data)
+	if err != nil {
+		t.Fatalf("ImportBaseline failed: %v", err)
+	}
+	if baseline.Name != "main" || !dst.HasBaseline("main") {
+		t.Errorf("Expected baseline main to be imported, got %+v", baseline)
+	}
+
+	for _, data := range []string{`{`, `{"id": ""}`, `{"id": "../escape"}`} {
+		if _, err := dst.ImportRun([]byte(data)); err == nil {
+			t.Errorf("Expected ImportRun(%s) to fail", data)
+		}
+	}
+	if _, err := dst.ImportBaseline([]byte(`{"name": "a/b"}`)); err == nil {
+		t.Error("Expected ImportBaseline to reject an invalid name")
+	}
+}
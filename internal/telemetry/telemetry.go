@@ -0,0 +1,305 @@
+// Package telemetry counts which commands and features are used, to help
+// the maintainers prioritize. It is off unless turned on with
+// 'gokanon telemetry on', records no benchmark data, names, paths or
+// arguments, and sends nothing that 'gokanon telemetry status' does not
+// show first.
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"time"
+
+	"github.com/alenon/gokanon/internal/config"
+)
+
+// Endpoint is the URL reports are posted to, set in release builds with
+// -ldflags "-X github.com/alenon/gokanon/internal/telemetry.Endpoint=...".
+// Without one, and without $GOKANON_TELEMETRY_URL, usage is only counted
+// locally.
+var Endpoint = ""
+
+// SendInterval is how long usage is counted before it is reported
+const SendInterval = 24 * time.Hour
+
+// retryInterval is how long a report that failed to send waits before
+// being sent again, so that an unreachable endpoint does not slow down
+// every command
+const retryInterval = time.Hour
+
+// requestTimeout bounds how long a command waits for the endpoint
+const requestTimeout = 3 * time.Second
+
+// eventPattern is what an event name may look like, so that no free text
+// given on the command line is ever recorded
+var eventPattern = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)*$`)
+
+// Settings is whether telemetry is on
+type Settings struct {
+	Enabled   bool      `json:"enabled"`
+	ID        string    `json:"id,omitempty"` // Random, new each time telemetry is turned on
+	EnabledAt time.Time `json:"enabled_at,omitempty"`
+}
+
+// Report is everything sent to the endpoint: how many times each event
+// happened since Start
+type Report struct {
+	ID      string         `json:"id"`
+	Version string         `json:"version"`
+	OS      string         `json:"os"`
+	Arch    string         `json:"arch"`
+	Start   time.Time      `json:"start"` // Day the first event was counted, in UTC
+	Counts  map[string]int `json:"counts"`
+}
+
+// pending is the report file: the counts not sent yet
+type pending struct {
+	Report      Report    `json:"report"`
+	LastAttempt time.Time `json:"last_attempt,omitempty"`
+}
+
+// Collector counts events in a report file and sends the report
+type Collector struct {
+	SettingsPath string
+	ReportPath   string
+	Endpoint     string // Empty to only count locally
+	Version      string // gokanon version sent with the report
+	client       *http.Client
+}
+
+// Default returns the collector of the user's settings and report under
+// the XDG base directories
+func Default(version string) *Collector {
+	endpoint := Endpoint
+	if env := os.Getenv("GOKANON_TELEMETRY_URL"); env != "" {
+		endpoint = env
+	}
+	return &Collector{
+		SettingsPath: config.TelemetrySettingsPath(),
+		ReportPath:   config.TelemetryReportPath(),
+		Endpoint:     endpoint,
+		Version:      version,
+	}
+}
+
+// OptOut returns the environment variable turning telemetry off even when
+// it was turned on, or an empty string
+func OptOut() string {
+	if v := os.Getenv("GOKANON_TELEMETRY"); v == "off" || v == "0" || v == "false" {
+		return "GOKANON_TELEMETRY"
+	}
+	if v := os.Getenv("DO_NOT_TRACK"); v != "" && v != "0" {
+		return "DO_NOT_TRACK"
+	}
+	return ""
+}
+
+// Settings returns whether telemetry was turned on. Without a settings
+// file it is off.
+func (c *Collector) Settings() (Settings, error) {
+	var settings Settings
+	if c.SettingsPath == "" {
+		return settings, nil
+	}
+	data, err := os.ReadFile(c.SettingsPath)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to read telemetry settings: %w", err)
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse telemetry settings %s: %w", c.SettingsPath, err)
+	}
+	return settings, nil
+}
+
+// Active reports whether events are counted: telemetry is on and not
+// turned off by the environment
+func (c *Collector) Active() bool {
+	if OptOut() != "" {
+		return false
+	}
+	settings, err := c.Settings()
+	return err == nil && settings.Enabled
+}
+
+// Enable turns telemetry on under a new random ID
+func (c *Collector) Enable() (Settings, error) {
+	if c.SettingsPath == "" {
+		return Settings{}, fmt.Errorf("no user config directory to record the setting in")
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Settings{}, fmt.Errorf("failed to generate telemetry ID: %w", err)
+	}
+	settings := Settings{Enabled: true, ID: hex.EncodeToString(id), EnabledAt: time.Now().UTC()}
+	return settings, writeJSON(c.SettingsPath, settings)
+}
+
+// Disable turns telemetry off and deletes the counts not sent yet
+func (c *Collector) Disable() error {
+	if c.SettingsPath != "" {
+		if err := writeJSON(c.SettingsPath, Settings{}); err != nil {
+			return err
+		}
+	}
+	if c.ReportPath != "" {
+		if err := os.Remove(c.ReportPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete telemetry report: %w", err)
+		}
+	}
+	return nil
+}
+
+// Record counts events, e.g. "command.run" or "export.html", when
+// telemetry is active. Events that are not lowercase dot-separated words
+// are dropped.
+func (c *Collector) Record(now time.Time, events ...string) error {
+	if !c.Active() || c.ReportPath == "" {
+		return nil
+	}
+	settings, err := c.Settings()
+	if err != nil {
+		return err
+	}
+	p, err := c.load()
+	if err != nil {
+		return err
+	}
+	if p == nil || p.Report.ID != settings.ID {
+		p = &pending{Report: Report{
+			ID:     settings.ID,
+			Start:  now.UTC().Truncate(24 * time.Hour),
+			Counts: make(map[string]int),
+		}}
+	}
+	for _, event := range events {
+		if len(event) <= 64 && eventPattern.MatchString(event) {
+			p.Report.Counts[event]++
+		}
+	}
+	return writeJSON(c.ReportPath, p)
+}
+
+// Pending returns the report exactly as it would be sent now, or nil when
+// nothing was counted
+func (c *Collector) Pending() (*Report, error) {
+	p, err := c.load()
+	if err != nil || p == nil {
+		return nil, err
+	}
+	report := p.Report
+	report.Version = c.Version
+	report.OS = runtime.GOOS
+	report.Arch = runtime.GOARCH
+	return &report, nil
+}
+
+// SendDue sends the pending report once it covers SendInterval, deleting
+// it when the endpoint accepted it. It reports whether a report was sent.
+func (c *Collector) SendDue(now time.Time) (bool, error) {
+	if !c.Active() || c.Endpoint == "" {
+		return false, nil
+	}
+	p, err := c.load()
+	if err != nil || p == nil {
+		return false, err
+	}
+	if now.Sub(p.Report.Start) < SendInterval || now.Sub(p.LastAttempt) < retryInterval {
+		return false, nil
+	}
+
+	report, err := c.Pending()
+	if err != nil {
+		return false, err
+	}
+	if err := c.send(report); err != nil {
+		p.LastAttempt = now
+		if werr := writeJSON(c.ReportPath, p); werr != nil {
+			return false, werr
+		}
+		return false, err
+	}
+	if err := os.Remove(c.ReportPath); err != nil && !os.IsNotExist(err) {
+		return true, fmt.Errorf("failed to delete telemetry report: %w", err)
+	}
+	return true, nil
+}
+
+// send posts a report to the endpoint
+func (c *Collector) send(report *Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry report: %w", err)
+	}
+	client := c.client
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	resp, err := client.Post(c.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// load reads the report file, returning nil when there is none
+func (c *Collector) load() (*pending, error) {
+	if c.ReportPath == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.ReportPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read telemetry report: %w", err)
+	}
+	var p pending
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse telemetry report %s: %w", c.ReportPath, err)
+	}
+	if p.Report.Counts == nil {
+		p.Report.Counts = make(map[string]int)
+	}
+	return &p, nil
+}
+
+// writeJSON writes v to path, creating its directory
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Record counts events in the user's report when telemetry is active.
+// Telemetry never fails a command, so errors are ignored.
+func Record(events ...string) {
+	Default("").Record(time.Now(), events...)
+}
+
+// SendDue sends the user's report when it is due, ignoring errors
+func SendDue(version string) {
+	Default(version).SendDue(time.Now())
+}
@@ -0,0 +1,128 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newCollector(t *testing.T, endpoint string) *Collector {
+	t.Helper()
+	t.Setenv("GOKANON_TELEMETRY", "")
+	t.Setenv("DO_NOT_TRACK", "")
+	dir := t.TempDir()
+	return &Collector{
+		SettingsPath: filepath.Join(dir, "config", "telemetry.json"),
+		ReportPath:   filepath.Join(dir, "data", "telemetry.json"),
+		Endpoint:     endpoint,
+		Version:      "1.2.3",
+	}
+}
+
+func TestRecordOnlyWhenOn(t *testing.T) {
+	c := newCollector(t, "")
+	now := time.Date(2026, 3, 1, 15, 4, 5, 0, time.UTC)
+
+	// Off by default
+	if c.Active() {
+		t.Fatal("Telemetry is on without a settings file")
+	}
+	if err := c.Record(now, "command.run"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if report, err := c.Pending(); err != nil || report != nil {
+		t.Fatalf("Pending() = %v, %v while off, want nothing", report, err)
+	}
+
+	settings, err := c.Enable()
+	if err != nil || !settings.Enabled || len(settings.ID) != 32 {
+		t.Fatalf("Enable() = %+v, %v", settings, err)
+	}
+	for _, event := range []string{"command.run", "command.run", "export.html", "export./home/me/secret", "Command.Run"} {
+		if err := c.Record(now, event); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	report, err := c.Pending()
+	if err != nil || report == nil {
+		t.Fatalf("Pending() = %v, %v", report, err)
+	}
+	want := map[string]int{"command.run": 2, "export.html": 1}
+	if len(report.Counts) != len(want) || report.Counts["command.run"] != 2 || report.Counts["export.html"] != 1 {
+		t.Errorf("Counts = %v, want %v", report.Counts, want)
+	}
+	if report.ID != settings.ID || report.Version != "1.2.3" || report.OS == "" {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if !report.Start.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Start = %v, want the day of the first event", report.Start)
+	}
+
+	// The environment wins over the setting
+	t.Setenv("DO_NOT_TRACK", "1")
+	if c.Active() {
+		t.Error("Telemetry is active with DO_NOT_TRACK=1")
+	}
+	t.Setenv("DO_NOT_TRACK", "")
+
+	if err := c.Disable(); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if c.Active() {
+		t.Error("Telemetry is active after Disable")
+	}
+	if report, _ := c.Pending(); report != nil {
+		t.Errorf("Pending counts were kept after Disable: %+v", report)
+	}
+}
+
+func TestSendDue(t *testing.T) {
+	var received []Report
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("Invalid report: %v", err)
+		}
+		received = append(received, report)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	c := newCollector(t, server.URL)
+	if _, err := c.Enable(); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	start := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	if err := c.Record(start, "command.list"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	if sent, err := c.SendDue(start.Add(time.Hour)); sent || err != nil {
+		t.Fatalf("SendDue before the interval = %v, %v, want nothing sent", sent, err)
+	}
+
+	// A failure is retried an hour later, not on the next command
+	status = http.StatusInternalServerError
+	day := start.Add(SendInterval)
+	if sent, err := c.SendDue(day); sent || err == nil {
+		t.Fatalf("SendDue to a failing endpoint = %v, %v, want an error", sent, err)
+	}
+	if sent, _ := c.SendDue(day.Add(time.Minute)); sent || len(received) != 1 {
+		t.Fatalf("Report was sent again %d time(s) right after a failure", len(received)-1)
+	}
+
+	status = http.StatusNoContent
+	if sent, err := c.SendDue(day.Add(time.Hour)); !sent || err != nil {
+		t.Fatalf("SendDue = %v, %v, want the report sent", sent, err)
+	}
+	if got := received[len(received)-1]; got.Counts["command.list"] != 1 || got.Version != "1.2.3" {
+		t.Errorf("Unexpected report sent: %+v", got)
+	}
+	if report, _ := c.Pending(); report != nil {
+		t.Errorf("Report was kept after it was sent: %+v", report)
+	}
+}
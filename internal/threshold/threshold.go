@@ -2,8 +2,12 @@ package threshold
 
 import (
 	"fmt"
+	"maps"
+	"path"
+	"slices"
 
 	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
 )
 
 // Result represents the result of a threshold check
@@ -23,51 +27,188 @@ type Failure struct {
 
 // Checker handles threshold checking for benchmarks
 type Checker struct {
-	maxDegradation float64 // Maximum allowed performance degradation (%)
+	maxDegradation float64            // Maximum allowed performance degradation (%)
+	source         string             // Where maxDegradation comes from
+	failOnRemoved  bool               // Fail when a benchmark is missing from the new run
+	ignoreMemory   bool               // Do not check B/op and allocs/op
+	thresholds     map[string]float64 // Per-benchmark thresholds by name or pattern
+	patterns       []string           // Keys of thresholds in lexical order
+}
+
+// Limit is the threshold applied to a benchmark and where it comes from
+type Limit struct {
+	Percent float64
+	Source  string // e.g. "global (-threshold)" or "per-benchmark (Parse/*)"
 }
 
 // NewChecker creates a new threshold checker
 func NewChecker(maxDegradation float64) *Checker {
 	return &Checker{
 		maxDegradation: maxDegradation,
+		source:         "global",
+	}
+}
+
+// WithSource describes where the global threshold comes from, such as a
+// flag or the configuration, for explanations of the check
+func (c *Checker) WithSource(source string) *Checker {
+	c.source = "global (" + source + ")"
+	return c
+}
+
+// WithBenchmarkThresholds sets thresholds for individual benchmarks that
+// override the global one. Keys are benchmark names without the
+// "Benchmark" prefix and GOMAXPROCS suffix, or path.Match patterns such as
+// "Parse/*"; exact names take precedence, then the first matching pattern
+// in lexical order.
+func (c *Checker) WithBenchmarkThresholds(thresholds map[string]float64) *Checker {
+	c.thresholds = thresholds
+	c.patterns = slices.Sorted(maps.Keys(thresholds))
+	return c
+}
+
+// Limit returns the threshold applied to a benchmark
+func (c *Checker) Limit(name string) Limit {
+	key := stats.BenchmarkKey(name)
+	if percent, ok := c.thresholds[key]; ok {
+		return Limit{Percent: percent, Source: "per-benchmark (" + key + ")"}
+	}
+	for _, pattern := range c.patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return Limit{Percent: c.thresholds[pattern], Source: "per-benchmark (" + pattern + ")"}
+		}
 	}
+	return Limit{Percent: c.maxDegradation, Source: c.source}
 }
 
-// Check checks if the comparisons meet the threshold requirements
+// WithFailOnRemoved makes the check fail for benchmarks that exist in the
+// old run but not in the new one
+func (c *Checker) WithFailOnRemoved(fail bool) *Checker {
+	c.failOnRemoved = fail
+	return c
+}
+
+// WithMemory sets whether B/op and allocs/op regressions fail the check.
+// They do by default, with the same threshold as the timing.
+func (c *Checker) WithMemory(check bool) *Checker {
+	c.ignoreMemory = !check
+	return c
+}
+
+// Check checks if the comparisons meet the threshold requirements.
+// Added benchmarks have no baseline and are not checked; removed benchmarks
+// are only checked when the checker fails on them.
 func (c *Checker) Check(comparisons []models.Comparison) *Result {
 	result := &Result{
-		Passed:       true,
-		TotalChecked: len(comparisons),
+		Passed: true,
 	}
 
 	for _, comp := range comparisons {
-		// Check if performance degraded beyond threshold
-		if comp.DeltaPercent > c.maxDegradation {
+		switch comp.Status {
+		case models.StatusAdded:
+			continue
+		case models.StatusRemoved:
+			if !c.failOnRemoved {
+				continue
+			}
+			result.TotalChecked++
 			result.Passed = false
 			result.Failures = append(result.Failures, Failure{
 				BenchmarkName: comp.Name,
-				DeltaPercent:  comp.DeltaPercent,
 				Threshold:     c.maxDegradation,
+				Message:       "Benchmark was removed",
+			})
+			continue
+		}
+		result.TotalChecked++
+		limit := c.Limit(comp.Name).Percent
+
+		// A benchmark that no longer passes cannot meet any threshold
+		if comp.Status == models.StatusFailed {
+			message := "Benchmark failed"
+			if comp.Message != "" {
+				message += ": " + comp.Message
+			}
+			result.Passed = false
+			result.Failures = append(result.Failures, Failure{
+				BenchmarkName: comp.Name,
+				Threshold:     limit,
+				Message:       message,
+			})
+			continue
+		}
+
+		// Check if performance degraded beyond threshold. Differences
+		// between samples that may be noise are not regressions.
+		var failure *Failure
+		if comp.DeltaPercent > limit && comp.Significant() {
+			failure = &Failure{
+				BenchmarkName: comp.Name,
+				DeltaPercent:  comp.DeltaPercent,
+				Threshold:     limit,
 				Message: fmt.Sprintf(
 					"Performance degraded by %.2f%% (threshold: %.2f%%)",
 					comp.DeltaPercent,
-					c.maxDegradation,
+					limit,
 				),
-			})
+			}
+		}
+
+		// Memory is checked on its own, so that stable timing does not
+		// hide a memory regression
+		if !c.ignoreMemory {
+			for _, metric := range []*models.MetricComparison{comp.BytesPerOp, comp.AllocsPerOp} {
+				message, ok := memoryRegression(metric, limit)
+				if !ok {
+					continue
+				}
+				if failure == nil {
+					failure = &Failure{BenchmarkName: comp.Name, DeltaPercent: metric.DeltaPercent, Threshold: limit, Message: message}
+				} else {
+					failure.Message += "; " + message
+				}
+			}
+		}
+
+		if failure != nil {
+			result.Passed = false
+			result.Failures = append(result.Failures, *failure)
 		}
 	}
 
 	return result
 }
 
+// memoryRegression describes a standard memory metric that grew beyond
+// the threshold. A benchmark starting to allocate always fails.
+func memoryRegression(metric *models.MetricComparison, limit float64) (string, bool) {
+	switch {
+	case metric == nil || metric.Delta <= 0:
+		return "", false
+	case metric.Old == 0:
+		return fmt.Sprintf("%s rose from 0 to %.0f", metric.Name, metric.New), true
+	case metric.DeltaPercent > limit:
+		return fmt.Sprintf("%s degraded by %.2f%% (threshold: %.2f%%)", metric.Name, metric.DeltaPercent, limit), true
+	}
+	return "", false
+}
+
+// FormatVerdict formats the outcome of the threshold check in one line
+func FormatVerdict(result *Result) string {
+	if result.Passed {
+		return fmt.Sprintf("✓ All %d benchmarks passed the threshold check", result.TotalChecked)
+	}
+	return fmt.Sprintf("✗ %d/%d benchmarks failed the threshold check",
+		len(result.Failures), result.TotalChecked)
+}
+
 // FormatResult formats the threshold check result for display
 func FormatResult(result *Result) string {
 	if result.Passed {
-		return fmt.Sprintf("✓ All %d benchmarks passed the threshold check", result.TotalChecked)
+		return FormatVerdict(result)
 	}
 
-	output := fmt.Sprintf("✗ %d/%d benchmarks failed the threshold check:\n\n",
-		len(result.Failures), result.TotalChecked)
+	output := FormatVerdict(result) + ":\n\n"
 
 	for _, failure := range result.Failures {
 		output += fmt.Sprintf("  • %s: %s\n", failure.BenchmarkName, failure.Message)
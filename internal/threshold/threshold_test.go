@@ -239,3 +239,142 @@ func TestCheckBoundaryValues(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckFailedBenchmark(t *testing.T) {
+	checker := NewChecker(10.0)
+
+	comparisons := []models.Comparison{
+		{Name: "BenchmarkA", DeltaPercent: 2.0, Status: "same"},
+		{Name: "BenchmarkB", Status: models.StatusFailed, Message: "assertion failed"},
+		{Name: "BenchmarkC", Status: models.StatusSkipped},
+	}
+
+	result := checker.Check(comparisons)
+	if result.Passed {
+		t.Fatal("Expected check to fail when a benchmark failed")
+	}
+	if len(result.Failures) != 1 || result.Failures[0].BenchmarkName != "BenchmarkB" {
+		t.Fatalf("Expected a single failure for BenchmarkB, got %+v", result.Failures)
+	}
+	if !strings.Contains(result.Failures[0].Message, "assertion failed") {
+		t.Errorf("Expected failure message to include the cause, got %q", result.Failures[0].Message)
+	}
+}
+
+func TestCheckRemovedBenchmarks(t *testing.T) {
+	comparisons := []models.Comparison{
+		{Name: "BenchmarkA", DeltaPercent: 2.0, Status: "same"},
+		{Name: "BenchmarkB", Status: models.StatusAdded},
+		{Name: "BenchmarkC", Status: models.StatusRemoved},
+	}
+
+	tests := []struct {
+		name          string
+		failOnRemoved bool
+		wantPassed    bool
+		wantChecked   int
+	}{
+		{"removed ignored by default", false, true, 1},
+		{"fail on removed", true, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewChecker(10.0).WithFailOnRemoved(tt.failOnRemoved).Check(comparisons)
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+			if result.TotalChecked != tt.wantChecked {
+				t.Errorf("TotalChecked = %d, want %d", result.TotalChecked, tt.wantChecked)
+			}
+			if !tt.wantPassed && (len(result.Failures) != 1 || result.Failures[0].BenchmarkName != "BenchmarkC") {
+				t.Errorf("Expected a single failure for BenchmarkC, got %+v", result.Failures)
+			}
+		})
+	}
+}
+
+func TestCheckInsignificantDegradation(t *testing.T) {
+	noise, significant := 0.4, 0.01
+	comparisons := []models.Comparison{
+		{Name: "BenchmarkNoisy", DeltaPercent: 25.0, Status: "same", PValue: &noise},
+		{Name: "BenchmarkSlower", DeltaPercent: 25.0, Status: "degraded", PValue: &significant},
+	}
+
+	result := NewChecker(10.0).Check(comparisons)
+	if result.Passed {
+		t.Fatal("Expected check to fail for the significant degradation")
+	}
+	if len(result.Failures) != 1 || result.Failures[0].BenchmarkName != "BenchmarkSlower" {
+		t.Errorf("Expected a single failure for BenchmarkSlower, got %+v", result.Failures)
+	}
+}
+
+func TestCheckMemoryRegression(t *testing.T) {
+	comparisons := []models.Comparison{
+		{
+			Name: "BenchmarkParse", DeltaPercent: 1.0, Status: "same",
+			BytesPerOp:  &models.MetricComparison{Name: "B/op", Old: 100, New: 150, Delta: 50, DeltaPercent: 50, Status: "degraded"},
+			AllocsPerOp: &models.MetricComparison{Name: "allocs/op", Old: 2, New: 2, Status: "same"},
+		},
+		{
+			Name: "BenchmarkHash", DeltaPercent: 20.0, Status: "degraded",
+			AllocsPerOp: &models.MetricComparison{Name: "allocs/op", Old: 0, New: 1, Delta: 1, Status: "degraded"},
+		},
+		{
+			Name: "BenchmarkEncode", DeltaPercent: 0, Status: "same",
+			BytesPerOp: &models.MetricComparison{Name: "B/op", Old: 100, New: 105, Delta: 5, DeltaPercent: 5, Status: "same"},
+		},
+	}
+
+	result := NewChecker(10.0).Check(comparisons)
+	if len(result.Failures) != 2 {
+		t.Fatalf("Expected 2 failures, got %+v", result.Failures)
+	}
+	if msg := result.Failures[0].Message; msg != "B/op degraded by 50.00% (threshold: 10.00%)" {
+		t.Errorf("Unexpected message for Parse: %s", msg)
+	}
+	// One failure per benchmark, listing each regression
+	if msg := result.Failures[1].Message; !strings.Contains(msg, "Performance degraded by 20.00%") || !strings.Contains(msg, "allocs/op rose from 0 to 1") {
+		t.Errorf("Unexpected message for Hash: %s", msg)
+	}
+
+	result = NewChecker(10.0).WithMemory(false).Check(comparisons)
+	if len(result.Failures) != 1 || result.Failures[0].BenchmarkName != "BenchmarkHash" {
+		t.Errorf("Expected only the timing regression without memory checks, got %+v", result.Failures)
+	}
+}
+
+func TestBenchmarkThresholds(t *testing.T) {
+	c := NewChecker(10.0).WithSource("-threshold").WithBenchmarkThresholds(map[string]float64{
+		"Parse":   2.0,
+		"Encode*": 30.0,
+	})
+
+	tests := []struct {
+		name        string
+		wantPercent float64
+		wantSource  string
+	}{
+		{"BenchmarkParse-8", 2.0, "per-benchmark (Parse)"},
+		{"EncodeJSON", 30.0, "per-benchmark (Encode*)"},
+		{"Decode", 10.0, "global (-threshold)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := c.Limit(tt.name)
+			if limit.Percent != tt.wantPercent || limit.Source != tt.wantSource {
+				t.Errorf("Limit(%q) = %+v, want %.1f%% from %s", tt.name, limit, tt.wantPercent, tt.wantSource)
+			}
+		})
+	}
+
+	result := c.Check([]models.Comparison{
+		{Name: "Parse", DeltaPercent: 5.0, Status: "degraded"},
+		{Name: "EncodeJSON", DeltaPercent: 20.0, Status: "degraded"},
+		{Name: "Decode", DeltaPercent: 5.0, Status: "degraded"},
+	})
+	if len(result.Failures) != 1 || result.Failures[0].BenchmarkName != "Parse" {
+		t.Errorf("Expected a single failure for Parse, got %+v", result.Failures)
+	}
+}
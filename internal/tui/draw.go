@@ -0,0 +1,67 @@
+package tui
+
+import "github.com/gdamore/tcell/v2"
+
+// help lists the keys on the last line of the screen
+const help = "↑/↓ select · t trends · c compare · m mark for comparison · pgup/pgdn scroll · q quit"
+
+// Draw draws the UI on a screen: a title line, the run list and the
+// detail pane side by side, and the keys
+func (m *Model) Draw(s tcell.Screen) {
+	s.Clear()
+	if len(m.runs) == 0 {
+		drawLine(s, 0, 0, m.width, text(plainStyle, "No benchmark runs found. Run 'gokanon run' first."))
+		drawLine(s, 0, 2, m.width, text(plainStyle, "Press q to quit."))
+		return
+	}
+
+	drawLine(s, 0, 0, m.width, text(titleStyle, "gokanon — %d runs", len(m.runs)))
+
+	listWidth, detailWidth, height := m.listWidth(), m.detailWidth(), m.paneHeight()
+	drawBox(s, 0, 1, listWidth+2, height+2)
+	for i, row := range m.runList() {
+		drawLine(s, 1, 2+i, listWidth, row)
+	}
+
+	x := listWidth + 2
+	drawBox(s, x, 1, detailWidth+2, height+2)
+	lines := m.detail()
+	for i := m.scroll; i < len(lines) && i < m.scroll+height; i++ {
+		drawLine(s, x+1, 2+i-m.scroll, detailWidth, lines[i])
+	}
+
+	drawLine(s, 0, height+3, m.width, text(dimStyle, "%s", help))
+}
+
+// drawLine draws a line at x, y, cut at width columns with an ellipsis
+func drawLine(s tcell.Screen, x, y, width int, l line) {
+	long := len([]rune(l.String())) > width
+	col := 0
+	for _, sp := range l {
+		for _, r := range sp.text {
+			if long && col == width-1 {
+				s.SetContent(x+col, y, '…', nil, sp.style)
+				return
+			}
+			s.SetContent(x+col, y, r, nil, sp.style)
+			col++
+		}
+	}
+}
+
+// drawBox draws a rounded border of width columns and height rows at x, y
+func drawBox(s tcell.Screen, x, y, width, height int) {
+	right, bottom := x+width-1, y+height-1
+	for col := x + 1; col < right; col++ {
+		s.SetContent(col, y, '─', nil, borderStyle)
+		s.SetContent(col, bottom, '─', nil, borderStyle)
+	}
+	for row := y + 1; row < bottom; row++ {
+		s.SetContent(x, row, '│', nil, borderStyle)
+		s.SetContent(right, row, '│', nil, borderStyle)
+	}
+	s.SetContent(x, y, '╭', nil, borderStyle)
+	s.SetContent(right, y, '╮', nil, borderStyle)
+	s.SetContent(x, bottom, '╰', nil, borderStyle)
+	s.SetContent(right, bottom, '╯', nil, borderStyle)
+}
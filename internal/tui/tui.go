@@ -0,0 +1,201 @@
+// Package tui is the full-screen terminal UI of 'gokanon tui': a
+// navigable list of runs next to the trends of the selected run's
+// benchmarks or its comparison with another run
+package tui
+
+import (
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/ui"
+	"github.com/gdamore/tcell/v2"
+)
+
+// view is what the pane next to the run list shows
+type view int
+
+const (
+	viewTrends view = iota
+	viewCompare
+)
+
+// Options configure the terminal UI
+type Options struct {
+	Comparer   *compare.Comparer // Matching rules of comparisons (default rules when nil)
+	TimeFormat *ui.TimeFormat    // How run timestamps are shown (default format when nil)
+	Window     int               // Runs a trend covers, ending with the selected one (10 when 0)
+}
+
+// Model is the state of the terminal UI
+type Model struct {
+	runs   []models.BenchmarkRun // Newest first, as storage lists them
+	opts   Options
+	view   view
+	cursor int // Selected run
+	offset int // First run shown in the list
+	marked int // Run the selected one is compared with, -1 for the run before it
+	scroll int // First line of the detail pane shown
+
+	width, height int
+}
+
+// Default size until the terminal reports its own
+const (
+	defaultWidth  = 100
+	defaultHeight = 30
+)
+
+// New returns the UI for runs listed newest first
+func New(runs []models.BenchmarkRun, opts Options) *Model {
+	if opts.Comparer == nil {
+		opts.Comparer = compare.NewComparer()
+	}
+	if opts.TimeFormat == nil {
+		opts.TimeFormat = ui.DefaultTimeFormat()
+	}
+	if opts.Window <= 0 {
+		opts.Window = 10
+	}
+	return &Model{runs: runs, opts: opts, marked: -1, width: defaultWidth, height: defaultHeight}
+}
+
+// Run shows the UI full-screen until the user quits
+func Run(runs []models.BenchmarkRun, opts Options) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	m := New(runs, opts)
+	m.Resize(screen.Size())
+	for {
+		m.Draw(screen)
+		screen.Show()
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			m.Resize(ev.Size())
+			screen.Sync()
+		case *tcell.EventKey:
+			if !m.HandleKey(ev) {
+				return nil
+			}
+		case nil:
+			// The screen was finalized
+			return nil
+		}
+	}
+}
+
+// Resize adapts the layout to a terminal of width columns and height rows
+func (m *Model) Resize(width, height int) {
+	m.width, m.height = width, height
+	m.follow()
+}
+
+// HandleKey applies a key press. It returns false when the user quits.
+func (m *Model) HandleKey(ev *tcell.EventKey) bool {
+	switch keyName(ev) {
+	case "q", "esc", "ctrl+c":
+		return false
+	case "up", "k":
+		m.selectRun(m.cursor - 1)
+	case "down", "j":
+		m.selectRun(m.cursor + 1)
+	case "home", "g":
+		m.selectRun(0)
+	case "end", "G":
+		m.selectRun(len(m.runs) - 1)
+	case "pgdown", "ctrl+d", " ":
+		m.scroll = min(m.scroll+m.paneHeight()/2, max(len(m.detail())-m.paneHeight(), 0))
+	case "pgup", "ctrl+u":
+		m.scroll = max(m.scroll-m.paneHeight()/2, 0)
+	case "t":
+		m.view, m.scroll = viewTrends, 0
+	case "c":
+		m.view, m.scroll = viewCompare, 0
+	case "tab":
+		m.view, m.scroll = 1-m.view, 0
+	case "m":
+		if m.marked == m.cursor {
+			m.marked = -1
+		} else {
+			m.marked = m.cursor
+		}
+		if m.view == viewCompare {
+			m.scroll = 0
+		}
+	}
+	return true
+}
+
+// keyName returns the name of a key press, such as "up", "ctrl+c" or the
+// character typed
+func keyName(ev *tcell.EventKey) string {
+	switch ev.Key() {
+	case tcell.KeyRune:
+		return string(ev.Rune())
+	case tcell.KeyUp:
+		return "up"
+	case tcell.KeyDown:
+		return "down"
+	case tcell.KeyHome:
+		return "home"
+	case tcell.KeyEnd:
+		return "end"
+	case tcell.KeyPgUp:
+		return "pgup"
+	case tcell.KeyPgDn:
+		return "pgdown"
+	case tcell.KeyTab:
+		return "tab"
+	case tcell.KeyEscape:
+		return "esc"
+	case tcell.KeyCtrlC:
+		return "ctrl+c"
+	case tcell.KeyCtrlD:
+		return "ctrl+d"
+	case tcell.KeyCtrlU:
+		return "ctrl+u"
+	}
+	return ""
+}
+
+// selectRun selects the run at i, keeping it in the list
+func (m *Model) selectRun(i int) {
+	if len(m.runs) == 0 {
+		return
+	}
+	m.cursor = max(0, min(i, len(m.runs)-1))
+	m.scroll = 0
+	m.follow()
+}
+
+// follow scrolls the list to the selected run
+func (m *Model) follow() {
+	rows := m.paneHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// paneHeight returns the number of lines inside the panes, below the
+// title line and above the help line
+func (m *Model) paneHeight() int {
+	return max(m.height-4, 1)
+}
+
+// listWidth returns the width inside the run list pane
+func (m *Model) listWidth() int {
+	return max(min(40, m.width/3), 20)
+}
+
+// detailWidth returns the width inside the detail pane
+func (m *Model) detailWidth() int {
+	return max(m.width-m.listWidth()-4, 20)
+}
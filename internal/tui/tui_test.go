@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/gdamore/tcell/v2"
+)
+
+// testRuns returns three runs, newest first, in which Parse gets slower
+func testRuns() []models.BenchmarkRun {
+	now := time.Now()
+	var runs []models.BenchmarkRun
+	for i, ns := range []float64{300, 200, 100} {
+		runs = append(runs, models.BenchmarkRun{
+			ID:        []string{"run-c", "run-b", "run-a"}[i],
+			Timestamp: now.Add(-time.Duration(i) * time.Hour),
+			Results: []models.BenchmarkResult{
+				{Name: "Parse", NsPerOp: ns},
+				{Name: "Encode", NsPerOp: 50},
+			},
+		})
+	}
+	return runs
+}
+
+// press sends keys to a model: names of special keys or characters
+func press(t *testing.T, m *Model, keys ...string) {
+	t.Helper()
+	special := map[string]tcell.Key{"up": tcell.KeyUp, "down": tcell.KeyDown, "tab": tcell.KeyTab}
+	for _, key := range keys {
+		ev := tcell.NewEventKey(tcell.KeyRune, []rune(key)[0], tcell.ModNone)
+		if k, ok := special[key]; ok {
+			ev = tcell.NewEventKey(k, 0, tcell.ModNone)
+		}
+		if !m.HandleKey(ev) {
+			t.Fatalf("%s quit the UI", key)
+		}
+	}
+}
+
+// render draws a model on a simulated screen of width columns and height
+// rows and returns the text on it
+func render(t *testing.T, m *Model, width, height int) string {
+	t.Helper()
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Fini()
+	s.SetSize(width, height)
+	m.Resize(width, height)
+	m.Draw(s)
+	s.Show()
+
+	cells, w, _ := s.GetContents()
+	var sb strings.Builder
+	for i, cell := range cells {
+		if len(cell.Runes) > 0 {
+			sb.WriteRune(cell.Runes[0])
+		} else {
+			sb.WriteByte(' ')
+		}
+		if (i+1)%w == 0 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+func TestTrends(t *testing.T) {
+	m := New(testRuns(), Options{})
+
+	screen := render(t, m, 120, 20)
+	if !strings.Contains(screen, "3 runs up to run-c") {
+		t.Errorf("Expected the trend of the 3 runs up to the newest:\n%s", screen)
+	}
+	if !strings.Contains(screen, "▁▅█") || !strings.Contains(screen, "300.00 ns/op") || !strings.Contains(screen, "+200.00%") {
+		t.Errorf("Expected Parse to trend up:\n%s", screen)
+	}
+
+	// Trends end with the selected run
+	press(t, m, "down")
+	if screen := render(t, m, 120, 20); !strings.Contains(screen, "2 runs up to run-b") {
+		t.Errorf("Expected the trend of the 2 runs up to run-b:\n%s", screen)
+	}
+}
+
+func TestComparison(t *testing.T) {
+	m := New(testRuns(), Options{})
+
+	// The selected run is compared with the run before it
+	press(t, m, "c")
+	screen := render(t, m, 120, 20)
+	if !strings.Contains(screen, "run-b") || !strings.Contains(screen, "200.00 ns/op") || !strings.Contains(screen, "+50.00%") {
+		t.Errorf("Expected run-c compared with run-b:\n%s", screen)
+	}
+
+	// Or with the marked run
+	press(t, m, "down", "down", "m", "up", "up")
+	if screen := render(t, m, 120, 20); !strings.Contains(screen, "run-a (") || !strings.Contains(screen, "+200.00%") {
+		t.Errorf("Expected run-c compared with the marked run-a:\n%s", screen)
+	}
+
+	// The older of the two runs is the baseline
+	press(t, m, "m", "down", "down")
+	if screen := render(t, m, 120, 20); !strings.Contains(screen, "run-a (") || !strings.Contains(screen, "→ run-c (") {
+		t.Errorf("Expected the marked run-c to be compared with the older run-a:\n%s", screen)
+	}
+
+	// Unmarked, the oldest run has nothing to compare with
+	press(t, m, "g", "m", "G")
+	if screen := render(t, m, 120, 20); !strings.Contains(screen, "nothing to compare with") {
+		t.Errorf("Expected no comparison for the oldest run:\n%s", screen)
+	}
+
+	if m.HandleKey(tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone)) {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestRunListScrolls(t *testing.T) {
+	var runs []models.BenchmarkRun
+	for i := range 30 {
+		runs = append(runs, models.BenchmarkRun{ID: "run-" + string(rune('A'+i)), Timestamp: time.Now()})
+	}
+	m := New(runs, Options{})
+	m.Resize(100, 10)
+
+	press(t, m, "G")
+	screen := render(t, m, 100, 10)
+	if !strings.Contains(screen, "run-"+string(rune('A'+29))) || strings.Contains(screen, "run-A ") {
+		t.Errorf("Expected the list to scroll to the last run:\n%s", screen)
+	}
+}
+
+func TestLongLinesAreCut(t *testing.T) {
+	runs := testRuns()
+	runs[0].Results[0].Name = "Parse/" + strings.Repeat("x", 200)
+	m := New(runs, Options{})
+
+	screen := render(t, m, 80, 12)
+	// The right border of the detail pane is intact
+	rows := strings.Split(strings.TrimRight(screen, "\n"), "\n")
+	for _, row := range rows[1 : len(rows)-1] {
+		if runes := []rune(row); !strings.ContainsRune("╮│╯", runes[len(runes)-1]) {
+			t.Errorf("Row without the right border: %q", row)
+		}
+	}
+	if !strings.Contains(screen, "x…") {
+		t.Errorf("Expected the long name to be cut:\n%s", screen)
+	}
+}
@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/stats"
+	"github.com/alenon/gokanon/internal/ui"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Styles of the panes and values
+var (
+	plainStyle    = tcell.StyleDefault
+	titleStyle    = tcell.StyleDefault.Bold(true)
+	selectedStyle = tcell.StyleDefault.Reverse(true)
+	dimStyle      = tcell.StyleDefault.Dim(true)
+	borderStyle   = tcell.StyleDefault.Foreground(tcell.ColorGray)
+	worseStyle    = tcell.StyleDefault.Foreground(tcell.ColorRed)
+	betterStyle   = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+)
+
+// span is a piece of text drawn in one style
+type span struct {
+	text  string
+	style tcell.Style
+}
+
+// line is a line of text made of spans
+type line []span
+
+// text returns a line in a single style
+func text(style tcell.Style, format string, args ...any) line {
+	return line{{fmt.Sprintf(format, args...), style}}
+}
+
+// String returns the text of the line without styles
+func (l line) String() string {
+	var sb strings.Builder
+	for _, s := range l {
+		sb.WriteString(s.text)
+	}
+	return sb.String()
+}
+
+// runList returns the visible rows of the run list. The marked run is
+// starred and failed runs are crossed.
+func (m *Model) runList() []line {
+	width := m.listWidth()
+	var rows []line
+	for i := m.offset; i < len(m.runs) && i < m.offset+m.paneHeight(); i++ {
+		run := m.runs[i]
+		flag := " "
+		switch {
+		case i == m.marked:
+			flag = "*"
+		case run.Failed():
+			flag = "✗"
+		}
+		when := m.opts.TimeFormat.Format(run.Timestamp)
+		idWidth := max(width-len([]rune(when))-3, 8)
+		row := truncate(fmt.Sprintf("%s %-*s %s", flag, idWidth, truncate(run.ID, idWidth), when), width)
+		style := plainStyle
+		if i == m.cursor {
+			row, style = fmt.Sprintf("%-*s", width, row), selectedStyle
+		}
+		rows = append(rows, text(style, "%s", row))
+	}
+	return rows
+}
+
+// detail returns the lines of the pane next to the run list
+func (m *Model) detail() []line {
+	if m.view == viewCompare {
+		return m.comparison()
+	}
+	return m.trends()
+}
+
+// trends returns a sparkline of the ns/op of each benchmark of the
+// selected run over the runs of the window ending with it
+func (m *Model) trends() []line {
+	// Chronological order, oldest first
+	window := slices.Clone(m.runs[m.cursor:min(m.cursor+m.opts.Window, len(m.runs))])
+	slices.Reverse(window)
+	selected := &m.runs[m.cursor]
+
+	lines := []line{
+		text(titleStyle, "Trends"),
+		text(dimStyle, "%d runs up to %s", len(window), selected.ID),
+		nil,
+	}
+	names := benchmarkNames(selected)
+	if len(names) == 0 {
+		return append(lines, text(plainStyle, "No measured benchmarks in this run."))
+	}
+
+	nameWidth := columnWidth(names, m.detailWidth()-len(window)-30)
+	for _, name := range names {
+		values := trendValues(window, name)
+		if len(values) == 0 {
+			continue
+		}
+		latest := values[len(values)-1]
+		l := text(plainStyle, "%-*s  %-*s  %14s  ",
+			nameWidth, truncate(name, nameWidth), len(window), ui.Sparkline(values), formatNs(latest))
+		if len(values) > 1 && values[0] > 0 {
+			l = append(l, delta((latest-values[0])/values[0]*100))
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// comparison returns the benchmarks of the selected run side by side with
+// those of the marked run, or of the run before it. The older of the two
+// runs is the baseline.
+func (m *Model) comparison() []line {
+	lines := []line{text(titleStyle, "Comparison")}
+	oldIndex := m.marked
+	if oldIndex < 0 || oldIndex == m.cursor {
+		oldIndex = m.cursor + 1
+	}
+	if oldIndex >= len(m.runs) {
+		return append(lines, nil, text(plainStyle, "The oldest run has nothing to compare with. Mark another run with m."))
+	}
+	oldRun, newRun := &m.runs[oldIndex], &m.runs[m.cursor]
+	if oldIndex < m.cursor {
+		// The marked run is newer than the selected one
+		oldRun, newRun = newRun, oldRun
+	}
+	lines = append(lines,
+		text(dimStyle, "%s (%s) → %s (%s)",
+			oldRun.ID, m.opts.TimeFormat.Format(oldRun.Timestamp), newRun.ID, m.opts.TimeFormat.Format(newRun.Timestamp)),
+		nil)
+
+	comparisons := m.opts.Comparer.Compare(oldRun, newRun)
+	if len(comparisons) == 0 {
+		return append(lines, text(plainStyle, "No benchmarks in the two runs."))
+	}
+	names := make([]string, len(comparisons))
+	for i, comp := range comparisons {
+		names[i] = comp.Name
+	}
+	nameWidth := columnWidth(names, m.detailWidth()-44)
+	lines = append(lines, text(titleStyle, "%-*s  %14s  %14s  %s", nameWidth, "Benchmark", "Old", "New", "Delta"))
+	for _, comp := range comparisons {
+		oldValue, newValue := "-", "-"
+		if comp.OldNsPerOp > 0 {
+			oldValue = formatNs(comp.OldNsPerOp)
+		}
+		if comp.NewNsPerOp > 0 {
+			newValue = formatNs(comp.NewNsPerOp)
+		}
+		l := text(plainStyle, "%-*s  %14s  %14s  ", nameWidth, truncate(comp.Name, nameWidth), oldValue, newValue)
+		switch comp.Status {
+		case models.StatusAdded, models.StatusRemoved, models.StatusFailed:
+			l = append(l, span{comp.Status, dimStyle})
+		default:
+			l = append(l, delta(comp.DeltaPercent))
+		}
+		lines = append(lines, l)
+	}
+	return append(lines, nil, text(plainStyle, "%s", compare.Summary(comparisons)))
+}
+
+// benchmarkNames returns the names of the measured benchmarks of a run,
+// the variants of a benchmark together
+func benchmarkNames(run *models.BenchmarkRun) []string {
+	var results []models.BenchmarkResult
+	for _, result := range run.Results {
+		if result.Measured() {
+			results = append(results, result)
+		}
+	}
+	slices.SortStableFunc(results, func(a, b models.BenchmarkResult) int {
+		return cmp.Or(strings.Compare(a.Family(), b.Family()), strings.Compare(a.Name, b.Name))
+	})
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.Name
+	}
+	return slices.Compact(names)
+}
+
+// trendValues returns the ns/op of a benchmark in each run that measured it
+func trendValues(runs []models.BenchmarkRun, name string) []float64 {
+	var values []float64
+	for _, run := range runs {
+		for _, result := range run.Results {
+			if result.Name == name && result.Measured() {
+				if v, ok := stats.MetricValue(result, "ns/op"); ok {
+					values = append(values, v)
+				}
+				break
+			}
+		}
+	}
+	return values
+}
+
+// columnWidth returns the width of a column of names, at most limit and at
+// least 10
+func columnWidth(names []string, limit int) int {
+	width := 0
+	for _, name := range names {
+		width = max(width, len([]rune(name)))
+	}
+	return max(min(width, limit), 10)
+}
+
+// formatNs formats a duration per operation in nanoseconds
+func formatNs(ns float64) string {
+	return fmt.Sprintf("%.2f ns/op", ns)
+}
+
+// delta formats a change of ns/op, colored by direction
+func delta(percent float64) span {
+	text := fmt.Sprintf("%+.2f%%", percent)
+	switch {
+	case percent > 0:
+		return span{text, worseStyle}
+	case percent < 0:
+		return span{text, betterStyle}
+	}
+	return span{text, dimStyle}
+}
+
+// truncate shortens s to width runes, ending it with an ellipsis
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-1]) + "…"
+}
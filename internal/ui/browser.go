@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// startCommand starts a command without waiting for it, replaced in tests
+var startCommand = func(name string, args ...string) error {
+	return exec.Command(name, args...).Start()
+}
+
+// OpenBrowser opens a URL or file with the platform's default browser or
+// viewer, without waiting for it to close
+func OpenBrowser(target string) error {
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		err = startCommand("open", target)
+	case "windows":
+		err = startCommand("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		err = startCommand("xdg-open", target)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOpenBrowser(t *testing.T) {
+	var started []string
+	old := startCommand
+	startCommand = func(name string, args ...string) error {
+		started = append([]string{name}, args...)
+		return nil
+	}
+	defer func() { startCommand = old }()
+
+	if err := OpenBrowser("http://localhost:8080"); err != nil {
+		t.Fatalf("OpenBrowser failed: %v", err)
+	}
+	want := map[string]string{"darwin": "open", "windows": "rundll32"}[runtime.GOOS]
+	if want == "" {
+		want = "xdg-open"
+	}
+	if len(started) == 0 || started[0] != want || started[len(started)-1] != "http://localhost:8080" {
+		t.Errorf("Started %v, want %s with the URL", started, want)
+	}
+
+	startCommand = func(string, ...string) error { return errors.New("executable file not found") }
+	if err := OpenBrowser("report.html"); err == nil || !strings.Contains(err.Error(), "report.html") {
+		t.Errorf("Expected the failure to name the target, got %v", err)
+	}
+}
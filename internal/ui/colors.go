@@ -94,6 +94,22 @@ func FormatChange(percentChange float64) string {
 	return Dim(fmt.Sprintf("%.2f%%", percentChange))
 }
 
+// FormatGrade formats a confidence grade with a color from green (A) to
+// red (D), or a dash for an ungraded result
+func FormatGrade(grade string) string {
+	switch grade {
+	case "A":
+		return Success(grade)
+	case "B":
+		return Info(grade)
+	case "C":
+		return Warning(grade)
+	case "D":
+		return Error(grade)
+	}
+	return Dim("-")
+}
+
 // FormatDuration formats a duration with color based on magnitude
 func FormatDuration(ns float64) string {
 	if ns < 1000 {
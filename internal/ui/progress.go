@@ -87,8 +87,8 @@ type Spinner struct {
 	mu          sync.RWMutex // Protects message field
 }
 
-// isCI checks if we're running in a CI environment
-func isCI() bool {
+// InCI reports whether gokanon runs in a CI environment
+func InCI() bool {
 	// Check common CI environment variables
 	ciVars := []string{
 		"CI",
@@ -129,7 +129,7 @@ func (s *Spinner) Start() {
 	s.isRunning = true
 
 	// In CI environments, just print once without spinning
-	if isCI() {
+	if InCI() {
 		s.mu.RLock()
 		msg := s.message
 		s.mu.RUnlock()
@@ -170,7 +170,7 @@ func (s *Spinner) Stop() {
 	}
 
 	// In CI, we just printed once, so nothing to clean up
-	if isCI() {
+	if InCI() {
 		s.isRunning = false
 		return
 	}
@@ -0,0 +1,27 @@
+package ui
+
+// sparkBlocks are the levels of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a line of unicode block characters scaled
+// between their minimum and maximum. Constant values render mid-height.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := len(sparkBlocks) / 2
+		if hi > lo {
+			level = int((v-lo)/(hi-lo)*float64(len(sparkBlocks)-1) + 0.5)
+		}
+		line[i] = sparkBlocks[level]
+	}
+	return string(line)
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayout is how timestamps are displayed unless a time format
+// is given
+const DefaultTimeLayout = "2006-01-02 15:04:05"
+
+// timePresets are the named time formats accepted besides Go layouts
+var timePresets = map[string]string{
+	"default":  DefaultTimeLayout,
+	"datetime": time.DateTime,
+	"date":     time.DateOnly,
+	"rfc3339":  time.RFC3339,
+	"rfc1123":  time.RFC1123,
+	"kitchen":  time.Kitchen,
+}
+
+// TimeFormat controls how timestamps are displayed: the layout and the
+// time zone they are converted to
+type TimeFormat struct {
+	layout   string         // Go layout, or "unix" or "relative"
+	location *time.Location // nil keeps the zone a timestamp was recorded in
+	now      func() time.Time
+}
+
+// DefaultTimeFormat displays timestamps with DefaultTimeLayout in the zone
+// they were recorded in
+func DefaultTimeFormat() *TimeFormat {
+	return &TimeFormat{layout: DefaultTimeLayout, now: time.Now}
+}
+
+// ParseTimeFormat parses the -time-format and -tz options. format is a
+// preset (default, datetime, date, rfc3339, rfc1123, kitchen, unix or
+// relative) or a Go time layout such as "02 Jan 15:04". tz is a zone as
+// accepted by ParseTimeZone.
+func ParseTimeFormat(format, tz string) (*TimeFormat, error) {
+	f := DefaultTimeFormat()
+
+	switch preset := strings.ToLower(format); {
+	case format == "":
+	case preset == "unix" || preset == "relative":
+		f.layout = preset
+	case timePresets[preset] != "":
+		f.layout = timePresets[preset]
+	case strings.ContainsAny(format, "0123456789"):
+		// Every Go layout contains a reference value such as 2006 or 15
+		f.layout = format
+	default:
+		return nil, fmt.Errorf("invalid time format %q: use default, datetime, date, rfc3339, rfc1123, kitchen, unix, relative or a Go layout like 2006-01-02 15:04", format)
+	}
+
+	location, err := ParseTimeZone(tz)
+	if err != nil {
+		return nil, err
+	}
+	f.location = location
+	return f, nil
+}
+
+// ParseTimeZone parses a time zone option: "local" for the zone of this
+// machine, "UTC" or an IANA name such as "Europe/Berlin". An empty zone
+// returns nil, keeping the zone timestamps were recorded in.
+func ParseTimeZone(tz string) (*time.Location, error) {
+	switch strings.ToLower(tz) {
+	case "":
+		return nil, nil
+	case "local":
+		return time.Local, nil
+	case "utc", "z":
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: use local, UTC or an IANA name like Europe/Berlin", tz)
+	}
+	return location, nil
+}
+
+// Format formats a timestamp for display
+func (f *TimeFormat) Format(t time.Time) string {
+	if f.location != nil {
+		t = t.In(f.location)
+	}
+	switch f.layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "relative":
+		return relativeTime(t, f.now())
+	}
+	return t.Format(f.layout)
+}
+
+// relativeTime formats t relative to now, such as "5m ago". Times more than
+// a month away are shown as dates.
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	suffix := " ago"
+	if d < 0 {
+		d, suffix = -d, " from now"
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%s", int(d/time.Minute), suffix)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%s", int(d/time.Hour), suffix)
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd%s", int(d/(24*time.Hour)), suffix)
+	}
+	return t.Format(time.DateOnly)
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeFormat(t *testing.T) {
+	// Recorded in UTC, as on a CI machine
+	ts := time.Date(2024, 5, 1, 22, 30, 15, 0, time.UTC)
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		format string
+		tz     string
+		want   string
+	}{
+		{"", "", "2024-05-01 22:30:15"},
+		{"default", "utc", "2024-05-01 22:30:15"},
+		{"rfc3339", "", "2024-05-01T22:30:15Z"},
+		{"RFC3339", "Europe/Berlin", "2024-05-02T00:30:15+02:00"},
+		{"date", "Europe/Berlin", "2024-05-02"},
+		{"kitchen", "", "10:30PM"},
+		{"unix", "Europe/Berlin", "1714602615"},
+		{"02 Jan 15:04 MST", "Europe/Berlin", "02 May 00:30 CEST"},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseTimeFormat(tt.format, tt.tz)
+		if err != nil {
+			t.Errorf("ParseTimeFormat(%q, %q) failed: %v", tt.format, tt.tz, err)
+			continue
+		}
+		if got := f.Format(ts); got != tt.want {
+			t.Errorf("ParseTimeFormat(%q, %q).Format() = %q, want %q", tt.format, tt.tz, got, tt.want)
+		}
+	}
+
+	// Local converts to the zone of this machine
+	f, err := ParseTimeFormat("rfc3339", "local")
+	if err != nil {
+		t.Fatalf("ParseTimeFormat failed: %v", err)
+	}
+	if got, want := f.Format(ts), ts.In(time.Local).Format(time.RFC3339); got != want {
+		t.Errorf("Format() in local zone = %q, want %q", got, want)
+	}
+}
+
+func TestParseTimeFormatInvalid(t *testing.T) {
+	if _, err := ParseTimeFormat("fancy", ""); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+	if _, err := ParseTimeFormat("", "Mars/Olympus_Mons"); err == nil {
+		t.Error("Expected an error for an unknown time zone")
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{now.Add(-30 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-50 * time.Hour), "2d ago"},
+		{now.Add(2 * time.Hour), "2h from now"},
+		{now.AddDate(0, -2, 0), "2024-03-01"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTime(tt.t, now); got != tt.want {
+			t.Errorf("relativeTime(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+
+	f, _ := ParseTimeFormat("relative", "")
+	f.now = func() time.Time { return now }
+	if got := f.Format(now.Add(-5 * time.Minute)); got != "5m ago" {
+		t.Errorf("Format() = %q, want 5m ago", got)
+	}
+}
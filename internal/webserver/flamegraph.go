@@ -0,0 +1,262 @@
+package webserver
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alenon/gokanon/internal/profiler"
+)
+
+// Flame graph layout, in pixels
+const (
+	flameWidth       = 1200
+	flamePadding     = 10
+	flameFrameHeight = 16
+	flameHeader      = 44
+	flameFooter      = 28
+	flameCharWidth   = 7
+	flameMinFraction = 0.0005 // Frames narrower than this share of the total are not drawn
+)
+
+// flameNode is a function in a call tree built from folded stacks. Its
+// value includes the values of its callees.
+type flameNode struct {
+	name     string
+	value    int64
+	children []*flameNode
+}
+
+// child returns the callee of n with the given name, adding it if needed
+func (n *flameNode) child(name string) *flameNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &flameNode{name: name}
+	n.children = append(n.children, c)
+	return c
+}
+
+// depth returns the number of levels below n, n included
+func (n *flameNode) depth() int {
+	d := 0
+	for _, c := range n.children {
+		d = max(d, c.depth())
+	}
+	return d + 1
+}
+
+// buildFlameTree merges folded stacks into a call tree under a root node.
+// Stacks are sorted, so callees are in alphabetical order.
+func buildFlameTree(folded *profiler.FoldedStacks) *flameNode {
+	root := &flameNode{name: "all"}
+	for _, stack := range folded.Stacks {
+		root.value += stack.Weight
+		node := root
+		for _, name := range stack.Functions {
+			node = node.child(name)
+			node.value += stack.Weight
+		}
+	}
+	return root
+}
+
+// renderFlameGraph writes an interactive SVG flame graph of folded stacks,
+// callers above their callees. Clicking a frame zooms into it, and the
+// embedded script also searches for functions and shows frame details.
+func renderFlameGraph(w io.Writer, folded *profiler.FoldedStacks, title string) error {
+	root := buildFlameTree(folded)
+	height := flameHeader + root.depth()*flameFrameHeight + flameFooter
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg id="flamegraph" xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" data-width="%d" data-padding="%d" data-char-width="%d">`+"\n",
+		flameWidth, height, flameWidth, height, flameWidth-2*flamePadding, flamePadding, flameCharWidth)
+	sb.WriteString(`<style>
+.f rect { stroke: white; stroke-width: 0.5; cursor: pointer; }
+.f text { font: 12px monospace; fill: black; pointer-events: none; }
+.f.ancestor { opacity: 0.5; }
+.control { font: 12px sans-serif; fill: #007acc; cursor: pointer; }
+#details, #title, #matched { font: 12px sans-serif; fill: #333; }
+#title { font-size: 16px; font-weight: bold; }
+</style>
+`)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="#f8f8f8"/>`+"\n")
+	fmt.Fprintf(&sb, `<text id="title" x="%d" y="22" text-anchor="middle">%s</text>`+"\n", flameWidth/2, html.EscapeString(title))
+	fmt.Fprintf(&sb, `<text id="reset" class="control" x="%d" y="22" visibility="hidden">Reset Zoom</text>`+"\n", flamePadding)
+	fmt.Fprintf(&sb, `<text id="search" class="control" x="%d" y="22" text-anchor="end">Search</text>`+"\n", flameWidth-flamePadding)
+	fmt.Fprintf(&sb, `<text id="matched" x="%d" y="%d" text-anchor="end"></text>`+"\n", flameWidth-flamePadding, height-10)
+	fmt.Fprintf(&sb, `<text id="details" x="%d" y="%d"> </text>`+"\n", flamePadding, height-10)
+
+	if root.value > 0 {
+		writeFlameFrames(&sb, root, root.value, 0, 0, folded)
+	}
+
+	sb.WriteString("<script><![CDATA[\n" + flameGraphScript + "]]></script>\n</svg>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeFlameFrames writes the frame of a node starting at the fraction x of
+// the total, then the frames of its callees below it
+func writeFlameFrames(sb *strings.Builder, n *flameNode, total int64, x float64, depth int, folded *profiler.FoldedStacks) {
+	fraction := float64(n.value) / float64(total)
+	if fraction < flameMinFraction {
+		return
+	}
+
+	inner := float64(flameWidth - 2*flamePadding)
+	px, pw := flamePadding+x*inner, fraction*inner
+	y := flameHeader + depth*flameFrameHeight
+	info := fmt.Sprintf("%s (%s, %.2f%%)", n.name, formatFlameValue(n.value, folded.Unit), fraction*100)
+
+	fmt.Fprintf(sb, `<g class="f" data-x="%.6f" data-w="%.6f" data-d="%d" data-n="%s">`,
+		x, fraction, depth, html.EscapeString(n.name))
+	fmt.Fprintf(sb, `<title>%s</title>`, html.EscapeString(info))
+	fmt.Fprintf(sb, `<rect x="%.2f" y="%d" width="%.2f" height="%d" rx="2" fill="%s"/>`,
+		px, y, pw, flameFrameHeight-1, flameColor(n.name, depth == 0))
+	fmt.Fprintf(sb, `<text x="%.2f" y="%d">%s</text></g>`+"\n",
+		px+3, y+flameFrameHeight-4, html.EscapeString(flameLabel(n.name, pw)))
+
+	for _, c := range n.children {
+		writeFlameFrames(sb, c, total, x, depth+1, folded)
+		x += float64(c.value) / float64(total)
+	}
+}
+
+// flameLabel truncates a function name to fit a frame of the given width
+func flameLabel(name string, width float64) string {
+	chars := int((width - 6) / flameCharWidth)
+	if chars < 3 {
+		return ""
+	}
+	if len(name) <= chars {
+		return name
+	}
+	return name[:chars-2] + ".."
+}
+
+// flameColor returns a warm color that is stable for a function name, so
+// the same function has the same color in every flame graph
+func flameColor(name string, root bool) string {
+	if root {
+		return "rgb(200,200,200)"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	v := h.Sum32()
+	return fmt.Sprintf("rgb(%d,%d,%d)", 205+v%50, (v>>8)%230, (v>>16)%55)
+}
+
+// formatFlameValue formats a sample value in its unit
+func formatFlameValue(value int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return time.Duration(value).String()
+	case "bytes":
+		const k = 1024
+		if value < k {
+			return fmt.Sprintf("%d B", value)
+		}
+		div, exp := int64(k), 0
+		for n := value / k; n >= k; n /= k {
+			div *= k
+			exp++
+		}
+		return fmt.Sprintf("%.1f %cB", float64(value)/float64(div), "KMGTPE"[exp])
+	case "count", "":
+		return fmt.Sprintf("%d samples", value)
+	default:
+		return fmt.Sprintf("%d %s", value, unit)
+	}
+}
+
+// flameGraphScript makes the flame graph interactive: it zooms into clicked
+// frames, highlights the functions matching a search and shows the details
+// of the hovered frame. It runs in a standalone SVG and inlined in HTML.
+const flameGraphScript = `(function() {
+    var svg = document.getElementById('flamegraph');
+    var width = parseFloat(svg.getAttribute('data-width'));
+    var padding = parseFloat(svg.getAttribute('data-padding'));
+    var charWidth = parseFloat(svg.getAttribute('data-char-width'));
+    var frames = Array.prototype.slice.call(svg.querySelectorAll('g.f'));
+    var details = document.getElementById('details');
+    var reset = document.getElementById('reset');
+    var matched = document.getElementById('matched');
+
+    function attr(g, name) { return parseFloat(g.getAttribute('data-' + name)); }
+
+    function label(name, w) {
+        var chars = Math.floor((w - 6) / charWidth);
+        if (chars < 3) return '';
+        return name.length <= chars ? name : name.substring(0, chars - 2) + '..';
+    }
+
+    function place(g, fx, fw) {
+        var rect = g.querySelector('rect'), text = g.querySelector('text');
+        var x = padding + fx * width, w = fw * width;
+        rect.setAttribute('x', x);
+        rect.setAttribute('width', w);
+        text.setAttribute('x', x + 3);
+        text.textContent = label(g.getAttribute('data-n'), w);
+    }
+
+    function zoom(target) {
+        var x0 = attr(target, 'x'), w0 = attr(target, 'w'), d0 = attr(target, 'd');
+        var eps = 1e-9;
+        frames.forEach(function(g) {
+            var x = attr(g, 'x'), w = attr(g, 'w'), d = attr(g, 'd');
+            g.classList.remove('ancestor');
+            if (d < d0 && x <= x0 + eps && x + w >= x0 + w0 - eps) {
+                g.style.display = '';
+                g.classList.add('ancestor');
+                place(g, 0, 1);
+            } else if (d >= d0 && x >= x0 - eps && x + w <= x0 + w0 + eps) {
+                g.style.display = '';
+                place(g, (x - x0) / w0, w / w0);
+            } else {
+                g.style.display = 'none';
+            }
+        });
+        reset.setAttribute('visibility', d0 > 0 ? 'visible' : 'hidden');
+    }
+
+    function search() {
+        var term = prompt('Search for functions (regular expression):', '');
+        var re = null;
+        if (term) {
+            try { re = new RegExp(term); } catch (e) { re = null; }
+        }
+        var spans = [];
+        frames.forEach(function(g) {
+            var rect = g.querySelector('rect');
+            if (!rect.hasAttribute('data-fill')) rect.setAttribute('data-fill', rect.getAttribute('fill'));
+            var hit = re !== null && attr(g, 'd') > 0 && re.test(g.getAttribute('data-n'));
+            rect.setAttribute('fill', hit ? 'rgb(230,0,230)' : rect.getAttribute('data-fill'));
+            if (hit) spans.push([attr(g, 'x'), attr(g, 'x') + attr(g, 'w')]);
+        });
+        // Nested matches must not be counted twice
+        spans.sort(function(a, b) { return a[0] - b[0]; });
+        var total = 0, end = 0;
+        spans.forEach(function(s) {
+            if (s[0] >= end) { total += s[1] - s[0]; end = s[1]; }
+            else if (s[1] > end) { total += s[1] - end; end = s[1]; }
+        });
+        matched.textContent = re === null ? '' : 'Matched: ' + (total * 100).toFixed(2) + '%';
+    }
+
+    frames.forEach(function(g) {
+        g.addEventListener('click', function() { zoom(g); });
+        g.addEventListener('mouseover', function() {
+            details.textContent = g.querySelector('title').textContent;
+        });
+        g.addEventListener('mouseout', function() { details.textContent = ' '; });
+    });
+    reset.addEventListener('click', function() { if (frames.length) zoom(frames[0]); });
+    document.getElementById('search').addEventListener('click', search);
+})();
+`
@@ -0,0 +1,95 @@
+package webserver
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alenon/gokanon/internal/profiler"
+)
+
+func testFoldedStacks() *profiler.FoldedStacks {
+	return &profiler.FoldedStacks{
+		SampleType: "cpu",
+		Unit:       "nanoseconds",
+		Stacks: []profiler.FoldedStack{
+			{Functions: []string{"main", "parse"}, Weight: 60},
+			{Functions: []string{"main", "parse", "lex<T>"}, Weight: 30},
+			{Functions: []string{"main", "write"}, Weight: 10},
+		},
+	}
+}
+
+func TestBuildFlameTree(t *testing.T) {
+	root := buildFlameTree(testFoldedStacks())
+	if root.value != 100 || root.depth() != 4 {
+		t.Fatalf("Expected a root of 100 and depth 4, got %d and %d", root.value, root.depth())
+	}
+	main := root.children[0]
+	if len(root.children) != 1 || main.name != "main" || main.value != 100 {
+		t.Fatalf("Unexpected root children: %+v", root.children)
+	}
+	if len(main.children) != 2 || main.children[0].name != "parse" || main.children[0].value != 90 {
+		t.Errorf("Unexpected callees of main: %+v", main.children)
+	}
+}
+
+func TestRenderFlameGraph(t *testing.T) {
+	folded := testFoldedStacks()
+	folded.Stacks = append(folded.Stacks, profiler.FoldedStack{Functions: []string{"main", "tiny"}, Weight: 0})
+
+	var buf bytes.Buffer
+	if err := renderFlameGraph(&buf, folded, "CPU Flame Graph"); err != nil {
+		t.Fatalf("renderFlameGraph failed: %v", err)
+	}
+	svg := buf.String()
+
+	if !strings.HasPrefix(svg, `<svg id="flamegraph"`) || !strings.Contains(svg, "<script><![CDATA[") {
+		t.Error("Expected an interactive SVG")
+	}
+	// all, main, parse, lex and write
+	if got := strings.Count(svg, `<g class="f"`); got != 5 {
+		t.Errorf("Expected 5 frames, got %d", got)
+	}
+	if !strings.Contains(svg, `data-n="lex&lt;T&gt;"`) {
+		t.Error("Expected escaped function names")
+	}
+	if !strings.Contains(svg, "<title>parse (90ns, 90.00%)</title>") {
+		t.Error("Expected frame details with value and share")
+	}
+}
+
+func TestFlameLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		width float64
+		want  string
+	}{
+		{"main.parse", 200, "main.parse"},
+		{"main.parse", 62, "main.p.."},
+		{"main.parse", 20, ""},
+	}
+	for _, tt := range tests {
+		if got := flameLabel(tt.name, tt.width); got != tt.want {
+			t.Errorf("flameLabel(%q, %v) = %q, want %q", tt.name, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestFormatFlameValue(t *testing.T) {
+	tests := []struct {
+		value int64
+		unit  string
+		want  string
+	}{
+		{1500000, "nanoseconds", "1.5ms"},
+		{2048, "bytes", "2.0 KB"},
+		{42, "count", "42 samples"},
+		{3, "objects", "3 objects"},
+	}
+	for _, tt := range tests {
+		if got := formatFlameValue(tt.value, tt.unit); got != tt.want {
+			t.Errorf("formatFlameValue(%d, %q) = %q, want %q", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
@@ -6,11 +6,12 @@ import (
 	"html/template"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/profiler"
 	"github.com/alenon/gokanon/internal/storage"
 	"github.com/google/pprof/profile"
 )
@@ -37,7 +38,7 @@ func (s *Server) Start(runID string) error {
 		return fmt.Errorf("failed to load run: %w", err)
 	}
 
-	if run.CPUProfile == "" && run.MemoryProfile == "" {
+	if run.CPUProfile == "" && run.MemoryProfile == "" && run.BlockProfile == "" && run.MutexProfile == "" {
 		return fmt.Errorf("no profiles found for run %s", runID)
 	}
 
@@ -69,6 +70,24 @@ func (s *Server) Start(runID string) error {
 		})
 	}
 
+	// Contention profile visualization
+	if run.BlockProfile != "" {
+		mux.HandleFunc("/block", func(w http.ResponseWriter, r *http.Request) {
+			s.handleProfile(w, r, run.BlockProfile, "Block Profile")
+		})
+		mux.HandleFunc("/block/flamegraph", func(w http.ResponseWriter, r *http.Request) {
+			s.handleFlameGraph(w, r, run.BlockProfile, "Block")
+		})
+	}
+	if run.MutexProfile != "" {
+		mux.HandleFunc("/mutex", func(w http.ResponseWriter, r *http.Request) {
+			s.handleProfile(w, r, run.MutexProfile, "Mutex Profile")
+		})
+		mux.HandleFunc("/mutex/flamegraph", func(w http.ResponseWriter, r *http.Request) {
+			s.handleFlameGraph(w, r, run.MutexProfile, "Mutex")
+		})
+	}
+
 	// Profile comparison
 	if run.CPUProfile != "" && run.MemoryProfile != "" {
 		mux.HandleFunc("/compare", func(w http.ResponseWriter, r *http.Request) {
@@ -104,6 +123,9 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request, run *models
 		"float64": func(i int64) float64 {
 			return float64(i)
 		},
+		"duration": func(ns int64) time.Duration {
+			return time.Duration(ns)
+		},
 	}
 
 	tmpl := template.Must(template.New("index").Funcs(funcMap).Parse(indexTemplate))
@@ -111,11 +133,15 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request, run *models
 		Run        *models.BenchmarkRun
 		HasCPU     bool
 		HasMemory  bool
+		HasBlock   bool
+		HasMutex   bool
 		HasSummary bool
 	}{
 		Run:        run,
 		HasCPU:     run.CPUProfile != "",
 		HasMemory:  run.MemoryProfile != "",
+		HasBlock:   run.BlockProfile != "",
+		HasMutex:   run.MutexProfile != "",
 		HasSummary: run.ProfileSummary != nil,
 	}
 
@@ -138,37 +164,47 @@ func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request, profilePa
 	w.Write(data)
 }
 
-// handleFlameGraph generates and serves a flame graph
+// handleFlameGraph renders a flame graph of a profile, as a page or with
+// ?format=svg as a standalone SVG. ?sample_type= selects the sample type,
+// memory profiles showing allocated bytes by default.
 func (s *Server) handleFlameGraph(w http.ResponseWriter, r *http.Request, profilePath, profileType string) {
-	// Check if profile exists
-	if _, err := os.Stat(profilePath); err != nil {
+	data, err := os.ReadFile(profilePath)
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Profile not found: %v", err), http.StatusNotFound)
 		return
 	}
 
-	// Try to generate SVG using go tool pprof
-	cmd := exec.Command("go", "tool", "pprof", "-http=:", "-no_browser", profilePath)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	// Generate a simple visualization using go tool pprof
-	// For a basic flame graph, we'll generate the top output
-	cmd = exec.Command("go", "tool", "pprof", "-top", "-cum", profilePath)
-	output, err := cmd.CombinedOutput()
-
+	sampleType := r.URL.Query().Get("sample_type")
+	folded, err := profiler.Fold(profiler.ExportProfile{Name: profileType, Data: data, SampleType: sampleType})
 	if err != nil {
+		if sampleType != "" {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		// Fallback to simple visualization
 		s.handleSimpleVisualization(w, profilePath, profileType)
 		return
 	}
 
-	// Display as formatted text
+	var svg bytes.Buffer
+	title := fmt.Sprintf("%s Flame Graph (%s)", profileType, folded.SampleType)
+	if err := renderFlameGraph(&svg, folded, title); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to render flame graph: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-flamegraph.svg", strings.ToLower(profileType)))
+		w.Write(svg.Bytes())
+		return
+	}
+
 	tmpl := template.Must(template.New("flamegraph").Parse(flamegraphTemplate))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	tmpl.Execute(w, map[string]interface{}{
 		"Type":    profileType,
-		"Content": string(output),
+		"Content": template.HTML(svg.String()),
 		"Path":    profilePath,
 	})
 }
@@ -253,15 +289,14 @@ const flamegraphTemplate = `<!DOCTYPE html>
         .btn:hover {
             background: #005a9e;
         }
-        pre {
-            background: #2d2d30;
-            padding: 20px;
+        .flamegraph {
+            background: #f8f8f8;
             border-radius: 8px;
             overflow-x: auto;
-            white-space: pre;
-            font-family: 'Courier New', Courier, monospace;
-            font-size: 13px;
-            line-height: 1.5;
+        }
+        .flamegraph svg {
+            display: block;
+            margin: 0 auto;
         }
         .hint {
             background: #3e3e42;
@@ -278,15 +313,17 @@ const flamegraphTemplate = `<!DOCTYPE html>
         <div class="actions">
             <a href="/" class="btn">← Back to Overview</a>
             <a href="{{.Path}}" class="btn">Download Profile</a>
+            <a href="?format=svg" class="btn">Download SVG</a>
         </div>
     </div>
 
     <div class="hint">
-        <strong>💡 Tip:</strong> For interactive flame graphs, download the profile and use:<br>
+        <strong>💡 Tip:</strong> Click a frame to zoom into it, and use Search to highlight functions.
+        For source-level analysis, download the profile and use:<br>
         <code>go tool pprof -http=:8080 {{.Path}}</code>
     </div>
 
-    <pre>{{.Content}}</pre>
+    <div class="flamegraph">{{.Content}}</div>
 </body>
 </html>`
 
@@ -377,6 +414,21 @@ const indexTemplate = `<!DOCTYPE html>
             font-weight: bold;
             color: #333;
         }
+        .hotspots {
+            width: 100%;
+            border-collapse: collapse;
+            margin-top: 10px;
+        }
+        .hotspots th, .hotspots td {
+            padding: 8px;
+            text-align: left;
+            border-bottom: 1px solid #eee;
+        }
+        .hotspots th {
+            font-size: 12px;
+            color: #666;
+            text-transform: uppercase;
+        }
     </style>
 </head>
 <body>
@@ -409,6 +461,24 @@ const indexTemplate = `<!DOCTYPE html>
         </div>
         {{end}}
 
+        {{if .HasBlock}}
+        <div class="card">
+            <h2>⏳ Block Profile</h2>
+            <p>Find where goroutines wait on channels, locks and select.</p>
+            <a href="/block/flamegraph" class="btn">View Flame Graph</a>
+            <a href="/block" class="btn">Download Profile</a>
+        </div>
+        {{end}}
+
+        {{if .HasMutex}}
+        <div class="card">
+            <h2>🔒 Mutex Profile</h2>
+            <p>Find the lock holders other goroutines wait for.</p>
+            <a href="/mutex/flamegraph" class="btn">View Flame Graph</a>
+            <a href="/mutex" class="btn">Download Profile</a>
+        </div>
+        {{end}}
+
         {{if and .HasCPU .HasMemory}}
         <div class="card">
             <h2>📊 Comparison</h2>
@@ -434,6 +504,18 @@ const indexTemplate = `<!DOCTYPE html>
                 <div class="stat-value">{{printf "%.1f MB" (div (float64 .Run.ProfileSummary.TotalMemoryBytes) 1048576)}}</div>
             </div>
             {{end}}
+            {{if .Run.ProfileSummary.TotalBlockDelay}}
+            <div class="stat">
+                <div class="stat-label">Time Blocked</div>
+                <div class="stat-value">{{duration .Run.ProfileSummary.TotalBlockDelay}}</div>
+            </div>
+            {{end}}
+            {{if .Run.ProfileSummary.TotalMutexDelay}}
+            <div class="stat">
+                <div class="stat-label">Mutex Wait</div>
+                <div class="stat-value">{{duration .Run.ProfileSummary.TotalMutexDelay}}</div>
+            </div>
+            {{end}}
             <div class="stat">
                 <div class="stat-label">Hot Functions</div>
                 <div class="stat-value">{{len .Run.ProfileSummary.CPUTopFunctions}}</div>
@@ -443,6 +525,27 @@ const indexTemplate = `<!DOCTYPE html>
                 <div class="stat-value">{{len .Run.ProfileSummary.Suggestions}}</div>
             </div>
         </div>
+
+        {{with .Run.ProfileSummary.ContentionHotspots}}
+        <h3>🔒 Contention Hotspots</h3>
+        <table class="hotspots">
+            <thead>
+                <tr><th>Function</th><th>Profile</th><th>Waiting In</th><th>Contentions</th><th>Delay</th><th>Share</th></tr>
+            </thead>
+            <tbody>
+                {{range .}}
+                <tr>
+                    <td><code>{{.Function}}</code></td>
+                    <td>{{.Profile}}</td>
+                    <td><code>{{.Primitive}}</code></td>
+                    <td>{{.Contentions}}</td>
+                    <td>{{duration .DelayNs}}</td>
+                    <td>{{printf "%.1f%%" .Percentage}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
     </div>
     {{end}}
 </body>
@@ -175,6 +175,32 @@ func TestHandleIndex(t *testing.T) {
 	}
 }
 
+func TestHandleIndexContention(t *testing.T) {
+	store, run, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	run.BlockProfile = "block.prof"
+	run.MutexProfile = "mutex.prof"
+	run.ProfileSummary = &models.ProfileSummary{
+		TotalBlockDelay: int64(1500 * time.Millisecond),
+		ContentionHotspots: []models.ContentionHotspot{
+			{Function: "pkg.(*Cache).Get", Profile: "block", Primitive: "sync.(*Mutex).Lock", Contentions: 12, DelayNs: int64(1200 * time.Millisecond), Percentage: 80},
+		},
+	}
+
+	server := NewServer(store, "8080")
+	w := httptest.NewRecorder()
+	server.handleIndex(w, httptest.NewRequest("GET", "/", nil), run)
+
+	body := w.Body.String()
+	for _, want := range []string{"/block/flamegraph", "/mutex/flamegraph", "Contention Hotspots",
+		"pkg.(*Cache).Get", "sync.(*Mutex).Lock", "1.2s", "80.0%", "1.5s"} {
+		if !contains(body, want) {
+			t.Errorf("Response doesn't contain %q", want)
+		}
+	}
+}
+
 func TestHandleIndexNotFound(t *testing.T) {
 	store, run, cleanup := setupTestEnvironment(t)
 	defer cleanup()
@@ -267,6 +293,34 @@ func TestHandleFlameGraph(t *testing.T) {
 	}
 }
 
+func TestHandleFlameGraphSVG(t *testing.T) {
+	store, run, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	server := NewServer(store, "8080")
+
+	w := httptest.NewRecorder()
+	server.handleFlameGraph(w, httptest.NewRequest("GET", "/cpu/flamegraph", nil), run.CPUProfile, "CPU")
+	if body := w.Body.String(); !contains(body, `<svg id="flamegraph"`) || !contains(body, "main.test") {
+		t.Errorf("Expected the flame graph inlined in the page, got %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	server.handleFlameGraph(w, httptest.NewRequest("GET", "/cpu/flamegraph?format=svg&sample_type=samples", nil), run.CPUProfile, "CPU")
+	if ct := w.Result().Header.Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("Content-Type = %s, want image/svg+xml", ct)
+	}
+	if !contains(w.Body.String(), "CPU Flame Graph (samples)") {
+		t.Error("Expected the selected sample type in the title")
+	}
+
+	w = httptest.NewRecorder()
+	server.handleFlameGraph(w, httptest.NewRequest("GET", "/cpu/flamegraph?sample_type=bogus", nil), run.CPUProfile, "CPU")
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d for an unknown sample type", w.Result().StatusCode, http.StatusBadRequest)
+	}
+}
+
 func TestHandleFlameGraphNotFound(t *testing.T) {
 	store := storage.NewStorage(t.TempDir())
 	server := NewServer(store, "8080")
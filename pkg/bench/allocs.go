@@ -0,0 +1,38 @@
+package bench
+
+import "testing"
+
+// allocRuns is the number of calls allocations are averaged over
+const allocRuns = 100
+
+// AssertNoAllocs fails tb when fn allocates, reporting the allocations
+// per call. It runs fn a hundred times and is meant for tests guarding hot
+// paths.
+func AssertNoAllocs(tb testing.TB, fn func()) {
+	tb.Helper()
+	AssertAllocs(tb, 0, fn)
+}
+
+// AssertAllocs fails tb when fn allocates more than max times per call on
+// average
+func AssertAllocs(tb testing.TB, max float64, fn func()) {
+	tb.Helper()
+	if allocs := testing.AllocsPerRun(allocRuns, fn); allocs > max {
+		tb.Errorf("%.1f allocations per call, want at most %.0f", allocs, max)
+	}
+}
+
+// NoAllocs benchmarks fn after checking that it does not allocate. A
+// benchmark failing the check is reported as failed rather than measured,
+// so that gokanon records the allocation as a failure of the run instead
+// of a slower result.
+func NoAllocs(b *testing.B, fn func()) {
+	b.Helper()
+	if allocs := testing.AllocsPerRun(allocRuns, fn); allocs > 0 {
+		b.Fatalf("%.1f allocations per call, want none", allocs)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		fn()
+	}
+}
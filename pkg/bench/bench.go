@@ -0,0 +1,73 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Throughput benchmarks fn, which processes size bytes per call. go test
+// reports MB/s along with ns/op, so that runs with different inputs stay
+// comparable.
+func Throughput(b *testing.B, size int64, fn func()) {
+	b.SetBytes(size)
+	b.ReportAllocs()
+	for b.Loop() {
+		fn()
+	}
+}
+
+// Sizes benchmarks the throughput of an operation for each input size, as
+// a sub-benchmark named after the size, e.g. "4KiB". setup prepares the
+// input of a size outside the measurement and returns the operation.
+func Sizes(b *testing.B, sizes []int, setup func(size int) func()) {
+	for _, size := range sizes {
+		b.Run(SizeName(size), func(b *testing.B) {
+			Throughput(b, int64(size), setup(size))
+		})
+	}
+}
+
+// SizeName names a sub-benchmark after a size in bytes, such as "64B",
+// "4KiB" or "1MiB". Unlike names such as "size-64", it cannot be mistaken
+// for the GOMAXPROCS suffix of go test.
+func SizeName(size int) string {
+	switch {
+	case size >= 1<<30 && size%(1<<30) == 0:
+		return fmt.Sprintf("%dGiB", size>>30)
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMiB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKiB", size>>10)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+// RunParallel benchmarks fn called from many goroutines at once, such as
+// code sharing a lock, a pool or a cache. go test starts GOMAXPROCS
+// goroutines, so running it with several -cpu values, or with
+// 'gokanon run -stress', which finds benchmarks calling RunParallel,
+// shows how throughput scales and where contention sets in.
+func RunParallel(b *testing.B, fn func()) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			fn()
+		}
+	})
+}
+
+// RunParallelWith is RunParallel for operations needing state of their
+// own in each goroutine, such as a buffer or a random source, created by
+// newState outside the measurement
+func RunParallelWith[S any](b *testing.B, newState func() S, fn func(S)) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		state := newState()
+		for pb.Next() {
+			fn(state)
+		}
+	})
+}
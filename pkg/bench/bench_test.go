@@ -0,0 +1,133 @@
+package bench_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alenon/gokanon/pkg/bench"
+)
+
+var sink []byte
+
+func TestSizeName(t *testing.T) {
+	tests := []struct {
+		size int
+		want string
+	}{
+		{64, "64B"},
+		{1000, "1000B"},
+		{4 << 10, "4KiB"},
+		{1536, "1536B"},
+		{1 << 20, "1MiB"},
+		{3 << 30, "3GiB"},
+	}
+	for _, tt := range tests {
+		if got := bench.SizeName(tt.size); got != tt.want {
+			t.Errorf("SizeName(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestThroughput(t *testing.T) {
+	result := testing.Benchmark(func(b *testing.B) {
+		input := make([]byte, 1024)
+		bench.Throughput(b, int64(len(input)), func() { sink = append(sink[:0], input...) })
+	})
+	if result.N == 0 || result.Bytes != 1024 {
+		t.Errorf("Expected 1024 bytes per operation, got %+v", result)
+	}
+	if !strings.Contains(result.String(), "MB/s") {
+		t.Errorf("Expected MB/s to be reported: %s", result)
+	}
+	if result.MemString() == "" {
+		t.Error("Expected allocations to be reported")
+	}
+}
+
+func TestSizes(t *testing.T) {
+	var sizes []int
+	testing.Benchmark(func(b *testing.B) {
+		bench.Sizes(b, []int{64, 4 << 10}, func(size int) func() {
+			sizes = append(sizes, size)
+			input := make([]byte, size)
+			return func() { sink = append(sink[:0], input...) }
+		})
+	})
+	if len(sizes) == 0 || sizes[0] != 64 || sizes[len(sizes)-1] != 4<<10 {
+		t.Errorf("Expected setups for 64 and 4096 bytes, got %v", sizes)
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	result := testing.Benchmark(func(b *testing.B) {
+		bench.RunParallel(b, func() {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+		})
+	})
+	if result.N == 0 || calls < result.N {
+		t.Errorf("Expected at least %d calls, got %d", result.N, calls)
+	}
+
+	states := 0
+	testing.Benchmark(func(b *testing.B) {
+		bench.RunParallelWith(b, func() []byte {
+			mu.Lock()
+			states++
+			mu.Unlock()
+			return make([]byte, 64)
+		}, func(buf []byte) { buf[0]++ })
+	})
+	if states == 0 {
+		t.Error("Expected per-goroutine state to be created")
+	}
+}
+
+// recorder records the failures of assertions
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertAllocs(t *testing.T) {
+	var r recorder
+	bench.AssertNoAllocs(&r, func() { sink = sink[:0] })
+	if len(r.errors) != 0 {
+		t.Errorf("Expected no failure for code without allocations, got %v", r.errors)
+	}
+
+	bench.AssertNoAllocs(&r, func() { sink = make([]byte, 64) })
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "1.0 allocations per call") {
+		t.Errorf("Expected a failure for an allocation, got %v", r.errors)
+	}
+
+	r.errors = nil
+	bench.AssertAllocs(&r, 1, func() { sink = make([]byte, 64) })
+	if len(r.errors) != 0 {
+		t.Errorf("Expected no failure within the limit, got %v", r.errors)
+	}
+}
+
+func TestNoAllocs(t *testing.T) {
+	if result := testing.Benchmark(func(b *testing.B) {
+		bench.NoAllocs(b, func() { sink = sink[:0] })
+	}); result.N == 0 {
+		t.Error("Expected code without allocations to be measured")
+	}
+	if result := testing.Benchmark(func(b *testing.B) {
+		bench.NoAllocs(b, func() { sink = make([]byte, 64) })
+	}); result.N != 0 {
+		t.Errorf("Expected the benchmark of allocating code to fail, got %+v", result)
+	}
+}
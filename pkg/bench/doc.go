@@ -0,0 +1,25 @@
+// Package bench provides ready-made patterns for writing benchmarks whose
+// results gokanon tracks well: throughput across input sizes, parallel
+// benchmarks of contended code, and assertions that code does not
+// allocate.
+//
+// The helpers always report allocations, so that B/op and allocs/op are
+// compared along with ns/op, and name sub-benchmarks so that gokanon can
+// tell them from the GOMAXPROCS suffix go test appends:
+//
+//	func BenchmarkEncode(b *testing.B) {
+//		bench.Sizes(b, []int{64, 4 << 10, 1 << 20}, func(size int) func() {
+//			input := make([]byte, size)
+//			return func() { encode(input) }
+//		})
+//	}
+//
+//	func BenchmarkCacheGet(b *testing.B) {
+//		cache := newCache()
+//		bench.RunParallel(b, func() { cache.Get("key") })
+//	}
+//
+//	func BenchmarkLookup(b *testing.B) {
+//		bench.NoAllocs(b, func() { table.Lookup(42) })
+//	}
+package bench
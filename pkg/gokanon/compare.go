@@ -0,0 +1,37 @@
+package gokanon
+
+import (
+	"github.com/alenon/gokanon/internal/compare"
+	"github.com/alenon/gokanon/internal/threshold"
+)
+
+// Comparer compares the benchmarks of two runs, pairing them by name the
+// way 'gokanon compare' does
+type Comparer struct {
+	comparer *compare.Comparer
+}
+
+// NewComparer returns a comparer treating changes within 5% as the same
+func NewComparer() *Comparer {
+	return &Comparer{comparer: compare.NewComparer()}
+}
+
+// Compare returns the comparison of each benchmark of newRun with the same
+// benchmark in oldRun. Benchmarks present in only one run are included
+// with the StatusAdded or StatusRemoved status.
+func (c *Comparer) Compare(oldRun, newRun *BenchmarkRun) []Comparison {
+	return c.comparer.Compare(oldRun, newRun)
+}
+
+// Summary counts the comparisons by status in one line, such as how many
+// benchmarks improved or degraded
+func Summary(comparisons []Comparison) string {
+	return compare.Summary(comparisons)
+}
+
+// Check checks comparisons against a maximum degradation in percent, as
+// 'gokanon check' does. Failed benchmarks, and B/op or allocs/op growing
+// beyond the threshold, fail the check too.
+func Check(comparisons []Comparison, maxDegradation float64) *CheckResult {
+	return threshold.NewChecker(maxDegradation).Check(comparisons)
+}
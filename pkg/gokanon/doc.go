@@ -0,0 +1,30 @@
+// Package gokanon runs Go benchmarks, keeps their results and compares
+// runs, the way the gokanon command does, for programs and test harnesses
+// that track benchmarks without shelling out to the CLI.
+//
+// A typical program runs the benchmarks of a package, saves the run and
+// checks it against the previous one:
+//
+//	store := gokanon.OpenStorage("")
+//	previous, err := store.Latest()
+//	if err != nil && !errors.Is(err, gokanon.ErrRunNotFound) {
+//		return err
+//	}
+//	run, err := gokanon.NewRunner("./parser", ".").WithCount(5).Run(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	if err := store.Save(run); err != nil || previous == nil {
+//		return err
+//	}
+//	comparisons := gokanon.NewComparer().Compare(previous, run)
+//	if result := gokanon.Check(comparisons, 10); !result.Passed {
+//		return fmt.Errorf("%d benchmarks regressed", len(result.Failures))
+//	}
+//
+// Errors are wrapped with what they concern; ErrRunNotFound and the other
+// errors of the package are told apart with errors.Is.
+//
+// Runs saved here are the runs the CLI and the dashboard show, as long as
+// both use the same storage directory.
+package gokanon
@@ -0,0 +1,27 @@
+package gokanon
+
+import (
+	"github.com/alenon/gokanon/internal/runner"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Errors that Storage and Runner return wrapped, to be told apart with
+// errors.Is
+var (
+	// ErrRunNotFound is returned for a run that is not stored, including
+	// by Latest for an empty storage
+	ErrRunNotFound = storage.ErrRunNotFound
+
+	// ErrBaselineNotFound is returned for a baseline that does not exist
+	ErrBaselineNotFound = storage.ErrBaselineNotFound
+
+	// ErrBaselineExists is returned by CreateBaseline for a name taken
+	ErrBaselineExists = storage.ErrBaselineExists
+
+	// ErrProfileMissing is returned for a profile a run does not have
+	ErrProfileMissing = storage.ErrProfileMissing
+
+	// ErrNoBenchmarks is returned by Runner.Run when go test reported no
+	// benchmark result, e.g. because the filter matched none
+	ErrNoBenchmarks = runner.ErrNoBenchmarks
+)
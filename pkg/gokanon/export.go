@@ -0,0 +1,58 @@
+package gokanon
+
+import (
+	"time"
+
+	"github.com/alenon/gokanon/internal/export"
+)
+
+// timestampFormat is how exports show the time of a run
+const timestampFormat = "2006-01-02 15:04:05"
+
+// Exporter writes comparisons and runs to report files
+type Exporter struct {
+	exporter *export.Exporter
+}
+
+// NewExporter returns an exporter
+func NewExporter() *Exporter {
+	return &Exporter{exporter: export.NewExporter()}
+}
+
+// ToHTML writes the comparison of two runs as an interactive HTML report,
+// with the optimization suggestions of newRun's profiles
+func (e *Exporter) ToHTML(comparisons []Comparison, oldRun, newRun *BenchmarkRun, filename string) error {
+	var suggestions []Suggestion
+	if newRun.ProfileSummary != nil {
+		suggestions = newRun.ProfileSummary.Suggestions
+	}
+	return e.exporter.ToHTML(comparisons, suggestions, oldRun.ID, newRun.ID,
+		formatTimestamp(oldRun.Timestamp), formatTimestamp(newRun.Timestamp), filename)
+}
+
+// ToMarkdown writes the comparison of two runs as a Markdown report
+func (e *Exporter) ToMarkdown(comparisons []Comparison, oldRun, newRun *BenchmarkRun, filename string) error {
+	return e.exporter.ToMarkdown(comparisons, oldRun.ID, newRun.ID, filename)
+}
+
+// ToCSV writes comparisons as CSV
+func (e *Exporter) ToCSV(comparisons []Comparison, filename string) error {
+	return e.exporter.ToCSV(comparisons, filename)
+}
+
+// ToRunHTML writes a single run as a self-contained HTML page, with its
+// results, profile summary and optimization suggestions
+func (e *Exporter) ToRunHTML(run *BenchmarkRun, filename string) error {
+	return e.exporter.ToRunHTML(run, formatTimestamp(run.Timestamp), filename)
+}
+
+// ToParquet writes the result history of runs as a Parquet file with one
+// row per run, benchmark and metric
+func (e *Exporter) ToParquet(runs []BenchmarkRun, filename string) error {
+	return e.exporter.ToParquet(runs, filename)
+}
+
+// formatTimestamp formats the time of a run for exports
+func formatTimestamp(t time.Time) string {
+	return t.Format(timestampFormat)
+}
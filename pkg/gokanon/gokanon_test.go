@@ -0,0 +1,155 @@
+package gokanon_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alenon/gokanon/pkg/gokanon"
+)
+
+// saveRuns saves a run and a newer one whose Parse benchmark is 50% slower
+func saveRuns(t *testing.T, store *gokanon.Storage) (oldRun, newRun *gokanon.BenchmarkRun) {
+	t.Helper()
+	oldRun = &gokanon.BenchmarkRun{
+		ID:        "run-old",
+		Timestamp: time.Now().Add(-time.Hour),
+		Results: []gokanon.BenchmarkResult{
+			{Name: "Parse-8", NsPerOp: 100},
+			{Name: "Encode-8", NsPerOp: 50},
+		},
+	}
+	newRun = &gokanon.BenchmarkRun{
+		ID:        "run-new",
+		Timestamp: time.Now(),
+		Results: []gokanon.BenchmarkResult{
+			{Name: "Parse-8", NsPerOp: 150},
+			{Name: "Encode-8", NsPerOp: 50},
+		},
+	}
+	for _, run := range []*gokanon.BenchmarkRun{oldRun, newRun} {
+		if err := store.Save(run); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	return oldRun, newRun
+}
+
+func TestStorageAndCompare(t *testing.T) {
+	dir := t.TempDir()
+	store := gokanon.OpenStorage(dir)
+	if store.Dir() != dir {
+		t.Errorf("Dir() = %s, want %s", store.Dir(), dir)
+	}
+	saveRuns(t, store)
+
+	latest, err := store.Latest()
+	if err != nil || latest.ID != "run-new" {
+		t.Fatalf("Latest() = %v, %v, want run-new", latest, err)
+	}
+	if _, err := store.SaveBaseline("v1.0", "run-old", "First release", nil); err != nil {
+		t.Fatalf("SaveBaseline failed: %v", err)
+	}
+	if _, err := store.CreateBaseline("v1.0", "run-new", "", nil); !errors.Is(err, gokanon.ErrBaselineExists) {
+		t.Errorf("CreateBaseline of a taken name: got %v, want ErrBaselineExists", err)
+	}
+	if _, err := store.Resolve("run-missing"); !errors.Is(err, gokanon.ErrRunNotFound) {
+		t.Errorf("Resolve(run-missing): got %v, want ErrRunNotFound", err)
+	}
+	baseline, err := store.Resolve("baseline:v1.0")
+	if err != nil || baseline.ID != "run-old" {
+		t.Fatalf("Resolve(baseline:v1.0) = %v, %v, want run-old", baseline, err)
+	}
+
+	comparisons := gokanon.NewComparer().Compare(baseline, latest)
+	if len(comparisons) != 2 {
+		t.Fatalf("Expected 2 comparisons, got %+v", comparisons)
+	}
+	statuses := map[string]string{}
+	for _, comp := range comparisons {
+		statuses[comp.Name] = comp.Status
+	}
+	if statuses["Parse-8"] != gokanon.StatusDegraded || statuses["Encode-8"] != gokanon.StatusSame {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+	if summary := gokanon.Summary(comparisons); !strings.Contains(summary, "1 degraded") {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+
+	if result := gokanon.Check(comparisons, 10); result.Passed || len(result.Failures) != 1 {
+		t.Errorf("Expected Parse to fail a 10%% threshold, got %+v", result)
+	}
+	if result := gokanon.Check(comparisons, 60); !result.Passed {
+		t.Errorf("Expected a 60%% threshold to pass, got %+v", result)
+	}
+
+	if err := store.Delete("run-new"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if runs, err := store.List(); err != nil || len(runs) != 1 {
+		t.Errorf("Expected 1 run left, got %d, %v", len(runs), err)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	store := gokanon.OpenStorage(t.TempDir())
+	oldRun, newRun := saveRuns(t, store)
+	comparisons := gokanon.NewComparer().Compare(oldRun, newRun)
+
+	dir := t.TempDir()
+	exporter := gokanon.NewExporter()
+	exports := map[string]func(string) error{
+		"report.html": func(f string) error { return exporter.ToHTML(comparisons, oldRun, newRun, f) },
+		"report.md":   func(f string) error { return exporter.ToMarkdown(comparisons, oldRun, newRun, f) },
+		"report.csv":  func(f string) error { return exporter.ToCSV(comparisons, f) },
+		"run.html":    func(f string) error { return exporter.ToRunHTML(newRun, f) },
+	}
+	for name, write := range exports {
+		path := filepath.Join(dir, name)
+		if err := write(path); err != nil {
+			t.Errorf("%s: export failed: %v", name, err)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "Parse") {
+			t.Errorf("%s: expected the Parse benchmark in the export, got %v", name, err)
+		}
+	}
+}
+
+func TestRunner(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+
+	var progress []string
+	run, err := gokanon.NewRunner(".", "^BenchmarkStringBuilder$").
+		WithDir("../../examples").
+		WithBenchtime("1x").
+		WithoutHooks().
+		WithProgress(func(result gokanon.BenchmarkResult) {
+			progress = append(progress, result.Name)
+		}).
+		Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(run.Results) != 1 || !strings.HasPrefix(run.Results[0].Name, "StringBuilder") {
+		t.Errorf("Expected the StringBuilder result, got %+v", run.Results)
+	}
+	if len(progress) != 1 {
+		t.Errorf("Expected one progress callback, got %v", progress)
+	}
+}
+
+func TestRunnerUnknownProfile(t *testing.T) {
+	store := gokanon.OpenStorage(t.TempDir())
+	_, err := gokanon.NewRunner(".", ".").WithProfiling(store, "heap").Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `unknown profile type "heap"`) {
+		t.Errorf("Expected an unknown profile error, got %v", err)
+	}
+}
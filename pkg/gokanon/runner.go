@@ -0,0 +1,127 @@
+package gokanon
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/alenon/gokanon/internal/runner"
+)
+
+// Profile is a profile the runner can take while the benchmarks run
+type Profile string
+
+// Profiles the runner can take
+const (
+	ProfileCPU    Profile = "cpu"
+	ProfileMemory Profile = "mem"
+	ProfileBlock  Profile = "block"
+	ProfileMutex  Profile = "mutex"
+)
+
+// Runner runs the benchmarks of Go packages with go test and parses their
+// results into a run. Its methods configure it and return it, so that they
+// can be chained; a Runner runs once.
+type Runner struct {
+	runner *runner.Runner
+	err    error // First configuration error, returned by Run
+}
+
+// NewRunner returns a runner for the benchmarks of the packages matching
+// packagePath, such as "./..." (the current directory when empty), whose
+// names match benchFilter, the -bench regexp of go test
+func NewRunner(packagePath, benchFilter string) *Runner {
+	if benchFilter == "" {
+		benchFilter = "."
+	}
+	return &Runner{runner: runner.NewRunner(packagePath, benchFilter)}
+}
+
+// WithDir runs go test from dir instead of the current directory. The
+// package path is relative to dir.
+func (r *Runner) WithDir(dir string) *Runner {
+	r.runner.WithDir(dir)
+	return r
+}
+
+// WithBenchtime sets the -benchtime of go test, such as "2s" or "1000x"
+func (r *Runner) WithBenchtime(benchtime string) *Runner {
+	r.runner.WithBenchtime(benchtime)
+	return r
+}
+
+// WithCount runs each benchmark n times. The results hold every sample,
+// so that comparisons test the significance of their differences.
+func (r *Runner) WithCount(n int) *Runner {
+	if n > 1 {
+		r.runner.WithCount(n)
+	}
+	return r
+}
+
+// WithCPU sets the -cpu list of go test, the GOMAXPROCS values each
+// benchmark runs with, such as "1,4"
+func (r *Runner) WithCPU(cpu string) *Runner {
+	r.runner.WithCPU(cpu)
+	return r
+}
+
+// WithGCFlags sets the -gcflags passed to the compiler
+func (r *Runner) WithGCFlags(gcflags string) *Runner {
+	r.runner.WithGCFlags(gcflags)
+	return r
+}
+
+// WithProgress calls fn with the result of each benchmark as it finishes
+func (r *Runner) WithProgress(fn func(BenchmarkResult)) *Runner {
+	r.runner.WithProgress(runner.ProgressCallback(fn))
+	return r
+}
+
+// WithOutput copies the output of go test to w
+func (r *Runner) WithOutput(w io.Writer) *Runner {
+	r.runner.WithVerbose(w)
+	return r
+}
+
+// WithoutHooks disables the GokanonSetup and GokanonTeardown hooks that
+// benchmark packages may declare
+func (r *Runner) WithoutHooks() *Runner {
+	r.runner.WithoutHooks()
+	return r
+}
+
+// WithProfiling takes the given profiles while the benchmarks run. They
+// are saved to store under the ID of the run, and analyzed into its
+// profile summary; the run itself is saved by the caller.
+func (r *Runner) WithProfiling(store *Storage, profiles ...Profile) *Runner {
+	opts := &runner.ProfileOptions{Storage: store.store}
+	for _, profile := range profiles {
+		switch profile {
+		case ProfileCPU:
+			opts.EnableCPU = true
+		case ProfileMemory:
+			opts.EnableMemory = true
+		case ProfileBlock:
+			opts.EnableBlock = true
+		case ProfileMutex:
+			opts.EnableMutex = true
+		default:
+			if r.err == nil {
+				r.err = fmt.Errorf("unknown profile type %q", profile)
+			}
+		}
+	}
+	r.runner.WithProfiling(opts)
+	return r
+}
+
+// Run runs the benchmarks. Cancelling ctx stops them and returns an error.
+// Benchmarks that fail or are skipped are recorded in the run with their
+// status rather than failing it.
+func (r *Runner) Run(ctx context.Context) (*BenchmarkRun, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.runner.WithContext(ctx).Run()
+}
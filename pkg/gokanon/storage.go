@@ -0,0 +1,89 @@
+package gokanon
+
+import (
+	"github.com/alenon/gokanon/internal/config"
+	"github.com/alenon/gokanon/internal/storage"
+)
+
+// Storage keeps benchmark runs, their profiles and baselines in a
+// directory, in the format the gokanon command reads
+type Storage struct {
+	dir   string
+	store *storage.Storage
+}
+
+// OpenStorage opens the storage in dir, which is created when the first run
+// is saved. An empty dir is the directory the gokanon command uses from
+// the working directory: the nearest .gokanon directory, or the project's
+// directory under $XDG_DATA_HOME/gokanon.
+func OpenStorage(dir string) *Storage {
+	if dir == "" {
+		dir = config.DefaultStorageDir()
+	}
+	return &Storage{dir: dir, store: storage.NewStorage(dir)}
+}
+
+// Dir returns the directory of the storage
+func (s *Storage) Dir() string {
+	return s.dir
+}
+
+// Save saves a run
+func (s *Storage) Save(run *BenchmarkRun) error {
+	return s.store.Save(run)
+}
+
+// Load loads the run with the given ID
+func (s *Storage) Load(id string) (*BenchmarkRun, error) {
+	return s.store.Load(id)
+}
+
+// Resolve loads the run a reference names, as the CLI accepts them: a run
+// ID, "latest", "latest~N", "previous", "baseline:NAME" for the run of a
+// baseline, or "commit:REV" for the newest run of a commit
+func (s *Storage) Resolve(ref string) (*BenchmarkRun, error) {
+	return s.store.Resolve(ref)
+}
+
+// List returns all runs, newest first
+func (s *Storage) List() ([]BenchmarkRun, error) {
+	return s.store.List()
+}
+
+// Latest returns the newest run
+func (s *Storage) Latest() (*BenchmarkRun, error) {
+	return s.store.GetLatest()
+}
+
+// Delete deletes a run and its profiles
+func (s *Storage) Delete(id string) error {
+	return s.store.Delete(id)
+}
+
+// SaveBaseline saves the run with the given ID as the named baseline,
+// replacing an existing baseline of that name
+func (s *Storage) SaveBaseline(name, runID, description string, tags map[string]string) (*Baseline, error) {
+	return s.store.SaveBaseline(name, runID, description, tags)
+}
+
+// CreateBaseline is SaveBaseline failing with ErrBaselineExists instead of
+// replacing a baseline of that name
+func (s *Storage) CreateBaseline(name, runID, description string, tags map[string]string) (*Baseline, error) {
+	return s.store.CreateBaseline(name, runID, description, tags)
+}
+
+// LoadBaseline loads the named baseline. Its run is loaded with
+// Resolve("baseline:" + name).
+func (s *Storage) LoadBaseline(name string) (*Baseline, error) {
+	return s.store.LoadBaseline(name)
+}
+
+// ListBaselines returns all baselines
+func (s *Storage) ListBaselines() ([]Baseline, error) {
+	return s.store.ListBaselines()
+}
+
+// DeleteBaseline deletes the named baseline, keeping its run
+func (s *Storage) DeleteBaseline(name string) error {
+	return s.store.DeleteBaseline(name)
+}
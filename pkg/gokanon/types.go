@@ -0,0 +1,54 @@
+package gokanon
+
+import (
+	"github.com/alenon/gokanon/internal/models"
+	"github.com/alenon/gokanon/internal/threshold"
+)
+
+// BenchmarkRun is a complete benchmark run with its metadata, as saved in
+// the storage
+type BenchmarkRun = models.BenchmarkRun
+
+// BenchmarkResult is the result of one benchmark in a run
+type BenchmarkResult = models.BenchmarkResult
+
+// Comparison is the change of one benchmark between two runs
+type Comparison = models.Comparison
+
+// MetricComparison is the change of one metric, such as B/op, between two
+// runs
+type MetricComparison = models.MetricComparison
+
+// Environment fingerprints the machine a run executed on
+type Environment = models.Environment
+
+// Baseline is a named reference to a saved run
+type Baseline = models.Baseline
+
+// ProfileSummary is the analysis of the profiles taken during a run
+type ProfileSummary = models.ProfileSummary
+
+// Suggestion is an optimization suggestion from the profile analysis
+type Suggestion = models.Suggestion
+
+// CheckResult is the outcome of a threshold check
+type CheckResult = threshold.Result
+
+// CheckFailure is a benchmark that failed a threshold check
+type CheckFailure = threshold.Failure
+
+// Benchmark result statuses
+const (
+	StatusOK      = models.StatusOK
+	StatusFailed  = models.StatusFailed
+	StatusSkipped = models.StatusSkipped
+)
+
+// Comparison statuses
+const (
+	StatusImproved = "improved"
+	StatusDegraded = "degraded"
+	StatusSame     = "same"
+	StatusAdded    = models.StatusAdded
+	StatusRemoved  = models.StatusRemoved
+)